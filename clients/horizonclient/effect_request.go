@@ -17,18 +17,26 @@ type EffectHandler func(effects.Effect)
 // BuildURL creates the endpoint to be queried based on the data in the EffectRequest struct.
 // If no data is set, it defaults to the build the URL for all effects
 func (er EffectRequest) BuildURL() (endpoint string, err error) {
-	nParams := countParams(er.ForAccount, er.ForLedger, er.ForLiquidityPool, er.ForOperation, er.ForTransaction)
+	nParams := countParams(er.ForAccount, er.ForMuxedAccount, er.ForLedger, er.ForLiquidityPool, er.ForOperation, er.ForTransaction)
 
 	if nParams > 1 {
 		return endpoint, errors.New("invalid request: too many parameters")
 	}
 
+	if err = checkMuxedAccount(er.ForMuxedAccount); err != nil {
+		return endpoint, err
+	}
+
 	endpoint = "effects"
 
 	if er.ForAccount != "" {
 		endpoint = fmt.Sprintf("accounts/%s/effects", er.ForAccount)
 	}
 
+	if er.ForMuxedAccount != "" {
+		endpoint = fmt.Sprintf("accounts/%s/effects", er.ForMuxedAccount)
+	}
+
 	if er.ForLedger != "" {
 		endpoint = fmt.Sprintf("ledgers/%s/effects", er.ForLedger)
 	}
@@ -25,6 +25,13 @@ func TestEffectRequestBuildUrl(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "accounts/GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU/effects", endpoint)
 
+	er = EffectRequest{ForMuxedAccount: "MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4O"}
+	endpoint, err = er.BuildURL()
+
+	// It should return valid muxed account effects endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "accounts/MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4O/effects", endpoint)
+
 	er = EffectRequest{ForLedger: "123"}
 	endpoint, err = er.BuildURL()
 
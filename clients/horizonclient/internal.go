@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/clock"
 	"github.com/stellar/go/support/errors"
 )
@@ -114,6 +115,15 @@ func countParams(params ...interface{}) int {
 	return counter
 }
 
+// checkMuxedAccount returns an error if address is set and is not a muxed
+// account (M...) address
+func checkMuxedAccount(address string) error {
+	if address != "" && !strkey.IsValidMuxedAccountEd25519PublicKey(address) {
+		return errors.New("invalid request: not a muxed account address")
+	}
+	return nil
+}
+
 // addQueryParams sets query parameters for a url
 func addQueryParams(params ...interface{}) string {
 	query := url.Values{}
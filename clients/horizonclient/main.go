@@ -285,11 +285,13 @@ type AccountRequest struct {
 }
 
 // EffectRequest struct contains data for getting effects from a horizon server.
-// "ForAccount", "ForLedger", "ForOperation" and "ForTransaction": Not more than one of these
+// "ForAccount", "ForMuxedAccount", "ForLedger", "ForOperation" and "ForTransaction": Not more than one of these
 // can be set at a time. If none are set, the default is to return all effects.
+// "ForMuxedAccount" is a muxed account (M...) address, only the effects of the muxed account are returned.
 // The query parameters (Order, Cursor and Limit) are optional. All or none can be set.
 type EffectRequest struct {
 	ForAccount       string
+	ForMuxedAccount  string
 	ForLedger        string
 	ForLiquidityPool string
 	ForOperation     string
@@ -337,11 +339,14 @@ type OfferRequest struct {
 }
 
 // OperationRequest struct contains data for getting operation details from a horizon server.
-// "ForAccount", "ForLedger", "ForTransaction": Only one of these can be set at a time. If none
+// "ForAccount", "ForMuxedAccount", "ForLedger", "ForTransaction": Only one of these can be set at a time. If none
 // are provided, the default is to return all operations.
+// "ForMuxedAccount" is a muxed account (M...) address, only the operations in which the muxed account
+// participates are returned.
 // The query parameters (Order, Cursor, Limit and IncludeFailed) are optional. All or none can be set.
 type OperationRequest struct {
 	ForAccount          string
+	ForMuxedAccount     string
 	ForClaimableBalance string
 	ForLedger           uint
 	ForLiquidityPool    string
@@ -361,11 +366,14 @@ type submitRequest struct {
 }
 
 // TransactionRequest struct contains data for getting transaction details from a horizon server.
-// "ForAccount", "ForClaimableBalance", "ForLedger": Only one of these can be set at a time.
+// "ForAccount", "ForMuxedAccount", "ForClaimableBalance", "ForLedger": Only one of these can be set at a time.
 // If none are provided, the default is to return all transactions.
+// "ForMuxedAccount" is a muxed account (M...) address, only the transactions in which the muxed account
+// participates are returned.
 // The query parameters (Order, Cursor, Limit and IncludeFailed) are optional. All or none can be set.
 type TransactionRequest struct {
 	ForAccount          string
+	ForMuxedAccount     string
 	ForClaimableBalance string
 	ForLedger           uint
 	ForLiquidityPool    string
@@ -400,6 +408,9 @@ type PathsRequest struct {
 	DestinationAmount      string
 	SourceAccount          string
 	SourceAssets           string
+	// SplitRoutes requests payments split across several payment paths to
+	// reduce slippage. The routes of each payment are listed in Path.Routes.
+	SplitRoutes bool
 }
 
 // StrictSendPathsRequest struct contains data for getting available strict send path payments from a horizon server.
@@ -413,6 +424,9 @@ type StrictSendPathsRequest struct {
 	SourceAssetCode    string
 	SourceAssetIssuer  string
 	SourceAmount       string
+	// SplitRoutes requests payments split across several payment paths to
+	// reduce slippage. The routes of each payment are listed in Path.Routes.
+	SplitRoutes bool
 }
 
 // TradeRequest struct contains data for getting trade details from a horizon server.
@@ -14,12 +14,16 @@ import (
 // BuildURL creates the endpoint to be queried based on the data in the OperationRequest struct.
 // If no data is set, it defaults to the build the URL for all operations or all payments; depending on thevalue of `op.endpoint`
 func (op OperationRequest) BuildURL() (endpoint string, err error) {
-	nParams := countParams(op.ForAccount, op.ForLedger, op.ForLiquidityPool, op.forOperationID, op.ForTransaction)
+	nParams := countParams(op.ForAccount, op.ForMuxedAccount, op.ForLedger, op.ForLiquidityPool, op.forOperationID, op.ForTransaction)
 
 	if nParams > 1 {
 		return endpoint, errors.New("invalid request: too many parameters")
 	}
 
+	if err = checkMuxedAccount(op.ForMuxedAccount); err != nil {
+		return endpoint, err
+	}
+
 	if op.endpoint == "" {
 		return endpoint, errors.New("internal error, endpoint not set")
 	}
@@ -28,6 +32,9 @@ func (op OperationRequest) BuildURL() (endpoint string, err error) {
 	if op.ForAccount != "" {
 		endpoint = fmt.Sprintf("accounts/%s/%s", op.ForAccount, op.endpoint)
 	}
+	if op.ForMuxedAccount != "" {
+		endpoint = fmt.Sprintf("accounts/%s/%s", op.ForMuxedAccount, op.endpoint)
+	}
 	if op.ForClaimableBalance != "" {
 		endpoint = fmt.Sprintf("claimable_balances/%s/%s", op.ForClaimableBalance, op.endpoint)
 	}
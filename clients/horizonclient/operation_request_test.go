@@ -25,6 +25,13 @@ func TestOperationRequestBuildUrl(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "accounts/GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU/operations", endpoint)
 
+	op = OperationRequest{ForMuxedAccount: "MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4O", endpoint: "payments"}
+	endpoint, err = op.BuildURL()
+
+	// It should return valid muxed account payments endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "accounts/MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4O/payments", endpoint)
+
 	op = OperationRequest{ForClaimableBalance: "00000000178826fbfe339e1f5c53417c6fedfe2c05e8bec14303143ec46b38981b09c3f9", endpoint: "operations"}
 	endpoint, err = op.BuildURL()
 
@@ -22,6 +22,9 @@ func (pr PathsRequest) BuildURL() (endpoint string, err error) {
 	paramMap["destination_amount"] = pr.DestinationAmount
 	paramMap["source_account"] = pr.SourceAccount
 	paramMap["source_assets"] = pr.SourceAssets
+	if pr.SplitRoutes {
+		paramMap["split_routes"] = "true"
+	}
 
 	queryParams := addQueryParams(paramMap)
 	if queryParams != "" {
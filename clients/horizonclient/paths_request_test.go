@@ -38,6 +38,15 @@ func TestPathsRequestBuildUrl(t *testing.T) {
 		endpoint,
 	)
 
+	pr.SplitRoutes = true
+	endpoint, err = pr.BuildURL()
+
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"paths?destination_account=GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU&destination_amount=100&destination_asset_code=NGN&destination_asset_issuer=GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM&destination_asset_type=credit_alphanum4&source_account=GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM&source_assets=COP%3AGDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM&split_routes=true",
+		endpoint,
+	)
 }
 
 func TestPathsRequest(t *testing.T) {
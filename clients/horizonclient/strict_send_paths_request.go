@@ -21,6 +21,9 @@ func (pr StrictSendPathsRequest) BuildURL() (endpoint string, err error) {
 	paramMap["source_asset_code"] = pr.SourceAssetCode
 	paramMap["source_asset_issuer"] = pr.SourceAssetIssuer
 	paramMap["source_amount"] = pr.SourceAmount
+	if pr.SplitRoutes {
+		paramMap["split_routes"] = "true"
+	}
 
 	queryParams := addQueryParams(paramMap)
 	if queryParams != "" {
@@ -51,6 +51,16 @@ func TestStrictSendPathsRequestBuildUrl(t *testing.T) {
 		"paths/strict-send?destination_assets=EURT%3AGAP5LETOV6YIE62YAM56STDANPRDO7ZFDBGSNHJQIYGGKSMOZAHOOS2S%2Cnative&source_amount=100&source_asset_code=USD&source_asset_issuer=GDUKMGUGDZQK6YHYA5Z6AY2G4XDSZPSZ3SW5UN3ARVMO6QSRDWP5YLEX&source_asset_type=credit_alphanum4",
 		endpoint,
 	)
+
+	pr.SplitRoutes = true
+	endpoint, err = pr.BuildURL()
+
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"paths/strict-send?destination_assets=EURT%3AGAP5LETOV6YIE62YAM56STDANPRDO7ZFDBGSNHJQIYGGKSMOZAHOOS2S%2Cnative&source_amount=100&source_asset_code=USD&source_asset_issuer=GDUKMGUGDZQK6YHYA5Z6AY2G4XDSZPSZ3SW5UN3ARVMO6QSRDWP5YLEX&source_asset_type=credit_alphanum4&split_routes=true",
+		endpoint,
+	)
 }
 func TestStrictSendPathsRequest(t *testing.T) {
 	hmock := httptest.NewClient()
@@ -14,16 +14,23 @@ import (
 // BuildURL creates the endpoint to be queried based on the data in the TransactionRequest struct.
 // If no data is set, it defaults to the build the URL for all transactions
 func (tr TransactionRequest) BuildURL() (endpoint string, err error) {
-	nParams := countParams(tr.ForAccount, tr.ForLedger, tr.ForLiquidityPool, tr.forTransactionHash)
+	nParams := countParams(tr.ForAccount, tr.ForMuxedAccount, tr.ForLedger, tr.ForLiquidityPool, tr.forTransactionHash)
 
 	if nParams > 1 {
 		return endpoint, errors.New("invalid request: too many parameters")
 	}
 
+	if err = checkMuxedAccount(tr.ForMuxedAccount); err != nil {
+		return endpoint, err
+	}
+
 	endpoint = "transactions"
 	if tr.ForAccount != "" {
 		endpoint = fmt.Sprintf("accounts/%s/transactions", tr.ForAccount)
 	}
+	if tr.ForMuxedAccount != "" {
+		endpoint = fmt.Sprintf("accounts/%s/transactions", tr.ForMuxedAccount)
+	}
 	if tr.ForClaimableBalance != "" {
 		endpoint = fmt.Sprintf("claimable_balances/%s/transactions", tr.ForClaimableBalance)
 	}
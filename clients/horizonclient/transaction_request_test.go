@@ -25,6 +25,25 @@ func TestTransactionRequestBuildUrl(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "accounts/GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU/transactions", endpoint)
 
+	tr = TransactionRequest{ForMuxedAccount: "MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4O"}
+	endpoint, err = tr.BuildURL()
+
+	// It should return valid muxed account transactions endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "accounts/MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4O/transactions", endpoint)
+
+	tr = TransactionRequest{ForMuxedAccount: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"}
+	_, err = tr.BuildURL()
+
+	// It should return an error for addresses which are not muxed
+	assert.EqualError(t, err, "invalid request: not a muxed account address")
+
+	tr = TransactionRequest{ForAccount: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", ForMuxedAccount: "MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4O"}
+	_, err = tr.BuildURL()
+
+	// It should return an error when both account filters are set
+	assert.EqualError(t, err, "invalid request: too many parameters")
+
 	tr = TransactionRequest{ForClaimableBalance: "00000000178826fbfe339e1f5c53417c6fedfe2c05e8bec14303143ec46b38981b09c3f9"}
 	endpoint, err = tr.BuildURL()
 
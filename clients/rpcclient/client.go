@@ -0,0 +1,390 @@
+package rpcclient
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/jhttp"
+
+	proto "github.com/stellar/go/protocols/rpc"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// Client represents a client that is capable of communicating with a Stellar
+// RPC server.
+type Client struct {
+	url  string
+	conn *jrpc2.Client
+}
+
+// NewClient creates a client for the Stellar RPC server at url. If httpClient
+// is nil, http.DefaultClient is used.
+func NewClient(url string, httpClient *http.Client) *Client {
+	var opts *jhttp.ChannelOptions
+	if httpClient != nil {
+		opts = &jhttp.ChannelOptions{Client: httpClient}
+	}
+	ch := jhttp.NewChannel(url, opts)
+	return &Client{url: url, conn: jrpc2.NewClient(ch, nil)}
+}
+
+// Close releases the resources held by the client.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	if err := c.conn.CallResult(ctx, method, params, result); err != nil {
+		return errors.Wrapf(err, "%s request to %s failed", method, c.url)
+	}
+	return nil
+}
+
+// GetHealth returns the health of the RPC server and the range of ledgers it
+// stores.
+func (c *Client) GetHealth(ctx context.Context) (proto.GetHealthResponse, error) {
+	var result proto.GetHealthResponse
+	if err := c.call(ctx, proto.GetHealthMethodName, nil, &result); err != nil {
+		return proto.GetHealthResponse{}, err
+	}
+	return result, nil
+}
+
+// GetNetwork returns the network passphrase and protocol version of the
+// network the RPC server is connected to.
+func (c *Client) GetNetwork(ctx context.Context) (proto.GetNetworkResponse, error) {
+	var result proto.GetNetworkResponse
+	if err := c.call(ctx, proto.GetNetworkMethodName, nil, &result); err != nil {
+		return proto.GetNetworkResponse{}, err
+	}
+	return result, nil
+}
+
+// GetLatestLedger returns the latest ledger known to the RPC server.
+func (c *Client) GetLatestLedger(ctx context.Context) (proto.GetLatestLedgerResponse, error) {
+	var result proto.GetLatestLedgerResponse
+	if err := c.call(ctx, proto.GetLatestLedgerMethodName, nil, &result); err != nil {
+		return proto.GetLatestLedgerResponse{}, err
+	}
+	return result, nil
+}
+
+// GetLedgerEntries returns the current value of the given ledger entries.
+// Entries which don't exist are omitted from the result.
+func (c *Client) GetLedgerEntries(ctx context.Context, keys []xdr.LedgerKey) (LedgerEntries, error) {
+	request := proto.GetLedgerEntriesRequest{Keys: make([]string, 0, len(keys))}
+	for _, key := range keys {
+		encoded, err := xdr.MarshalBase64(key)
+		if err != nil {
+			return LedgerEntries{}, errors.Wrap(err, "could not encode ledger key")
+		}
+		request.Keys = append(request.Keys, encoded)
+	}
+
+	var response proto.GetLedgerEntriesResponse
+	if err := c.call(ctx, proto.GetLedgerEntriesMethodName, request, &response); err != nil {
+		return LedgerEntries{}, err
+	}
+
+	result := LedgerEntries{
+		Entries:      make([]LedgerEntry, 0, len(response.Entries)),
+		LatestLedger: response.LatestLedger,
+	}
+	for _, entry := range response.Entries {
+		decoded := LedgerEntry{
+			LastModifiedLedger: entry.LastModifiedLedger,
+			LiveUntilLedgerSeq: entry.LiveUntilLedgerSeq,
+		}
+		if err := xdr.SafeUnmarshalBase64(entry.KeyXDR, &decoded.Key); err != nil {
+			return LedgerEntries{}, errors.Wrap(err, "could not decode ledger key")
+		}
+		if err := xdr.SafeUnmarshalBase64(entry.DataXDR, &decoded.Data); err != nil {
+			return LedgerEntries{}, errors.Wrap(err, "could not decode ledger entry data")
+		}
+		result.Entries = append(result.Entries, decoded)
+	}
+	return result, nil
+}
+
+// GetEvents returns the contract events matching the request.
+func (c *Client) GetEvents(ctx context.Context, request proto.GetEventsRequest) (EventsPage, error) {
+	request.Format = proto.FormatBase64
+	var response proto.GetEventsResponse
+	if err := c.call(ctx, proto.GetEventsMethodName, request, &response); err != nil {
+		return EventsPage{}, err
+	}
+
+	result := EventsPage{
+		Events:       make([]Event, 0, len(response.Events)),
+		LatestLedger: response.LatestLedger,
+		Cursor:       response.Cursor,
+	}
+	for _, event := range response.Events {
+		decoded := Event{
+			Type:                     event.EventType,
+			Ledger:                   event.Ledger,
+			LedgerClosedAt:           event.LedgerClosedAt,
+			ContractID:               event.ContractID,
+			ID:                       event.ID,
+			InSuccessfulContractCall: event.InSuccessfulContractCall,
+			TransactionHash:          event.TransactionHash,
+			Topic:                    make([]xdr.ScVal, len(event.TopicXDR)),
+		}
+		for i, topic := range event.TopicXDR {
+			if err := xdr.SafeUnmarshalBase64(topic, &decoded.Topic[i]); err != nil {
+				return EventsPage{}, errors.Wrapf(err, "could not decode topic of event %s", event.ID)
+			}
+		}
+		if err := xdr.SafeUnmarshalBase64(event.ValueXDR, &decoded.Value); err != nil {
+			return EventsPage{}, errors.Wrapf(err, "could not decode value of event %s", event.ID)
+		}
+		result.Events = append(result.Events, decoded)
+	}
+	return result, nil
+}
+
+// GetTransaction returns the transaction with the given hex encoded hash. If
+// the RPC server doesn't know the transaction, the returned status is
+// rpc.TransactionStatusNotFound.
+func (c *Client) GetTransaction(ctx context.Context, hash string) (TransactionResult, error) {
+	request := proto.GetTransactionRequest{Hash: hash, Format: proto.FormatBase64}
+	var response proto.GetTransactionResponse
+	if err := c.call(ctx, proto.GetTransactionMethodName, request, &response); err != nil {
+		return TransactionResult{}, err
+	}
+
+	result := TransactionResult{
+		LatestLedger:          response.LatestLedger,
+		LatestLedgerCloseTime: response.LatestLedgerCloseTime,
+		OldestLedger:          response.OldestLedger,
+		OldestLedgerCloseTime: response.OldestLedgerCloseTime,
+	}
+	var err error
+	result.Transaction, err = decodeTransaction(response.TransactionDetails, response.LedgerCloseTime)
+	if err != nil {
+		return TransactionResult{}, err
+	}
+	if result.TransactionHash == "" {
+		result.TransactionHash = hash
+	}
+	return result, nil
+}
+
+// GetTransactions returns a page of transactions starting at the ledger or
+// cursor of the request.
+func (c *Client) GetTransactions(ctx context.Context, request proto.GetTransactionsRequest) (TransactionsPage, error) {
+	request.Format = proto.FormatBase64
+	var response proto.GetTransactionsResponse
+	if err := c.call(ctx, proto.GetTransactionsMethodName, request, &response); err != nil {
+		return TransactionsPage{}, err
+	}
+
+	result := TransactionsPage{
+		Transactions:          make([]Transaction, 0, len(response.Transactions)),
+		LatestLedger:          response.LatestLedger,
+		LatestLedgerCloseTime: response.LatestLedgerCloseTime,
+		OldestLedger:          response.OldestLedger,
+		OldestLedgerCloseTime: response.OldestLedgerCloseTime,
+		Cursor:                response.Cursor,
+	}
+	for _, tx := range response.Transactions {
+		decoded, err := decodeTransaction(tx.TransactionDetails, tx.LedgerCloseTime)
+		if err != nil {
+			return TransactionsPage{}, err
+		}
+		result.Transactions = append(result.Transactions, decoded)
+	}
+	return result, nil
+}
+
+// GetLedgers returns a page of ledgers starting at the ledger or cursor of the
+// request.
+func (c *Client) GetLedgers(ctx context.Context, request proto.GetLedgersRequest) (LedgersPage, error) {
+	request.Format = proto.FormatBase64
+	var response proto.GetLedgersResponse
+	if err := c.call(ctx, proto.GetLedgersMethodName, request, &response); err != nil {
+		return LedgersPage{}, err
+	}
+
+	result := LedgersPage{
+		Ledgers:               make([]Ledger, 0, len(response.Ledgers)),
+		LatestLedger:          response.LatestLedger,
+		LatestLedgerCloseTime: response.LatestLedgerCloseTime,
+		OldestLedger:          response.OldestLedger,
+		OldestLedgerCloseTime: response.OldestLedgerCloseTime,
+		Cursor:                response.Cursor,
+	}
+	for _, ledger := range response.Ledgers {
+		decoded := Ledger{
+			Hash:            ledger.Hash,
+			Sequence:        ledger.Sequence,
+			LedgerCloseTime: ledger.LedgerCloseTime,
+		}
+		if err := xdr.SafeUnmarshalBase64(ledger.LedgerHeader, &decoded.Header); err != nil {
+			return LedgersPage{}, errors.Wrapf(err, "could not decode header of ledger %d", ledger.Sequence)
+		}
+		if err := xdr.SafeUnmarshalBase64(ledger.LedgerMetadata, &decoded.Meta); err != nil {
+			return LedgersPage{}, errors.Wrapf(err, "could not decode metadata of ledger %d", ledger.Sequence)
+		}
+		result.Ledgers = append(result.Ledgers, decoded)
+	}
+	return result, nil
+}
+
+// SimulateTransaction simulates the given transaction, which must contain a
+// single Soroban operation, and returns the resources, fees and
+// authorizations required to submit it. resourceConfig is optional.
+func (c *Client) SimulateTransaction(ctx context.Context, tx xdr.TransactionEnvelope, resourceConfig *proto.ResourceConfig) (SimulationResult, error) {
+	encoded, err := xdr.MarshalBase64(tx)
+	if err != nil {
+		return SimulationResult{}, errors.Wrap(err, "could not encode transaction")
+	}
+	request := proto.SimulateTransactionRequest{
+		Transaction:    encoded,
+		ResourceConfig: resourceConfig,
+		Format:         proto.FormatBase64,
+	}
+	var response proto.SimulateTransactionResponse
+	if err := c.call(ctx, proto.SimulateTransactionMethodName, request, &response); err != nil {
+		return SimulationResult{}, err
+	}
+
+	result := SimulationResult{
+		Error:          response.Error,
+		MinResourceFee: response.MinResourceFee,
+		LatestLedger:   response.LatestLedger,
+	}
+	if result.Events, err = decodeDiagnosticEvents(response.EventsXDR); err != nil {
+		return SimulationResult{}, err
+	}
+	if response.Error != "" {
+		return result, nil
+	}
+
+	if response.TransactionDataXDR != "" {
+		if err := xdr.SafeUnmarshalBase64(response.TransactionDataXDR, &result.TransactionData); err != nil {
+			return SimulationResult{}, errors.Wrap(err, "could not decode transaction data")
+		}
+	}
+	for _, hostFunctionResult := range response.Results {
+		decoded := HostFunctionResult{
+			Auth: make([]xdr.SorobanAuthorizationEntry, len(hostFunctionResult.AuthXDR)),
+		}
+		for i, auth := range hostFunctionResult.AuthXDR {
+			if err := xdr.SafeUnmarshalBase64(auth, &decoded.Auth[i]); err != nil {
+				return SimulationResult{}, errors.Wrap(err, "could not decode authorization entry")
+			}
+		}
+		if err := xdr.SafeUnmarshalBase64(hostFunctionResult.ReturnValueXDR, &decoded.ReturnValue); err != nil {
+			return SimulationResult{}, errors.Wrap(err, "could not decode return value")
+		}
+		result.Results = append(result.Results, decoded)
+	}
+	if response.RestorePreamble != nil {
+		result.RestorePreamble = &RestorePreamble{MinResourceFee: response.RestorePreamble.MinResourceFee}
+		if err := xdr.SafeUnmarshalBase64(response.RestorePreamble.TransactionDataXDR, &result.RestorePreamble.TransactionData); err != nil {
+			return SimulationResult{}, errors.Wrap(err, "could not decode restore preamble transaction data")
+		}
+	}
+	for _, change := range response.StateChanges {
+		decoded := LedgerEntryChange{Type: change.Type}
+		if err := xdr.SafeUnmarshalBase64(change.KeyXDR, &decoded.Key); err != nil {
+			return SimulationResult{}, errors.Wrap(err, "could not decode state change key")
+		}
+		if decoded.Before, err = decodeLedgerEntry(change.BeforeXDR); err != nil {
+			return SimulationResult{}, err
+		}
+		if decoded.After, err = decodeLedgerEntry(change.AfterXDR); err != nil {
+			return SimulationResult{}, err
+		}
+		result.StateChanges = append(result.StateChanges, decoded)
+	}
+	return result, nil
+}
+
+// SendTransaction submits the given transaction to the network. The
+// transaction is processed asynchronously, GetTransaction can be used to
+// follow its outcome.
+func (c *Client) SendTransaction(ctx context.Context, tx xdr.TransactionEnvelope) (SendTransactionResult, error) {
+	encoded, err := xdr.MarshalBase64(tx)
+	if err != nil {
+		return SendTransactionResult{}, errors.Wrap(err, "could not encode transaction")
+	}
+	request := proto.SendTransactionRequest{Transaction: encoded, Format: proto.FormatBase64}
+	var response proto.SendTransactionResponse
+	if err := c.call(ctx, proto.SendTransactionMethodName, request, &response); err != nil {
+		return SendTransactionResult{}, err
+	}
+
+	result := SendTransactionResult{
+		Status:                response.Status,
+		Hash:                  response.Hash,
+		LatestLedger:          response.LatestLedger,
+		LatestLedgerCloseTime: response.LatestLedgerCloseTime,
+	}
+	if response.ErrorResultXDR != "" {
+		result.ErrorResult = &xdr.TransactionResult{}
+		if err := xdr.SafeUnmarshalBase64(response.ErrorResultXDR, result.ErrorResult); err != nil {
+			return SendTransactionResult{}, errors.Wrap(err, "could not decode error result")
+		}
+	}
+	if result.DiagnosticEvents, err = decodeDiagnosticEvents(response.DiagnosticEventsXDR); err != nil {
+		return SendTransactionResult{}, err
+	}
+	return result, nil
+}
+
+func decodeTransaction(details proto.TransactionDetails, ledgerCloseTime int64) (Transaction, error) {
+	tx := Transaction{
+		Status:           details.Status,
+		TransactionHash:  details.TransactionHash,
+		ApplicationOrder: details.ApplicationOrder,
+		FeeBump:          details.FeeBump,
+		Ledger:           details.Ledger,
+		LedgerCloseTime:  ledgerCloseTime,
+	}
+	if details.Status == proto.TransactionStatusNotFound {
+		return tx, nil
+	}
+
+	if err := xdr.SafeUnmarshalBase64(details.EnvelopeXDR, &tx.Envelope); err != nil {
+		return Transaction{}, errors.Wrapf(err, "could not decode envelope of transaction %s", details.TransactionHash)
+	}
+	if err := xdr.SafeUnmarshalBase64(details.ResultXDR, &tx.Result); err != nil {
+		return Transaction{}, errors.Wrapf(err, "could not decode result of transaction %s", details.TransactionHash)
+	}
+	if err := xdr.SafeUnmarshalBase64(details.ResultMetaXDR, &tx.Meta); err != nil {
+		return Transaction{}, errors.Wrapf(err, "could not decode meta of transaction %s", details.TransactionHash)
+	}
+	var err error
+	if tx.DiagnosticEvents, err = decodeDiagnosticEvents(details.DiagnosticEventsXDR); err != nil {
+		return Transaction{}, err
+	}
+	return tx, nil
+}
+
+func decodeDiagnosticEvents(encoded []string) ([]xdr.DiagnosticEvent, error) {
+	if len(encoded) == 0 {
+		return nil, nil
+	}
+	events := make([]xdr.DiagnosticEvent, len(encoded))
+	for i, event := range encoded {
+		if err := xdr.SafeUnmarshalBase64(event, &events[i]); err != nil {
+			return nil, errors.Wrap(err, "could not decode diagnostic event")
+		}
+	}
+	return events, nil
+}
+
+func decodeLedgerEntry(encoded *string) (*xdr.LedgerEntry, error) {
+	if encoded == nil {
+		return nil, nil
+	}
+	var entry xdr.LedgerEntry
+	if err := xdr.SafeUnmarshalBase64(*encoded, &entry); err != nil {
+		return nil, errors.Wrap(err, "could not decode ledger entry")
+	}
+	return &entry, nil
+}
@@ -0,0 +1,531 @@
+package rpcclient
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/handler"
+	"github.com/creachadair/jrpc2/jhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	proto "github.com/stellar/go/protocols/rpc"
+	"github.com/stellar/go/xdr"
+)
+
+const testAccount = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+
+func newTestClient(t *testing.T, methods handler.Map) *Client {
+	bridge := jhttp.NewBridge(methods, nil)
+	server := httptest.NewServer(bridge)
+	client := NewClient(server.URL, nil)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+		bridge.Close()
+	})
+	return client
+}
+
+func mustMarshalBase64(t *testing.T, v interface{}) string {
+	encoded, err := xdr.MarshalBase64(v)
+	require.NoError(t, err)
+	return encoded
+}
+
+func symbol(s string) xdr.ScVal {
+	sym := xdr.ScSymbol(s)
+	return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}
+}
+
+func testTransactionEnvelope() xdr.TransactionEnvelope {
+	return xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: xdr.MustMuxedAddress(testAccount),
+				Fee:           100,
+				SeqNum:        1,
+				Operations: []xdr.Operation{
+					{
+						Body: xdr.OperationBody{
+							Type:           xdr.OperationTypeBumpSequence,
+							BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: 2},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func bumpSequenceResult() xdr.OperationResult {
+	return xdr.OperationResult{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type:          xdr.OperationTypeBumpSequence,
+			BumpSeqResult: &xdr.BumpSequenceResult{Code: xdr.BumpSequenceResultCodeBumpSequenceSuccess},
+		},
+	}
+}
+
+func TestGetHealth(t *testing.T) {
+	client := newTestClient(t, handler.Map{
+		"getHealth": handler.New(func(ctx context.Context) (proto.GetHealthResponse, error) {
+			return proto.GetHealthResponse{
+				Status:                "healthy",
+				LatestLedger:          100,
+				OldestLedger:          10,
+				LedgerRetentionWindow: 91,
+			}, nil
+		}),
+	})
+
+	health, err := client.GetHealth(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, proto.GetHealthResponse{
+		Status:                "healthy",
+		LatestLedger:          100,
+		OldestLedger:          10,
+		LedgerRetentionWindow: 91,
+	}, health)
+}
+
+func TestRequestError(t *testing.T) {
+	client := newTestClient(t, handler.Map{
+		"getLatestLedger": handler.New(func(ctx context.Context) (proto.GetLatestLedgerResponse, error) {
+			return proto.GetLatestLedgerResponse{}, jrpc2.Errorf(jrpc2.InternalError, "database is down")
+		}),
+	})
+
+	_, err := client.GetLatestLedger(context.Background())
+	assert.ErrorContains(t, err, "getLatestLedger request to")
+	assert.ErrorContains(t, err, "database is down")
+
+	_, err = client.GetNetwork(context.Background())
+	assert.ErrorContains(t, err, "getNetwork request to")
+}
+
+func TestGetLedgerEntries(t *testing.T) {
+	key := xdr.LedgerKey{
+		Type:    xdr.LedgerEntryTypeAccount,
+		Account: &xdr.LedgerKeyAccount{AccountId: xdr.MustAddress(testAccount)},
+	}
+	data := xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeAccount,
+		Account: &xdr.AccountEntry{
+			AccountId: xdr.MustAddress(testAccount),
+			Balance:   1000,
+		},
+	}
+	liveUntil := uint32(500)
+
+	client := newTestClient(t, handler.Map{
+		"getLedgerEntries": handler.New(func(ctx context.Context, request proto.GetLedgerEntriesRequest) (proto.GetLedgerEntriesResponse, error) {
+			assert.Equal(t, []string{mustMarshalBase64(t, key)}, request.Keys)
+			return proto.GetLedgerEntriesResponse{
+				Entries: []proto.LedgerEntryResult{
+					{
+						KeyXDR:             request.Keys[0],
+						DataXDR:            mustMarshalBase64(t, data),
+						LastModifiedLedger: 90,
+						LiveUntilLedgerSeq: &liveUntil,
+					},
+				},
+				LatestLedger: 100,
+			}, nil
+		}),
+	})
+
+	entries, err := client.GetLedgerEntries(context.Background(), []xdr.LedgerKey{key})
+	require.NoError(t, err)
+	assert.Equal(t, LedgerEntries{
+		Entries: []LedgerEntry{
+			{
+				Key:                key,
+				Data:               data,
+				LastModifiedLedger: 90,
+				LiveUntilLedgerSeq: &liveUntil,
+			},
+		},
+		LatestLedger: 100,
+	}, entries)
+}
+
+func TestGetEvents(t *testing.T) {
+	topic := symbol("transfer")
+	value := symbol("value")
+	wildcard := proto.WildCardExactOne
+
+	client := newTestClient(t, handler.Map{
+		"getEvents": handler.New(func(ctx context.Context, request proto.GetEventsRequest) (proto.GetEventsResponse, error) {
+			assert.Equal(t, uint32(50), request.StartLedger)
+			assert.Equal(t, &proto.PaginationOptions{Limit: 10}, request.Pagination)
+			assert.Equal(t, []proto.EventFilter{
+				{
+					EventType:   proto.EventTypeSet{proto.EventTypeContract: nil},
+					ContractIDs: []string{"CCJZ5DGASBWQXR5MPFCJXMBI333XE5U3FSJTNQU7RIKE3P5GN2K2WYD5"},
+					Topics: []proto.TopicFilter{
+						{{ScVal: &topic}, {Wildcard: &wildcard}},
+					},
+				},
+			}, request.Filters)
+			return proto.GetEventsResponse{
+				Events: []proto.EventInfo{
+					{
+						EventType:                proto.EventTypeContract,
+						Ledger:                   55,
+						LedgerClosedAt:           "2024-01-01T00:00:00Z",
+						ContractID:               "CCJZ5DGASBWQXR5MPFCJXMBI333XE5U3FSJTNQU7RIKE3P5GN2K2WYD5",
+						ID:                       "0000236223164416-0000000000",
+						InSuccessfulContractCall: true,
+						TransactionHash:          "abcd",
+						TopicXDR:                 []string{mustMarshalBase64(t, topic)},
+						ValueXDR:                 mustMarshalBase64(t, value),
+					},
+				},
+				LatestLedger: 100,
+				Cursor:       "0000236223164416-0000000000",
+			}, nil
+		}),
+	})
+
+	events, err := client.GetEvents(context.Background(), proto.GetEventsRequest{
+		StartLedger: 50,
+		Filters: []proto.EventFilter{
+			{
+				EventType:   proto.EventTypeSet{proto.EventTypeContract: nil},
+				ContractIDs: []string{"CCJZ5DGASBWQXR5MPFCJXMBI333XE5U3FSJTNQU7RIKE3P5GN2K2WYD5"},
+				Topics: []proto.TopicFilter{
+					{{ScVal: &topic}, {Wildcard: &wildcard}},
+				},
+			},
+		},
+		Pagination: &proto.PaginationOptions{Limit: 10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, EventsPage{
+		Events: []Event{
+			{
+				Type:                     proto.EventTypeContract,
+				Ledger:                   55,
+				LedgerClosedAt:           "2024-01-01T00:00:00Z",
+				ContractID:               "CCJZ5DGASBWQXR5MPFCJXMBI333XE5U3FSJTNQU7RIKE3P5GN2K2WYD5",
+				ID:                       "0000236223164416-0000000000",
+				InSuccessfulContractCall: true,
+				TransactionHash:          "abcd",
+				Topic:                    []xdr.ScVal{topic},
+				Value:                    value,
+			},
+		},
+		LatestLedger: 100,
+		Cursor:       "0000236223164416-0000000000",
+	}, events)
+}
+
+func TestGetTransaction(t *testing.T) {
+	envelope := testTransactionEnvelope()
+	result := xdr.TransactionResult{
+		FeeCharged: 100,
+		Result: xdr.TransactionResultResult{
+			Code:    xdr.TransactionResultCodeTxSuccess,
+			Results: &[]xdr.OperationResult{bumpSequenceResult()},
+		},
+	}
+	meta := xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{}}
+
+	client := newTestClient(t, handler.Map{
+		"getTransaction": handler.New(func(ctx context.Context, request proto.GetTransactionRequest) (proto.GetTransactionResponse, error) {
+			response := proto.GetTransactionResponse{
+				LatestLedger:          100,
+				LatestLedgerCloseTime: 1000,
+				OldestLedger:          10,
+				OldestLedgerCloseTime: 100,
+			}
+			if request.Hash != "abcd" {
+				response.Status = proto.TransactionStatusNotFound
+				return response, nil
+			}
+			response.TransactionDetails = proto.TransactionDetails{
+				Status:           proto.TransactionStatusSuccess,
+				TransactionHash:  "abcd",
+				ApplicationOrder: 1,
+				EnvelopeXDR:      mustMarshalBase64(t, envelope),
+				ResultXDR:        mustMarshalBase64(t, result),
+				ResultMetaXDR:    mustMarshalBase64(t, meta),
+				Ledger:           90,
+			}
+			response.LedgerCloseTime = 900
+			return response, nil
+		}),
+	})
+
+	tx, err := client.GetTransaction(context.Background(), "abcd")
+	require.NoError(t, err)
+	assert.Equal(t, TransactionResult{
+		Transaction: Transaction{
+			Status:           proto.TransactionStatusSuccess,
+			TransactionHash:  "abcd",
+			ApplicationOrder: 1,
+			Envelope:         envelope,
+			Result:           result,
+			Meta:             meta,
+			Ledger:           90,
+			LedgerCloseTime:  900,
+		},
+		LatestLedger:          100,
+		LatestLedgerCloseTime: 1000,
+		OldestLedger:          10,
+		OldestLedgerCloseTime: 100,
+	}, tx)
+
+	tx, err = client.GetTransaction(context.Background(), "ef01")
+	require.NoError(t, err)
+	assert.Equal(t, proto.TransactionStatusNotFound, tx.Status)
+	assert.Equal(t, "ef01", tx.TransactionHash)
+}
+
+func TestGetTransactions(t *testing.T) {
+	envelope := testTransactionEnvelope()
+	result := xdr.TransactionResult{
+		FeeCharged: 100,
+		Result: xdr.TransactionResultResult{
+			Code:    xdr.TransactionResultCodeTxFailed,
+			Results: &[]xdr.OperationResult{bumpSequenceResult()},
+		},
+	}
+	meta := xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{}}
+
+	client := newTestClient(t, handler.Map{
+		"getTransactions": handler.New(func(ctx context.Context, request proto.GetTransactionsRequest) (proto.GetTransactionsResponse, error) {
+			assert.Equal(t, &proto.PaginationOptions{Cursor: "123"}, request.Pagination)
+			return proto.GetTransactionsResponse{
+				Transactions: []proto.TransactionInfo{
+					{
+						TransactionDetails: proto.TransactionDetails{
+							Status:          proto.TransactionStatusFailed,
+							TransactionHash: "abcd",
+							EnvelopeXDR:     mustMarshalBase64(t, envelope),
+							ResultXDR:       mustMarshalBase64(t, result),
+							ResultMetaXDR:   mustMarshalBase64(t, meta),
+							Ledger:          90,
+						},
+						LedgerCloseTime: 900,
+					},
+				},
+				LatestLedger: 100,
+				Cursor:       "456",
+			}, nil
+		}),
+	})
+
+	page, err := client.GetTransactions(context.Background(), proto.GetTransactionsRequest{
+		Pagination: &proto.PaginationOptions{Cursor: "123"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "456", page.Cursor)
+	require.Len(t, page.Transactions, 1)
+	assert.Equal(t, Transaction{
+		Status:          proto.TransactionStatusFailed,
+		TransactionHash: "abcd",
+		Envelope:        envelope,
+		Result:          result,
+		Meta:            meta,
+		Ledger:          90,
+		LedgerCloseTime: 900,
+	}, page.Transactions[0])
+}
+
+func TestGetLedgers(t *testing.T) {
+	header := xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: 90}}
+	meta := xdr.LedgerCloseMeta{V: 0, V0: &xdr.LedgerCloseMetaV0{LedgerHeader: header}}
+
+	client := newTestClient(t, handler.Map{
+		"getLedgers": handler.New(func(ctx context.Context, request proto.GetLedgersRequest) (proto.GetLedgersResponse, error) {
+			assert.Equal(t, uint32(90), request.StartLedger)
+			assert.Equal(t, proto.FormatBase64, request.Format)
+			return proto.GetLedgersResponse{
+				Ledgers: []proto.LedgerInfo{
+					{
+						Hash:            "abcd",
+						Sequence:        90,
+						LedgerCloseTime: 900,
+						LedgerHeader:    mustMarshalBase64(t, header),
+						LedgerMetadata:  mustMarshalBase64(t, meta),
+					},
+				},
+				LatestLedger: 100,
+				OldestLedger: 10,
+				Cursor:       "90",
+			}, nil
+		}),
+	})
+
+	page, err := client.GetLedgers(context.Background(), proto.GetLedgersRequest{StartLedger: 90})
+	require.NoError(t, err)
+	assert.Equal(t, LedgersPage{
+		Ledgers: []Ledger{
+			{
+				Hash:            "abcd",
+				Sequence:        90,
+				LedgerCloseTime: 900,
+				Header:          header,
+				Meta:            meta,
+			},
+		},
+		LatestLedger: 100,
+		OldestLedger: 10,
+		Cursor:       "90",
+	}, page)
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	envelope := testTransactionEnvelope()
+	transactionData := xdr.SorobanTransactionData{
+		Resources: xdr.SorobanResources{Instructions: 1000},
+	}
+	returnValue := symbol("ok")
+	contractID := xdr.Hash{1}
+	auth := xdr.SorobanAuthorizationEntry{
+		Credentials: xdr.SorobanCredentials{Type: xdr.SorobanCredentialsTypeSorobanCredentialsSourceAccount},
+		RootInvocation: xdr.SorobanAuthorizedInvocation{
+			Function: xdr.SorobanAuthorizedFunction{
+				Type: xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn,
+				ContractFn: &xdr.InvokeContractArgs{
+					ContractAddress: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+					FunctionName:    "hello",
+				},
+			},
+		},
+	}
+	key := xdr.LedgerKey{
+		Type:    xdr.LedgerEntryTypeAccount,
+		Account: &xdr.LedgerKeyAccount{AccountId: xdr.MustAddress(testAccount)},
+	}
+	after := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{AccountId: xdr.MustAddress(testAccount)},
+		},
+	}
+	afterXDR := mustMarshalBase64(t, after)
+
+	client := newTestClient(t, handler.Map{
+		"simulateTransaction": handler.New(func(ctx context.Context, request proto.SimulateTransactionRequest) (proto.SimulateTransactionResponse, error) {
+			assert.Equal(t, mustMarshalBase64(t, envelope), request.Transaction)
+			assert.Equal(t, &proto.ResourceConfig{InstructionLeeway: 10}, request.ResourceConfig)
+			return proto.SimulateTransactionResponse{
+				TransactionDataXDR: mustMarshalBase64(t, transactionData),
+				MinResourceFee:     5000,
+				Results: []proto.SimulateHostFunctionResult{
+					{
+						AuthXDR:        []string{mustMarshalBase64(t, auth)},
+						ReturnValueXDR: mustMarshalBase64(t, returnValue),
+					},
+				},
+				RestorePreamble: &proto.RestorePreamble{
+					TransactionDataXDR: mustMarshalBase64(t, transactionData),
+					MinResourceFee:     300,
+				},
+				StateChanges: []proto.LedgerEntryChange{
+					{
+						Type:     proto.LedgerEntryChangeTypeCreated,
+						KeyXDR:   mustMarshalBase64(t, key),
+						AfterXDR: &afterXDR,
+					},
+				},
+				LatestLedger: 100,
+			}, nil
+		}),
+	})
+
+	simulation, err := client.SimulateTransaction(context.Background(), envelope, &proto.ResourceConfig{InstructionLeeway: 10})
+	require.NoError(t, err)
+	assert.Equal(t, SimulationResult{
+		TransactionData: transactionData,
+		MinResourceFee:  5000,
+		Results: []HostFunctionResult{
+			{
+				Auth:        []xdr.SorobanAuthorizationEntry{auth},
+				ReturnValue: returnValue,
+			},
+		},
+		RestorePreamble: &RestorePreamble{
+			TransactionData: transactionData,
+			MinResourceFee:  300,
+		},
+		StateChanges: []LedgerEntryChange{
+			{
+				Type:  proto.LedgerEntryChangeTypeCreated,
+				Key:   key,
+				After: &after,
+			},
+		},
+		LatestLedger: 100,
+	}, simulation)
+}
+
+func TestSimulateTransactionError(t *testing.T) {
+	event := xdr.DiagnosticEvent{
+		Event: xdr.ContractEvent{
+			Type: xdr.ContractEventTypeDiagnostic,
+			Body: xdr.ContractEventBody{
+				V:  0,
+				V0: &xdr.ContractEventV0{Data: symbol("error")},
+			},
+		},
+	}
+
+	client := newTestClient(t, handler.Map{
+		"simulateTransaction": handler.New(func(ctx context.Context, request proto.SimulateTransactionRequest) (proto.SimulateTransactionResponse, error) {
+			return proto.SimulateTransactionResponse{
+				Error:        "HostError: Error(WasmVm, InvalidAction)",
+				EventsXDR:    []string{mustMarshalBase64(t, event)},
+				LatestLedger: 100,
+			}, nil
+		}),
+	})
+
+	simulation, err := client.SimulateTransaction(context.Background(), testTransactionEnvelope(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, SimulationResult{
+		Error:        "HostError: Error(WasmVm, InvalidAction)",
+		Events:       []xdr.DiagnosticEvent{event},
+		LatestLedger: 100,
+	}, simulation)
+}
+
+func TestSendTransaction(t *testing.T) {
+	envelope := testTransactionEnvelope()
+	errorResult := xdr.TransactionResult{
+		FeeCharged: 100,
+		Result: xdr.TransactionResultResult{
+			Code: xdr.TransactionResultCodeTxBadSeq,
+		},
+	}
+
+	client := newTestClient(t, handler.Map{
+		"sendTransaction": handler.New(func(ctx context.Context, request proto.SendTransactionRequest) (proto.SendTransactionResponse, error) {
+			assert.Equal(t, mustMarshalBase64(t, envelope), request.Transaction)
+			return proto.SendTransactionResponse{
+				Status:                proto.SendTransactionStatusError,
+				Hash:                  "abcd",
+				ErrorResultXDR:        mustMarshalBase64(t, errorResult),
+				LatestLedger:          100,
+				LatestLedgerCloseTime: 1000,
+			}, nil
+		}),
+	})
+
+	result, err := client.SendTransaction(context.Background(), envelope)
+	require.NoError(t, err)
+	assert.Equal(t, SendTransactionResult{
+		Status:                proto.SendTransactionStatusError,
+		Hash:                  "abcd",
+		ErrorResult:           &errorResult,
+		LatestLedger:          100,
+		LatestLedgerCloseTime: 1000,
+	}, result)
+}
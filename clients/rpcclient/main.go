@@ -0,0 +1,158 @@
+/*
+Package rpcclient provides client access to a Stellar RPC server through its
+JSON-RPC API.
+
+The request and response types of the API live in the protocols/rpc package.
+Methods of Client send those requests and decode the base64 encoded XDR values
+of the responses into the corresponding xdr types.
+*/
+package rpcclient
+
+import (
+	"github.com/stellar/go/xdr"
+)
+
+// LedgerEntry is a ledger entry returned by GetLedgerEntries.
+type LedgerEntry struct {
+	Key                xdr.LedgerKey
+	Data               xdr.LedgerEntryData
+	LastModifiedLedger uint32
+	// LiveUntilLedgerSeq is only set for entries that have an associated ttl
+	// ledger entry.
+	LiveUntilLedgerSeq *uint32
+}
+
+// LedgerEntries is the result of GetLedgerEntries.
+type LedgerEntries struct {
+	Entries      []LedgerEntry
+	LatestLedger uint32
+}
+
+// Event is a contract event returned by GetEvents.
+type Event struct {
+	Type                     string
+	Ledger                   uint32
+	LedgerClosedAt           string
+	ContractID               string
+	ID                       string
+	InSuccessfulContractCall bool
+	TransactionHash          string
+	Topic                    []xdr.ScVal
+	Value                    xdr.ScVal
+}
+
+// EventsPage is the result of GetEvents.
+type EventsPage struct {
+	Events       []Event
+	LatestLedger uint32
+	// Cursor can be used to request the next page of events.
+	Cursor string
+}
+
+// Transaction is a transaction returned by GetTransaction or GetTransactions.
+type Transaction struct {
+	// Status is one of rpc.TransactionStatusSuccess,
+	// rpc.TransactionStatusFailed or rpc.TransactionStatusNotFound. The
+	// fields below are not set when the transaction was not found.
+	Status           string
+	TransactionHash  string
+	ApplicationOrder int32
+	FeeBump          bool
+	Envelope         xdr.TransactionEnvelope
+	Result           xdr.TransactionResult
+	Meta             xdr.TransactionMeta
+	DiagnosticEvents []xdr.DiagnosticEvent
+	Ledger           uint32
+	LedgerCloseTime  int64
+}
+
+// TransactionResult is the result of GetTransaction.
+type TransactionResult struct {
+	Transaction
+	LatestLedger          uint32
+	LatestLedgerCloseTime int64
+	OldestLedger          uint32
+	OldestLedgerCloseTime int64
+}
+
+// TransactionsPage is the result of GetTransactions.
+type TransactionsPage struct {
+	Transactions          []Transaction
+	LatestLedger          uint32
+	LatestLedgerCloseTime int64
+	OldestLedger          uint32
+	OldestLedgerCloseTime int64
+	// Cursor can be used to request the next page of transactions.
+	Cursor string
+}
+
+// Ledger is a ledger returned by GetLedgers.
+type Ledger struct {
+	Hash            string
+	Sequence        uint32
+	LedgerCloseTime int64
+	Header          xdr.LedgerHeaderHistoryEntry
+	Meta            xdr.LedgerCloseMeta
+}
+
+// LedgersPage is the result of GetLedgers.
+type LedgersPage struct {
+	Ledgers               []Ledger
+	LatestLedger          uint32
+	LatestLedgerCloseTime int64
+	OldestLedger          uint32
+	OldestLedgerCloseTime int64
+	// Cursor can be used to request the next page of ledgers.
+	Cursor string
+}
+
+// HostFunctionResult is the result of a simulated host function invocation.
+type HostFunctionResult struct {
+	Auth        []xdr.SorobanAuthorizationEntry
+	ReturnValue xdr.ScVal
+}
+
+// RestorePreamble describes the RestoreFootprint operation needed before a
+// simulated transaction can be submitted.
+type RestorePreamble struct {
+	TransactionData xdr.SorobanTransactionData
+	MinResourceFee  int64
+}
+
+// LedgerEntryChange is a ledger entry change resulting from a simulation.
+// Before is nil for created entries and After is nil for deleted entries.
+type LedgerEntryChange struct {
+	Type   string
+	Key    xdr.LedgerKey
+	Before *xdr.LedgerEntry
+	After  *xdr.LedgerEntry
+}
+
+// SimulationResult is the result of SimulateTransaction.
+type SimulationResult struct {
+	// Error is set if the simulation failed, in which case only Events and
+	// LatestLedger are meaningful.
+	Error           string
+	TransactionData xdr.SorobanTransactionData
+	Events          []xdr.DiagnosticEvent
+	MinResourceFee  int64
+	// Results contains a single element when the transaction invokes a host
+	// function.
+	Results         []HostFunctionResult
+	RestorePreamble *RestorePreamble
+	StateChanges    []LedgerEntryChange
+	LatestLedger    uint32
+}
+
+// SendTransactionResult is the result of SendTransaction.
+type SendTransactionResult struct {
+	// Status is one of the rpc.SendTransactionStatus* values.
+	Status string
+	Hash   string
+	// ErrorResult and DiagnosticEvents are only set when Status is
+	// rpc.SendTransactionStatusError.
+	ErrorResult           *xdr.TransactionResult
+	DiagnosticEvents      []xdr.DiagnosticEvent
+	LatestLedger          uint32
+	LatestLedgerCloseTime int64
+}
@@ -723,6 +723,27 @@ func (graph *OrderBookGraph) findPathsWithLock(
 	graph.lock.RLock()
 	defer graph.lock.RUnlock()
 
+	paths, err := graph.findPaths(
+		ctx, maxPathLength, destinationAsset, destinationAmount, sourceAccountID, sourceAssets, sourceAssetBalances,
+		validateSourceBalance, includePools, nil,
+	)
+	return paths, graph.lastLedger, err
+}
+
+// findPaths searches for the payment paths of FindPaths, ignoring the trading
+// pairs in `excludedPairs`. The caller must hold the graph lock.
+func (graph *OrderBookGraph) findPaths(
+	ctx context.Context,
+	maxPathLength int,
+	destinationAsset xdr.Asset,
+	destinationAmount xdr.Int64,
+	sourceAccountID *xdr.AccountId,
+	sourceAssets []xdr.Asset,
+	sourceAssetBalances []xdr.Int64,
+	validateSourceBalance bool,
+	includePools bool,
+	excludedPairs map[tradingPair]bool,
+) ([]Path, error) {
 	destinationAssetString := destinationAsset.String()
 	sourceAssetsMap := make(map[int32]xdr.Int64, len(sourceAssets))
 	for i, sourceAsset := range sourceAssets {
@@ -735,7 +756,7 @@ func (graph *OrderBookGraph) findPathsWithLock(
 	}
 	destinationAssetID, ok := graph.assetStringToID[destinationAssetString]
 	if !ok || len(sourceAssetsMap) == 0 {
-		return []Path{}, graph.lastLedger, nil
+		return []Path{}, nil
 	}
 	searchState := &sellingGraphSearchState{
 		graph:                  graph,
@@ -749,12 +770,12 @@ func (graph *OrderBookGraph) findPathsWithLock(
 	}
 	err := search(
 		ctx,
-		searchState,
+		excludePairs(searchState, excludedPairs),
 		maxPathLength,
 		destinationAssetID,
 		destinationAmount,
 	)
-	return searchState.paths, graph.lastLedger, err
+	return searchState.paths, err
 }
 
 type sortablePaths struct {
@@ -817,6 +838,23 @@ func (graph *OrderBookGraph) findFixedPathsWithLock(
 	graph.lock.RLock()
 	defer graph.lock.RUnlock()
 
+	paths, err := graph.findFixedPaths(
+		ctx, maxPathLength, sourceAsset, amountToSpend, destinationAssets, includePools, nil,
+	)
+	return paths, graph.lastLedger, err
+}
+
+// findFixedPaths searches for the payment paths of FindFixedPaths, ignoring
+// the trading pairs in `excludedPairs`. The caller must hold the graph lock.
+func (graph *OrderBookGraph) findFixedPaths(
+	ctx context.Context,
+	maxPathLength int,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAssets []xdr.Asset,
+	includePools bool,
+	excludedPairs map[tradingPair]bool,
+) ([]Path, error) {
 	target := make(map[int32]bool, len(destinationAssets))
 	for _, destinationAsset := range destinationAssets {
 		destinationAssetString := destinationAsset.String()
@@ -830,7 +868,7 @@ func (graph *OrderBookGraph) findFixedPathsWithLock(
 	sourceAssetString := sourceAsset.String()
 	sourceAssetID, ok := graph.assetStringToID[sourceAssetString]
 	if !ok || len(target) == 0 {
-		return []Path{}, graph.lastLedger, nil
+		return []Path{}, nil
 	}
 	searchState := &buyingGraphSearchState{
 		graph:             graph,
@@ -842,12 +880,12 @@ func (graph *OrderBookGraph) findFixedPathsWithLock(
 	}
 	err := search(
 		ctx,
-		searchState,
+		excludePairs(searchState, excludedPairs),
 		maxPathLength,
 		sourceAssetID,
 		amountToSpend,
 	)
-	return searchState.paths, graph.lastLedger, err
+	return searchState.paths, err
 }
 
 // compareSourceAsset will group payment paths by `SourceAsset`
@@ -415,6 +415,43 @@ func (state *buyingGraphSearchState) consumePool(
 	return makeTrade(pool, currentAsset, tradeTypeDeposit, currentAssetAmount)
 }
 
+// excludingSearchState wraps a searchState and ignores the venues of some
+// trading pairs.
+type excludingSearchState struct {
+	searchState
+	// excludedPairs holds the excluded trading pairs, in asset id order.
+	excludedPairs map[tradingPair]bool
+}
+
+// excludePairs returns a searchState which ignores the venues of the given
+// trading pairs (in asset id order), or the given state if there are none.
+func excludePairs(state searchState, excludedPairs map[tradingPair]bool) searchState {
+	if len(excludedPairs) == 0 {
+		return state
+	}
+	return &excludingSearchState{searchState: state, excludedPairs: excludedPairs}
+}
+
+func (state *excludingSearchState) venues(currentAsset int32) edgeSet {
+	edges := state.searchState.venues(currentAsset)
+	filtered := make(edgeSet, 0, len(edges))
+	for _, e := range edges {
+		if !state.excludedPairs[orderedTradingPair(currentAsset, e.key)] {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// orderedTradingPair returns the trading pair of the given assets in asset id
+// order.
+func orderedTradingPair(a, b int32) tradingPair {
+	if b < a {
+		a, b = b, a
+	}
+	return tradingPair{buyingAsset: a, sellingAsset: b}
+}
+
 func consumeOffersForSellingAsset(
 	offers []xdr.OfferEntry,
 	ignoreOffersFrom *xdr.AccountId,
@@ -0,0 +1,151 @@
+package orderbook
+
+import (
+	"github.com/stellar/go/price"
+	"github.com/stellar/go/xdr"
+)
+
+// SimulatePathPayment evaluates a path payment through the given assets, from
+// the source asset (the first one) to the destination asset (the last one),
+// against the offers and liquidity pools of the graph without modifying it.
+//
+// In strict receive mode `amount` is the amount of the destination asset
+// delivered and the amount of the source asset needed is returned. In strict
+// send mode `amount` is the amount of the source asset spent and the amount
+// of the destination asset delivered is returned. The returned bool is false
+// if the order book cannot fill the payment. Offers created by
+// `sourceAccountID` are ignored in strict receive mode, like FindPaths does.
+func (graph *OrderBookGraph) SimulatePathPayment(
+	assets []xdr.Asset,
+	amount xdr.Int64,
+	strictReceive bool,
+	sourceAccountID *xdr.AccountId,
+	includePools bool,
+) (xdr.Int64, bool, error) {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	// consecutive hops with the same asset are a no-op, like in stellar-core
+	var assetStrings []string
+	for _, asset := range assets {
+		assetString := asset.String()
+		if len(assetStrings) > 0 && assetStrings[len(assetStrings)-1] == assetString {
+			continue
+		}
+		assetStrings = append(assetStrings, assetString)
+	}
+	if len(assetStrings) < 2 {
+		return amount, amount > 0, nil
+	}
+
+	ids := make([]int32, len(assetStrings))
+	for i, assetString := range assetStrings {
+		id, ok := graph.assetStringToID[assetString]
+		if !ok {
+			return 0, false, nil
+		}
+		ids[i] = id
+	}
+
+	simulation := &splitSimulation{
+		graph:            graph,
+		strictReceive:    strictReceive,
+		ignoreOffersFrom: sourceAccountID,
+		includePools:     includePools,
+	}
+	simulation.reset()
+	result, err := simulation.executeRoute(ids, amount, false)
+	if err != nil {
+		return 0, false, err
+	}
+	if result <= 0 {
+		return 0, false, nil
+	}
+	return result, true, nil
+}
+
+// OfferCrossing is the outcome of crossing a new offer with the offers of the
+// order book graph.
+type OfferCrossing struct {
+	// AmountSold is the amount of the selling asset of the new offer which is
+	// exchanged immediately.
+	AmountSold xdr.Int64
+	// AmountBought is the amount of the buying asset received in exchange.
+	AmountBought xdr.Int64
+	// CrossesSelf is true if the new offer would cross an offer created by
+	// the same account, which the network rejects.
+	CrossesSelf bool
+}
+
+// SimulateOffer crosses an offer selling `amount` of `selling` in exchange for
+// `buying` at `offerPrice` (units of `buying` per unit of `selling`) with the
+// offers of the graph, without modifying it. Passive offers do not cross
+// offers with the same price and, like in stellar-core, offers never cross
+// liquidity pools.
+func (graph *OrderBookGraph) SimulateOffer(
+	seller xdr.AccountId,
+	selling, buying xdr.Asset,
+	amount xdr.Int64,
+	offerPrice xdr.Price,
+	passive bool,
+) (OfferCrossing, error) {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	var result OfferCrossing
+	sellingID, ok := graph.assetStringToID[selling.String()]
+	if !ok {
+		return result, nil
+	}
+	buyingID, ok := graph.assetStringToID[buying.String()]
+	if !ok {
+		return result, nil
+	}
+
+	// the offers which can cross the new offer buy its selling asset and sell
+	// its buying asset, sorted by price
+	edges := graph.venuesForBuyingAsset[sellingID]
+	i := edges.find(buyingID)
+	if i < 0 {
+		return result, nil
+	}
+
+	remaining := amount
+	for _, offer := range edges[i].value.offers {
+		n, d := int64(offer.Price.N), int64(offer.Price.D)
+		// the offers cross if the product of their prices is at most 1
+		lhs, rhs := n*int64(offerPrice.N), d*int64(offerPrice.D)
+		if lhs > rhs || (passive && lhs == rhs) {
+			break
+		}
+		if offer.SellerId.Equals(seller) {
+			result.CrossesSelf = true
+			break
+		}
+
+		sold, bought, err := price.ConvertToBuyingUnits(int64(offer.Amount), int64(offer.Amount), n, d)
+		if err != nil {
+			return result, err
+		}
+		if xdr.Int64(sold) > remaining {
+			needed, err := price.MulFractionRoundDown(int64(remaining), d, n)
+			if err != nil {
+				return result, err
+			}
+			if needed <= 0 {
+				break
+			}
+			if sold, bought, err = price.ConvertToBuyingUnits(int64(offer.Amount), needed, n, d); err != nil {
+				return result, err
+			}
+		}
+
+		result.AmountSold += xdr.Int64(sold)
+		result.AmountBought += xdr.Int64(bought)
+		remaining -= xdr.Int64(sold)
+		if remaining <= 0 {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package orderbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/xdr"
+)
+
+func TestSimulatePathPayment(t *testing.T) {
+	graph := NewOrderBookGraph()
+	graph.AddOffers(fiftyCentsOffer, quarterOffer, dollarOffer)
+	require.NoError(t, graph.Apply(1))
+	offersBefore := graph.Offers()
+
+	// buying 100 XLM only consumes the quarter offer
+	amount, ok, err := graph.SimulatePathPayment([]xdr.Asset{usdAsset, nativeAsset}, 100, true, nil, true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, xdr.Int64(25), amount)
+
+	amount, ok, err = graph.SimulatePathPayment([]xdr.Asset{usdAsset, nativeAsset}, 25, false, nil, true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, xdr.Int64(100), amount)
+
+	// consecutive hops with the same asset are ignored
+	amount, ok, err = graph.SimulatePathPayment([]xdr.Asset{usdAsset, usdAsset, nativeAsset}, 100, true, nil, true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, xdr.Int64(25), amount)
+
+	amount, ok, err = graph.SimulatePathPayment([]xdr.Asset{usdAsset, usdAsset}, 100, true, nil, true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, xdr.Int64(100), amount)
+
+	// the offers can't deliver more than 1500 XLM
+	_, ok, err = graph.SimulatePathPayment([]xdr.Asset{usdAsset, nativeAsset}, 1600, true, nil, true)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// offers created by the source account are ignored
+	_, ok, err = graph.SimulatePathPayment([]xdr.Asset{usdAsset, nativeAsset}, 100, true, &issuer, true)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = graph.SimulatePathPayment([]xdr.Asset{eurAsset, nativeAsset}, 100, true, nil, true)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assertOfferListEquals(t, offersBefore, graph.Offers())
+}
+
+func TestSimulateOffer(t *testing.T) {
+	graph := NewOrderBookGraph()
+	graph.AddOffers(fiftyCentsOffer, quarterOffer, dollarOffer)
+	require.NoError(t, graph.Apply(1))
+	seller := xdr.MustAddress("GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU")
+
+	// selling 100 USD for at least 2 XLM each partially consumes the quarter offer
+	crossing, err := graph.SimulateOffer(seller, usdAsset, nativeAsset, 100, xdr.Price{N: 2, D: 1}, false)
+	require.NoError(t, err)
+	assert.Equal(t, OfferCrossing{AmountSold: 100, AmountBought: 400}, crossing)
+
+	// the quarter offer is consumed and the fifty cents offer is partially consumed
+	crossing, err = graph.SimulateOffer(seller, usdAsset, nativeAsset, 150, xdr.Price{N: 2, D: 1}, false)
+	require.NoError(t, err)
+	assert.Equal(t, OfferCrossing{AmountSold: 150, AmountBought: 550}, crossing)
+
+	// passive offers do not cross offers with the same price
+	crossing, err = graph.SimulateOffer(seller, usdAsset, nativeAsset, 100, xdr.Price{N: 4, D: 1}, true)
+	require.NoError(t, err)
+	assert.Equal(t, OfferCrossing{}, crossing)
+
+	crossing, err = graph.SimulateOffer(seller, usdAsset, nativeAsset, 100, xdr.Price{N: 4, D: 1}, false)
+	require.NoError(t, err)
+	assert.Equal(t, OfferCrossing{AmountSold: 100, AmountBought: 400}, crossing)
+
+	crossing, err = graph.SimulateOffer(issuer, usdAsset, nativeAsset, 100, xdr.Price{N: 2, D: 1}, false)
+	require.NoError(t, err)
+	assert.True(t, crossing.CrossesSelf)
+	assert.Equal(t, xdr.Int64(0), crossing.AmountSold)
+
+	crossing, err = graph.SimulateOffer(seller, nativeAsset, usdAsset, 100, xdr.Price{N: 1, D: 1}, false)
+	require.NoError(t, err)
+	assert.Equal(t, OfferCrossing{}, crossing)
+}
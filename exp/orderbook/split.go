@@ -0,0 +1,612 @@
+package orderbook
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/stellar/go/price"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// splitPathChunks is the number of parts in which the amount of a split
+// payment is divided. Each part is routed through the payment path which is
+// the most favorable after the previous parts consumed their share of the
+// offers and liquidity pools.
+const splitPathChunks = 20
+
+// SplitPath represents a payment from a source asset to a destination asset
+// which is split across several payment paths (routes). Every route is meant
+// to be submitted as its own path payment and the amounts of the routes add
+// up to the amounts of the SplitPath.
+type SplitPath struct {
+	SourceAsset       string
+	SourceAmount      xdr.Int64
+	DestinationAsset  string
+	DestinationAmount xdr.Int64
+
+	Routes []Path
+}
+
+// FindSplitPaths works like FindPaths but instead of returning alternative
+// payment paths it returns, for each source asset, the cheapest way of
+// delivering `destinationAmount` by splitting it across up to `maxRoutes`
+// payment paths. Liquidity consumed by a route is not available to the other
+// routes, even if they share some offers or liquidity pools.
+func (graph *OrderBookGraph) FindSplitPaths(
+	ctx context.Context,
+	maxPathLength int,
+	destinationAsset xdr.Asset,
+	destinationAmount xdr.Int64,
+	sourceAccountID *xdr.AccountId,
+	sourceAssets []xdr.Asset,
+	sourceAssetBalances []xdr.Int64,
+	validateSourceBalance bool,
+	maxRoutes int,
+	includePools bool,
+) ([]SplitPath, uint32, error) {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	balances := map[string]xdr.Int64{}
+	find := func(amount xdr.Int64, group string, excludedPairs map[tradingPair]bool) ([]Path, error) {
+		assets, assetBalances := sourceAssets, sourceAssetBalances
+		if group != "" {
+			assets, assetBalances = []xdr.Asset{}, []xdr.Int64{}
+			for i, sourceAsset := range sourceAssets {
+				if sourceAsset.String() == group {
+					assets = append(assets, sourceAsset)
+					assetBalances = append(assetBalances, sourceAssetBalances[i])
+				}
+			}
+		}
+		return graph.findPaths(
+			ctx, maxPathLength, destinationAsset, amount, sourceAccountID, assets, assetBalances,
+			validateSourceBalance, includePools, excludedPairs,
+		)
+	}
+	if validateSourceBalance {
+		for i, sourceAsset := range sourceAssets {
+			balances[sourceAsset.String()] = sourceAssetBalances[i]
+		}
+	}
+
+	result, err := graph.findSplitPaths(
+		find,
+		destinationAmount,
+		maxRoutes,
+		sortBySourceAsset,
+		&splitSimulation{
+			graph:            graph,
+			strictReceive:    true,
+			ignoreOffersFrom: sourceAccountID,
+			includePools:     includePools,
+			balances:         balances,
+		},
+	)
+	if err != nil {
+		return nil, graph.lastLedger, errors.Wrap(err, "could not determine split paths")
+	}
+	return result, graph.lastLedger, nil
+}
+
+// FindFixedSplitPaths works like FindFixedPaths but instead of returning
+// alternative payment paths it returns, for each destination asset, the most
+// profitable way of spending `amountToSpend` by splitting it across up to
+// `maxRoutes` payment paths. Liquidity consumed by a route is not available
+// to the other routes, even if they share some offers or liquidity pools.
+func (graph *OrderBookGraph) FindFixedSplitPaths(
+	ctx context.Context,
+	maxPathLength int,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAssets []xdr.Asset,
+	maxRoutes int,
+	includePools bool,
+) ([]SplitPath, uint32, error) {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	find := func(amount xdr.Int64, group string, excludedPairs map[tradingPair]bool) ([]Path, error) {
+		assets := destinationAssets
+		if group != "" {
+			assets = []xdr.Asset{}
+			for _, destinationAsset := range destinationAssets {
+				if destinationAsset.String() == group {
+					assets = append(assets, destinationAsset)
+				}
+			}
+		}
+		return graph.findFixedPaths(
+			ctx, maxPathLength, sourceAsset, amount, assets, includePools, excludedPairs,
+		)
+	}
+
+	result, err := graph.findSplitPaths(
+		find,
+		amountToSpend,
+		maxRoutes,
+		sortByDestinationAsset,
+		&splitSimulation{
+			graph:        graph,
+			includePools: includePools,
+		},
+	)
+	if err != nil {
+		return nil, graph.lastLedger, errors.Wrap(err, "could not determine split paths")
+	}
+	return result, graph.lastLedger, nil
+}
+
+// splitPathSearch searches for payment paths carrying `amount`, ignoring the
+// trading pairs in `excludedPairs`. If `group` is not empty the search is
+// restricted to the given source asset (in strict receive searches) or
+// destination asset (in strict send searches).
+type splitPathSearch func(amount xdr.Int64, group string, excludedPairs map[tradingPair]bool) ([]Path, error)
+
+// findSplitPaths splits `amount` across the payment paths found by `find`.
+//
+// The candidate routes of every source (or destination) asset are the best
+// path which can carry the whole amount, the best paths which can carry a
+// single chunk of the amount and, since the search only reports a path if it
+// improves on the paths found before, the best paths which do not trade any
+// of the pairs of the routes found so far. The caller must hold the graph
+// lock.
+func (graph *OrderBookGraph) findSplitPaths(
+	find splitPathSearch,
+	amount xdr.Int64,
+	maxRoutes int,
+	sortType sortByType,
+	simulation *splitSimulation,
+) ([]SplitPath, error) {
+	groupKey := func(p Path) string { return p.SourceAsset }
+	if sortType == sortByDestinationAsset {
+		groupKey = func(p Path) string { return p.DestinationAsset }
+	}
+	chunks := xdr.Int64(splitPathChunks)
+	if amount < chunks {
+		chunks = amount
+	}
+	chunkAmount := amount / chunks
+
+	fullPaths, err := find(amount, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if fullPaths, err = sortAndFilterPaths(fullPaths, 1, sortType); err != nil {
+		return nil, err
+	}
+	chunkPaths, err := find(chunkAmount, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if chunkPaths, err = sortAndFilterPaths(chunkPaths, maxRoutes, sortType); err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	candidates := map[string][]Path{}
+	bestFullPath := map[string]Path{}
+	for _, path := range fullPaths {
+		bestFullPath[groupKey(path)] = path
+	}
+	for _, path := range append(fullPaths, chunkPaths...) {
+		key := groupKey(path)
+		if _, ok := candidates[key]; !ok {
+			groups = append(groups, key)
+		}
+		candidates[key] = append(candidates[key], path)
+	}
+	sort.Strings(groups)
+
+	var result []SplitPath
+	for _, group := range groups {
+		routes, err := graph.splitRoutes(find, group, chunkAmount, maxRoutes, sortType, candidates[group])
+		if err != nil {
+			return nil, err
+		}
+
+		simulation.reset()
+		split, ok, err := simulation.allocate(routes, amount, chunks)
+		if err != nil {
+			return nil, err
+		}
+
+		single, hasSingle := bestFullPath[group]
+		if hasSingle && (!ok || !simulation.better(split, single)) {
+			split = SplitPath{
+				SourceAsset:       single.SourceAsset,
+				SourceAmount:      single.SourceAmount,
+				DestinationAsset:  single.DestinationAsset,
+				DestinationAmount: single.DestinationAmount,
+				Routes:            []Path{single},
+			}
+		} else if !ok {
+			continue
+		}
+		result = append(result, split)
+	}
+	return result, nil
+}
+
+// splitRoutes returns up to `maxRoutes` distinct candidate routes for the
+// given group, starting with the given paths and completing them with the
+// best paths which do not trade any of the pairs of the routes found so far.
+func (graph *OrderBookGraph) splitRoutes(
+	find splitPathSearch,
+	group string,
+	chunkAmount xdr.Int64,
+	maxRoutes int,
+	sortType sortByType,
+	paths []Path,
+) ([]*splitRoute, error) {
+	var routes []*splitRoute
+	seen := map[string]bool{}
+	excludedPairs := map[tradingPair]bool{}
+	add := func(path Path) bool {
+		key := path.SourceAsset + "," + strings.Join(path.InteriorNodes, ",") + "," + path.DestinationAsset
+		if seen[key] || len(routes) >= maxRoutes {
+			return false
+		}
+		seen[key] = true
+		route := graph.newSplitRoute(path)
+		for i := 1; i < len(route.assets); i++ {
+			excludedPairs[orderedTradingPair(route.assets[i-1], route.assets[i])] = true
+		}
+		routes = append(routes, route)
+		return true
+	}
+
+	for _, path := range paths {
+		add(path)
+	}
+	for len(routes) < maxRoutes {
+		found, err := find(chunkAmount, group, excludedPairs)
+		if err != nil {
+			return nil, err
+		}
+		if found, err = sortAndFilterPaths(found, 1, sortType); err != nil {
+			return nil, err
+		}
+		if len(found) == 0 || !add(found[0]) {
+			break
+		}
+	}
+	return routes, nil
+}
+
+// splitRoute is a candidate route of a split payment along with the amounts
+// allocated to it so far.
+type splitRoute struct {
+	path Path
+	// assets holds the assets of the route in order, from the source asset
+	// to the destination asset.
+	assets            []int32
+	sourceAmount      xdr.Int64
+	destinationAmount xdr.Int64
+}
+
+func (graph *OrderBookGraph) newSplitRoute(path Path) *splitRoute {
+	assets := make([]int32, 0, len(path.InteriorNodes)+2)
+	assets = append(assets, graph.assetStringToID[path.SourceAsset])
+	for _, node := range path.InteriorNodes {
+		assets = append(assets, graph.assetStringToID[node])
+	}
+	if path.DestinationAsset != path.SourceAsset {
+		assets = append(assets, graph.assetStringToID[path.DestinationAsset])
+	}
+	return &splitRoute{path: path, assets: assets}
+}
+
+// splitSimulation simulates the execution of path payments against a private
+// copy of the offers and liquidity pools of the graph, so that consecutive
+// path payments observe the liquidity consumed by the previous ones.
+type splitSimulation struct {
+	graph *OrderBookGraph
+	// strictReceive is true when the destination amount is fixed and false
+	// when the source amount is fixed.
+	strictReceive    bool
+	ignoreOffersFrom *xdr.AccountId
+	includePools     bool
+	// balances holds the maximum amount which can be spent of each source
+	// asset. It is empty if the source balances are not validated.
+	balances map[string]xdr.Int64
+
+	// offers maps a trading pair to the offers selling its selling asset in
+	// exchange for its buying asset, sorted by price.
+	offers map[tradingPair][]xdr.OfferEntry
+	// pools maps a trading pair (in asset order) to its liquidity pool.
+	pools map[tradingPair]*liquidityPool
+}
+
+func (s *splitSimulation) reset() {
+	s.offers = map[tradingPair][]xdr.OfferEntry{}
+	s.pools = map[tradingPair]*liquidityPool{}
+}
+
+// better returns true if the split payment is more favorable than the given
+// single payment path.
+func (s *splitSimulation) better(split SplitPath, single Path) bool {
+	if s.strictReceive {
+		return split.SourceAmount < single.SourceAmount
+	}
+	return split.DestinationAmount > single.DestinationAmount
+}
+
+// allocate divides `amount` in `chunks` parts and assigns each one of them to
+// the route which is the most favorable at that point. It returns false if
+// the amount cannot be routed through the given routes.
+func (s *splitSimulation) allocate(routes []*splitRoute, amount, chunks xdr.Int64) (SplitPath, bool, error) {
+	var totalSource, totalDestination xdr.Int64
+	for _, route := range routes {
+		route.sourceAmount, route.destinationAmount = 0, 0
+	}
+
+	chunkAmount := amount / chunks
+	for i := xdr.Int64(0); i < chunks; i++ {
+		current := chunkAmount
+		if i == chunks-1 {
+			current = amount - chunkAmount*(chunks-1)
+		}
+
+		var best *splitRoute
+		var bestResult xdr.Int64
+		for _, route := range routes {
+			result, err := s.executeRoute(route.assets, current, false)
+			if err != nil {
+				return SplitPath{}, false, err
+			}
+			if result <= 0 {
+				continue
+			}
+			if balance, ok := s.balances[route.path.SourceAsset]; ok && totalSource+result > balance {
+				continue
+			}
+			if best == nil || (s.strictReceive && result < bestResult) || (!s.strictReceive && result > bestResult) {
+				best, bestResult = route, result
+			}
+		}
+		if best == nil {
+			return SplitPath{}, false, nil
+		}
+		if _, err := s.executeRoute(best.assets, current, true); err != nil {
+			return SplitPath{}, false, err
+		}
+
+		if s.strictReceive {
+			best.sourceAmount += bestResult
+			best.destinationAmount += current
+			totalSource += bestResult
+			totalDestination += current
+		} else {
+			best.sourceAmount += current
+			best.destinationAmount += bestResult
+			totalSource += current
+			totalDestination += bestResult
+		}
+	}
+
+	split := SplitPath{
+		SourceAsset:       routes[0].path.SourceAsset,
+		SourceAmount:      totalSource,
+		DestinationAsset:  routes[0].path.DestinationAsset,
+		DestinationAmount: totalDestination,
+	}
+	for _, route := range routes {
+		if route.sourceAmount == 0 {
+			continue
+		}
+		path := route.path
+		path.SourceAmount = route.sourceAmount
+		path.DestinationAmount = route.destinationAmount
+		split.Routes = append(split.Routes, path)
+	}
+	// list the routes carrying the largest share of the payment first
+	sort.SliceStable(split.Routes, func(i, j int) bool {
+		if s.strictReceive {
+			return split.Routes[i].DestinationAmount > split.Routes[j].DestinationAmount
+		}
+		return split.Routes[i].SourceAmount > split.Routes[j].SourceAmount
+	})
+	return split, true, nil
+}
+
+// executeRoute simulates a path payment through the given assets. In strict
+// receive mode `amount` is the amount delivered and the amount spent is
+// returned. In strict send mode `amount` is the amount spent and the amount
+// delivered is returned. A non positive result means the route cannot carry
+// the payment. The offers and pools are only updated when `apply` is true.
+func (s *splitSimulation) executeRoute(assets []int32, amount xdr.Int64, apply bool) (xdr.Int64, error) {
+	var err error
+	if s.strictReceive {
+		for i := len(assets) - 1; i > 0 && amount > 0; i-- {
+			amount, err = s.executeHop(assets[i-1], assets[i], amount, apply)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return amount, nil
+	}
+
+	for i := 0; i < len(assets)-1 && amount > 0; i++ {
+		amount, err = s.executeHop(assets[i], assets[i+1], amount, apply)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return amount, nil
+}
+
+// executeHop simulates exchanging `from` for `to` using whichever of the
+// offers or the liquidity pool of the trading pair gives the best rate, like
+// the path finding search does.
+func (s *splitSimulation) executeHop(from, to int32, amount xdr.Int64, apply bool) (xdr.Int64, error) {
+	offers := s.venueOffers(from, to)
+	pool := s.venuePool(from, to)
+
+	offersResult, err := s.consumeOffers(offers, amount, false)
+	if err != nil {
+		return 0, err
+	}
+	poolResult := xdr.Int64(-1)
+	if pool != nil {
+		poolResult = s.consumePool(pool, from, amount, false)
+	}
+
+	usePool := poolResult > 0 && (offersResult <= 0 ||
+		(s.strictReceive && poolResult < offersResult) ||
+		(!s.strictReceive && poolResult > offersResult))
+	if usePool {
+		if apply {
+			s.consumePool(pool, from, amount, true)
+		}
+		return poolResult, nil
+	}
+	if offersResult > 0 && apply {
+		if _, err := s.consumeOffers(offers, amount, true); err != nil {
+			return 0, err
+		}
+	}
+	return offersResult, nil
+}
+
+// venueOffers returns the offers which sell `to` in exchange for `from`,
+// copying them from the graph the first time they are requested.
+func (s *splitSimulation) venueOffers(from, to int32) []xdr.OfferEntry {
+	pair := tradingPair{buyingAsset: from, sellingAsset: to}
+	if offers, ok := s.offers[pair]; ok {
+		return offers
+	}
+
+	var offers []xdr.OfferEntry
+	edges := s.graph.venuesForBuyingAsset[from]
+	if i := edges.find(to); i >= 0 {
+		offers = append(offers, edges[i].value.offers...)
+	}
+	s.offers[pair] = offers
+	return offers
+}
+
+// venuePool returns the liquidity pool of the trading pair, copying it from
+// the graph the first time it is requested.
+func (s *splitSimulation) venuePool(from, to int32) *liquidityPool {
+	if !s.includePools {
+		return nil
+	}
+	pair := tradingPair{buyingAsset: from, sellingAsset: to}
+	if to < from {
+		pair = tradingPair{buyingAsset: to, sellingAsset: from}
+	}
+	if pool, ok := s.pools[pair]; ok {
+		return pool
+	}
+
+	var pool *liquidityPool
+	edges := s.graph.venuesForBuyingAsset[from]
+	if i := edges.find(to); i >= 0 && edges[i].value.pool.Body.ConstantProduct != nil {
+		venuePool := edges[i].value.pool
+		constantProduct := *venuePool.Body.ConstantProduct
+		venuePool.Body.ConstantProduct = &constantProduct
+		pool = &venuePool
+	}
+	s.pools[pair] = pool
+	return pool
+}
+
+// consumePool trades with the liquidity pool, depositing `from`. It returns a
+// non positive amount if the pool cannot fulfill the trade.
+func (s *splitSimulation) consumePool(pool *liquidityPool, from int32, amount xdr.Int64, apply bool) xdr.Int64 {
+	tradeType := tradeTypeDeposit
+	if s.strictReceive {
+		tradeType = tradeTypeExpectation
+	}
+	result, err := makeTrade(*pool, from, tradeType, amount)
+	if err != nil || result <= 0 {
+		return -1
+	}
+
+	if apply {
+		deposited, withdrawn := amount, result
+		if s.strictReceive {
+			deposited, withdrawn = result, amount
+		}
+		details := pool.Body.ConstantProduct
+		if pool.assetA == from {
+			details.ReserveA += deposited
+			details.ReserveB -= withdrawn
+		} else {
+			details.ReserveB += deposited
+			details.ReserveA -= withdrawn
+		}
+	}
+	return result
+}
+
+// consumeOffers crosses the given offers, sorted by price. It mirrors
+// consumeOffersForSellingAsset (in strict receive mode) and
+// consumeOffersForBuyingAsset (in strict send mode) and returns a non
+// positive amount if the offers cannot fulfill the trade. The amounts of the
+// offers are only updated when `apply` is true.
+func (s *splitSimulation) consumeOffers(offers []xdr.OfferEntry, amount xdr.Int64, apply bool) (xdr.Int64, error) {
+	total := xdr.Int64(0)
+	for i := range offers {
+		offer := &offers[i]
+		if offer.Amount <= 0 {
+			continue
+		}
+		n, d := int64(offer.Price.N), int64(offer.Price.D)
+
+		if s.strictReceive {
+			if s.ignoreOffersFrom != nil && s.ignoreOffersFrom.Equals(offer.SellerId) {
+				continue
+			}
+			buyingUnits, sellingUnits, err := price.ConvertToBuyingUnits(int64(offer.Amount), int64(amount), n, d)
+			if err == price.ErrOverflow {
+				return -1, nil
+			} else if err != nil {
+				return -1, err
+			}
+			total += xdr.Int64(buyingUnits)
+			amount -= xdr.Int64(sellingUnits)
+			if apply {
+				offer.Amount -= xdr.Int64(sellingUnits)
+			}
+		} else {
+			amountSold, err := price.MulFractionRoundDown(int64(amount), d, n)
+			if err == nil {
+				if amountSold <= 0 {
+					return -1, nil
+				}
+				if xdr.Int64(amountSold) <= offer.Amount {
+					if apply {
+						offer.Amount -= xdr.Int64(amountSold)
+					}
+					return total + xdr.Int64(amountSold), nil
+				}
+			} else if err != price.ErrOverflow {
+				return -1, err
+			}
+
+			buyingUnits, sellingUnits, err := price.ConvertToBuyingUnits(int64(offer.Amount), int64(offer.Amount), n, d)
+			if err == price.ErrOverflow {
+				return -1, nil
+			} else if err != nil {
+				return -1, err
+			}
+			total += xdr.Int64(sellingUnits)
+			amount -= xdr.Int64(buyingUnits)
+			if apply {
+				offer.Amount -= xdr.Int64(sellingUnits)
+			}
+		}
+
+		if amount == 0 {
+			return total, nil
+		}
+		if amount < 0 {
+			return -1, errSoldTooMuch
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,123 @@
+package orderbook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/xdr"
+)
+
+func setupSplitGraph(t *testing.T) *OrderBookGraph {
+	graph := NewOrderBookGraph()
+	// USD can be exchanged for Yen through EUR or through CHF, with the same
+	// liquidity on both routes
+	graph.AddLiquidityPools(
+		makePool(usdAsset, eurAsset, 1000, 1000),
+		makePool(eurAsset, yenAsset, 1000, 1000),
+		makePool(usdAsset, chfAsset, 1000, 1000),
+		makePool(chfAsset, yenAsset, 1000, 1000),
+	)
+	require.NoError(t, graph.Apply(1))
+	return graph
+}
+
+func assertSplitPathConsistent(t *testing.T, split SplitPath) {
+	var sourceAmount, destinationAmount xdr.Int64
+	for _, route := range split.Routes {
+		assert.Equal(t, split.SourceAsset, route.SourceAsset)
+		assert.Equal(t, split.DestinationAsset, route.DestinationAsset)
+		sourceAmount += route.SourceAmount
+		destinationAmount += route.DestinationAmount
+	}
+	assert.Equal(t, split.SourceAmount, sourceAmount)
+	assert.Equal(t, split.DestinationAmount, destinationAmount)
+}
+
+func TestFindFixedSplitPaths(t *testing.T) {
+	graph := setupSplitGraph(t)
+	poolsBefore := graph.LiquidityPools()
+
+	single, _, err := graph.FindFixedPaths(context.TODO(), 3, usdAsset, 400, []xdr.Asset{yenAsset}, 5, true)
+	require.NoError(t, err)
+	require.NotEmpty(t, single)
+
+	splits, lastLedger, err := graph.FindFixedSplitPaths(context.TODO(), 3, usdAsset, 400, []xdr.Asset{yenAsset}, 5, true)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), lastLedger)
+	require.Len(t, splits, 1)
+
+	split := splits[0]
+	assertSplitPathConsistent(t, split)
+	assert.Equal(t, usdAsset.String(), split.SourceAsset)
+	assert.Equal(t, yenAsset.String(), split.DestinationAsset)
+	assert.Equal(t, xdr.Int64(400), split.SourceAmount)
+	require.Len(t, split.Routes, 2)
+	// both routes have the same liquidity so the amount is split evenly
+	assert.Equal(t, xdr.Int64(200), split.Routes[0].SourceAmount)
+	assert.Equal(t, xdr.Int64(200), split.Routes[1].SourceAmount)
+	assert.Greater(t, split.DestinationAmount, single[0].DestinationAmount)
+
+	// the graph is not modified by the simulation
+	assert.ElementsMatch(t, poolsBefore, graph.LiquidityPools())
+}
+
+func TestFindSplitPaths(t *testing.T) {
+	graph := setupSplitGraph(t)
+
+	single, _, err := graph.FindPaths(
+		context.TODO(), 3, yenAsset, 300, nil, []xdr.Asset{usdAsset}, []xdr.Int64{0}, false, 5, true,
+	)
+	require.NoError(t, err)
+	require.NotEmpty(t, single)
+
+	splits, _, err := graph.FindSplitPaths(
+		context.TODO(), 3, yenAsset, 300, nil, []xdr.Asset{usdAsset}, []xdr.Int64{0}, false, 5, true,
+	)
+	require.NoError(t, err)
+	require.Len(t, splits, 1)
+
+	split := splits[0]
+	assertSplitPathConsistent(t, split)
+	assert.Equal(t, xdr.Int64(300), split.DestinationAmount)
+	require.Len(t, split.Routes, 2)
+	assert.Equal(t, xdr.Int64(150), split.Routes[0].DestinationAmount)
+	assert.Equal(t, xdr.Int64(150), split.Routes[1].DestinationAmount)
+	assert.Less(t, split.SourceAmount, single[0].SourceAmount)
+
+	t.Run("not enough source balance", func(t *testing.T) {
+		splits, _, err := graph.FindSplitPaths(
+			context.TODO(), 3, yenAsset, 300, nil,
+			[]xdr.Asset{usdAsset}, []xdr.Int64{split.SourceAmount - 1}, true, 5, true,
+		)
+		require.NoError(t, err)
+		assert.Empty(t, splits)
+	})
+}
+
+func TestFindSplitPathsSingleRoute(t *testing.T) {
+	graph := NewOrderBookGraph()
+	graph.AddOffers(fiftyCentsOffer, quarterOffer, dollarOffer)
+	require.NoError(t, graph.Apply(1))
+
+	single, _, err := graph.FindFixedPaths(context.TODO(), 3, usdAsset, 100, []xdr.Asset{nativeAsset}, 5, true)
+	require.NoError(t, err)
+	require.Len(t, single, 1)
+
+	// there is a single way of exchanging USD for XLM so the split payment is
+	// the best payment path
+	splits, _, err := graph.FindFixedSplitPaths(context.TODO(), 3, usdAsset, 100, []xdr.Asset{nativeAsset}, 5, true)
+	require.NoError(t, err)
+	require.Len(t, splits, 1)
+	assertSplitPathConsistent(t, splits[0])
+	assertPathEquals(t, single, splits[0].Routes)
+
+	// the offers can't deliver more than 1500 XLM
+	splits, _, err = graph.FindSplitPaths(
+		context.TODO(), 3, nativeAsset, 1600, nil, []xdr.Asset{usdAsset}, []xdr.Int64{0}, false, 5, true,
+	)
+	require.NoError(t, err)
+	assert.Empty(t, splits)
+}
@@ -1,5 +1,7 @@
 package asset
 
+import "github.com/stellar/go/xdr"
+
 // NewNativeAsset creates an Asset representing the native token (XLM).
 func NewNativeAsset() *Asset {
 	return &Asset{AssetType: &Asset_Native{Native: true}}
@@ -16,3 +18,11 @@ func NewIssuedAsset(assetCode, issuer string) *Asset {
 		},
 	}
 }
+
+// NewProtoAsset converts an xdr.Asset into its protobuf representation.
+func NewProtoAsset(xdrAsset xdr.Asset) *Asset {
+	if xdrAsset.Type == xdr.AssetTypeAssetTypeNative {
+		return NewNativeAsset()
+	}
+	return NewIssuedAsset(xdrAsset.GetCode(), xdrAsset.GetIssuer())
+}
@@ -1,6 +1,7 @@
 package asset
 
 import (
+	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/proto"
 	"testing"
@@ -44,3 +45,13 @@ func TestAssetSerialization(t *testing.T) {
 
 	assert.True(t, proto.Equal(original, &deserializedAsset), "Deserialized asset does not match the original")
 }
+
+func TestNewProtoAsset(t *testing.T) {
+	assert.True(t, proto.Equal(NewNativeAsset(), NewProtoAsset(xdr.MustNewNativeAsset())))
+
+	issuer := "GA5ZSEJYB37JRC5AVCIA5MOP4RHTM335X2KGX3IHOJAPP5RE34K4KZVN"
+	assert.True(t, proto.Equal(
+		NewIssuedAsset("USDC", issuer),
+		NewProtoAsset(xdr.MustNewCreditAsset("USDC", issuer)),
+	))
+}
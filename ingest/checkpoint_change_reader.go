@@ -40,6 +40,14 @@ type CheckpointChangeReader struct {
 
 	encodingBuffer *xdr.EncodingBuffer
 
+	// concurrency is the number of buckets read at the same time by a reader
+	// created with NewParallelCheckpointChangeReader, 0 for sequential readers.
+	concurrency int
+	shardBy     ShardBy
+	// shards are the channels changes are sent to by parallel readers, only
+	// readChan if changes are not sharded.
+	shards []chan readResult
+
 	// This should be set to true in tests only
 	disableBucketListHashValidation bool
 	sleep                           func(time.Duration)
@@ -157,14 +165,31 @@ func (r *CheckpointChangeReader) streamBuckets() {
 		close(r.readChan)
 	}()
 
+	buckets, err := r.bucketHashes()
+	if err != nil {
+		r.readChan <- r.error(err)
+		return
+	}
+
+	for i, hash := range buckets {
+		oldestBucket := i == len(buckets)-1
+		if shouldContinue := r.streamBucketContents(hash, oldestBucket); !shouldContinue {
+			break
+		}
+	}
+}
+
+// bucketHashes returns the hashes of the non-empty buckets of the HAS, from
+// newest to oldest, after checking that they exist and adding their sizes to
+// totalSize.
+func (r *CheckpointChangeReader) bucketHashes() ([]historyarchive.Hash, error) {
 	var buckets []historyarchive.Hash
 	for i := 0; i < len(r.has.CurrentBuckets); i++ {
 		b := r.has.CurrentBuckets[i]
 		for _, hashString := range []string{b.Curr, b.Snap} {
 			hash, err := historyarchive.DecodeHash(hashString)
 			if err != nil {
-				r.readChan <- r.error(errors.Wrap(err, "Error decoding bucket hash"))
-				return
+				return nil, errors.Wrap(err, "Error decoding bucket hash")
 			}
 
 			if hash.IsZero() {
@@ -178,25 +203,16 @@ func (r *CheckpointChangeReader) streamBuckets() {
 	for _, hash := range buckets {
 		exists, err := r.bucketExists(hash)
 		if err != nil {
-			r.readChan <- r.error(
-				errors.Wrapf(err, "error checking if bucket exists: %s", hash),
-			)
-			return
+			return nil, errors.Wrapf(err, "error checking if bucket exists: %s", hash)
 		}
 
 		if !exists {
-			r.readChan <- r.error(
-				errors.Errorf("bucket hash does not exist: %s", hash),
-			)
-			return
+			return nil, errors.Errorf("bucket hash does not exist: %s", hash)
 		}
 
 		size, err := r.archive.BucketSize(hash)
 		if err != nil {
-			r.readChan <- r.error(
-				errors.Wrapf(err, "error checking bucket size: %s", hash),
-			)
-			return
+			return nil, errors.Wrapf(err, "error checking bucket size: %s", hash)
 		}
 
 		r.readBytesMutex.Lock()
@@ -204,12 +220,7 @@ func (r *CheckpointChangeReader) streamBuckets() {
 		r.readBytesMutex.Unlock()
 	}
 
-	for i, hash := range buckets {
-		oldestBucket := i == len(buckets)-1
-		if shouldContinue := r.streamBucketContents(hash, oldestBucket); !shouldContinue {
-			break
-		}
-	}
+	return buckets, nil
 }
 
 // readBucketEntry will attempt to read a bucket entry from `stream`.
@@ -433,13 +444,28 @@ func (r *CheckpointChangeReader) streamBucketContents(hash historyarchive.Hash,
 }
 
 // Read returns a new ledger entry change on each call, returning io.EOF when the stream ends.
+// Changes of readers created with NewParallelCheckpointChangeReader with more
+// than one shard are read with the readers returned by Shards instead.
 func (r *CheckpointChangeReader) Read() (Change, error) {
+	if len(r.shards) > 1 {
+		return Change{}, errors.New("changes are sharded, read them with the readers returned by Shards")
+	}
+	return r.readFrom(r.readChan)
+}
+
+// readFrom starts streaming buckets, unless it has started already, and
+// returns the next change sent to readChan.
+func (r *CheckpointChangeReader) readFrom(readChan <-chan readResult) (Change, error) {
 	r.streamOnce.Do(func() {
-		go r.streamBuckets()
+		if r.concurrency > 0 {
+			go r.streamBucketsParallel()
+		} else {
+			go r.streamBuckets()
+		}
 	})
 
 	// blocking call. anytime we consume from this channel, the background goroutine will stream in the next value
-	result, ok := <-r.readChan
+	result, ok := <-readChan
 	if !ok {
 		// when channel is closed then return io.EOF
 		return Change{}, io.EOF
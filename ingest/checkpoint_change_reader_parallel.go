@@ -0,0 +1,414 @@
+package ingest
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/support/collections/set"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ShardBy selects how a parallel CheckpointChangeReader splits changes into
+// shards.
+type ShardBy int
+
+const (
+	// ShardByKeyHash splits changes by the hash of their ledger key, which
+	// spreads them evenly over the shards.
+	ShardByKeyHash ShardBy = iota
+	// ShardByEntryType splits changes by ledger entry type: all the changes
+	// of a type are sent to shard int(type) % shards.
+	ShardByEntryType
+)
+
+// ParallelOptions configures a CheckpointChangeReader created with
+// NewParallelCheckpointChangeReader.
+type ParallelOptions struct {
+	// Concurrency is the number of buckets downloaded and decoded at the same
+	// time, runtime.GOMAXPROCS(0) if not set.
+	Concurrency int
+	// Shards is the number of shards changes are split into, see
+	// CheckpointChangeReader.Shards. Changes are not sharded if it's 0 or 1.
+	Shards int
+	// ShardBy selects the shard of a change, ShardByKeyHash if not set.
+	ShardBy ShardBy
+}
+
+const (
+	// parallelBatchSize is the number of bucket entries sent at a time by
+	// the goroutines decoding buckets.
+	parallelBatchSize = 1000
+	// parallelBatchBuffer is the number of batches of a bucket decoded ahead
+	// of the merge.
+	parallelBatchBuffer = 16
+)
+
+// decodedEntry is a bucket entry decoded by a parallel reader.
+type decodedEntry struct {
+	entry xdr.BucketEntry
+	// key is the compressed ledger key of the entry.
+	key          string
+	unique       bool
+	oldestBucket bool
+	shard        int
+}
+
+type decodedBatch struct {
+	entries []decodedEntry
+	err     error
+}
+
+// NewParallelCheckpointChangeReader constructs a CheckpointChangeReader which
+// downloads and decodes up to options.Concurrency buckets at the same time.
+// Buckets are still merged from newest to oldest, so it returns the same
+// changes as a reader created with NewCheckpointChangeReader, in the same
+// order if they are not sharded.
+//
+// If options.Shards is greater than 1 changes are split into shards, read
+// with the readers returned by Shards. All the shards must be read
+// concurrently: once the buffer of a shard is full, the others are blocked
+// until it's read.
+func NewParallelCheckpointChangeReader(
+	ctx context.Context,
+	archive historyarchive.ArchiveInterface,
+	sequence uint32,
+	options ParallelOptions,
+) (*CheckpointChangeReader, error) {
+	if options.Concurrency < 0 {
+		return nil, errors.Errorf("invalid concurrency %d", options.Concurrency)
+	}
+	if options.Shards < 0 {
+		return nil, errors.Errorf("invalid number of shards %d", options.Shards)
+	}
+	if options.ShardBy != ShardByKeyHash && options.ShardBy != ShardByEntryType {
+		return nil, errors.Errorf("invalid ShardBy %d", options.ShardBy)
+	}
+
+	reader, err := NewCheckpointChangeReader(ctx, archive, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	reader.concurrency = options.Concurrency
+	if reader.concurrency == 0 {
+		reader.concurrency = runtime.GOMAXPROCS(0)
+	}
+	reader.shardBy = options.ShardBy
+	if options.Shards <= 1 {
+		reader.shards = []chan readResult{reader.readChan}
+		return reader, nil
+	}
+
+	reader.readChan = nil
+	bufferSize := max(msrBufferSize/options.Shards, parallelBatchSize)
+	for i := 0; i < options.Shards; i++ {
+		reader.shards = append(reader.shards, make(chan readResult, bufferSize))
+	}
+	return reader, nil
+}
+
+// Shards returns a reader of each shard of the changes of a reader created
+// with NewParallelCheckpointChangeReader, or only the reader itself if changes
+// are not sharded. Closing any of the readers closes all of them.
+func (r *CheckpointChangeReader) Shards() []ChangeReader {
+	if len(r.shards) <= 1 {
+		return []ChangeReader{r}
+	}
+
+	readers := make([]ChangeReader, 0, len(r.shards))
+	for _, shard := range r.shards {
+		readers = append(readers, checkpointShardReader{reader: r, readChan: shard})
+	}
+	return readers
+}
+
+// checkpointShardReader reads the changes of a shard of a parallel
+// CheckpointChangeReader.
+type checkpointShardReader struct {
+	reader   *CheckpointChangeReader
+	readChan chan readResult
+}
+
+func (s checkpointShardReader) Read() (Change, error) {
+	return s.reader.readFrom(s.readChan)
+}
+
+func (s checkpointShardReader) Close() error {
+	return s.reader.Close()
+}
+
+func (s checkpointShardReader) VerifyBucketList(expectedHash xdr.Hash) error {
+	return s.reader.VerifyBucketList(expectedHash)
+}
+
+// streamBucketsParallel is the parallel version of streamBuckets.
+//
+// Buckets are downloaded and decoded by up to r.concurrency goroutines,
+// started in the order buckets are merged so the bucket merged next is always
+// being read. Decoded entries are routed, bucket after bucket, to a goroutine
+// per shard which keeps the set of ledger keys seen in newer buckets of its
+// shard, as streamBuckets does for all the keys. A ledger key always belongs
+// to the same shard, so the newest entry of a key still shadows the older ones.
+func (r *CheckpointChangeReader) streamBucketsParallel() {
+	inputs := make([]chan []decodedEntry, len(r.shards))
+	var merges sync.WaitGroup
+	for i := range r.shards {
+		inputs[i] = make(chan []decodedEntry, parallelBatchBuffer)
+		merges.Add(1)
+		go func(i int) {
+			defer merges.Done()
+			r.mergeShard(inputs[i], r.shards[i])
+		}(i)
+	}
+
+	err := r.routeBuckets(inputs)
+
+	for _, input := range inputs {
+		close(input)
+	}
+	merges.Wait()
+
+	if err != nil {
+		for _, shard := range r.shards {
+			select {
+			case shard <- r.error(err):
+			case <-r.done:
+			}
+		}
+	}
+
+	r.closeOnce.Do(r.close)
+	for _, shard := range r.shards {
+		close(shard)
+	}
+}
+
+// routeBuckets sends the entries of each bucket, from newest to oldest, to
+// the inputs of their shards.
+func (r *CheckpointChangeReader) routeBuckets(inputs []chan []decodedEntry) error {
+	buckets, err := r.bucketHashes()
+	if err != nil {
+		return err
+	}
+
+	decoded := make([]chan decodedBatch, len(buckets))
+	for i := range decoded {
+		decoded[i] = make(chan decodedBatch, parallelBatchBuffer)
+	}
+	go r.decodeBuckets(buckets, decoded)
+
+	for i := range buckets {
+		for {
+			var batch decodedBatch
+			var ok bool
+			select {
+			case batch, ok = <-decoded[i]:
+			case <-r.done:
+				return nil
+			}
+			if !ok {
+				break
+			}
+			if batch.err != nil {
+				return batch.err
+			}
+
+			shardEntries := make([][]decodedEntry, len(inputs))
+			for _, entry := range batch.entries {
+				shardEntries[entry.shard] = append(shardEntries[entry.shard], entry)
+			}
+			for shard, entries := range shardEntries {
+				if len(entries) == 0 {
+					continue
+				}
+				select {
+				case inputs[shard] <- entries:
+				case <-r.done:
+					return nil
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// decodeBuckets starts a goroutine decoding each bucket into its decoded
+// channel, in the order of buckets and with at most r.concurrency running at
+// the same time.
+func (r *CheckpointChangeReader) decodeBuckets(buckets []historyarchive.Hash, decoded []chan decodedBatch) {
+	running := make(chan struct{}, r.concurrency)
+	for i, hash := range buckets {
+		select {
+		case running <- struct{}{}:
+		case <-r.done:
+			return
+		}
+
+		go func(i int, hash historyarchive.Hash) {
+			defer func() {
+				close(decoded[i])
+				<-running
+			}()
+
+			oldestBucket := i == len(buckets)-1
+			if err := r.decodeBucket(hash, oldestBucket, decoded[i]); err != nil {
+				select {
+				case decoded[i] <- decodedBatch{err: err}:
+				case <-r.done:
+				}
+			}
+		}(i, hash)
+	}
+}
+
+// decodeBucket sends the entries of a bucket to out in batches. It applies
+// the same checks as streamBucketContents.
+func (r *CheckpointChangeReader) decodeBucket(hash historyarchive.Hash, oldestBucket bool, out chan<- decodedBatch) error {
+	rdr, err := r.newXDRStream(hash)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get xdr stream for hash '%s'", hash.String())
+	}
+
+	err = r.decodeBucketEntries(rdr, hash, oldestBucket, out)
+	if closeErr := rdr.Close(); err == nil && closeErr != nil {
+		err = errors.Wrap(closeErr, "Error closing xdr stream")
+	}
+	return err
+}
+
+func (r *CheckpointChangeReader) decodeBucketEntries(
+	rdr *xdr.Stream,
+	hash historyarchive.Hash,
+	oldestBucket bool,
+	out chan<- decodedBatch,
+) error {
+	send := func(entries []decodedEntry) bool {
+		select {
+		case out <- decodedBatch{entries: entries}:
+			return true
+		case <-r.done:
+			return false
+		}
+	}
+
+	// encodingBuffer can't be shared with the other goroutines
+	encodingBuffer := xdr.NewEncodingBuffer()
+	// bucketProtocolVersion is a protocol version read from METAENTRY or 0 when no METAENTRY.
+	bucketProtocolVersion := uint32(0)
+	batch := make([]decodedEntry, 0, parallelBatchSize)
+
+	for n := 0; ; n++ {
+		entry, err := r.readBucketEntry(rdr, hash)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Error on XDR record %d of hash '%s'", n, hash.String())
+		}
+
+		var key xdr.LedgerKey
+		switch entry.Type {
+		case xdr.BucketEntryTypeMetaentry:
+			if n != 0 {
+				return errors.Errorf(
+					"METAENTRY not the first entry (n=%d) in the bucket hash '%s'",
+					n, hash.String(),
+				)
+			}
+			bucketProtocolVersion = uint32(entry.MetaEntry.LedgerVersion)
+			continue
+		case xdr.BucketEntryTypeLiveentry, xdr.BucketEntryTypeInitentry:
+			if entry.Type == xdr.BucketEntryTypeInitentry && bucketProtocolVersion < 11 {
+				return errors.Errorf("Read INITENTRY from version <11 bucket: %d@%s", n, hash.String())
+			}
+			liveEntry := entry.MustLiveEntry()
+			key, err = liveEntry.LedgerKey()
+			if err != nil {
+				return errors.Wrapf(err, "Error generating ledger key for XDR record %d of hash '%s'", n, hash.String())
+			}
+		case xdr.BucketEntryTypeDeadentry:
+			key = entry.MustDeadEntry()
+		default:
+			return errors.Errorf("Unknown BucketEntryType=%d: %d@%s", entry.Type, n, hash.String())
+		}
+
+		keyBytes, err := encodingBuffer.LedgerKeyUnsafeMarshalBinaryCompress(key)
+		if err != nil {
+			return errors.Wrapf(err, "Error marshaling XDR record %d of hash '%s'", n, hash.String())
+		}
+
+		batch = append(batch, decodedEntry{
+			entry: entry,
+			key:   string(keyBytes),
+			unique: key.Type == xdr.LedgerEntryTypeClaimableBalance ||
+				key.Type == xdr.LedgerEntryTypeOffer,
+			oldestBucket: oldestBucket,
+			shard:        r.shard(key.Type, keyBytes),
+		})
+		if len(batch) == parallelBatchSize {
+			if !send(batch) {
+				return nil
+			}
+			batch = make([]decodedEntry, 0, parallelBatchSize)
+		}
+	}
+
+	if len(batch) > 0 {
+		send(batch)
+	}
+	return nil
+}
+
+// shard returns the shard of a ledger key, given its compressed encoding.
+func (r *CheckpointChangeReader) shard(entryType xdr.LedgerEntryType, keyBytes []byte) int {
+	if len(r.shards) == 1 {
+		return 0
+	}
+	if r.shardBy == ShardByEntryType {
+		return int(entryType) % len(r.shards)
+	}
+	hash := fnv.New32a()
+	hash.Write(keyBytes)
+	return int(hash.Sum32() % uint32(len(r.shards)))
+}
+
+// mergeShard sends the entries of a shard which are not shadowed by entries
+// of newer buckets to output, following the rules of streamBucketContents.
+func (r *CheckpointChangeReader) mergeShard(input <-chan []decodedEntry, output chan<- readResult) {
+	visitedLedgerKeys := set.Set[string]{}
+	for entries := range input {
+		for _, entry := range entries {
+			switch entry.entry.Type {
+			case xdr.BucketEntryTypeLiveentry, xdr.BucketEntryTypeInitentry:
+				if !visitedLedgerKeys.Contains(entry.key) {
+					liveEntry := entry.entry.MustLiveEntry()
+					entryChange := xdr.LedgerEntryChange{
+						Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+						State: &liveEntry,
+					}
+					select {
+					case output <- readResult{entryChange, nil}:
+					case <-r.done:
+						return
+					}
+
+					// See streamBucketContents: INITENTRY keys don't need to
+					// be tracked and the oldest bucket has no older entries
+					// to shadow.
+					if entry.entry.Type == xdr.BucketEntryTypeLiveentry && !entry.oldestBucket {
+						visitedLedgerKeys.Add(entry.key)
+					}
+				} else if entry.entry.Type == xdr.BucketEntryTypeInitentry && entry.unique {
+					visitedLedgerKeys.Remove(entry.key)
+				}
+			case xdr.BucketEntryTypeDeadentry:
+				visitedLedgerKeys.Add(entry.key)
+			}
+		}
+	}
+}
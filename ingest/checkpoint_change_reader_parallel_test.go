@@ -0,0 +1,334 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+const parallelTestLedger = uint32(63)
+
+// memoryArchive serves gzip compressed buckets from memory.
+type memoryArchive struct {
+	historyarchive.MockArchive
+	has     historyarchive.HistoryArchiveState
+	buckets map[historyarchive.Hash][]byte
+}
+
+// newMemoryArchive returns an archive with the given buckets, newest first,
+// stored as the curr and snap buckets of the levels of the HAS.
+func newMemoryArchive(t testing.TB, buckets [][]xdr.BucketEntry) *memoryArchive {
+	require.LessOrEqual(t, len(buckets), 2*historyarchive.NumLevels)
+	archive := &memoryArchive{buckets: map[historyarchive.Hash][]byte{}}
+	archive.has.CurrentLedger = parallelTestLedger
+
+	hashes := make([]string, 2*historyarchive.NumLevels)
+	for i := range hashes {
+		hashes[i] = historyarchive.Hash{}.String()
+	}
+	for i, entries := range buckets {
+		var raw, compressed bytes.Buffer
+		for _, entry := range entries {
+			require.NoError(t, xdr.MarshalFramed(&raw, entry))
+		}
+		hash := historyarchive.Hash(sha256.Sum256(raw.Bytes()))
+		writer := gzip.NewWriter(&compressed)
+		_, err := writer.Write(raw.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		archive.buckets[hash] = compressed.Bytes()
+		hashes[i] = hash.String()
+	}
+	for i := range archive.has.CurrentBuckets {
+		archive.has.CurrentBuckets[i].Curr = hashes[2*i]
+		archive.has.CurrentBuckets[i].Snap = hashes[2*i+1]
+	}
+	return archive
+}
+
+func (a *memoryArchive) GetCheckpointManager() historyarchive.CheckpointManager {
+	return historyarchive.NewCheckpointManager(historyarchive.DefaultCheckpointFrequency)
+}
+
+func (a *memoryArchive) GetCheckpointHAS(uint32) (historyarchive.HistoryArchiveState, error) {
+	return a.has, nil
+}
+
+func (a *memoryArchive) BucketExists(hash historyarchive.Hash) (bool, error) {
+	_, ok := a.buckets[hash]
+	return ok, nil
+}
+
+func (a *memoryArchive) BucketSize(hash historyarchive.Hash) (int64, error) {
+	return int64(len(a.buckets[hash])), nil
+}
+
+func (a *memoryArchive) GetXdrStreamForHash(hash historyarchive.Hash) (*xdr.Stream, error) {
+	return xdr.NewGzStream(io.NopCloser(bytes.NewReader(a.buckets[hash])))
+}
+
+// testBucketEntry returns an entry of an account, offer or claimable balance
+// depending on id, with value as its balance or amount.
+func testBucketEntry(t xdr.BucketEntryType, id int, value int64) xdr.BucketEntry {
+	var raw [32]byte
+	binary.BigEndian.PutUint64(raw[:], uint64(id))
+	switch id % 3 {
+	case 0:
+		entry := entryAccount(t, strkey.MustEncode(strkey.VersionByteAccountID, raw[:]), 0)
+		if entry.LiveEntry != nil {
+			entry.LiveEntry.Data.Account.Balance = xdr.Int64(value)
+		}
+		return entry
+	case 1:
+		entry := entryOffer(t, "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7", xdr.Int64(id))
+		if entry.LiveEntry != nil {
+			entry.LiveEntry.Data.Offer.Amount = xdr.Int64(value)
+		}
+		return entry
+	default:
+		return entryCB(t, xdr.Hash(raw), xdr.Int64(value))
+	}
+}
+
+// testBuckets generates count buckets, newest first, of entries created,
+// updated and removed over time following CAP-20: an INITENTRY is only
+// written if there is no live entry with the same key in older buckets, and
+// offers and claimable balances are never recreated. It also returns the
+// state described by the buckets, keyed by ledger key.
+func testBuckets(count, entriesPerBucket int) ([][]xdr.BucketEntry, map[string]xdr.LedgerEntry) {
+	random := rand.New(rand.NewSource(int64(count * entriesPerBucket)))
+	live := map[int]xdr.LedgerEntry{}
+	removed := map[int]bool{}
+
+	buckets := make([][]xdr.BucketEntry, count)
+	for i := count - 1; i >= 0; i-- {
+		bucket := []xdr.BucketEntry{metaEntry(11)}
+		for _, id := range random.Perm(2 * entriesPerBucket)[:entriesPerBucket] {
+			value := int64(count - i)
+			_, exists := live[id]
+			switch {
+			case !exists && removed[id] && id%3 != 0:
+				continue
+			case !exists:
+				entry := testBucketEntry(xdr.BucketEntryTypeInitentry, id, value)
+				bucket = append(bucket, entry)
+				live[id] = *entry.LiveEntry
+			case random.Intn(4) == 0:
+				bucket = append(bucket, testBucketEntry(xdr.BucketEntryTypeDeadentry, id, 0))
+				delete(live, id)
+				removed[id] = true
+			default:
+				entry := testBucketEntry(xdr.BucketEntryTypeLiveentry, id, value)
+				bucket = append(bucket, entry)
+				live[id] = *entry.LiveEntry
+			}
+		}
+		buckets[i] = bucket
+	}
+
+	state := map[string]xdr.LedgerEntry{}
+	for _, entry := range live {
+		state[ledgerKeyString(entry)] = entry
+	}
+	return buckets, state
+}
+
+func ledgerKeyString(entry xdr.LedgerEntry) string {
+	key, err := entry.LedgerKey()
+	if err != nil {
+		panic(err)
+	}
+	keyString, err := key.MarshalBinaryBase64()
+	if err != nil {
+		panic(err)
+	}
+	return keyString
+}
+
+// readAll reads all the changes of reader.
+func readAll(reader ChangeReader) ([]xdr.LedgerEntry, error) {
+	var entries []xdr.LedgerEntry
+	for {
+		change, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, *change.Post)
+	}
+}
+
+// readShards reads all the changes of the shards of reader concurrently.
+func readShards(reader *CheckpointChangeReader) ([][]xdr.LedgerEntry, []error) {
+	shards := reader.Shards()
+	entries := make([][]xdr.LedgerEntry, len(shards))
+	errs := make([]error, len(shards))
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, shard ChangeReader) {
+			defer wg.Done()
+			entries[i], errs[i] = readAll(shard)
+		}(i, shard)
+	}
+	wg.Wait()
+	return entries, errs
+}
+
+func TestParallelCheckpointChangeReader(t *testing.T) {
+	buckets, state := testBuckets(2*historyarchive.NumLevels, 200)
+	archive := newMemoryArchive(t, buckets)
+
+	sequential, err := NewCheckpointChangeReader(context.Background(), archive, parallelTestLedger)
+	require.NoError(t, err)
+	expected, err := readAll(sequential)
+	require.NoError(t, err)
+	require.Len(t, expected, len(state))
+	for _, entry := range expected {
+		assert.Equal(t, state[ledgerKeyString(entry)], entry)
+	}
+
+	for _, concurrency := range []int{1, 4, 32} {
+		reader, err := NewParallelCheckpointChangeReader(
+			context.Background(), archive, parallelTestLedger, ParallelOptions{Concurrency: concurrency},
+		)
+		require.NoError(t, err)
+		entries, err := readAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, expected, entries, "concurrency %d", concurrency)
+	}
+
+	for _, shardBy := range []ShardBy{ShardByKeyHash, ShardByEntryType} {
+		reader, err := NewParallelCheckpointChangeReader(
+			context.Background(), archive, parallelTestLedger, ParallelOptions{Concurrency: 4, Shards: 3, ShardBy: shardBy},
+		)
+		require.NoError(t, err)
+		_, err = reader.Read()
+		assert.EqualError(t, err, "changes are sharded, read them with the readers returned by Shards")
+
+		shards, errs := readShards(reader)
+		var entries []xdr.LedgerEntry
+		for i, shardEntries := range shards {
+			require.NoError(t, errs[i])
+			assert.NotEmpty(t, shardEntries)
+			for _, entry := range shardEntries {
+				key, err := entry.LedgerKey()
+				require.NoError(t, err)
+				keyBytes, err := xdr.NewEncodingBuffer().LedgerKeyUnsafeMarshalBinaryCompress(key)
+				require.NoError(t, err)
+				assert.Equal(t, i, reader.shard(key.Type, keyBytes))
+				if shardBy == ShardByEntryType {
+					assert.Equal(t, i, int(key.Type)%3)
+				}
+			}
+			entries = append(entries, shardEntries...)
+		}
+		assert.ElementsMatch(t, expected, entries, "sharded by %d", shardBy)
+	}
+}
+
+func TestParallelCheckpointChangeReaderErrors(t *testing.T) {
+	buckets, _ := testBuckets(4, 10)
+	buckets[2] = append(buckets[2], metaEntry(11))
+	archive := newMemoryArchive(t, buckets)
+
+	reader, err := NewParallelCheckpointChangeReader(
+		context.Background(), archive, parallelTestLedger, ParallelOptions{Concurrency: 2, Shards: 2},
+	)
+	require.NoError(t, err)
+	_, errs := readShards(reader)
+	for _, err := range errs {
+		assert.ErrorContains(t, err, "Error while reading from buckets: METAENTRY not the first entry (n=11) in the bucket hash")
+	}
+
+	for _, options := range []ParallelOptions{
+		{Concurrency: -1},
+		{Shards: -1},
+		{ShardBy: ShardBy(2)},
+	} {
+		_, err = NewParallelCheckpointChangeReader(context.Background(), archive, parallelTestLedger, options)
+		assert.Error(t, err)
+	}
+	_, err = NewParallelCheckpointChangeReader(context.Background(), archive, 100, ParallelOptions{})
+	assert.ErrorContains(t, err, "100 is not a checkpoint ledger")
+}
+
+func TestParallelCheckpointChangeReaderClose(t *testing.T) {
+	buckets, _ := testBuckets(2*historyarchive.NumLevels, 2000)
+	archive := newMemoryArchive(t, buckets)
+
+	reader, err := NewParallelCheckpointChangeReader(
+		context.Background(), archive, parallelTestLedger, ParallelOptions{Concurrency: 4, Shards: 2},
+	)
+	require.NoError(t, err)
+	shards := reader.Shards()
+	_, err = shards[0].Read()
+	require.NoError(t, err)
+	require.NoError(t, shards[1].Close())
+
+	// the shards are closed without reading the remaining buckets
+	for _, shard := range shards {
+		entries, err := readAll(shard)
+		require.NoError(t, err)
+		assert.Less(t, len(entries), 2*historyarchive.NumLevels*2000)
+	}
+}
+
+// BenchmarkCheckpointChangeReader compares reading a checkpoint with
+// NewCheckpointChangeReader and NewParallelCheckpointChangeReader. Buckets
+// are served from memory so it measures decoding and merging buckets, without
+// downloads.
+func BenchmarkCheckpointChangeReader(b *testing.B) {
+	buckets, state := testBuckets(2*historyarchive.NumLevels, 20000)
+	archive := newMemoryArchive(b, buckets)
+
+	benchmark := func(b *testing.B, newReader func() (*CheckpointChangeReader, error)) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			reader, err := newReader()
+			require.NoError(b, err)
+			shards, errs := readShards(reader)
+			count := 0
+			for j := range shards {
+				require.NoError(b, errs[j])
+				count += len(shards[j])
+			}
+			require.Equal(b, len(state), count)
+		}
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		benchmark(b, func() (*CheckpointChangeReader, error) {
+			return NewCheckpointChangeReader(context.Background(), archive, parallelTestLedger)
+		})
+	})
+	for _, options := range []ParallelOptions{
+		{Concurrency: 4},
+		{Concurrency: 8},
+		{Concurrency: 8, Shards: 4},
+		{Concurrency: 8, Shards: 4, ShardBy: ShardByEntryType},
+	} {
+		name := fmt.Sprintf("parallel/concurrency=%d/shards=%d/shardBy=%d", options.Concurrency, options.Shards, options.ShardBy)
+		b.Run(name, func(b *testing.B) {
+			benchmark(b, func() (*CheckpointChangeReader, error) {
+				return NewParallelCheckpointChangeReader(context.Background(), archive, parallelTestLedger, options)
+			})
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/collections/set"
+	"github.com/stellar/go/support/errors"
+)
+
+type accountFilter struct {
+	addresses set.Set[string]
+}
+
+// NewAccountFilter returns a filter which includes the transactions in which
+// any of the given addresses participates (see Participants).
+//
+// Addresses can be accounts (G...), muxed accounts (M...) or contracts
+// (C...). An account address also matches the activity of all the muxed
+// accounts built on top of it while a muxed account address only matches the
+// activity of that muxed account. The filter is disabled if no addresses are
+// given.
+func NewAccountFilter(addresses ...string) (LedgerTransactionFilterer, error) {
+	f := accountFilter{addresses: set.NewSet[string](len(addresses))}
+	for _, address := range addresses {
+		version, _, err := strkey.DecodeAny(address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid address %s", address)
+		}
+		switch version {
+		case strkey.VersionByteAccountID, strkey.VersionByteMuxedAccount, strkey.VersionByteContract:
+			f.addresses.Add(address)
+		default:
+			return nil, errors.Errorf("address %s is not an account, muxed account or contract", address)
+		}
+	}
+	return f, nil
+}
+
+func (f accountFilter) Name() string {
+	return "filters.accountFilter"
+}
+
+func (f accountFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, bool, error) {
+	if len(f.addresses) == 0 {
+		return false, true, nil
+	}
+
+	participants, err := newTransactionParticipants(transaction)
+	if err != nil {
+		return true, false, err
+	}
+	for address := range participants.addresses {
+		if f.addresses.Contains(address) {
+			return true, true, nil
+		}
+	}
+	return true, false, nil
+}
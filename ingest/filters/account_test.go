@@ -0,0 +1,177 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+func TestParticipants(t *testing.T) {
+	source := randomAccount()
+	destination, err := xdr.MuxedAccountFromAccountId(keypair.MustRandom().Address(), 7)
+	require.NoError(t, err)
+	opSource := randomAccount()
+	trustor := randomAccount().ToAccountId()
+	contractID := xdr.Hash{1}
+	argContractID := xdr.Hash{2}
+	eventContractID := xdr.Hash{3}
+	signer := randomAccount().ToAccountId()
+	eventRecipient := randomAccount().ToAccountId()
+	changedAccount := randomAccount().ToAccountId()
+
+	argVec := &xdr.ScVec{
+		{Type: xdr.ScValTypeScvAddress, Address: &xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &argContractID}},
+	}
+	tx := makeTransaction(
+		source,
+		[]xdr.Operation{
+			{
+				SourceAccount: &opSource,
+				Body: xdr.OperationBody{
+					Type:      xdr.OperationTypePayment,
+					PaymentOp: &xdr.PaymentOp{Destination: destination, Asset: xdr.MustNewNativeAsset()},
+				},
+			},
+			{
+				Body: xdr.OperationBody{
+					Type:         xdr.OperationTypeAllowTrust,
+					AllowTrustOp: &xdr.AllowTrustOp{Trustor: trustor},
+				},
+			},
+			{
+				Body: xdr.OperationBody{
+					Type: xdr.OperationTypeInvokeHostFunction,
+					InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{
+						HostFunction: xdr.HostFunction{
+							Type: xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+							InvokeContract: &xdr.InvokeContractArgs{
+								ContractAddress: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+								FunctionName:    "run",
+								Args:            []xdr.ScVal{{Type: xdr.ScValTypeScvVec, Vec: &argVec}},
+							},
+						},
+						Auth: []xdr.SorobanAuthorizationEntry{
+							{
+								Credentials: xdr.SorobanCredentials{
+									Type: xdr.SorobanCredentialsTypeSorobanCredentialsAddress,
+									Address: &xdr.SorobanAddressCredentials{
+										Address: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &signer},
+									},
+								},
+								RootInvocation: xdr.SorobanAuthorizedInvocation{
+									Function: xdr.SorobanAuthorizedFunction{
+										Type: xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn,
+										ContractFn: &xdr.InvokeContractArgs{
+											ContractAddress: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+											FunctionName:    "run",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		[]xdr.ContractEvent{
+			{
+				ContractId: &eventContractID,
+				Type:       xdr.ContractEventTypeContract,
+				Body: xdr.ContractEventBody{
+					V: 0,
+					V0: &xdr.ContractEventV0{
+						Topics: []xdr.ScVal{
+							{Type: xdr.ScValTypeScvAddress, Address: &xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &eventRecipient}},
+						},
+					},
+				},
+			},
+		},
+		xdr.LedgerEntryChanges{
+			{
+				Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+				Created: &xdr.LedgerEntry{
+					Data: xdr.LedgerEntryData{
+						Type:    xdr.LedgerEntryTypeAccount,
+						Account: &xdr.AccountEntry{AccountId: changedAccount},
+					},
+				},
+			},
+		},
+	)
+
+	participants, err := Participants(tx)
+	require.NoError(t, err)
+
+	contract := func(id xdr.Hash) string {
+		return strkey.MustEncode(strkey.VersionByteContract, id[:])
+	}
+	assert.ElementsMatch(t, []string{
+		source.Address(),
+		destination.Address(),
+		destination.ToAccountId().Address(),
+		opSource.Address(),
+		trustor.Address(),
+		contract(contractID),
+		contract(argContractID),
+		contract(eventContractID),
+		signer.Address(),
+		eventRecipient.Address(),
+		changedAccount.Address(),
+	}, participants)
+}
+
+func TestAccountFilter(t *testing.T) {
+	ctx := context.Background()
+	source := randomAccount()
+	destination, err := xdr.MuxedAccountFromAccountId(keypair.MustRandom().Address(), 7)
+	require.NoError(t, err)
+	otherMuxed, err := xdr.MuxedAccountFromAccountId(destination.ToAccountId().Address(), 8)
+	require.NoError(t, err)
+
+	tx := makeTransaction(source, []xdr.Operation{
+		{
+			Body: xdr.OperationBody{
+				Type:      xdr.OperationTypePayment,
+				PaymentOp: &xdr.PaymentOp{Destination: destination, Asset: xdr.MustNewNativeAsset()},
+			},
+		},
+	}, nil, nil)
+
+	filter, err := NewAccountFilter()
+	require.NoError(t, err)
+	enabled, include, err := filter.FilterTransaction(ctx, tx)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	assert.True(t, include)
+
+	for _, testCase := range []struct {
+		address string
+		include bool
+	}{
+		{source.Address(), true},
+		{destination.Address(), true},
+		{destination.ToAccountId().Address(), true},
+		{otherMuxed.Address(), false},
+		{keypair.MustRandom().Address(), false},
+	} {
+		filter, err = NewAccountFilter(testCase.address)
+		require.NoError(t, err)
+		enabled, include, err = filter.FilterTransaction(ctx, tx)
+		require.NoError(t, err)
+		assert.True(t, enabled)
+		assert.Equal(t, testCase.include, include, testCase.address)
+	}
+
+	_, err = NewAccountFilter("GABC")
+	assert.Error(t, err)
+	seed := keypair.MustRandom().Seed()
+	_, err = NewAccountFilter(seed)
+	assert.EqualError(t, err, "address "+seed+" is not an account, muxed account or contract")
+}
@@ -0,0 +1,152 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/collections/set"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+type assetFilter struct {
+	canonicalAssets set.Set[string]
+	contracts       set.Set[string]
+}
+
+// NewAssetFilter returns a filter which includes the transactions involving
+// any of the given assets, either through classic operations (payments,
+// offers, trustlines, claimable balances, clawbacks), through changes to
+// trustlines of the assets or through invocations and events of the Stellar
+// Asset Contracts of the assets on the network with the given passphrase.
+// The filter is disabled if no assets are given.
+func NewAssetFilter(networkPassphrase string, assets ...xdr.Asset) (LedgerTransactionFilterer, error) {
+	f := assetFilter{
+		canonicalAssets: set.NewSet[string](len(assets)),
+		contracts:       set.NewSet[string](len(assets)),
+	}
+	for _, asset := range assets {
+		contractID, err := asset.ContractID(networkPassphrase)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compute contract id of asset %s", asset.StringCanonical())
+		}
+		contract, err := strkey.Encode(strkey.VersionByteContract, contractID[:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not encode contract id of asset %s", asset.StringCanonical())
+		}
+		f.canonicalAssets.Add(asset.StringCanonical())
+		f.contracts.Add(contract)
+	}
+	return f, nil
+}
+
+func (f assetFilter) Name() string {
+	return "filters.assetFilter"
+}
+
+func (f assetFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, bool, error) {
+	if len(f.canonicalAssets) == 0 {
+		return false, true, nil
+	}
+
+	txSource := transaction.Envelope.SourceAccount()
+	for _, op := range transaction.Envelope.Operations() {
+		source := txSource
+		if op.SourceAccount != nil {
+			source = *op.SourceAccount
+		}
+		if f.operationMatches(source.ToAccountId(), op) {
+			return true, true, nil
+		}
+	}
+
+	participants, err := newTransactionParticipants(transaction)
+	if err != nil {
+		return true, false, err
+	}
+	for contract := range participants.contracts {
+		if f.contracts.Contains(contract) {
+			return true, true, nil
+		}
+	}
+
+	changes, err := transaction.GetChanges()
+	if err != nil {
+		return true, false, errors.Wrap(err, "could not read transaction changes")
+	}
+	for _, change := range changes {
+		if change.Pre != nil && f.ledgerEntryMatches(*change.Pre) {
+			return true, true, nil
+		}
+		if change.Post != nil && f.ledgerEntryMatches(*change.Post) {
+			return true, true, nil
+		}
+	}
+
+	return true, false, nil
+}
+
+func (f assetFilter) operationMatches(source xdr.AccountId, op xdr.Operation) bool {
+	switch op.Body.Type {
+	case xdr.OperationTypePayment:
+		return f.matches(op.Body.MustPaymentOp().Asset)
+	case xdr.OperationTypePathPaymentStrictReceive:
+		body := op.Body.MustPathPaymentStrictReceiveOp()
+		return f.matches(body.SendAsset) || f.matches(body.DestAsset) || f.matchesAny(body.Path)
+	case xdr.OperationTypePathPaymentStrictSend:
+		body := op.Body.MustPathPaymentStrictSendOp()
+		return f.matches(body.SendAsset) || f.matches(body.DestAsset) || f.matchesAny(body.Path)
+	case xdr.OperationTypeManageSellOffer:
+		body := op.Body.MustManageSellOfferOp()
+		return f.matches(body.Selling) || f.matches(body.Buying)
+	case xdr.OperationTypeManageBuyOffer:
+		body := op.Body.MustManageBuyOfferOp()
+		return f.matches(body.Selling) || f.matches(body.Buying)
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		body := op.Body.MustCreatePassiveSellOfferOp()
+		return f.matches(body.Selling) || f.matches(body.Buying)
+	case xdr.OperationTypeChangeTrust:
+		line := op.Body.MustChangeTrustOp().Line
+		if pool, ok := line.GetLiquidityPool(); ok {
+			return f.matches(pool.ConstantProduct.AssetA) || f.matches(pool.ConstantProduct.AssetB)
+		}
+		return f.matches(line.ToAsset())
+	case xdr.OperationTypeAllowTrust:
+		return f.matches(op.Body.MustAllowTrustOp().Asset.ToAsset(source))
+	case xdr.OperationTypeSetTrustLineFlags:
+		return f.matches(op.Body.MustSetTrustLineFlagsOp().Asset)
+	case xdr.OperationTypeCreateClaimableBalance:
+		return f.matches(op.Body.MustCreateClaimableBalanceOp().Asset)
+	case xdr.OperationTypeClawback:
+		return f.matches(op.Body.MustClawbackOp().Asset)
+	}
+	return false
+}
+
+func (f assetFilter) ledgerEntryMatches(entry xdr.LedgerEntry) bool {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeTrustline:
+		asset := entry.Data.MustTrustLine().Asset
+		if asset.Type == xdr.AssetTypeAssetTypePoolShare {
+			return false
+		}
+		return f.matches(asset.ToAsset())
+	case xdr.LedgerEntryTypeClaimableBalance:
+		return f.matches(entry.Data.MustClaimableBalance().Asset)
+	}
+	return false
+}
+
+func (f assetFilter) matches(asset xdr.Asset) bool {
+	return f.canonicalAssets.Contains(asset.StringCanonical())
+}
+
+func (f assetFilter) matchesAny(assets []xdr.Asset) bool {
+	for _, asset := range assets {
+		if f.matches(asset) {
+			return true
+		}
+	}
+	return false
+}
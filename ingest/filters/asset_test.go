@@ -0,0 +1,134 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+func TestAssetFilter(t *testing.T) {
+	ctx := context.Background()
+	issuer := randomAccount()
+	usd := xdr.MustNewCreditAsset("USD", issuer.Address())
+	eur := xdr.MustNewCreditAsset("EUR", issuer.Address())
+	usdContractID, err := usd.ContractID(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	usdContractHash := xdr.Hash(usdContractID)
+	usdCode, err := xdr.NewAssetCodeFromString("USD")
+	require.NoError(t, err)
+
+	filter, err := NewAssetFilter(network.TestNetworkPassphrase, usd)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		name    string
+		tx      func() ([]xdr.Operation, []xdr.ContractEvent, xdr.LedgerEntryChanges)
+		include bool
+	}{
+		{
+			name: "payment",
+			tx: func() ([]xdr.Operation, []xdr.ContractEvent, xdr.LedgerEntryChanges) {
+				return []xdr.Operation{{
+					Body: xdr.OperationBody{
+						Type:      xdr.OperationTypePayment,
+						PaymentOp: &xdr.PaymentOp{Destination: randomAccount(), Asset: usd},
+					},
+				}}, nil, nil
+			},
+			include: true,
+		},
+		{
+			name: "payment of other asset",
+			tx: func() ([]xdr.Operation, []xdr.ContractEvent, xdr.LedgerEntryChanges) {
+				return []xdr.Operation{{
+					Body: xdr.OperationBody{
+						Type:      xdr.OperationTypePayment,
+						PaymentOp: &xdr.PaymentOp{Destination: randomAccount(), Asset: eur},
+					},
+				}}, nil, nil
+			},
+			include: false,
+		},
+		{
+			name: "path payment through asset",
+			tx: func() ([]xdr.Operation, []xdr.ContractEvent, xdr.LedgerEntryChanges) {
+				return []xdr.Operation{{
+					Body: xdr.OperationBody{
+						Type: xdr.OperationTypePathPaymentStrictSend,
+						PathPaymentStrictSendOp: &xdr.PathPaymentStrictSendOp{
+							Destination: randomAccount(),
+							SendAsset:   eur,
+							DestAsset:   xdr.MustNewNativeAsset(),
+							Path:        []xdr.Asset{usd},
+						},
+					},
+				}}, nil, nil
+			},
+			include: true,
+		},
+		{
+			name: "allow trust by issuer",
+			tx: func() ([]xdr.Operation, []xdr.ContractEvent, xdr.LedgerEntryChanges) {
+				return []xdr.Operation{{
+					SourceAccount: &issuer,
+					Body: xdr.OperationBody{
+						Type: xdr.OperationTypeAllowTrust,
+						AllowTrustOp: &xdr.AllowTrustOp{
+							Trustor: randomAccount().ToAccountId(),
+							Asset:   usdCode,
+						},
+					},
+				}}, nil, nil
+			},
+			include: true,
+		},
+		{
+			name: "stellar asset contract event",
+			tx: func() ([]xdr.Operation, []xdr.ContractEvent, xdr.LedgerEntryChanges) {
+				return nil, []xdr.ContractEvent{{
+					ContractId: &usdContractHash,
+					Type:       xdr.ContractEventTypeContract,
+					Body:       xdr.ContractEventBody{V: 0, V0: &xdr.ContractEventV0{}},
+				}}, nil
+			},
+			include: true,
+		},
+		{
+			name: "trustline change",
+			tx: func() ([]xdr.Operation, []xdr.ContractEvent, xdr.LedgerEntryChanges) {
+				return nil, nil, xdr.LedgerEntryChanges{{
+					Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+					Created: &xdr.LedgerEntry{
+						Data: xdr.LedgerEntryData{
+							Type: xdr.LedgerEntryTypeTrustline,
+							TrustLine: &xdr.TrustLineEntry{
+								AccountId: randomAccount().ToAccountId(),
+								Asset:     usd.ToTrustLineAsset(),
+							},
+						},
+					},
+				}}
+			},
+			include: true,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			ops, events, changes := testCase.tx()
+			enabled, include, err := filter.FilterTransaction(ctx, makeTransaction(randomAccount(), ops, events, changes))
+			require.NoError(t, err)
+			assert.True(t, enabled)
+			assert.Equal(t, testCase.include, include)
+		})
+	}
+
+	filter, err = NewAssetFilter(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	enabled, _, err := filter.FilterTransaction(ctx, makeTransaction(randomAccount(), nil, nil, nil))
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
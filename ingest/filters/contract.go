@@ -0,0 +1,51 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/collections/set"
+	"github.com/stellar/go/support/errors"
+)
+
+type contractFilter struct {
+	contracts set.Set[string]
+}
+
+// NewContractFilter returns a filter which includes the transactions which
+// invoke (directly or through an authorized sub-invocation) any of the given
+// contracts, pass any of them as an argument, in which any of them emits an
+// event or which change the ledger entries of any of them. Contract ids must be C... strkeys.
+// The filter is disabled if no contract ids are given.
+func NewContractFilter(contractIDs ...string) (LedgerTransactionFilterer, error) {
+	f := contractFilter{contracts: set.NewSet[string](len(contractIDs))}
+	for _, contractID := range contractIDs {
+		if _, err := strkey.Decode(strkey.VersionByteContract, contractID); err != nil {
+			return nil, errors.Wrapf(err, "invalid contract id %s", contractID)
+		}
+		f.contracts.Add(contractID)
+	}
+	return f, nil
+}
+
+func (f contractFilter) Name() string {
+	return "filters.contractFilter"
+}
+
+func (f contractFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, bool, error) {
+	if len(f.contracts) == 0 {
+		return false, true, nil
+	}
+
+	participants, err := newTransactionParticipants(transaction)
+	if err != nil {
+		return true, false, err
+	}
+	for contract := range participants.contracts {
+		if f.contracts.Contains(contract) {
+			return true, true, nil
+		}
+	}
+	return true, false, nil
+}
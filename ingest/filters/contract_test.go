@@ -0,0 +1,93 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+func invokeContractOp(contractID xdr.Hash, subInvocations ...xdr.SorobanAuthorizedInvocation) xdr.Operation {
+	address := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID}
+	return xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{
+				HostFunction: xdr.HostFunction{
+					Type:           xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+					InvokeContract: &xdr.InvokeContractArgs{ContractAddress: address, FunctionName: "run"},
+				},
+				Auth: []xdr.SorobanAuthorizationEntry{{
+					Credentials: xdr.SorobanCredentials{Type: xdr.SorobanCredentialsTypeSorobanCredentialsSourceAccount},
+					RootInvocation: xdr.SorobanAuthorizedInvocation{
+						Function: xdr.SorobanAuthorizedFunction{
+							Type:       xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn,
+							ContractFn: &xdr.InvokeContractArgs{ContractAddress: address, FunctionName: "run"},
+						},
+						SubInvocations: subInvocations,
+					},
+				}},
+			},
+		},
+	}
+}
+
+func TestContractFilter(t *testing.T) {
+	ctx := context.Background()
+	contractID := xdr.Hash{1}
+	otherContractID := xdr.Hash{2}
+	contract := strkey.MustEncode(strkey.VersionByteContract, contractID[:])
+
+	filter, err := NewContractFilter(contract)
+	require.NoError(t, err)
+
+	subInvocation := xdr.SorobanAuthorizedInvocation{
+		Function: xdr.SorobanAuthorizedFunction{
+			Type: xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn,
+			ContractFn: &xdr.InvokeContractArgs{
+				ContractAddress: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+				FunctionName:    "transfer",
+			},
+		},
+	}
+	dataChange := xdr.LedgerEntryChanges{{
+		Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+		Created: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeContractData,
+				ContractData: &xdr.ContractDataEntry{
+					Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+					Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+					Durability: xdr.ContractDataDurabilityPersistent,
+				},
+			},
+		},
+	}}
+
+	for _, testCase := range []struct {
+		name    string
+		ops     []xdr.Operation
+		changes xdr.LedgerEntryChanges
+		include bool
+	}{
+		{"invocation", []xdr.Operation{invokeContractOp(contractID)}, nil, true},
+		{"sub invocation", []xdr.Operation{invokeContractOp(otherContractID, subInvocation)}, nil, true},
+		{"contract data change", nil, dataChange, true},
+		{"other contract", []xdr.Operation{invokeContractOp(otherContractID)}, nil, false},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			tx := makeTransaction(randomAccount(), testCase.ops, nil, testCase.changes)
+			enabled, include, err := filter.FilterTransaction(ctx, tx)
+			require.NoError(t, err)
+			assert.True(t, enabled)
+			assert.Equal(t, testCase.include, include)
+		})
+	}
+
+	_, err = NewContractFilter(randomAccount().ToAccountId().Address())
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// EventFilter describes the contract events matched by a contract event
+// filter.
+type EventFilter struct {
+	// ContractID is the C... strkey of the contract emitting the events. Events
+	// emitted by any contract are matched if it's empty.
+	ContractID string
+	// Topics are matched against the leading topics of the events. A nil
+	// segment matches any value. For example, the topics
+	// {"transfer", nil, <address>} match Stellar Asset Contract transfers to
+	// <address>.
+	Topics []*xdr.ScVal
+}
+
+type contractEventFilter struct {
+	filters []contractEventMatcher
+}
+
+type contractEventMatcher struct {
+	contractID *xdr.Hash
+	topics     []*xdr.ScVal
+}
+
+// NewContractEventFilter returns a filter which includes the transactions
+// emitting a contract event matching any of the given event filters. The
+// filter is disabled if no event filters are given.
+func NewContractEventFilter(filters ...EventFilter) (LedgerTransactionFilterer, error) {
+	f := contractEventFilter{filters: make([]contractEventMatcher, 0, len(filters))}
+	for _, filter := range filters {
+		matcher := contractEventMatcher{topics: filter.Topics}
+		if filter.ContractID != "" {
+			raw, err := strkey.Decode(strkey.VersionByteContract, filter.ContractID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid contract id %s", filter.ContractID)
+			}
+			var contractID xdr.Hash
+			copy(contractID[:], raw)
+			matcher.contractID = &contractID
+		}
+		f.filters = append(f.filters, matcher)
+	}
+	return f, nil
+}
+
+func (f contractEventFilter) Name() string {
+	return "filters.contractEventFilter"
+}
+
+func (f contractEventFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, bool, error) {
+	if len(f.filters) == 0 {
+		return false, true, nil
+	}
+
+	participants, err := newTransactionParticipants(transaction)
+	if err != nil {
+		return true, false, err
+	}
+	for _, event := range participants.events {
+		for _, matcher := range f.filters {
+			if matcher.matches(event) {
+				return true, true, nil
+			}
+		}
+	}
+	return true, false, nil
+}
+
+func (m contractEventMatcher) matches(event xdr.ContractEvent) bool {
+	if m.contractID != nil && (event.ContractId == nil || *event.ContractId != *m.contractID) {
+		return false
+	}
+	v0, ok := event.Body.GetV0()
+	if !ok || len(v0.Topics) < len(m.topics) {
+		return false
+	}
+	for i, topic := range m.topics {
+		if topic != nil && !topic.Equals(v0.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+func TestContractEventFilter(t *testing.T) {
+	ctx := context.Background()
+	contractID := xdr.Hash{1}
+	contract := strkey.MustEncode(strkey.VersionByteContract, contractID[:])
+	recipient := randomAccount().ToAccountId()
+
+	transfer := xdr.ScSymbol("transfer")
+	mint := xdr.ScSymbol("mint")
+	transferTopic := xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &transfer}
+	mintTopic := xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &mint}
+	recipientTopic := xdr.ScVal{
+		Type:    xdr.ScValTypeScvAddress,
+		Address: &xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &recipient},
+	}
+
+	tx := makeTransaction(randomAccount(), nil, []xdr.ContractEvent{{
+		ContractId: &contractID,
+		Type:       xdr.ContractEventTypeContract,
+		Body: xdr.ContractEventBody{
+			V: 0,
+			V0: &xdr.ContractEventV0{
+				Topics: []xdr.ScVal{transferTopic, recipientTopic, recipientTopic},
+			},
+		},
+	}}, nil)
+
+	for _, testCase := range []struct {
+		name    string
+		filters []EventFilter
+		include bool
+	}{
+		{"any event", []EventFilter{{}}, true},
+		{"contract", []EventFilter{{ContractID: contract}}, true},
+		{"other contract", []EventFilter{{ContractID: strkey.MustEncode(strkey.VersionByteContract, make([]byte, 32))}}, false},
+		{"topic", []EventFilter{{Topics: []*xdr.ScVal{&transferTopic}}}, true},
+		{"wildcard topic", []EventFilter{{ContractID: contract, Topics: []*xdr.ScVal{nil, nil, &recipientTopic}}}, true},
+		{"other topic", []EventFilter{{Topics: []*xdr.ScVal{&mintTopic}}}, false},
+		{"too many topics", []EventFilter{{Topics: []*xdr.ScVal{nil, nil, nil, nil}}}, false},
+		{"any of filters", []EventFilter{{Topics: []*xdr.ScVal{&mintTopic}}, {Topics: []*xdr.ScVal{&transferTopic}}}, true},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			filter, err := NewContractEventFilter(testCase.filters...)
+			require.NoError(t, err)
+			enabled, include, err := filter.FilterTransaction(ctx, tx)
+			require.NoError(t, err)
+			assert.True(t, enabled)
+			assert.Equal(t, testCase.include, include)
+		})
+	}
+
+	filter, err := NewContractEventFilter()
+	require.NoError(t, err)
+	enabled, include, err := filter.FilterTransaction(ctx, tx)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	assert.True(t, include)
+
+	_, err = NewContractEventFilter(EventFilter{ContractID: "C123"})
+	assert.Error(t, err)
+}
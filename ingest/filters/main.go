@@ -0,0 +1,101 @@
+// Package filters contains ledger transaction filters which can be used to
+// only process the transactions relevant to a set of accounts, assets,
+// contracts or contract events when reading ledgers with
+// ingest.LedgerTransactionReader.
+//
+// Every filter implements LedgerTransactionFilterer, the same interface used
+// by Horizon's ingestion filters, so filters from this package can be
+// combined with (or replace) the filters used by Horizon.
+package filters
+
+import (
+	"context"
+
+	"github.com/stellar/go/ingest"
+)
+
+// LedgerTransactionFilterer decides whether a transaction should be processed.
+//
+// FilterTransaction returns whether the filter is enabled and, if it is,
+// whether the transaction should be included. Disabled filters (for example,
+// filters created without any rules) are ignored when combined.
+type LedgerTransactionFilterer interface {
+	Name() string
+	FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, bool, error)
+}
+
+type anyOfFilter struct {
+	filters []LedgerTransactionFilterer
+}
+
+// AnyOf returns a filter which includes a transaction if any of the enabled
+// filters includes it. The returned filter is disabled if none of the given
+// filters is enabled.
+func AnyOf(filters ...LedgerTransactionFilterer) LedgerTransactionFilterer {
+	return anyOfFilter{filters: filters}
+}
+
+func (f anyOfFilter) Name() string {
+	return "filters.anyOf"
+}
+
+func (f anyOfFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, bool, error) {
+	enabled := false
+	for _, filter := range f.filters {
+		filterEnabled, include, err := filter.FilterTransaction(ctx, transaction)
+		if err != nil {
+			return false, false, err
+		}
+		if !filterEnabled {
+			continue
+		}
+		if include {
+			return true, true, nil
+		}
+		enabled = true
+	}
+	return enabled, !enabled, nil
+}
+
+type allOfFilter struct {
+	filters []LedgerTransactionFilterer
+}
+
+// AllOf returns a filter which includes a transaction only if all of the
+// enabled filters include it. The returned filter is disabled if none of the
+// given filters is enabled.
+func AllOf(filters ...LedgerTransactionFilterer) LedgerTransactionFilterer {
+	return allOfFilter{filters: filters}
+}
+
+func (f allOfFilter) Name() string {
+	return "filters.allOf"
+}
+
+func (f allOfFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, bool, error) {
+	enabled := false
+	for _, filter := range f.filters {
+		filterEnabled, include, err := filter.FilterTransaction(ctx, transaction)
+		if err != nil {
+			return false, false, err
+		}
+		if !filterEnabled {
+			continue
+		}
+		if !include {
+			return true, false, nil
+		}
+		enabled = true
+	}
+	return enabled, true, nil
+}
+
+// Include returns true if the transaction should be processed according to
+// the given filter. Transactions are always included by disabled filters.
+func Include(ctx context.Context, filter LedgerTransactionFilterer, transaction ingest.LedgerTransaction) (bool, error) {
+	enabled, include, err := filter.FilterTransaction(ctx, transaction)
+	if err != nil {
+		return false, err
+	}
+	return !enabled || include, nil
+}
@@ -0,0 +1,87 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/xdr"
+)
+
+type staticFilter struct {
+	enabled, include bool
+}
+
+func (f staticFilter) Name() string {
+	return "staticFilter"
+}
+
+func (f staticFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, bool, error) {
+	return f.enabled, f.include, nil
+}
+
+func makeTransaction(source xdr.MuxedAccount, ops []xdr.Operation, events []xdr.ContractEvent, changes xdr.LedgerEntryChanges) ingest.LedgerTransaction {
+	return ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: source,
+					Operations:    ops,
+				},
+			},
+		},
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 3,
+			V3: &xdr.TransactionMetaV3{
+				TxChangesAfter: changes,
+				SorobanMeta: &xdr.SorobanTransactionMeta{
+					Events: events,
+				},
+			},
+		},
+	}
+}
+
+func randomAccount() xdr.MuxedAccount {
+	return xdr.MustMuxedAddress(keypair.MustRandom().Address())
+}
+
+func TestCombinators(t *testing.T) {
+	ctx := context.Background()
+	tx := ingest.LedgerTransaction{}
+	disabled := staticFilter{enabled: false, include: true}
+	included := staticFilter{enabled: true, include: true}
+	excluded := staticFilter{enabled: true, include: false}
+
+	for _, testCase := range []struct {
+		name    string
+		filter  LedgerTransactionFilterer
+		enabled bool
+		include bool
+	}{
+		{"any of nothing", AnyOf(), false, true},
+		{"any of disabled", AnyOf(disabled, disabled), false, true},
+		{"any of excluded", AnyOf(disabled, excluded), true, false},
+		{"any of included", AnyOf(excluded, included), true, true},
+		{"all of nothing", AllOf(), false, true},
+		{"all of disabled", AllOf(disabled), false, true},
+		{"all of excluded", AllOf(included, excluded), true, false},
+		{"all of included", AllOf(disabled, included), true, true},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			enabled, include, err := testCase.filter.FilterTransaction(ctx, tx)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.enabled, enabled)
+			assert.Equal(t, testCase.include, include)
+
+			include, err = Include(ctx, testCase.filter, tx)
+			require.NoError(t, err)
+			assert.Equal(t, !testCase.enabled || testCase.include, include)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package filters
+
+import (
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/support/collections/set"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// transactionParticipants holds the addresses which take part in a
+// transaction.
+type transactionParticipants struct {
+	// addresses contains the G, M and C addresses of all the participants.
+	addresses set.Set[string]
+	// contracts contains the C addresses of the contracts which were invoked,
+	// passed as arguments, emitted events or had their ledger entries changed.
+	contracts set.Set[string]
+	// events contains the contract events emitted by the transaction.
+	events []xdr.ContractEvent
+}
+
+// Participants returns the addresses of the accounts (G), muxed accounts (M)
+// and contracts (C) taking part in the transaction. This includes:
+//   - the transaction, fee bump and operation source accounts,
+//   - the destinations, trustors, claimants and sponsored accounts of classic
+//     operations,
+//   - the invoked contracts, contract invocation arguments and authorized
+//     addresses of Soroban operations,
+//   - the emitting contracts and addresses found in contract events, which
+//     covers the senders and recipients of Stellar Asset Contract transfers,
+//   - the accounts and contracts whose ledger entries were changed.
+func Participants(transaction ingest.LedgerTransaction) ([]string, error) {
+	participants, err := newTransactionParticipants(transaction)
+	if err != nil {
+		return nil, err
+	}
+	return participants.addresses.Slice(), nil
+}
+
+func newTransactionParticipants(transaction ingest.LedgerTransaction) (*transactionParticipants, error) {
+	p := &transactionParticipants{
+		addresses: set.NewSet[string](4),
+		contracts: set.NewSet[string](1),
+	}
+
+	envelope := transaction.Envelope
+	p.addMuxedAccount(envelope.SourceAccount())
+	if envelope.IsFeeBump() {
+		p.addMuxedAccount(envelope.FeeBumpAccount())
+	}
+	for _, op := range envelope.Operations() {
+		if op.SourceAccount != nil {
+			p.addMuxedAccount(*op.SourceAccount)
+		}
+		p.addOperation(op)
+	}
+
+	if transaction.UnsafeMeta.V == 3 && transaction.UnsafeMeta.MustV3().SorobanMeta != nil {
+		for _, event := range transaction.UnsafeMeta.MustV3().SorobanMeta.Events {
+			p.addEvent(event)
+		}
+	}
+
+	changes, err := transaction.GetChanges()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read transaction changes")
+	}
+	for _, change := range append(transaction.GetFeeChanges(), changes...) {
+		if change.Pre != nil {
+			p.addLedgerEntry(*change.Pre)
+		}
+		if change.Post != nil {
+			p.addLedgerEntry(*change.Post)
+		}
+	}
+
+	return p, nil
+}
+
+func (p *transactionParticipants) addMuxedAccount(account xdr.MuxedAccount) {
+	p.addresses.Add(account.ToAccountId().Address())
+	if account.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		p.addresses.Add(account.Address())
+	}
+}
+
+func (p *transactionParticipants) addContract(contractID xdr.Hash) {
+	address := xdr.ScAddress{
+		Type:       xdr.ScAddressTypeScAddressTypeContract,
+		ContractId: &contractID,
+	}
+	p.addAddress(address)
+}
+
+func (p *transactionParticipants) addAddress(address xdr.ScAddress) {
+	encoded, err := address.String()
+	if err != nil {
+		// unknown address types can't be matched against any filter
+		return
+	}
+	p.addresses.Add(encoded)
+	if address.Type == xdr.ScAddressTypeScAddressTypeContract {
+		p.contracts.Add(encoded)
+	}
+}
+
+// addScVal adds all the addresses found in the (possibly nested) value.
+func (p *transactionParticipants) addScVal(val xdr.ScVal) {
+	switch val.Type {
+	case xdr.ScValTypeScvAddress:
+		p.addAddress(val.MustAddress())
+	case xdr.ScValTypeScvVec:
+		if vec, ok := val.GetVec(); ok && vec != nil {
+			for _, item := range *vec {
+				p.addScVal(item)
+			}
+		}
+	case xdr.ScValTypeScvMap:
+		if m, ok := val.GetMap(); ok && m != nil {
+			for _, entry := range *m {
+				p.addScVal(entry.Key)
+				p.addScVal(entry.Val)
+			}
+		}
+	}
+}
+
+func (p *transactionParticipants) addOperation(op xdr.Operation) {
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		p.addresses.Add(op.Body.MustCreateAccountOp().Destination.Address())
+	case xdr.OperationTypePayment:
+		p.addMuxedAccount(op.Body.MustPaymentOp().Destination)
+	case xdr.OperationTypePathPaymentStrictReceive:
+		p.addMuxedAccount(op.Body.MustPathPaymentStrictReceiveOp().Destination)
+	case xdr.OperationTypePathPaymentStrictSend:
+		p.addMuxedAccount(op.Body.MustPathPaymentStrictSendOp().Destination)
+	case xdr.OperationTypeAccountMerge:
+		p.addMuxedAccount(op.Body.MustDestination())
+	case xdr.OperationTypeAllowTrust:
+		p.addresses.Add(op.Body.MustAllowTrustOp().Trustor.Address())
+	case xdr.OperationTypeSetTrustLineFlags:
+		p.addresses.Add(op.Body.MustSetTrustLineFlagsOp().Trustor.Address())
+	case xdr.OperationTypeClawback:
+		p.addMuxedAccount(op.Body.MustClawbackOp().From)
+	case xdr.OperationTypeBeginSponsoringFutureReserves:
+		p.addresses.Add(op.Body.MustBeginSponsoringFutureReservesOp().SponsoredId.Address())
+	case xdr.OperationTypeCreateClaimableBalance:
+		for _, claimant := range op.Body.MustCreateClaimableBalanceOp().Claimants {
+			if v0, ok := claimant.GetV0(); ok {
+				p.addresses.Add(v0.Destination.Address())
+			}
+		}
+	case xdr.OperationTypeInvokeHostFunction:
+		invokeOp := op.Body.MustInvokeHostFunctionOp()
+		p.addHostFunction(invokeOp.HostFunction)
+		for _, entry := range invokeOp.Auth {
+			if credentials, ok := entry.Credentials.GetAddress(); ok {
+				p.addAddress(credentials.Address)
+			}
+			p.addAuthorizedInvocation(entry.RootInvocation)
+		}
+	}
+}
+
+func (p *transactionParticipants) addHostFunction(fn xdr.HostFunction) {
+	switch fn.Type {
+	case xdr.HostFunctionTypeHostFunctionTypeInvokeContract:
+		p.addInvocation(fn.MustInvokeContract())
+	case xdr.HostFunctionTypeHostFunctionTypeCreateContract:
+		p.addContractIDPreimage(fn.MustCreateContract().ContractIdPreimage)
+	case xdr.HostFunctionTypeHostFunctionTypeCreateContractV2:
+		args := fn.MustCreateContractV2()
+		p.addContractIDPreimage(args.ContractIdPreimage)
+		for _, arg := range args.ConstructorArgs {
+			p.addScVal(arg)
+		}
+	}
+}
+
+func (p *transactionParticipants) addAuthorizedInvocation(invocation xdr.SorobanAuthorizedInvocation) {
+	switch invocation.Function.Type {
+	case xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn:
+		p.addInvocation(invocation.Function.MustContractFn())
+	case xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeCreateContractHostFn:
+		p.addContractIDPreimage(invocation.Function.MustCreateContractHostFn().ContractIdPreimage)
+	case xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeCreateContractV2HostFn:
+		args := invocation.Function.MustCreateContractV2HostFn()
+		p.addContractIDPreimage(args.ContractIdPreimage)
+		for _, arg := range args.ConstructorArgs {
+			p.addScVal(arg)
+		}
+	}
+	for _, subInvocation := range invocation.SubInvocations {
+		p.addAuthorizedInvocation(subInvocation)
+	}
+}
+
+func (p *transactionParticipants) addInvocation(args xdr.InvokeContractArgs) {
+	p.addAddress(args.ContractAddress)
+	for _, arg := range args.Args {
+		p.addScVal(arg)
+	}
+}
+
+func (p *transactionParticipants) addContractIDPreimage(preimage xdr.ContractIdPreimage) {
+	if fromAddress, ok := preimage.GetFromAddress(); ok {
+		p.addAddress(fromAddress.Address)
+	}
+}
+
+func (p *transactionParticipants) addEvent(event xdr.ContractEvent) {
+	p.events = append(p.events, event)
+	if event.ContractId != nil {
+		p.addContract(*event.ContractId)
+	}
+	if v0, ok := event.Body.GetV0(); ok {
+		for _, topic := range v0.Topics {
+			p.addScVal(topic)
+		}
+		p.addScVal(v0.Data)
+	}
+}
+
+func (p *transactionParticipants) addLedgerEntry(entry xdr.LedgerEntry) {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		p.addresses.Add(entry.Data.MustAccount().AccountId.Address())
+	case xdr.LedgerEntryTypeTrustline:
+		p.addresses.Add(entry.Data.MustTrustLine().AccountId.Address())
+	case xdr.LedgerEntryTypeContractData:
+		contractData := entry.Data.MustContractData()
+		p.addAddress(contractData.Contract)
+		// balances and allowances of token contracts are keyed by the
+		// addresses of their holders
+		p.addScVal(contractData.Key)
+	}
+}
@@ -620,3 +620,33 @@ func TestLedgerBufferRetryLimit(t *testing.T) {
 	assert.ErrorContains(t, err, objectName)
 	assert.ErrorContains(t, err, "transient error")
 }
+
+func TestBSBGetLedger_FilesystemDataStore(t *testing.T) {
+	ctx := context.Background()
+	schema := datastore.DataStoreSchema{
+		LedgersPerFile:    2,
+		FilesPerPartition: 4,
+	}
+	dataStore, err := datastore.NewFilesystemDataStore(t.TempDir(), schema)
+	assert.NoError(t, err)
+
+	for start := uint32(2); start <= 10; start += schema.LedgersPerFile {
+		end := start + schema.LedgersPerFile - 1
+		batch := createTestLedgerCloseMetaBatch(start, end, schema.LedgersPerFile)
+		encoder := compressxdr.NewXDREncoder(compressxdr.DefaultCompressor, batch)
+		ok, err := dataStore.PutFileIfNotExists(ctx, schema.GetObjectKeyFromSequenceNumber(start), encoder, nil)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	bsb, err := NewBufferedStorageBackend(createBufferedStorageBackendConfigForTesting(), dataStore)
+	assert.NoError(t, err)
+	defer bsb.Close()
+
+	assert.NoError(t, bsb.PrepareRange(ctx, BoundedRange(3, 10)))
+	for seq := uint32(3); seq <= 10; seq++ {
+		lcm, err := bsb.GetLedger(ctx, seq)
+		assert.NoError(t, err)
+		assert.Equal(t, createLedgerCloseMeta(seq), lcm)
+	}
+}
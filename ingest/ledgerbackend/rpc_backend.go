@@ -0,0 +1,386 @@
+package ledgerbackend
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/stellar/go/clients/rpcclient"
+	proto "github.com/stellar/go/protocols/rpc"
+	"github.com/stellar/go/xdr"
+)
+
+// Ensure RPCLedgerBackend implements LedgerBackend
+var _ LedgerBackend = (*RPCLedgerBackend)(nil)
+
+// RPCLedgerBackendOptions configures an RPCLedgerBackend.
+type RPCLedgerBackendOptions struct {
+	// RPCServerURL is the URL of the Stellar RPC server.
+	RPCServerURL string
+	// BufferSize is the maximum number of ledgers prefetched ahead of
+	// GetLedger. It is also the page size used for getLedgers requests.
+	BufferSize uint32
+	// RetryLimit is the number of times a failed request is retried before
+	// giving up.
+	RetryLimit uint32
+	// RetryWait is the time to wait between retries and between polls for new
+	// ledgers when the prepared range is unbounded.
+	RetryWait time.Duration
+	// HttpClient is the client used to reach the RPC server. If nil,
+	// http.DefaultClient is used.
+	HttpClient *http.Client
+}
+
+// RPCLedgerBackend is a ledger backend that streams ledgers from a Stellar
+// RPC server using the getLedgers method.
+type RPCLedgerBackend struct {
+	config RPCLedgerBackendOptions
+	client *rpcclient.Client
+
+	rpcBackendLock sync.RWMutex
+
+	buffer     *rpcLedgerBuffer
+	prepared   *Range // Non-nil if any range is prepared
+	closed     bool   // False until the backend is closed
+	lastLCM    xdr.LedgerCloseMeta
+	nextLedger uint32
+	lastLedger uint32
+}
+
+// NewRPCLedgerBackend returns a new RPCLedgerBackend instance.
+func NewRPCLedgerBackend(options RPCLedgerBackendOptions) (*RPCLedgerBackend, error) {
+	if options.RPCServerURL == "" {
+		return nil, errors.New("RPC server URL must be set")
+	}
+
+	if options.BufferSize == 0 {
+		return nil, errors.New("buffer size must be > 0")
+	}
+
+	return &RPCLedgerBackend{
+		config: options,
+		client: rpcclient.NewClient(options.RPCServerURL, options.HttpClient),
+	}, nil
+}
+
+// getLedgers fetches up to limit ledgers starting at startLedger.
+func (rb *RPCLedgerBackend) getLedgers(ctx context.Context, startLedger, limit uint32) ([]xdr.LedgerCloseMeta, error) {
+	page, err := rb.client.GetLedgers(ctx, proto.GetLedgersRequest{
+		StartLedger: startLedger,
+		Pagination:  &proto.PaginationOptions{Limit: uint(limit)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ledgers := make([]xdr.LedgerCloseMeta, 0, len(page.Ledgers))
+	for _, ledger := range page.Ledgers {
+		if ledger.Meta.LedgerSequence() != ledger.Sequence {
+			return nil, errors.Errorf("ledger %d has metadata for ledger %d", ledger.Sequence, ledger.Meta.LedgerSequence())
+		}
+		ledgers = append(ledgers, ledger.Meta)
+	}
+	return ledgers, nil
+}
+
+// GetLatestLedgerSequence returns the sequence of the latest ledger known to the RPC server.
+func (rb *RPCLedgerBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	rb.rpcBackendLock.RLock()
+	defer rb.rpcBackendLock.RUnlock()
+
+	if rb.closed {
+		return 0, errors.New("RPCLedgerBackend is closed; cannot GetLatestLedgerSequence")
+	}
+
+	health, err := rb.client.GetHealth(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return health.LatestLedger, nil
+}
+
+// nextExpectedSequence returns nextLedger (if currently set) or start of
+// prepared range. Otherwise it returns 0.
+func (rb *RPCLedgerBackend) nextExpectedSequence() uint32 {
+	if rb.nextLedger == 0 && rb.prepared != nil {
+		return rb.prepared.from
+	}
+	return rb.nextLedger
+}
+
+// GetLedger returns the LedgerCloseMeta for the specified ledger sequence number.
+// Ledgers must be requested sequentially, starting at the beginning of the
+// prepared range. GetLedger blocks until the ledger is available.
+func (rb *RPCLedgerBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	rb.rpcBackendLock.RLock()
+	defer rb.rpcBackendLock.RUnlock()
+
+	if rb.closed {
+		return xdr.LedgerCloseMeta{}, errors.New("RPCLedgerBackend is closed; cannot GetLedger")
+	}
+
+	if rb.prepared == nil {
+		return xdr.LedgerCloseMeta{}, errors.New("session is not prepared, call PrepareRange first")
+	}
+
+	if sequence < rb.buffer.ledgerRange.from {
+		return xdr.LedgerCloseMeta{}, errors.New("requested sequence preceeds current LedgerRange")
+	}
+
+	if rb.buffer.ledgerRange.bounded {
+		if sequence > rb.buffer.ledgerRange.to {
+			return xdr.LedgerCloseMeta{}, errors.New("requested sequence beyond current LedgerRange")
+		}
+	}
+
+	if rb.lastLedger != 0 && sequence == rb.lastLedger {
+		return rb.lastLCM, nil
+	}
+
+	if sequence < rb.lastLedger {
+		return xdr.LedgerCloseMeta{}, errors.New("requested sequence preceeds the lastLedger")
+	}
+
+	if sequence > rb.nextExpectedSequence() {
+		return xdr.LedgerCloseMeta{}, errors.New("requested sequence is not the lastLedger nor the next available ledger")
+	}
+
+	lcm, err := rb.buffer.getFromLedgerQueue(ctx)
+	if err != nil {
+		return xdr.LedgerCloseMeta{}, errors.Wrap(err, "failed getting next ledger from queue")
+	}
+	if lcm.LedgerSequence() != sequence {
+		return xdr.LedgerCloseMeta{}, errors.Errorf("unexpected ledger %d in queue, expected %d", lcm.LedgerSequence(), sequence)
+	}
+
+	rb.lastLCM = lcm
+	rb.lastLedger = sequence
+	rb.nextLedger = sequence + 1
+
+	return lcm, nil
+}
+
+// PrepareRange starts prefetching the given range from the RPC server.
+func (rb *RPCLedgerBackend) PrepareRange(ctx context.Context, ledgerRange Range) error {
+	rb.rpcBackendLock.Lock()
+	defer rb.rpcBackendLock.Unlock()
+
+	if rb.closed {
+		return errors.New("RPCLedgerBackend is closed; cannot PrepareRange")
+	}
+
+	if rb.isPrepared(ledgerRange) {
+		return nil
+	}
+
+	if rb.buffer != nil {
+		rb.buffer.close()
+	}
+	rb.buffer = rb.newLedgerBuffer(ledgerRange)
+	rb.nextLedger = ledgerRange.from
+	rb.lastLedger = 0
+	rb.lastLCM = xdr.LedgerCloseMeta{}
+	rb.prepared = &ledgerRange
+
+	return nil
+}
+
+// IsPrepared returns true if a given ledgerRange is prepared.
+func (rb *RPCLedgerBackend) IsPrepared(ctx context.Context, ledgerRange Range) (bool, error) {
+	rb.rpcBackendLock.RLock()
+	defer rb.rpcBackendLock.RUnlock()
+
+	if rb.closed {
+		return false, errors.New("RPCLedgerBackend is closed; cannot IsPrepared")
+	}
+
+	return rb.isPrepared(ledgerRange), nil
+}
+
+func (rb *RPCLedgerBackend) isPrepared(ledgerRange Range) bool {
+	if rb.closed || rb.prepared == nil {
+		return false
+	}
+
+	// Ledgers can only be read sequentially, so a range is prepared only if
+	// its start has not been consumed yet.
+	if rb.nextExpectedSequence() > ledgerRange.from && rb.lastLedger != ledgerRange.from {
+		return false
+	}
+
+	if rb.prepared.from > ledgerRange.from {
+		return false
+	}
+
+	if rb.prepared.bounded && !ledgerRange.bounded {
+		return false
+	}
+
+	if !rb.prepared.bounded {
+		return true
+	}
+
+	return rb.prepared.to >= ledgerRange.to
+}
+
+// Close closes existing RPCLedgerBackend processes.
+// Note, once an RPCLedgerBackend instance is closed it can no longer be used and
+// all subsequent calls to PrepareRange(), GetLedger(), etc will fail.
+// Close is thread-safe and can be called from another go routine.
+func (rb *RPCLedgerBackend) Close() error {
+	rb.rpcBackendLock.RLock()
+	defer rb.rpcBackendLock.RUnlock()
+
+	if rb.buffer != nil {
+		rb.buffer.close()
+	}
+
+	rb.closed = true
+
+	return rb.client.Close()
+}
+
+// rpcLedgerBuffer prefetches ledgers from the RPC server in the background
+// and queues them in order.
+type rpcLedgerBuffer struct {
+	backend *RPCLedgerBackend
+	config  RPCLedgerBackendOptions
+
+	// context used to cancel the fetcher within the rpcLedgerBuffer
+	context context.Context
+	cancel  context.CancelCauseFunc
+
+	wg sync.WaitGroup
+
+	ledgerQueue chan xdr.LedgerCloseMeta
+	ledgerRange Range
+}
+
+func (rb *RPCLedgerBackend) newLedgerBuffer(ledgerRange Range) *rpcLedgerBuffer {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	buffer := &rpcLedgerBuffer{
+		backend:     rb,
+		config:      rb.config,
+		context:     ctx,
+		cancel:      cancel,
+		ledgerQueue: make(chan xdr.LedgerCloseMeta, rb.config.BufferSize),
+		ledgerRange: ledgerRange,
+	}
+
+	buffer.wg.Add(1)
+	go buffer.fetcher(ctx)
+
+	return buffer
+}
+
+// sleepWithContext returns true upon sleeping without interruption from the context
+func (lb *rpcLedgerBuffer) sleepWithContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	select {
+	case <-ctx.Done():
+		if !timer.Stop() {
+			<-timer.C
+		}
+		return false
+	case <-timer.C:
+	}
+	return true
+}
+
+func (lb *rpcLedgerBuffer) fetcher(ctx context.Context) {
+	defer lb.wg.Done()
+
+	next := lb.ledgerRange.from
+	for attempt := uint32(0); !lb.ledgerRange.bounded || next <= lb.ledgerRange.to; {
+		limit := lb.config.BufferSize
+		if lb.ledgerRange.bounded && lb.ledgerRange.to-next+1 < limit {
+			limit = lb.ledgerRange.to - next + 1
+		}
+
+		ledgers, err := lb.backend.getLedgers(ctx, next, limit)
+		if err == nil && len(ledgers) == 0 {
+			err = errors.Errorf("no ledgers returned starting at %d", next)
+		}
+		if err != nil {
+			// don't bother retrying if we've received the signal to shut down
+			if ctx.Err() != nil {
+				return
+			}
+
+			// The RPC server rejects ledgers it hasn't ingested yet, so
+			// check whether we are just waiting for the network to catch up.
+			health, healthErr := lb.backend.client.GetHealth(ctx)
+			if healthErr == nil {
+				if next < health.OldestLedger {
+					lb.cancel(errors.Errorf("ledger %d is older than the oldest ledger %d retained by the RPC server", next, health.OldestLedger))
+					return
+				}
+				if next > health.LatestLedger {
+					if !lb.sleepWithContext(ctx, lb.config.RetryWait) {
+						return
+					}
+					continue
+				}
+			}
+
+			if attempt == lb.config.RetryLimit {
+				lb.cancel(errors.Wrapf(err, "maximum retries exceeded for fetching ledger %d", next))
+				return
+			}
+			attempt++
+			if !lb.sleepWithContext(ctx, lb.config.RetryWait) {
+				return
+			}
+			continue
+		}
+		attempt = 0
+
+		for _, lcm := range ledgers {
+			sequence := lcm.LedgerSequence()
+			if sequence < next {
+				continue
+			}
+			if sequence != next {
+				lb.cancel(errors.Errorf("RPC server returned ledger %d, expected %d", sequence, next))
+				return
+			}
+			if lb.ledgerRange.bounded && sequence > lb.ledgerRange.to {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case lb.ledgerQueue <- lcm:
+			}
+			next++
+		}
+	}
+}
+
+func (lb *rpcLedgerBuffer) getFromLedgerQueue(ctx context.Context) (xdr.LedgerCloseMeta, error) {
+	select {
+	case lcm := <-lb.ledgerQueue:
+		return lcm, nil
+	case <-lb.context.Done():
+		// drain ledgers queued before a failure
+		select {
+		case lcm := <-lb.ledgerQueue:
+			return lcm, nil
+		default:
+		}
+		return xdr.LedgerCloseMeta{}, context.Cause(lb.context)
+	case <-ctx.Done():
+		return xdr.LedgerCloseMeta{}, ctx.Err()
+	}
+}
+
+func (lb *rpcLedgerBuffer) close() {
+	lb.cancel(context.Canceled)
+	// wait for the fetcher to finish terminating
+	lb.wg.Wait()
+}
@@ -0,0 +1,245 @@
+package ledgerbackend
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/creachadair/jrpc2"
+	"github.com/creachadair/jrpc2/handler"
+	"github.com/creachadair/jrpc2/jhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	proto "github.com/stellar/go/protocols/rpc"
+	"github.com/stellar/go/xdr"
+)
+
+// stubRPCServer serves canned ledgers through the getLedgers and getHealth
+// methods of the Stellar RPC API.
+type stubRPCServer struct {
+	lock         sync.Mutex
+	oldestLedger uint32
+	latestLedger uint32
+	failures     int // number of getLedgers requests to fail before succeeding
+	requests     int
+}
+
+func (s *stubRPCServer) getHealth(ctx context.Context) (proto.GetHealthResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return proto.GetHealthResponse{
+		Status:       "healthy",
+		OldestLedger: s.oldestLedger,
+		LatestLedger: s.latestLedger,
+	}, nil
+}
+
+func (s *stubRPCServer) getLedgers(ctx context.Context, request proto.GetLedgersRequest) (proto.GetLedgersResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests++
+	if s.failures > 0 {
+		s.failures--
+		return proto.GetLedgersResponse{}, jrpc2.Errorf(jrpc2.InternalError, "transient failure")
+	}
+	if request.StartLedger < s.oldestLedger || request.StartLedger > s.latestLedger {
+		return proto.GetLedgersResponse{}, jrpc2.Errorf(jrpc2.InvalidParams,
+			"start ledger must be between the oldest ledger: %d and the latest ledger: %d for this rpc instance",
+			s.oldestLedger, s.latestLedger)
+	}
+
+	response := proto.GetLedgersResponse{
+		LatestLedger: s.latestLedger,
+		OldestLedger: s.oldestLedger,
+	}
+	end := request.StartLedger + uint32(request.Pagination.Limit) - 1
+	if end > s.latestLedger {
+		end = s.latestLedger
+	}
+	for seq := request.StartLedger; seq <= end; seq++ {
+		lcm := createLedgerCloseMeta(seq)
+		header, err := xdr.MarshalBase64(lcm.V0.LedgerHeader)
+		if err != nil {
+			return proto.GetLedgersResponse{}, err
+		}
+		metadata, err := xdr.MarshalBase64(lcm)
+		if err != nil {
+			return proto.GetLedgersResponse{}, err
+		}
+		response.Ledgers = append(response.Ledgers, proto.LedgerInfo{
+			Sequence:       seq,
+			LedgerHeader:   header,
+			LedgerMetadata: metadata,
+		})
+	}
+	return response, nil
+}
+
+func (s *stubRPCServer) setLatestLedger(latest uint32) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.latestLedger = latest
+}
+
+func newTestRPCLedgerBackend(t *testing.T, stub *stubRPCServer) *RPCLedgerBackend {
+	bridge := jhttp.NewBridge(handler.Map{
+		"getHealth":  handler.New(stub.getHealth),
+		"getLedgers": handler.New(stub.getLedgers),
+	}, nil)
+	server := httptest.NewServer(bridge)
+	t.Cleanup(func() {
+		server.Close()
+		bridge.Close()
+	})
+
+	backend, err := NewRPCLedgerBackend(RPCLedgerBackendOptions{
+		RPCServerURL: server.URL,
+		BufferSize:   4,
+		RetryLimit:   3,
+		RetryWait:    time.Millisecond,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		backend.Close()
+	})
+	return backend
+}
+
+func TestNewRPCLedgerBackend(t *testing.T) {
+	_, err := NewRPCLedgerBackend(RPCLedgerBackendOptions{BufferSize: 1})
+	assert.EqualError(t, err, "RPC server URL must be set")
+
+	_, err = NewRPCLedgerBackend(RPCLedgerBackendOptions{RPCServerURL: "http://localhost:8000"})
+	assert.EqualError(t, err, "buffer size must be > 0")
+}
+
+func TestRPCGetLedgerBoundedRange(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRPCServer{oldestLedger: 2, latestLedger: 30}
+	backend := newTestRPCLedgerBackend(t, stub)
+
+	_, err := backend.GetLedger(ctx, 3)
+	assert.EqualError(t, err, "session is not prepared, call PrepareRange first")
+
+	require.NoError(t, backend.PrepareRange(ctx, BoundedRange(3, 12)))
+	ok, err := backend.IsPrepared(ctx, BoundedRange(3, 12))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = backend.IsPrepared(ctx, UnboundedRange(3))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for seq := uint32(3); seq <= 12; seq++ {
+		lcm, err := backend.GetLedger(ctx, seq)
+		require.NoError(t, err)
+		assert.Equal(t, seq, lcm.LedgerSequence())
+	}
+
+	// the last ledger can be requested again
+	lcm, err := backend.GetLedger(ctx, 12)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(12), lcm.LedgerSequence())
+
+	_, err = backend.GetLedger(ctx, 13)
+	assert.EqualError(t, err, "requested sequence beyond current LedgerRange")
+	_, err = backend.GetLedger(ctx, 5)
+	assert.EqualError(t, err, "requested sequence preceeds the lastLedger")
+
+	latest, err := backend.GetLatestLedgerSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(30), latest)
+}
+
+func TestRPCGetLedgerOutOfOrder(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRPCServer{oldestLedger: 2, latestLedger: 30}
+	backend := newTestRPCLedgerBackend(t, stub)
+
+	require.NoError(t, backend.PrepareRange(ctx, BoundedRange(3, 12)))
+	_, err := backend.GetLedger(ctx, 2)
+	assert.EqualError(t, err, "requested sequence preceeds current LedgerRange")
+	_, err = backend.GetLedger(ctx, 5)
+	assert.EqualError(t, err, "requested sequence is not the lastLedger nor the next available ledger")
+}
+
+func TestRPCGetLedgerUnboundedRange(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRPCServer{oldestLedger: 2, latestLedger: 5}
+	backend := newTestRPCLedgerBackend(t, stub)
+
+	require.NoError(t, backend.PrepareRange(ctx, UnboundedRange(3)))
+	for seq := uint32(3); seq <= 5; seq++ {
+		lcm, err := backend.GetLedger(ctx, seq)
+		require.NoError(t, err)
+		assert.Equal(t, seq, lcm.LedgerSequence())
+	}
+
+	// ledger 6 is not closed yet, GetLedger blocks until it is
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		stub.setLatestLedger(8)
+	}()
+	for seq := uint32(6); seq <= 8; seq++ {
+		lcm, err := backend.GetLedger(ctx, seq)
+		require.NoError(t, err)
+		assert.Equal(t, seq, lcm.LedgerSequence())
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err := backend.GetLedger(timeoutCtx, 9)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRPCGetLedgerRetries(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRPCServer{oldestLedger: 2, latestLedger: 30, failures: 3}
+	backend := newTestRPCLedgerBackend(t, stub)
+
+	require.NoError(t, backend.PrepareRange(ctx, BoundedRange(3, 5)))
+	for seq := uint32(3); seq <= 5; seq++ {
+		lcm, err := backend.GetLedger(ctx, seq)
+		require.NoError(t, err)
+		assert.Equal(t, seq, lcm.LedgerSequence())
+	}
+	assert.Equal(t, 4, stub.requests)
+}
+
+func TestRPCGetLedgerRetryLimitExceeded(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRPCServer{oldestLedger: 2, latestLedger: 30, failures: 4}
+	backend := newTestRPCLedgerBackend(t, stub)
+
+	require.NoError(t, backend.PrepareRange(ctx, BoundedRange(3, 5)))
+	_, err := backend.GetLedger(ctx, 3)
+	assert.ErrorContains(t, err, "maximum retries exceeded for fetching ledger 3")
+}
+
+func TestRPCGetLedgerOutsideRetentionWindow(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRPCServer{oldestLedger: 10, latestLedger: 30}
+	backend := newTestRPCLedgerBackend(t, stub)
+
+	require.NoError(t, backend.PrepareRange(ctx, BoundedRange(3, 5)))
+	_, err := backend.GetLedger(ctx, 3)
+	assert.ErrorContains(t, err, "ledger 3 is older than the oldest ledger 10 retained by the RPC server")
+}
+
+func TestRPCClose(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubRPCServer{oldestLedger: 2, latestLedger: 30}
+	backend := newTestRPCLedgerBackend(t, stub)
+
+	require.NoError(t, backend.PrepareRange(ctx, UnboundedRange(3)))
+	require.NoError(t, backend.Close())
+
+	_, err := backend.GetLedger(ctx, 3)
+	assert.EqualError(t, err, "RPCLedgerBackend is closed; cannot GetLedger")
+	err = backend.PrepareRange(ctx, UnboundedRange(3))
+	assert.EqualError(t, err, "RPCLedgerBackend is closed; cannot PrepareRange")
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stellar/go/ingest"
 	utils "github.com/stellar/go/ingest/processors/processor_utils"
+	"github.com/stellar/go/support/contractspec"
 	"github.com/stellar/go/xdr"
 )
 
@@ -30,6 +31,9 @@ type ContractCodeOutput struct {
 	NImports          uint32 `json:"n_imports"`
 	NExports          uint32 `json:"n_exports"`
 	NDataSegmentBytes uint32 `json:"n_data_segment_bytes"`
+	// ContractFunctions are the signatures of the functions of the contract
+	// spec, empty if the code has no spec
+	ContractFunctions []string `json:"contract_functions"`
 }
 
 // TransformContractCode converts a contract code ledger change entry into a form suitable for BigQuery
@@ -106,6 +110,18 @@ func TransformContractCode(ledgerChange ingest.Change, header xdr.LedgerHeaderHi
 		NImports:           outputNImports,
 		NExports:           outputNExports,
 		NDataSegmentBytes:  outputNDataSegmentBytes,
+		ContractFunctions:  contractFunctions(contractCode.Code),
 	}
 	return transformedCode, nil
 }
+
+// contractFunctions returns the function signatures of the contract spec in
+// the code. Code without a valid spec has no functions rather than failing the
+// transform, the code itself was validated by the network when uploaded.
+func contractFunctions(code []byte) []string {
+	spec, err := contractspec.NewSpecFromWasm(code)
+	if err != nil {
+		return []string{}
+	}
+	return spec.FunctionSignatures()
+}
@@ -2,6 +2,7 @@ package contract
 
 import (
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -18,7 +19,7 @@ func TestTransformContractCode(t *testing.T) {
 		wantErr    error
 	}
 
-	hardCodedInput := makeContractCodeTestInput()
+	hardCodedInput := makeContractCodeTestInput(t)
 	hardCodedOutput := makeContractCodeTestOutput()
 	tests := []transformTest{
 		{
@@ -58,8 +59,12 @@ func TestTransformContractCode(t *testing.T) {
 	}
 }
 
-func makeContractCodeTestInput() []ingest.Change {
+func makeContractCodeTestInput(t *testing.T) []ingest.Change {
 	var hash [32]byte
+	code, err := os.ReadFile("testdata/soroban_add_u64.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	contractCodeLedgerEntry := xdr.LedgerEntry{
 		LastModifiedLedgerSeq: 24229503,
@@ -67,6 +72,7 @@ func makeContractCodeTestInput() []ingest.Change {
 			Type: xdr.LedgerEntryTypeContractCode,
 			ContractCode: &xdr.ContractCodeEntry{
 				Hash: hash,
+				Code: code,
 				Ext: xdr.ContractCodeEntryExt{
 					V: 1,
 					V1: &xdr.ContractCodeEntryV1{
@@ -118,6 +124,7 @@ func makeContractCodeTestOutput() []ContractCodeOutput {
 			NImports:           8,
 			NExports:           9,
 			NDataSegmentBytes:  10,
+			ContractFunctions:  []string{"add(a: u64, b: u64) -> u64"},
 		},
 	}
 }
@@ -2,12 +2,14 @@ package contract
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/stellar/go/ingest"
 	utils "github.com/stellar/go/ingest/processors/processor_utils"
 	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/contractspec"
 	"github.com/stellar/go/toid"
 	"github.com/stellar/go/xdr"
 )
@@ -27,6 +29,8 @@ type ContractEventOutput struct {
 	TopicsDecoded            map[string][]map[string]string `json:"topics_decoded"`
 	Data                     map[string]string              `json:"data"`
 	DataDecoded              map[string]string              `json:"data_decoded"`
+	TopicsJSON               string                         `json:"topics_json"`
+	DataJSON                 string                         `json:"data_json"`
 	ContractEventXDR         string                         `json:"contract_event_xdr"`
 }
 
@@ -73,6 +77,8 @@ func TransformContractEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledger
 		eventData := getEventData(event.Body)
 		outputData, outputDataDecoded := SerializeScVal(eventData)
 
+		outputTopicsJSONString, outputDataJSONString := serializeEventJSON(event)
+
 		// Convert the xdrContactId to string
 		// TODO: https://stellarorg.atlassian.net/browse/HUBBLE-386 this should be a stellar/go/xdr function
 		if event.ContractId != nil {
@@ -103,6 +109,8 @@ func TransformContractEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledger
 			TopicsDecoded:            outputTopicsDecodedJson,
 			Data:                     outputData,
 			DataDecoded:              outputDataDecoded,
+			TopicsJSON:               outputTopicsJSONString,
+			DataJSON:                 outputDataJSONString,
 			ContractEventXDR:         outputContractEventXDR,
 		}
 
@@ -134,6 +142,25 @@ func getEventData(eventBody xdr.ContractEventBody) xdr.ScVal {
 	}
 }
 
+// serializeEventJSON returns the topics and data of an event as JSON, using the
+// representation of contractspec.DecodeEvent. Like SerializeScVal, events which
+// cannot be decoded do not fail the transform and are left empty.
+func serializeEventJSON(event xdr.ContractEvent) (string, string) {
+	decoded, err := contractspec.DecodeEvent(event)
+	if err != nil {
+		return "", ""
+	}
+	topics, err := json.Marshal(decoded.Topics)
+	if err != nil {
+		return "", ""
+	}
+	data, err := json.Marshal(decoded.Data)
+	if err != nil {
+		return "", ""
+	}
+	return string(topics), string(data)
+}
+
 // TODO this should also be used in the operations processor
 func SerializeScVal(scVal xdr.ScVal) (map[string]string, map[string]string) {
 	serializedData := map[string]string{}
@@ -95,6 +95,8 @@ func makeContractEventTestOutput() (output [][]ContractEventOutput, err error) {
 			TopicsDecoded:            topicsDecoded,
 			Data:                     data,
 			DataDecoded:              dataDecoded,
+			TopicsJSON:               "[true]",
+			DataJSON:                 "true",
 			ContractEventXDR:         "AAAAAQAAAAAAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACAAAAAAAAAAEAAAAAAAAAAQAAAAAAAAAB",
 		},
 	}}
@@ -0,0 +1,428 @@
+package parquetwriter
+
+import "time"
+
+// The rows below are the Parquet schemas of the processor outputs. Unsigned
+// integers are widened to int64 because Spark does not read unsigned Parquet
+// types, except the hashes of the normalized offers which need the full uint64
+// range. Nullable values are optional columns.
+
+// ScValRow is an ScVal serialized by contract.SerializeScVal: its type and
+// either its base64 encoded XDR or its decoded representation.
+type ScValRow struct {
+	Type  string `parquet:"type"`
+	Value string `parquet:"value"`
+}
+
+// ClaimantRow is a claimant of a claimable balance, the predicate is JSON
+// encoded as it is a recursive structure.
+type ClaimantRow struct {
+	Destination string `parquet:"destination"`
+	Predicate   string `parquet:"predicate,json"`
+}
+
+// ContractCostParamRow is a cost parameter of the Soroban cost model.
+type ContractCostParamRow struct {
+	ExtV       int32 `parquet:"ext_v"`
+	ConstTerm  int64 `parquet:"const_term"`
+	LinearTerm int64 `parquet:"linear_term"`
+}
+
+type LedgerRow struct {
+	Sequence                   int64     `parquet:"sequence"`
+	LedgerHash                 string    `parquet:"ledger_hash"`
+	PreviousLedgerHash         string    `parquet:"previous_ledger_hash"`
+	LedgerHeader               string    `parquet:"ledger_header"`
+	TransactionCount           int32     `parquet:"transaction_count"`
+	OperationCount             int32     `parquet:"operation_count"`
+	SuccessfulTransactionCount int32     `parquet:"successful_transaction_count"`
+	FailedTransactionCount     int32     `parquet:"failed_transaction_count"`
+	TxSetOperationCount        string    `parquet:"tx_set_operation_count"`
+	ClosedAt                   time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	TotalCoins                 int64     `parquet:"total_coins"`
+	FeePool                    int64     `parquet:"fee_pool"`
+	BaseFee                    int64     `parquet:"base_fee"`
+	BaseReserve                int64     `parquet:"base_reserve"`
+	MaxTxSetSize               int64     `parquet:"max_tx_set_size"`
+	ProtocolVersion            int64     `parquet:"protocol_version"`
+	LedgerID                   int64     `parquet:"id"`
+	SorobanFeeWrite1Kb         int64     `parquet:"soroban_fee_write_1kb"`
+	NodeID                     string    `parquet:"node_id"`
+	Signature                  string    `parquet:"signature"`
+	TotalByteSizeOfBucketList  int64     `parquet:"total_byte_size_of_bucket_list"`
+}
+
+type TransactionRow struct {
+	TransactionHash                      string    `parquet:"transaction_hash"`
+	LedgerSequence                       int64     `parquet:"ledger_sequence"`
+	Account                              string    `parquet:"account"`
+	AccountMuxed                         string    `parquet:"account_muxed"`
+	AccountSequence                      int64     `parquet:"account_sequence"`
+	MaxFee                               int64     `parquet:"max_fee"`
+	FeeCharged                           int64     `parquet:"fee_charged"`
+	OperationCount                       int32     `parquet:"operation_count"`
+	TxEnvelope                           string    `parquet:"tx_envelope"`
+	TxResult                             string    `parquet:"tx_result"`
+	TxMeta                               string    `parquet:"tx_meta"`
+	TxFeeMeta                            string    `parquet:"tx_fee_meta"`
+	CreatedAt                            time.Time `parquet:"created_at,timestamp(microsecond)"`
+	MemoType                             string    `parquet:"memo_type"`
+	Memo                                 string    `parquet:"memo"`
+	TimeBounds                           string    `parquet:"time_bounds"`
+	Successful                           bool      `parquet:"successful"`
+	TransactionID                        int64     `parquet:"id"`
+	FeeAccount                           string    `parquet:"fee_account"`
+	FeeAccountMuxed                      string    `parquet:"fee_account_muxed"`
+	InnerTransactionHash                 string    `parquet:"inner_transaction_hash"`
+	NewMaxFee                            int64     `parquet:"new_max_fee"`
+	LedgerBounds                         string    `parquet:"ledger_bounds"`
+	MinAccountSequence                   *int64    `parquet:"min_account_sequence,optional"`
+	MinAccountSequenceAge                *int64    `parquet:"min_account_sequence_age,optional"`
+	MinAccountSequenceLedgerGap          *int64    `parquet:"min_account_sequence_ledger_gap,optional"`
+	ExtraSigners                         []string  `parquet:"extra_signers,list"`
+	ClosedAt                             time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	ResourceFee                          int64     `parquet:"resource_fee"`
+	SorobanResourcesInstructions         int64     `parquet:"soroban_resources_instructions"`
+	SorobanResourcesReadBytes            int64     `parquet:"soroban_resources_read_bytes"`
+	SorobanResourcesWriteBytes           int64     `parquet:"soroban_resources_write_bytes"`
+	TransactionResultCode                string    `parquet:"transaction_result_code"`
+	InclusionFeeBid                      int64     `parquet:"inclusion_fee_bid"`
+	InclusionFeeCharged                  int64     `parquet:"inclusion_fee_charged"`
+	ResourceFeeRefund                    int64     `parquet:"resource_fee_refund"`
+	TotalNonRefundableResourceFeeCharged int64     `parquet:"non_refundable_resource_fee_charged"`
+	TotalRefundableResourceFeeCharged    int64     `parquet:"refundable_resource_fee_charged"`
+	RentFeeCharged                       int64     `parquet:"rent_fee_charged"`
+	TxSigners                            []string  `parquet:"tx_signers,list"`
+}
+
+type OperationRow struct {
+	SourceAccount        string    `parquet:"source_account"`
+	SourceAccountMuxed   string    `parquet:"source_account_muxed"`
+	Type                 int32     `parquet:"type"`
+	TypeString           string    `parquet:"type_string"`
+	OperationDetails     string    `parquet:"details,json"`
+	TransactionID        int64     `parquet:"transaction_id"`
+	OperationID          int64     `parquet:"id"`
+	ClosedAt             time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	OperationResultCode  string    `parquet:"operation_result_code"`
+	OperationTraceCode   string    `parquet:"operation_trace_code"`
+	LedgerSequence       int64     `parquet:"ledger_sequence"`
+	OperationDetailsJSON string    `parquet:"details_json,json"`
+}
+
+type EffectRow struct {
+	Address        string    `parquet:"address"`
+	AddressMuxed   *string   `parquet:"address_muxed,optional"`
+	OperationID    int64     `parquet:"operation_id"`
+	Details        string    `parquet:"details,json"`
+	Type           int32     `parquet:"type"`
+	TypeString     string    `parquet:"type_string"`
+	LedgerClosed   time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence int64     `parquet:"ledger_sequence"`
+	EffectIndex    int64     `parquet:"index"`
+	EffectId       string    `parquet:"id"`
+}
+
+type TradeRow struct {
+	Order                  int32     `parquet:"order"`
+	LedgerClosedAt         time.Time `parquet:"ledger_closed_at,timestamp(microsecond)"`
+	SellingAccountAddress  string    `parquet:"selling_account_address"`
+	SellingAssetCode       string    `parquet:"selling_asset_code"`
+	SellingAssetIssuer     string    `parquet:"selling_asset_issuer"`
+	SellingAssetType       string    `parquet:"selling_asset_type"`
+	SellingAssetID         int64     `parquet:"selling_asset_id"`
+	SellingAmount          float64   `parquet:"selling_amount"`
+	BuyingAccountAddress   string    `parquet:"buying_account_address"`
+	BuyingAssetCode        string    `parquet:"buying_asset_code"`
+	BuyingAssetIssuer      string    `parquet:"buying_asset_issuer"`
+	BuyingAssetType        string    `parquet:"buying_asset_type"`
+	BuyingAssetID          int64     `parquet:"buying_asset_id"`
+	BuyingAmount           float64   `parquet:"buying_amount"`
+	PriceN                 int64     `parquet:"price_n"`
+	PriceD                 int64     `parquet:"price_d"`
+	SellingOfferID         *int64    `parquet:"selling_offer_id,optional"`
+	BuyingOfferID          *int64    `parquet:"buying_offer_id,optional"`
+	SellingLiquidityPoolID *string   `parquet:"selling_liquidity_pool_id,optional"`
+	LiquidityPoolFee       *int64    `parquet:"liquidity_pool_fee,optional"`
+	HistoryOperationID     int64     `parquet:"history_operation_id"`
+	TradeType              int32     `parquet:"trade_type"`
+	RoundingSlippage       *int64    `parquet:"rounding_slippage,optional"`
+	SellerIsExact          *bool     `parquet:"seller_is_exact,optional"`
+}
+
+type AccountRow struct {
+	AccountID            string    `parquet:"account_id"`
+	Balance              float64   `parquet:"balance"`
+	BuyingLiabilities    float64   `parquet:"buying_liabilities"`
+	SellingLiabilities   float64   `parquet:"selling_liabilities"`
+	SequenceNumber       int64     `parquet:"sequence_number"`
+	SequenceLedger       int64     `parquet:"sequence_ledger"`
+	SequenceTime         int64     `parquet:"sequence_time"`
+	NumSubentries        int64     `parquet:"num_subentries"`
+	InflationDestination string    `parquet:"inflation_destination"`
+	Flags                int64     `parquet:"flags"`
+	HomeDomain           string    `parquet:"home_domain"`
+	MasterWeight         int32     `parquet:"master_weight"`
+	ThresholdLow         int32     `parquet:"threshold_low"`
+	ThresholdMedium      int32     `parquet:"threshold_medium"`
+	ThresholdHigh        int32     `parquet:"threshold_high"`
+	Sponsor              *string   `parquet:"sponsor,optional"`
+	NumSponsored         int64     `parquet:"num_sponsored"`
+	NumSponsoring        int64     `parquet:"num_sponsoring"`
+	LastModifiedLedger   int64     `parquet:"last_modified_ledger"`
+	LedgerEntryChange    int64     `parquet:"ledger_entry_change"`
+	Deleted              bool      `parquet:"deleted"`
+	ClosedAt             time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence       int64     `parquet:"ledger_sequence"`
+}
+
+type AccountSignerRow struct {
+	AccountID          string    `parquet:"account_id"`
+	Signer             string    `parquet:"signer"`
+	Weight             int32     `parquet:"weight"`
+	Sponsor            *string   `parquet:"sponsor,optional"`
+	LastModifiedLedger int64     `parquet:"last_modified_ledger"`
+	LedgerEntryChange  int64     `parquet:"ledger_entry_change"`
+	Deleted            bool      `parquet:"deleted"`
+	ClosedAt           time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence     int64     `parquet:"ledger_sequence"`
+}
+
+type AssetRow struct {
+	AssetCode      string    `parquet:"asset_code"`
+	AssetIssuer    string    `parquet:"asset_issuer"`
+	AssetType      string    `parquet:"asset_type"`
+	AssetID        int64     `parquet:"asset_id"`
+	ClosedAt       time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence int64     `parquet:"ledger_sequence"`
+}
+
+type ClaimableBalanceRow struct {
+	BalanceID          string        `parquet:"balance_id"`
+	Claimants          []ClaimantRow `parquet:"claimants,list"`
+	AssetCode          string        `parquet:"asset_code"`
+	AssetIssuer        string        `parquet:"asset_issuer"`
+	AssetType          string        `parquet:"asset_type"`
+	AssetID            int64         `parquet:"asset_id"`
+	AssetAmount        float64       `parquet:"asset_amount"`
+	Sponsor            *string       `parquet:"sponsor,optional"`
+	Flags              int64         `parquet:"flags"`
+	LastModifiedLedger int64         `parquet:"last_modified_ledger"`
+	LedgerEntryChange  int64         `parquet:"ledger_entry_change"`
+	Deleted            bool          `parquet:"deleted"`
+	ClosedAt           time.Time     `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence     int64         `parquet:"ledger_sequence"`
+}
+
+type ConfigSettingRow struct {
+	ConfigSettingId                 int32                  `parquet:"config_setting_id"`
+	ContractMaxSizeBytes            int64                  `parquet:"contract_max_size_bytes"`
+	LedgerMaxInstructions           int64                  `parquet:"ledger_max_instructions"`
+	TxMaxInstructions               int64                  `parquet:"tx_max_instructions"`
+	FeeRatePerInstructionsIncrement int64                  `parquet:"fee_rate_per_instructions_increment"`
+	TxMemoryLimit                   int64                  `parquet:"tx_memory_limit"`
+	LedgerMaxReadLedgerEntries      int64                  `parquet:"ledger_max_read_ledger_entries"`
+	LedgerMaxReadBytes              int64                  `parquet:"ledger_max_read_bytes"`
+	LedgerMaxWriteLedgerEntries     int64                  `parquet:"ledger_max_write_ledger_entries"`
+	LedgerMaxWriteBytes             int64                  `parquet:"ledger_max_write_bytes"`
+	TxMaxReadLedgerEntries          int64                  `parquet:"tx_max_read_ledger_entries"`
+	TxMaxReadBytes                  int64                  `parquet:"tx_max_read_bytes"`
+	TxMaxWriteLedgerEntries         int64                  `parquet:"tx_max_write_ledger_entries"`
+	TxMaxWriteBytes                 int64                  `parquet:"tx_max_write_bytes"`
+	FeeReadLedgerEntry              int64                  `parquet:"fee_read_ledger_entry"`
+	FeeWriteLedgerEntry             int64                  `parquet:"fee_write_ledger_entry"`
+	FeeRead1Kb                      int64                  `parquet:"fee_read_1kb"`
+	BucketListTargetSizeBytes       int64                  `parquet:"bucket_list_target_size_bytes"`
+	WriteFee1KbBucketListLow        int64                  `parquet:"write_fee_1kb_bucket_list_low"`
+	WriteFee1KbBucketListHigh       int64                  `parquet:"write_fee_1kb_bucket_list_high"`
+	BucketListWriteFeeGrowthFactor  int64                  `parquet:"bucket_list_write_fee_growth_factor"`
+	FeeHistorical1Kb                int64                  `parquet:"fee_historical_1kb"`
+	TxMaxContractEventsSizeBytes    int64                  `parquet:"tx_max_contract_events_size_bytes"`
+	FeeContractEvents1Kb            int64                  `parquet:"fee_contract_events_1kb"`
+	LedgerMaxTxsSizeBytes           int64                  `parquet:"ledger_max_txs_size_bytes"`
+	TxMaxSizeBytes                  int64                  `parquet:"tx_max_size_bytes"`
+	FeeTxSize1Kb                    int64                  `parquet:"fee_tx_size_1kb"`
+	ContractCostParamsCpuInsns      []ContractCostParamRow `parquet:"contract_cost_params_cpu_insns,list"`
+	ContractCostParamsMemBytes      []ContractCostParamRow `parquet:"contract_cost_params_mem_bytes,list"`
+	ContractDataKeySizeBytes        int64                  `parquet:"contract_data_key_size_bytes"`
+	ContractDataEntrySizeBytes      int64                  `parquet:"contract_data_entry_size_bytes"`
+	MaxEntryTtl                     int64                  `parquet:"max_entry_ttl"`
+	MinTemporaryTtl                 int64                  `parquet:"min_temporary_ttl"`
+	MinPersistentTtl                int64                  `parquet:"min_persistent_ttl"`
+	AutoBumpLedgers                 int64                  `parquet:"auto_bump_ledgers"`
+	PersistentRentRateDenominator   int64                  `parquet:"persistent_rent_rate_denominator"`
+	TempRentRateDenominator         int64                  `parquet:"temp_rent_rate_denominator"`
+	MaxEntriesToArchive             int64                  `parquet:"max_entries_to_archive"`
+	BucketListSizeWindowSampleSize  int64                  `parquet:"bucket_list_size_window_sample_size"`
+	EvictionScanSize                int64                  `parquet:"eviction_scan_size"`
+	StartingEvictionScanLevel       int64                  `parquet:"starting_eviction_scan_level"`
+	LedgerMaxTxCount                int64                  `parquet:"ledger_max_tx_count"`
+	BucketListSizeWindow            []int64                `parquet:"bucket_list_size_window,list"`
+	LastModifiedLedger              int64                  `parquet:"last_modified_ledger"`
+	LedgerEntryChange               int64                  `parquet:"ledger_entry_change"`
+	Deleted                         bool                   `parquet:"deleted"`
+	ClosedAt                        time.Time              `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence                  int64                  `parquet:"ledger_sequence"`
+}
+
+type ContractDataRow struct {
+	ContractId                string    `parquet:"contract_id"`
+	ContractKeyType           string    `parquet:"contract_key_type"`
+	ContractDurability        string    `parquet:"contract_durability"`
+	ContractDataAssetCode     string    `parquet:"asset_code"`
+	ContractDataAssetIssuer   string    `parquet:"asset_issuer"`
+	ContractDataAssetType     string    `parquet:"asset_type"`
+	ContractDataBalanceHolder string    `parquet:"balance_holder"`
+	ContractDataBalance       string    `parquet:"balance"`
+	LastModifiedLedger        int64     `parquet:"last_modified_ledger"`
+	LedgerEntryChange         int64     `parquet:"ledger_entry_change"`
+	Deleted                   bool      `parquet:"deleted"`
+	ClosedAt                  time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence            int64     `parquet:"ledger_sequence"`
+	LedgerKeyHash             string    `parquet:"ledger_key_hash"`
+	Key                       ScValRow  `parquet:"key"`
+	KeyDecoded                ScValRow  `parquet:"key_decoded"`
+	Val                       ScValRow  `parquet:"val"`
+	ValDecoded                ScValRow  `parquet:"val_decoded"`
+	ContractDataXDR           string    `parquet:"contract_data_xdr"`
+}
+
+type ContractCodeRow struct {
+	ContractCodeHash   string    `parquet:"contract_code_hash"`
+	ContractCodeExtV   int32     `parquet:"contract_code_ext_v"`
+	LastModifiedLedger int64     `parquet:"last_modified_ledger"`
+	LedgerEntryChange  int64     `parquet:"ledger_entry_change"`
+	Deleted            bool      `parquet:"deleted"`
+	ClosedAt           time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence     int64     `parquet:"ledger_sequence"`
+	LedgerKeyHash      string    `parquet:"ledger_key_hash"`
+	NInstructions      int64     `parquet:"n_instructions"`
+	NFunctions         int64     `parquet:"n_functions"`
+	NGlobals           int64     `parquet:"n_globals"`
+	NTableEntries      int64     `parquet:"n_table_entries"`
+	NTypes             int64     `parquet:"n_types"`
+	NDataSegments      int64     `parquet:"n_data_segments"`
+	NElemSegments      int64     `parquet:"n_elem_segments"`
+	NImports           int64     `parquet:"n_imports"`
+	NExports           int64     `parquet:"n_exports"`
+	NDataSegmentBytes  int64     `parquet:"n_data_segment_bytes"`
+	ContractFunctions  []string  `parquet:"contract_functions,list"`
+}
+
+type ContractEventRow struct {
+	TransactionHash          string     `parquet:"transaction_hash"`
+	TransactionID            int64      `parquet:"transaction_id"`
+	Successful               bool       `parquet:"successful"`
+	LedgerSequence           int64      `parquet:"ledger_sequence"`
+	ClosedAt                 time.Time  `parquet:"closed_at,timestamp(microsecond)"`
+	InSuccessfulContractCall bool       `parquet:"in_successful_contract_call"`
+	ContractId               string     `parquet:"contract_id"`
+	Type                     int32      `parquet:"type"`
+	TypeString               string     `parquet:"type_string"`
+	Topics                   []ScValRow `parquet:"topics,list"`
+	TopicsDecoded            []ScValRow `parquet:"topics_decoded,list"`
+	Data                     ScValRow   `parquet:"data"`
+	DataDecoded              ScValRow   `parquet:"data_decoded"`
+	TopicsJSON               string     `parquet:"topics_json,json"`
+	DataJSON                 string     `parquet:"data_json,json"`
+	ContractEventXDR         string     `parquet:"contract_event_xdr"`
+}
+
+type TtlRow struct {
+	KeyHash            string    `parquet:"key_hash"`
+	LiveUntilLedgerSeq int64     `parquet:"live_until_ledger_seq"`
+	LastModifiedLedger int64     `parquet:"last_modified_ledger"`
+	LedgerEntryChange  int64     `parquet:"ledger_entry_change"`
+	Deleted            bool      `parquet:"deleted"`
+	ClosedAt           time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence     int64     `parquet:"ledger_sequence"`
+}
+
+type PoolRow struct {
+	PoolID             string    `parquet:"liquidity_pool_id"`
+	PoolType           string    `parquet:"type"`
+	PoolFee            int64     `parquet:"fee"`
+	TrustlineCount     int64     `parquet:"trustline_count"`
+	PoolShareCount     float64   `parquet:"pool_share_count"`
+	AssetAType         string    `parquet:"asset_a_type"`
+	AssetACode         string    `parquet:"asset_a_code"`
+	AssetAIssuer       string    `parquet:"asset_a_issuer"`
+	AssetAReserve      float64   `parquet:"asset_a_amount"`
+	AssetAID           int64     `parquet:"asset_a_id"`
+	AssetBType         string    `parquet:"asset_b_type"`
+	AssetBCode         string    `parquet:"asset_b_code"`
+	AssetBIssuer       string    `parquet:"asset_b_issuer"`
+	AssetBReserve      float64   `parquet:"asset_b_amount"`
+	AssetBID           int64     `parquet:"asset_b_id"`
+	LastModifiedLedger int64     `parquet:"last_modified_ledger"`
+	LedgerEntryChange  int64     `parquet:"ledger_entry_change"`
+	Deleted            bool      `parquet:"deleted"`
+	ClosedAt           time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence     int64     `parquet:"ledger_sequence"`
+}
+
+type OfferRow struct {
+	SellerID           string    `parquet:"seller_id"`
+	OfferID            int64     `parquet:"offer_id"`
+	SellingAssetType   string    `parquet:"selling_asset_type"`
+	SellingAssetCode   string    `parquet:"selling_asset_code"`
+	SellingAssetIssuer string    `parquet:"selling_asset_issuer"`
+	SellingAssetID     int64     `parquet:"selling_asset_id"`
+	BuyingAssetType    string    `parquet:"buying_asset_type"`
+	BuyingAssetCode    string    `parquet:"buying_asset_code"`
+	BuyingAssetIssuer  string    `parquet:"buying_asset_issuer"`
+	BuyingAssetID      int64     `parquet:"buying_asset_id"`
+	Amount             float64   `parquet:"amount"`
+	PriceN             int32     `parquet:"pricen"`
+	PriceD             int32     `parquet:"priced"`
+	Price              float64   `parquet:"price"`
+	Flags              int64     `parquet:"flags"`
+	LastModifiedLedger int64     `parquet:"last_modified_ledger"`
+	LedgerEntryChange  int64     `parquet:"ledger_entry_change"`
+	Deleted            bool      `parquet:"deleted"`
+	Sponsor            *string   `parquet:"sponsor,optional"`
+	ClosedAt           time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence     int64     `parquet:"ledger_sequence"`
+}
+
+// NormalizedOfferRow flattens the dimensions and the fact of a normalized
+// offer.
+type NormalizedOfferRow struct {
+	MarketID            uint64  `parquet:"market_id"`
+	MarketBaseCode      string  `parquet:"market_base_code"`
+	MarketBaseIssuer    string  `parquet:"market_base_issuer"`
+	MarketCounterCode   string  `parquet:"market_counter_code"`
+	MarketCounterIssuer string  `parquet:"market_counter_issuer"`
+	HorizonOfferID      int64   `parquet:"horizon_offer_id"`
+	DimOfferID          uint64  `parquet:"dim_offer_id"`
+	MakerID             uint64  `parquet:"maker_id"`
+	Action              string  `parquet:"action"`
+	BaseAmount          float64 `parquet:"base_amount"`
+	CounterAmount       float64 `parquet:"counter_amount"`
+	Price               float64 `parquet:"price"`
+	AccountID           uint64  `parquet:"account_id"`
+	AccountAddress      string  `parquet:"account_address"`
+	LedgerSequence      int64   `parquet:"ledger_id"`
+	OfferInstanceID     uint64  `parquet:"offer_instance_id"`
+}
+
+type TrustlineRow struct {
+	LedgerKey          string    `parquet:"ledger_key"`
+	AccountID          string    `parquet:"account_id"`
+	AssetCode          string    `parquet:"asset_code"`
+	AssetIssuer        string    `parquet:"asset_issuer"`
+	AssetType          string    `parquet:"asset_type"`
+	AssetID            int64     `parquet:"asset_id"`
+	Balance            float64   `parquet:"balance"`
+	TrustlineLimit     int64     `parquet:"trust_line_limit"`
+	LiquidityPoolID    string    `parquet:"liquidity_pool_id"`
+	BuyingLiabilities  float64   `parquet:"buying_liabilities"`
+	SellingLiabilities float64   `parquet:"selling_liabilities"`
+	Flags              int64     `parquet:"flags"`
+	LastModifiedLedger int64     `parquet:"last_modified_ledger"`
+	LedgerEntryChange  int64     `parquet:"ledger_entry_change"`
+	Sponsor            *string   `parquet:"sponsor,optional"`
+	Deleted            bool      `parquet:"deleted"`
+	ClosedAt           time.Time `parquet:"closed_at,timestamp(microsecond)"`
+	LedgerSequence     int64     `parquet:"ledger_sequence"`
+}
@@ -0,0 +1,618 @@
+package parquetwriter
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/guregu/null"
+
+	account "github.com/stellar/go/ingest/processors/account_processor"
+	asset "github.com/stellar/go/ingest/processors/asset_processor"
+	claimablebalance "github.com/stellar/go/ingest/processors/claimable_balance_processor"
+	configsetting "github.com/stellar/go/ingest/processors/config_setting_processor"
+	contract "github.com/stellar/go/ingest/processors/contract_processor"
+	effect "github.com/stellar/go/ingest/processors/effects_processor"
+	ledger "github.com/stellar/go/ingest/processors/ledger_processor"
+	liquiditypool "github.com/stellar/go/ingest/processors/liquidity_pool_processor"
+	offer "github.com/stellar/go/ingest/processors/offer_processor"
+	operation "github.com/stellar/go/ingest/processors/operation_processor"
+	trade "github.com/stellar/go/ingest/processors/trade_processor"
+	transaction "github.com/stellar/go/ingest/processors/transaction_processor"
+	trustline "github.com/stellar/go/ingest/processors/trustline_processor"
+	"github.com/stellar/go/support/errors"
+)
+
+// The schemas of the processor outputs. The version of a schema must be
+// incremented whenever its row changes, TestSchemasAreStable fails until the
+// version is bumped.
+var (
+	Ledgers = Schema[ledger.LedgerOutput, LedgerRow]{
+		Name: "ledgers", Version: 1, convert: ledgerRow,
+	}
+	Transactions = Schema[transaction.TransactionOutput, TransactionRow]{
+		Name: "transactions", Version: 1, convert: transactionRow,
+	}
+	Operations = Schema[operation.OperationOutput, OperationRow]{
+		Name: "operations", Version: 1, convert: operationRow,
+	}
+	Effects = Schema[effect.EffectOutput, EffectRow]{
+		Name: "effects", Version: 1, convert: effectRow,
+	}
+	Trades = Schema[trade.TradeOutput, TradeRow]{
+		Name: "trades", Version: 1, convert: tradeRow,
+	}
+	Accounts = Schema[account.AccountOutput, AccountRow]{
+		Name: "accounts", Version: 1, convert: accountRow,
+	}
+	AccountSigners = Schema[account.AccountSignerOutput, AccountSignerRow]{
+		Name: "account_signers", Version: 1, convert: accountSignerRow,
+	}
+	Assets = Schema[asset.AssetOutput, AssetRow]{
+		Name: "assets", Version: 1, convert: assetRow,
+	}
+	ClaimableBalances = Schema[claimablebalance.ClaimableBalanceOutput, ClaimableBalanceRow]{
+		Name: "claimable_balances", Version: 1, convert: claimableBalanceRow,
+	}
+	ConfigSettings = Schema[configsetting.ConfigSettingOutput, ConfigSettingRow]{
+		Name: "config_settings", Version: 1, convert: configSettingRow,
+	}
+	ContractData = Schema[contract.ContractDataOutput, ContractDataRow]{
+		Name: "contract_data", Version: 1, convert: contractDataRow,
+	}
+	ContractCode = Schema[contract.ContractCodeOutput, ContractCodeRow]{
+		Name: "contract_code", Version: 2, convert: contractCodeRow,
+	}
+	ContractEvents = Schema[contract.ContractEventOutput, ContractEventRow]{
+		Name: "contract_events", Version: 2, convert: contractEventRow,
+	}
+	Ttls = Schema[contract.TtlOutput, TtlRow]{
+		Name: "ttls", Version: 1, convert: ttlRow,
+	}
+	LiquidityPools = Schema[liquiditypool.PoolOutput, PoolRow]{
+		Name: "liquidity_pools", Version: 1, convert: poolRow,
+	}
+	Offers = Schema[offer.OfferOutput, OfferRow]{
+		Name: "offers", Version: 1, convert: offerRow,
+	}
+	NormalizedOffers = Schema[offer.NormalizedOfferOutput, NormalizedOfferRow]{
+		Name: "normalized_offers", Version: 1, convert: normalizedOfferRow,
+	}
+	Trustlines = Schema[trustline.TrustlineOutput, TrustlineRow]{
+		Name: "trustlines", Version: 1, convert: trustlineRow,
+	}
+)
+
+func ledgerRow(o ledger.LedgerOutput) (LedgerRow, error) {
+	return LedgerRow{
+		Sequence:                   int64(o.Sequence),
+		LedgerHash:                 o.LedgerHash,
+		PreviousLedgerHash:         o.PreviousLedgerHash,
+		LedgerHeader:               o.LedgerHeader,
+		TransactionCount:           o.TransactionCount,
+		OperationCount:             o.OperationCount,
+		SuccessfulTransactionCount: o.SuccessfulTransactionCount,
+		FailedTransactionCount:     o.FailedTransactionCount,
+		TxSetOperationCount:        o.TxSetOperationCount,
+		ClosedAt:                   o.ClosedAt,
+		TotalCoins:                 o.TotalCoins,
+		FeePool:                    o.FeePool,
+		BaseFee:                    int64(o.BaseFee),
+		BaseReserve:                int64(o.BaseReserve),
+		MaxTxSetSize:               int64(o.MaxTxSetSize),
+		ProtocolVersion:            int64(o.ProtocolVersion),
+		LedgerID:                   o.LedgerID,
+		SorobanFeeWrite1Kb:         o.SorobanFeeWrite1Kb,
+		NodeID:                     o.NodeID,
+		Signature:                  o.Signature,
+		TotalByteSizeOfBucketList:  int64(o.TotalByteSizeOfBucketList),
+	}, nil
+}
+
+func transactionRow(o transaction.TransactionOutput) (TransactionRow, error) {
+	return TransactionRow{
+		TransactionHash:                      o.TransactionHash,
+		LedgerSequence:                       int64(o.LedgerSequence),
+		Account:                              o.Account,
+		AccountMuxed:                         o.AccountMuxed,
+		AccountSequence:                      o.AccountSequence,
+		MaxFee:                               int64(o.MaxFee),
+		FeeCharged:                           o.FeeCharged,
+		OperationCount:                       o.OperationCount,
+		TxEnvelope:                           o.TxEnvelope,
+		TxResult:                             o.TxResult,
+		TxMeta:                               o.TxMeta,
+		TxFeeMeta:                            o.TxFeeMeta,
+		CreatedAt:                            o.CreatedAt,
+		MemoType:                             o.MemoType,
+		Memo:                                 o.Memo,
+		TimeBounds:                           o.TimeBounds,
+		Successful:                           o.Successful,
+		TransactionID:                        o.TransactionID,
+		FeeAccount:                           o.FeeAccount,
+		FeeAccountMuxed:                      o.FeeAccountMuxed,
+		InnerTransactionHash:                 o.InnerTransactionHash,
+		NewMaxFee:                            int64(o.NewMaxFee),
+		LedgerBounds:                         o.LedgerBounds,
+		MinAccountSequence:                   nullInt(o.MinAccountSequence),
+		MinAccountSequenceAge:                nullInt(o.MinAccountSequenceAge),
+		MinAccountSequenceLedgerGap:          nullInt(o.MinAccountSequenceLedgerGap),
+		ExtraSigners:                         o.ExtraSigners,
+		ClosedAt:                             o.ClosedAt,
+		ResourceFee:                          o.ResourceFee,
+		SorobanResourcesInstructions:         int64(o.SorobanResourcesInstructions),
+		SorobanResourcesReadBytes:            int64(o.SorobanResourcesReadBytes),
+		SorobanResourcesWriteBytes:           int64(o.SorobanResourcesWriteBytes),
+		TransactionResultCode:                o.TransactionResultCode,
+		InclusionFeeBid:                      o.InclusionFeeBid,
+		InclusionFeeCharged:                  o.InclusionFeeCharged,
+		ResourceFeeRefund:                    o.ResourceFeeRefund,
+		TotalNonRefundableResourceFeeCharged: o.TotalNonRefundableResourceFeeCharged,
+		TotalRefundableResourceFeeCharged:    o.TotalRefundableResourceFeeCharged,
+		RentFeeCharged:                       o.RentFeeCharged,
+		TxSigners:                            o.TxSigners,
+	}, nil
+}
+
+func operationRow(o operation.OperationOutput) (OperationRow, error) {
+	details, err := jsonColumn(o.OperationDetails)
+	if err != nil {
+		return OperationRow{}, errors.Wrapf(err, "could not encode details of operation %d", o.OperationID)
+	}
+	detailsJSON, err := jsonColumn(o.OperationDetailsJSON)
+	if err != nil {
+		return OperationRow{}, errors.Wrapf(err, "could not encode details_json of operation %d", o.OperationID)
+	}
+	return OperationRow{
+		SourceAccount:        o.SourceAccount,
+		SourceAccountMuxed:   o.SourceAccountMuxed,
+		Type:                 o.Type,
+		TypeString:           o.TypeString,
+		OperationDetails:     details,
+		TransactionID:        o.TransactionID,
+		OperationID:          o.OperationID,
+		ClosedAt:             o.ClosedAt,
+		OperationResultCode:  o.OperationResultCode,
+		OperationTraceCode:   o.OperationTraceCode,
+		LedgerSequence:       int64(o.LedgerSequence),
+		OperationDetailsJSON: detailsJSON,
+	}, nil
+}
+
+func effectRow(o effect.EffectOutput) (EffectRow, error) {
+	details, err := jsonColumn(o.Details)
+	if err != nil {
+		return EffectRow{}, errors.Wrapf(err, "could not encode details of effect %s", o.EffectId)
+	}
+	return EffectRow{
+		Address:        o.Address,
+		AddressMuxed:   nullString(o.AddressMuxed),
+		OperationID:    o.OperationID,
+		Details:        details,
+		Type:           o.Type,
+		TypeString:     o.TypeString,
+		LedgerClosed:   o.LedgerClosed,
+		LedgerSequence: int64(o.LedgerSequence),
+		EffectIndex:    int64(o.EffectIndex),
+		EffectId:       o.EffectId,
+	}, nil
+}
+
+func tradeRow(o trade.TradeOutput) (TradeRow, error) {
+	return TradeRow{
+		Order:                  o.Order,
+		LedgerClosedAt:         o.LedgerClosedAt,
+		SellingAccountAddress:  o.SellingAccountAddress,
+		SellingAssetCode:       o.SellingAssetCode,
+		SellingAssetIssuer:     o.SellingAssetIssuer,
+		SellingAssetType:       o.SellingAssetType,
+		SellingAssetID:         o.SellingAssetID,
+		SellingAmount:          o.SellingAmount,
+		BuyingAccountAddress:   o.BuyingAccountAddress,
+		BuyingAssetCode:        o.BuyingAssetCode,
+		BuyingAssetIssuer:      o.BuyingAssetIssuer,
+		BuyingAssetType:        o.BuyingAssetType,
+		BuyingAssetID:          o.BuyingAssetID,
+		BuyingAmount:           o.BuyingAmount,
+		PriceN:                 o.PriceN,
+		PriceD:                 o.PriceD,
+		SellingOfferID:         nullInt(o.SellingOfferID),
+		BuyingOfferID:          nullInt(o.BuyingOfferID),
+		SellingLiquidityPoolID: nullString(o.SellingLiquidityPoolID),
+		LiquidityPoolFee:       nullInt(o.LiquidityPoolFee),
+		HistoryOperationID:     o.HistoryOperationID,
+		TradeType:              o.TradeType,
+		RoundingSlippage:       nullInt(o.RoundingSlippage),
+		SellerIsExact:          nullBool(o.SellerIsExact),
+	}, nil
+}
+
+func accountRow(o account.AccountOutput) (AccountRow, error) {
+	return AccountRow{
+		AccountID:            o.AccountID,
+		Balance:              o.Balance,
+		BuyingLiabilities:    o.BuyingLiabilities,
+		SellingLiabilities:   o.SellingLiabilities,
+		SequenceNumber:       o.SequenceNumber,
+		SequenceLedger:       o.SequenceLedger.Int64,
+		SequenceTime:         o.SequenceTime.Int64,
+		NumSubentries:        int64(o.NumSubentries),
+		InflationDestination: o.InflationDestination,
+		Flags:                int64(o.Flags),
+		HomeDomain:           o.HomeDomain,
+		MasterWeight:         o.MasterWeight,
+		ThresholdLow:         o.ThresholdLow,
+		ThresholdMedium:      o.ThresholdMedium,
+		ThresholdHigh:        o.ThresholdHigh,
+		Sponsor:              nullString(o.Sponsor),
+		NumSponsored:         int64(o.NumSponsored),
+		NumSponsoring:        int64(o.NumSponsoring),
+		LastModifiedLedger:   int64(o.LastModifiedLedger),
+		LedgerEntryChange:    int64(o.LedgerEntryChange),
+		Deleted:              o.Deleted,
+		ClosedAt:             o.ClosedAt,
+		LedgerSequence:       int64(o.LedgerSequence),
+	}, nil
+}
+
+func accountSignerRow(o account.AccountSignerOutput) (AccountSignerRow, error) {
+	return AccountSignerRow{
+		AccountID:          o.AccountID,
+		Signer:             o.Signer,
+		Weight:             o.Weight,
+		Sponsor:            nullString(o.Sponsor),
+		LastModifiedLedger: int64(o.LastModifiedLedger),
+		LedgerEntryChange:  int64(o.LedgerEntryChange),
+		Deleted:            o.Deleted,
+		ClosedAt:           o.ClosedAt,
+		LedgerSequence:     int64(o.LedgerSequence),
+	}, nil
+}
+
+func assetRow(o asset.AssetOutput) (AssetRow, error) {
+	return AssetRow{
+		AssetCode:      o.AssetCode,
+		AssetIssuer:    o.AssetIssuer,
+		AssetType:      o.AssetType,
+		AssetID:        o.AssetID,
+		ClosedAt:       o.ClosedAt,
+		LedgerSequence: int64(o.LedgerSequence),
+	}, nil
+}
+
+func claimableBalanceRow(o claimablebalance.ClaimableBalanceOutput) (ClaimableBalanceRow, error) {
+	claimants := make([]ClaimantRow, 0, len(o.Claimants))
+	for _, claimant := range o.Claimants {
+		predicate, err := jsonColumn(claimant.Predicate)
+		if err != nil {
+			return ClaimableBalanceRow{}, errors.Wrapf(err, "could not encode predicate of claimable balance %s", o.BalanceID)
+		}
+		claimants = append(claimants, ClaimantRow{
+			Destination: claimant.Destination,
+			Predicate:   predicate,
+		})
+	}
+	return ClaimableBalanceRow{
+		BalanceID:          o.BalanceID,
+		Claimants:          claimants,
+		AssetCode:          o.AssetCode,
+		AssetIssuer:        o.AssetIssuer,
+		AssetType:          o.AssetType,
+		AssetID:            o.AssetID,
+		AssetAmount:        o.AssetAmount,
+		Sponsor:            nullString(o.Sponsor),
+		Flags:              int64(o.Flags),
+		LastModifiedLedger: int64(o.LastModifiedLedger),
+		LedgerEntryChange:  int64(o.LedgerEntryChange),
+		Deleted:            o.Deleted,
+		ClosedAt:           o.ClosedAt,
+		LedgerSequence:     int64(o.LedgerSequence),
+	}, nil
+}
+
+func configSettingRow(o configsetting.ConfigSettingOutput) (ConfigSettingRow, error) {
+	cpuInsns, err := contractCostParamRows(o.ContractCostParamsCpuInsns)
+	if err != nil {
+		return ConfigSettingRow{}, errors.Wrapf(err, "invalid cpu cost params of config setting %d", o.ConfigSettingId)
+	}
+	memBytes, err := contractCostParamRows(o.ContractCostParamsMemBytes)
+	if err != nil {
+		return ConfigSettingRow{}, errors.Wrapf(err, "invalid memory cost params of config setting %d", o.ConfigSettingId)
+	}
+	bucketListSizeWindow := make([]int64, 0, len(o.BucketListSizeWindow))
+	for _, size := range o.BucketListSizeWindow {
+		bucketListSizeWindow = append(bucketListSizeWindow, int64(size))
+	}
+	return ConfigSettingRow{
+		ConfigSettingId:                 o.ConfigSettingId,
+		ContractMaxSizeBytes:            int64(o.ContractMaxSizeBytes),
+		LedgerMaxInstructions:           o.LedgerMaxInstructions,
+		TxMaxInstructions:               o.TxMaxInstructions,
+		FeeRatePerInstructionsIncrement: o.FeeRatePerInstructionsIncrement,
+		TxMemoryLimit:                   int64(o.TxMemoryLimit),
+		LedgerMaxReadLedgerEntries:      int64(o.LedgerMaxReadLedgerEntries),
+		LedgerMaxReadBytes:              int64(o.LedgerMaxReadBytes),
+		LedgerMaxWriteLedgerEntries:     int64(o.LedgerMaxWriteLedgerEntries),
+		LedgerMaxWriteBytes:             int64(o.LedgerMaxWriteBytes),
+		TxMaxReadLedgerEntries:          int64(o.TxMaxReadLedgerEntries),
+		TxMaxReadBytes:                  int64(o.TxMaxReadBytes),
+		TxMaxWriteLedgerEntries:         int64(o.TxMaxWriteLedgerEntries),
+		TxMaxWriteBytes:                 int64(o.TxMaxWriteBytes),
+		FeeReadLedgerEntry:              o.FeeReadLedgerEntry,
+		FeeWriteLedgerEntry:             o.FeeWriteLedgerEntry,
+		FeeRead1Kb:                      o.FeeRead1Kb,
+		BucketListTargetSizeBytes:       o.BucketListTargetSizeBytes,
+		WriteFee1KbBucketListLow:        o.WriteFee1KbBucketListLow,
+		WriteFee1KbBucketListHigh:       o.WriteFee1KbBucketListHigh,
+		BucketListWriteFeeGrowthFactor:  int64(o.BucketListWriteFeeGrowthFactor),
+		FeeHistorical1Kb:                o.FeeHistorical1Kb,
+		TxMaxContractEventsSizeBytes:    int64(o.TxMaxContractEventsSizeBytes),
+		FeeContractEvents1Kb:            o.FeeContractEvents1Kb,
+		LedgerMaxTxsSizeBytes:           int64(o.LedgerMaxTxsSizeBytes),
+		TxMaxSizeBytes:                  int64(o.TxMaxSizeBytes),
+		FeeTxSize1Kb:                    o.FeeTxSize1Kb,
+		ContractCostParamsCpuInsns:      cpuInsns,
+		ContractCostParamsMemBytes:      memBytes,
+		ContractDataKeySizeBytes:        int64(o.ContractDataKeySizeBytes),
+		ContractDataEntrySizeBytes:      int64(o.ContractDataEntrySizeBytes),
+		MaxEntryTtl:                     int64(o.MaxEntryTtl),
+		MinTemporaryTtl:                 int64(o.MinTemporaryTtl),
+		MinPersistentTtl:                int64(o.MinPersistentTtl),
+		AutoBumpLedgers:                 int64(o.AutoBumpLedgers),
+		PersistentRentRateDenominator:   o.PersistentRentRateDenominator,
+		TempRentRateDenominator:         o.TempRentRateDenominator,
+		MaxEntriesToArchive:             int64(o.MaxEntriesToArchive),
+		BucketListSizeWindowSampleSize:  int64(o.BucketListSizeWindowSampleSize),
+		EvictionScanSize:                int64(o.EvictionScanSize),
+		StartingEvictionScanLevel:       int64(o.StartingEvictionScanLevel),
+		LedgerMaxTxCount:                int64(o.LedgerMaxTxCount),
+		BucketListSizeWindow:            bucketListSizeWindow,
+		LastModifiedLedger:              int64(o.LastModifiedLedger),
+		LedgerEntryChange:               int64(o.LedgerEntryChange),
+		Deleted:                         o.Deleted,
+		ClosedAt:                        o.ClosedAt,
+		LedgerSequence:                  int64(o.LedgerSequence),
+	}, nil
+}
+
+func contractDataRow(o contract.ContractDataOutput) (ContractDataRow, error) {
+	return ContractDataRow{
+		ContractId:                o.ContractId,
+		ContractKeyType:           o.ContractKeyType,
+		ContractDurability:        o.ContractDurability,
+		ContractDataAssetCode:     o.ContractDataAssetCode,
+		ContractDataAssetIssuer:   o.ContractDataAssetIssuer,
+		ContractDataAssetType:     o.ContractDataAssetType,
+		ContractDataBalanceHolder: o.ContractDataBalanceHolder,
+		ContractDataBalance:       o.ContractDataBalance,
+		LastModifiedLedger:        int64(o.LastModifiedLedger),
+		LedgerEntryChange:         int64(o.LedgerEntryChange),
+		Deleted:                   o.Deleted,
+		ClosedAt:                  o.ClosedAt,
+		LedgerSequence:            int64(o.LedgerSequence),
+		LedgerKeyHash:             o.LedgerKeyHash,
+		Key:                       scValRow(o.Key),
+		KeyDecoded:                scValRow(o.KeyDecoded),
+		Val:                       scValRow(o.Val),
+		ValDecoded:                scValRow(o.ValDecoded),
+		ContractDataXDR:           o.ContractDataXDR,
+	}, nil
+}
+
+func contractCodeRow(o contract.ContractCodeOutput) (ContractCodeRow, error) {
+	return ContractCodeRow{
+		ContractCodeHash:   o.ContractCodeHash,
+		ContractCodeExtV:   o.ContractCodeExtV,
+		LastModifiedLedger: int64(o.LastModifiedLedger),
+		LedgerEntryChange:  int64(o.LedgerEntryChange),
+		Deleted:            o.Deleted,
+		ClosedAt:           o.ClosedAt,
+		LedgerSequence:     int64(o.LedgerSequence),
+		LedgerKeyHash:      o.LedgerKeyHash,
+		NInstructions:      int64(o.NInstructions),
+		NFunctions:         int64(o.NFunctions),
+		NGlobals:           int64(o.NGlobals),
+		NTableEntries:      int64(o.NTableEntries),
+		NTypes:             int64(o.NTypes),
+		NDataSegments:      int64(o.NDataSegments),
+		NElemSegments:      int64(o.NElemSegments),
+		NImports:           int64(o.NImports),
+		NExports:           int64(o.NExports),
+		NDataSegmentBytes:  int64(o.NDataSegmentBytes),
+		ContractFunctions:  o.ContractFunctions,
+	}, nil
+}
+
+func contractEventRow(o contract.ContractEventOutput) (ContractEventRow, error) {
+	return ContractEventRow{
+		TransactionHash:          o.TransactionHash,
+		TransactionID:            o.TransactionID,
+		Successful:               o.Successful,
+		LedgerSequence:           int64(o.LedgerSequence),
+		ClosedAt:                 o.ClosedAt,
+		InSuccessfulContractCall: o.InSuccessfulContractCall,
+		ContractId:               o.ContractId,
+		Type:                     o.Type,
+		TypeString:               o.TypeString,
+		Topics:                   scValRows(o.Topics["topics"]),
+		TopicsDecoded:            scValRows(o.TopicsDecoded["topics_decoded"]),
+		Data:                     scValRow(o.Data),
+		DataDecoded:              scValRow(o.DataDecoded),
+		TopicsJSON:               o.TopicsJSON,
+		DataJSON:                 o.DataJSON,
+		ContractEventXDR:         o.ContractEventXDR,
+	}, nil
+}
+
+func ttlRow(o contract.TtlOutput) (TtlRow, error) {
+	return TtlRow{
+		KeyHash:            o.KeyHash,
+		LiveUntilLedgerSeq: int64(o.LiveUntilLedgerSeq),
+		LastModifiedLedger: int64(o.LastModifiedLedger),
+		LedgerEntryChange:  int64(o.LedgerEntryChange),
+		Deleted:            o.Deleted,
+		ClosedAt:           o.ClosedAt,
+		LedgerSequence:     int64(o.LedgerSequence),
+	}, nil
+}
+
+func poolRow(o liquiditypool.PoolOutput) (PoolRow, error) {
+	return PoolRow{
+		PoolID:             o.PoolID,
+		PoolType:           o.PoolType,
+		PoolFee:            int64(o.PoolFee),
+		TrustlineCount:     int64(o.TrustlineCount),
+		PoolShareCount:     o.PoolShareCount,
+		AssetAType:         o.AssetAType,
+		AssetACode:         o.AssetACode,
+		AssetAIssuer:       o.AssetAIssuer,
+		AssetAReserve:      o.AssetAReserve,
+		AssetAID:           o.AssetAID,
+		AssetBType:         o.AssetBType,
+		AssetBCode:         o.AssetBCode,
+		AssetBIssuer:       o.AssetBIssuer,
+		AssetBReserve:      o.AssetBReserve,
+		AssetBID:           o.AssetBID,
+		LastModifiedLedger: int64(o.LastModifiedLedger),
+		LedgerEntryChange:  int64(o.LedgerEntryChange),
+		Deleted:            o.Deleted,
+		ClosedAt:           o.ClosedAt,
+		LedgerSequence:     int64(o.LedgerSequence),
+	}, nil
+}
+
+func offerRow(o offer.OfferOutput) (OfferRow, error) {
+	return OfferRow{
+		SellerID:           o.SellerID,
+		OfferID:            o.OfferID,
+		SellingAssetType:   o.SellingAssetType,
+		SellingAssetCode:   o.SellingAssetCode,
+		SellingAssetIssuer: o.SellingAssetIssuer,
+		SellingAssetID:     o.SellingAssetID,
+		BuyingAssetType:    o.BuyingAssetType,
+		BuyingAssetCode:    o.BuyingAssetCode,
+		BuyingAssetIssuer:  o.BuyingAssetIssuer,
+		BuyingAssetID:      o.BuyingAssetID,
+		Amount:             o.Amount,
+		PriceN:             o.PriceN,
+		PriceD:             o.PriceD,
+		Price:              o.Price,
+		Flags:              int64(o.Flags),
+		LastModifiedLedger: int64(o.LastModifiedLedger),
+		LedgerEntryChange:  int64(o.LedgerEntryChange),
+		Deleted:            o.Deleted,
+		Sponsor:            nullString(o.Sponsor),
+		ClosedAt:           o.ClosedAt,
+		LedgerSequence:     int64(o.LedgerSequence),
+	}, nil
+}
+
+func normalizedOfferRow(o offer.NormalizedOfferOutput) (NormalizedOfferRow, error) {
+	return NormalizedOfferRow{
+		MarketID:            o.Market.ID,
+		MarketBaseCode:      o.Market.BaseCode,
+		MarketBaseIssuer:    o.Market.BaseIssuer,
+		MarketCounterCode:   o.Market.CounterCode,
+		MarketCounterIssuer: o.Market.CounterIssuer,
+		HorizonOfferID:      o.Offer.HorizonID,
+		DimOfferID:          o.Offer.DimOfferID,
+		MakerID:             o.Offer.MakerID,
+		Action:              o.Offer.Action,
+		BaseAmount:          o.Offer.BaseAmount,
+		CounterAmount:       o.Offer.CounterAmount,
+		Price:               o.Offer.Price,
+		AccountID:           o.Account.ID,
+		AccountAddress:      o.Account.Address,
+		LedgerSequence:      int64(o.Event.LedgerSeq),
+		OfferInstanceID:     o.Event.OfferInstanceID,
+	}, nil
+}
+
+func trustlineRow(o trustline.TrustlineOutput) (TrustlineRow, error) {
+	return TrustlineRow{
+		LedgerKey:          o.LedgerKey,
+		AccountID:          o.AccountID,
+		AssetCode:          o.AssetCode,
+		AssetIssuer:        o.AssetIssuer,
+		AssetType:          o.AssetType,
+		AssetID:            o.AssetID,
+		Balance:            o.Balance,
+		TrustlineLimit:     o.TrustlineLimit,
+		LiquidityPoolID:    o.LiquidityPoolID,
+		BuyingLiabilities:  o.BuyingLiabilities,
+		SellingLiabilities: o.SellingLiabilities,
+		Flags:              int64(o.Flags),
+		LastModifiedLedger: int64(o.LastModifiedLedger),
+		LedgerEntryChange:  int64(o.LedgerEntryChange),
+		Sponsor:            nullString(o.Sponsor),
+		Deleted:            o.Deleted,
+		ClosedAt:           o.ClosedAt,
+		LedgerSequence:     int64(o.LedgerSequence),
+	}, nil
+}
+
+func nullInt(value null.Int) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
+func nullString(value null.String) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+func nullBool(value null.Bool) *bool {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Bool
+}
+
+func jsonColumn(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func scValRow(serialized map[string]string) ScValRow {
+	return ScValRow{Type: serialized["type"], Value: serialized["value"]}
+}
+
+func scValRows(serialized []map[string]string) []ScValRow {
+	rows := make([]ScValRow, 0, len(serialized))
+	for _, scVal := range serialized {
+		rows = append(rows, scValRow(scVal))
+	}
+	return rows
+}
+
+// contractCostParamRows parses the cost parameters serialized by the config
+// setting processor.
+func contractCostParamRows(serialized []map[string]string) ([]ContractCostParamRow, error) {
+	rows := make([]ContractCostParamRow, 0, len(serialized))
+	for _, param := range serialized {
+		extV, err := strconv.ParseInt(param["ExtV"], 10, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ExtV")
+		}
+		constTerm, err := strconv.ParseInt(param["ConstTerm"], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ConstTerm")
+		}
+		linearTerm, err := strconv.ParseInt(param["LinearTerm"], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid LinearTerm")
+		}
+		rows = append(rows, ContractCostParamRow{
+			ExtV:       int32(extV),
+			ConstTerm:  constTerm,
+			LinearTerm: linearTerm,
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,148 @@
+// Package parquetwriter writes the outputs of the ingest/processors packages to
+// Parquet files, to be loaded into analytics engines like DuckDB or Spark.
+//
+// Every processor output has a Schema which converts it to a row of a
+// versioned Parquet schema. Columns are named after the JSON fields of the
+// outputs, nested values (claimants, contract cost parameters, serialized
+// ScVals) are written as Parquet lists and groups, and free-form details are
+// written as JSON columns.
+package parquetwriter
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	"github.com/stellar/go/support/errors"
+)
+
+const (
+	// SchemaNameKey is the key of the Parquet file metadata holding the name of
+	// the schema of the file.
+	SchemaNameKey = "stellar.schema.name"
+	// SchemaVersionKey is the key of the Parquet file metadata holding the
+	// version of the schema of the file.
+	SchemaVersionKey = "stellar.schema.version"
+
+	// DefaultRowGroupSize is the default maximum number of rows of a row group.
+	DefaultRowGroupSize = 128 * 1024
+)
+
+// Compression is the compression codec of the columns of a Parquet file.
+type Compression string
+
+const (
+	Uncompressed Compression = "none"
+	Snappy       Compression = "snappy"
+	Gzip         Compression = "gzip"
+	Zstd         Compression = "zstd"
+	LZ4          Compression = "lz4"
+	Brotli       Compression = "brotli"
+)
+
+func (c Compression) codec() (compress.Codec, error) {
+	switch c {
+	case Uncompressed:
+		return &parquet.Uncompressed, nil
+	case Snappy, "":
+		return &parquet.Snappy, nil
+	case Gzip:
+		return &parquet.Gzip, nil
+	case Zstd:
+		return &parquet.Zstd, nil
+	case LZ4:
+		return &parquet.Lz4Raw, nil
+	case Brotli:
+		return &parquet.Brotli, nil
+	default:
+		return nil, errors.Errorf("unknown compression %s", string(c))
+	}
+}
+
+// Config configures a Writer, zero values are replaced by defaults.
+type Config struct {
+	// RowGroupSize is the maximum number of rows of a row group, it defaults
+	// to DefaultRowGroupSize. Rows are buffered in memory until their row group
+	// is complete.
+	RowGroupSize int64
+	// Compression is the compression codec of the columns, it defaults to
+	// Snappy.
+	Compression Compression
+}
+
+// Schema converts the processor output O to rows of type R, it is identified by
+// its Name and Version which are stored in the metadata of the files.
+type Schema[O, R any] struct {
+	// Name is the name of the schema, e.g. "transactions".
+	Name string
+	// Version is incremented whenever the columns of R change, so consumers
+	// can tell apart files written with different schemas.
+	Version int
+	convert func(O) (R, error)
+}
+
+// Convert converts a processor output to a row.
+func (s Schema[O, R]) Convert(output O) (R, error) {
+	return s.convert(output)
+}
+
+// Writer writes processor outputs to a Parquet file.
+type Writer[O, R any] struct {
+	schema Schema[O, R]
+	writer *parquet.GenericWriter[R]
+	rows   []R
+}
+
+// NewWriter creates a Writer writing the outputs of schema to out. Close must
+// be called once all the outputs are written to complete the file, out is not
+// closed by the Writer.
+func NewWriter[O, R any](out io.Writer, schema Schema[O, R], config Config) (*Writer[O, R], error) {
+	codec, err := config.Compression.codec()
+	if err != nil {
+		return nil, err
+	}
+	rowGroupSize := config.RowGroupSize
+	if rowGroupSize == 0 {
+		rowGroupSize = DefaultRowGroupSize
+	}
+	if rowGroupSize < 0 {
+		return nil, errors.Errorf("invalid row group size %d", rowGroupSize)
+	}
+
+	return &Writer[O, R]{
+		schema: schema,
+		writer: parquet.NewGenericWriter[R](
+			out,
+			parquet.Compression(codec),
+			parquet.MaxRowsPerRowGroup(rowGroupSize),
+			parquet.KeyValueMetadata(SchemaNameKey, schema.Name),
+			parquet.KeyValueMetadata(SchemaVersionKey, strconv.Itoa(schema.Version)),
+		),
+	}, nil
+}
+
+// Write converts outputs to rows and writes them to the file.
+func (w *Writer[O, R]) Write(outputs ...O) error {
+	w.rows = w.rows[:0]
+	for _, output := range outputs {
+		row, err := w.schema.convert(output)
+		if err != nil {
+			return errors.Wrapf(err, "could not convert %s output", w.schema.Name)
+		}
+		w.rows = append(w.rows, row)
+	}
+	if _, err := w.writer.Write(w.rows); err != nil {
+		return errors.Wrapf(err, "could not write %s rows", w.schema.Name)
+	}
+	return nil
+}
+
+// Close flushes the buffered rows and writes the footer of the file.
+func (w *Writer[O, R]) Close() error {
+	if err := w.writer.Close(); err != nil {
+		return errors.Wrapf(err, "could not close %s file", w.schema.Name)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package parquetwriter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claimablebalance "github.com/stellar/go/ingest/processors/claimable_balance_processor"
+	contract "github.com/stellar/go/ingest/processors/contract_processor"
+	operation "github.com/stellar/go/ingest/processors/operation_processor"
+	utils "github.com/stellar/go/ingest/processors/processor_utils"
+	transaction "github.com/stellar/go/ingest/processors/transaction_processor"
+	"github.com/stellar/go/xdr"
+)
+
+var closedAt = time.Unix(1700000000, 0).UTC()
+
+// schemaInfo describes a schema independently of its types.
+type schemaInfo struct {
+	name    string
+	version int
+	row     interface{}
+}
+
+var allSchemas = []schemaInfo{
+	{Ledgers.Name, Ledgers.Version, LedgerRow{}},
+	{Transactions.Name, Transactions.Version, TransactionRow{}},
+	{Operations.Name, Operations.Version, OperationRow{}},
+	{Effects.Name, Effects.Version, EffectRow{}},
+	{Trades.Name, Trades.Version, TradeRow{}},
+	{Accounts.Name, Accounts.Version, AccountRow{}},
+	{AccountSigners.Name, AccountSigners.Version, AccountSignerRow{}},
+	{Assets.Name, Assets.Version, AssetRow{}},
+	{ClaimableBalances.Name, ClaimableBalances.Version, ClaimableBalanceRow{}},
+	{ConfigSettings.Name, ConfigSettings.Version, ConfigSettingRow{}},
+	{ContractData.Name, ContractData.Version, ContractDataRow{}},
+	{ContractCode.Name, ContractCode.Version, ContractCodeRow{}},
+	{ContractEvents.Name, ContractEvents.Version, ContractEventRow{}},
+	{Ttls.Name, Ttls.Version, TtlRow{}},
+	{LiquidityPools.Name, LiquidityPools.Version, PoolRow{}},
+	{Offers.Name, Offers.Version, OfferRow{}},
+	{NormalizedOffers.Name, NormalizedOffers.Version, NormalizedOfferRow{}},
+	{Trustlines.Name, Trustlines.Version, TrustlineRow{}},
+}
+
+// TestSchemasAreStable compares the schemas with the ones recorded in
+// testdata. A schema cannot change without a new version, the file of the new
+// version is created by running the tests with UPDATE_SCHEMAS=true.
+func TestSchemasAreStable(t *testing.T) {
+	names := map[string]bool{}
+	for _, schema := range allSchemas {
+		assert.False(t, names[schema.name], "duplicate schema %s", schema.name)
+		names[schema.name] = true
+
+		path := filepath.Join("testdata", fmt.Sprintf("%s.v%d.schema", schema.name, schema.version))
+		actual := parquet.SchemaOf(schema.row).String()
+		if os.Getenv("UPDATE_SCHEMAS") == "true" {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				require.NoError(t, os.WriteFile(path, []byte(actual), 0644))
+			}
+		}
+		expected, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), actual, "the schema of %s changed, its version must be incremented", schema.name)
+	}
+}
+
+func TestTransactionsRoundTrip(t *testing.T) {
+	outputs := []transaction.TransactionOutput{
+		{
+			TransactionHash:    "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb",
+			LedgerSequence:     30521816,
+			Account:            "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
+			MaxFee:             4294967295,
+			OperationCount:     1,
+			CreatedAt:          closedAt,
+			ClosedAt:           closedAt,
+			Successful:         true,
+			TransactionID:      131090201534533632,
+			MinAccountSequence: null.IntFrom(123),
+			ExtraSigners:       []string{"GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA"},
+			TxSigners:          []string{"signer"},
+		},
+		{
+			TransactionHash: "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafc",
+			LedgerSequence:  30521816,
+			CreatedAt:       closedAt,
+			ClosedAt:        closedAt,
+			// lists are not nullable, nil lists are read back empty
+			ExtraSigners: []string{},
+			TxSigners:    []string{},
+		},
+	}
+
+	rows := roundTrip(t, Transactions, Config{}, outputs)
+	require.Len(t, rows, 2)
+	assert.Equal(t, int64(4294967295), rows[0].MaxFee)
+	assert.Equal(t, int64(123), *rows[0].MinAccountSequence)
+	assert.Nil(t, rows[0].MinAccountSequenceAge)
+	assert.Equal(t, []string{"GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA"}, rows[0].ExtraSigners)
+	assert.True(t, closedAt.Equal(rows[0].ClosedAt))
+	assert.Nil(t, rows[1].MinAccountSequence)
+	assert.Empty(t, rows[1].TxSigners)
+}
+
+func TestOperationsRoundTrip(t *testing.T) {
+	outputs := []operation.OperationOutput{{
+		SourceAccount: "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
+		Type:          1,
+		TypeString:    "payment",
+		OperationDetails: map[string]interface{}{
+			"amount": 35.0,
+			"path":   []utils.Path{{AssetType: "native"}},
+		},
+		OperationID: 131090201534533633,
+		ClosedAt:    closedAt,
+	}}
+
+	rows := roundTrip(t, Operations, Config{}, outputs)
+	require.Len(t, rows, 1)
+	assert.JSONEq(t, `{"amount": 35, "path": [{"asset_type": "native", "asset_code": "", "asset_issuer": ""}]}`, rows[0].OperationDetails)
+	assert.Equal(t, "null", rows[0].OperationDetailsJSON)
+
+	_, err := Operations.Convert(operation.OperationOutput{
+		OperationID:      1,
+		OperationDetails: map[string]interface{}{"invalid": func() {}},
+	})
+	assert.EqualError(t, err, "could not encode details of operation 1: json: unsupported type: func()")
+}
+
+func TestClaimableBalancesRoundTrip(t *testing.T) {
+	beforeAbsoluteTime := xdr.Int64(1700000000)
+	outputs := []claimablebalance.ClaimableBalanceOutput{{
+		BalanceID: "000000000a12cd57c169a34e7794bdcdf2d093fab135c59ea599e2d1233d7a53f26c1464",
+		Claimants: []utils.Claimant{
+			{
+				Destination: "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
+				Predicate:   xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateUnconditional},
+			},
+			{
+				Destination: "GAHK7EEG2WWHVKDNT4CEQFZGKF2LGDSW2IVM4S5DP42RBW3K6BTODB4A",
+				Predicate: xdr.ClaimPredicate{
+					Type:      xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime,
+					AbsBefore: &beforeAbsoluteTime,
+				},
+			},
+		},
+		AssetType: "native",
+		Sponsor:   null.StringFrom("GAHK7EEG2WWHVKDNT4CEQFZGKF2LGDSW2IVM4S5DP42RBW3K6BTODB4A"),
+		ClosedAt:  closedAt,
+	}}
+
+	rows := roundTrip(t, ClaimableBalances, Config{}, outputs)
+	require.Len(t, rows, 1)
+	require.Len(t, rows[0].Claimants, 2)
+	assert.Equal(t, "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA", rows[0].Claimants[0].Destination)
+	assert.JSONEq(t, `{"unconditional": true}`, rows[0].Claimants[0].Predicate)
+	assert.JSONEq(t, `{"abs_before": "2023-11-14T22:13:20Z", "abs_before_epoch": "1700000000"}`, rows[0].Claimants[1].Predicate)
+	assert.Equal(t, "GAHK7EEG2WWHVKDNT4CEQFZGKF2LGDSW2IVM4S5DP42RBW3K6BTODB4A", *rows[0].Sponsor)
+}
+
+func TestContractEventsRoundTrip(t *testing.T) {
+	sym := xdr.ScSymbol("transfer")
+	topics, topicsDecoded := contract.SerializeScValArray([]xdr.ScVal{
+		{Type: xdr.ScValTypeScvSymbol, Sym: &sym},
+	})
+	data, dataDecoded := contract.SerializeScVal(xdr.ScVal{Type: xdr.ScValTypeScvVoid})
+	outputs := []contract.ContractEventOutput{{
+		TransactionHash: "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb",
+		ClosedAt:        closedAt,
+		Topics:          map[string][]map[string]string{"topics": topics},
+		TopicsDecoded:   map[string][]map[string]string{"topics_decoded": topicsDecoded},
+		Data:            data,
+		DataDecoded:     dataDecoded,
+		TopicsJSON:      `["transfer"]`,
+		DataJSON:        `null`,
+	}}
+
+	rows := roundTrip(t, ContractEvents, Config{}, outputs)
+	require.Len(t, rows, 1)
+	assert.Equal(t, []ScValRow{{Type: "Sym", Value: "AAAADwAAAAh0cmFuc2Zlcg=="}}, rows[0].Topics)
+	assert.Equal(t, []ScValRow{{Type: "Sym", Value: "transfer"}}, rows[0].TopicsDecoded)
+	assert.Equal(t, ScValRow{Type: "", Value: "AAAAAQ=="}, rows[0].Data)
+	assert.JSONEq(t, `["transfer"]`, rows[0].TopicsJSON)
+	assert.JSONEq(t, `null`, rows[0].DataJSON)
+}
+
+func TestConfig(t *testing.T) {
+	outputs := make([]contract.TtlOutput, 10)
+	for i := range outputs {
+		outputs[i] = contract.TtlOutput{KeyHash: strconv.Itoa(i), LedgerSequence: uint32(i), ClosedAt: closedAt}
+	}
+
+	for _, compression := range []Compression{"", Uncompressed, Snappy, Gzip, Zstd, LZ4, Brotli} {
+		var buf bytes.Buffer
+		writer, err := NewWriter(&buf, Ttls, Config{RowGroupSize: 4, Compression: compression})
+		require.NoError(t, err)
+		require.NoError(t, writer.Write(outputs[:5]...))
+		require.NoError(t, writer.Write(outputs[5:]...))
+		require.NoError(t, writer.Close())
+
+		file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		assert.Len(t, file.RowGroups(), 3, compression)
+		assert.Equal(t, int64(10), file.NumRows())
+		name, _ := file.Lookup(SchemaNameKey)
+		assert.Equal(t, "ttls", name)
+		version, _ := file.Lookup(SchemaVersionKey)
+		assert.Equal(t, "1", version)
+
+		rows, err := parquet.Read[TtlRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		require.Len(t, rows, 10)
+		assert.Equal(t, "9", rows[9].KeyHash)
+	}
+
+	_, err := NewWriter(&bytes.Buffer{}, Ttls, Config{Compression: "lzo"})
+	assert.EqualError(t, err, "unknown compression lzo")
+	_, err = NewWriter(&bytes.Buffer{}, Ttls, Config{RowGroupSize: -1})
+	assert.EqualError(t, err, "invalid row group size -1")
+}
+
+func roundTrip[O, R any](t *testing.T, schema Schema[O, R], config Config, outputs []O) []R {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, schema, config)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(outputs...))
+	require.NoError(t, writer.Close())
+
+	rows, err := parquet.Read[R](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	for i, output := range outputs {
+		expected, err := schema.Convert(output)
+		require.NoError(t, err)
+		assert.Equal(t, expected, rows[i])
+	}
+	return rows
+}
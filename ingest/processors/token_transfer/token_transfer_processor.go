@@ -0,0 +1,650 @@
+package token_transfer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/address"
+	"github.com/stellar/go/ingest/asset"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/contractevents"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EventsProcessor derives TokenTransferEvents from ledgers and transactions.
+// It covers every classic operation which moves value between addresses as
+// well as Stellar Asset Contract (and SEP-41 compatible) contract events and
+// transaction fees.
+type EventsProcessor struct {
+	networkPassphrase string
+}
+
+// NewEventsProcessor creates a new EventsProcessor for the given network.
+func NewEventsProcessor(networkPassphrase string) *EventsProcessor {
+	return &EventsProcessor{networkPassphrase: networkPassphrase}
+}
+
+// EventsFromLedger returns all token transfer events in the given ledger.
+// Events are ordered by transaction; within a transaction the fee charge comes
+// first, followed by the events of each operation in order and finally any fee
+// refund.
+func (p *EventsProcessor) EventsFromLedger(lcm xdr.LedgerCloseMeta) ([]*TokenTransferEvent, error) {
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(p.networkPassphrase, lcm)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating transaction reader")
+	}
+	defer reader.Close()
+
+	var events []*TokenTransferEvent
+	for {
+		tx, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading transaction")
+		}
+
+		txEvents, err := p.EventsFromTransaction(tx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error processing transaction %s", tx.Result.TransactionHash.HexString())
+		}
+		events = append(events, txEvents...)
+	}
+	return events, nil
+}
+
+// EventsFromTransaction returns all token transfer events of a single
+// transaction. Failed transactions only produce fee events.
+func (p *EventsProcessor) EventsFromTransaction(tx ingest.LedgerTransaction) ([]*TokenTransferEvent, error) {
+	closedAt := time.Unix(tx.Ledger.LedgerCloseTime(), 0).UTC()
+	txHash := tx.Result.TransactionHash.HexString()
+
+	feeSource := tx.Envelope.SourceAccount()
+	if tx.Envelope.IsFeeBump() {
+		feeSource = tx.Envelope.FeeBumpAccount()
+	}
+	feeAccount := feeSource.ToAccountId()
+
+	var events []*TokenTransferEvent
+	if charged := -accountBalanceDelta(tx.FeeChanges, feeAccount); charged != 0 {
+		events = append(events, NewFeeEvent(
+			tx.Ledger.LedgerSequence(), closedAt, txHash,
+			muxedAccountAddress(feeSource), amount.StringFromInt64(charged), asset.NewNativeAsset(),
+		))
+	}
+
+	if tx.Successful() {
+		opResults, ok := tx.Result.OperationResults()
+		if !ok {
+			return nil, errors.New("transaction has no operation results")
+		}
+		for i, op := range tx.Envelope.Operations() {
+			opIndex := uint32(i)
+			meta := &EventMeta{
+				LedgerSequence: tx.Ledger.LedgerSequence(),
+				ClosedAt:       timestamppb.New(closedAt),
+				TxHash:         txHash,
+				OperationIndex: &opIndex,
+			}
+			changes, err := tx.GetOperationChanges(opIndex)
+			if err != nil {
+				return nil, err
+			}
+			opEvents, err := p.operationEvents(tx, meta, op, opResults[i], changes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error processing operation %d", opIndex)
+			}
+			events = append(events, opEvents...)
+		}
+	}
+
+	// Soroban transactions get the unused part of their resource fee back
+	// after they are applied.
+	if tx.UnsafeMeta.V == 3 {
+		refunded := accountBalanceDelta(tx.UnsafeMeta.MustV3().TxChangesAfter, feeAccount)
+		if refunded > 0 {
+			events = append(events, NewFeeEvent(
+				tx.Ledger.LedgerSequence(), closedAt, txHash,
+				muxedAccountAddress(feeSource), amount.StringFromInt64(-refunded), asset.NewNativeAsset(),
+			))
+		}
+	}
+
+	return events, nil
+}
+
+func (p *EventsProcessor) operationEvents(
+	tx ingest.LedgerTransaction,
+	meta *EventMeta,
+	op xdr.Operation,
+	opResult xdr.OperationResult,
+	changes []ingest.Change,
+) ([]*TokenTransferEvent, error) {
+	source := tx.Envelope.SourceAccount()
+	if op.SourceAccount != nil {
+		source = *op.SourceAccount
+	}
+	tr := opResult.MustTr()
+
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		createOp := op.Body.MustCreateAccountOp()
+		return []*TokenTransferEvent{
+			p.transferEvent(meta, source.ToAccountId(), muxedAccountAddress(source),
+				createOp.Destination, accountAddress(createOp.Destination),
+				xdr.MustNewNativeAsset(), int64(createOp.StartingBalance)),
+		}, nil
+
+	case xdr.OperationTypePayment:
+		paymentOp := op.Body.MustPaymentOp()
+		return []*TokenTransferEvent{
+			p.transferEvent(meta, source.ToAccountId(), muxedAccountAddress(source),
+				paymentOp.Destination.ToAccountId(), muxedAccountAddress(paymentOp.Destination),
+				paymentOp.Asset, int64(paymentOp.Amount)),
+		}, nil
+
+	case xdr.OperationTypePathPaymentStrictReceive:
+		ppOp := op.Body.MustPathPaymentStrictReceiveOp()
+		result := tr.MustPathPaymentStrictReceiveResult().MustSuccess()
+		events := p.claimAtomEvents(meta, source, result.Offers)
+		events = append(events, p.transferEvent(meta, source.ToAccountId(), muxedAccountAddress(source),
+			ppOp.Destination.ToAccountId(), muxedAccountAddress(ppOp.Destination),
+			ppOp.DestAsset, int64(ppOp.DestAmount)))
+		return events, nil
+
+	case xdr.OperationTypePathPaymentStrictSend:
+		ppOp := op.Body.MustPathPaymentStrictSendOp()
+		result := tr.MustPathPaymentStrictSendResult()
+		events := p.claimAtomEvents(meta, source, result.MustSuccess().Offers)
+		events = append(events, p.transferEvent(meta, source.ToAccountId(), muxedAccountAddress(source),
+			ppOp.Destination.ToAccountId(), muxedAccountAddress(ppOp.Destination),
+			ppOp.DestAsset, int64(result.DestAmount())))
+		return events, nil
+
+	case xdr.OperationTypeManageSellOffer, xdr.OperationTypeCreatePassiveSellOffer:
+		result := tr.MustManageSellOfferResult().MustSuccess()
+		return p.claimAtomEvents(meta, source, result.OffersClaimed), nil
+
+	case xdr.OperationTypeManageBuyOffer:
+		result := tr.MustManageBuyOfferResult().MustSuccess()
+		return p.claimAtomEvents(meta, source, result.OffersClaimed), nil
+
+	case xdr.OperationTypeAccountMerge:
+		destination := op.Body.MustDestination()
+		balance := tr.MustAccountMergeResult().MustSourceAccountBalance()
+		if balance == 0 {
+			return nil, nil
+		}
+		return []*TokenTransferEvent{
+			p.transferEvent(meta, source.ToAccountId(), muxedAccountAddress(source),
+				destination.ToAccountId(), muxedAccountAddress(destination),
+				xdr.MustNewNativeAsset(), int64(balance)),
+		}, nil
+
+	case xdr.OperationTypeInflation:
+		var events []*TokenTransferEvent
+		for _, payout := range tr.MustInflationResult().MustPayouts() {
+			events = append(events, NewMintEvent(meta, accountAddress(payout.Destination),
+				amount.String(payout.Amount), asset.NewNativeAsset()))
+		}
+		return events, nil
+
+	case xdr.OperationTypeCreateClaimableBalance:
+		createOp := op.Body.MustCreateClaimableBalanceOp()
+		balanceID := tr.MustCreateClaimableBalanceResult().MustBalanceId()
+		to, err := claimableBalanceAddress(balanceID)
+		if err != nil {
+			return nil, err
+		}
+		return []*TokenTransferEvent{
+			p.transferEvent(meta, source.ToAccountId(), muxedAccountAddress(source),
+				xdr.AccountId{}, to, createOp.Asset, int64(createOp.Amount)),
+		}, nil
+
+	case xdr.OperationTypeClaimClaimableBalance:
+		claimOp := op.Body.MustClaimClaimableBalanceOp()
+		cb, err := removedClaimableBalance(changes, claimOp.BalanceId)
+		if err != nil {
+			return nil, err
+		}
+		from, err := claimableBalanceAddress(cb.BalanceId)
+		if err != nil {
+			return nil, err
+		}
+		return []*TokenTransferEvent{
+			p.transferEvent(meta, xdr.AccountId{}, from,
+				source.ToAccountId(), muxedAccountAddress(source), cb.Asset, int64(cb.Amount)),
+		}, nil
+
+	case xdr.OperationTypeClawbackClaimableBalance:
+		clawbackOp := op.Body.MustClawbackClaimableBalanceOp()
+		cb, err := removedClaimableBalance(changes, clawbackOp.BalanceId)
+		if err != nil {
+			return nil, err
+		}
+		from, err := claimableBalanceAddress(cb.BalanceId)
+		if err != nil {
+			return nil, err
+		}
+		return []*TokenTransferEvent{
+			NewClawbackEvent(meta, from, amount.String(cb.Amount), asset.NewProtoAsset(cb.Asset)),
+		}, nil
+
+	case xdr.OperationTypeClawback:
+		clawbackOp := op.Body.MustClawbackOp()
+		return []*TokenTransferEvent{
+			NewClawbackEvent(meta, muxedAccountAddress(clawbackOp.From),
+				amount.String(clawbackOp.Amount), asset.NewProtoAsset(clawbackOp.Asset)),
+		}, nil
+
+	case xdr.OperationTypeLiquidityPoolDeposit:
+		depositOp := op.Body.MustLiquidityPoolDepositOp()
+		return p.liquidityPoolEvents(meta, source, depositOp.LiquidityPoolId, changes)
+
+	case xdr.OperationTypeLiquidityPoolWithdraw:
+		withdrawOp := op.Body.MustLiquidityPoolWithdrawOp()
+		return p.liquidityPoolEvents(meta, source, withdrawOp.LiquidityPoolId, changes)
+
+	case xdr.OperationTypeAllowTrust, xdr.OperationTypeSetTrustLineFlags:
+		return p.revocationEvents(meta, changes)
+
+	case xdr.OperationTypeInvokeHostFunction:
+		return p.contractEvents(tx, meta)
+
+	case xdr.OperationTypeChangeTrust,
+		xdr.OperationTypeSetOptions,
+		xdr.OperationTypeManageData,
+		xdr.OperationTypeBumpSequence,
+		xdr.OperationTypeBeginSponsoringFutureReserves,
+		xdr.OperationTypeEndSponsoringFutureReserves,
+		xdr.OperationTypeRevokeSponsorship,
+		xdr.OperationTypeExtendFootprintTtl,
+		xdr.OperationTypeRestoreFootprint:
+		// These operations don't move any value
+		return nil, nil
+
+	default:
+		return nil, fmt.Errorf("unknown operation type: %s", op.Body.Type)
+	}
+}
+
+// transferEvent builds the event for a movement of a classic asset between two
+// addresses. Transfers out of the issuer are reported as mints and transfers
+// into the issuer are reported as burns, since classic issuers don't hold
+// balances of their own assets. fromAccount and toAccount are only used for
+// the issuer check and may be empty for non-account addresses.
+func (p *EventsProcessor) transferEvent(
+	meta *EventMeta,
+	fromAccount xdr.AccountId, from *address.Address,
+	toAccount xdr.AccountId, to *address.Address,
+	xdrAsset xdr.Asset, amt int64,
+) *TokenTransferEvent {
+	protoAsset := asset.NewProtoAsset(xdrAsset)
+	amountStr := amount.StringFromInt64(amt)
+
+	switch {
+	case isIssuer(fromAccount, xdrAsset) && !isIssuer(toAccount, xdrAsset):
+		return NewMintEvent(meta, to, amountStr, protoAsset)
+	case isIssuer(toAccount, xdrAsset) && !isIssuer(fromAccount, xdrAsset):
+		return NewBurnEvent(meta, from, amountStr, protoAsset)
+	default:
+		return NewTransferEvent(meta, from, to, amountStr, protoAsset)
+	}
+}
+
+// claimAtomEvents generates the events for every offer or liquidity pool
+// crossed by the taker. For each hop the taker sends AssetBought to the maker
+// and receives AssetSold from it.
+func (p *EventsProcessor) claimAtomEvents(meta *EventMeta, taker xdr.MuxedAccount, claims []xdr.ClaimAtom) []*TokenTransferEvent {
+	var events []*TokenTransferEvent
+	for _, claim := range claims {
+		var (
+			makerAccount xdr.AccountId
+			makerAddress *address.Address
+		)
+		if claim.Type == xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
+			makerAddress = liquidityPoolAddress(claim.LiquidityPool.LiquidityPoolId)
+		} else {
+			makerAccount = claim.SellerId()
+			makerAddress = accountAddress(makerAccount)
+		}
+
+		events = append(events,
+			p.transferEvent(meta, taker.ToAccountId(), muxedAccountAddress(taker),
+				makerAccount, makerAddress, claim.AssetBought(), int64(claim.AmountBought())),
+			p.transferEvent(meta, makerAccount, makerAddress,
+				taker.ToAccountId(), muxedAccountAddress(taker), claim.AssetSold(), int64(claim.AmountSold())),
+		)
+	}
+	return events
+}
+
+// liquidityPoolEvents generates the events of a deposit into or a withdrawal
+// from a liquidity pool, based on the change in the pool reserves.
+func (p *EventsProcessor) liquidityPoolEvents(
+	meta *EventMeta,
+	source xdr.MuxedAccount,
+	poolID xdr.PoolId,
+	changes []ingest.Change,
+) ([]*TokenTransferEvent, error) {
+	for _, delta := range liquidityPoolDeltas(changes) {
+		if delta.poolID != poolID {
+			continue
+		}
+
+		poolAddress := liquidityPoolAddress(poolID)
+		var events []*TokenTransferEvent
+		for i, reserveDelta := range delta.reserves {
+			switch {
+			case reserveDelta > 0:
+				events = append(events, p.transferEvent(meta,
+					source.ToAccountId(), muxedAccountAddress(source),
+					xdr.AccountId{}, poolAddress,
+					delta.assets[i], reserveDelta))
+			case reserveDelta < 0:
+				events = append(events, p.transferEvent(meta,
+					xdr.AccountId{}, poolAddress,
+					source.ToAccountId(), muxedAccountAddress(source),
+					delta.assets[i], -reserveDelta))
+			}
+		}
+		return events, nil
+	}
+	return nil, errors.Errorf("liquidity pool %x change not found", poolID)
+}
+
+// revocationEvents generates the events caused by revoking the authorization
+// of a trustline which participates in liquidity pools. The pool shares get
+// redeemed and each reserve is moved into a claimable balance, unless the
+// owner of the shares is the asset issuer in which case it is burned.
+func (p *EventsProcessor) revocationEvents(meta *EventMeta, changes []ingest.Change) ([]*TokenTransferEvent, error) {
+	var createdBalances []xdr.ClaimableBalanceEntry
+	for _, change := range changes {
+		if change.Type == xdr.LedgerEntryTypeClaimableBalance && change.Pre == nil && change.Post != nil {
+			createdBalances = append(createdBalances, change.Post.Data.MustClaimableBalance())
+		}
+	}
+	// Core's claimable balance metadata isn't ordered, so we order it
+	// ourselves so that events are ordered consistently
+	sort.SliceStable(createdBalances, func(i, j int) bool {
+		return createdBalances[i].Asset.LessThan(createdBalances[j].Asset)
+	})
+
+	var events []*TokenTransferEvent
+	for _, delta := range liquidityPoolDeltas(changes) {
+		poolAddress := liquidityPoolAddress(delta.poolID)
+		for i, reserveDelta := range delta.reserves {
+			if reserveDelta >= 0 {
+				continue
+			}
+
+			cbIndex := -1
+			for j, cb := range createdBalances {
+				if cb.Asset.Equals(delta.assets[i]) && int64(cb.Amount) == -reserveDelta {
+					cbIndex = j
+					break
+				}
+			}
+			if cbIndex < 0 {
+				events = append(events, NewBurnEvent(meta, poolAddress,
+					amount.StringFromInt64(-reserveDelta), asset.NewProtoAsset(delta.assets[i])))
+				continue
+			}
+
+			cb := createdBalances[cbIndex]
+			createdBalances = append(createdBalances[:cbIndex], createdBalances[cbIndex+1:]...)
+			to, err := claimableBalanceAddress(cb.BalanceId)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, NewTransferEvent(meta, poolAddress, to,
+				amount.StringFromInt64(-reserveDelta), asset.NewProtoAsset(delta.assets[i])))
+		}
+	}
+	return events, nil
+}
+
+// contractEvents generates the events for the token contract events emitted
+// by an InvokeHostFunction operation.
+func (p *EventsProcessor) contractEvents(tx ingest.LedgerTransaction, meta *EventMeta) ([]*TokenTransferEvent, error) {
+	if tx.UnsafeMeta.V != 3 || tx.UnsafeMeta.MustV3().SorobanMeta == nil {
+		return nil, nil
+	}
+
+	var events []*TokenTransferEvent
+	for _, contractEvent := range tx.UnsafeMeta.MustV3().SorobanMeta.Events {
+		event, err := p.contractEvent(meta, contractEvent)
+		if err != nil {
+			return nil, err
+		}
+		if event != nil {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (p *EventsProcessor) contractEvent(opMeta *EventMeta, contractEvent xdr.ContractEvent) (*TokenTransferEvent, error) {
+	if contractEvent.Type != xdr.ContractEventTypeContract || contractEvent.ContractId == nil || contractEvent.Body.V != 0 {
+		return nil, nil
+	}
+
+	contractID, err := strkey.Encode(strkey.VersionByteContract, contractEvent.ContractId[:])
+	if err != nil {
+		return nil, err
+	}
+	meta := &EventMeta{
+		LedgerSequence:  opMeta.LedgerSequence,
+		ClosedAt:        opMeta.ClosedAt,
+		TxHash:          opMeta.TxHash,
+		OperationIndex:  opMeta.OperationIndex,
+		ContractAddress: &address.Address{AddressType: address.AddressType_ADDRESS_TYPE_CONTRACT, StrKey: contractID},
+	}
+
+	sacEvent, err := contractevents.NewStellarAssetContractEvent(&contractEvent, p.networkPassphrase)
+	switch errors.Cause(err) {
+	case nil:
+		return sacTokenTransferEvent(meta, sacEvent), nil
+	case contractevents.ErrNotStellarAssetContract, contractevents.ErrEventIntegrity:
+		return customTokenTransferEvent(meta, contractEvent.Body.MustV0())
+	default:
+		// Events which look like SAC events but can't be parsed are not
+		// token movements we can describe.
+		return nil, nil
+	}
+}
+
+func sacTokenTransferEvent(meta *EventMeta, sacEvent contractevents.StellarAssetContractEvent) *TokenTransferEvent {
+	xdrAsset := sacEvent.GetAsset()
+	protoAsset := asset.NewProtoAsset(xdrAsset)
+	issuer := xdrAsset.GetIssuer()
+
+	switch event := sacEvent.(type) {
+	case *contractevents.TransferEvent:
+		amt := amount.String128(event.Amount)
+		switch {
+		case event.From == issuer && event.To != issuer:
+			return NewMintEvent(meta, strKeyAddress(event.To), amt, protoAsset)
+		case event.To == issuer && event.From != issuer:
+			return NewBurnEvent(meta, strKeyAddress(event.From), amt, protoAsset)
+		default:
+			return NewTransferEvent(meta, strKeyAddress(event.From), strKeyAddress(event.To), amt, protoAsset)
+		}
+	case *contractevents.MintEvent:
+		return NewMintEvent(meta, strKeyAddress(event.To), amount.String128(event.Amount), protoAsset)
+	case *contractevents.BurnEvent:
+		return NewBurnEvent(meta, strKeyAddress(event.From), amount.String128(event.Amount), protoAsset)
+	case *contractevents.ClawbackEvent:
+		return NewClawbackEvent(meta, strKeyAddress(event.From), amount.String128(event.Amount), protoAsset)
+	default:
+		return nil
+	}
+}
+
+// customTokenTransferEvent parses events following the SEP-41 token interface
+// which weren't emitted by a Stellar Asset Contract. The resulting events have
+// no asset.
+func customTokenTransferEvent(meta *EventMeta, body xdr.ContractEventV0) (*TokenTransferEvent, error) {
+	topics := body.Topics
+	if len(topics) < 2 {
+		return nil, nil
+	}
+	fn, ok := topics[0].GetSym()
+	if !ok {
+		return nil, nil
+	}
+	amt, ok := body.Data.GetI128()
+	if !ok {
+		return nil, nil
+	}
+
+	addresses := make([]*address.Address, 0, len(topics)-1)
+	for _, topic := range topics[1:] {
+		scAddress, ok := topic.GetAddress()
+		if !ok {
+			break
+		}
+		strKey, err := scAddress.String()
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, strKeyAddress(strKey))
+	}
+
+	switch {
+	case fn == "transfer" && len(addresses) == 2:
+		return NewTransferEvent(meta, addresses[0], addresses[1], amount.String128(amt), nil), nil
+	case fn == "mint" && len(addresses) == 2:
+		return NewMintEvent(meta, addresses[1], amount.String128(amt), nil), nil
+	case fn == "burn" && len(addresses) == 1:
+		return NewBurnEvent(meta, addresses[0], amount.String128(amt), nil), nil
+	case fn == "clawback" && len(addresses) == 2:
+		return NewClawbackEvent(meta, addresses[1], amount.String128(amt), nil), nil
+	default:
+		return nil, nil
+	}
+}
+
+type liquidityPoolDelta struct {
+	poolID   xdr.PoolId
+	assets   [2]xdr.Asset
+	reserves [2]int64
+}
+
+// liquidityPoolDeltas returns the reserve changes of every constant product
+// pool updated or removed in changes.
+func liquidityPoolDeltas(changes []ingest.Change) []liquidityPoolDelta {
+	var deltas []liquidityPoolDelta
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeLiquidityPool || change.Pre == nil {
+			continue
+		}
+		pre := change.Pre.Data.MustLiquidityPool()
+		preBody := pre.Body.MustConstantProduct()
+		delta := liquidityPoolDelta{
+			poolID:   pre.LiquidityPoolId,
+			assets:   [2]xdr.Asset{preBody.Params.AssetA, preBody.Params.AssetB},
+			reserves: [2]int64{-int64(preBody.ReserveA), -int64(preBody.ReserveB)},
+		}
+		if change.Post != nil {
+			postBody := change.Post.Data.MustLiquidityPool().Body.MustConstantProduct()
+			delta.reserves[0] += int64(postBody.ReserveA)
+			delta.reserves[1] += int64(postBody.ReserveB)
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas
+}
+
+// removedClaimableBalance finds the state of the given claimable balance before
+// it was removed by a claim or a clawback.
+func removedClaimableBalance(changes []ingest.Change, balanceID xdr.ClaimableBalanceId) (xdr.ClaimableBalanceEntry, error) {
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeClaimableBalance || change.Pre == nil || change.Post != nil {
+			continue
+		}
+		cb := change.Pre.Data.MustClaimableBalance()
+		if cb.BalanceId.Type == balanceID.Type && cb.BalanceId.MustV0() == balanceID.MustV0() {
+			return cb, nil
+		}
+	}
+	return xdr.ClaimableBalanceEntry{}, errors.New("claimable balance removal not found")
+}
+
+// accountBalanceDelta returns the change in native balance of the given
+// account in changes.
+func accountBalanceDelta(changes xdr.LedgerEntryChanges, account xdr.AccountId) int64 {
+	var delta int64
+	for _, change := range ingest.GetChangesFromLedgerEntryChanges(changes) {
+		if change.Type != xdr.LedgerEntryTypeAccount || change.Pre == nil || change.Post == nil {
+			continue
+		}
+		pre := change.Pre.Data.MustAccount()
+		if !pre.AccountId.Equals(account) {
+			continue
+		}
+		delta += int64(change.Post.Data.MustAccount().Balance - pre.Balance)
+	}
+	return delta
+}
+
+func isIssuer(account xdr.AccountId, xdrAsset xdr.Asset) bool {
+	if xdrAsset.Type == xdr.AssetTypeAssetTypeNative || account.Ed25519 == nil {
+		return false
+	}
+	return account.Address() == xdrAsset.GetIssuer()
+}
+
+func accountAddress(account xdr.AccountId) *address.Address {
+	return &address.Address{
+		AddressType: address.AddressType_ADDRESS_TYPE_ACCOUNT,
+		StrKey:      account.Address(),
+	}
+}
+
+func muxedAccountAddress(account xdr.MuxedAccount) *address.Address {
+	addressType := address.AddressType_ADDRESS_TYPE_ACCOUNT
+	if account.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		addressType = address.AddressType_ADDRESS_TYPE_MUXED_ACCOUNT
+	}
+	return &address.Address{
+		AddressType: addressType,
+		StrKey:      account.Address(),
+	}
+}
+
+func liquidityPoolAddress(poolID xdr.PoolId) *address.Address {
+	return &address.Address{
+		AddressType: address.AddressType_ADDRESS_TYPE_LIQUIDITY_POOL,
+		StrKey:      fmt.Sprintf("%x", poolID[:]),
+	}
+}
+
+func claimableBalanceAddress(balanceID xdr.ClaimableBalanceId) (*address.Address, error) {
+	id, err := xdr.MarshalHex(balanceID)
+	if err != nil {
+		return nil, err
+	}
+	return &address.Address{
+		AddressType: address.AddressType_ADDRESS_TYPE_CLAIMABLE_BALANCE,
+		StrKey:      id,
+	}, nil
+}
+
+// strKeyAddress builds an address from a G... or C... strkey as found in
+// contract events.
+func strKeyAddress(strKey string) *address.Address {
+	addressType := address.AddressType_ADDRESS_TYPE_ACCOUNT
+	if version, err := strkey.Version(strKey); err == nil && version == strkey.VersionByteContract {
+		addressType = address.AddressType_ADDRESS_TYPE_CONTRACT
+	}
+	return &address.Address{AddressType: addressType, StrKey: strKey}
+}
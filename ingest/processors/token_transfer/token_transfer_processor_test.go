@@ -0,0 +1,702 @@
+package token_transfer
+
+import (
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/address"
+	"github.com/stellar/go/ingest/asset"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	someAccount  = xdr.MustAddress(keypair.MustRandom().Address())
+	otherAccount = xdr.MustAddress(keypair.MustRandom().Address())
+	issuer       = xdr.MustAddress(keypair.MustRandom().Address())
+	usdc         = xdr.MustNewCreditAsset("USDC", issuer.Address())
+)
+
+func testLedger() xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					LedgerSeq: 12345,
+					ScpValue:  xdr.StellarValue{CloseTime: 1700000000},
+				},
+			},
+		},
+	}
+}
+
+func accountEntryChanges(account xdr.AccountId, pre, post xdr.Int64) xdr.LedgerEntryChanges {
+	entry := func(balance xdr.Int64) xdr.LedgerEntry {
+		return xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type:    xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{AccountId: account, Balance: balance},
+			},
+		}
+	}
+	preEntry, postEntry := entry(pre), entry(post)
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &preEntry},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &postEntry},
+	}
+}
+
+func testTransaction(successful bool, ops []xdr.Operation, results []xdr.OperationResult, opMeta []xdr.OperationMeta) ingest.LedgerTransaction {
+	code := xdr.TransactionResultCodeTxSuccess
+	if !successful {
+		code = xdr.TransactionResultCodeTxFailed
+	}
+	return ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: someAccount.ToMuxedAccount(),
+					Operations:    ops,
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			TransactionHash: xdr.Hash{1},
+			Result: xdr.TransactionResult{
+				FeeCharged: 100,
+				Result: xdr.TransactionResultResult{
+					Code:    code,
+					Results: &results,
+				},
+			},
+		},
+		FeeChanges: accountEntryChanges(someAccount, 1000, 900),
+		UnsafeMeta: xdr.TransactionMeta{
+			V:  2,
+			V2: &xdr.TransactionMetaV2{Operations: opMeta},
+		},
+		Ledger: testLedger(),
+	}
+}
+
+func paymentOp(from, to xdr.AccountId, a xdr.Asset, amt xdr.Int64) (xdr.Operation, xdr.OperationResult) {
+	source := from.ToMuxedAccount()
+	return xdr.Operation{
+		SourceAccount: &source,
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: to.ToMuxedAccount(),
+				Asset:       a,
+				Amount:      amt,
+			},
+		},
+	}, xdr.OperationResult{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type:          xdr.OperationTypePayment,
+			PaymentResult: &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentSuccess},
+		},
+	}
+}
+
+func accountAddr(account xdr.AccountId) *address.Address {
+	return &address.Address{AddressType: address.AddressType_ADDRESS_TYPE_ACCOUNT, StrKey: account.Address()}
+}
+
+func assertEvents(t *testing.T, expected, actual []*TokenTransferEvent) {
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.True(t, proto.Equal(expected[i].Asset, actual[i].Asset), "asset of event %d", i)
+		assert.Equal(t, expected[i].GetMeta().GetOperationIndex(), actual[i].GetMeta().GetOperationIndex(), "operation index of event %d", i)
+		assert.True(t, proto.Equal(expected[i].GetMeta().GetContractAddress(), actual[i].GetMeta().GetContractAddress()), "contract address of event %d", i)
+
+		switch {
+		case expected[i].GetTransfer() != nil:
+			assert.True(t, proto.Equal(expected[i].GetTransfer(), actual[i].GetTransfer()), "event %d: %v", i, actual[i])
+		case expected[i].GetMint() != nil:
+			assert.True(t, proto.Equal(expected[i].GetMint(), actual[i].GetMint()), "event %d: %v", i, actual[i])
+		case expected[i].GetBurn() != nil:
+			assert.True(t, proto.Equal(expected[i].GetBurn(), actual[i].GetBurn()), "event %d: %v", i, actual[i])
+		case expected[i].GetClawback() != nil:
+			assert.True(t, proto.Equal(expected[i].GetClawback(), actual[i].GetClawback()), "event %d: %v", i, actual[i])
+		case expected[i].GetFee() != nil:
+			assert.True(t, proto.Equal(expected[i].GetFee(), actual[i].GetFee()), "event %d: %v", i, actual[i])
+		}
+	}
+}
+
+func opMeta(index uint32) *EventMeta {
+	return &EventMeta{OperationIndex: &index}
+}
+
+func TestFailedTransactionOnlyProducesFee(t *testing.T) {
+	op, result := paymentOp(someAccount, otherAccount, usdc, 100)
+	tx := testTransaction(false, []xdr.Operation{op}, []xdr.OperationResult{result}, []xdr.OperationMeta{{}})
+
+	events, err := NewEventsProcessor(network.TestNetworkPassphrase).EventsFromTransaction(tx)
+	require.NoError(t, err)
+	assertEvents(t, []*TokenTransferEvent{
+		{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+	}, events)
+	assert.Equal(t, uint32(12345), events[0].GetMeta().GetLedgerSequence())
+	assert.Equal(t, int64(1700000000), events[0].GetMeta().GetClosedAt().GetSeconds())
+	assert.Equal(t, xdr.Hash{1}.HexString(), events[0].GetMeta().GetTxHash())
+}
+
+func TestPaymentEvents(t *testing.T) {
+	transferOp, transferResult := paymentOp(someAccount, otherAccount, usdc, 100)
+	mintOp, mintResult := paymentOp(issuer, otherAccount, usdc, 200)
+	burnOp, burnResult := paymentOp(someAccount, issuer, usdc, 300)
+	nativeOp, nativeResult := paymentOp(someAccount, otherAccount, xdr.MustNewNativeAsset(), 400)
+
+	tx := testTransaction(true,
+		[]xdr.Operation{transferOp, mintOp, burnOp, nativeOp},
+		[]xdr.OperationResult{transferResult, mintResult, burnResult, nativeResult},
+		[]xdr.OperationMeta{{}, {}, {}, {}},
+	)
+
+	events, err := NewEventsProcessor(network.TestNetworkPassphrase).EventsFromTransaction(tx)
+	require.NoError(t, err)
+	assertEvents(t, []*TokenTransferEvent{
+		{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+		NewTransferEvent(opMeta(0), accountAddr(someAccount), accountAddr(otherAccount), "0.0000100", asset.NewProtoAsset(usdc)),
+		NewMintEvent(opMeta(1), accountAddr(otherAccount), "0.0000200", asset.NewProtoAsset(usdc)),
+		NewBurnEvent(opMeta(2), accountAddr(someAccount), "0.0000300", asset.NewProtoAsset(usdc)),
+		NewTransferEvent(opMeta(3), accountAddr(someAccount), accountAddr(otherAccount), "0.0000400", asset.NewNativeAsset()),
+	}, events)
+}
+
+func TestPathPaymentEvents(t *testing.T) {
+	seller := xdr.MustAddress(keypair.MustRandom().Address())
+	poolID := xdr.PoolId{0xab}
+	eur := xdr.MustNewCreditAsset("EUR", issuer.Address())
+	source := someAccount.ToMuxedAccount()
+
+	op := xdr.Operation{
+		SourceAccount: &source,
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePathPaymentStrictSend,
+			PathPaymentStrictSendOp: &xdr.PathPaymentStrictSendOp{
+				SendAsset:   xdr.MustNewNativeAsset(),
+				SendAmount:  1000,
+				Destination: otherAccount.ToMuxedAccount(),
+				DestAsset:   eur,
+				DestMin:     1,
+				Path:        []xdr.Asset{usdc},
+			},
+		},
+	}
+	result := xdr.OperationResult{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypePathPaymentStrictSend,
+			PathPaymentStrictSendResult: &xdr.PathPaymentStrictSendResult{
+				Code: xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess,
+				Success: &xdr.PathPaymentStrictSendResultSuccess{
+					Offers: []xdr.ClaimAtom{
+						{
+							Type: xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+							OrderBook: &xdr.ClaimOfferAtom{
+								SellerId:     seller,
+								OfferId:      1,
+								AssetSold:    usdc,
+								AmountSold:   500,
+								AssetBought:  xdr.MustNewNativeAsset(),
+								AmountBought: 1000,
+							},
+						},
+						{
+							Type: xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool,
+							LiquidityPool: &xdr.ClaimLiquidityAtom{
+								LiquidityPoolId: poolID,
+								AssetSold:       eur,
+								AmountSold:      250,
+								AssetBought:     usdc,
+								AmountBought:    500,
+							},
+						},
+					},
+					Last: xdr.SimplePaymentResult{
+						Destination: otherAccount,
+						Asset:       eur,
+						Amount:      250,
+					},
+				},
+			},
+		},
+	}
+
+	tx := testTransaction(true, []xdr.Operation{op}, []xdr.OperationResult{result}, []xdr.OperationMeta{{}})
+	events, err := NewEventsProcessor(network.TestNetworkPassphrase).EventsFromTransaction(tx)
+	require.NoError(t, err)
+
+	pool := &address.Address{AddressType: address.AddressType_ADDRESS_TYPE_LIQUIDITY_POOL, StrKey: liquidityPoolAddress(poolID).StrKey}
+	assertEvents(t, []*TokenTransferEvent{
+		{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+		NewTransferEvent(opMeta(0), accountAddr(someAccount), accountAddr(seller), "0.0001000", asset.NewNativeAsset()),
+		NewTransferEvent(opMeta(0), accountAddr(seller), accountAddr(someAccount), "0.0000500", asset.NewProtoAsset(usdc)),
+		NewTransferEvent(opMeta(0), accountAddr(someAccount), pool, "0.0000500", asset.NewProtoAsset(usdc)),
+		NewTransferEvent(opMeta(0), pool, accountAddr(someAccount), "0.0000250", asset.NewProtoAsset(eur)),
+		NewTransferEvent(opMeta(0), accountAddr(someAccount), accountAddr(otherAccount), "0.0000250", asset.NewProtoAsset(eur)),
+	}, events)
+}
+
+func TestClaimClaimableBalanceEvents(t *testing.T) {
+	balanceID := xdr.ClaimableBalanceId{
+		Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0,
+		V0:   &xdr.Hash{7},
+	}
+	source := otherAccount.ToMuxedAccount()
+	op := xdr.Operation{
+		SourceAccount: &source,
+		Body: xdr.OperationBody{
+			Type:                    xdr.OperationTypeClaimClaimableBalance,
+			ClaimClaimableBalanceOp: &xdr.ClaimClaimableBalanceOp{BalanceId: balanceID},
+		},
+	}
+	result := xdr.OperationResult{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypeClaimClaimableBalance,
+			ClaimClaimableBalanceResult: &xdr.ClaimClaimableBalanceResult{
+				Code: xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceSuccess,
+			},
+		},
+	}
+	pre := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeClaimableBalance,
+			ClaimableBalance: &xdr.ClaimableBalanceEntry{
+				BalanceId: balanceID,
+				Asset:     usdc,
+				Amount:    700,
+			},
+		},
+	}
+	removedKey, err := pre.LedgerKey()
+	require.NoError(t, err)
+	meta := []xdr.OperationMeta{{
+		Changes: xdr.LedgerEntryChanges{
+			{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+			{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &removedKey},
+		},
+	}}
+
+	tx := testTransaction(true, []xdr.Operation{op}, []xdr.OperationResult{result}, meta)
+	events, err := NewEventsProcessor(network.TestNetworkPassphrase).EventsFromTransaction(tx)
+	require.NoError(t, err)
+
+	cb, err := claimableBalanceAddress(balanceID)
+	require.NoError(t, err)
+	assertEvents(t, []*TokenTransferEvent{
+		{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+		NewTransferEvent(opMeta(0), cb, accountAddr(otherAccount), "0.0000700", asset.NewProtoAsset(usdc)),
+	}, events)
+}
+
+func TestStellarAssetContractEvents(t *testing.T) {
+	contractID, err := usdc.ContractID(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	xdrContractID := xdr.Hash(contractID)
+	contractAddress := &address.Address{
+		AddressType: address.AddressType_ADDRESS_TYPE_CONTRACT,
+		StrKey:      strkey.MustEncode(strkey.VersionByteContract, contractID[:]),
+	}
+
+	scAddress := func(account xdr.AccountId) xdr.ScVal {
+		addr := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &account}
+		return xdr.ScVal{Type: xdr.ScValTypeScvAddress, Address: &addr}
+	}
+	sym := func(s string) xdr.ScVal {
+		symbol := xdr.ScSymbol(s)
+		return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &symbol}
+	}
+	assetStr := xdr.ScString(usdc.StringCanonical())
+	assetTopic := xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &assetStr}
+	amt := xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &xdr.Int128Parts{Lo: 1234}}
+
+	contractEvent := func(topics ...xdr.ScVal) xdr.ContractEvent {
+		return xdr.ContractEvent{
+			Type:       xdr.ContractEventTypeContract,
+			ContractId: &xdrContractID,
+			Body: xdr.ContractEventBody{
+				V:  0,
+				V0: &xdr.ContractEventV0{Topics: topics, Data: amt},
+			},
+		}
+	}
+
+	source := someAccount.ToMuxedAccount()
+	op := xdr.Operation{
+		SourceAccount: &source,
+		Body: xdr.OperationBody{
+			Type:                 xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{},
+		},
+	}
+	result := xdr.OperationResult{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionResult: &xdr.InvokeHostFunctionResult{
+				Code: xdr.InvokeHostFunctionResultCodeInvokeHostFunctionSuccess,
+			},
+		},
+	}
+
+	tx := testTransaction(true, []xdr.Operation{op}, []xdr.OperationResult{result}, nil)
+	tx.UnsafeMeta = xdr.TransactionMeta{
+		V: 3,
+		V3: &xdr.TransactionMetaV3{
+			Operations:     []xdr.OperationMeta{{}},
+			TxChangesAfter: accountEntryChanges(someAccount, 900, 950),
+			SorobanMeta: &xdr.SorobanTransactionMeta{
+				Events: []xdr.ContractEvent{
+					contractEvent(sym("transfer"), scAddress(someAccount), scAddress(otherAccount), assetTopic),
+					contractEvent(sym("transfer"), scAddress(issuer), scAddress(otherAccount), assetTopic),
+					contractEvent(sym("burn"), scAddress(otherAccount), assetTopic),
+					contractEvent(sym("approve"), scAddress(someAccount), scAddress(otherAccount), assetTopic),
+				},
+			},
+		},
+	}
+
+	events, err := NewEventsProcessor(network.TestNetworkPassphrase).EventsFromTransaction(tx)
+	require.NoError(t, err)
+
+	contractMeta := &EventMeta{OperationIndex: opMeta(0).OperationIndex, ContractAddress: contractAddress}
+	assertEvents(t, []*TokenTransferEvent{
+		{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+		NewTransferEvent(contractMeta, accountAddr(someAccount), accountAddr(otherAccount), "0.0001234", asset.NewProtoAsset(usdc)),
+		NewMintEvent(contractMeta, accountAddr(otherAccount), "0.0001234", asset.NewProtoAsset(usdc)),
+		NewBurnEvent(contractMeta, accountAddr(otherAccount), "0.0001234", asset.NewProtoAsset(usdc)),
+		{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "-0.0000050"}}},
+	}, events)
+}
+
+func TestOperationEvents(t *testing.T) {
+	poolID := xdr.PoolId{0xcd}
+	pool := liquidityPoolAddress(poolID)
+	balanceID := func(b byte) xdr.ClaimableBalanceId {
+		return xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &xdr.Hash{b}}
+	}
+	cbAddress := func(id xdr.ClaimableBalanceId) *address.Address {
+		cb, err := claimableBalanceAddress(id)
+		require.NoError(t, err)
+		return cb
+	}
+
+	poolEntry := func(reserveA, reserveB xdr.Int64) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeLiquidityPool,
+				LiquidityPool: &xdr.LiquidityPoolEntry{
+					LiquidityPoolId: poolID,
+					Body: xdr.LiquidityPoolEntryBody{
+						Type: xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+						ConstantProduct: &xdr.LiquidityPoolEntryConstantProduct{
+							Params: xdr.LiquidityPoolConstantProductParameters{
+								AssetA: xdr.MustNewNativeAsset(),
+								AssetB: usdc,
+								Fee:    xdr.LiquidityPoolFeeV18,
+							},
+							ReserveA: reserveA,
+							ReserveB: reserveB,
+						},
+					},
+				},
+			},
+		}
+	}
+	cbEntry := func(id xdr.ClaimableBalanceId, a xdr.Asset, amt xdr.Int64) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type:             xdr.LedgerEntryTypeClaimableBalance,
+				ClaimableBalance: &xdr.ClaimableBalanceEntry{BalanceId: id, Asset: a, Amount: amt},
+			},
+		}
+	}
+	updated := func(pre, post *xdr.LedgerEntry) xdr.LedgerEntryChanges {
+		return xdr.LedgerEntryChanges{
+			{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: pre},
+			{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: post},
+		}
+	}
+	removed := func(pre *xdr.LedgerEntry) xdr.LedgerEntryChanges {
+		key, err := pre.LedgerKey()
+		require.NoError(t, err)
+		return xdr.LedgerEntryChanges{
+			{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: pre},
+			{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key},
+		}
+	}
+	created := func(post *xdr.LedgerEntry) xdr.LedgerEntryChanges {
+		return xdr.LedgerEntryChanges{{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: post}}
+	}
+	concat := func(changes ...xdr.LedgerEntryChanges) xdr.LedgerEntryChanges {
+		var result xdr.LedgerEntryChanges
+		for _, c := range changes {
+			result = append(result, c...)
+		}
+		return result
+	}
+
+	operation := func(source xdr.AccountId, body xdr.OperationBody) xdr.Operation {
+		muxed := source.ToMuxedAccount()
+		return xdr.Operation{SourceAccount: &muxed, Body: body}
+	}
+	revokeOp := func() xdr.OperationBody {
+		return xdr.OperationBody{
+			Type: xdr.OperationTypeSetTrustLineFlags,
+			SetTrustLineFlagsOp: &xdr.SetTrustLineFlagsOp{
+				Trustor:    someAccount,
+				Asset:      usdc,
+				ClearFlags: xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+			},
+		}
+	}
+	mergeBalance := xdr.Int64(5000)
+	noBalance := xdr.Int64(0)
+	merge := func(balance *xdr.Int64) xdr.OperationResultTr {
+		return xdr.OperationResultTr{
+			Type: xdr.OperationTypeAccountMerge,
+			AccountMergeResult: &xdr.AccountMergeResult{
+				Code:                 xdr.AccountMergeResultCodeAccountMergeSuccess,
+				SourceAccountBalance: balance,
+			},
+		}
+	}
+	destination := otherAccount.ToMuxedAccount()
+	payouts := []xdr.InflationPayout{
+		{Destination: someAccount, Amount: 600},
+		{Destination: otherAccount, Amount: 400},
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		op       xdr.Operation
+		result   xdr.OperationResultTr
+		changes  xdr.LedgerEntryChanges
+		expected []*TokenTransferEvent
+	}{
+		{
+			name: "liquidity pool deposit",
+			op: operation(someAccount, xdr.OperationBody{
+				Type:                   xdr.OperationTypeLiquidityPoolDeposit,
+				LiquidityPoolDepositOp: &xdr.LiquidityPoolDepositOp{LiquidityPoolId: poolID},
+			}),
+			result: xdr.OperationResultTr{
+				Type:                       xdr.OperationTypeLiquidityPoolDeposit,
+				LiquidityPoolDepositResult: &xdr.LiquidityPoolDepositResult{Code: xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess},
+			},
+			changes: updated(poolEntry(1000, 2000), poolEntry(1100, 2200)),
+			expected: []*TokenTransferEvent{
+				NewTransferEvent(opMeta(0), accountAddr(someAccount), pool, "0.0000100", asset.NewNativeAsset()),
+				NewTransferEvent(opMeta(0), accountAddr(someAccount), pool, "0.0000200", asset.NewProtoAsset(usdc)),
+			},
+		},
+		{
+			name: "liquidity pool deposit by the issuer",
+			op: operation(issuer, xdr.OperationBody{
+				Type:                   xdr.OperationTypeLiquidityPoolDeposit,
+				LiquidityPoolDepositOp: &xdr.LiquidityPoolDepositOp{LiquidityPoolId: poolID},
+			}),
+			result: xdr.OperationResultTr{
+				Type:                       xdr.OperationTypeLiquidityPoolDeposit,
+				LiquidityPoolDepositResult: &xdr.LiquidityPoolDepositResult{Code: xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess},
+			},
+			changes: updated(poolEntry(1000, 2000), poolEntry(1100, 2200)),
+			expected: []*TokenTransferEvent{
+				NewTransferEvent(opMeta(0), accountAddr(issuer), pool, "0.0000100", asset.NewNativeAsset()),
+				NewMintEvent(opMeta(0), pool, "0.0000200", asset.NewProtoAsset(usdc)),
+			},
+		},
+		{
+			name: "liquidity pool withdraw",
+			op: operation(someAccount, xdr.OperationBody{
+				Type:                    xdr.OperationTypeLiquidityPoolWithdraw,
+				LiquidityPoolWithdrawOp: &xdr.LiquidityPoolWithdrawOp{LiquidityPoolId: poolID, Amount: 100},
+			}),
+			result: xdr.OperationResultTr{
+				Type:                        xdr.OperationTypeLiquidityPoolWithdraw,
+				LiquidityPoolWithdrawResult: &xdr.LiquidityPoolWithdrawResult{Code: xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawSuccess},
+			},
+			changes: updated(poolEntry(1100, 2200), poolEntry(1000, 2000)),
+			expected: []*TokenTransferEvent{
+				NewTransferEvent(opMeta(0), pool, accountAddr(someAccount), "0.0000100", asset.NewNativeAsset()),
+				NewTransferEvent(opMeta(0), pool, accountAddr(someAccount), "0.0000200", asset.NewProtoAsset(usdc)),
+			},
+		},
+		{
+			name: "trustline revocation into claimable balances",
+			op:   operation(issuer, revokeOp()),
+			result: xdr.OperationResultTr{
+				Type:                    xdr.OperationTypeSetTrustLineFlags,
+				SetTrustLineFlagsResult: &xdr.SetTrustLineFlagsResult{Code: xdr.SetTrustLineFlagsResultCodeSetTrustLineFlagsSuccess},
+			},
+			changes: concat(
+				updated(poolEntry(1000, 2000), poolEntry(900, 1800)),
+				// created out of order, events follow the order of the reserves
+				created(cbEntry(balanceID(2), usdc, 200)),
+				created(cbEntry(balanceID(1), xdr.MustNewNativeAsset(), 100)),
+			),
+			expected: []*TokenTransferEvent{
+				NewTransferEvent(opMeta(0), pool, cbAddress(balanceID(1)), "0.0000100", asset.NewNativeAsset()),
+				NewTransferEvent(opMeta(0), pool, cbAddress(balanceID(2)), "0.0000200", asset.NewProtoAsset(usdc)),
+			},
+		},
+		{
+			name: "trustline revocation without claimable balance",
+			op: operation(issuer, xdr.OperationBody{
+				Type: xdr.OperationTypeAllowTrust,
+				AllowTrustOp: &xdr.AllowTrustOp{
+					Trustor: someAccount,
+					Asset:   xdr.MustNewAssetCodeFromString("USDC"),
+				},
+			}),
+			result: xdr.OperationResultTr{
+				Type:             xdr.OperationTypeAllowTrust,
+				AllowTrustResult: &xdr.AllowTrustResult{Code: xdr.AllowTrustResultCodeAllowTrustSuccess},
+			},
+			changes: concat(
+				removed(poolEntry(100, 200)),
+				created(cbEntry(balanceID(1), xdr.MustNewNativeAsset(), 100)),
+			),
+			expected: []*TokenTransferEvent{
+				NewTransferEvent(opMeta(0), pool, cbAddress(balanceID(1)), "0.0000100", asset.NewNativeAsset()),
+				NewBurnEvent(opMeta(0), pool, "0.0000200", asset.NewProtoAsset(usdc)),
+			},
+		},
+		{
+			name: "clawback",
+			op: operation(issuer, xdr.OperationBody{
+				Type: xdr.OperationTypeClawback,
+				ClawbackOp: &xdr.ClawbackOp{
+					Asset:  usdc,
+					From:   someAccount.ToMuxedAccount(),
+					Amount: 300,
+				},
+			}),
+			result: xdr.OperationResultTr{
+				Type:           xdr.OperationTypeClawback,
+				ClawbackResult: &xdr.ClawbackResult{Code: xdr.ClawbackResultCodeClawbackSuccess},
+			},
+			expected: []*TokenTransferEvent{
+				NewClawbackEvent(opMeta(0), accountAddr(someAccount), "0.0000300", asset.NewProtoAsset(usdc)),
+			},
+		},
+		{
+			name: "clawback claimable balance",
+			op: operation(issuer, xdr.OperationBody{
+				Type:                       xdr.OperationTypeClawbackClaimableBalance,
+				ClawbackClaimableBalanceOp: &xdr.ClawbackClaimableBalanceOp{BalanceId: balanceID(3)},
+			}),
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeClawbackClaimableBalance,
+				ClawbackClaimableBalanceResult: &xdr.ClawbackClaimableBalanceResult{
+					Code: xdr.ClawbackClaimableBalanceResultCodeClawbackClaimableBalanceSuccess,
+				},
+			},
+			changes: removed(cbEntry(balanceID(3), usdc, 700)),
+			expected: []*TokenTransferEvent{
+				NewClawbackEvent(opMeta(0), cbAddress(balanceID(3)), "0.0000700", asset.NewProtoAsset(usdc)),
+			},
+		},
+		{
+			name:   "account merge",
+			op:     operation(someAccount, xdr.OperationBody{Type: xdr.OperationTypeAccountMerge, Destination: &destination}),
+			result: merge(&mergeBalance),
+			expected: []*TokenTransferEvent{
+				NewTransferEvent(opMeta(0), accountAddr(someAccount), accountAddr(otherAccount), "0.0005000", asset.NewNativeAsset()),
+			},
+		},
+		{
+			name:   "account merge without balance",
+			op:     operation(someAccount, xdr.OperationBody{Type: xdr.OperationTypeAccountMerge, Destination: &destination}),
+			result: merge(&noBalance),
+		},
+		{
+			name: "inflation",
+			op:   operation(someAccount, xdr.OperationBody{Type: xdr.OperationTypeInflation}),
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeInflation,
+				InflationResult: &xdr.InflationResult{
+					Code:    xdr.InflationResultCodeInflationSuccess,
+					Payouts: &payouts,
+				},
+			},
+			expected: []*TokenTransferEvent{
+				NewMintEvent(opMeta(0), accountAddr(someAccount), "0.0000600", asset.NewNativeAsset()),
+				NewMintEvent(opMeta(0), accountAddr(otherAccount), "0.0000400", asset.NewNativeAsset()),
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := xdr.OperationResult{Code: xdr.OperationResultCodeOpInner, Tr: &testCase.result}
+			tx := testTransaction(true,
+				[]xdr.Operation{testCase.op},
+				[]xdr.OperationResult{result},
+				[]xdr.OperationMeta{{Changes: testCase.changes}},
+			)
+
+			events, err := NewEventsProcessor(network.TestNetworkPassphrase).EventsFromTransaction(tx)
+			require.NoError(t, err)
+			expected := append([]*TokenTransferEvent{
+				{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+			}, testCase.expected...)
+			assertEvents(t, expected, events)
+		})
+	}
+}
+
+func TestSorobanFeeRefundEvents(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		after    xdr.LedgerEntryChanges
+		expected []*TokenTransferEvent
+	}{
+		{
+			name:  "refund",
+			after: accountEntryChanges(someAccount, 900, 975),
+			expected: []*TokenTransferEvent{
+				{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+				{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "-0.0000075"}}},
+			},
+		},
+		{
+			name:  "no refund",
+			after: accountEntryChanges(someAccount, 900, 900),
+			expected: []*TokenTransferEvent{
+				{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+			},
+		},
+		{
+			name:  "refund of another account",
+			after: accountEntryChanges(otherAccount, 900, 975),
+			expected: []*TokenTransferEvent{
+				{Asset: asset.NewNativeAsset(), Event: &TokenTransferEvent_Fee{Fee: &Fee{From: accountAddr(someAccount), Amount: "0.0000100"}}},
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			tx := testTransaction(true, nil, nil, nil)
+			tx.UnsafeMeta = xdr.TransactionMeta{
+				V:  3,
+				V3: &xdr.TransactionMetaV3{TxChangesAfter: testCase.after},
+			}
+
+			events, err := NewEventsProcessor(network.TestNetworkPassphrase).EventsFromTransaction(tx)
+			require.NoError(t, err)
+			assertEvents(t, testCase.expected, events)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package statestore
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// loadBatchSize is the number of entries Initialize writes in a single
+// transaction.
+const loadBatchSize = 10000
+
+// Initialize loads the state at the checkpoint ledger sequence from reader,
+// usually an ingest.CheckpointChangeReader. The store must not have been
+// initialized before.
+//
+// Entries are written in batches and the ledger sequence is set with the
+// last one, so a store left behind by an interrupted Initialize is still not
+// initialized. Calling Initialize again discards the entries it has written.
+func (s *Store) Initialize(reader ingest.ChangeReader, sequence uint32) error {
+	if sequence == 0 {
+		return errors.New("ledger sequence must be greater than 0")
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if current := ledgerSequence(tx); current != 0 {
+			return errors.Errorf("state store is already initialized at ledger %d", current)
+		}
+		for _, name := range [][]byte{entriesBucket, countsBucket} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return errors.Wrap(err, "could not discard entries")
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return errors.Wrap(err, "could not discard entries")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	batch := make([]xdr.LedgerEntry, 0, loadBatchSize)
+	for {
+		change, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read change")
+		}
+		if change.Post == nil {
+			return errors.Errorf("change of %s entry has no post entry", change.Type)
+		}
+
+		batch = append(batch, *change.Post)
+		if len(batch) == loadBatchSize {
+			if err := s.load(batch, 0); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	return s.load(batch, sequence)
+}
+
+// load writes a batch of checkpoint entries and, if sequence isn't 0, sets
+// the ledger sequence.
+func (s *Store) load(entries []xdr.LedgerEntry, sequence uint32) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		w := newWriter(tx)
+		for _, entry := range entries {
+			key, err := entry.LedgerKey()
+			if err != nil {
+				return errors.Wrap(err, "could not get ledger key")
+			}
+			rawKey, err := key.MarshalBinary()
+			if err != nil {
+				return errors.Wrap(err, "could not marshal ledger key")
+			}
+			if w.exists(rawKey) {
+				return stateError("entry %s appears twice in the checkpoint", rawKey)
+			}
+			if err := w.put(rawKey, entry, true); err != nil {
+				return err
+			}
+		}
+		if sequence != 0 {
+			return setLedgerSequence(tx, sequence)
+		}
+		return nil
+	})
+}
+
+// ApplyChanges applies the changes of ledger sequence, which must be the
+// ledger following the last one applied to the store. The changes are
+// squashed with an ingest.ChangeCompactor, so they can be passed in the order
+// they were read, and applied in a single transaction: either all or none of
+// them are applied. A change that doesn't match the state in the store, like
+// creating an entry that already exists, fails with an ingest.StateError.
+func (s *Store) ApplyChanges(sequence uint32, changes []ingest.Change) error {
+	compactor := ingest.NewChangeCompactor()
+	for _, change := range changes {
+		if err := compactor.AddChange(change); err != nil {
+			return errors.Wrap(err, "could not compact change")
+		}
+	}
+	return s.apply(sequence, compactor, nil)
+}
+
+// ApplyLedgerCloseMeta applies the changes of the ledger in lcm, see
+// ApplyChanges. Besides the changes read by ingest.LedgerChangeReader it
+// removes the temporary entries evicted in the ledger.
+func (s *Store) ApplyLedgerCloseMeta(networkPassphrase string, lcm xdr.LedgerCloseMeta) error {
+	reader, err := ingest.NewLedgerChangeReaderFromLedgerCloseMeta(networkPassphrase, lcm)
+	if err != nil {
+		return errors.Wrap(err, "could not create change reader")
+	}
+	defer reader.Close()
+
+	compactor := ingest.NewChangeCompactor()
+	for {
+		change, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read change")
+		}
+		if err := compactor.AddChange(change); err != nil {
+			return errors.Wrap(err, "could not compact change")
+		}
+	}
+
+	evicted, err := lcm.EvictedTemporaryLedgerKeys()
+	if err != nil {
+		return errors.Wrap(err, "could not get evicted ledger keys")
+	}
+
+	return s.apply(lcm.LedgerSequence(), compactor, evicted)
+}
+
+func (s *Store) apply(sequence uint32, compactor *ingest.ChangeCompactor, evicted []xdr.LedgerKey) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		current := ledgerSequence(tx)
+		if current == 0 {
+			return errors.New("state store is not initialized")
+		}
+		if sequence != current+1 {
+			return errors.Errorf("expected changes of ledger %d, got ledger %d", current+1, sequence)
+		}
+
+		w := newWriter(tx)
+		for _, change := range compactor.GetChanges() {
+			if err := w.applyChange(change); err != nil {
+				return err
+			}
+		}
+
+		// Evicted keys are removed only if they exist: depending on the
+		// protocol version, an evicted entry can be both listed here and
+		// removed by an eviction change.
+		for _, key := range evicted {
+			rawKey, err := key.MarshalBinary()
+			if err != nil {
+				return errors.Wrap(err, "could not marshal ledger key")
+			}
+			if !w.exists(rawKey) {
+				continue
+			}
+			if err := w.delete(rawKey); err != nil {
+				return err
+			}
+		}
+
+		return setLedgerSequence(tx, sequence)
+	})
+}
+
+// writer writes entries in a transaction, keeping the counts of entries of
+// each type up to date.
+type writer struct {
+	entries *bolt.Bucket
+	counts  *bolt.Bucket
+}
+
+func newWriter(tx *bolt.Tx) writer {
+	return writer{
+		entries: tx.Bucket(entriesBucket),
+		counts:  tx.Bucket(countsBucket),
+	}
+}
+
+func (w writer) applyChange(change ingest.Change) error {
+	key, err := change.LedgerKey()
+	if err != nil {
+		return errors.Wrap(err, "could not get ledger key")
+	}
+	rawKey, err := key.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "could not marshal ledger key")
+	}
+
+	exists := w.exists(rawKey)
+	switch change.LedgerEntryChangeType() {
+	case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+		if exists {
+			return stateError("cannot create entry %s, it already exists", rawKey)
+		}
+		return w.put(rawKey, *change.Post, true)
+	case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+		if !exists {
+			return stateError("cannot update entry %s, it does not exist", rawKey)
+		}
+		return w.put(rawKey, *change.Post, false)
+	case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+		if !exists {
+			return stateError("cannot remove entry %s, it does not exist", rawKey)
+		}
+		return w.delete(rawKey)
+	}
+	return errors.Errorf("unexpected change type %s", change.LedgerEntryChangeType())
+}
+
+func (w writer) exists(rawKey []byte) bool {
+	return w.entries.Get(rawKey) != nil
+}
+
+func (w writer) put(rawKey []byte, entry xdr.LedgerEntry, created bool) error {
+	value, err := entry.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "could not marshal ledger entry")
+	}
+	if err := w.entries.Put(rawKey, value); err != nil {
+		return errors.Wrap(err, "could not write ledger entry")
+	}
+	if created {
+		return w.addToCount(rawKey, 1)
+	}
+	return nil
+}
+
+func (w writer) delete(rawKey []byte) error {
+	if err := w.entries.Delete(rawKey); err != nil {
+		return errors.Wrap(err, "could not delete ledger entry")
+	}
+	return w.addToCount(rawKey, -1)
+}
+
+// addToCount adds delta to the count of entries of the type of rawKey.
+func (w writer) addToCount(rawKey []byte, delta int64) error {
+	// the first 4 bytes of an XDR encoded ledger key are the entry type
+	entryType := rawKey[:4]
+	count := int64(decodeCount(w.counts.Get(entryType))) + delta
+	if count == 0 {
+		return w.counts.Delete(entryType)
+	}
+	return w.counts.Put(entryType, binary.BigEndian.AppendUint64(nil, uint64(count)))
+}
+
+func stateError(format string, rawKey []byte) error {
+	return ingest.NewStateError(errors.Errorf(format, base64.StdEncoding.EncodeToString(rawKey)))
+}
@@ -0,0 +1,113 @@
+package statestore
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/stellar/go/xdr"
+)
+
+// union is implemented by the XDR unions generated in the xdr package.
+type union interface {
+	SwitchFieldName() string
+	ArmForSwitch(int32) (string, bool)
+}
+
+// inKeyOrder returns true if the entries of the type of rawKey are stored in
+// the order stellar-core uses in buckets. Ledger keys are stored XDR encoded,
+// so entries are stored in that order only if their keys have no variable
+// length values: XDR puts the length of a value before its content, while
+// stellar-core compares the content first.
+func inKeyOrder(rawKey []byte) bool {
+	switch xdr.LedgerEntryType(binary.BigEndian.Uint32(rawKey)) {
+	case xdr.LedgerEntryTypeData, xdr.LedgerEntryTypeContractData:
+		return false
+	default:
+		return true
+	}
+}
+
+// compareLedgerKeys compares ledger keys in the order of stellar-core
+// buckets: by entry type and then by the fields of the key in the order they
+// are declared. Strings and variable length values are compared element by
+// element, shorter values first if one is a prefix of the other, unions by
+// discriminant and then by arm and optional values are absent first.
+func compareLedgerKeys(a, b xdr.LedgerKey) int {
+	return compareXDR(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func compareXDR(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Ptr:
+		switch {
+		case a.IsNil() && b.IsNil():
+			return 0
+		case a.IsNil():
+			return -1
+		case b.IsNil():
+			return 1
+		}
+		return compareXDR(a.Elem(), b.Elem())
+	case reflect.Struct:
+		if u, ok := a.Interface().(union); ok {
+			return compareUnions(u, a, b)
+		}
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareXDR(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < a.Len() && i < b.Len(); i++ {
+			if c := compareXDR(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.Len(), b.Len())
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case b.Bool():
+			return -1
+		default:
+			return 1
+		}
+	}
+	panic(fmt.Sprintf("cannot compare XDR values of type %s", a.Type()))
+}
+
+func compareUnions(u union, a, b reflect.Value) int {
+	name := u.SwitchFieldName()
+	if c := compareXDR(a.FieldByName(name), b.FieldByName(name)); c != 0 {
+		return c
+	}
+
+	var discriminant int32
+	switch sw := a.FieldByName(name); sw.Kind() {
+	case reflect.Bool:
+		if sw.Bool() {
+			discriminant = 1
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		discriminant = int32(sw.Uint())
+	default:
+		discriminant = int32(sw.Int())
+	}
+	arm, ok := u.ArmForSwitch(discriminant)
+	if !ok || arm == "" {
+		return 0
+	}
+	// arms are stored as pointers, set for the arm of the discriminant
+	return compareXDR(a.FieldByName(arm).Elem(), b.FieldByName(arm).Elem())
+}
@@ -0,0 +1,151 @@
+package statestore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"io"
+	"slices"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/verify"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// verifyBatchSize is the number of entries Verify compares at a time.
+const verifyBatchSize = 50000
+
+// WriteBucket writes the state in the store to w as a gzip compressed history
+// archive bucket: a METAENTRY with ledgerVersion followed by a LIVEENTRY for
+// every entry, in the ledger key order of stellar-core buckets. It returns
+// the hash of the bucket, which history archives use to name it. Published
+// as the only bucket of a bucket list, for example as the curr bucket of
+// level 0, it holds the state at LedgerSequence() and can be read with
+// ingest.CheckpointChangeReader.
+//
+// Entries of types whose keys aren't stored in that order, data and contract
+// data entries, are sorted in memory one type at a time.
+func (s *Store) WriteBucket(w io.Writer, ledgerVersion uint32) (xdr.Hash, error) {
+	var hash xdr.Hash
+	hasher := sha256.New()
+	gzipWriter := gzip.NewWriter(w)
+	out := io.MultiWriter(gzipWriter, hasher)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		meta := xdr.BucketEntry{
+			Type:      xdr.BucketEntryTypeMetaentry,
+			MetaEntry: &xdr.BucketMetadata{LedgerVersion: xdr.Uint32(ledgerVersion)},
+		}
+		if err := xdr.MarshalFramed(out, meta); err != nil {
+			return errors.Wrap(err, "could not write bucket metadata")
+		}
+
+		entries := tx.Bucket(entriesBucket)
+		cursor := entries.Cursor()
+		for rawKey, value := cursor.First(); rawKey != nil; {
+			if inKeyOrder(rawKey) {
+				if err := writeLiveEntry(out, value); err != nil {
+					return err
+				}
+				rawKey, value = cursor.Next()
+				continue
+			}
+
+			entryType := append([]byte(nil), rawKey[:4]...)
+			var keys []xdr.LedgerKey
+			for ; rawKey != nil && bytes.HasPrefix(rawKey, entryType); rawKey, value = cursor.Next() {
+				var key xdr.LedgerKey
+				if err := key.UnmarshalBinary(rawKey); err != nil {
+					return errors.Wrap(err, "could not unmarshal ledger key")
+				}
+				keys = append(keys, key)
+			}
+			slices.SortFunc(keys, compareLedgerKeys)
+			for _, key := range keys {
+				sortedKey, err := key.MarshalBinary()
+				if err != nil {
+					return errors.Wrap(err, "could not marshal ledger key")
+				}
+				if err := writeLiveEntry(out, entries.Get(sortedKey)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return hash, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return hash, errors.Wrap(err, "could not write bucket")
+	}
+
+	copy(hash[:], hasher.Sum(nil))
+	return hash, nil
+}
+
+func writeLiveEntry(out io.Writer, value []byte) error {
+	var entry xdr.LedgerEntry
+	if err := unmarshalEntry(value, &entry); err != nil {
+		return err
+	}
+	live := xdr.BucketEntry{Type: xdr.BucketEntryTypeLiveentry, LiveEntry: &entry}
+	if err := xdr.MarshalFramed(out, live); err != nil {
+		return errors.Wrap(err, "could not write bucket entry")
+	}
+	return nil
+}
+
+// Verify checks with verify.StateVerifier that the state in the store is the
+// state read from reader, usually an ingest.CheckpointChangeReader of the
+// ledger sequence, which must be the last ledger applied to the store. An
+// invalid state is returned as ingest.StateError. The store is read in a
+// single transaction, changes can be applied while Verify runs.
+func (s *Store) Verify(sequence uint32, reader ingest.ChangeReader) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		if current := ledgerSequence(tx); current != sequence {
+			return errors.Errorf("state store is at ledger %d, not %d", current, sequence)
+		}
+
+		verifier := verify.NewStateVerifier(reader, nil)
+		entries := tx.Bucket(entriesBucket)
+		for {
+			expected, err := verifier.GetLedgerEntries(verifyBatchSize)
+			if err != nil {
+				return err
+			}
+			if len(expected) == 0 {
+				break
+			}
+
+			for _, expectedEntry := range expected {
+				key, err := expectedEntry.LedgerKey()
+				if err != nil {
+					return errors.Wrap(err, "could not get ledger key")
+				}
+				rawKey, err := key.MarshalBinary()
+				if err != nil {
+					return errors.Wrap(err, "could not marshal ledger key")
+				}
+				value := entries.Get(rawKey)
+				if value == nil {
+					// reported by the verifier with the next batch
+					continue
+				}
+
+				var entry xdr.LedgerEntry
+				if err := unmarshalEntry(value, &entry); err != nil {
+					return err
+				}
+				if err := verifier.Write(entry); err != nil {
+					return err
+				}
+			}
+		}
+
+		return verifier.Verify(int(countAll(tx)))
+	})
+}
@@ -0,0 +1,190 @@
+// Package statestore provides an embedded, on-disk store of the ledger state
+// that is kept up to date by applying ledger entry changes ledger by ledger,
+// without the need for a database server.
+//
+// A store is initialized from a checkpoint with Initialize and then updated
+// with ApplyLedgerCloseMeta (or ApplyChanges) for every following ledger. The
+// state at the last applied ledger can be read with Get and Iterate, exported
+// as a history archive bucket with WriteBucket and checked against a history
+// archive checkpoint with Verify.
+package statestore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// openTimeout is the time Open waits for the lock of a store used by another
+// process.
+const openTimeout = 5 * time.Second
+
+var (
+	// entriesBucket maps XDR encoded ledger keys to XDR encoded ledger
+	// entries. XDR encoded keys start with the entry type so the entries of
+	// a type are stored next to each other.
+	entriesBucket = []byte("entries")
+	// countsBucket maps XDR encoded entry types to the number of entries of
+	// the type.
+	countsBucket = []byte("counts")
+	metaBucket   = []byte("meta")
+
+	ledgerSequenceKey = []byte("ledger_sequence")
+)
+
+// Store is a snapshot of the ledger state at a single ledger, stored in a
+// bbolt database file. It's safe to use from multiple goroutines: reads see
+// the state at the last ledger applied when they started and writes are
+// serialized.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the store at path, creating it if it doesn't exist. A store can
+// be opened by a single process at a time.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open state store %s", path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{entriesBucket, countsBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "could not create state store buckets")
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the store, waiting for pending reads and writes.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// LedgerSequence returns the sequence of the last ledger applied to the
+// store, or 0 if the store hasn't been initialized.
+func (s *Store) LedgerSequence() (uint32, error) {
+	var sequence uint32
+	err := s.db.View(func(tx *bolt.Tx) error {
+		sequence = ledgerSequence(tx)
+		return nil
+	})
+	return sequence, err
+}
+
+// Get returns the entry with the given key. The returned bool is false if the
+// entry doesn't exist.
+func (s *Store) Get(key xdr.LedgerKey) (xdr.LedgerEntry, bool, error) {
+	var entry xdr.LedgerEntry
+	rawKey, err := key.MarshalBinary()
+	if err != nil {
+		return entry, false, errors.Wrap(err, "could not marshal ledger key")
+	}
+
+	var found bool
+	err = s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(entriesBucket).Get(rawKey)
+		if value == nil {
+			return nil
+		}
+		found = true
+		return unmarshalEntry(value, &entry)
+	})
+	return entry, found, err
+}
+
+// Iterate calls fn for every entry of entryType, in the order of their XDR
+// encoded ledger keys. It stops at the first error returned by fn and returns
+// it. All entries are read from the same ledger, and fn must not write to the
+// store.
+func (s *Store) Iterate(entryType xdr.LedgerEntryType, fn func(xdr.LedgerEntry) error) error {
+	prefix, err := entryType.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "could not marshal entry type")
+	}
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(entriesBucket).Cursor()
+		for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			var entry xdr.LedgerEntry
+			if err := unmarshalEntry(value, &entry); err != nil {
+				return err
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Count returns the number of entries of the given types, or of all entries
+// if no types are given.
+func (s *Store) Count(entryTypes ...xdr.LedgerEntryType) (int, error) {
+	var count uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		counts := tx.Bucket(countsBucket)
+		if len(entryTypes) == 0 {
+			count = countAll(tx)
+			return nil
+		}
+		for _, entryType := range entryTypes {
+			prefix, err := entryType.MarshalBinary()
+			if err != nil {
+				return errors.Wrap(err, "could not marshal entry type")
+			}
+			count += decodeCount(counts.Get(prefix))
+		}
+		return nil
+	})
+	return int(count), err
+}
+
+func ledgerSequence(tx *bolt.Tx) uint32 {
+	value := tx.Bucket(metaBucket).Get(ledgerSequenceKey)
+	if value == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(value)
+}
+
+func setLedgerSequence(tx *bolt.Tx, sequence uint32) error {
+	return tx.Bucket(metaBucket).Put(ledgerSequenceKey, binary.BigEndian.AppendUint32(nil, sequence))
+}
+
+func countAll(tx *bolt.Tx) uint64 {
+	var count uint64
+	// ForEach only fails if the callback does
+	_ = tx.Bucket(countsBucket).ForEach(func(_, value []byte) error {
+		count += decodeCount(value)
+		return nil
+	})
+	return count
+}
+
+func decodeCount(value []byte) uint64 {
+	if value == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(value)
+}
+
+func unmarshalEntry(value []byte, entry *xdr.LedgerEntry) error {
+	if err := entry.UnmarshalBinary(value); err != nil {
+		return errors.Wrap(err, "could not unmarshal ledger entry")
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package statestore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+const checkpointLedger = uint32(63)
+
+// sliceChangeReader reads the creation of entries.
+type sliceChangeReader struct {
+	entries []xdr.LedgerEntry
+}
+
+func (r *sliceChangeReader) Read() (ingest.Change, error) {
+	if len(r.entries) == 0 {
+		return ingest.Change{}, io.EOF
+	}
+	entry := r.entries[0]
+	r.entries = r.entries[1:]
+	return ingest.Change{Type: entry.Data.Type, Post: &entry}, nil
+}
+
+func (r *sliceChangeReader) Close() error {
+	return nil
+}
+
+func (r *sliceChangeReader) VerifyBucketList(xdr.Hash) error {
+	return nil
+}
+
+func accountEntry(address string, balance int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 10,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId:  xdr.MustAddress(address),
+				Balance:    xdr.Int64(balance),
+				Thresholds: xdr.Thresholds{1, 0, 0, 0},
+			},
+		},
+	}
+}
+
+func temporaryEntries(symbol string) (xdr.LedgerEntry, xdr.LedgerEntry) {
+	sym := xdr.ScSymbol(symbol)
+	contractID := xdr.Hash{1}
+	val := xdr.Uint32(1)
+	data := xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 20,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract: xdr.ScAddress{
+					Type:       xdr.ScAddressTypeScAddressTypeContract,
+					ContractId: &contractID,
+				},
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym},
+				Durability: xdr.ContractDataDurabilityTemporary,
+				Val:        xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &val},
+			},
+		},
+	}
+	key, err := data.LedgerKey()
+	if err != nil {
+		panic(err)
+	}
+	rawKey, err := key.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	keyHash := xdr.Hash(sha256.Sum256(rawKey))
+	ttl := xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 20,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTtl,
+			Ttl:  &xdr.TtlEntry{KeyHash: keyHash, LiveUntilLedgerSeq: 40},
+		},
+	}
+	return data, ttl
+}
+
+func ledgerKey(t *testing.T, entry xdr.LedgerEntry) xdr.LedgerKey {
+	key, err := entry.LedgerKey()
+	require.NoError(t, err)
+	return key
+}
+
+func openStore(t *testing.T) *Store {
+	store, err := Open(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, store.Close())
+	})
+	return store
+}
+
+func initializedStore(t *testing.T, entries ...xdr.LedgerEntry) *Store {
+	store := openStore(t)
+	require.NoError(t, store.Initialize(&sliceChangeReader{entries: entries}, checkpointLedger))
+	return store
+}
+
+func iterate(t *testing.T, store *Store, entryType xdr.LedgerEntryType) []xdr.LedgerEntry {
+	var entries []xdr.LedgerEntry
+	require.NoError(t, store.Iterate(entryType, func(entry xdr.LedgerEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	return entries
+}
+
+func TestInitialize(t *testing.T) {
+	alice := accountEntry("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", 100)
+	bob := accountEntry("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON", 200)
+	data, ttl := temporaryEntries("nonce")
+
+	store := openStore(t)
+	sequence, err := store.LedgerSequence()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), sequence)
+
+	require.NoError(t, store.Initialize(&sliceChangeReader{entries: []xdr.LedgerEntry{bob, data, alice, ttl}}, checkpointLedger))
+	sequence, err = store.LedgerSequence()
+	require.NoError(t, err)
+	assert.Equal(t, checkpointLedger, sequence)
+
+	entry, found, err := store.Get(ledgerKey(t, alice))
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, alice, entry)
+
+	_, found, err = store.Get(ledgerKey(t, accountEntry("GCCOBXW2XQNUSL467IEILE6MMCNRR66SSVL4YQADUNYYNUVREF3FIV2Z", 0)))
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	assert.Equal(t, []xdr.LedgerEntry{alice, bob}, iterate(t, store, xdr.LedgerEntryTypeAccount))
+	assert.Equal(t, []xdr.LedgerEntry{ttl}, iterate(t, store, xdr.LedgerEntryTypeTtl))
+	assert.Empty(t, iterate(t, store, xdr.LedgerEntryTypeOffer))
+
+	count, err := store.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	count, err = store.Count(xdr.LedgerEntryTypeAccount, xdr.LedgerEntryTypeOffer)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	err = store.Initialize(&sliceChangeReader{}, checkpointLedger)
+	assert.EqualError(t, err, "state store is already initialized at ledger 63")
+}
+
+func TestInitializeDiscardsInterruptedLoad(t *testing.T) {
+	alice := accountEntry("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", 100)
+	bob := accountEntry("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON", 200)
+
+	store := openStore(t)
+	require.NoError(t, store.load([]xdr.LedgerEntry{alice, bob}, 0))
+
+	err := store.Initialize(&sliceChangeReader{entries: []xdr.LedgerEntry{bob, bob}}, checkpointLedger)
+	assert.ErrorContains(t, err, "appears twice in the checkpoint")
+	assert.IsType(t, ingest.StateError{}, err)
+
+	require.NoError(t, store.Initialize(&sliceChangeReader{entries: []xdr.LedgerEntry{bob}}, checkpointLedger))
+	assert.Equal(t, []xdr.LedgerEntry{bob}, iterate(t, store, xdr.LedgerEntryTypeAccount))
+	count, err := store.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestApplyChanges(t *testing.T) {
+	alice := accountEntry("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", 100)
+	bob := accountEntry("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON", 200)
+	carol := accountEntry("GCCOBXW2XQNUSL467IEILE6MMCNRR66SSVL4YQADUNYYNUVREF3FIV2Z", 300)
+	store := initializedStore(t, alice, bob)
+
+	updatedAlice := accountEntry("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", 150)
+	changes := []ingest.Change{
+		{Type: xdr.LedgerEntryTypeAccount, Pre: &alice, Post: &updatedAlice},
+		{Type: xdr.LedgerEntryTypeAccount, Pre: &bob},
+		{Type: xdr.LedgerEntryTypeAccount, Post: &carol},
+	}
+	err := store.ApplyChanges(checkpointLedger+2, changes)
+	assert.EqualError(t, err, "expected changes of ledger 64, got ledger 65")
+
+	require.NoError(t, store.ApplyChanges(checkpointLedger+1, changes))
+	sequence, err := store.LedgerSequence()
+	require.NoError(t, err)
+	assert.Equal(t, checkpointLedger+1, sequence)
+	assert.Equal(t, []xdr.LedgerEntry{updatedAlice, carol}, iterate(t, store, xdr.LedgerEntryTypeAccount))
+
+	// changes of a ledger are compacted, creating and removing an entry is a
+	// noop
+	require.NoError(t, store.ApplyChanges(checkpointLedger+2, []ingest.Change{
+		{Type: xdr.LedgerEntryTypeAccount, Post: &bob},
+		{Type: xdr.LedgerEntryTypeAccount, Pre: &bob},
+	}))
+	count, err := store.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	for _, change := range []ingest.Change{
+		{Type: xdr.LedgerEntryTypeAccount, Post: &carol},
+		{Type: xdr.LedgerEntryTypeAccount, Pre: &bob, Post: &bob},
+		{Type: xdr.LedgerEntryTypeAccount, Pre: &bob},
+	} {
+		err = store.ApplyChanges(checkpointLedger+3, []ingest.Change{
+			{Type: xdr.LedgerEntryTypeAccount, Pre: &alice, Post: &alice},
+			change,
+		})
+		require.Error(t, err)
+		assert.IsType(t, ingest.StateError{}, err)
+	}
+
+	// failed changes are not applied
+	sequence, err = store.LedgerSequence()
+	require.NoError(t, err)
+	assert.Equal(t, checkpointLedger+2, sequence)
+	assert.Equal(t, []xdr.LedgerEntry{updatedAlice, carol}, iterate(t, store, xdr.LedgerEntryTypeAccount))
+}
+
+func TestApplyChangesNotInitialized(t *testing.T) {
+	store := openStore(t)
+	err := store.ApplyChanges(checkpointLedger+1, nil)
+	assert.EqualError(t, err, "state store is not initialized")
+}
+
+func TestApplyLedgerCloseMeta(t *testing.T) {
+	alice := accountEntry("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", 100)
+	data, ttl := temporaryEntries("nonce")
+	otherData, otherTTL := temporaryEntries("counter")
+	store := initializedStore(t, alice, data, ttl, otherData, otherTTL)
+
+	updatedAlice := accountEntry("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", 150)
+	baseFee := xdr.Int64(100)
+	lcm := xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(checkpointLedger + 1), LedgerVersion: 21},
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V: 1,
+				V1TxSet: &xdr.TransactionSetV1{
+					Phases: []xdr.TransactionPhase{{
+						V0Components: &[]xdr.TxSetComponent{{
+							Type: xdr.TxSetComponentTypeTxsetCompTxsMaybeDiscountedFee,
+							TxsMaybeDiscountedFee: &xdr.TxSetComponentTxsMaybeDiscountedFee{
+								BaseFee: &baseFee,
+							},
+						}},
+					}},
+				},
+			},
+			UpgradesProcessing: []xdr.UpgradeEntryMeta{{
+				Changes: xdr.LedgerEntryChanges{
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &alice},
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &updatedAlice},
+				},
+			}},
+			EvictedTemporaryLedgerKeys: []xdr.LedgerKey{ledgerKey(t, data), ledgerKey(t, ttl)},
+		},
+	}
+	require.NoError(t, store.ApplyLedgerCloseMeta(network.TestNetworkPassphrase, lcm))
+
+	sequence, err := store.LedgerSequence()
+	require.NoError(t, err)
+	assert.Equal(t, checkpointLedger+1, sequence)
+	assert.Equal(t, []xdr.LedgerEntry{updatedAlice}, iterate(t, store, xdr.LedgerEntryTypeAccount))
+	assert.Equal(t, []xdr.LedgerEntry{otherData}, iterate(t, store, xdr.LedgerEntryTypeContractData))
+	assert.Equal(t, []xdr.LedgerEntry{otherTTL}, iterate(t, store, xdr.LedgerEntryTypeTtl))
+	count, err := store.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+// checkpointReader returns a reader of an archive with a single bucket.
+func checkpointReader(t *testing.T, hash xdr.Hash, bucket []byte) ingest.ChangeReader {
+	var has historyarchive.HistoryArchiveState
+	has.CurrentLedger = checkpointLedger
+	zero := historyarchive.Hash{}.String()
+	for i := range has.CurrentBuckets {
+		has.CurrentBuckets[i].Curr = zero
+		has.CurrentBuckets[i].Snap = zero
+	}
+	has.CurrentBuckets[0].Curr = historyarchive.Hash(hash).String()
+
+	archive := &historyarchive.MockArchive{}
+	archive.On("GetCheckpointManager").
+		Return(historyarchive.NewCheckpointManager(historyarchive.DefaultCheckpointFrequency))
+	archive.On("GetCheckpointHAS", checkpointLedger).Return(has, nil)
+	archive.On("BucketExists", historyarchive.Hash(hash)).Return(true, nil)
+	archive.On("BucketSize", historyarchive.Hash(hash)).Return(int64(len(bucket)), nil)
+	stream, err := xdr.NewGzStream(io.NopCloser(bytes.NewReader(bucket)))
+	require.NoError(t, err)
+	archive.On("GetXdrStreamForHash", historyarchive.Hash(hash)).Return(stream, nil).Once()
+
+	reader, err := ingest.NewCheckpointChangeReader(context.Background(), archive, checkpointLedger)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		reader.Close()
+	})
+	return reader
+}
+
+func TestWriteBucketAndVerify(t *testing.T) {
+	alice := accountEntry("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", 100)
+	bob := accountEntry("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON", 200)
+	data, ttl := temporaryEntries("nonce")
+	store := initializedStore(t, bob, ttl, alice, data)
+
+	var bucket bytes.Buffer
+	hash, err := store.WriteBucket(&bucket, 21)
+	require.NoError(t, err)
+
+	// the bucket is ordered by ledger key and its hash is the hash of the
+	// uncompressed stream
+	stream, err := xdr.NewGzStream(io.NopCloser(bytes.NewReader(bucket.Bytes())))
+	require.NoError(t, err)
+	stream.SetExpectedHash(hash)
+	var entries []xdr.BucketEntry
+	for {
+		var entry xdr.BucketEntry
+		err = stream.ReadOne(&entry)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	require.NoError(t, stream.Close())
+	assert.Equal(t, []xdr.BucketEntry{
+		{Type: xdr.BucketEntryTypeMetaentry, MetaEntry: &xdr.BucketMetadata{LedgerVersion: 21}},
+		{Type: xdr.BucketEntryTypeLiveentry, LiveEntry: &alice},
+		{Type: xdr.BucketEntryTypeLiveentry, LiveEntry: &bob},
+		{Type: xdr.BucketEntryTypeLiveentry, LiveEntry: &data},
+		{Type: xdr.BucketEntryTypeLiveentry, LiveEntry: &ttl},
+	}, entries)
+
+	require.NoError(t, store.Verify(checkpointLedger, checkpointReader(t, hash, bucket.Bytes())))
+
+	// a store initialized from the bucket has the same state
+	copied := openStore(t)
+	require.NoError(t, copied.Initialize(checkpointReader(t, hash, bucket.Bytes()), checkpointLedger))
+	require.NoError(t, copied.Verify(checkpointLedger, checkpointReader(t, hash, bucket.Bytes())))
+
+	err = store.Verify(checkpointLedger+1, checkpointReader(t, hash, bucket.Bytes()))
+	assert.EqualError(t, err, "state store is at ledger 63, not 64")
+}
+
+func dataEntry(address, name string) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 10,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeData,
+			Data: &xdr.DataEntry{
+				AccountId: xdr.MustAddress(address),
+				DataName:  xdr.String64(name),
+				DataValue: xdr.DataValue("value"),
+			},
+		},
+	}
+}
+
+func TestWriteBucketOrdersVariableLengthKeys(t *testing.T) {
+	const address = "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	alice := accountEntry(address, 100)
+	// XDR encoded, "b" is before "aa" because its length is smaller
+	short := dataEntry(address, "b")
+	long := dataEntry(address, "aa")
+	prefix := dataEntry(address, "a")
+	store := initializedStore(t, short, alice, long, prefix)
+
+	var bucket bytes.Buffer
+	hash, err := store.WriteBucket(&bucket, 21)
+	require.NoError(t, err)
+
+	// stellar-core compares data names byte by byte
+	reader := checkpointReader(t, hash, bucket.Bytes())
+	var entries []xdr.LedgerEntry
+	for {
+		change, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		entries = append(entries, *change.Post)
+	}
+	assert.Equal(t, []xdr.LedgerEntry{alice, prefix, long, short}, entries)
+}
+
+func TestVerifyInvalidState(t *testing.T) {
+	alice := accountEntry("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", 100)
+	bob := accountEntry("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON", 200)
+	carol := accountEntry("GCCOBXW2XQNUSL467IEILE6MMCNRR66SSVL4YQADUNYYNUVREF3FIV2Z", 300)
+	updatedBob := accountEntry("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON", 250)
+
+	for _, testCase := range []struct {
+		name       string
+		checkpoint []xdr.LedgerEntry
+		err        string
+	}{
+		{"different entry", []xdr.LedgerEntry{alice, updatedBob}, "Entry does not match the fetched entry"},
+		{"missing entry", []xdr.LedgerEntry{alice, bob, carol}, "Entries (1) not found locally"},
+		{"extra entry", []xdr.LedgerEntry{alice}, "Number of entries read using GetEntries (1) does not match number of entries in your storage (2)."},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := initializedStore(t, alice, bob)
+			err := store.Verify(checkpointLedger, &sliceChangeReader{entries: testCase.checkpoint})
+			require.Error(t, err)
+			assert.IsType(t, ingest.StateError{}, err)
+			assert.ErrorContains(t, err, testCase.err)
+		})
+	}
+}
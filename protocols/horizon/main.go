@@ -229,6 +229,124 @@ type Balance struct {
 	base.Asset
 }
 
+// AccountBalanceHistory represents the balance of an asset held by an account
+// at the end of a ledger in which the balance changed.
+type AccountBalanceHistory struct {
+	Links struct {
+		Ledger hal.Link `json:"ledger"`
+	} `json:"_links"`
+
+	PT              string    `json:"paging_token"`
+	Ledger          int32     `json:"ledger"`
+	LedgerCloseTime time.Time `json:"ledger_close_time"`
+	Balance         string    `json:"balance"`
+	base.Asset
+}
+
+// PagingToken implementation for hal.Pageable
+func (res AccountBalanceHistory) PagingToken() string {
+	return res.PT
+}
+
+// AccountExportRecord is a row of the export of the history of an account.
+// Details holds the transaction, operation, effect or trade as it is
+// returned by the endpoint of its kind.
+type AccountExportRecord struct {
+	RecordType      string          `json:"record_type"`
+	ID              string          `json:"id"`
+	PT              string          `json:"paging_token"`
+	Ledger          int32           `json:"ledger"`
+	CreatedAt       time.Time       `json:"created_at"`
+	TransactionHash string          `json:"transaction_hash,omitempty"`
+	Successful      bool            `json:"successful"`
+	Type            string          `json:"type,omitempty"`
+	Details         json.RawMessage `json:"details"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res AccountExportRecord) PagingToken() string {
+	return res.PT
+}
+
+// AccountBalanceHistoryPage returns a list of account balance history records
+type AccountBalanceHistoryPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []AccountBalanceHistory `json:"records"`
+	} `json:"_embedded"`
+}
+
+// ContractEvent represents an event emitted by a successful Soroban
+// transaction.
+type ContractEvent struct {
+	Links struct {
+		Transaction hal.Link `json:"transaction"`
+		Operation   hal.Link `json:"operation"`
+	} `json:"_links"`
+
+	ID              string    `json:"id"`
+	PT              string    `json:"paging_token"`
+	Type            string    `json:"type"`
+	Ledger          int32     `json:"ledger"`
+	LedgerCloseTime time.Time `json:"ledger_close_time"`
+	TransactionHash string    `json:"transaction_hash"`
+	ContractID      string    `json:"contract_id,omitempty"`
+	// Topics and Value are base64 encoded xdr.ScVal values.
+	Topics []string `json:"topics"`
+	Value  string   `json:"value"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res ContractEvent) PagingToken() string {
+	return res.PT
+}
+
+// ContractEventsPage returns a list of contract event records
+type ContractEventsPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []ContractEvent `json:"records"`
+	} `json:"_embedded"`
+}
+
+// Contract represents a Soroban contract. Horizon only knows about contracts
+// which are Stellar Asset Contracts or which hold Stellar Asset Contract
+// balances.
+type Contract struct {
+	Links struct {
+		Self     hal.Link `json:"self"`
+		Balances hal.Link `json:"balances"`
+	} `json:"_links"`
+
+	ID string `json:"id"`
+	// Asset is set when the contract is the Stellar Asset Contract of an
+	// asset.
+	Asset *base.Asset `json:"asset,omitempty"`
+}
+
+// ContractBalance represents a Stellar Asset Contract balance held by a
+// contract.
+type ContractBalance struct {
+	PT              string `json:"paging_token"`
+	AssetContractID string `json:"asset_contract_id"`
+	Balance         string `json:"balance"`
+	LiveUntilLedger uint32 `json:"live_until_ledger"`
+	base.Asset
+}
+
+// PagingToken implementation for hal.Pageable
+func (res ContractBalance) PagingToken() string {
+	return res.PT
+}
+
+// ContractBalancesPage returns a list of contract balance records
+type ContractBalancesPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []ContractBalance `json:"records"`
+	} `json:"_embedded"`
+}
+
 // Ledger represents a single closed ledger
 type Ledger struct {
 	Links struct {
@@ -304,6 +422,12 @@ type Path struct {
 	DestinationAssetIssuer string  `json:"destination_asset_issuer,omitempty"`
 	DestinationAmount      string  `json:"destination_amount"`
 	Path                   []Asset `json:"path"`
+	// Price and Routes are only present on split payments. Price is the
+	// aggregate amount of the destination asset received per unit of the
+	// source asset and Routes are the payment paths across which the
+	// payment is divided.
+	Price  string `json:"price,omitempty"`
+	Routes []Path `json:"routes,omitempty"`
 }
 
 // stub implementation to satisfy pageable interface
@@ -664,6 +788,19 @@ type TransactionResultCodes struct {
 	OperationCodes       []string `json:"operations,omitempty"`
 }
 
+// TransactionSimulation is the predicted result of submitting a transaction,
+// based on the ledger state ingested by Horizon.
+type TransactionSimulation struct {
+	Hash      string `json:"hash"`
+	InnerHash string `json:"inner_hash,omitempty"`
+	// Ledger is the last ingested ledger the transaction was simulated
+	// against.
+	Ledger      int32                  `json:"ledger"`
+	Successful  bool                   `json:"successful"`
+	FeeCharged  int64                  `json:"fee_charged,string"`
+	ResultCodes TransactionResultCodes `json:"result_codes"`
+}
+
 // KeyTypeFromAddress converts the version byte of the provided strkey encoded
 // value (for example an account id or a signer key) and returns the appropriate
 // horizon-specific type name.
@@ -923,3 +1060,86 @@ func (f *AssetFilterConfig) UnmarshalJSON(data []byte) error {
 	*f = AssetFilterConfig(config)
 	return nil
 }
+
+// WebhookSubscription is a subscription to the operations and effects
+// ingested by Horizon. Empty filters match everything. Secret is only
+// included in the response creating the subscription.
+type WebhookSubscription struct {
+	ID             int64      `json:"id,string"`
+	URL            string     `json:"url"`
+	Secret         string     `json:"secret,omitempty"`
+	Accounts       []string   `json:"accounts"`
+	Assets         []string   `json:"assets"`
+	OperationTypes []string   `json:"operation_types"`
+	EffectTypes    []string   `json:"effect_types"`
+	Cursor         uint32     `json:"cursor"`
+	FailedAttempts int32      `json:"failed_attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// APIKey is an API key of the clients of Horizon. A zero limit means the key
+// is not limited in that dimension. Key is only included in the response
+// creating the key.
+type APIKey struct {
+	ID                   int64     `json:"id,string"`
+	Name                 string    `json:"name"`
+	Key                  string    `json:"key,omitempty"`
+	RequestsPerHour      int32     `json:"requests_per_hour"`
+	MaxStreams           int32     `json:"max_streams"`
+	PathFindingPerSecond int32     `json:"path_finding_per_second"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// WebhookDeadLetter is a webhook payload which could not be delivered.
+type WebhookDeadLetter struct {
+	ID             int64           `json:"id,string"`
+	PT             string          `json:"paging_token"`
+	SubscriptionID int64           `json:"subscription_id,string"`
+	Ledger         uint32          `json:"ledger"`
+	Attempts       int32           `json:"attempts"`
+	LastError      string          `json:"last_error"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (d WebhookDeadLetter) PagingToken() string {
+	return d.PT
+}
+
+// WebhookPayload is the body POSTed to a webhook subscription for every
+// ingested ledger containing matching operations or effects. Operations and
+// Effects have the same format as the /operations and /effects endpoints.
+type WebhookPayload struct {
+	SubscriptionID int64             `json:"subscription_id,string"`
+	Ledger         uint32            `json:"ledger"`
+	ClosedAt       time.Time         `json:"closed_at"`
+	Operations     []json.RawMessage `json:"operations"`
+	Effects        []json.RawMessage `json:"effects"`
+}
+
+// StreamRequest is a message sent by clients over the /ws WebSocket endpoint
+// to manage their stream subscriptions. Type is either "subscribe" or
+// "unsubscribe", ID is chosen by the client and identifies the subscription
+// and Path is the streaming endpoint to subscribe to, including its query
+// (e.g. "/accounts/G.../payments?cursor=now").
+type StreamRequest struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Path string `json:"path,omitempty"`
+}
+
+// StreamMessage is a message sent by Horizon over the /ws WebSocket endpoint.
+// Type is one of "subscribed", "unsubscribed", "event" or "error". Events
+// carry the resource in Data and, for paged endpoints, its paging token in
+// EventID. Errors carry a problem document in Error and end the subscription.
+type StreamMessage struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	EventID string          `json:"event_id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
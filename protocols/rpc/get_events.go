@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+const GetEventsMethodName = "getEvents"
+
+const (
+	EventTypeSystem     = "system"
+	EventTypeContract   = "contract"
+	EventTypeDiagnostic = "diagnostic"
+)
+
+const (
+	// WildCardExactOne matches exactly one topic segment.
+	WildCardExactOne = "*"
+	// WildCardZeroOrMore matches zero or more trailing topic segments.
+	WildCardZeroOrMore = "**"
+)
+
+// EventTypeSet is the set of event types matched by an EventFilter. It is
+// encoded as a comma separated list.
+type EventTypeSet map[string]interface{}
+
+// MarshalJSON encodes the set as a comma separated list of types.
+func (e EventTypeSet) MarshalJSON() ([]byte, error) {
+	keys := make([]string, 0, len(e))
+	for _, key := range []string{EventTypeSystem, EventTypeContract, EventTypeDiagnostic} {
+		if _, ok := e[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return json.Marshal(strings.Join(keys, ","))
+}
+
+// UnmarshalJSON decodes a comma separated list of types.
+func (e *EventTypeSet) UnmarshalJSON(data []byte) error {
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return err
+	}
+	*e = EventTypeSet{}
+	if joined == "" {
+		return nil
+	}
+	for _, key := range strings.Split(joined, ",") {
+		switch key {
+		case EventTypeSystem, EventTypeContract, EventTypeDiagnostic:
+			(*e)[key] = nil
+		default:
+			return errors.Errorf("unsupported event type %q", key)
+		}
+	}
+	return nil
+}
+
+// SegmentFilter matches a single topic segment, either with a wildcard or
+// with an exact xdr.ScVal.
+type SegmentFilter struct {
+	Wildcard *string
+	ScVal    *xdr.ScVal
+}
+
+// MarshalJSON encodes the segment as the wildcard or as the base64 encoded ScVal.
+func (s SegmentFilter) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.Wildcard != nil && s.ScVal != nil:
+		return nil, errors.New("segment filter cannot have both a wildcard and a value")
+	case s.Wildcard != nil:
+		return json.Marshal(*s.Wildcard)
+	case s.ScVal != nil:
+		encoded, err := xdr.MarshalBase64(*s.ScVal)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(encoded)
+	default:
+		return nil, errors.New("segment filter must have a wildcard or a value")
+	}
+}
+
+// UnmarshalJSON decodes a wildcard or a base64 encoded ScVal.
+func (s *SegmentFilter) UnmarshalJSON(data []byte) error {
+	var segment string
+	if err := json.Unmarshal(data, &segment); err != nil {
+		return err
+	}
+	*s = SegmentFilter{}
+	if segment == WildCardExactOne || segment == WildCardZeroOrMore {
+		s.Wildcard = &segment
+		return nil
+	}
+	var scVal xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(segment, &scVal); err != nil {
+		return errors.Wrapf(err, "invalid topic segment %q", segment)
+	}
+	s.ScVal = &scVal
+	return nil
+}
+
+// TopicFilter matches the topics of an event segment by segment.
+type TopicFilter []SegmentFilter
+
+// EventFilter selects the events returned by getEvents. An event matches the
+// filter if it matches all of its non-empty fields.
+type EventFilter struct {
+	EventType   EventTypeSet  `json:"type,omitempty"`
+	ContractIDs []string      `json:"contractIds,omitempty"`
+	Topics      []TopicFilter `json:"topics,omitempty"`
+}
+
+// GetEventsRequest is the request of the getEvents method. Either StartLedger
+// or a Pagination.Cursor must be set.
+type GetEventsRequest struct {
+	StartLedger uint32             `json:"startLedger,omitempty"`
+	EndLedger   uint32             `json:"endLedger,omitempty"`
+	Filters     []EventFilter      `json:"filters"`
+	Pagination  *PaginationOptions `json:"pagination,omitempty"`
+	Format      string             `json:"xdrFormat,omitempty"`
+}
+
+// EventInfo is a single event returned by getEvents.
+type EventInfo struct {
+	EventType                string `json:"type"`
+	Ledger                   uint32 `json:"ledger"`
+	LedgerClosedAt           string `json:"ledgerClosedAt"`
+	ContractID               string `json:"contractId"`
+	ID                       string `json:"id"`
+	InSuccessfulContractCall bool   `json:"inSuccessfulContractCall"`
+	TransactionHash          string `json:"txHash"`
+	// TopicXDR are the base64 encoded xdr.ScVal topics of the event.
+	TopicXDR []string `json:"topic,omitempty"`
+	// ValueXDR is the base64 encoded xdr.ScVal value of the event.
+	ValueXDR string `json:"value,omitempty"`
+}
+
+// GetEventsResponse is the response of the getEvents method.
+type GetEventsResponse struct {
+	Events       []EventInfo `json:"events"`
+	LatestLedger uint32      `json:"latestLedger"`
+	// Cursor can be used in the Pagination of a subsequent request to
+	// continue after the last returned event.
+	Cursor string `json:"cursor"`
+}
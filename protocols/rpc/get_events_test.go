@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/xdr"
+)
+
+func TestEventFilterJSON(t *testing.T) {
+	sym := xdr.ScSymbol("transfer")
+	topic := xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}
+	encodedTopic, err := xdr.MarshalBase64(topic)
+	require.NoError(t, err)
+	exactOne, zeroOrMore := WildCardExactOne, WildCardZeroOrMore
+
+	filter := EventFilter{
+		EventType:   EventTypeSet{EventTypeDiagnostic: nil, EventTypeContract: nil},
+		ContractIDs: []string{"CCJZ5DGASBWQXR5MPFCJXMBI333XE5U3FSJTNQU7RIKE3P5GN2K2WYD5"},
+		Topics: []TopicFilter{
+			{{ScVal: &topic}, {Wildcard: &exactOne}, {Wildcard: &zeroOrMore}},
+		},
+	}
+	encoded, err := json.Marshal(filter)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "contract,diagnostic",
+		"contractIds": ["CCJZ5DGASBWQXR5MPFCJXMBI333XE5U3FSJTNQU7RIKE3P5GN2K2WYD5"],
+		"topics": [["`+encodedTopic+`", "*", "**"]]
+	}`, string(encoded))
+
+	var decoded EventFilter
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, filter, decoded)
+}
+
+func TestEventFilterJSONErrors(t *testing.T) {
+	_, err := json.Marshal(SegmentFilter{})
+	assert.ErrorContains(t, err, "segment filter must have a wildcard or a value")
+
+	var eventTypes EventTypeSet
+	assert.EqualError(t, json.Unmarshal([]byte(`"contract,unknown"`), &eventTypes), `unsupported event type "unknown"`)
+
+	var segment SegmentFilter
+	assert.ErrorContains(t, json.Unmarshal([]byte(`"not xdr"`), &segment), `invalid topic segment "not xdr"`)
+}
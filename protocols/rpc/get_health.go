@@ -0,0 +1,34 @@
+// Package rpc contains the request and response types of the Stellar RPC
+// JSON-RPC API.
+package rpc
+
+const (
+	GetHealthMethodName       = "getHealth"
+	GetNetworkMethodName      = "getNetwork"
+	GetLatestLedgerMethodName = "getLatestLedger"
+)
+
+// GetHealthResponse is the response of the getHealth method.
+type GetHealthResponse struct {
+	Status                string `json:"status"`
+	LatestLedger          uint32 `json:"latestLedger"`
+	OldestLedger          uint32 `json:"oldestLedger"`
+	LedgerRetentionWindow uint32 `json:"ledgerRetentionWindow"`
+}
+
+// GetNetworkResponse is the response of the getNetwork method.
+type GetNetworkResponse struct {
+	FriendbotURL    string `json:"friendbotUrl,omitempty"`
+	Passphrase      string `json:"passphrase"`
+	ProtocolVersion int    `json:"protocolVersion"`
+}
+
+// GetLatestLedgerResponse is the response of the getLatestLedger method.
+type GetLatestLedgerResponse struct {
+	// Hash of the latest ledger as a hex-encoded string
+	Hash string `json:"id"`
+	// Stellar Core protocol version associated with the ledger.
+	ProtocolVersion uint32 `json:"protocolVersion"`
+	// Sequence number of the latest ledger.
+	Sequence uint32 `json:"sequence"`
+}
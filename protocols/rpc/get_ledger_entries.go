@@ -0,0 +1,31 @@
+package rpc
+
+const GetLedgerEntriesMethodName = "getLedgerEntries"
+
+// GetLedgerEntriesRequest is the request of the getLedgerEntries method. Keys
+// are base64 encoded xdr.LedgerKey values.
+type GetLedgerEntriesRequest struct {
+	Keys   []string `json:"keys"`
+	Format string   `json:"xdrFormat,omitempty"`
+}
+
+// LedgerEntryResult is a single ledger entry returned by getLedgerEntries.
+type LedgerEntryResult struct {
+	// Original request key matching this LedgerEntryResult.
+	KeyXDR string `json:"key,omitempty"`
+	// Ledger entry data encoded in base64.
+	DataXDR string `json:"xdr,omitempty"`
+	// Last modified ledger for this entry.
+	LastModifiedLedger uint32 `json:"lastModifiedLedgerSeq"`
+	// The ledger sequence until the entry is live, available for entries that
+	// have associated ttl ledger entries.
+	LiveUntilLedgerSeq *uint32 `json:"liveUntilLedgerSeq,omitempty"`
+}
+
+// GetLedgerEntriesResponse is the response of the getLedgerEntries method.
+type GetLedgerEntriesResponse struct {
+	// All found ledger entries.
+	Entries []LedgerEntryResult `json:"entries"`
+	// Sequence number of the latest ledger at time of request.
+	LatestLedger uint32 `json:"latestLedger"`
+}
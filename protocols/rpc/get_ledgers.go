@@ -0,0 +1,31 @@
+package rpc
+
+const GetLedgersMethodName = "getLedgers"
+
+// GetLedgersRequest is the request of the getLedgers method.
+type GetLedgersRequest struct {
+	StartLedger uint32             `json:"startLedger,omitempty"`
+	Pagination  *PaginationOptions `json:"pagination,omitempty"`
+	Format      string             `json:"xdrFormat,omitempty"`
+}
+
+// LedgerInfo is a single ledger returned by getLedgers.
+type LedgerInfo struct {
+	Hash            string `json:"hash"`
+	Sequence        uint32 `json:"sequence"`
+	LedgerCloseTime int64  `json:"ledgerCloseTime,string"`
+	// Base64 encoded xdr.LedgerHeaderHistoryEntry
+	LedgerHeader string `json:"headerXdr"`
+	// Base64 encoded xdr.LedgerCloseMeta
+	LedgerMetadata string `json:"metadataXdr"`
+}
+
+// GetLedgersResponse is the response of the getLedgers method.
+type GetLedgersResponse struct {
+	Ledgers               []LedgerInfo `json:"ledgers"`
+	LatestLedger          uint32       `json:"latestLedger"`
+	LatestLedgerCloseTime int64        `json:"latestLedgerCloseTime"`
+	OldestLedger          uint32       `json:"oldestLedger"`
+	OldestLedgerCloseTime int64        `json:"oldestLedgerCloseTime"`
+	Cursor                string       `json:"cursor"`
+}
@@ -0,0 +1,94 @@
+package rpc
+
+const (
+	GetTransactionMethodName  = "getTransaction"
+	GetTransactionsMethodName = "getTransactions"
+)
+
+const (
+	// TransactionStatusSuccess indicates the transaction was included in the
+	// ledger and it was successful.
+	TransactionStatusSuccess = "SUCCESS"
+	// TransactionStatusNotFound indicates the transaction was not found in
+	// the ledger range stored by the RPC server.
+	TransactionStatusNotFound = "NOT_FOUND"
+	// TransactionStatusFailed indicates the transaction was included in the
+	// ledger and it failed.
+	TransactionStatusFailed = "FAILED"
+)
+
+// GetTransactionRequest is the request of the getTransaction method.
+type GetTransactionRequest struct {
+	Hash   string `json:"hash"`
+	Format string `json:"xdrFormat,omitempty"`
+}
+
+// TransactionDetails contains the fields shared by the getTransaction and
+// getTransactions responses.
+type TransactionDetails struct {
+	// Status is one of: TransactionStatusSuccess, TransactionStatusFailed or
+	// TransactionStatusNotFound.
+	Status string `json:"status"`
+	// TransactionHash is the hex encoded hash of the transaction.
+	TransactionHash string `json:"txHash"`
+	// ApplicationOrder is the index of the transaction among all the
+	// transactions for that ledger.
+	ApplicationOrder int32 `json:"applicationOrder"`
+	// FeeBump indicates whether the transaction is a feebump transaction
+	FeeBump bool `json:"feeBump"`
+	// EnvelopeXDR is the base64 encoded xdr.TransactionEnvelope.
+	EnvelopeXDR string `json:"envelopeXdr,omitempty"`
+	// ResultXDR is the base64 encoded xdr.TransactionResult.
+	ResultXDR string `json:"resultXdr,omitempty"`
+	// ResultMetaXDR is the base64 encoded xdr.TransactionMeta.
+	ResultMetaXDR string `json:"resultMetaXdr,omitempty"`
+	// DiagnosticEventsXDR are the base64 encoded xdr.DiagnosticEvent values
+	// emitted by the transaction.
+	DiagnosticEventsXDR []string `json:"diagnosticEventsXdr,omitempty"`
+	// Ledger is the sequence of the ledger which included the transaction.
+	Ledger uint32 `json:"ledger"`
+}
+
+// GetTransactionResponse is the response of the getTransaction method.
+type GetTransactionResponse struct {
+	// LatestLedger is the latest ledger stored in the RPC server.
+	LatestLedger uint32 `json:"latestLedger"`
+	// LatestLedgerCloseTime is the unix timestamp of when the latest ledger was closed.
+	LatestLedgerCloseTime int64 `json:"latestLedgerCloseTime,string"`
+	// OldestLedger is the oldest ledger stored in the RPC server.
+	OldestLedger uint32 `json:"oldestLedger"`
+	// OldestLedgerCloseTime is the unix timestamp of when the oldest ledger was closed.
+	OldestLedgerCloseTime int64 `json:"oldestLedgerCloseTime,string"`
+
+	// The fields below are only present if Status is not
+	// TransactionStatusNotFound.
+	TransactionDetails
+	// LedgerCloseTime is the unix timestamp of when the transaction was
+	// included in the ledger.
+	LedgerCloseTime int64 `json:"createdAt,string"`
+}
+
+// GetTransactionsRequest is the request of the getTransactions method.
+type GetTransactionsRequest struct {
+	StartLedger uint32             `json:"startLedger"`
+	Pagination  *PaginationOptions `json:"pagination,omitempty"`
+	Format      string             `json:"xdrFormat,omitempty"`
+}
+
+// TransactionInfo is a single transaction returned by getTransactions.
+type TransactionInfo struct {
+	TransactionDetails
+	// LedgerCloseTime is the unix timestamp of when the transaction was
+	// included in the ledger.
+	LedgerCloseTime int64 `json:"createdAt"`
+}
+
+// GetTransactionsResponse is the response of the getTransactions method.
+type GetTransactionsResponse struct {
+	Transactions          []TransactionInfo `json:"transactions"`
+	LatestLedger          uint32            `json:"latestLedger"`
+	LatestLedgerCloseTime int64             `json:"latestLedgerCloseTimestamp"`
+	OldestLedger          uint32            `json:"oldestLedger"`
+	OldestLedgerCloseTime int64             `json:"oldestLedgerCloseTimestamp"`
+	Cursor                string            `json:"cursor"`
+}
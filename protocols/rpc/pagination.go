@@ -0,0 +1,16 @@
+package rpc
+
+const (
+	// FormatBase64 requests XDR values encoded as base64 strings, which is
+	// the default format of the RPC server.
+	FormatBase64 = "base64"
+	// FormatJSON requests XDR values encoded as JSON objects.
+	FormatJSON = "json"
+)
+
+// PaginationOptions controls paging of the methods returning lists of items.
+// Cursor is mutually exclusive with the start ledger of the request.
+type PaginationOptions struct {
+	Cursor string `json:"cursor,omitempty"`
+	Limit  uint   `json:"limit,omitempty"`
+}
@@ -0,0 +1,47 @@
+package rpc
+
+const SendTransactionMethodName = "sendTransaction"
+
+const (
+	// SendTransactionStatusPending indicates the transaction was accepted and
+	// is waiting to be included in a ledger.
+	SendTransactionStatusPending = "PENDING"
+	// SendTransactionStatusDuplicate indicates the transaction was already
+	// submitted.
+	SendTransactionStatusDuplicate = "DUPLICATE"
+	// SendTransactionStatusTryAgainLater indicates the transaction was not
+	// accepted because of rate limiting and should be resubmitted later.
+	SendTransactionStatusTryAgainLater = "TRY_AGAIN_LATER"
+	// SendTransactionStatusError indicates the transaction was rejected. The
+	// reason is included in ErrorResultXDR.
+	SendTransactionStatusError = "ERROR"
+)
+
+// SendTransactionRequest is the request of the sendTransaction method.
+type SendTransactionRequest struct {
+	// Transaction is the base64 encoded xdr.TransactionEnvelope.
+	Transaction string `json:"transaction"`
+	Format      string `json:"xdrFormat,omitempty"`
+}
+
+// SendTransactionResponse is the response of the sendTransaction method.
+type SendTransactionResponse struct {
+	// ErrorResultXDR is present only if Status is
+	// SendTransactionStatusError. It is the base64 encoded
+	// xdr.TransactionResult.
+	ErrorResultXDR string `json:"errorResultXdr,omitempty"`
+	// DiagnosticEventsXDR is present only if Status is
+	// SendTransactionStatusError. They are the base64 encoded
+	// xdr.DiagnosticEvent values.
+	DiagnosticEventsXDR []string `json:"diagnosticEventsXdr,omitempty"`
+	// Status represents the status of the transaction submission.
+	Status string `json:"status"`
+	// Hash is a hex encoded hash of the transaction.
+	Hash string `json:"hash"`
+	// LatestLedger is the latest ledger known to the RPC server at the
+	// time it handled the request.
+	LatestLedger uint32 `json:"latestLedger"`
+	// LatestLedgerCloseTime is the unix timestamp of the close time of the
+	// latest ledger known to the RPC server.
+	LatestLedgerCloseTime int64 `json:"latestLedgerCloseTime,string"`
+}
@@ -0,0 +1,80 @@
+package rpc
+
+const SimulateTransactionMethodName = "simulateTransaction"
+
+// ResourceConfig configures the resources estimated by the simulation.
+type ResourceConfig struct {
+	// InstructionLeeway is added to the instructions estimated by the
+	// simulation.
+	InstructionLeeway uint64 `json:"instructionLeeway"`
+}
+
+// SimulateTransactionRequest is the request of the simulateTransaction method.
+type SimulateTransactionRequest struct {
+	// Transaction is the base64 encoded xdr.TransactionEnvelope.
+	Transaction    string          `json:"transaction"`
+	ResourceConfig *ResourceConfig `json:"resourceConfig,omitempty"`
+	Format         string          `json:"xdrFormat,omitempty"`
+}
+
+// SimulateHostFunctionResult is the result of simulating a host function
+// invocation.
+type SimulateHostFunctionResult struct {
+	// AuthXDR are the base64 encoded xdr.SorobanAuthorizationEntry values
+	// required by the invocation.
+	AuthXDR []string `json:"auth"`
+	// ReturnValueXDR is the base64 encoded xdr.ScVal returned by the
+	// invocation.
+	ReturnValueXDR string `json:"xdr"`
+}
+
+// RestorePreamble is returned by the simulation when archived ledger entries
+// need to be restored before the transaction can be submitted.
+type RestorePreamble struct {
+	// TransactionDataXDR is the base64 encoded xdr.SorobanTransactionData of
+	// the restore operation.
+	TransactionDataXDR string `json:"transactionData"`
+	MinResourceFee     int64  `json:"minResourceFee,string"`
+}
+
+const (
+	LedgerEntryChangeTypeCreated = "created"
+	LedgerEntryChangeTypeUpdated = "updated"
+	LedgerEntryChangeTypeDeleted = "deleted"
+)
+
+// LedgerEntryChange designates a change in a ledger entry. Before and After
+// cannot be omitted at the same time. If Before is omitted, it constitutes a
+// creation, if After is omitted, it constitutes a deletion.
+type LedgerEntryChange struct {
+	Type string `json:"type"`
+	// KeyXDR is the base64 encoded xdr.LedgerKey
+	KeyXDR string `json:"key"`
+	// BeforeXDR is the base64 encoded xdr.LedgerEntry
+	BeforeXDR *string `json:"before"`
+	// AfterXDR is the base64 encoded xdr.LedgerEntry
+	AfterXDR *string `json:"after"`
+}
+
+// SimulateTransactionResponse is the response of the simulateTransaction method.
+type SimulateTransactionResponse struct {
+	// Error is set if the simulation failed.
+	Error string `json:"error,omitempty"`
+	// TransactionDataXDR is the base64 encoded xdr.SorobanTransactionData.
+	TransactionDataXDR string `json:"transactionData,omitempty"`
+	// EventsXDR are the base64 encoded xdr.DiagnosticEvent values emitted
+	// during the simulation.
+	EventsXDR []string `json:"events,omitempty"`
+	// MinResourceFee is the recommended minimum resource fee.
+	MinResourceFee int64 `json:"minResourceFee,string,omitempty"`
+	// Results contains a single element when the transaction invokes a host
+	// function.
+	Results []SimulateHostFunctionResult `json:"results,omitempty"`
+	// RestorePreamble is present if a RestoreFootprint operation is needed
+	// before the transaction can be submitted.
+	RestorePreamble *RestorePreamble `json:"restorePreamble,omitempty"`
+	// StateChanges are the ledger entry changes resulting from the simulation.
+	StateChanges []LedgerEntryChange `json:"stateChanges,omitempty"`
+	// LatestLedger is the latest ledger known to the RPC server.
+	LatestLedger uint32 `json:"latestLedger"`
+}
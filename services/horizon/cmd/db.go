@@ -193,6 +193,8 @@ func runDBReingestRange(ledgerRanges []history.LedgerRange, reingestForce bool,
 		RoundingSlippageFilter:      config.RoundingSlippageFilter,
 		MaxLedgerPerFlush:           maxLedgersPerFlush,
 		SkipTxmeta:                  config.SkipTxmeta,
+		IngestContractEvents:        config.IngestContractEvents,
+		IngestBalanceHistory:        config.IngestBalanceHistory,
 		LedgerBackendType:           ledgerBackendType,
 		StorageBackendConfig:        storageBackendConfig,
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/hal"
 	"github.com/stellar/go/support/render/problem"
@@ -311,6 +312,20 @@ func (handler GetAccountByIDHandler) GetResource(
 		return nil, err
 	}
 
+	// Contract addresses are served as contracts so clients can look up any
+	// address through the same endpoint.
+	addr, err := getStringFromURLParam(r, "account_id")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = strkey.Decode(strkey.VersionByteContract, addr); err == nil {
+		contract, err := ContractInfo(r.Context(), historyQ, addr)
+		if err != nil {
+			return nil, err
+		}
+		return Contract(*contract), nil
+	}
+
 	qp := AccountByIDQuery{}
 	err = getParams(&qp, r)
 	if err != nil {
@@ -0,0 +1,129 @@
+package actions
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/support/time"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountBalanceHistoryQuery query struct for the account balance history
+// end-point
+type AccountBalanceHistoryQuery struct {
+	AccountID   string      `schema:"account_id" valid:"accountID,required"`
+	AssetFilter string      `schema:"asset" valid:"asset,optional"`
+	StartLedger uint32      `schema:"start_ledger" valid:"-"`
+	EndLedger   uint32      `schema:"end_ledger" valid:"-"`
+	StartTime   time.Millis `schema:"start_time" valid:"-"`
+	EndTime     time.Millis `schema:"end_time" valid:"-"`
+}
+
+// Validate runs extra validations on query parameters
+func (qp AccountBalanceHistoryQuery) Validate() error {
+	if qp.StartLedger > 0 && qp.EndLedger > 0 && qp.StartLedger > qp.EndLedger {
+		return problem.MakeInvalidFieldProblem(
+			"end_ledger",
+			errors.New("end_ledger must be greater than or equal to start_ledger"),
+		)
+	}
+	if !qp.StartTime.IsNil() && !qp.EndTime.IsNil() && qp.StartTime.ToInt64() >= qp.EndTime.ToInt64() {
+		return problem.MakeInvalidFieldProblem(
+			"end_time",
+			errors.New("end_time must be greater than start_time"),
+		)
+	}
+	return nil
+}
+
+// Asset returns the asset to filter the balance history by, or nil if the
+// history of all the assets held by the account was requested.
+func (qp AccountBalanceHistoryQuery) Asset() *xdr.Asset {
+	switch qp.AssetFilter {
+	case "":
+		return nil
+	case "native":
+		asset := xdr.MustNewNativeAsset()
+		return &asset
+	default:
+		parts := strings.Split(qp.AssetFilter, ":")
+		asset := xdr.MustNewCreditAsset(parts[0], parts[1])
+		return &asset
+	}
+}
+
+// HistoryQuery converts the query parameters into a
+// history.AccountBalanceHistoryQuery
+func (qp AccountBalanceHistoryQuery) HistoryQuery() history.AccountBalanceHistoryQuery {
+	query := history.AccountBalanceHistoryQuery{
+		Account:     qp.AccountID,
+		Asset:       qp.Asset(),
+		StartLedger: qp.StartLedger,
+		EndLedger:   qp.EndLedger,
+	}
+	if !qp.StartTime.IsNil() {
+		query.StartTime = qp.StartTime.ToTime()
+	}
+	if !qp.EndTime.IsNil() {
+		query.EndTime = qp.EndTime.ToTime()
+	}
+	return query
+}
+
+// GetAccountBalanceHistoryHandler is the action handler for the
+// /accounts/{account_id}/balances/history endpoint
+type GetAccountBalanceHistoryHandler struct {
+	LedgerState *ledger.State
+}
+
+// GetResourcePage returns a page of the balances of the account at the end
+// of each ledger in which they changed.
+func (handler GetAccountBalanceHistoryHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	pq, err := GetPageQuery(handler.LedgerState, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateAndAdjustCursor(handler.LedgerState, &pq)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := AccountBalanceHistoryQuery{}
+	err = getParams(&qp, r)
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.AccountBalanceHistory(
+		r.Context(),
+		qp.HistoryQuery(),
+		pq,
+		handler.LedgerState.CurrentStatus().HistoryElder,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading account balance history records")
+	}
+
+	var result []hal.Pageable
+	for _, record := range records {
+		var balance horizon.AccountBalanceHistory
+		resourceadapter.PopulateAccountBalanceHistory(r.Context(), &balance, record)
+		result = append(result, balance)
+	}
+
+	return result, nil
+}
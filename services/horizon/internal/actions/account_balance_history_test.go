@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+func TestAccountBalanceHistoryQueryValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		name          string
+		query         string
+		invalidField  string
+		invalidReason string
+	}{
+		{
+			"invalid asset",
+			"asset=USD",
+			"asset",
+			"Asset must be the string \"native\" or a string of the form \"Code:IssuerAccountID\" for issued assets.",
+		},
+		{
+			"invalid ledger range",
+			"start_ledger=10&end_ledger=9",
+			"end_ledger",
+			"end_ledger must be greater than or equal to start_ledger",
+		},
+		{
+			"invalid time range",
+			"start_time=2000&end_time=1000",
+			"end_time",
+			"end_time must be greater than start_time",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			called := false
+			s := httptest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				qp := AccountBalanceHistoryQuery{}
+				err := getParams(&qp, r)
+				assert.Error(t, err)
+				p, ok := err.(*problem.P)
+				if assert.True(t, ok) {
+					assert.Equal(t, 400, p.Status)
+					assert.Equal(t, testCase.invalidField, p.Extras["invalid_field"])
+					assert.Equal(t, testCase.invalidReason, p.Extras["reason"])
+				}
+				called = true
+			}))
+			defer s.Close()
+
+			_, err := http.Get(s.URL + "/?account_id=GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB&" + testCase.query)
+			assert.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestAccountBalanceHistoryQueryHistoryQuery(t *testing.T) {
+	qp := AccountBalanceHistoryQuery{
+		AccountID:   "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB",
+		AssetFilter: "USD:GANFZDRBCNTUXIODCJEYMACPMCSZEVE4WZGZ3CZDZ3P2SXK4KH75IK6Y",
+		StartLedger: 10,
+		EndLedger:   20,
+		StartTime:   1000,
+		EndTime:     2000,
+	}
+
+	usd := xdr.MustNewCreditAsset("USD", "GANFZDRBCNTUXIODCJEYMACPMCSZEVE4WZGZ3CZDZ3P2SXK4KH75IK6Y")
+	assert.Equal(t, history.AccountBalanceHistoryQuery{
+		Account:     qp.AccountID,
+		Asset:       &usd,
+		StartLedger: 10,
+		EndLedger:   20,
+		StartTime:   time.Unix(1, 0).UTC(),
+		EndTime:     time.Unix(2, 0).UTC(),
+	}, qp.HistoryQuery())
+
+	native := xdr.MustNewNativeAsset()
+	assert.Equal(t, &native, AccountBalanceHistoryQuery{AssetFilter: "native"}.Asset())
+	assert.Nil(t, AccountBalanceHistoryQuery{}.Asset())
+}
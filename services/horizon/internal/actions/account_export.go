@@ -0,0 +1,417 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	stdtime "time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/support/time"
+	"github.com/stellar/go/toid"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+)
+
+// accountExportColumns are the columns of the CSV exports, they match the
+// json names of the fields of hProtocol.AccountExportRecord.
+var accountExportColumns = []string{
+	"record_type",
+	"id",
+	"paging_token",
+	"ledger",
+	"created_at",
+	"transaction_hash",
+	"successful",
+	"type",
+	"details",
+}
+
+// AccountExportQuery query struct for the /accounts/{account_id}/export
+// end-point
+type AccountExportQuery struct {
+	AccountID    string      `schema:"account_id" valid:"accountID,required"`
+	From         time.Millis `schema:"from" valid:"-"`
+	To           time.Millis `schema:"to" valid:"-"`
+	FormatFilter string      `schema:"format" valid:"-"`
+}
+
+// Validate runs extra validations on query parameters
+func (qp AccountExportQuery) Validate() error {
+	if qp.From.IsNil() {
+		return problem.MakeInvalidFieldProblem("from", errors.New("from is required"))
+	}
+	if qp.To.IsNil() {
+		return problem.MakeInvalidFieldProblem("to", errors.New("to is required"))
+	}
+	if qp.From.ToInt64() >= qp.To.ToInt64() {
+		return problem.MakeInvalidFieldProblem("to", errors.New("to must be greater than from"))
+	}
+	switch qp.FormatFilter {
+	case "", exportFormatCSV, exportFormatNDJSON:
+	default:
+		return problem.MakeInvalidFieldProblem(
+			"format",
+			errors.Errorf("format must be %s or %s", exportFormatCSV, exportFormatNDJSON),
+		)
+	}
+	return nil
+}
+
+// Format returns the format of the export, ndjson unless csv was requested.
+func (qp AccountExportQuery) Format() string {
+	if qp.FormatFilter == "" {
+		return exportFormatNDJSON
+	}
+	return qp.FormatFilter
+}
+
+// GetAccountExportHandler is the action handler for the
+// /accounts/{account_id}/export endpoint. It streams the transactions,
+// operations, effects and trades of the account in the requested period.
+type GetAccountExportHandler struct {
+	// MaxRows is the maximum number of records of an export, requests
+	// selecting more records are rejected before anything is streamed.
+	MaxRows    uint
+	SkipTxMeta bool
+}
+
+func (handler GetAccountExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	qp := AccountExportQuery{}
+	if err := getParams(&qp, r); err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	// The records are fetched in small batches, so the statements of the
+	// export are not subject to the query timeout which would otherwise bound
+	// the duration of the whole export. The request context is still checked
+	// before each statement, so the export stops when the client goes away.
+	streamCtx := context.WithValue(r.Context(), &db.DeadlineCtxKey, stdtime.Time{})
+	err = historyQ.BeginTx(streamCtx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		problem.Render(r.Context(), w, errors.Wrap(err, "could not begin export transaction"))
+		return
+	}
+	defer historyQ.Rollback()
+
+	query, err := handler.exportQuery(r.Context(), historyQ, qp)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	var writer exportWriter
+	if qp.Format() == exportFormatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		writer = newCSVExportWriter(w)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		writer = newNDJSONExportWriter(w)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(
+		`attachment; filename="%s-%d-%d.%s"`,
+		qp.AccountID, qp.From.ToInt64(), qp.To.ToInt64(), qp.Format(),
+	))
+	w.WriteHeader(http.StatusOK)
+
+	if err = writer.begin(); err == nil && query.StartLedger > 0 {
+		err = handler.stream(streamCtx, historyQ, query, writer)
+	}
+	if err == nil {
+		err = writer.flush()
+	}
+	if err != nil {
+		// the response has started, the export is truncated
+		log.Ctx(r.Context()).WithError(err).Error("could not export account history")
+	}
+}
+
+// exportQuery resolves the account and the ledgers of the requested period
+// and checks the number of records selected is within the limit.
+func (handler GetAccountExportHandler) exportQuery(ctx context.Context, historyQ *history.Q, qp AccountExportQuery) (history.AccountExportQuery, error) {
+	var account history.Account
+	if err := historyQ.AccountByAddress(ctx, &account, qp.AccountID); err != nil {
+		return history.AccountExportQuery{}, err
+	}
+
+	start, end, err := historyQ.LedgerRangeByCloseTime(ctx, qp.From.ToTime(), qp.To.ToTime())
+	if err != nil {
+		return history.AccountExportQuery{}, err
+	}
+	query := history.AccountExportQuery{AccountID: account.ID, StartLedger: start, EndLedger: end}
+	if start == 0 {
+		return query, nil
+	}
+
+	count, err := historyQ.CountAccountExport(ctx, query)
+	if err != nil {
+		return history.AccountExportQuery{}, err
+	}
+	if count > int64(handler.MaxRows) {
+		return history.AccountExportQuery{}, problem.MakeInvalidFieldProblem(
+			"to",
+			errors.Errorf(
+				"the period contains %d records which is more than the limit of %d, split it into shorter periods",
+				count, handler.MaxRows,
+			),
+		)
+	}
+	return query, nil
+}
+
+func (handler GetAccountExportHandler) stream(ctx context.Context, historyQ *history.Q, query history.AccountExportQuery, writer exportWriter) error {
+	err := historyQ.StreamAccountTransactions(ctx, query, func(rows []history.Transaction) error {
+		for _, row := range rows {
+			record, err := exportTransaction(ctx, row, handler.SkipTxMeta)
+			if err != nil {
+				return err
+			}
+			if err = writer.write(record); err != nil {
+				return err
+			}
+		}
+		return writer.flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	err = historyQ.StreamAccountOperations(ctx, query, func(rows []history.Operation) error {
+		ledgerCache := history.LedgerCache{}
+		for _, row := range rows {
+			ledgerCache.Queue(row.LedgerSequence())
+		}
+		if err := ledgerCache.Load(ctx, historyQ); err != nil {
+			return errors.Wrap(err, "failed to load ledger batch")
+		}
+		for _, row := range rows {
+			record, err := exportOperation(ctx, row, ledgerCache.Records[row.LedgerSequence()], handler.SkipTxMeta)
+			if err != nil {
+				return err
+			}
+			if err = writer.write(record); err != nil {
+				return err
+			}
+		}
+		return writer.flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	err = historyQ.StreamAccountEffects(ctx, query, func(rows []history.Effect) error {
+		ledgerCache := history.LedgerCache{}
+		for _, row := range rows {
+			ledgerCache.Queue(row.LedgerSequence())
+		}
+		if err := ledgerCache.Load(ctx, historyQ); err != nil {
+			return errors.Wrap(err, "failed to load ledger batch")
+		}
+		for _, row := range rows {
+			record, err := exportEffect(ctx, row, ledgerCache.Records[row.LedgerSequence()])
+			if err != nil {
+				return err
+			}
+			if err = writer.write(record); err != nil {
+				return err
+			}
+		}
+		return writer.flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return historyQ.StreamAccountTrades(ctx, query, func(rows []history.Trade) error {
+		for _, row := range rows {
+			record, err := exportTrade(ctx, row)
+			if err != nil {
+				return err
+			}
+			if err = writer.write(record); err != nil {
+				return err
+			}
+		}
+		return writer.flush()
+	})
+}
+
+func exportTransaction(ctx context.Context, row history.Transaction, skipTxMeta bool) (hProtocol.AccountExportRecord, error) {
+	var resource hProtocol.Transaction
+	if err := resourceadapter.PopulateTransaction(ctx, row.TransactionHash, &resource, row, skipTxMeta); err != nil {
+		return hProtocol.AccountExportRecord{}, err
+	}
+	return newExportRecord(resource, hProtocol.AccountExportRecord{
+		RecordType:      "transaction",
+		ID:              resource.ID,
+		PT:              resource.PT,
+		Ledger:          resource.Ledger,
+		CreatedAt:       resource.LedgerCloseTime,
+		TransactionHash: resource.Hash,
+		Successful:      resource.Successful,
+	})
+}
+
+func exportOperation(ctx context.Context, row history.Operation, ledger history.Ledger, skipTxMeta bool) (hProtocol.AccountExportRecord, error) {
+	resource, err := resourceadapter.NewOperation(ctx, row, row.TransactionHash, nil, ledger, skipTxMeta)
+	if err != nil {
+		return hProtocol.AccountExportRecord{}, err
+	}
+	op := resource.(operations.Operation)
+	return newExportRecord(resource, hProtocol.AccountExportRecord{
+		RecordType:      "operation",
+		ID:              op.GetID(),
+		PT:              op.PagingToken(),
+		Ledger:          row.LedgerSequence(),
+		CreatedAt:       op.GetBase().LedgerCloseTime,
+		TransactionHash: op.GetTransactionHash(),
+		Successful:      op.IsTransactionSuccessful(),
+		Type:            op.GetType(),
+	})
+}
+
+func exportEffect(ctx context.Context, row history.Effect, ledger history.Ledger) (hProtocol.AccountExportRecord, error) {
+	resource, err := resourceadapter.NewEffect(ctx, row, ledger)
+	if err != nil {
+		return hProtocol.AccountExportRecord{}, err
+	}
+	effect := resource.(effects.Effect)
+	return newExportRecord(resource, hProtocol.AccountExportRecord{
+		RecordType: "effect",
+		ID:         effect.GetID(),
+		PT:         effect.PagingToken(),
+		Ledger:     row.LedgerSequence(),
+		CreatedAt:  ledger.ClosedAt,
+		Successful: true,
+		Type:       effect.GetType(),
+	})
+}
+
+func exportTrade(ctx context.Context, row history.Trade) (hProtocol.AccountExportRecord, error) {
+	var resource hProtocol.Trade
+	resourceadapter.PopulateTrade(ctx, &resource, row)
+	return newExportRecord(resource, hProtocol.AccountExportRecord{
+		RecordType: "trade",
+		ID:         resource.ID,
+		PT:         resource.PT,
+		Ledger:     toid.Parse(row.HistoryOperationID).LedgerSequence,
+		CreatedAt:  resource.LedgerCloseTime,
+		Successful: true,
+		Type:       resource.TradeType,
+	})
+}
+
+// newExportRecord sets the details of record to the json encoding of
+// resource.
+func newExportRecord(resource interface{}, record hProtocol.AccountExportRecord) (hProtocol.AccountExportRecord, error) {
+	details, err := json.Marshal(resource)
+	if err != nil {
+		return hProtocol.AccountExportRecord{}, errors.Wrapf(err, "could not encode %s %s", record.RecordType, record.ID)
+	}
+	record.Details = details
+	return record, nil
+}
+
+// exportWriter writes the records of an export in one of the supported
+// formats.
+type exportWriter interface {
+	begin() error
+	write(record hProtocol.AccountExportRecord) error
+	// flush sends the records written so far to the client.
+	flush() error
+}
+
+type csvExportWriter struct {
+	out io.Writer
+	csv *csv.Writer
+}
+
+func newCSVExportWriter(out io.Writer) *csvExportWriter {
+	return &csvExportWriter{out: out, csv: csv.NewWriter(out)}
+}
+
+func (w *csvExportWriter) begin() error {
+	return w.csv.Write(accountExportColumns)
+}
+
+func (w *csvExportWriter) write(record hProtocol.AccountExportRecord) error {
+	return w.csv.Write([]string{
+		record.RecordType,
+		record.ID,
+		record.PT,
+		strconv.FormatInt(int64(record.Ledger), 10),
+		record.CreatedAt.UTC().Format(stdtime.RFC3339),
+		record.TransactionHash,
+		strconv.FormatBool(record.Successful),
+		record.Type,
+		string(record.Details),
+	})
+}
+
+func (w *csvExportWriter) flush() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return errors.Wrap(err, "could not write csv records")
+	}
+	flushResponse(w.out)
+	return nil
+}
+
+type ndjsonExportWriter struct {
+	out     io.Writer
+	encoder *json.Encoder
+}
+
+func newNDJSONExportWriter(out io.Writer) *ndjsonExportWriter {
+	return &ndjsonExportWriter{out: out, encoder: json.NewEncoder(out)}
+}
+
+func (w *ndjsonExportWriter) begin() error {
+	return nil
+}
+
+func (w *ndjsonExportWriter) write(record hProtocol.AccountExportRecord) error {
+	// Encode terminates each record with a newline
+	if err := w.encoder.Encode(record); err != nil {
+		return errors.Wrap(err, "could not write json record")
+	}
+	return nil
+}
+
+func (w *ndjsonExportWriter) flush() error {
+	flushResponse(w.out)
+	return nil
+}
+
+func flushResponse(out io.Writer) {
+	if flusher, ok := out.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
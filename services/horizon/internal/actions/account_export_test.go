@@ -0,0 +1,141 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/toid"
+)
+
+func TestAccountExportQueryValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		name          string
+		query         string
+		invalidField  string
+		invalidReason string
+	}{
+		{
+			"missing from",
+			"to=2000",
+			"from",
+			"from is required",
+		},
+		{
+			"missing to",
+			"from=1000",
+			"to",
+			"to is required",
+		},
+		{
+			"invalid time range",
+			"from=2000&to=2000",
+			"to",
+			"to must be greater than from",
+		},
+		{
+			"invalid format",
+			"from=1000&to=2000&format=xml",
+			"format",
+			"format must be csv or ndjson",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			called := false
+			s := httptest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				qp := AccountExportQuery{}
+				err := getParams(&qp, r)
+				assert.Error(t, err)
+				p, ok := err.(*problem.P)
+				if assert.True(t, ok) {
+					assert.Equal(t, 400, p.Status)
+					assert.Equal(t, testCase.invalidField, p.Extras["invalid_field"])
+					assert.Equal(t, testCase.invalidReason, p.Extras["reason"])
+				}
+				called = true
+			}))
+			defer s.Close()
+
+			_, err := http.Get(s.URL + "/?account_id=GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB&" + testCase.query)
+			assert.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestAccountExportQueryFormat(t *testing.T) {
+	assert.Equal(t, exportFormatNDJSON, AccountExportQuery{}.Format())
+	assert.Equal(t, exportFormatCSV, AccountExportQuery{FormatFilter: "csv"}.Format())
+}
+
+func TestExportWriters(t *testing.T) {
+	closedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	opID := toid.New(10, 1, 1).ToInt64()
+	record, err := exportTrade(context.Background(), history.Trade{
+		HistoryOperationID: opID,
+		Order:              0,
+		LedgerCloseTime:    closedAt,
+		BaseAssetType:      "native",
+		CounterAssetType:   "native",
+		BaseAmount:         10,
+		CounterAmount:      20,
+		PriceN:             null.IntFrom(2),
+		PriceD:             null.IntFrom(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "trade", record.RecordType)
+	assert.Equal(t, int32(10), record.Ledger)
+	assert.Equal(t, closedAt, record.CreatedAt)
+	assert.True(t, record.Successful)
+
+	var trade hProtocol.Trade
+	require.NoError(t, json.Unmarshal(record.Details, &trade))
+	assert.Equal(t, record.ID, trade.ID)
+	assert.Equal(t, "0.0000010", trade.BaseAmount)
+
+	var out bytes.Buffer
+	writer := newNDJSONExportWriter(&out)
+	require.NoError(t, writer.begin())
+	require.NoError(t, writer.write(record))
+	require.NoError(t, writer.write(record))
+	require.NoError(t, writer.flush())
+	lines := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 2)
+	var decoded hProtocol.AccountExportRecord
+	require.NoError(t, json.Unmarshal(lines[1], &decoded))
+	assert.Equal(t, record.ID, decoded.ID)
+	assert.JSONEq(t, string(record.Details), string(decoded.Details))
+
+	out.Reset()
+	csvWriter := newCSVExportWriter(&out)
+	require.NoError(t, csvWriter.begin())
+	require.NoError(t, csvWriter.write(record))
+	require.NoError(t, csvWriter.flush())
+	rows, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, accountExportColumns, rows[0])
+	assert.Equal(t, []string{
+		"trade",
+		record.ID,
+		record.PT,
+		"10",
+		"2024-01-02T03:04:05Z",
+		"",
+		"true",
+		record.Type,
+		string(record.Details),
+	}, rows[1])
+}
@@ -0,0 +1,252 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/apikeys"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db/pg"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// APIKeyRegistry holds the API keys used to authenticate requests, it is
+// refreshed whenever a key is changed.
+type APIKeyRegistry interface {
+	Refresh(ctx context.Context) error
+}
+
+// these admin HTTP endpoints are documented in services/horizon/internal/httpx/static/admin_oapi.yml
+type APIKeyHandler struct {
+	Registry APIKeyRegistry
+}
+
+func (handler APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	key, err := handler.keyRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	secret, hash, err := apikeys.Generate()
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	key.KeyHash = hash
+
+	inserted, err := historyQ.InsertAPIKey(r.Context(), key)
+	if err != nil {
+		problem.Render(r.Context(), w, handler.keyError(err, key))
+		return
+	}
+	handler.refresh(r.Context())
+
+	responsePayload := handler.keyResource(inserted)
+	// the key is only disclosed when it is created
+	responsePayload.Key = secret
+	w.WriteHeader(http.StatusCreated)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler APIKeyHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	keys, err := historyQ.GetAPIKeys(r.Context())
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := make([]hProtocol.APIKey, 0, len(keys))
+	for _, key := range keys {
+		responsePayload = append(responsePayload, handler.keyResource(key))
+	}
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler APIKeyHandler) GetKey(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	id, err := handler.keyID(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	key, err := historyQ.GetAPIKeyByID(r.Context(), id)
+	if historyQ.NoRows(err) {
+		err = problem.NotFound
+	}
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(handler.keyResource(key)); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler APIKeyHandler) UpdateKey(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	id, err := handler.keyID(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	key, err := handler.keyRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	key.ID = id
+
+	updated, err := historyQ.UpdateAPIKey(r.Context(), key)
+	if historyQ.NoRows(err) {
+		err = problem.NotFound
+	}
+	if err != nil {
+		problem.Render(r.Context(), w, handler.keyError(err, key))
+		return
+	}
+	handler.refresh(r.Context())
+
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(handler.keyResource(updated)); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler APIKeyHandler) DeleteKey(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	id, err := handler.keyID(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	deleted, err := historyQ.DeleteAPIKey(r.Context(), id)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	if deleted == 0 {
+		problem.Render(r.Context(), w, problem.NotFound)
+		return
+	}
+	handler.refresh(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refresh applies a change of the keys to this instance right away, the other
+// instances sharing the database pick it up on their next refresh.
+func (handler APIKeyHandler) refresh(ctx context.Context) {
+	if handler.Registry == nil {
+		return
+	}
+	if err := handler.Registry.Refresh(ctx); err != nil {
+		log.Ctx(ctx).WithError(err).Warn("could not refresh api keys")
+	}
+}
+
+func (handler APIKeyHandler) keyID(r *http.Request) (int64, error) {
+	value, err := getStringFromURLParam(r, "id")
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, problem.MakeInvalidFieldProblem("id", errors.New("invalid api key id"))
+	}
+	return id, nil
+}
+
+func (handler APIKeyHandler) keyError(err error, key history.APIKey) error {
+	if pg.IsUniqueViolation(err) {
+		return problem.MakeInvalidFieldProblem("name", fmt.Errorf("an api key named %s already exists", key.Name))
+	}
+	return err
+}
+
+func (handler APIKeyHandler) keyRequest(r *http.Request) (history.APIKey, error) {
+	var request hProtocol.APIKey
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&request); err != nil {
+		p := problem.NewProblemWithInvalidField(problem.BadRequest, "reason", fmt.Errorf("invalid json for api key %v", err.Error()))
+		return history.APIKey{}, p
+	}
+
+	key := history.APIKey{
+		Name:                 strings.TrimSpace(request.Name),
+		RequestsPerHour:      request.RequestsPerHour,
+		MaxStreams:           request.MaxStreams,
+		PathFindingPerSecond: request.PathFindingPerSecond,
+	}
+	if key.Name == "" {
+		return history.APIKey{}, problem.MakeInvalidFieldProblem("name", errors.New("name is required"))
+	}
+	for _, limit := range []struct {
+		field string
+		value int32
+	}{
+		{"requests_per_hour", key.RequestsPerHour},
+		{"max_streams", key.MaxStreams},
+		{"path_finding_per_second", key.PathFindingPerSecond},
+	} {
+		if limit.value < 0 {
+			return history.APIKey{}, problem.MakeInvalidFieldProblem(limit.field, errors.New("limits must be positive, or zero for no limit"))
+		}
+	}
+	return key, nil
+}
+
+func (handler APIKeyHandler) keyResource(key history.APIKey) hProtocol.APIKey {
+	return hProtocol.APIKey{
+		ID:                   key.ID,
+		Name:                 key.Name,
+		RequestsPerHour:      key.RequestsPerHour,
+		MaxStreams:           key.MaxStreams,
+		PathFindingPerSecond: key.PathFindingPerSecond,
+		CreatedAt:            key.CreatedAt,
+		UpdatedAt:            key.UpdatedAt,
+	}
+}
@@ -0,0 +1,134 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/apikeys"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/render/problem"
+)
+
+type testAPIKeyRegistry struct {
+	refreshed int
+}
+
+func (r *testAPIKeyRegistry) Refresh(ctx context.Context) error {
+	r.refreshed++
+	return nil
+}
+
+func TestAPIKeyRequestValidation(t *testing.T) {
+	handler := APIKeyHandler{}
+
+	for _, testCase := range []struct {
+		body  string
+		field string
+	}{
+		{`{"name": 1}`, "reason"},
+		{`{"name": "  "}`, "name"},
+		{`{"name": "wallet", "requests_per_hour": -1}`, "requests_per_hour"},
+		{`{"name": "wallet", "max_streams": -1}`, "max_streams"},
+		{`{"name": "wallet", "path_finding_per_second": -1}`, "path_finding_per_second"},
+	} {
+		request := httptest.NewRequest("POST", "/api_keys", strings.NewReader(testCase.body))
+		_, err := handler.keyRequest(request)
+		require.Error(t, err, testCase.body)
+		assert.Equal(t, testCase.field, err.(*problem.P).Extras["invalid_field"], testCase.body)
+	}
+
+	request := httptest.NewRequest("POST", "/api_keys", strings.NewReader(`{
+		"name": " wallet ",
+		"requests_per_hour": 7200,
+		"max_streams": 5,
+		"path_finding_per_second": 2
+	}`))
+	key, err := handler.keyRequest(request)
+	require.NoError(t, err)
+	assert.Equal(t, history.APIKey{
+		Name:                 "wallet",
+		RequestsPerHour:      7200,
+		MaxStreams:           5,
+		PathFindingPerSecond: 2,
+	}, key)
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+
+	q := &history.Q{SessionInterface: tt.HorizonSession()}
+	registry := &testAPIKeyRegistry{}
+	handler := APIKeyHandler{Registry: registry}
+
+	recorder := httptest.NewRecorder()
+	request := makeRequest(t, map[string]string{}, map[string]string{}, q)
+	request.Body = ioutil.NopCloser(strings.NewReader(`{"name": "wallet", "max_streams": 5}`))
+	handler.CreateKey(recorder, request)
+
+	resp := recorder.Result()
+	tt.Assert.Equal(http.StatusCreated, resp.StatusCode)
+	var created hProtocol.APIKey
+	tt.Assert.NoError(json.NewDecoder(resp.Body).Decode(&created))
+	tt.Assert.NotZero(created.ID)
+	tt.Assert.Len(created.Key, 64)
+	tt.Assert.Equal(int32(5), created.MaxStreams)
+	tt.Assert.Equal(1, registry.refreshed)
+
+	stored, err := q.GetAPIKeyByID(tt.Ctx, created.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(apikeys.Hash(created.Key), stored.KeyHash)
+
+	recorder = httptest.NewRecorder()
+	request = makeRequest(t, map[string]string{}, map[string]string{}, q)
+	request.Body = ioutil.NopCloser(strings.NewReader(`{"name": "wallet"}`))
+	handler.CreateKey(recorder, request)
+	tt.Assert.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+
+	id := map[string]string{"id": strconv.FormatInt(created.ID, 10)}
+
+	recorder = httptest.NewRecorder()
+	request = makeRequest(t, map[string]string{}, id, q)
+	request.Body = ioutil.NopCloser(strings.NewReader(`{"name": "wallet", "requests_per_hour": 3600}`))
+	handler.UpdateKey(recorder, request)
+	tt.Assert.Equal(http.StatusOK, recorder.Result().StatusCode)
+	var updated hProtocol.APIKey
+	tt.Assert.NoError(json.NewDecoder(recorder.Result().Body).Decode(&updated))
+	tt.Assert.Equal(int32(3600), updated.RequestsPerHour)
+	tt.Assert.Zero(updated.MaxStreams)
+	tt.Assert.Empty(updated.Key)
+	tt.Assert.Equal(2, registry.refreshed)
+
+	recorder = httptest.NewRecorder()
+	handler.GetKeys(recorder, makeRequest(t, map[string]string{}, map[string]string{}, q))
+	var keys []hProtocol.APIKey
+	tt.Assert.NoError(json.NewDecoder(recorder.Result().Body).Decode(&keys))
+	tt.Assert.Len(keys, 1)
+	tt.Assert.Equal(created.ID, keys[0].ID)
+	tt.Assert.Empty(keys[0].Key)
+
+	recorder = httptest.NewRecorder()
+	handler.DeleteKey(recorder, makeRequest(t, map[string]string{}, id, q))
+	tt.Assert.Equal(http.StatusNoContent, recorder.Result().StatusCode)
+	tt.Assert.Equal(3, registry.refreshed)
+
+	recorder = httptest.NewRecorder()
+	handler.GetKey(recorder, makeRequest(t, map[string]string{}, id, q))
+	tt.Assert.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+
+	recorder = httptest.NewRecorder()
+	handler.DeleteKey(recorder, makeRequest(t, map[string]string{}, id, q))
+	tt.Assert.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+}
@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+// ContractInfo returns the information about a contract identified by addr.
+// Horizon only knows about stellar asset contracts and contracts holding
+// stellar asset contract balances, other contracts are not found.
+func ContractInfo(ctx context.Context, hq *history.Q, addr string) (*protocol.Contract, error) {
+	contractID, err := contractHash(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var assetStat *history.ExpAssetStat
+	stat, err := hq.GetAssetStatByContract(ctx, contractID)
+	switch {
+	case hq.NoRows(err):
+		holdings, err := hq.GetContractAssetHoldings(ctx, contractID, db2.PageQuery{Order: db2.OrderAscending, Limit: 1})
+		if err != nil {
+			return nil, errors.Wrap(err, "getting contract balances")
+		}
+		if len(holdings) == 0 {
+			return nil, problem.NotFound
+		}
+	case err != nil:
+		return nil, errors.Wrap(err, "getting asset stat")
+	default:
+		assetStat = &stat
+	}
+
+	var resource protocol.Contract
+	if err := resourceadapter.PopulateContract(ctx, &resource, addr, assetStat); err != nil {
+		return nil, errors.Wrap(err, "populating contract")
+	}
+	return &resource, nil
+}
+
+func contractHash(addr string) (xdr.Hash, error) {
+	var contractID xdr.Hash
+	raw, err := strkey.Decode(strkey.VersionByteContract, addr)
+	if err != nil {
+		return contractID, errors.Wrap(err, "decoding contract id")
+	}
+	copy(contractID[:], raw)
+	return contractID, nil
+}
+
+// ContractQuery query struct for contracts/{contract_id} end-points
+type ContractQuery struct {
+	ContractID string `schema:"contract_id" valid:"contractID"`
+}
+
+// Contract is the response for the contracts/{contract_id} end-point
+type Contract protocol.Contract
+
+// Equals implements StreamableObjectResponse
+func (c Contract) Equals(other StreamableObjectResponse) bool {
+	otherContract, ok := other.(Contract)
+	if !ok {
+		return false
+	}
+	return c.ID == otherContract.ID
+}
+
+// GetContractByIDHandler is the action handler for the
+// /contracts/{contract_id} endpoint
+type GetContractByIDHandler struct{}
+
+// GetResource returns a contract.
+func (handler GetContractByIDHandler) GetResource(
+	w HeaderWriter,
+	r *http.Request,
+) (StreamableObjectResponse, error) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := ContractQuery{}
+	if err = getParams(&qp, r); err != nil {
+		return nil, err
+	}
+	contract, err := ContractInfo(r.Context(), historyQ, qp.ContractID)
+	if err != nil {
+		return nil, err
+	}
+	return Contract(*contract), nil
+}
+
+// GetContractBalancesHandler is the action handler for the
+// /contracts/{contract_id}/balances endpoint
+type GetContractBalancesHandler struct {
+	LedgerState *ledger.State
+}
+
+// GetResourcePage returns a page of the stellar asset contract balances held
+// by a contract.
+func (handler GetContractBalancesHandler) GetResourcePage(
+	w HeaderWriter,
+	r *http.Request,
+) ([]hal.Pageable, error) {
+	ctx := r.Context()
+	qp := ContractQuery{}
+	err := getParams(&qp, r)
+	if err != nil {
+		return nil, err
+	}
+
+	pq, err := GetPageQuery(handler.LedgerState, r, DisableCursorValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	contractID, err := contractHash(qp.ContractID)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := historyQ.GetContractAssetHoldings(ctx, contractID, pq)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading contract balances")
+	}
+
+	balances := make([]hal.Pageable, 0, len(holdings))
+	for _, holding := range holdings {
+		var balance protocol.ContractBalance
+		if err := resourceadapter.PopulateContractBalance(ctx, &balance, holding); err != nil {
+			return nil, errors.Wrap(err, "populating contract balance")
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
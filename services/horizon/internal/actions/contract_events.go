@@ -0,0 +1,115 @@
+package actions
+
+import (
+	"net/http"
+
+	"github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+// contractEventTypes are the event types which can be used to filter the
+// contract events end-point. Diagnostic events are not ingested.
+var contractEventTypes = map[string]xdr.ContractEventType{
+	"contract": xdr.ContractEventTypeContract,
+	"system":   xdr.ContractEventTypeSystem,
+}
+
+// ContractEventsQuery query struct for the contract events end-point
+type ContractEventsQuery struct {
+	ContractID  string `schema:"contract_id" valid:"contractID,optional"`
+	Type        string `schema:"type" valid:"contractEventType,optional"`
+	Topic1      string `schema:"topic1" valid:"scVal,optional"`
+	Topic2      string `schema:"topic2" valid:"scVal,optional"`
+	Topic3      string `schema:"topic3" valid:"scVal,optional"`
+	Topic4      string `schema:"topic4" valid:"scVal,optional"`
+	StartLedger uint32 `schema:"start_ledger" valid:"-"`
+	EndLedger   uint32 `schema:"end_ledger" valid:"-"`
+}
+
+// Validate runs extra validations on query parameters
+func (qp ContractEventsQuery) Validate() error {
+	if qp.StartLedger > 0 && qp.EndLedger > 0 && qp.StartLedger > qp.EndLedger {
+		return problem.MakeInvalidFieldProblem(
+			"end_ledger",
+			errors.New("end_ledger must be greater than or equal to start_ledger"),
+		)
+	}
+	return nil
+}
+
+// HistoryQuery converts the query parameters into a history.ContractEventsQuery
+func (qp ContractEventsQuery) HistoryQuery() history.ContractEventsQuery {
+	query := history.ContractEventsQuery{
+		ContractID:  qp.ContractID,
+		StartLedger: qp.StartLedger,
+		EndLedger:   qp.EndLedger,
+	}
+	if eventType, ok := contractEventTypes[qp.Type]; ok {
+		query.Type = &eventType
+	}
+	for i, topic := range []string{qp.Topic1, qp.Topic2, qp.Topic3, qp.Topic4} {
+		if topic != "" {
+			topic := topic
+			query.Topics[i] = &topic
+		}
+	}
+	return query
+}
+
+// GetContractEventsHandler is the action handler for the /contract_events
+// endpoint
+type GetContractEventsHandler struct {
+	LedgerState *ledger.State
+}
+
+// GetResourcePage returns a page of contract events.
+func (handler GetContractEventsHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	pq, err := GetPageQuery(handler.LedgerState, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateAndAdjustCursor(handler.LedgerState, &pq)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := ContractEventsQuery{}
+	err = getParams(&qp, r)
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.ContractEvents(
+		r.Context(),
+		qp.HistoryQuery(),
+		pq,
+		handler.LedgerState.CurrentStatus().HistoryElder,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading contract event records")
+	}
+
+	var result []hal.Pageable
+	for _, record := range records {
+		var event horizon.ContractEvent
+		if err = resourceadapter.PopulateContractEvent(r.Context(), &event, record); err != nil {
+			return nil, errors.Wrap(err, "could not create contract event")
+		}
+		result = append(result, event)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+func TestContractEventsQueryValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		name          string
+		query         string
+		invalidField  string
+		invalidReason string
+	}{
+		{
+			"invalid contract id",
+			"contract_id=GAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQPZW",
+			"contract_id",
+			"Contract ID must start with `C` and contain 56 alphanum characters",
+		},
+		{
+			"diagnostic events are not supported",
+			"type=diagnostic",
+			"type",
+			"Contract event type must be contract or system",
+		},
+		{
+			"invalid topic",
+			"topic2=foobar",
+			"topic2",
+			"Topic must be the base64-encoded XDR representation of a SCVal",
+		},
+		{
+			"invalid ledger range",
+			"start_ledger=10&end_ledger=9",
+			"end_ledger",
+			"end_ledger must be greater than or equal to start_ledger",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			called := false
+			s := httptest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				qp := ContractEventsQuery{}
+				err := getParams(&qp, r)
+				assert.Error(t, err)
+				p, ok := err.(*problem.P)
+				if assert.True(t, ok) {
+					assert.Equal(t, 400, p.Status)
+					assert.Equal(t, testCase.invalidField, p.Extras["invalid_field"])
+					assert.Equal(t, testCase.invalidReason, p.Extras["reason"])
+				}
+				called = true
+			}))
+			defer s.Close()
+
+			_, err := http.Get(s.URL + "/?" + testCase.query)
+			assert.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestContractEventsQueryHistoryQuery(t *testing.T) {
+	qp := ContractEventsQuery{
+		ContractID:  "CA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQGAXE",
+		Type:        "system",
+		Topic2:      "AAAAAwAAAAo=",
+		StartLedger: 10,
+		EndLedger:   20,
+	}
+	query := qp.HistoryQuery()
+
+	systemType := xdr.ContractEventTypeSystem
+	topic := "AAAAAwAAAAo="
+	assert.Equal(t, history.ContractEventsQuery{
+		ContractID:  qp.ContractID,
+		Type:        &systemType,
+		Topics:      [history.MaxContractEventTopics]*string{nil, &topic},
+		StartLedger: 10,
+		EndLedger:   20,
+	}, query)
+
+	assert.Equal(t, history.ContractEventsQuery{}, ContractEventsQuery{}.HistoryQuery())
+}
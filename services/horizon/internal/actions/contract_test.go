@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+func TestGetContractHandlers(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &history.Q{tt.HorizonSession()}
+
+	usdContractID := xdr.Hash{1}
+	usdAssetStat := history.ExpAssetStat{
+		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+		AssetIssuer: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+		AssetCode:   "USD",
+		Balances: history.ExpAssetStatBalances{
+			Authorized:                      "0",
+			AuthorizedToMaintainLiabilities: "0",
+			Unauthorized:                    "0",
+			ClaimableBalances:               "0",
+			LiquidityPools:                  "0",
+		},
+	}
+	usdAssetStat.SetContractID(usdContractID)
+	_, err := q.InsertAssetStat(tt.Ctx, usdAssetStat)
+	tt.Assert.NoError(err)
+
+	holderID := xdr.Hash{2}
+	unknownAssetContractID := xdr.Hash{3}
+	tt.Assert.NoError(q.InsertContractAssetBalances(tt.Ctx, []history.ContractAssetBalance{
+		{
+			KeyHash:          []byte{1, 31: 0},
+			ContractID:       usdContractID[:],
+			HolderID:         holderID[:],
+			Amount:           "1000000000",
+			ExpirationLedger: 100,
+		},
+		{
+			// balances of unknown asset contracts are ignored
+			KeyHash:          []byte{2, 31: 0},
+			ContractID:       unknownAssetContractID[:],
+			HolderID:         holderID[:],
+			Amount:           "5",
+			ExpirationLedger: 100,
+		},
+	}))
+
+	usdContract := strkey.MustEncode(strkey.VersionByteContract, usdContractID[:])
+	holder := strkey.MustEncode(strkey.VersionByteContract, holderID[:])
+
+	handler := GetContractByIDHandler{}
+	response, err := handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"contract_id": usdContract}, q,
+	))
+	tt.Assert.NoError(err)
+	contract := response.(Contract)
+	tt.Assert.Equal(usdContract, contract.ID)
+	tt.Assert.Equal("USD", contract.Asset.Code)
+	tt.Assert.Equal("credit_alphanum4", contract.Asset.Type)
+
+	response, err = handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"contract_id": holder}, q,
+	))
+	tt.Assert.NoError(err)
+	contract = response.(Contract)
+	tt.Assert.Equal(holder, contract.ID)
+	tt.Assert.Nil(contract.Asset)
+
+	// contracts are also served from /accounts/{account_id}
+	response, err = GetAccountByIDHandler{}.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"account_id": holder}, q,
+	))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(holder, response.(Contract).ID)
+
+	unknown := strkey.MustEncode(strkey.VersionByteContract, unknownAssetContractID[:])
+	_, err = handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"contract_id": unknown}, q,
+	))
+	tt.Assert.Equal(problem.NotFound, err)
+
+	balancesHandler := GetContractBalancesHandler{LedgerState: &ledger.State{}}
+	records, err := balancesHandler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"contract_id": holder}, q,
+	))
+	tt.Assert.NoError(err)
+	tt.Assert.Len(records, 1)
+	balance := records[0].(protocol.ContractBalance)
+	tt.Assert.Equal(usdContract, balance.AssetContractID)
+	tt.Assert.Equal("100.0000000", balance.Balance)
+	tt.Assert.Equal(uint32(100), balance.LiveUntilLedger)
+	tt.Assert.Equal("USD", balance.Code)
+
+	records, err = balancesHandler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{"cursor": balance.PagingToken()}, map[string]string{"contract_id": holder}, q,
+	))
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(records)
+}
+
+func TestContractQueryValidation(t *testing.T) {
+	r := makeRequest(
+		t,
+		map[string]string{},
+		map[string]string{"contract_id": "GAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQPZW"},
+		&db.MockSession{},
+	)
+
+	_, err := GetContractByIDHandler{}.GetResource(httptest.NewRecorder(), r)
+	assertInvalidContractID(t, err)
+
+	_, err = GetContractBalancesHandler{LedgerState: &ledger.State{}}.GetResourcePage(httptest.NewRecorder(), r)
+	assertInvalidContractID(t, err)
+}
+
+func assertInvalidContractID(t *testing.T, err error) {
+	p, ok := err.(*problem.P)
+	if assert.True(t, ok) {
+		assert.Equal(t, "contract_id", p.Extras["invalid_field"])
+		assert.Equal(t, "Contract ID must start with `C` and contain 56 alphanum characters", p.Extras["reason"])
+	}
+}
@@ -16,7 +16,7 @@ import (
 
 // EffectsQuery query struct for effects end-points
 type EffectsQuery struct {
-	AccountID       string `schema:"account_id" valid:"accountID,optional"`
+	AccountID       string `schema:"account_id" valid:"muxedAccountID,optional"`
 	OperationID     uint64 `schema:"op_id" valid:"-"`
 	LiquidityPoolID string `schema:"liquidity_pool_id" valid:"sha256,optional"`
 	TxHash          string `schema:"tx_id" valid:"transactionHash,optional"`
@@ -29,7 +29,7 @@ func (qp Joinable) IncludeTransactions() bool {
 // OperationsQuery query struct for operations end-points
 type OperationsQuery struct {
 	Joinable                  `valid:"optional"`
-	AccountID                 string `schema:"account_id" valid:"accountID,optional"`
+	AccountID                 string `schema:"account_id" valid:"muxedAccountID,optional"`
 	ClaimableBalanceID        string `schema:"claimable_balance_id" valid:"claimableBalanceID,optional"`
 	LiquidityPoolID           string `schema:"liquidity_pool_id" valid:"sha256,optional"`
 	TransactionHash           string `schema:"tx_id" valid:"transactionHash,optional"`
@@ -2,11 +2,15 @@ package actions
 
 import (
 	"net/http"
+	"strconv"
 
 	protocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
 	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/problem"
 )
 
@@ -62,6 +66,11 @@ var invalidOrderBook = problem.P{
 
 // GetOrderbookHandler is the action handler for the /order_book endpoint
 type GetOrderbookHandler struct {
+	LedgerState *ledger.State
+	// OrderBookHistory is true when the order book history is ingested, in
+	// which case the order book can be requested at a past ledger with the
+	// ledger parameter.
+	OrderBookHistory bool
 }
 
 func convertPriceLevels(src []history.PriceLevel) []protocol.PriceLevel {
@@ -95,16 +104,37 @@ func (handler GetOrderbookHandler) GetResource(w HeaderWriter, r *http.Request)
 		return nil, invalidOrderBook
 	}
 
-	historyQ, err := context.HistoryQFromRequest(r)
+	ledgerSequence, err := handler.getLedger(r)
 	if err != nil {
 		return nil, err
 	}
 
-	summary, err := historyQ.GetOrderBookSummary(r.Context(), selling, buying, int(limit))
+	historyQ, err := context.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
 
+	var summary history.OrderBookSummary
+	if ledgerSequence == 0 {
+		summary, err = historyQ.GetOrderBookSummary(r.Context(), selling, buying, int(limit))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var startLedger uint32
+		startLedger, err = historyQ.GetOrderBookHistoryStartLedger(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		if startLedger == 0 || ledgerSequence < startLedger {
+			return nil, hProblem.BeforeHistory
+		}
+		summary, err = historyQ.GetOrderBookSummaryAtLedger(r.Context(), selling, buying, ledgerSequence, int(limit))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var response OrderBookResponse
 	if err := resourceadapter.PopulateAsset(r.Context(), &response.Selling, selling); err != nil {
 		return nil, err
@@ -117,3 +147,32 @@ func (handler GetOrderbookHandler) GetResource(w HeaderWriter, r *http.Request)
 
 	return response, nil
 }
+
+// getLedger returns the ledger at which the order book was requested or 0 if
+// the current order book was requested.
+func (handler GetOrderbookHandler) getLedger(r *http.Request) (uint32, error) {
+	value, err := getString(r, "ledger")
+	if err != nil || value == "" {
+		return 0, err
+	}
+	if !handler.OrderBookHistory {
+		return 0, problem.MakeInvalidFieldProblem(
+			"ledger",
+			errors.New("the order book history is not ingested by this Horizon instance"),
+		)
+	}
+	sequence, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || sequence == 0 {
+		return 0, problem.MakeInvalidFieldProblem(
+			"ledger",
+			errors.New("ledger must be a positive ledger sequence"),
+		)
+	}
+	if latest := handler.LedgerState.CurrentStatus().HistoryLatest; sequence > uint64(latest) {
+		return 0, problem.MakeInvalidFieldProblem(
+			"ledger",
+			errors.Errorf("ledger must not be greater than the latest ingested ledger (%d)", latest),
+		)
+	}
+	return uint32(sequence), nil
+}
@@ -8,7 +8,9 @@ import (
 	"testing"
 
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/render/problem"
 	"github.com/stretchr/testify/assert"
 
 	protocol "github.com/stellar/go/protocols/horizon"
@@ -464,6 +466,69 @@ func TestOrderbookGetResourceValidation(t *testing.T) {
 	}
 }
 
+func TestOrderbookGetResourceLedgerValidation(t *testing.T) {
+	ledgerState := &ledger.State{}
+	ledgerState.SetHorizonStatus(ledger.HorizonStatus{HistoryLatest: 100})
+
+	params := map[string]string{
+		"buying_asset_type":    "native",
+		"selling_asset_type":   "credit_alphanum4",
+		"selling_asset_code":   "EUR",
+		"selling_asset_issuer": "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
+	}
+
+	for _, testCase := range []struct {
+		name             string
+		ledger           string
+		orderBookHistory bool
+		expectedError    string
+	}{
+		{
+			"order book history not ingested",
+			"50",
+			false,
+			"the order book history is not ingested by this Horizon instance",
+		},
+		{
+			"ledger is not a number",
+			"abc",
+			true,
+			"ledger must be a positive ledger sequence",
+		},
+		{
+			"ledger is zero",
+			"0",
+			true,
+			"ledger must be a positive ledger sequence",
+		},
+		{
+			"ledger is after the latest ledger",
+			"101",
+			true,
+			"ledger must not be greater than the latest ingested ledger (100)",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			queryParams := map[string]string{"ledger": testCase.ledger}
+			for key, value := range params {
+				queryParams[key] = value
+			}
+			handler := GetOrderbookHandler{
+				LedgerState:      ledgerState,
+				OrderBookHistory: testCase.orderBookHistory,
+			}
+			r := makeRequest(t, queryParams, map[string]string{}, nil)
+			_, err := handler.GetResource(httptest.NewRecorder(), r)
+			p, ok := err.(*problem.P)
+			if assert.True(t, ok) {
+				assert.Equal(t, 400, p.Status)
+				assert.Equal(t, "ledger", p.Extras["invalid_field"])
+				assert.Equal(t, testCase.expectedError, p.Extras["reason"])
+			}
+		})
+	}
+}
+
 func TestOrderbookGetResource(t *testing.T) {
 	tt := test.Start(t)
 	defer tt.Finish()
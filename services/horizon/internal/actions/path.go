@@ -38,6 +38,7 @@ type StrictReceivePathsQuery struct {
 	DestinationAssetIssuer string `schema:"destination_asset_issuer" valid:"accountID,optional"`
 	DestinationAssetCode   string `schema:"destination_asset_code" valid:"-"`
 	DestinationAmount      string `schema:"destination_amount" valid:"amount"`
+	SplitRoutes            bool   `schema:"split_routes" valid:"-"`
 }
 
 // Assets returns a list of xdr.Asset
@@ -163,7 +164,11 @@ func (handler FindPathsHandler) GetResource(w HeaderWriter, r *http.Request) (in
 	records := []paths.Path{}
 	if len(query.SourceAssets) > 0 {
 		var lastIngestedLedger uint32
-		records, lastIngestedLedger, err = handler.PathFinder.Find(ctx, query, handler.MaxPathLength)
+		if qp.SplitRoutes {
+			records, lastIngestedLedger, err = handler.PathFinder.FindSplitPaths(ctx, query, handler.MaxPathLength)
+		} else {
+			records, lastIngestedLedger, err = handler.PathFinder.Find(ctx, query, handler.MaxPathLength)
+		}
 		switch err {
 		case simplepath.ErrEmptyInMemoryOrderBook:
 			return nil, horizonProblem.StillIngesting
@@ -225,6 +230,7 @@ type FindFixedPathsQuery struct {
 	SourceAssetIssuer  string `schema:"source_asset_issuer" valid:"accountID,optional"`
 	SourceAssetCode    string `schema:"source_asset_code" valid:"-"`
 	SourceAmount       string `schema:"source_amount" valid:"amount"`
+	SplitRoutes        bool   `schema:"split_routes" valid:"-"`
 }
 
 // URITemplate returns a rfc6570 URI template for the query struct
@@ -335,7 +341,11 @@ func (handler FindFixedPathsHandler) GetResource(w HeaderWriter, r *http.Request
 	records := []paths.Path{}
 	if len(destinationAssets) > 0 {
 		var lastIngestedLedger uint32
-		records, lastIngestedLedger, err = handler.PathFinder.FindFixedPaths(
+		findFixedPaths := handler.PathFinder.FindFixedPaths
+		if qp.SplitRoutes {
+			findFixedPaths = handler.PathFinder.FindFixedSplitPaths
+		}
+		records, lastIngestedLedger, err = findFixedPaths(
 			ctx,
 			sourceAsset,
 			amountToSpend,
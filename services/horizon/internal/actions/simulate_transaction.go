@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"net/http"
+
+	"github.com/stellar/go/exp/orderbook"
+	"github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/txsim"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// SimulateTransactionHandler is the action handler for the
+// /transactions/simulate endpoint. It predicts the result codes of a
+// transaction using the ingested ledger state, without submitting it.
+type SimulateTransactionHandler struct {
+	NetworkPassphrase string
+	// OrderBookGraph is used to simulate offers and path payments, it is nil
+	// when path finding is disabled.
+	OrderBookGraph *orderbook.OrderBookGraph
+	IncludePools   bool
+}
+
+// GetResource returns the simulated result of the transaction.
+func (handler SimulateTransactionHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	if err := validateBodyType(r); err != nil {
+		return nil, err
+	}
+
+	raw, err := getString(r, "tx")
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := extractEnvelopeInfo(raw, handler.NetworkPassphrase)
+	if err != nil {
+		return nil, &problem.P{
+			Type:   "transaction_malformed",
+			Title:  "Transaction Malformed",
+			Status: http.StatusBadRequest,
+			Detail: "Horizon could not decode the transaction envelope in this " +
+				"request. A transaction should be an XDR TransactionEnvelope struct " +
+				"encoded using base64.  The envelope read from this request is " +
+				"echoed in the `extras.envelope_xdr` field of this response for your " +
+				"convenience.",
+			Extras: map[string]interface{}{
+				"envelope_xdr": raw,
+			},
+		}
+	}
+
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	lastIngested, err := historyQ.GetLastLedgerIngestNonBlocking(r.Context())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get last ingested ledger")
+	}
+	ledger, err := getLedgerBySequence(r.Context(), historyQ, int32(lastIngested))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load last ingested ledger")
+	}
+	if ledger == nil {
+		return nil, hProblem.StillIngesting
+	}
+
+	simulator := txsim.Simulator{
+		NetworkPassphrase: handler.NetworkPassphrase,
+		OrderBook:         handler.OrderBookGraph,
+		IncludePools:      handler.IncludePools,
+	}
+	result, err := simulator.Simulate(r.Context(), txsim.NewHistoryState(historyQ), txsim.Ledger{
+		Sequence:    uint32(ledger.Sequence),
+		CloseTime:   ledger.ClosedAt.Unix(),
+		BaseFee:     uint32(ledger.BaseFee),
+		BaseReserve: uint32(ledger.BaseReserve),
+	}, info.parsed)
+	if err == txsim.ErrSorobanNotSupported {
+		return nil, &problem.P{
+			Type:   "transaction_simulation_not_supported",
+			Title:  "Transaction Simulation Not Supported",
+			Status: http.StatusBadRequest,
+			Detail: "Transactions invoking Soroban host functions cannot be simulated " +
+				"by Horizon. Use the simulateTransaction method of Stellar RPC instead.",
+			Extras: map[string]interface{}{
+				"envelope_xdr": raw,
+			},
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not simulate transaction")
+	}
+
+	return horizon.TransactionSimulation{
+		Hash:       result.Hash,
+		InnerHash:  result.InnerHash,
+		Ledger:     ledger.Sequence,
+		Successful: result.Successful,
+		FeeCharged: result.FeeCharged,
+		ResultCodes: horizon.TransactionResultCodes{
+			TransactionCode:      result.TransactionCode,
+			InnerTransactionCode: result.InnerTransactionCode,
+			OperationCodes:       result.OperationCodes,
+		},
+	}, nil
+}
@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/network"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func TestSimulateTransactionMalformedTx(t *testing.T) {
+	handler := SimulateTransactionHandler{NetworkPassphrase: network.TestNetworkPassphrase}
+
+	form := url.Values{}
+	form.Set("tx", "not a transaction")
+	request, err := http.NewRequest(
+		"POST",
+		"https://horizon.stellar.org/transactions/simulate",
+		strings.NewReader(form.Encode()),
+	)
+	require.NoError(t, err)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err = handler.GetResource(httptest.NewRecorder(), request)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.(*problem.P).Status)
+	assert.Equal(t, "transaction_malformed", err.(*problem.P).Type)
+	assert.Equal(t, "not a transaction", err.(*problem.P).Extras["envelope_xdr"])
+}
+
+func TestSimulateTransactionUnsupportedMediaType(t *testing.T) {
+	handler := SimulateTransactionHandler{NetworkPassphrase: network.TestNetworkPassphrase}
+
+	request := httptest.NewRequest("POST", "https://horizon.stellar.org/transactions/simulate", strings.NewReader("{}"))
+	request.Header.Add("Content-Type", "application/json")
+
+	_, err := handler.GetResource(httptest.NewRecorder(), request)
+	assert.Equal(t, &hProblem.UnsupportedMediaType, err)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stellar/go/amount"
 	"github.com/stellar/go/services/horizon/internal/assets"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
@@ -21,10 +22,14 @@ type Validateable interface {
 
 func init() {
 	govalidator.TagMap["accountID"] = isAccountID
+	govalidator.TagMap["muxedAccountID"] = isMuxedAccountID
 	govalidator.TagMap["amount"] = isAmount
 	govalidator.TagMap["assetType"] = isAssetType
 	govalidator.TagMap["asset"] = isAsset
 	govalidator.TagMap["claimableBalanceID"] = isClaimableBalanceID
+	govalidator.TagMap["contractID"] = isContractID
+	govalidator.TagMap["contractEventType"] = isContractEventType
+	govalidator.TagMap["scVal"] = isScVal
 	govalidator.TagMap["transactionHash"] = isTransactionHash
 	govalidator.TagMap["sha256"] = govalidator.IsSHA256
 	govalidator.TagMap["tradeType"] = isTradeType
@@ -37,6 +42,10 @@ var customTagsErrorMessages = map[string]string{
 	"assetType":            "Asset type must be native, credit_alphanum4 or credit_alphanum12",
 	"bool":                 "Filter should be true or false",
 	"claimable_balance_id": "Claimable Balance ID must be the hex-encoded XDR representation of a Claimable Balance ID",
+	"contractID":           "Contract ID must start with `C` and contain 56 alphanum characters",
+	"contractEventType":    "Contract event type must be contract or system",
+	"muxedAccountID":       "Account ID must start with `G` and contain 56 alphanum characters, or start with `M` and contain 69 alphanum characters",
+	"scVal":                "Topic must be the base64-encoded XDR representation of a SCVal",
 	"ledger_id":            "Ledger ID must be an integer higher than 0",
 	"offer_id":             "Offer ID must be an integer higher than 0",
 	"op_id":                "Operation ID must be an integer higher than 0",
@@ -139,6 +148,14 @@ func isAccountID(str string) bool {
 	return true
 }
 
+func isMuxedAccountID(str string) bool {
+	if _, err := xdr.AddressToMuxedAccount(str); err != nil {
+		return false
+	}
+
+	return true
+}
+
 func isTransactionHash(str string) bool {
 	decoded, err := hex.DecodeString(str)
 	if err != nil {
@@ -160,6 +177,22 @@ func isAmount(str string) bool {
 	return true
 }
 
+func isContractID(str string) bool {
+	_, err := strkey.Decode(strkey.VersionByteContract, str)
+	return err == nil
+}
+
+func isContractEventType(str string) bool {
+	_, ok := contractEventTypes[str]
+	return ok
+}
+
+func isScVal(str string) bool {
+	var scVal xdr.ScVal
+	err := xdr.SafeUnmarshalBase64(str, &scVal)
+	return err == nil
+}
+
 func isClaimableBalanceID(str string) bool {
 	var cbID xdr.ClaimableBalanceId
 	err := xdr.SafeUnmarshalHex(str, &cbID)
@@ -100,6 +100,60 @@ func TestAccountIDValidator(t *testing.T) {
 	}
 }
 
+func TestMuxedAccountIDValidator(t *testing.T) {
+	type Query struct {
+		Account string `valid:"muxedAccountID,optional"`
+	}
+
+	for _, testCase := range []struct {
+		name          string
+		value         string
+		expectedError string
+	}{
+		{
+			"invalid muxed address",
+			"MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4P",
+			"Account: MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4P does not validate as muxedAccountID",
+		},
+		{
+			"contract address",
+			"CA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQGAXE",
+			"Account: CA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQGAXE does not validate as muxedAccountID",
+		},
+		{
+			"valid muxed address",
+			"MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQAAAAAAAAAAAAGK4O",
+			"",
+		},
+		{
+			"valid stellar address",
+			"GAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQPZW",
+			"",
+		},
+		{
+			"empty stellar address should not be validated",
+			"",
+			"",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			tt := assert.New(t)
+
+			q := Query{
+				Account: testCase.value,
+			}
+
+			result, err := govalidator.ValidateStruct(q)
+			if testCase.expectedError == "" {
+				tt.NoError(err)
+				tt.True(result)
+			} else {
+				tt.Equal(testCase.expectedError, err.Error())
+			}
+		})
+	}
+}
+
 func TestAssetValidator(t *testing.T) {
 	type Query struct {
 		Asset string `valid:"asset"`
@@ -0,0 +1,304 @@
+package actions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/operations"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+// these admin HTTP endpoints are documented in services/horizon/internal/httpx/static/admin_oapi.yml
+type WebhookHandler struct {
+	LedgerState *ledger.State
+}
+
+func (handler WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	subscription, err := handler.subscriptionRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			problem.Render(r.Context(), w, errors.Wrap(err, "could not generate secret"))
+			return
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+	if subscription.LastLedger == 0 {
+		subscription.LastLedger, err = historyQ.GetLastLedgerIngestNonBlocking(r.Context())
+		if err != nil {
+			problem.Render(r.Context(), w, err)
+			return
+		}
+	}
+
+	subscription, err = historyQ.InsertWebhookSubscription(r.Context(), subscription)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := handler.subscriptionResource(subscription)
+	// the secret is only disclosed when the subscription is created
+	responsePayload.Secret = subscription.Secret
+	w.WriteHeader(http.StatusCreated)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler WebhookHandler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	subscriptions, err := historyQ.GetWebhookSubscriptions(r.Context())
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := make([]hProtocol.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		responsePayload = append(responsePayload, handler.subscriptionResource(subscription))
+	}
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler WebhookHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	subscription, err := handler.loadSubscription(r, historyQ)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := handler.subscriptionResource(subscription)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	id, err := handler.subscriptionID(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	deleted, err := historyQ.DeleteWebhookSubscription(r.Context(), id)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	if deleted == 0 {
+		problem.Render(r.Context(), w, problem.NotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler WebhookHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	subscription, err := handler.loadSubscription(r, historyQ)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	page, err := GetPageQuery(handler.LedgerState, r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	deadLetters, err := historyQ.GetWebhookDeadLetters(r.Context(), subscription.ID, page)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := make([]hProtocol.WebhookDeadLetter, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		responsePayload = append(responsePayload, hProtocol.WebhookDeadLetter{
+			ID:             deadLetter.ID,
+			PT:             deadLetter.PagingToken(),
+			SubscriptionID: deadLetter.SubscriptionID,
+			Ledger:         deadLetter.Ledger,
+			Attempts:       deadLetter.Attempts,
+			LastError:      deadLetter.LastError,
+			Payload:        json.RawMessage(deadLetter.Payload),
+			CreatedAt:      deadLetter.CreatedAt,
+		})
+	}
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler WebhookHandler) subscriptionID(r *http.Request) (int64, error) {
+	value, err := getStringFromURLParam(r, "id")
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, problem.MakeInvalidFieldProblem("id", errors.New("invalid subscription id"))
+	}
+	return id, nil
+}
+
+func (handler WebhookHandler) loadSubscription(r *http.Request, historyQ *history.Q) (history.WebhookSubscription, error) {
+	id, err := handler.subscriptionID(r)
+	if err != nil {
+		return history.WebhookSubscription{}, err
+	}
+	subscription, err := historyQ.GetWebhookSubscriptionByID(r.Context(), id)
+	if historyQ.NoRows(err) {
+		return history.WebhookSubscription{}, problem.NotFound
+	}
+	return subscription, err
+}
+
+func (handler WebhookHandler) subscriptionRequest(r *http.Request) (history.WebhookSubscription, error) {
+	var request hProtocol.WebhookSubscription
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&request); err != nil {
+		p := problem.NewProblemWithInvalidField(problem.BadRequest, "reason", fmt.Errorf("invalid json for webhook subscription %v", err.Error()))
+		return history.WebhookSubscription{}, p
+	}
+
+	target, err := url.Parse(request.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem("url", errors.New("url must be an absolute http or https url"))
+	}
+
+	subscription := history.WebhookSubscription{
+		URL:            target.String(),
+		Secret:         request.Secret,
+		Accounts:       pq.StringArray{},
+		Assets:         pq.StringArray{},
+		OperationTypes: pq.Int32Array{},
+		EffectTypes:    pq.Int32Array{},
+		LastLedger:     request.Cursor,
+	}
+	for _, account := range request.Accounts {
+		if !strkey.IsValidEd25519PublicKey(account) {
+			return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem("accounts", fmt.Errorf("%s is not a valid account id", account))
+		}
+		subscription.Accounts = append(subscription.Accounts, account)
+	}
+	for _, asset := range request.Assets {
+		assets, err := xdr.BuildAssets(asset)
+		if err != nil || len(assets) != 1 {
+			return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem("assets", fmt.Errorf("%s is not a valid asset", asset))
+		}
+		subscription.Assets = append(subscription.Assets, assets[0].StringCanonical())
+	}
+	for _, name := range request.OperationTypes {
+		typ, ok := operationTypesByName[name]
+		if !ok {
+			return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem("operation_types", fmt.Errorf("%s is not a valid operation type", name))
+		}
+		subscription.OperationTypes = append(subscription.OperationTypes, int32(typ))
+	}
+	for _, name := range request.EffectTypes {
+		typ, ok := effectTypesByName[name]
+		if !ok {
+			return history.WebhookSubscription{}, problem.MakeInvalidFieldProblem("effect_types", fmt.Errorf("%s is not a valid effect type", name))
+		}
+		subscription.EffectTypes = append(subscription.EffectTypes, int32(typ))
+	}
+	return subscription, nil
+}
+
+func (handler WebhookHandler) subscriptionResource(subscription history.WebhookSubscription) hProtocol.WebhookSubscription {
+	resource := hProtocol.WebhookSubscription{
+		ID:             subscription.ID,
+		URL:            subscription.URL,
+		Accounts:       append([]string{}, subscription.Accounts...),
+		Assets:         append([]string{}, subscription.Assets...),
+		OperationTypes: []string{},
+		EffectTypes:    []string{},
+		Cursor:         subscription.LastLedger,
+		FailedAttempts: subscription.FailedAttempts,
+		LastError:      subscription.LastError,
+		CreatedAt:      subscription.CreatedAt,
+	}
+	for _, typ := range subscription.OperationTypes {
+		resource.OperationTypes = append(resource.OperationTypes, operations.TypeNames[xdr.OperationType(typ)])
+	}
+	for _, typ := range subscription.EffectTypes {
+		resource.EffectTypes = append(resource.EffectTypes, resourceadapter.EffectTypeNames[history.EffectType(typ)])
+	}
+	if subscription.NextAttemptAt.Valid {
+		nextAttemptAt := subscription.NextAttemptAt.Time
+		resource.NextAttemptAt = &nextAttemptAt
+	}
+	return resource
+}
+
+var operationTypesByName = func() map[string]xdr.OperationType {
+	names := map[string]xdr.OperationType{}
+	for typ, name := range operations.TypeNames {
+		names[name] = typ
+	}
+	return names
+}()
+
+var effectTypesByName = func() map[string]history.EffectType {
+	names := map[string]history.EffectType{}
+	for typ, name := range resourceadapter.EffectTypeNames {
+		names[name] = typ
+	}
+	return names
+}()
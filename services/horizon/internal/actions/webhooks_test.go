@@ -0,0 +1,129 @@
+package actions
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+func TestWebhookSubscriptionRequestValidation(t *testing.T) {
+	handler := WebhookHandler{}
+	issuer := "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+
+	for _, testCase := range []struct {
+		body  string
+		field string
+	}{
+		{`{"url": "ftp://localhost/hook"}`, "url"},
+		{`{"url": "/hook"}`, "url"},
+		{`{"url": "http://localhost/hook", "accounts": ["GABC"]}`, "accounts"},
+		{`{"url": "http://localhost/hook", "assets": ["USD"]}`, "assets"},
+		{`{"url": "http://localhost/hook", "operation_types": ["send_money"]}`, "operation_types"},
+		{`{"url": "http://localhost/hook", "effect_types": ["payment"]}`, "effect_types"},
+	} {
+		request := httptest.NewRequest("POST", "/webhooks", strings.NewReader(testCase.body))
+		_, err := handler.subscriptionRequest(request)
+		require.Error(t, err, testCase.body)
+		assert.Equal(t, testCase.field, err.(*problem.P).Extras["invalid_field"], testCase.body)
+	}
+
+	request := httptest.NewRequest("POST", "/webhooks", strings.NewReader(`{
+		"url": "https://example.com/hook",
+		"accounts": ["`+issuer+`"],
+		"assets": ["native", "USD:`+issuer+`"],
+		"operation_types": ["payment", "path_payment_strict_send"],
+		"effect_types": ["account_credited"],
+		"cursor": 100
+	}`))
+	subscription, err := handler.subscriptionRequest(request)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook", subscription.URL)
+	assert.Equal(t, []string{issuer}, []string(subscription.Accounts))
+	assert.Equal(t, []string{"native", "USD:" + issuer}, []string(subscription.Assets))
+	assert.Equal(t, []int32{
+		int32(xdr.OperationTypePayment),
+		int32(xdr.OperationTypePathPaymentStrictSend),
+	}, []int32(subscription.OperationTypes))
+	assert.Equal(t, []int32{int32(history.EffectAccountCredited)}, []int32(subscription.EffectTypes))
+	assert.Equal(t, uint32(100), subscription.LastLedger)
+}
+
+func TestWebhookSubscriptionLifecycle(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+
+	q := &history.Q{SessionInterface: tt.HorizonSession()}
+	tt.Assert.NoError(q.UpdateLastLedgerIngest(tt.Ctx, 42))
+
+	handler := WebhookHandler{LedgerState: &ledger.State{}}
+	recorder := httptest.NewRecorder()
+	request := makeRequest(t, map[string]string{}, map[string]string{}, q)
+	request.Body = ioutil.NopCloser(strings.NewReader(`{
+		"url": "http://localhost:8000/hook",
+		"operation_types": ["payment"]
+	}`))
+	handler.CreateSubscription(recorder, request)
+
+	resp := recorder.Result()
+	tt.Assert.Equal(http.StatusCreated, resp.StatusCode)
+	var created hProtocol.WebhookSubscription
+	tt.Assert.NoError(json.NewDecoder(resp.Body).Decode(&created))
+	tt.Assert.NotZero(created.ID)
+	tt.Assert.Len(created.Secret, 64)
+	tt.Assert.Equal(uint32(42), created.Cursor)
+	tt.Assert.Equal([]string{"payment"}, created.OperationTypes)
+	tt.Assert.Empty(created.Accounts)
+
+	id := map[string]string{"id": strconv.FormatInt(created.ID, 10)}
+
+	recorder = httptest.NewRecorder()
+	handler.GetSubscriptions(recorder, makeRequest(t, map[string]string{}, map[string]string{}, q))
+	var subscriptions []hProtocol.WebhookSubscription
+	tt.Assert.NoError(json.NewDecoder(recorder.Result().Body).Decode(&subscriptions))
+	tt.Assert.Len(subscriptions, 1)
+	tt.Assert.Equal(created.ID, subscriptions[0].ID)
+	tt.Assert.Empty(subscriptions[0].Secret)
+
+	tt.Assert.NoError(q.InsertWebhookDeadLetter(tt.Ctx, history.WebhookDeadLetter{
+		SubscriptionID: created.ID,
+		Ledger:         43,
+		Payload:        `{"ledger":43}`,
+		Attempts:       10,
+		LastError:      "unexpected status code 500",
+	}))
+	recorder = httptest.NewRecorder()
+	handler.GetDeadLetters(recorder, makeRequest(t, map[string]string{}, id, q))
+	tt.Assert.Equal(http.StatusOK, recorder.Result().StatusCode)
+	var deadLetters []hProtocol.WebhookDeadLetter
+	tt.Assert.NoError(json.NewDecoder(recorder.Result().Body).Decode(&deadLetters))
+	tt.Assert.Len(deadLetters, 1)
+	tt.Assert.Equal(uint32(43), deadLetters[0].Ledger)
+	tt.Assert.JSONEq(`{"ledger":43}`, string(deadLetters[0].Payload))
+
+	recorder = httptest.NewRecorder()
+	handler.DeleteSubscription(recorder, makeRequest(t, map[string]string{}, id, q))
+	tt.Assert.Equal(http.StatusNoContent, recorder.Result().StatusCode)
+
+	recorder = httptest.NewRecorder()
+	handler.GetSubscription(recorder, makeRequest(t, map[string]string{}, id, q))
+	tt.Assert.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+
+	recorder = httptest.NewRecorder()
+	handler.DeleteSubscription(recorder, makeRequest(t, map[string]string{}, id, q))
+	tt.Assert.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+}
@@ -684,6 +684,98 @@ func TestPathActionsStrictSend(t *testing.T) {
 	finder.AssertExpectations(t)
 }
 
+func TestPathActionsSplitRoutes(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	assertions := &test.Assertions{tt.Assert}
+
+	usd := xdr.MustNewCreditAsset("USD", "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN")
+	eur := xdr.MustNewCreditAsset("EUR", "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN")
+	chf := xdr.MustNewCreditAsset("CHF", "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN")
+	split := paths.Path{
+		Path:              []string{eur.String()},
+		Source:            usd.String(),
+		SourceAmount:      200000000,
+		Destination:       xdr.MustNewNativeAsset().String(),
+		DestinationAmount: 100000000,
+		Routes: []paths.Path{
+			{
+				Path:              []string{eur.String()},
+				Source:            usd.String(),
+				SourceAmount:      120000000,
+				Destination:       xdr.MustNewNativeAsset().String(),
+				DestinationAmount: 60000000,
+			},
+			{
+				Path:              []string{chf.String()},
+				Source:            usd.String(),
+				SourceAmount:      80000000,
+				Destination:       xdr.MustNewNativeAsset().String(),
+				DestinationAmount: 40000000,
+			},
+		},
+	}
+
+	finder := paths.MockFinder{}
+	finder.On("FindSplitPaths", mock.Anything, mock.Anything, uint(3)).
+		Return([]paths.Path{split}, uint32(1234), nil).Once()
+	finder.On("FindFixedSplitPaths", mock.Anything, usd, xdr.Int64(200000000), []xdr.Asset{xdr.MustNewNativeAsset()}, uint(3)).
+		Return([]paths.Path{split}, uint32(1234), nil).Once()
+
+	rh := mockPathFindingClient(
+		tt,
+		&finder,
+		2,
+		tt.HorizonSession(),
+	)
+
+	assertSplitPath := func(records []horizon.Path) {
+		tt.Assert.Len(records, 1)
+		tt.Assert.Equal("20.0000000", records[0].SourceAmount)
+		tt.Assert.Equal("10.0000000", records[0].DestinationAmount)
+		tt.Assert.Equal("0.5000000", records[0].Price)
+		tt.Assert.Len(records[0].Path, 1)
+		tt.Assert.Equal("EUR", records[0].Path[0].Code)
+		tt.Assert.Len(records[0].Routes, 2)
+		tt.Assert.Equal("12.0000000", records[0].Routes[0].SourceAmount)
+		tt.Assert.Equal("6.0000000", records[0].Routes[0].DestinationAmount)
+		tt.Assert.Equal("CHF", records[0].Routes[1].Path[0].Code)
+		tt.Assert.Equal("8.0000000", records[0].Routes[1].SourceAmount)
+		tt.Assert.Equal("4.0000000", records[0].Routes[1].DestinationAmount)
+	}
+
+	var q = make(url.Values)
+	q.Add("source_assets", assetsToURLParam([]xdr.Asset{usd}))
+	q.Add("destination_asset_type", "native")
+	q.Add("destination_amount", "10")
+	q.Add("split_routes", "true")
+
+	w := rh.Get("/paths/strict-receive?" + q.Encode())
+	assertions.Equal(http.StatusOK, w.Code)
+	assertions.Equal("1234", w.Header().Get(actions.LastLedgerHeaderName))
+	records := []horizon.Path{}
+	tt.UnmarshalPage(w.Body, &records)
+	assertSplitPath(records)
+
+	q = make(url.Values)
+	q.Add("destination_assets", "native")
+	q.Add("source_asset_issuer", "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN")
+	q.Add("source_asset_type", "credit_alphanum4")
+	q.Add("source_asset_code", "USD")
+	q.Add("source_amount", "20")
+	q.Add("split_routes", "true")
+
+	w = rh.Get("/paths/strict-send?" + q.Encode())
+	assertions.Equal(http.StatusOK, w.Code)
+	assertions.Equal("1234", w.Header().Get(actions.LastLedgerHeaderName))
+	records = []horizon.Path{}
+	tt.UnmarshalPage(w.Body, &records)
+	assertSplitPath(records)
+
+	finder.AssertExpectations(t)
+}
+
 func assetsToURLParam(xdrAssets []xdr.Asset) string {
 	var assets []string
 	for _, xdrAsset := range xdrAssets {
@@ -708,6 +800,7 @@ func TestFindFixedPathsQueryQueryURLTemplate(t *testing.T) {
 		"source_asset_issuer",
 		"source_asset_code",
 		"source_amount",
+		"split_routes",
 	}
 	expected := "/paths/strict-send{?" + strings.Join(params, ",") + "}"
 	qp := actions.FindFixedPathsQuery{}
@@ -724,6 +817,7 @@ func TestStrictReceivePathsQueryURLTemplate(t *testing.T) {
 		"destination_asset_issuer",
 		"destination_asset_code",
 		"destination_amount",
+		"split_routes",
 	}
 	expected := "/paths/strict-receive{?" + strings.Join(params, ",") + "}"
 	qp := actions.StrictReceivePathsQuery{}
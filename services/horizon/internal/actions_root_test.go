@@ -63,6 +63,7 @@ func TestRootAction(t *testing.T) {
 			"source_asset_issuer",
 			"source_asset_code",
 			"source_amount",
+			"split_routes",
 		}
 
 		ht.Assert.Equal(
@@ -78,6 +79,7 @@ func TestRootAction(t *testing.T) {
 			"destination_asset_issuer",
 			"destination_asset_code",
 			"destination_amount",
+			"split_routes",
 		}
 
 		ht.Assert.Equal(
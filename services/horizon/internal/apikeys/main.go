@@ -0,0 +1,367 @@
+// Package apikeys authenticates Horizon clients with API keys and enforces the
+// limits of every key: the request rate, the number of concurrent streams and
+// the rate of path finding requests. Keys are managed on the admin port and
+// stored in the Horizon database, every instance keeps them in memory and
+// reloads them periodically.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stellar/throttled"
+	"golang.org/x/time/rate"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	logpkg "github.com/stellar/go/support/log"
+)
+
+const (
+	// Header holds the API key of a request.
+	Header = "X-Api-Key"
+
+	defaultRefreshInterval = 10 * time.Second
+	// maxRequestBurst is the number of requests of a key which are allowed at
+	// once, on top of its hourly rate. It matches the burst of the per ip
+	// address limit (--per-hour-rate-limit).
+	maxRequestBurst = 100
+	// varyByPrefix prefixes the rate limiting keys of the requests
+	// authenticated with an API key, see VaryBy.
+	varyByPrefix = "api-key:"
+)
+
+var log = logpkg.DefaultLogger.WithField("service", "apikeys")
+
+// unlimited is the result of the rate limiting of requests which are not
+// subject to any limit, it does not set any X-RateLimit-* header.
+var unlimited = throttled.RateLimitResult{Limit: -1, Remaining: -1, ResetAfter: -1, RetryAfter: -1}
+
+// Generate returns a new random API key and the hash which is stored in the
+// database.
+func Generate() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", errors.Wrap(err, "could not generate api key")
+	}
+	key := hex.EncodeToString(raw)
+	return key, Hash(key), nil
+}
+
+// Hash returns the hex encoded SHA-256 hash of an API key.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Store provides the API keys loaded by the Registry.
+type Store interface {
+	GetAPIKeys(ctx context.Context) ([]history.APIKey, error)
+}
+
+// Config configures the Registry, zero values are replaced by defaults.
+type Config struct {
+	// RefreshInterval is how often the keys are reloaded from the database.
+	RefreshInterval time.Duration
+}
+
+type metrics struct {
+	requests    *prometheus.CounterVec
+	rejected    *prometheus.CounterVec
+	openStreams *prometheus.GaugeVec
+}
+
+// Registry holds the API keys and the state of their limits.
+type Registry struct {
+	config  Config
+	store   Store
+	logger  *logpkg.Entry
+	metrics metrics
+
+	lock   sync.RWMutex
+	byHash map[string]*Key
+	byID   map[int64]*Key
+}
+
+// NewRegistry creates a new Registry loading the keys from the database.
+func NewRegistry(config Config, dbSession db.SessionInterface) *Registry {
+	return newRegistry(config, &history.Q{dbSession.Clone()})
+}
+
+func newRegistry(config Config, store Store) *Registry {
+	if config.RefreshInterval == 0 {
+		config.RefreshInterval = defaultRefreshInterval
+	}
+	return &Registry{
+		config: config,
+		store:  store,
+		logger: log.WithField("subservice", "registry"),
+		metrics: metrics{
+			requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "horizon", Subsystem: "api_keys", Name: "requests_total",
+				Help: "number of requests authenticated with an api key, by key",
+			}, []string{"key"}),
+			rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "horizon", Subsystem: "api_keys", Name: "rejected_requests_total",
+				Help: "number of requests rejected by the limits of an api key, by key and limit (requests, streams or path_finding)",
+			}, []string{"key", "limit"}),
+			openStreams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Namespace: "horizon", Subsystem: "api_keys", Name: "open_streams",
+				Help: "number of open streams authenticated with an api key, by key",
+			}, []string{"key"}),
+		},
+		byHash: map[string]*Key{},
+		byID:   map[int64]*Key{},
+	}
+}
+
+// RegisterMetrics registers the prometheus metrics
+func (r *Registry) RegisterMetrics(registry *prometheus.Registry) {
+	registry.MustRegister(r.metrics.requests, r.metrics.rejected, r.metrics.openStreams)
+}
+
+// Run reloads the keys until the context is cancelled.
+func (r *Registry) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+			r.logger.WithError(err).Error("could not refresh api keys")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			r.logger.Info("shutting down api key registry")
+			return
+		}
+	}
+}
+
+// Refresh reloads the keys from the database. The state of the limits of the
+// keys which did not change is preserved, and the open streams of a key are
+// still counted when its limits change.
+func (r *Registry) Refresh(ctx context.Context) error {
+	rows, err := r.store.GetAPIKeys(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not load api keys")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	byHash := make(map[string]*Key, len(rows))
+	byID := make(map[int64]*Key, len(rows))
+	names := map[string]bool{}
+	for _, row := range rows {
+		previous := r.byID[row.ID]
+		key := previous
+		if previous == nil || !previous.matches(row) {
+			key, err = newKey(row, &r.metrics)
+			if err != nil {
+				return err
+			}
+			if previous != nil {
+				key.streams = previous.streams
+			}
+		}
+		byHash[row.KeyHash] = key
+		byID[row.ID] = key
+		names[row.Name] = true
+	}
+	for _, key := range r.byID {
+		if !names[key.row.Name] {
+			r.metrics.requests.DeleteLabelValues(key.row.Name)
+			r.metrics.openStreams.DeleteLabelValues(key.row.Name)
+			r.metrics.rejected.DeletePartialMatch(prometheus.Labels{"key": key.row.Name})
+		}
+	}
+	r.byHash = byHash
+	r.byID = byID
+	return nil
+}
+
+// Authenticate returns the key matching the API key sent by a client, or nil
+// if there is no such key.
+func (r *Registry) Authenticate(apiKey string) *Key {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.byHash[Hash(apiKey)]
+}
+
+func (r *Registry) keyByID(id int64) *Key {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.byID[id]
+}
+
+// VaryBy returns the rate limiting key of the requests: the API key the
+// request was authenticated with, or the key returned by anonymous for
+// anonymous requests.
+func VaryBy(anonymous interface{ Key(*http.Request) string }) interface{ Key(*http.Request) string } {
+	return varyBy{anonymous: anonymous}
+}
+
+type varyBy struct {
+	anonymous interface{ Key(*http.Request) string }
+}
+
+func (v varyBy) Key(r *http.Request) string {
+	if key := FromContext(r.Context()); key != nil {
+		return varyByPrefix + strconv.FormatInt(key.row.ID, 10)
+	}
+	return v.anonymous.Key(r)
+}
+
+// RateLimiter returns a rate limiter which limits the requests authenticated
+// with an API key (see VaryBy) by the request rate of the key and the other
+// requests with anonymous, anonymous requests are not limited if anonymous is
+// nil.
+func (r *Registry) RateLimiter(anonymous throttled.RateLimiter) throttled.RateLimiter {
+	return rateLimiter{registry: r, anonymous: anonymous}
+}
+
+type rateLimiter struct {
+	registry  *Registry
+	anonymous throttled.RateLimiter
+}
+
+func (l rateLimiter) RateLimit(key string, quantity int) (bool, throttled.RateLimitResult, error) {
+	if value, ok := strings.CutPrefix(key, varyByPrefix); ok {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, unlimited, errors.Wrapf(err, "invalid rate limiting key %s", key)
+		}
+		apiKey := l.registry.keyByID(id)
+		if apiKey == nil {
+			// the key was deleted while its stream was open
+			return true, unlimited, nil
+		}
+		return apiKey.rateLimit(quantity)
+	}
+	if l.anonymous == nil {
+		return false, unlimited, nil
+	}
+	return l.anonymous.RateLimit(key, quantity)
+}
+
+// Key is an API key and the state of its limits.
+type Key struct {
+	row         history.APIKey
+	metrics     *metrics
+	requests    throttled.RateLimiter
+	pathFinding *rate.Limiter
+	streams     *atomic.Int32
+}
+
+func newKey(row history.APIKey, metrics *metrics) (*Key, error) {
+	key := &Key{
+		row:     row,
+		metrics: metrics,
+		streams: &atomic.Int32{},
+	}
+	if row.RequestsPerHour > 0 {
+		burst := maxRequestBurst
+		if int(row.RequestsPerHour) <= burst {
+			burst = int(row.RequestsPerHour) - 1
+		}
+		requests, err := throttled.NewGCRARateLimiter(1, throttled.RateQuota{
+			MaxRate:  throttled.PerHour(int(row.RequestsPerHour)),
+			MaxBurst: burst,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not create rate limiter of api key %s", row.Name)
+		}
+		key.requests = requests
+	}
+	if row.PathFindingPerSecond > 0 {
+		key.pathFinding = rate.NewLimiter(rate.Limit(row.PathFindingPerSecond), int(row.PathFindingPerSecond))
+	}
+	return key, nil
+}
+
+// matches returns true if row holds the same key and limits as k.
+func (k *Key) matches(row history.APIKey) bool {
+	return k.row.ID == row.ID &&
+		k.row.Name == row.Name &&
+		k.row.KeyHash == row.KeyHash &&
+		k.row.RequestsPerHour == row.RequestsPerHour &&
+		k.row.MaxStreams == row.MaxStreams &&
+		k.row.PathFindingPerSecond == row.PathFindingPerSecond
+}
+
+// Name returns the name of the key.
+func (k *Key) Name() string {
+	return k.row.Name
+}
+
+// CountRequest records a request authenticated with the key.
+func (k *Key) CountRequest() {
+	k.metrics.requests.WithLabelValues(k.row.Name).Inc()
+}
+
+func (k *Key) rateLimit(quantity int) (bool, throttled.RateLimitResult, error) {
+	if k.requests == nil {
+		return false, unlimited, nil
+	}
+	limited, result, err := k.requests.RateLimit(k.row.Name, quantity)
+	if limited {
+		k.metrics.rejected.WithLabelValues(k.row.Name, "requests").Inc()
+	}
+	return limited, result, err
+}
+
+// AcquireStream reserves one of the concurrent streams of the key. It returns
+// false if all the streams of the key are open, otherwise ReleaseStream must
+// be called once the stream is closed.
+func (k *Key) AcquireStream() bool {
+	open := k.streams.Add(1)
+	if k.row.MaxStreams > 0 && open > k.row.MaxStreams {
+		k.streams.Add(-1)
+		k.metrics.rejected.WithLabelValues(k.row.Name, "streams").Inc()
+		return false
+	}
+	k.metrics.openStreams.WithLabelValues(k.row.Name).Set(float64(open))
+	return true
+}
+
+// ReleaseStream releases a stream reserved by AcquireStream.
+func (k *Key) ReleaseStream() {
+	open := k.streams.Add(-1)
+	k.metrics.openStreams.WithLabelValues(k.row.Name).Set(float64(open))
+}
+
+// AllowPathFinding returns false if the path finding rate of the key is
+// exceeded.
+func (k *Key) AllowPathFinding() bool {
+	if k.pathFinding == nil || k.pathFinding.Allow() {
+		return true
+	}
+	k.metrics.rejected.WithLabelValues(k.row.Name, "path_finding").Inc()
+	return false
+}
+
+type contextKey struct{}
+
+// WithKey returns a copy of ctx holding the key a request was authenticated
+// with.
+func WithKey(ctx context.Context, key *Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// FromContext returns the key a request was authenticated with, or nil for
+// anonymous requests.
+func FromContext(ctx context.Context) *Key {
+	key, _ := ctx.Value(contextKey{}).(*Key)
+	return key
+}
@@ -0,0 +1,214 @@
+package apikeys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stellar/throttled"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/paths"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+type testStore struct {
+	keys []history.APIKey
+	err  error
+}
+
+func (s *testStore) GetAPIKeys(ctx context.Context) ([]history.APIKey, error) {
+	return s.keys, s.err
+}
+
+type testVaryBy struct{}
+
+func (testVaryBy) Key(r *http.Request) string {
+	return r.RemoteAddr
+}
+
+type testRateLimiter struct {
+	keys []string
+}
+
+func (l *testRateLimiter) RateLimit(key string, quantity int) (bool, throttled.RateLimitResult, error) {
+	l.keys = append(l.keys, key)
+	return true, unlimited, nil
+}
+
+func TestGenerate(t *testing.T) {
+	key, hash, err := Generate()
+	require.NoError(t, err)
+	assert.Len(t, key, 64)
+	assert.Equal(t, Hash(key), hash)
+	assert.NotEqual(t, key, hash)
+
+	other, _, err := Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestRefresh(t *testing.T) {
+	store := &testStore{keys: []history.APIKey{
+		{ID: 1, Name: "payments", KeyHash: Hash("key1"), MaxStreams: 1},
+		{ID: 2, Name: "wallet", KeyHash: Hash("key2")},
+	}}
+	registry := newRegistry(Config{}, store)
+	assert.Nil(t, registry.Authenticate("key1"))
+
+	require.NoError(t, registry.Refresh(context.Background()))
+	payments := registry.Authenticate("key1")
+	require.NotNil(t, payments)
+	assert.Equal(t, "payments", payments.Name())
+	assert.Equal(t, "wallet", registry.Authenticate("key2").Name())
+	assert.Nil(t, registry.Authenticate("key3"))
+	assert.True(t, payments.AcquireStream())
+
+	// unchanged keys are kept with the state of their limits
+	require.NoError(t, registry.Refresh(context.Background()))
+	assert.Same(t, payments, registry.Authenticate("key1"))
+
+	// open streams are still counted when the limits change
+	store.keys[0].MaxStreams = 2
+	require.NoError(t, registry.Refresh(context.Background()))
+	updated := registry.Authenticate("key1")
+	assert.NotSame(t, payments, updated)
+	assert.True(t, updated.AcquireStream())
+	assert.False(t, updated.AcquireStream())
+	payments.ReleaseStream()
+	assert.True(t, updated.AcquireStream())
+
+	store.keys = store.keys[1:]
+	require.NoError(t, registry.Refresh(context.Background()))
+	assert.Nil(t, registry.Authenticate("key1"))
+
+	// the keys are kept when they cannot be loaded
+	store.err = errors.New("connection refused")
+	assert.EqualError(t, registry.Refresh(context.Background()), "could not load api keys: connection refused")
+	assert.NotNil(t, registry.Authenticate("key2"))
+}
+
+func TestStreamLimit(t *testing.T) {
+	registry := newRegistry(Config{}, &testStore{keys: []history.APIKey{
+		{ID: 1, Name: "payments", KeyHash: Hash("key1"), MaxStreams: 2},
+		{ID: 2, Name: "wallet", KeyHash: Hash("key2")},
+	}})
+	require.NoError(t, registry.Refresh(context.Background()))
+
+	payments := registry.Authenticate("key1")
+	assert.True(t, payments.AcquireStream())
+	assert.True(t, payments.AcquireStream())
+	assert.False(t, payments.AcquireStream())
+	assert.Equal(t, float64(2), testutil.ToFloat64(registry.metrics.openStreams.WithLabelValues("payments")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(registry.metrics.rejected.WithLabelValues("payments", "streams")))
+	payments.ReleaseStream()
+	assert.True(t, payments.AcquireStream())
+
+	wallet := registry.Authenticate("key2")
+	for i := 0; i < 100; i++ {
+		assert.True(t, wallet.AcquireStream())
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	registry := newRegistry(Config{}, &testStore{keys: []history.APIKey{
+		{ID: 1, Name: "payments", KeyHash: Hash("key1"), RequestsPerHour: 2},
+		{ID: 2, Name: "wallet", KeyHash: Hash("key2")},
+	}})
+	require.NoError(t, registry.Refresh(context.Background()))
+	anonymous := &testRateLimiter{}
+	limiter := registry.RateLimiter(anonymous)
+	varyBy := VaryBy(testVaryBy{})
+
+	request := httptest.NewRequest(http.MethodGet, "/ledgers", nil)
+	request.RemoteAddr = "10.0.0.1"
+	key := varyBy.Key(request)
+	assert.Equal(t, "10.0.0.1", key)
+	limited, _, err := limiter.RateLimit(key, 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.Equal(t, []string{"10.0.0.1"}, anonymous.keys)
+
+	payments := request.WithContext(WithKey(request.Context(), registry.Authenticate("key1")))
+	key = varyBy.Key(payments)
+	assert.Equal(t, "api-key:1", key)
+	for i := 0; i < 2; i++ {
+		limited, result, err := limiter.RateLimit(key, 1)
+		require.NoError(t, err)
+		assert.False(t, limited)
+		assert.Equal(t, 2, result.Limit)
+	}
+	limited, _, err = limiter.RateLimit(key, 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.Equal(t, float64(1), testutil.ToFloat64(registry.metrics.rejected.WithLabelValues("payments", "requests")))
+	assert.Len(t, anonymous.keys, 1)
+
+	wallet := request.WithContext(WithKey(request.Context(), registry.Authenticate("key2")))
+	for i := 0; i < 10; i++ {
+		limited, result, err := limiter.RateLimit(varyBy.Key(wallet), 1)
+		require.NoError(t, err)
+		assert.False(t, limited)
+		assert.Equal(t, -1, result.Limit)
+	}
+
+	// the requests of deleted keys are limited
+	limited, _, err = limiter.RateLimit("api-key:3", 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+
+	// anonymous requests are not limited without anonymous limiter
+	limited, _, err = registry.RateLimiter(nil).RateLimit("10.0.0.1", 1)
+	require.NoError(t, err)
+	assert.False(t, limited)
+}
+
+func TestPathFinder(t *testing.T) {
+	registry := newRegistry(Config{}, &testStore{keys: []history.APIKey{
+		{ID: 1, Name: "payments", KeyHash: Hash("key1"), PathFindingPerSecond: 1},
+	}})
+	require.NoError(t, registry.Refresh(context.Background()))
+	mockFinder := &paths.MockFinder{}
+	mockFinder.On("Find", mock.Anything, paths.Query{}, uint(3)).Return([]paths.Path{}, uint32(1), nil)
+	finder := NewPathFinder(mockFinder)
+	ctx := WithKey(context.Background(), registry.Authenticate("key1"))
+
+	_, _, err := finder.Find(ctx, paths.Query{}, 3)
+	assert.NoError(t, err)
+	_, _, err = finder.Find(ctx, paths.Query{}, 3)
+	assert.Equal(t, ErrPathFindingLimitExceeded, err)
+	_, _, err = finder.FindFixedPaths(ctx, xdr.MustNewNativeAsset(), 10, nil, 3)
+	assert.Equal(t, ErrPathFindingLimitExceeded, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(registry.metrics.rejected.WithLabelValues("payments", "path_finding")))
+
+	// anonymous requests are not limited
+	for i := 0; i < 5; i++ {
+		_, _, err = finder.Find(context.Background(), paths.Query{}, 3)
+		assert.NoError(t, err)
+	}
+	mockFinder.AssertNumberOfCalls(t, "Find", 6)
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	registry := newRegistry(Config{}, &testStore{keys: []history.APIKey{
+		{ID: 1, Name: "payments", KeyHash: Hash("key1")},
+	}})
+	prometheusRegistry := prometheus.NewRegistry()
+	registry.RegisterMetrics(prometheusRegistry)
+	require.NoError(t, registry.Refresh(context.Background()))
+
+	registry.Authenticate("key1").CountRequest()
+	assert.Equal(t, float64(1), testutil.ToFloat64(registry.metrics.requests.WithLabelValues("payments")))
+
+	// the metrics of deleted keys are removed
+	registry.store = &testStore{}
+	require.NoError(t, registry.Refresh(context.Background()))
+	assert.Equal(t, 0, testutil.CollectAndCount(registry.metrics.requests))
+}
@@ -0,0 +1,75 @@
+package apikeys
+
+import (
+	"context"
+
+	"github.com/stellar/go/services/horizon/internal/paths"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrPathFindingLimitExceeded indicates that the path finding rate of the API
+// key of the request is exceeded.
+var ErrPathFindingLimitExceeded = errors.New("Path finding rate limit of the API key exceeded")
+
+// PathFinder is a paths.Finder which enforces the path finding rate of the
+// API key of the requests, requests without API key are not limited.
+type PathFinder struct {
+	paths.Finder
+}
+
+// NewPathFinder constructs a new PathFinder wrapping finder.
+func NewPathFinder(finder paths.Finder) PathFinder {
+	return PathFinder{Finder: finder}
+}
+
+func allowPathFinding(ctx context.Context) error {
+	if key := FromContext(ctx); key != nil && !key.AllowPathFinding() {
+		return ErrPathFindingLimitExceeded
+	}
+	return nil
+}
+
+// Find implements the paths.Finder interface
+func (f PathFinder) Find(ctx context.Context, q paths.Query, maxLength uint) ([]paths.Path, uint32, error) {
+	if err := allowPathFinding(ctx); err != nil {
+		return nil, 0, err
+	}
+	return f.Finder.Find(ctx, q, maxLength)
+}
+
+// FindFixedPaths implements the paths.Finder interface
+func (f PathFinder) FindFixedPaths(
+	ctx context.Context,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAssets []xdr.Asset,
+	maxLength uint,
+) ([]paths.Path, uint32, error) {
+	if err := allowPathFinding(ctx); err != nil {
+		return nil, 0, err
+	}
+	return f.Finder.FindFixedPaths(ctx, sourceAsset, amountToSpend, destinationAssets, maxLength)
+}
+
+// FindSplitPaths implements the paths.Finder interface
+func (f PathFinder) FindSplitPaths(ctx context.Context, q paths.Query, maxLength uint) ([]paths.Path, uint32, error) {
+	if err := allowPathFinding(ctx); err != nil {
+		return nil, 0, err
+	}
+	return f.Finder.FindSplitPaths(ctx, q, maxLength)
+}
+
+// FindFixedSplitPaths implements the paths.Finder interface
+func (f PathFinder) FindFixedSplitPaths(
+	ctx context.Context,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAssets []xdr.Asset,
+	maxLength uint,
+) ([]paths.Path, uint32, error) {
+	if err := allowPathFinding(ctx); err != nil {
+		return nil, 0, err
+	}
+	return f.Finder.FindFixedSplitPaths(ctx, sourceAsset, amountToSpend, destinationAssets, maxLength)
+}
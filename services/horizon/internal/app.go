@@ -15,6 +15,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/stellar/go/clients/stellarcore"
+	"github.com/stellar/go/exp/orderbook"
+	"github.com/stellar/go/services/horizon/internal/apikeys"
 	"github.com/stellar/go/services/horizon/internal/corestate"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/httpx"
@@ -23,6 +25,7 @@ import (
 	"github.com/stellar/go/services/horizon/internal/operationfeestats"
 	"github.com/stellar/go/services/horizon/internal/paths"
 	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/services/horizon/internal/webhooks"
 	"github.com/stellar/go/support/app"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
@@ -43,9 +46,12 @@ type App struct {
 	horizonVersion  string
 	coreState       corestate.Store
 	orderBookStream *ingest.OrderBookStream
+	orderBookGraph  *orderbook.OrderBookGraph
 	submitter       *txsub.System
 	paths           paths.Finder
 	ingester        ingest.System
+	webhooks        *webhooks.Dispatcher
+	apiKeys         *apikeys.Registry
 	ticks           *time.Ticker
 	ledgerState     *ledger.State
 
@@ -92,6 +98,12 @@ func (a *App) Serve() error {
 	if !a.config.DisablePathFinding {
 		go a.orderBookStream.Run(a.ctx)
 	}
+	if a.webhooks != nil {
+		go a.webhooks.Run(a.ctx)
+	}
+	if a.apiKeys != nil {
+		go a.apiKeys.Run(a.ctx)
+	}
 
 	// WaitGroup for all go routines. Makes sure that DB is closed when
 	// all services gracefully shutdown.
@@ -487,8 +499,14 @@ func (a *App) init() error {
 		// ingester
 		initIngester(a)
 	}
+	// api keys, the path finder enforces their path finding limits
+	initAPIKeys(a)
+
 	initPathFinder(a)
 
+	// webhooks
+	initWebhooks(a)
+
 	// txsub
 	initSubmissionSystem(a)
 
@@ -523,6 +541,8 @@ func (a *App) init() error {
 		MaxPathLength:           a.config.MaxPathLength,
 		MaxAssetsPerPathRequest: a.config.MaxAssetsPerPathRequest,
 		PathFinder:              a.paths,
+		OrderBookGraph:          a.orderBookGraph,
+		DisablePoolPathFinding:  a.config.DisablePoolPathFinding,
 		PrometheusRegistry:      a.prometheusRegistry,
 		CoreGetter:              a,
 		HorizonVersion:          a.horizonVersion,
@@ -538,7 +558,15 @@ func (a *App) init() error {
 			},
 			cache: newHealthCache(healthCacheTTL),
 		},
-		SkipTxMeta: a.config.SkipTxmeta,
+		SkipTxMeta:             a.config.SkipTxmeta,
+		IngestContractEvents:   a.config.IngestContractEvents,
+		IngestBalanceHistory:   a.config.IngestBalanceHistory,
+		IngestOrderBookHistory: a.config.IngestOrderBookHistory,
+		EnableWebhooks:         a.config.EnableWebhooks,
+		EnableGraphQL:          a.config.EnableGraphQL,
+		ExportMaxRows:          a.config.ExportMaxRows,
+		ExportRateQuota:        a.config.ExportRateQuota,
+		APIKeys:                a.apiKeys,
 	}
 
 	if a.primaryHistoryQ != nil {
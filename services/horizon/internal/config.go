@@ -125,4 +125,20 @@ type Config struct {
 	DisableTxSub bool
 	// SkipTxmeta, when enabled, will not store meta xdr in history transaction table
 	SkipTxmeta bool
+	// IngestContractEvents, when enabled, will store the events emitted by Soroban transactions in the history contract events table
+	IngestContractEvents bool
+	// IngestBalanceHistory, when enabled, will store the balances of accounts at the end of every ledger in which they changed in the history account balances table
+	IngestBalanceHistory bool
+	// IngestOrderBookHistory, when enabled, will store the offers and liquidity pool reserves at the end of every ledger in which they changed in the order book history tables
+	IngestOrderBookHistory bool
+	// EnableWebhooks, when enabled, will deliver the operations and effects of ingested ledgers to the webhook subscriptions
+	EnableWebhooks bool
+	// EnableGraphQL, when enabled, will serve the GraphQL API at /graphql
+	EnableGraphQL bool
+	// EnableAPIKeys, when enabled, will authenticate requests with the API keys managed on the admin port and enforce their limits
+	EnableAPIKeys bool
+	// ExportMaxRows is the max count of records of an account history export, 0 disables the exports
+	ExportMaxRows uint
+	// ExportRateQuota limits the account history exports by remote ip address, nil disables the limit
+	ExportRateQuota *throttled.RateQuota
 }
@@ -0,0 +1,185 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountBalanceHistory is a row of data from the `history_account_balances`
+// table joined with the asset of the balance. Each row holds the balance of
+// an asset held by an account at the end of a ledger in which it changed.
+type AccountBalanceHistory struct {
+	HistoryLedgerID int64     `db:"history_ledger_id"`
+	LedgerCloseTime time.Time `db:"ledger_closed_at"`
+	HistoryAssetID  int64     `db:"history_asset_id"`
+	Balance         int64     `db:"balance"`
+	AssetType       string    `db:"asset_type"`
+	AssetCode       string    `db:"asset_code"`
+	AssetIssuer     string    `db:"asset_issuer"`
+}
+
+// PagingToken returns a cursor for this balance history record
+func (r AccountBalanceHistory) PagingToken() string {
+	return fmt.Sprintf("%d-%d", r.HistoryLedgerID, r.HistoryAssetID)
+}
+
+// LedgerSequence returns the ledger at the end of which the account held the
+// balance.
+func (r AccountBalanceHistory) LedgerSequence() int32 {
+	return toid.Parse(r.HistoryLedgerID).LedgerSequence
+}
+
+// AccountBalanceHistoryQuery holds the filters of an account balance history
+// query. Empty fields match all balances of the account.
+type AccountBalanceHistoryQuery struct {
+	Account string
+	Asset   *xdr.Asset
+	// StartLedger and EndLedger bound (inclusively) the ledgers in which the
+	// balances changed.
+	StartLedger uint32
+	EndLedger   uint32
+	// StartTime and EndTime bound the close time of the ledgers in which the
+	// balances changed. StartTime is inclusive and EndTime is exclusive.
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// QAccountBalanceHistory defines history_account_balances related queries.
+type QAccountBalanceHistory interface {
+	NewAccountBalanceBatchInsertBuilder() AccountBalanceBatchInsertBuilder
+}
+
+// AccountBalanceBatchInsertBuilder is used to insert account balances into
+// the history_account_balances table
+type AccountBalanceBatchInsertBuilder interface {
+	Add(
+		ledgerID int64,
+		ledgerCloseTime time.Time,
+		accountID FutureAccountID,
+		assetID FutureAssetID,
+		balance int64,
+	) error
+	Exec(ctx context.Context, session db.SessionInterface) error
+}
+
+// accountBalanceBatchInsertBuilder is a simple wrapper around db.FastBatchInsertBuilder
+type accountBalanceBatchInsertBuilder struct {
+	table   string
+	builder db.FastBatchInsertBuilder
+}
+
+// NewAccountBalanceBatchInsertBuilder constructs a new AccountBalanceBatchInsertBuilder instance
+func (q *Q) NewAccountBalanceBatchInsertBuilder() AccountBalanceBatchInsertBuilder {
+	return &accountBalanceBatchInsertBuilder{
+		table:   "history_account_balances",
+		builder: db.FastBatchInsertBuilder{},
+	}
+}
+
+// Add adds an account balance to the batch
+func (i *accountBalanceBatchInsertBuilder) Add(
+	ledgerID int64,
+	ledgerCloseTime time.Time,
+	accountID FutureAccountID,
+	assetID FutureAssetID,
+	balance int64,
+) error {
+	return i.builder.Row(map[string]interface{}{
+		"history_ledger_id":  ledgerID,
+		"ledger_closed_at":   ledgerCloseTime,
+		"history_account_id": accountID,
+		"history_asset_id":   assetID,
+		"balance":            balance,
+	})
+}
+
+// Exec flushes all outstanding account balances to the database
+func (i *accountBalanceBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	return i.builder.Exec(ctx, session, i.table)
+}
+
+// AccountBalanceHistory returns a page of the balance history of an account.
+func (q *Q) AccountBalanceHistory(ctx context.Context, query AccountBalanceHistoryQuery, page db2.PageQuery, oldestLedger int32) ([]AccountBalanceHistory, error) {
+	ledgerID, assetID, err := page.CursorInt64Pair(db2.DefaultPairSep)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := selectAccountBalanceHistory.Where("hacc.address = ?", query.Account)
+	if query.Asset != nil {
+		key := AssetKeyFromXDR(*query.Asset)
+		sql = sql.Where(sq.Eq{
+			"ha.asset_type":   key.Type,
+			"ha.asset_code":   key.Code,
+			"ha.asset_issuer": key.Issuer,
+		})
+	}
+	if query.StartLedger > 0 {
+		sql = sql.Where("hab.history_ledger_id >= ?", toid.New(int32(query.StartLedger), 0, 0).ToInt64())
+	}
+	if query.EndLedger > 0 {
+		sql = sql.Where("hab.history_ledger_id < ?", toid.New(int32(query.EndLedger+1), 0, 0).ToInt64())
+	}
+	if !query.StartTime.IsZero() {
+		sql = sql.Where("hab.ledger_closed_at >= ?", query.StartTime)
+	}
+	if !query.EndTime.IsZero() {
+		sql = sql.Where("hab.ledger_closed_at < ?", query.EndTime)
+	}
+
+	switch page.Order {
+	case "asc":
+		sql = sql.
+			Where(`(
+					 hab.history_ledger_id >= ?
+				AND (
+					 hab.history_ledger_id > ? OR
+					(hab.history_ledger_id = ? AND hab.history_asset_id > ?)
+				))`, ledgerID, ledgerID, ledgerID, assetID).
+			OrderBy("hab.history_ledger_id asc, hab.history_asset_id asc")
+	case "desc":
+		if lowerBound := lowestLedgerBound(oldestLedger); lowerBound > 0 {
+			sql = sql.Where("hab.history_ledger_id > ?", lowerBound)
+		}
+		sql = sql.
+			Where(`(
+					 hab.history_ledger_id <= ?
+				AND (
+					 hab.history_ledger_id < ? OR
+					(hab.history_ledger_id = ? AND hab.history_asset_id < ?)
+				))`, ledgerID, ledgerID, ledgerID, assetID).
+			OrderBy("hab.history_ledger_id desc, hab.history_asset_id desc")
+	default:
+		return nil, errors.Errorf("invalid paging order: %s", page.Order)
+	}
+
+	sql = sql.Limit(page.Limit)
+
+	var rows []AccountBalanceHistory
+	if err = q.Select(ctx, &rows, sql); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+var selectAccountBalanceHistory = sq.Select(
+	"hab.history_ledger_id",
+	"hab.ledger_closed_at",
+	"hab.history_asset_id",
+	"hab.balance",
+	"ha.asset_type",
+	"ha.asset_code",
+	"ha.asset_issuer",
+).
+	From("history_account_balances hab").
+	Join("history_accounts hacc ON hab.history_account_id = hacc.id").
+	Join("history_assets ha ON hab.history_asset_id = ha.id")
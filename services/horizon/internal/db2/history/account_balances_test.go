@@ -0,0 +1,112 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestAccountBalanceHistory(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+
+	account := "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	otherAccount := "GANFZDRBCNTUXIODCJEYMACPMCSZEVE4WZGZ3CZDZ3P2SXK4KH75IK6Y"
+	native := xdr.MustNewNativeAsset()
+	usd := xdr.MustNewCreditAsset("USD", otherAccount)
+
+	accountLoader := NewAccountLoader(ConcurrentInserts)
+	assetLoader := NewAssetLoader(ConcurrentInserts)
+	builder := q.NewAccountBalanceBatchInsertBuilder()
+	for _, row := range []struct {
+		ledger  int32
+		account string
+		asset   xdr.Asset
+		balance int64
+	}{
+		{10, account, native, 1000},
+		{10, account, usd, 50},
+		{11, otherAccount, native, 10},
+		{12, account, native, 900},
+	} {
+		tt.Assert.NoError(builder.Add(
+			toid.New(row.ledger, 0, 0).ToInt64(),
+			time.Unix(int64(row.ledger)*5, 0).UTC(),
+			accountLoader.GetFuture(row.account),
+			assetLoader.GetFuture(AssetKeyFromXDR(row.asset)),
+			row.balance,
+		))
+	}
+	tt.Assert.NoError(accountLoader.Exec(tt.Ctx, q))
+	tt.Assert.NoError(assetLoader.Exec(tt.Ctx, q))
+	tt.Assert.NoError(builder.Exec(tt.Ctx, q))
+	tt.Assert.NoError(q.Commit())
+
+	pq := db2.PageQuery{Cursor: "", Order: "asc", Limit: 10}
+
+	balances, err := q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{Account: account}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(balances, 3)
+	tt.Assert.Equal(int32(10), balances[0].LedgerSequence())
+	tt.Assert.Equal(time.Unix(50, 0).UTC(), balances[0].LedgerCloseTime.UTC())
+	tt.Assert.Equal(int32(12), balances[2].LedgerSequence())
+	tt.Assert.Equal(int64(900), balances[2].Balance)
+	tt.Assert.Equal("native", balances[2].AssetType)
+
+	balances, err = q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{Account: account, Asset: &usd}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(balances, 1)
+	tt.Assert.Equal(int64(50), balances[0].Balance)
+	tt.Assert.Equal("USD", balances[0].AssetCode)
+	tt.Assert.Equal(otherAccount, balances[0].AssetIssuer)
+
+	balances, err = q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{Account: account, StartLedger: 11, EndLedger: 12}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(balances, 1)
+	tt.Assert.Equal(int32(12), balances[0].LedgerSequence())
+
+	balances, err = q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{
+		Account:   account,
+		StartTime: time.Unix(50, 0).UTC(),
+		EndTime:   time.Unix(60, 0).UTC(),
+	}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(balances, 2)
+
+	// the balance at ledger 11 is the latest balance at or before ledger 11
+	balances, err = q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{Account: account, Asset: &native, EndLedger: 11}, db2.PageQuery{
+		Order: "desc",
+		Limit: 1,
+	}, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(balances, 1)
+	tt.Assert.Equal(int64(1000), balances[0].Balance)
+
+	// paging
+	all, err := q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{Account: account}, pq, 0)
+	tt.Assert.NoError(err)
+	balances, err = q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{Account: account}, db2.PageQuery{
+		Cursor: all[0].PagingToken(),
+		Order:  "asc",
+		Limit:  10,
+	}, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(balances, 2)
+	tt.Assert.Equal(all[1].PagingToken(), balances[0].PagingToken())
+
+	balances, err = q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{Account: account}, db2.PageQuery{
+		Cursor: all[2].PagingToken(),
+		Order:  "desc",
+		Limit:  1,
+	}, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(balances, 1)
+	tt.Assert.Equal(all[1].PagingToken(), balances[0].PagingToken())
+}
@@ -0,0 +1,205 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+)
+
+// accountExportBatchSize is the number of rows fetched at a time from the
+// server-side cursors streaming an account export.
+const accountExportBatchSize = 1000
+
+// AccountExportQuery selects the history of an account in a range of ledgers.
+type AccountExportQuery struct {
+	// AccountID is the internal id of the account in history_accounts.
+	AccountID   int64
+	StartLedger int32
+	// EndLedger is inclusive.
+	EndLedger int32
+}
+
+// idRange returns the range of the ids of the transactions and operations
+// of the ledgers selected by the query, the end of the range is exclusive.
+func (q AccountExportQuery) idRange() (int64, int64) {
+	start := toid.ID{LedgerSequence: q.StartLedger}
+	end := toid.ID{LedgerSequence: q.EndLedger + 1}
+	return start.ToInt64(), end.ToInt64()
+}
+
+func (q AccountExportQuery) transactions() sq.SelectBuilder {
+	start, end := q.idRange()
+	return selectTransactionHistory.
+		Join("history_transaction_participants htp ON htp.history_transaction_id = ht.id").
+		Where("htp.history_account_id = ?", q.AccountID).
+		Where("htp.history_transaction_id >= ? AND htp.history_transaction_id < ?", start, end)
+}
+
+func (q AccountExportQuery) operations() sq.SelectBuilder {
+	start, end := q.idRange()
+	return selectOperation.
+		Join("history_operation_participants hopp ON hopp.history_operation_id = hop.id").
+		Where("hopp.history_account_id = ?", q.AccountID).
+		Where("hopp.history_operation_id >= ? AND hopp.history_operation_id < ?", start, end)
+}
+
+func (q AccountExportQuery) effects() sq.SelectBuilder {
+	start, end := q.idRange()
+	return selectEffect.
+		Where("heff.history_account_id = ?", q.AccountID).
+		Where("heff.history_operation_id >= ? AND heff.history_operation_id < ?", start, end)
+}
+
+// trades returns the query selecting the trades of the account where the
+// account is either the base or the counter party, ordered by id.
+func (q AccountExportQuery) trades() (string, []interface{}, error) {
+	start, end := q.idRange()
+	sql := joinTradeAssets(
+		joinTradeLiquidityPools(
+			joinTradeAccounts(
+				selectTradeFields.From("history_trades htrd"),
+				"history_accounts",
+			),
+			"history_liquidity_pools",
+		),
+		"history_assets",
+	).Where("htrd.history_operation_id >= ? AND htrd.history_operation_id < ?", start, end)
+
+	baseSQL, baseArgs, err := sql.Where("htrd.base_account_id = ?", q.AccountID).ToSql()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error building base trades query")
+	}
+	counterSQL, counterArgs, err := sql.Where("htrd.counter_account_id = ?", q.AccountID).ToSql()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error building counter trades query")
+	}
+	rawSQL := fmt.Sprintf(`(%s) UNION (%s) ORDER BY history_operation_id asc, "order" asc`, baseSQL, counterSQL)
+	return rawSQL, append(baseArgs, counterArgs...), nil
+}
+
+// LedgerRangeByCloseTime returns the first and the last ledgers closed at or
+// after start and before end. It returns zeros if no ledger was closed in
+// the given period.
+func (q *Q) LedgerRangeByCloseTime(ctx context.Context, start, end time.Time) (int32, int32, error) {
+	var ledgerRange struct {
+		First int32 `db:"first"`
+		Last  int32 `db:"last"`
+	}
+	err := q.Get(ctx, &ledgerRange, sq.
+		Select("COALESCE(MIN(sequence), 0) as first, COALESCE(MAX(sequence), 0) as last").
+		From("history_ledgers").
+		Where("closed_at >= ? AND closed_at < ?", start, end),
+	)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "could not select ledger range")
+	}
+	return ledgerRange.First, ledgerRange.Last, nil
+}
+
+// CountAccountExport returns the number of transactions, operations, effects
+// and trades selected by the query.
+func (q *Q) CountAccountExport(ctx context.Context, query AccountExportQuery) (int64, error) {
+	tradesSQL, tradesArgs, err := query.trades()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for name, sql := range map[string]sq.Sqlizer{
+		"transactions": query.transactions(),
+		"operations":   query.operations(),
+		"effects":      query.effects(),
+		"trades":       sq.Expr(tradesSQL, tradesArgs...),
+	} {
+		rawSQL, args, err := sql.ToSql()
+		if err != nil {
+			return 0, errors.Wrapf(err, "could not build %s query", name)
+		}
+		var count int64
+		if err = q.GetRaw(ctx, &count, "SELECT COUNT(*) FROM ("+rawSQL+") export", args...); err != nil {
+			return 0, errors.Wrapf(err, "could not count %s", name)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// StreamAccountTransactions passes the transactions selected by the query to
+// callback in batches ordered by id. It must be called in a transaction.
+func (q *Q) StreamAccountTransactions(ctx context.Context, query AccountExportQuery, callback func([]Transaction) error) error {
+	return streamWithCursor(ctx, q, "account_export_transactions",
+		query.transactions().OrderBy("htp.history_transaction_id asc"),
+		callback,
+	)
+}
+
+// StreamAccountOperations passes the operations selected by the query to
+// callback in batches ordered by id. It must be called in a transaction.
+func (q *Q) StreamAccountOperations(ctx context.Context, query AccountExportQuery, callback func([]Operation) error) error {
+	return streamWithCursor(ctx, q, "account_export_operations",
+		query.operations().OrderBy("hopp.history_operation_id asc"),
+		callback,
+	)
+}
+
+// StreamAccountEffects passes the effects selected by the query to callback
+// in batches ordered by id. It must be called in a transaction.
+func (q *Q) StreamAccountEffects(ctx context.Context, query AccountExportQuery, callback func([]Effect) error) error {
+	return streamWithCursor(ctx, q, "account_export_effects",
+		query.effects().OrderBy("heff.history_operation_id asc, heff.order asc"),
+		callback,
+	)
+}
+
+// StreamAccountTrades passes the trades selected by the query to callback
+// in batches ordered by id. It must be called in a transaction.
+func (q *Q) StreamAccountTrades(ctx context.Context, query AccountExportQuery, callback func([]Trade) error) error {
+	rawSQL, args, err := query.trades()
+	if err != nil {
+		return err
+	}
+	return streamWithCursor(ctx, q, "account_export_trades", sq.Expr(rawSQL, args...), callback)
+}
+
+// streamWithCursor runs the query with a server-side cursor and passes its
+// rows to callback in batches. The cursor is not read while callback runs so
+// callback can run other queries in the same transaction.
+func streamWithCursor[T any](ctx context.Context, q *Q, name string, query sq.Sqlizer, callback func([]T) error) error {
+	if q.GetTx() == nil {
+		return errors.New("server-side cursors can only be used in a transaction")
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return errors.Wrap(err, "could not build cursor query")
+	}
+	if _, err = q.ExecRaw(ctx, "DECLARE "+name+" NO SCROLL CURSOR FOR "+sql, args...); err != nil {
+		return errors.Wrapf(err, "could not declare cursor %s", name)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", accountExportBatchSize, name)
+	for {
+		var batch []T
+		if err = q.SelectRaw(ctx, &batch, fetch); err != nil {
+			return errors.Wrapf(err, "could not fetch from cursor %s", name)
+		}
+		if len(batch) > 0 {
+			if err = callback(batch); err != nil {
+				return err
+			}
+		}
+		if len(batch) < accountExportBatchSize {
+			break
+		}
+	}
+
+	if _, err = q.ExecRaw(ctx, "CLOSE "+name); err != nil {
+		return errors.Wrapf(err, "could not close cursor %s", name)
+	}
+	return nil
+}
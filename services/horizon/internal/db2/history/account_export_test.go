@@ -0,0 +1,57 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/toid"
+)
+
+func TestAccountExportTrades(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+	fixtures := TradeScenario(tt, q)
+	tradesSeq := toid.Parse(fixtures.Trades[0].HistoryOperationID).LedgerSequence
+
+	address := fixtures.Addresses[0]
+	var account Account
+	tt.Assert.NoError(q.AccountByAddress(tt.Ctx, &account, address))
+	query := AccountExportQuery{AccountID: account.ID, StartLedger: tradesSeq, EndLedger: tradesSeq}
+	expected := filterByAccount(fixtures.Trades, address)
+	tt.Assert.NotEmpty(expected)
+
+	count, err := q.CountAccountExport(tt.Ctx, query)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(len(expected)), count)
+
+	noop := func([]Trade) error { return nil }
+	tt.Assert.EqualError(
+		q.StreamAccountTrades(tt.Ctx, query, noop),
+		"server-side cursors can only be used in a transaction",
+	)
+
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+	defer q.Rollback()
+
+	var rows []Trade
+	tt.Assert.NoError(q.StreamAccountTrades(tt.Ctx, query, func(batch []Trade) error {
+		rows = append(rows, batch...)
+		return nil
+	}))
+	assertTradesAreEqual(tt, expected, rows)
+
+	// the cursor can be declared again once the stream has ended
+	rows = nil
+	query.StartLedger, query.EndLedger = tradesSeq+1, tradesSeq+10
+	tt.Assert.NoError(q.StreamAccountTrades(tt.Ctx, query, func(batch []Trade) error {
+		rows = append(rows, batch...)
+		return nil
+	}))
+	tt.Assert.Empty(rows)
+
+	count, err = q.CountAccountExport(tt.Ctx, query)
+	tt.Assert.NoError(err)
+	tt.Assert.Zero(count)
+}
@@ -0,0 +1,93 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/stellar/go/support/errors"
+)
+
+const apiKeysTableName = "api_keys"
+
+// APIKey is a row of data from the `api_keys` table. Only the SHA-256 hash of
+// the key is stored. A zero limit means the key is not limited in that
+// dimension.
+type APIKey struct {
+	ID                   int64     `db:"id"`
+	Name                 string    `db:"name"`
+	KeyHash              string    `db:"key_hash"`
+	RequestsPerHour      int32     `db:"requests_per_hour"`
+	MaxStreams           int32     `db:"max_streams"`
+	PathFindingPerSecond int32     `db:"path_finding_per_second"`
+	CreatedAt            time.Time `db:"created_at"`
+	UpdatedAt            time.Time `db:"updated_at"`
+}
+
+// QAPIKeys defines API key related queries.
+type QAPIKeys interface {
+	InsertAPIKey(ctx context.Context, key APIKey) (APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]APIKey, error)
+	GetAPIKeyByID(ctx context.Context, id int64) (APIKey, error)
+	UpdateAPIKey(ctx context.Context, key APIKey) (APIKey, error)
+	DeleteAPIKey(ctx context.Context, id int64) (int64, error)
+}
+
+// InsertAPIKey creates a new API key and returns it with its id and
+// timestamps populated.
+func (q *Q) InsertAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	sql := sq.Insert(apiKeysTableName).SetMap(map[string]interface{}{
+		"name":                    key.Name,
+		"key_hash":                key.KeyHash,
+		"requests_per_hour":       key.RequestsPerHour,
+		"max_streams":             key.MaxStreams,
+		"path_finding_per_second": key.PathFindingPerSecond,
+	}).Suffix("RETURNING *")
+
+	var inserted APIKey
+	if err := q.Get(ctx, &inserted, sql); err != nil {
+		return APIKey{}, errors.Wrap(err, "could not insert api key")
+	}
+	return inserted, nil
+}
+
+// GetAPIKeys returns all the API keys ordered by id.
+func (q *Q) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var keys []APIKey
+	sql := sq.Select("*").From(apiKeysTableName).OrderBy("id asc")
+	err := q.Select(ctx, &keys, sql)
+	return keys, err
+}
+
+// GetAPIKeyByID returns the API key with the given id, the error satisfies
+// q.NoRows() if it does not exist.
+func (q *Q) GetAPIKeyByID(ctx context.Context, id int64) (APIKey, error) {
+	var key APIKey
+	sql := sq.Select("*").From(apiKeysTableName).Where("id = ?", id)
+	err := q.Get(ctx, &key, sql)
+	return key, err
+}
+
+// UpdateAPIKey updates the name and the limits of the API key and returns
+// the updated row, the error satisfies q.NoRows() if it does not exist. The
+// key hash cannot be changed.
+func (q *Q) UpdateAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	sql := sq.Update(apiKeysTableName).SetMap(map[string]interface{}{
+		"name":                    key.Name,
+		"requests_per_hour":       key.RequestsPerHour,
+		"max_streams":             key.MaxStreams,
+		"path_finding_per_second": key.PathFindingPerSecond,
+		"updated_at":              sq.Expr("now() at time zone 'utc'"),
+	}).Where("id = ?", key.ID).Suffix("RETURNING *")
+
+	var updated APIKey
+	err := q.Get(ctx, &updated, sql)
+	return updated, err
+}
+
+// DeleteAPIKey removes the API key. It returns the number of deleted keys.
+func (q *Q) DeleteAPIKey(ctx context.Context, id int64) (int64, error) {
+	sql := sq.Delete(apiKeysTableName).Where("id = ?", id)
+	return q.checkForError(sql, ctx)
+}
@@ -0,0 +1,69 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/db/pg"
+)
+
+func TestAPIKeys(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	first, err := q.InsertAPIKey(tt.Ctx, APIKey{
+		Name:            "payments",
+		KeyHash:         "hash1",
+		RequestsPerHour: 3600,
+		MaxStreams:      10,
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.NotZero(first.ID)
+	tt.Assert.Zero(first.PathFindingPerSecond)
+	tt.Assert.False(first.CreatedAt.IsZero())
+
+	second, err := q.InsertAPIKey(tt.Ctx, APIKey{
+		Name:                 "wallet",
+		KeyHash:              "hash2",
+		PathFindingPerSecond: 5,
+	})
+	tt.Assert.NoError(err)
+
+	_, err = q.InsertAPIKey(tt.Ctx, APIKey{Name: "payments", KeyHash: "hash3"})
+	tt.Assert.True(pg.IsUniqueViolation(err))
+
+	keys, err := q.GetAPIKeys(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(keys, 2)
+	tt.Assert.Equal(first.ID, keys[0].ID)
+	tt.Assert.Equal(second.ID, keys[1].ID)
+
+	second.Name = "wallets"
+	second.MaxStreams = 3
+	second.KeyHash = "ignored"
+	updated, err := q.UpdateAPIKey(tt.Ctx, second)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("wallets", updated.Name)
+	tt.Assert.Equal(int32(3), updated.MaxStreams)
+	tt.Assert.Equal(int32(5), updated.PathFindingPerSecond)
+	tt.Assert.Equal("hash2", updated.KeyHash)
+
+	loaded, err := q.GetAPIKeyByID(tt.Ctx, second.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(updated, loaded)
+
+	deleted, err := q.DeleteAPIKey(tt.Ctx, first.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), deleted)
+
+	_, err = q.GetAPIKeyByID(tt.Ctx, first.ID)
+	tt.Assert.True(q.NoRows(err))
+	_, err = q.UpdateAPIKey(tt.Ctx, first)
+	tt.Assert.True(q.NoRows(err))
+
+	deleted, err = q.DeleteAPIKey(tt.Ctx, first.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Zero(deleted)
+}
@@ -2,6 +2,7 @@ package history
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
@@ -88,6 +89,8 @@ type ContractAssetBalance struct {
 	KeyHash []byte `db:"key_hash"`
 	// ContractID is the contract id of the stellar asset contract
 	ContractID []byte `db:"asset_contract_id"`
+	// HolderID is the contract id of the contract holding the balance
+	HolderID []byte `db:"holder_id"`
 	// Amount is the amount held by the contract
 	Amount string `db:"amount"`
 	// ExpirationLedger is the latest ledger for which this contract balance
@@ -210,6 +213,61 @@ func (q *Q) GetContractAssetBalances(ctx context.Context, keys []xdr.Hash) ([]Co
 	return balances, err
 }
 
+// ContractAssetHolding is a contract_asset_balances row along with the asset
+// of the stellar asset contract which issued the balance.
+type ContractAssetHolding struct {
+	ContractAssetBalance
+	AssetType   xdr.AssetType `db:"asset_type"`
+	AssetCode   string        `db:"asset_code"`
+	AssetIssuer string        `db:"asset_issuer"`
+}
+
+// PagingToken returns a cursor for this contract asset holding
+func (h ContractAssetHolding) PagingToken() string {
+	return hex.EncodeToString(h.ContractID)
+}
+
+// GetContractAssetHoldings returns a page of the stellar asset contract
+// balances held by the given contract, ordered by the asset contract id.
+// Balances which were not issued by a known stellar asset contract are
+// excluded.
+func (q *Q) GetContractAssetHoldings(ctx context.Context, holderID xdr.Hash, page db2.PageQuery) ([]ContractAssetHolding, error) {
+	sql := sq.Select(
+		"cab.*",
+		"exp_asset_stats.asset_type",
+		"exp_asset_stats.asset_code",
+		"exp_asset_stats.asset_issuer",
+	).From("contract_asset_balances cab").
+		Join("exp_asset_stats ON exp_asset_stats.contract_id = cab.asset_contract_id").
+		Where("cab.holder_id = ?", holderID[:])
+
+	var cursorComparison string
+	switch page.Order {
+	case "asc":
+		cursorComparison = ">"
+	case "desc":
+		cursorComparison = "<"
+	default:
+		return nil, errors.Errorf("invalid page order %s", page.Order)
+	}
+
+	if page.Cursor != "" {
+		cursor, err := hex.DecodeString(page.Cursor)
+		if err != nil || len(cursor) != len(xdr.Hash{}) {
+			return nil, db2.ErrInvalidCursor
+		}
+		sql = sql.Where("cab.asset_contract_id "+cursorComparison+" ?", cursor)
+	}
+
+	sql = sql.OrderBy("cab.asset_contract_id " + page.Order).Limit(page.Limit)
+
+	var holdings []ContractAssetHolding
+	if err := q.Select(ctx, &holdings, sql); err != nil {
+		return nil, errors.Wrap(err, "could not run select query")
+	}
+	return holdings, nil
+}
+
 // RemoveContractAssetBalances removes rows from the contract_asset_balances table
 func (q *Q) RemoveContractAssetBalances(ctx context.Context, keys []xdr.Hash) error {
 	if len(keys) == 0 {
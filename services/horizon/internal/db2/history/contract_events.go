@@ -0,0 +1,166 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// MaxContractEventTopics is the maximum number of topics of a contract event
+// which are stored in (and can be filtered on in) the history_contract_events
+// table. It matches the limit enforced by the Soroban host.
+const MaxContractEventTopics = 4
+
+// ContractEvent is a row of data from the `history_contract_events` table
+type ContractEvent struct {
+	HistoryOperationID int64                 `db:"history_operation_id"`
+	Order              int32                 `db:"order"`
+	TransactionHash    string                `db:"transaction_hash"`
+	LedgerCloseTime    time.Time             `db:"ledger_closed_at"`
+	ContractID         null.String           `db:"contract_id"`
+	Type               xdr.ContractEventType `db:"type"`
+	Topic1             null.String           `db:"topic1"`
+	Topic2             null.String           `db:"topic2"`
+	Topic3             null.String           `db:"topic3"`
+	Topic4             null.String           `db:"topic4"`
+	EventXDR           string                `db:"event_xdr"`
+}
+
+// ID returns a lexically ordered id for this contract event record
+func (r ContractEvent) ID() string {
+	return fmt.Sprintf("%019d-%010d", r.HistoryOperationID, r.Order)
+}
+
+// PagingToken returns a cursor for this contract event
+func (r ContractEvent) PagingToken() string {
+	return fmt.Sprintf("%d-%d", r.HistoryOperationID, r.Order)
+}
+
+// LedgerSequence returns the ledger in which the event was emitted.
+func (r ContractEvent) LedgerSequence() int32 {
+	return toid.Parse(r.HistoryOperationID).LedgerSequence
+}
+
+// ContractEventsQuery holds the filters of a contract events query. Empty
+// fields match all events.
+type ContractEventsQuery struct {
+	ContractID string
+	Type       *xdr.ContractEventType
+	// Topics holds the base64 encoded topics to match. A nil segment matches
+	// any topic at that position.
+	Topics [MaxContractEventTopics]*string
+	// StartLedger and EndLedger bound (inclusively) the ledgers in which the
+	// events were emitted.
+	StartLedger uint32
+	EndLedger   uint32
+}
+
+// QContractEvents defines history_contract_events related queries.
+type QContractEvents interface {
+	NewContractEventBatchInsertBuilder() ContractEventBatchInsertBuilder
+}
+
+// ContractEventBatchInsertBuilder is used to insert contract events into the
+// history_contract_events table
+type ContractEventBatchInsertBuilder interface {
+	Add(event ContractEvent) error
+	Exec(ctx context.Context, session db.SessionInterface) error
+}
+
+// contractEventBatchInsertBuilder is a simple wrapper around db.FastBatchInsertBuilder
+type contractEventBatchInsertBuilder struct {
+	table   string
+	builder db.FastBatchInsertBuilder
+}
+
+// NewContractEventBatchInsertBuilder constructs a new ContractEventBatchInsertBuilder instance
+func (q *Q) NewContractEventBatchInsertBuilder() ContractEventBatchInsertBuilder {
+	return &contractEventBatchInsertBuilder{
+		table:   "history_contract_events",
+		builder: db.FastBatchInsertBuilder{},
+	}
+}
+
+// Add adds a contract event to the batch
+func (i *contractEventBatchInsertBuilder) Add(event ContractEvent) error {
+	return errors.Wrap(i.builder.RowStruct(event), "failed to add contract event")
+}
+
+// Exec flushes all outstanding contract events to the database
+func (i *contractEventBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	return i.builder.Exec(ctx, session, i.table)
+}
+
+// ContractEvents returns a page of contract events matching the given query.
+func (q *Q) ContractEvents(ctx context.Context, query ContractEventsQuery, page db2.PageQuery, oldestLedger int32) ([]ContractEvent, error) {
+	op, idx, err := parseEffectsCursor(page)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := selectContractEvent
+	if query.ContractID != "" {
+		sql = sql.Where("hce.contract_id = ?", query.ContractID)
+	}
+	if query.Type != nil {
+		sql = sql.Where("hce.type = ?", int32(*query.Type))
+	}
+	for i, topic := range query.Topics {
+		if topic != nil {
+			sql = sql.Where(fmt.Sprintf("hce.topic%d = ?", i+1), *topic)
+		}
+	}
+	if query.StartLedger > 0 {
+		sql = sql.Where("hce.history_operation_id >= ?", toid.New(int32(query.StartLedger), 0, 0).ToInt64())
+	}
+	if query.EndLedger > 0 {
+		sql = sql.Where("hce.history_operation_id < ?", toid.New(int32(query.EndLedger+1), 0, 0).ToInt64())
+	}
+
+	// NOTE: as with effects, the conditions below are written so that the
+	// multicolumn indexes can be used.
+	switch page.Order {
+	case "asc":
+		sql = sql.
+			Where(`(
+					 hce.history_operation_id >= ?
+				AND (
+					 hce.history_operation_id > ? OR
+					(hce.history_operation_id = ? AND hce.order > ?)
+				))`, op, op, op, idx).
+			OrderBy("hce.history_operation_id asc, hce.order asc")
+	case "desc":
+		if lowerBound := lowestLedgerBound(oldestLedger); lowerBound > 0 {
+			sql = sql.Where("hce.history_operation_id > ?", lowerBound)
+		}
+		sql = sql.
+			Where(`(
+					 hce.history_operation_id <= ?
+				AND (
+					 hce.history_operation_id < ? OR
+					(hce.history_operation_id = ? AND hce.order < ?)
+				))`, op, op, op, idx).
+			OrderBy("hce.history_operation_id desc, hce.order desc")
+	default:
+		return nil, errors.Errorf("invalid paging order: %s", page.Order)
+	}
+
+	sql = sql.Limit(page.Limit)
+
+	var rows []ContractEvent
+	if err = q.Select(ctx, &rows, sql); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+var selectContractEvent = sq.Select("hce.*").From("history_contract_events hce")
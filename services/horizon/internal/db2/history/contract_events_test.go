@@ -0,0 +1,107 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestContractEvents(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+
+	contractA := "CA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQGAXE"
+	contractB := "CCJZ5DGASBWQXR5MPFCJXMBI333XE5U3FSJTNQU7RIKE3P5GN2K2WYD5"
+	closeTime := time.Unix(1000, 0).UTC()
+	rows := []ContractEvent{
+		{
+			HistoryOperationID: toid.New(10, 1, 1).ToInt64(),
+			Order:              1,
+			ContractID:         null.StringFrom(contractA),
+			Type:               xdr.ContractEventTypeContract,
+			Topic1:             null.StringFrom("AAAADwAAAAh0cmFuc2Zlcg=="),
+		},
+		{
+			HistoryOperationID: toid.New(10, 1, 1).ToInt64(),
+			Order:              2,
+			Type:               xdr.ContractEventTypeSystem,
+		},
+		{
+			HistoryOperationID: toid.New(12, 1, 1).ToInt64(),
+			Order:              1,
+			ContractID:         null.StringFrom(contractB),
+			Type:               xdr.ContractEventTypeContract,
+			Topic1:             null.StringFrom("AAAADwAAAAh0cmFuc2Zlcg=="),
+			Topic2:             null.StringFrom("AAAAAwAAAAo="),
+		},
+	}
+
+	builder := q.NewContractEventBatchInsertBuilder()
+	for _, row := range rows {
+		row.TransactionHash = "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d"
+		row.LedgerCloseTime = closeTime
+		row.EventXDR = "AAAAAA=="
+		tt.Assert.NoError(builder.Add(row))
+	}
+	tt.Assert.NoError(builder.Exec(tt.Ctx, q))
+	tt.Assert.NoError(q.Commit())
+
+	pq := db2.PageQuery{Cursor: "", Order: "asc", Limit: 10}
+
+	events, err := q.ContractEvents(tt.Ctx, ContractEventsQuery{}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(events, 3)
+	tt.Assert.Equal(rows[0].PagingToken(), events[0].PagingToken())
+	tt.Assert.Equal(int32(10), events[0].LedgerSequence())
+	tt.Assert.Equal(closeTime, events[0].LedgerCloseTime.UTC())
+
+	events, err = q.ContractEvents(tt.Ctx, ContractEventsQuery{ContractID: contractB}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(events, 1)
+	tt.Assert.Equal(rows[2].PagingToken(), events[0].PagingToken())
+
+	systemType := xdr.ContractEventTypeSystem
+	events, err = q.ContractEvents(tt.Ctx, ContractEventsQuery{Type: &systemType}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(events, 1)
+	tt.Assert.Equal(rows[1].PagingToken(), events[0].PagingToken())
+
+	topic := "AAAAAwAAAAo="
+	events, err = q.ContractEvents(tt.Ctx, ContractEventsQuery{Topics: [MaxContractEventTopics]*string{nil, &topic}}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(events, 1)
+	tt.Assert.Equal(rows[2].PagingToken(), events[0].PagingToken())
+
+	events, err = q.ContractEvents(tt.Ctx, ContractEventsQuery{StartLedger: 11, EndLedger: 12}, pq, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(events, 1)
+	tt.Assert.Equal(rows[2].PagingToken(), events[0].PagingToken())
+
+	// paging
+	events, err = q.ContractEvents(tt.Ctx, ContractEventsQuery{}, db2.PageQuery{
+		Cursor: rows[0].PagingToken(),
+		Order:  "asc",
+		Limit:  10,
+	}, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(events, 2)
+	tt.Assert.Equal(rows[1].PagingToken(), events[0].PagingToken())
+
+	events, err = q.ContractEvents(tt.Ctx, ContractEventsQuery{}, db2.PageQuery{
+		Cursor: rows[2].PagingToken(),
+		Order:  "desc",
+		Limit:  1,
+	}, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(events, 1)
+	tt.Assert.Equal(rows[1].PagingToken(), events[0].PagingToken())
+}
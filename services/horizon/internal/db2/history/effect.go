@@ -105,14 +105,21 @@ func (q *Q) Effects(ctx context.Context, page db2.PageQuery, oldestLedger int32)
 	return rows, nil
 }
 
-// EffectsForAccount returns a page of effects for a given account
+// EffectsForAccount returns a page of effects for a given account. If aid is a
+// muxed account (M...) address, only the effects of the muxed account are
+// returned.
 func (q *Q) EffectsForAccount(ctx context.Context, aid string, page db2.PageQuery, oldestLedger int32) ([]Effect, error) {
+	address, _, muxed := parseMuxedAddress(aid)
 	var account Account
-	if err := q.AccountByAddress(ctx, &account, aid); err != nil {
+	if err := q.AccountByAddress(ctx, &account, address); err != nil {
 		return nil, err
 	}
 
 	query := selectEffect.Where("heff.history_account_id = ?", account.ID)
+	if muxed {
+		// in order to use the index_history_effects_on_address_muxed index
+		query = selectEffect.Where("heff.address_muxed = ?", aid)
+	}
 	return q.selectEffectsPage(ctx, query, page, oldestLedger)
 }
 
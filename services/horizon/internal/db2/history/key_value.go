@@ -20,6 +20,8 @@ const (
 	stateInvalid                    = "exp_state_invalid"
 	offerCompactionSequence         = "offer_compaction_sequence"
 	liquidityPoolCompactionSequence = "liquidity_pool_compaction_sequence"
+	orderBookHistoryStartLedger     = "order_book_history_start_ledger"
+	orderBookHistoryLastLedger      = "order_book_history_last_ledger"
 	lookupTableReapOffsetSuffix     = "_reap_offset"
 )
 
@@ -150,6 +152,29 @@ func (q *Q) GetLiquidityPoolCompactionSequence(ctx context.Context) (uint32, err
 	return uint32(parsed), nil
 }
 
+// GetOrderBookHistoryStartLedger returns the first ledger at which the order
+// book can be reconstructed from the order book history, or 0 if the order
+// book history has not been ingested.
+func (q *Q) GetOrderBookHistoryStartLedger(ctx context.Context) (uint32, error) {
+	parsed, err := q.getIntValueFromStore(ctx, orderBookHistoryStartLedger, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error converting sequence value")
+	}
+
+	return uint32(parsed), nil
+}
+
+// GetOrderBookHistoryLastLedger returns the last ledger recorded in the
+// order book history, or 0 if the order book history has not been ingested.
+func (q *Q) GetOrderBookHistoryLastLedger(ctx context.Context) (uint32, error) {
+	parsed, err := q.getIntValueFromStore(ctx, orderBookHistoryLastLedger, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error converting sequence value")
+	}
+
+	return uint32(parsed), nil
+}
+
 func (q *Q) getIntValueFromStore(ctx context.Context, key string, bitSize int) (int64, error) {
 	sequence, err := q.getValueFromStore(ctx, key, false)
 	if err != nil {
@@ -184,6 +209,26 @@ func (q *Q) UpdateLiquidityPoolCompactionSequence(ctx context.Context, sequence
 	)
 }
 
+// UpdateOrderBookHistoryStartLedger sets the first ledger at which the order
+// book can be reconstructed from the order book history.
+func (q *Q) UpdateOrderBookHistoryStartLedger(ctx context.Context, sequence uint32) error {
+	return q.updateValueInStore(
+		ctx,
+		orderBookHistoryStartLedger,
+		strconv.FormatUint(uint64(sequence), 10),
+	)
+}
+
+// UpdateOrderBookHistoryLastLedger sets the last ledger recorded in the
+// order book history.
+func (q *Q) UpdateOrderBookHistoryLastLedger(ctx context.Context, sequence uint32) error {
+	return q.updateValueInStore(
+		ctx,
+		orderBookHistoryLastLedger,
+		strconv.FormatUint(uint64(sequence), 10),
+	)
+}
+
 // getValueFromStore returns a value for a given key from KV store. If value
 // is not present in the key value store "" will be returned.
 func (q *Q) getValueFromStore(ctx context.Context, key string, forUpdate bool) (string, error) {
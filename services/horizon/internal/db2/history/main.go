@@ -263,9 +263,11 @@ type AccountEntry struct {
 type IngestionQ interface {
 	QAccounts
 	QFilter
+	QAccountBalanceHistory
 	QAssetStats
 	QClaimableBalances
 	QHistoryClaimableBalances
+	QContractEvents
 	QData
 	QEffects
 	QLedgers
@@ -273,11 +275,14 @@ type IngestionQ interface {
 	QHistoryLiquidityPools
 	QOffers
 	QOperations
+	QOrderBookHistory
 	// QParticipants
 	// Copy the small interfaces with shared methods directly, otherwise error:
 	// duplicate method CreateAccounts
 	NewTransactionParticipantsBatchInsertBuilder() TransactionParticipantsBatchInsertBuilder
 	NewOperationParticipantBatchInsertBuilder() OperationParticipantBatchInsertBuilder
+	NewMuxedTransactionParticipantsBatchInsertBuilder() MuxedTransactionParticipantsBatchInsertBuilder
+	NewMuxedOperationParticipantBatchInsertBuilder() MuxedOperationParticipantBatchInsertBuilder
 	QSigners
 	//QTrades
 	NewTradeBatchInsertBuilder() TradeBatchInsertBuilder
@@ -1046,6 +1051,10 @@ func (q *Q) reapLookupTable(ctx context.Context, table string, ids []int64, newO
 
 var historyLookupTables = map[string][]tableObjectFieldPair{
 	"history_accounts": {
+		{
+			name:        "history_account_balances",
+			objectField: "history_account_id",
+		},
 		{
 			name:        "history_transaction_participants",
 			objectField: "history_account_id",
@@ -1059,6 +1068,14 @@ var historyLookupTables = map[string][]tableObjectFieldPair{
 			name:        "history_operation_participants",
 			objectField: "history_account_id",
 		},
+		{
+			name:        "history_muxed_transaction_participants",
+			objectField: "history_account_id",
+		},
+		{
+			name:        "history_muxed_operation_participants",
+			objectField: "history_account_id",
+		},
 		{
 			name:        "history_trades",
 			objectField: "base_account_id",
@@ -1069,6 +1086,10 @@ var historyLookupTables = map[string][]tableObjectFieldPair{
 		},
 	},
 	"history_assets": {
+		{
+			name:        "history_account_balances",
+			objectField: "history_asset_id",
+		},
 		{
 			name:        "history_trades",
 			objectField: "base_asset_id",
@@ -1209,8 +1230,12 @@ func constructFindReapLookupTablesQuery(table string, batchSize int, offset int6
 func (q *Q) DeleteRangeAll(ctx context.Context, start, end int64) (int64, error) {
 	var total int64
 	for table, column := range map[string]string{
+		"history_account_balances":               "history_ledger_id",
+		"history_contract_events":                "history_operation_id",
 		"history_effects":                        "history_operation_id",
 		"history_ledgers":                        "id",
+		"history_muxed_operation_participants":   "history_operation_id",
+		"history_muxed_transaction_participants": "history_transaction_id",
 		"history_operation_claimable_balances":   "history_operation_id",
 		"history_operation_participants":         "history_operation_id",
 		"history_operation_liquidity_pools":      "history_operation_id",
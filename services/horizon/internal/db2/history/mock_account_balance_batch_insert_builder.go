@@ -0,0 +1,33 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stellar/go/support/db"
+)
+
+// MockAccountBalanceBatchInsertBuilder mock AccountBalanceBatchInsertBuilder
+type MockAccountBalanceBatchInsertBuilder struct {
+	mock.Mock
+}
+
+// Add mock
+func (m *MockAccountBalanceBatchInsertBuilder) Add(
+	ledgerID int64,
+	ledgerCloseTime time.Time,
+	accountID FutureAccountID,
+	assetID FutureAssetID,
+	balance int64,
+) error {
+	a := m.Called(ledgerID, ledgerCloseTime, accountID, assetID, balance)
+	return a.Error(0)
+}
+
+// Exec mock
+func (m *MockAccountBalanceBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	a := m.Called(ctx, session)
+	return a.Error(0)
+}
@@ -24,3 +24,20 @@ func (m *MockOperationParticipantBatchInsertBuilder) Exec(ctx context.Context, s
 	a := m.Called(ctx, session)
 	return a.Error(0)
 }
+
+// MockMuxedOperationParticipantBatchInsertBuilder MuxedOperationParticipantBatchInsertBuilder mock
+type MockMuxedOperationParticipantBatchInsertBuilder struct {
+	mock.Mock
+}
+
+// Add mock
+func (m *MockMuxedOperationParticipantBatchInsertBuilder) Add(operationID int64, account FutureAccountID, muxedID uint64) error {
+	a := m.Called(operationID, account, muxedID)
+	return a.Error(0)
+}
+
+// Exec mock
+func (m *MockMuxedOperationParticipantBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	a := m.Called(ctx, session)
+	return a.Error(0)
+}
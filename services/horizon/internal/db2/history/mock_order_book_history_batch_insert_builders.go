@@ -0,0 +1,53 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOfferHistoryBatchInsertBuilder mock OfferHistoryBatchInsertBuilder
+type MockOfferHistoryBatchInsertBuilder struct {
+	mock.Mock
+}
+
+// Add mock
+func (m *MockOfferHistoryBatchInsertBuilder) Add(offer OfferHistory) error {
+	a := m.Called(offer)
+	return a.Error(0)
+}
+
+// Exec mock
+func (m *MockOfferHistoryBatchInsertBuilder) Exec(ctx context.Context) error {
+	a := m.Called(ctx)
+	return a.Error(0)
+}
+
+// Len mock
+func (m *MockOfferHistoryBatchInsertBuilder) Len() int {
+	a := m.Called()
+	return a.Int(0)
+}
+
+// MockLiquidityPoolReservesHistoryBatchInsertBuilder mock LiquidityPoolReservesHistoryBatchInsertBuilder
+type MockLiquidityPoolReservesHistoryBatchInsertBuilder struct {
+	mock.Mock
+}
+
+// Add mock
+func (m *MockLiquidityPoolReservesHistoryBatchInsertBuilder) Add(reserves LiquidityPoolReservesHistory) error {
+	a := m.Called(reserves)
+	return a.Error(0)
+}
+
+// Exec mock
+func (m *MockLiquidityPoolReservesHistoryBatchInsertBuilder) Exec(ctx context.Context) error {
+	a := m.Called(ctx)
+	return a.Error(0)
+}
+
+// Len mock
+func (m *MockLiquidityPoolReservesHistoryBatchInsertBuilder) Len() int {
+	a := m.Called()
+	return a.Int(0)
+}
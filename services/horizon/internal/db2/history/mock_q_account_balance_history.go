@@ -0,0 +1,15 @@
+package history
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQAccountBalanceHistory is a mock implementation of the QAccountBalanceHistory interface
+type MockQAccountBalanceHistory struct {
+	mock.Mock
+}
+
+func (m *MockQAccountBalanceHistory) NewAccountBalanceBatchInsertBuilder() AccountBalanceBatchInsertBuilder {
+	a := m.Called()
+	return a.Get(0).(AccountBalanceBatchInsertBuilder)
+}
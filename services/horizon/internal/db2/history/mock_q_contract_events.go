@@ -0,0 +1,15 @@
+package history
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQContractEvents is a mock implementation of the QContractEvents interface
+type MockQContractEvents struct {
+	mock.Mock
+}
+
+func (m *MockQContractEvents) NewContractEventBatchInsertBuilder() ContractEventBatchInsertBuilder {
+	a := m.Called()
+	return a.Get(0).(ContractEventBatchInsertBuilder)
+}
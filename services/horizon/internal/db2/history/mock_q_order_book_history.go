@@ -0,0 +1,47 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQOrderBookHistory is a mock implementation of the QOrderBookHistory interface
+type MockQOrderBookHistory struct {
+	mock.Mock
+}
+
+func (m *MockQOrderBookHistory) NewOfferHistoryBatchInsertBuilder() OfferHistoryBatchInsertBuilder {
+	a := m.Called()
+	return a.Get(0).(OfferHistoryBatchInsertBuilder)
+}
+
+func (m *MockQOrderBookHistory) NewLiquidityPoolReservesHistoryBatchInsertBuilder() LiquidityPoolReservesHistoryBatchInsertBuilder {
+	a := m.Called()
+	return a.Get(0).(LiquidityPoolReservesHistoryBatchInsertBuilder)
+}
+
+func (m *MockQOrderBookHistory) GetOrderBookHistoryStartLedger(ctx context.Context) (uint32, error) {
+	a := m.Called(ctx)
+	return a.Get(0).(uint32), a.Error(1)
+}
+
+func (m *MockQOrderBookHistory) GetOrderBookHistoryLastLedger(ctx context.Context) (uint32, error) {
+	a := m.Called(ctx)
+	return a.Get(0).(uint32), a.Error(1)
+}
+
+func (m *MockQOrderBookHistory) UpdateOrderBookHistoryLastLedger(ctx context.Context, sequence uint32) error {
+	a := m.Called(ctx, sequence)
+	return a.Error(0)
+}
+
+func (m *MockQOrderBookHistory) ResetOrderBookHistory(ctx context.Context, sequence uint32) error {
+	a := m.Called(ctx, sequence)
+	return a.Error(0)
+}
+
+func (m *MockQOrderBookHistory) DeleteOrderBookHistoryRange(ctx context.Context, start, end int64) (int64, error) {
+	a := m.Called(ctx, start, end)
+	return a.Get(0).(int64), a.Error(1)
+}
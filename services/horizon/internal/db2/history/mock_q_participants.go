@@ -45,3 +45,31 @@ func (m *MockQParticipants) NewOperationParticipantBatchInsertBuilder() Operatio
 	v := a.Get(0)
 	return v.(OperationParticipantBatchInsertBuilder)
 }
+
+// NewMuxedTransactionParticipantsBatchInsertBuilder mock
+func (m *MockQParticipants) NewMuxedTransactionParticipantsBatchInsertBuilder() MuxedTransactionParticipantsBatchInsertBuilder {
+	a := m.Called()
+	return a.Get(0).(MuxedTransactionParticipantsBatchInsertBuilder)
+}
+
+// NewMuxedOperationParticipantBatchInsertBuilder mock
+func (m *MockQParticipants) NewMuxedOperationParticipantBatchInsertBuilder() MuxedOperationParticipantBatchInsertBuilder {
+	a := m.Called()
+	return a.Get(0).(MuxedOperationParticipantBatchInsertBuilder)
+}
+
+// MockMuxedTransactionParticipantsBatchInsertBuilder is a mock implementation of the
+// MuxedTransactionParticipantsBatchInsertBuilder interface
+type MockMuxedTransactionParticipantsBatchInsertBuilder struct {
+	mock.Mock
+}
+
+func (m *MockMuxedTransactionParticipantsBatchInsertBuilder) Add(transactionID int64, accountID FutureAccountID, muxedID uint64) error {
+	a := m.Called(transactionID, accountID, muxedID)
+	return a.Error(0)
+}
+
+func (m *MockMuxedTransactionParticipantsBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	a := m.Called(ctx, session)
+	return a.Error(0)
+}
@@ -135,14 +135,28 @@ func (q *Q) OperationByID(ctx context.Context, includeTransactions bool, id int6
 	return operation, nil, err
 }
 
-// ForAccount filters the operations collection to a specific account
+// ForAccount filters the operations collection to a specific account. If aid
+// is a muxed account (M...) address, only the operations in which the muxed
+// account participates are included.
 func (q *OperationsQ) ForAccount(ctx context.Context, aid string) *OperationsQ {
+	address, muxedID, muxed := parseMuxedAddress(aid)
 	var account Account
-	q.Err = q.parent.AccountByAddress(ctx, &account, aid)
+	q.Err = q.parent.AccountByAddress(ctx, &account, address)
 	if q.Err != nil {
 		return q
 	}
 
+	if muxed {
+		q.sql = q.sql.Join(
+			"history_muxed_operation_participants hmop ON "+
+				"hmop.history_operation_id = hop.id",
+		).Where("hmop.history_account_id = ? AND hmop.muxed_id = ?", account.ID, int64(muxedID))
+
+		// in order to use history_muxed_operation_participants.hist_muxed_op_p_id index
+		q.opIdCol = "hmop.history_operation_id"
+		return q
+	}
+
 	q.sql = q.sql.Join(
 		"history_operation_participants hopp ON "+
 			"hopp.history_operation_id = hop.id",
@@ -44,3 +44,45 @@ func (i *operationParticipantBatchInsertBuilder) Add(
 func (i *operationParticipantBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
 	return i.builder.Exec(ctx, session, i.table)
 }
+
+// MuxedOperationParticipantBatchInsertBuilder is used to insert the muxed
+// accounts participating in operations into the
+// history_muxed_operation_participants table
+type MuxedOperationParticipantBatchInsertBuilder interface {
+	Add(
+		operationID int64,
+		accountID FutureAccountID,
+		muxedID uint64,
+	) error
+	Exec(ctx context.Context, session db.SessionInterface) error
+}
+
+type muxedOperationParticipantBatchInsertBuilder struct {
+	table   string
+	builder db.FastBatchInsertBuilder
+}
+
+// NewMuxedOperationParticipantBatchInsertBuilder constructs a new MuxedOperationParticipantBatchInsertBuilder instance
+func (q *Q) NewMuxedOperationParticipantBatchInsertBuilder() MuxedOperationParticipantBatchInsertBuilder {
+	return &muxedOperationParticipantBatchInsertBuilder{
+		table:   "history_muxed_operation_participants",
+		builder: db.FastBatchInsertBuilder{},
+	}
+}
+
+// Add adds a muxed operation participant to the batch
+func (i *muxedOperationParticipantBatchInsertBuilder) Add(
+	operationID int64,
+	accountID FutureAccountID,
+	muxedID uint64,
+) error {
+	return i.builder.Row(map[string]interface{}{
+		"history_operation_id": operationID,
+		"history_account_id":   accountID,
+		"muxed_id":             int64(muxedID),
+	})
+}
+
+func (i *muxedOperationParticipantBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	return i.builder.Exec(ctx, session, i.table)
+}
@@ -0,0 +1,495 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/price"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// OfferHistory is a row of data from the `history_offers` table. Each row
+// holds the state of an offer at the end of a ledger in which it changed.
+type OfferHistory struct {
+	OfferID         int64     `db:"offer_id"`
+	HistoryLedgerID int64     `db:"history_ledger_id"`
+	SellingAsset    xdr.Asset `db:"selling_asset"`
+	BuyingAsset     xdr.Asset `db:"buying_asset"`
+	Amount          int64     `db:"amount"`
+	Pricen          int32     `db:"pricen"`
+	Priced          int32     `db:"priced"`
+	Price           float64   `db:"price"`
+	Deleted         bool      `db:"deleted"`
+}
+
+// LiquidityPoolReservesHistory is a row of data from the
+// `history_liquidity_pool_reserves` table. Each row holds the reserves of a
+// liquidity pool at the end of a ledger in which they changed.
+type LiquidityPoolReservesHistory struct {
+	PoolID          string    `db:"liquidity_pool_id"`
+	HistoryLedgerID int64     `db:"history_ledger_id"`
+	AssetA          xdr.Asset `db:"asset_a"`
+	AssetB          xdr.Asset `db:"asset_b"`
+	ReserveA        int64     `db:"reserve_a"`
+	ReserveB        int64     `db:"reserve_b"`
+	Fee             int32     `db:"fee"`
+	Deleted         bool      `db:"deleted"`
+}
+
+// QOrderBookHistory defines order book history related queries.
+type QOrderBookHistory interface {
+	NewOfferHistoryBatchInsertBuilder() OfferHistoryBatchInsertBuilder
+	NewLiquidityPoolReservesHistoryBatchInsertBuilder() LiquidityPoolReservesHistoryBatchInsertBuilder
+	GetOrderBookHistoryStartLedger(ctx context.Context) (uint32, error)
+	GetOrderBookHistoryLastLedger(ctx context.Context) (uint32, error)
+	UpdateOrderBookHistoryLastLedger(ctx context.Context, sequence uint32) error
+	ResetOrderBookHistory(ctx context.Context, sequence uint32) error
+	DeleteOrderBookHistoryRange(ctx context.Context, start, end int64) (int64, error)
+}
+
+// OfferHistoryBatchInsertBuilder is used to insert offers into the
+// history_offers table
+type OfferHistoryBatchInsertBuilder interface {
+	Add(offer OfferHistory) error
+	Exec(ctx context.Context) error
+	Len() int
+}
+
+// offerHistoryBatchInsertBuilder is a simple wrapper around db.FastBatchInsertBuilder
+type offerHistoryBatchInsertBuilder struct {
+	session db.SessionInterface
+	builder db.FastBatchInsertBuilder
+	table   string
+}
+
+// NewOfferHistoryBatchInsertBuilder constructs a new OfferHistoryBatchInsertBuilder instance
+func (q *Q) NewOfferHistoryBatchInsertBuilder() OfferHistoryBatchInsertBuilder {
+	return &offerHistoryBatchInsertBuilder{
+		session: q,
+		builder: db.FastBatchInsertBuilder{},
+		table:   "history_offers",
+	}
+}
+
+// Add adds an offer to the batch
+func (i *offerHistoryBatchInsertBuilder) Add(offer OfferHistory) error {
+	return errors.Wrap(i.builder.RowStruct(offer), "failed to add offer history")
+}
+
+// Exec writes the batch of offers to the database.
+func (i *offerHistoryBatchInsertBuilder) Exec(ctx context.Context) error {
+	return i.builder.Exec(ctx, i.session, i.table)
+}
+
+// Len returns the number of items in the batch.
+func (i *offerHistoryBatchInsertBuilder) Len() int {
+	return i.builder.Len()
+}
+
+// LiquidityPoolReservesHistoryBatchInsertBuilder is used to insert liquidity
+// pool reserves into the history_liquidity_pool_reserves table
+type LiquidityPoolReservesHistoryBatchInsertBuilder interface {
+	Add(reserves LiquidityPoolReservesHistory) error
+	Exec(ctx context.Context) error
+	Len() int
+}
+
+// liquidityPoolReservesHistoryBatchInsertBuilder is a simple wrapper around db.FastBatchInsertBuilder
+type liquidityPoolReservesHistoryBatchInsertBuilder struct {
+	session db.SessionInterface
+	builder db.FastBatchInsertBuilder
+	table   string
+}
+
+// NewLiquidityPoolReservesHistoryBatchInsertBuilder constructs a new
+// LiquidityPoolReservesHistoryBatchInsertBuilder instance
+func (q *Q) NewLiquidityPoolReservesHistoryBatchInsertBuilder() LiquidityPoolReservesHistoryBatchInsertBuilder {
+	return &liquidityPoolReservesHistoryBatchInsertBuilder{
+		session: q,
+		builder: db.FastBatchInsertBuilder{},
+		table:   "history_liquidity_pool_reserves",
+	}
+}
+
+// Add adds liquidity pool reserves to the batch
+func (i *liquidityPoolReservesHistoryBatchInsertBuilder) Add(reserves LiquidityPoolReservesHistory) error {
+	return errors.Wrap(i.builder.RowStruct(reserves), "failed to add liquidity pool reserves history")
+}
+
+// Exec writes the batch of liquidity pool reserves to the database.
+func (i *liquidityPoolReservesHistoryBatchInsertBuilder) Exec(ctx context.Context) error {
+	return i.builder.Exec(ctx, i.session, i.table)
+}
+
+// Len returns the number of items in the batch.
+func (i *liquidityPoolReservesHistoryBatchInsertBuilder) Len() int {
+	return i.builder.Len()
+}
+
+// ResetOrderBookHistory seeds the order book history with the offers and
+// liquidity pools currently stored in the offers and liquidity_pools state
+// tables, which must reflect the state at the end of the given ledger. It is
+// used when the order book history has not been ingested yet or has a gap,
+// the order book can be reconstructed at any ledger from the given one
+// onwards.
+//
+// The history before the given ledger is kept, it is removed by the history
+// reaper: offers and liquidity pools recorded in it which are not in the
+// state anymore are recorded as deleted at the given ledger. The history
+// recorded at and after the given ledger is replaced.
+func (q *Q) ResetOrderBookHistory(ctx context.Context, sequence uint32) error {
+	ledgerID := toid.New(int32(sequence), 0, 0).ToInt64()
+	for _, table := range []string{"history_offers", "history_liquidity_pool_reserves"} {
+		_, err := q.ExecRaw(ctx, "DELETE FROM "+table+" WHERE history_ledger_id >= ?", ledgerID)
+		if err != nil {
+			return errors.Wrapf(err, "could not clear %s", table)
+		}
+	}
+
+	_, err := q.ExecRaw(ctx, `
+		INSERT INTO history_offers
+			(offer_id, history_ledger_id, selling_asset, buying_asset, amount, pricen, priced, price, deleted)
+		SELECT ho.offer_id, ?, ho.selling_asset, ho.buying_asset, ho.amount, ho.pricen, ho.priced, ho.price, true
+		FROM (
+			SELECT DISTINCT ON (offer_id) *
+			FROM history_offers
+			ORDER BY offer_id, history_ledger_id DESC
+		) ho
+		WHERE ho.deleted = false AND NOT EXISTS (
+			SELECT 1 FROM offers o WHERE o.offer_id = ho.offer_id AND o.deleted = false
+		)`, ledgerID)
+	if err != nil {
+		return errors.Wrap(err, "could not record removed offers")
+	}
+
+	_, err = q.ExecRaw(ctx, `
+		INSERT INTO history_offers
+			(offer_id, history_ledger_id, selling_asset, buying_asset, amount, pricen, priced, price, deleted)
+		SELECT offer_id, ?, selling_asset, buying_asset, amount, pricen, priced, price, false
+		FROM offers
+		WHERE deleted = false`, ledgerID)
+	if err != nil {
+		return errors.Wrap(err, "could not seed offers history")
+	}
+
+	_, err = q.ExecRaw(ctx, `
+		INSERT INTO history_liquidity_pool_reserves
+			(liquidity_pool_id, history_ledger_id, asset_a, asset_b, reserve_a, reserve_b, fee, deleted)
+		SELECT lpr.liquidity_pool_id, ?, lpr.asset_a, lpr.asset_b, lpr.reserve_a, lpr.reserve_b, lpr.fee, true
+		FROM (
+			SELECT DISTINCT ON (liquidity_pool_id) *
+			FROM history_liquidity_pool_reserves
+			ORDER BY liquidity_pool_id, history_ledger_id DESC
+		) lpr
+		WHERE lpr.deleted = false AND NOT EXISTS (
+			SELECT 1 FROM liquidity_pools lp WHERE lp.id = lpr.liquidity_pool_id AND lp.deleted = false
+		)`, ledgerID)
+	if err != nil {
+		return errors.Wrap(err, "could not record removed liquidity pools")
+	}
+
+	_, err = q.ExecRaw(ctx, `
+		INSERT INTO history_liquidity_pool_reserves
+			(liquidity_pool_id, history_ledger_id, asset_a, asset_b, reserve_a, reserve_b, fee, deleted)
+		SELECT
+			id, ?,
+			asset_reserves->0->>'asset', asset_reserves->1->>'asset',
+			(asset_reserves->0->>'reserve')::bigint, (asset_reserves->1->>'reserve')::bigint,
+			fee, false
+		FROM liquidity_pools
+		WHERE deleted = false`, ledgerID)
+	if err != nil {
+		return errors.Wrap(err, "could not seed liquidity pool reserves history")
+	}
+
+	if err := q.UpdateOrderBookHistoryStartLedger(ctx, sequence); err != nil {
+		return err
+	}
+	return q.UpdateOrderBookHistoryLastLedger(ctx, sequence)
+}
+
+// DeleteOrderBookHistoryRange removes the order book history before end, the
+// exclusive end of a range of history ids removed by the history reaper.
+// Unlike the other history tables, the order book history holds the state of
+// an offer or liquidity pool only at the ledgers in which it changed, so the
+// last state of every offer and liquidity pool before end is kept: the order
+// book can still be reconstructed at end and later ledgers, which become the
+// start of the order book history.
+//
+// It must not be used when reingesting a range: the order book history is
+// not rebuilt by reingestion, so the range and the history before it are
+// left untouched by DeleteRangeAll.
+func (q *Q) DeleteOrderBookHistoryRange(ctx context.Context, start, end int64) (int64, error) {
+	startLedger, err := q.GetOrderBookHistoryStartLedger(ctx)
+	if err != nil {
+		return 0, err
+	}
+	lastLedger, err := q.GetOrderBookHistoryLastLedger(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// nothing is deleted if the range is after the recorded history
+	if startLedger == 0 || uint32(toid.Parse(start).LedgerSequence) > lastLedger {
+		return 0, nil
+	}
+
+	var total int64
+	for table, column := range map[string]string{
+		"history_offers":                  "offer_id",
+		"history_liquidity_pool_reserves": "liquidity_pool_id",
+	} {
+		result, err := q.ExecRaw(
+			context.WithValue(ctx, &db.QueryTypeContextKey, db.DeleteQueryType),
+			fmt.Sprintf(`
+				DELETE FROM %[1]s h
+				WHERE h.history_ledger_id < $1 AND (h.deleted OR EXISTS (
+					SELECT 1 FROM %[1]s newer
+					WHERE newer.%[2]s = h.%[2]s
+						AND newer.history_ledger_id > h.history_ledger_id
+						AND newer.history_ledger_id < $1
+				))`, table, column),
+			end,
+		)
+		if err != nil {
+			return 0, errors.Wrapf(err, "Error clearing %s", table)
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return 0, errors.Wrapf(err, "Error clearing %s", table)
+		}
+		total += count
+	}
+
+	if endLedger := uint32(toid.Parse(end).LedgerSequence); endLedger > startLedger {
+		if err := q.UpdateOrderBookHistoryStartLedger(ctx, endLedger); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// GetOrderBookSummaryAtLedger returns the OrderBookSummary of a trading pair
+// at the end of the given ledger, reconstructed from the order book history.
+//
+// Unlike GetOrderBookSummary, the price levels include the depth implied by
+// the liquidity pool of the trading pair: the amount the pool would trade
+// before its marginal price crosses a price level is added to that level. A
+// side of the order book without offers gets the price levels of the pool,
+// see poolPriceLevels.
+func (q *Q) GetOrderBookSummaryAtLedger(ctx context.Context, sellingAsset, buyingAsset xdr.Asset, sequence uint32, maxPriceLevels int) (OrderBookSummary, error) {
+	var result OrderBookSummary
+
+	selling, err := xdr.MarshalBase64(sellingAsset)
+	if err != nil {
+		return result, errors.Wrap(err, "cannot marshal selling asset")
+	}
+	buying, err := xdr.MarshalBase64(buyingAsset)
+	if err != nil {
+		return result, errors.Wrap(err, "cannot marshal buying asset")
+	}
+	beforeLedgerID := toid.New(int32(sequence+1), 0, 0).ToInt64()
+
+	// Offers can be updated to trade a different pair so the latest version
+	// of every offer which traded the pair at some point is selected before
+	// filtering on the assets.
+	var offers []OfferHistory
+	err = q.SelectRaw(ctx, &offers, `
+		SELECT * FROM (
+			SELECT DISTINCT ON (ho.offer_id) ho.*
+			FROM history_offers ho
+			WHERE ho.offer_id IN (
+				SELECT offer_id FROM history_offers
+				WHERE (selling_asset = $1 AND buying_asset = $2) OR (selling_asset = $2 AND buying_asset = $1)
+			) AND ho.history_ledger_id < $3
+			ORDER BY ho.offer_id, ho.history_ledger_id DESC
+		) o
+		WHERE o.deleted = false AND (
+			(o.selling_asset = $1 AND o.buying_asset = $2) OR (o.selling_asset = $2 AND o.buying_asset = $1)
+		)`, selling, buying, beforeLedgerID)
+	if err != nil {
+		return result, errors.Wrap(err, "cannot select offers")
+	}
+
+	var pools []LiquidityPoolReservesHistory
+	err = q.SelectRaw(ctx, &pools, `
+		SELECT * FROM (
+			SELECT DISTINCT ON (lpr.liquidity_pool_id) lpr.*
+			FROM history_liquidity_pool_reserves lpr
+			WHERE ((lpr.asset_a = $1 AND lpr.asset_b = $2) OR (lpr.asset_a = $2 AND lpr.asset_b = $1))
+				AND lpr.history_ledger_id < $3
+			ORDER BY lpr.liquidity_pool_id, lpr.history_ledger_id DESC
+		) p
+		WHERE p.deleted = false`, selling, buying, beforeLedgerID)
+	if err != nil {
+		return result, errors.Wrap(err, "cannot select liquidity pools")
+	}
+
+	var asks, bids []OfferHistory
+	for _, offer := range offers {
+		if offer.SellingAsset.Equals(sellingAsset) {
+			asks = append(asks, offer)
+		} else {
+			bids = append(bids, offer)
+		}
+	}
+
+	askLevels := aggregateOffers(asks, maxPriceLevels)
+	bidLevels := aggregateOffers(bids, maxPriceLevels)
+	for _, pool := range pools {
+		sellingReserve, buyingReserve := pool.ReserveA, pool.ReserveB
+		if !pool.AssetA.Equals(sellingAsset) {
+			sellingReserve, buyingReserve = buyingReserve, sellingReserve
+		}
+		if len(askLevels) == 0 {
+			askLevels = poolPriceLevels(sellingReserve, buyingReserve, pool.Fee, maxPriceLevels)
+		}
+		if len(bidLevels) == 0 {
+			bidLevels = poolPriceLevels(buyingReserve, sellingReserve, pool.Fee, maxPriceLevels)
+		}
+		addPoolDepth(askLevels, sellingReserve, buyingReserve, pool.Fee)
+		addPoolDepth(bidLevels, buyingReserve, sellingReserve, pool.Fee)
+	}
+	askLevels, bidLevels = askLevels.nonEmpty(), bidLevels.nonEmpty()
+
+	if result.Asks, err = askLevels.priceLevels(false); err != nil {
+		return result, err
+	}
+	if result.Bids, err = bidLevels.priceLevels(true); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// historicalPriceLevel is the sum of the amounts of the offers selling an
+// asset at the same price.
+type historicalPriceLevel struct {
+	price  *big.Rat
+	amount *big.Int
+}
+
+type historicalPriceLevels []historicalPriceLevel
+
+// aggregateOffers aggregates the given offers selling the same asset
+// into at most maxPriceLevels price levels, sorted by ascending price.
+func aggregateOffers(offers []OfferHistory, maxPriceLevels int) historicalPriceLevels {
+	byPrice := map[string]historicalPriceLevel{}
+	for _, offer := range offers {
+		price := big.NewRat(int64(offer.Pricen), int64(offer.Priced))
+		level, ok := byPrice[price.String()]
+		if !ok {
+			level = historicalPriceLevel{price: price, amount: new(big.Int)}
+		}
+		level.amount.Add(level.amount, big.NewInt(offer.Amount))
+		byPrice[price.String()] = level
+	}
+
+	levels := make(historicalPriceLevels, 0, len(byPrice))
+	for _, level := range byPrice {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].price.Cmp(levels[j].price) < 0
+	})
+	if len(levels) > maxPriceLevels {
+		levels = levels[:maxPriceLevels]
+	}
+	return levels
+}
+
+// poolPriceLevelStep is the relative difference between the prices of
+// consecutive price levels created by poolPriceLevels.
+const poolPriceLevelStep = 0.01
+
+// poolPriceLevels returns at most maxPriceLevels empty price levels, sorted by
+// ascending price, for a side of the order book which only has a liquidity
+// pool. The prices of the levels are spaced by poolPriceLevelStep from the
+// marginal price of the pool, addPoolDepth adds the depth of the pool to them.
+func poolPriceLevels(sellingReserve, buyingReserve int64, feeBips int32, maxPriceLevels int) historicalPriceLevels {
+	if sellingReserve <= 0 || buyingReserve <= 0 {
+		return nil
+	}
+	feeMultiplier := 1 - float64(feeBips)/10000
+	marginalPrice := float64(buyingReserve) / (float64(sellingReserve) * feeMultiplier)
+
+	levels := make(historicalPriceLevels, 0, maxPriceLevels)
+	for i := 1; i <= maxPriceLevels; i++ {
+		levelPrice, err := price.Parse(price.StringFromFloat64(marginalPrice * (1 + float64(i)*poolPriceLevelStep)))
+		if err != nil || levelPrice.N <= 0 || levelPrice.D <= 0 {
+			continue
+		}
+		level := big.NewRat(int64(levelPrice.N), int64(levelPrice.D))
+		// prices which cannot be told apart at the precision of a price
+		// level are skipped
+		if len(levels) > 0 && level.Cmp(levels[len(levels)-1].price) <= 0 {
+			continue
+		}
+		levels = append(levels, historicalPriceLevel{price: level, amount: new(big.Int)})
+	}
+	return levels
+}
+
+// addPoolDepth adds to every price level the amount of the selling asset the
+// liquidity pool would sell before its marginal price (in units of the buying
+// asset) exceeds the price of the level and after it exceeds the price of the
+// previous level.
+func addPoolDepth(levels historicalPriceLevels, sellingReserve, buyingReserve int64, feeBips int32) {
+	if sellingReserve <= 0 || buyingReserve <= 0 {
+		return
+	}
+	x, y := float64(sellingReserve), float64(buyingReserve)
+	feeMultiplier := 1 - float64(feeBips)/10000
+
+	var previous float64
+	for _, level := range levels {
+		price, _ := level.price.Float64()
+		// With a constant product x*y = k and a fee charged on the amount
+		// received by the pool, the marginal price after selling s is
+		// x*y / ((x-s)^2 * feeMultiplier).
+		sold := x - math.Sqrt(x*y/(price*feeMultiplier))
+		if sold <= previous {
+			continue
+		}
+		level.amount.Add(level.amount, big.NewInt(int64(sold)-int64(previous)))
+		previous = float64(int64(sold))
+	}
+}
+
+// nonEmpty returns the price levels with a positive amount.
+func (levels historicalPriceLevels) nonEmpty() historicalPriceLevels {
+	result := levels[:0]
+	for _, level := range levels {
+		if level.amount.Sign() > 0 {
+			result = append(result, level)
+		}
+	}
+	return result
+}
+
+func (levels historicalPriceLevels) priceLevels(invert bool) ([]PriceLevel, error) {
+	result := make([]PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		price := new(big.Rat).Set(level.price)
+		if invert {
+			price = price.Inv(price)
+		}
+		entry := PriceLevel{
+			Pricef: price.FloatString(7),
+			Pricen: int32(price.Num().Int64()),
+			Priced: int32(price.Denom().Int64()),
+		}
+		var err error
+		entry.Amount, err = amount.IntStringToAmount(level.amount.String())
+		if err != nil {
+			return nil, errors.Wrap(err, "could not determine price level amount")
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
@@ -0,0 +1,315 @@
+package history
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/toid"
+)
+
+func TestResetOrderBookHistory(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	startLedger, err := q.GetOrderBookHistoryStartLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(0), startLedger)
+
+	deletedOffer := threeEurOffer
+	deletedOffer.Deleted = true
+	tt.Assert.NoError(q.UpsertOffers(tt.Ctx, []Offer{eurOffer, twoEurOffer, deletedOffer}))
+	tt.Assert.NoError(q.UpsertLiquidityPools(tt.Ctx, []LiquidityPool{MakeTestPool(nativeAsset, 100, eurAsset, 200)}))
+
+	tt.Assert.NoError(q.ResetOrderBookHistory(tt.Ctx, 10))
+
+	startLedger, err = q.GetOrderBookHistoryStartLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(10), startLedger)
+
+	var offers []OfferHistory
+	tt.Assert.NoError(q.SelectRaw(tt.Ctx, &offers, "SELECT * FROM history_offers ORDER BY offer_id"))
+	tt.Assert.Len(offers, 2)
+	tt.Assert.Equal(eurOffer.OfferID, offers[0].OfferID)
+	tt.Assert.Equal(twoEurOffer.OfferID, offers[1].OfferID)
+	tt.Assert.Equal(toid.New(10, 0, 0).ToInt64(), offers[1].HistoryLedgerID)
+	tt.Assert.True(offers[1].SellingAsset.Equals(nativeAsset))
+	tt.Assert.True(offers[1].BuyingAsset.Equals(eurAsset))
+
+	var pools []LiquidityPoolReservesHistory
+	tt.Assert.NoError(q.SelectRaw(tt.Ctx, &pools, "SELECT * FROM history_liquidity_pool_reserves"))
+	tt.Assert.Len(pools, 1)
+	tt.Assert.True(pools[0].AssetA.Equals(nativeAsset))
+	tt.Assert.Equal(int64(100), pools[0].ReserveA)
+	tt.Assert.Equal(int64(200), pools[0].ReserveB)
+
+	// resetting again keeps the previous history and records the offers
+	// removed from the state as deleted
+	removedOffer := twoEurOffer
+	removedOffer.Deleted = true
+	tt.Assert.NoError(q.UpsertOffers(tt.Ctx, []Offer{removedOffer}))
+	tt.Assert.NoError(q.ResetOrderBookHistory(tt.Ctx, 20))
+	offers = nil
+	tt.Assert.NoError(q.SelectRaw(tt.Ctx, &offers, "SELECT * FROM history_offers WHERE history_ledger_id = ? ORDER BY offer_id", toid.New(20, 0, 0).ToInt64()))
+	tt.Assert.Len(offers, 2)
+	tt.Assert.Equal(eurOffer.OfferID, offers[0].OfferID)
+	tt.Assert.False(offers[0].Deleted)
+	tt.Assert.Equal(twoEurOffer.OfferID, offers[1].OfferID)
+	tt.Assert.True(offers[1].Deleted)
+	var count int
+	tt.Assert.NoError(q.GetRaw(tt.Ctx, &count, "SELECT count(*) FROM history_offers"))
+	tt.Assert.Equal(4, count)
+
+	startLedger, err = q.GetOrderBookHistoryStartLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(20), startLedger)
+	lastLedger, err := q.GetOrderBookHistoryLastLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(20), lastLedger)
+}
+
+// insertOrderBookHistoryUpdates records an update of the second offer at
+// ledger 12 and the deletion of the first one at ledger 15 after seeding the
+// order book history at ledger 10.
+func insertOrderBookHistoryUpdates(tt *test.T, q *Q) {
+	tt.Assert.NoError(q.UpsertOffers(tt.Ctx, []Offer{eurOffer, twoEurOffer}))
+	tt.Assert.NoError(q.ResetOrderBookHistory(tt.Ctx, 10))
+
+	batch := q.NewOfferHistoryBatchInsertBuilder()
+	tt.Assert.NoError(batch.Add(OfferHistory{
+		OfferID:         twoEurOffer.OfferID,
+		HistoryLedgerID: toid.New(12, 0, 0).ToInt64(),
+		SellingAsset:    twoEurOffer.SellingAsset,
+		BuyingAsset:     twoEurOffer.BuyingAsset,
+		Amount:          100,
+		Pricen:          twoEurOffer.Pricen,
+		Priced:          twoEurOffer.Priced,
+		Price:           twoEurOffer.Price,
+	}))
+	tt.Assert.NoError(batch.Add(OfferHistory{
+		OfferID:         eurOffer.OfferID,
+		HistoryLedgerID: toid.New(15, 0, 0).ToInt64(),
+		SellingAsset:    eurOffer.SellingAsset,
+		BuyingAsset:     eurOffer.BuyingAsset,
+		Amount:          eurOffer.Amount,
+		Pricen:          eurOffer.Pricen,
+		Priced:          eurOffer.Priced,
+		Price:           eurOffer.Price,
+		Deleted:         true,
+	}))
+	tt.Assert.NoError(batch.Exec(tt.Ctx))
+	tt.Assert.NoError(q.UpdateOrderBookHistoryLastLedger(tt.Ctx, 15))
+}
+
+func TestDeleteOrderBookHistoryRange(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+	insertOrderBookHistoryUpdates(tt, q)
+
+	before, err := q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 13, 20)
+	tt.Assert.NoError(err)
+
+	start, end, err := toid.LedgerRangeInclusive(1, 12)
+	tt.Assert.NoError(err)
+	deleted, err := q.DeleteOrderBookHistoryRange(tt.Ctx, start, end)
+	tt.Assert.NoError(err)
+	// only the state of the second offer at ledger 10 is superseded
+	tt.Assert.Equal(int64(1), deleted)
+
+	startLedger, err := q.GetOrderBookHistoryStartLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(13), startLedger)
+
+	after, err := q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 13, 20)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(before, after)
+	tt.Assert.Len(after.Asks, 2)
+
+	// the first offer is removed with its deletion
+	start, end, err = toid.LedgerRangeInclusive(13, 15)
+	tt.Assert.NoError(err)
+	deleted, err = q.DeleteOrderBookHistoryRange(tt.Ctx, start, end)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(2), deleted)
+	var offers []OfferHistory
+	tt.Assert.NoError(q.SelectRaw(tt.Ctx, &offers, "SELECT * FROM history_offers"))
+	tt.Assert.Len(offers, 1)
+	tt.Assert.Equal(twoEurOffer.OfferID, offers[0].OfferID)
+	tt.Assert.Equal(int64(100), offers[0].Amount)
+}
+
+func TestDeleteRangeAllKeepsOrderBookHistory(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+	insertOrderBookHistoryUpdates(tt, q)
+
+	var summaries []OrderBookSummary
+	for sequence := uint32(10); sequence <= 15; sequence++ {
+		summary, err := q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, sequence, 20)
+		tt.Assert.NoError(err)
+		summaries = append(summaries, summary)
+	}
+
+	// reingesting a range in the middle of the history clears the range
+	// before ingesting it again, the order book history isn't rebuilt by
+	// reingestion so it must be left untouched
+	start, end, err := toid.LedgerRangeInclusive(12, 13)
+	tt.Assert.NoError(err)
+	_, err = q.DeleteRangeAll(tt.Ctx, start, end)
+	tt.Assert.NoError(err)
+
+	var offers []OfferHistory
+	tt.Assert.NoError(q.SelectRaw(tt.Ctx, &offers, "SELECT * FROM history_offers"))
+	tt.Assert.Len(offers, 4)
+	startLedger, err := q.GetOrderBookHistoryStartLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(10), startLedger)
+
+	for i, sequence := 0, uint32(10); sequence <= 15; i, sequence = i+1, sequence+1 {
+		summary, err := q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, sequence, 20)
+		tt.Assert.NoError(err)
+		tt.Assert.Equal(summaries[i], summary, "ledger %d", sequence)
+	}
+	tt.Assert.Len(summaries[0].Asks, 2)
+	tt.Assert.Len(summaries[5].Asks, 1)
+}
+
+func TestGetOrderBookSummaryAtLedger(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	tt.Assert.NoError(q.UpsertOffers(tt.Ctx, []Offer{twoEurOffer, threeEurOffer}))
+	tt.Assert.NoError(q.ResetOrderBookHistory(tt.Ctx, 10))
+
+	// at ledger 12 the first offer is deleted and a bid is created
+	batch := q.NewOfferHistoryBatchInsertBuilder()
+	tt.Assert.NoError(batch.Add(OfferHistory{
+		OfferID:         twoEurOffer.OfferID,
+		HistoryLedgerID: toid.New(12, 0, 0).ToInt64(),
+		SellingAsset:    twoEurOffer.SellingAsset,
+		BuyingAsset:     twoEurOffer.BuyingAsset,
+		Amount:          twoEurOffer.Amount,
+		Pricen:          twoEurOffer.Pricen,
+		Priced:          twoEurOffer.Priced,
+		Price:           twoEurOffer.Price,
+		Deleted:         true,
+	}))
+	tt.Assert.NoError(batch.Add(OfferHistory{
+		OfferID:         eurOffer.OfferID,
+		HistoryLedgerID: toid.New(12, 0, 0).ToInt64(),
+		SellingAsset:    eurAsset,
+		BuyingAsset:     nativeAsset,
+		Amount:          300,
+		Pricen:          1,
+		Priced:          4,
+		Price:           0.25,
+	}))
+	tt.Assert.NoError(batch.Exec(tt.Ctx))
+
+	summary, err := q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 11, 20)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]PriceLevel{
+		{Pricen: 2, Priced: 1, Pricef: "2.0000000", Amount: "0.0000500"},
+		{Pricen: 3, Priced: 1, Pricef: "3.0000000", Amount: "0.0000500"},
+	}, summary.Asks)
+	tt.Assert.Empty(summary.Bids)
+
+	summary, err = q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 12, 20)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]PriceLevel{
+		{Pricen: 3, Priced: 1, Pricef: "3.0000000", Amount: "0.0000500"},
+	}, summary.Asks)
+	tt.Assert.Equal([]PriceLevel{
+		{Pricen: 4, Priced: 1, Pricef: "4.0000000", Amount: "0.0000300"},
+	}, summary.Bids)
+
+	summary, err = q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 12, 1)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(summary.Asks, 1)
+	tt.Assert.Len(summary.Bids, 1)
+}
+
+func TestAddPoolDepth(t *testing.T) {
+	levels := historicalPriceLevels{
+		{price: big.NewRat(1, 2), amount: big.NewInt(10)},
+		{price: big.NewRat(1, 1), amount: big.NewInt(10)},
+		{price: big.NewRat(4, 1), amount: big.NewInt(10)},
+	}
+
+	addPoolDepth(levels, 10000000, 10000000, 0)
+	// the marginal price of the pool is above the first two levels
+	assert.Equal(t, int64(10), levels[0].amount.Int64())
+	assert.Equal(t, int64(10), levels[1].amount.Int64())
+	// the pool sells half of its reserve before its price reaches 4
+	assert.Equal(t, int64(5000010), levels[2].amount.Int64())
+
+	levels = historicalPriceLevels{
+		{price: big.NewRat(2, 1), amount: big.NewInt(0)},
+		{price: big.NewRat(4, 1), amount: big.NewInt(0)},
+	}
+	addPoolDepth(levels, 10000000, 10000000, 30)
+	require.Equal(t, int64(2918301), levels[0].amount.Int64())
+	assert.Equal(t, int64(4992483-2918301), levels[1].amount.Int64())
+
+	// empty pools do not add any depth
+	addPoolDepth(levels, 0, 10000000, 30)
+	assert.Equal(t, int64(2918301), levels[0].amount.Int64())
+}
+
+func TestGetOrderBookSummaryAtLedgerPoolOnly(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	tt.Assert.NoError(q.UpsertLiquidityPools(tt.Ctx, []LiquidityPool{MakeTestPool(nativeAsset, 10000000, eurAsset, 20000000)}))
+	tt.Assert.NoError(q.ResetOrderBookHistory(tt.Ctx, 10))
+
+	// without offers both sides get the price levels of the pool
+	summary, err := q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 10, 3)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]PriceLevel{
+		{Pricen: 10130391, Priced: 5000000, Pricef: "2.0260782", Amount: "0.0049628"},
+		{Pricen: 2557673, Priced: 1250000, Pricef: "2.0461384", Amount: "0.0048896"},
+		{Pricen: 10330993, Priced: 5000000, Pricef: "2.0661986", Amount: "0.0048183"},
+	}, summary.Asks)
+	tt.Assert.Equal([]PriceLevel{
+		{Pricen: 2500000, Priced: 1266299, Pricef: "1.9742573", Amount: "0.0099257"},
+		{Pricen: 5000000, Priced: 2557673, Pricef: "1.9549020", Amount: "0.0097792"},
+		{Pricen: 1250000, Priced: 645687, Pricef: "1.9359225", Amount: "0.0096364"},
+	}, summary.Bids)
+}
+
+func TestPoolPriceLevels(t *testing.T) {
+	// the marginal price of the pool, including the fee, is 2/0.997
+	levels := poolPriceLevels(10000000, 20000000, 30, 3)
+	require.Len(t, levels, 3)
+	for i, level := range levels {
+		assert.Zero(t, level.amount.Sign())
+		price, _ := level.price.Float64()
+		assert.InDelta(t, 2/0.997*(1+float64(i+1)*poolPriceLevelStep), price, 0.0000001)
+	}
+
+	addPoolDepth(levels, 10000000, 20000000, 30)
+	result, err := levels.nonEmpty().priceLevels(false)
+	require.NoError(t, err)
+	assert.Equal(t, []PriceLevel{
+		{Pricen: 10130391, Priced: 5000000, Pricef: "2.0260782", Amount: "0.0049628"},
+		{Pricen: 2557673, Priced: 1250000, Pricef: "2.0461384", Amount: "0.0048896"},
+		{Pricen: 10330993, Priced: 5000000, Pricef: "2.0661986", Amount: "0.0048183"},
+	}, result)
+
+	// empty pools have no price levels
+	assert.Empty(t, poolPriceLevels(0, 20000000, 30, 3))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/xdr"
 )
 
 // QParticipants defines ingestion participant related queries.
@@ -11,6 +12,8 @@ type QParticipants interface {
 	QCreateAccountsHistory
 	NewTransactionParticipantsBatchInsertBuilder() TransactionParticipantsBatchInsertBuilder
 	NewOperationParticipantBatchInsertBuilder() OperationParticipantBatchInsertBuilder
+	NewMuxedTransactionParticipantsBatchInsertBuilder() MuxedTransactionParticipantsBatchInsertBuilder
+	NewMuxedOperationParticipantBatchInsertBuilder() MuxedOperationParticipantBatchInsertBuilder
 }
 
 // TransactionParticipantsBatchInsertBuilder is used to insert transaction participants into the
@@ -45,3 +48,50 @@ func (i *transactionParticipantsBatchInsertBuilder) Add(transactionID int64, acc
 func (i *transactionParticipantsBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
 	return i.builder.Exec(ctx, session, i.tableName)
 }
+
+// MuxedTransactionParticipantsBatchInsertBuilder is used to insert the muxed
+// accounts participating in transactions into the
+// history_muxed_transaction_participants table
+type MuxedTransactionParticipantsBatchInsertBuilder interface {
+	Add(transactionID int64, accountID FutureAccountID, muxedID uint64) error
+	Exec(ctx context.Context, session db.SessionInterface) error
+}
+
+type muxedTransactionParticipantsBatchInsertBuilder struct {
+	tableName string
+	builder   db.FastBatchInsertBuilder
+}
+
+// NewMuxedTransactionParticipantsBatchInsertBuilder constructs a new MuxedTransactionParticipantsBatchInsertBuilder instance
+func (q *Q) NewMuxedTransactionParticipantsBatchInsertBuilder() MuxedTransactionParticipantsBatchInsertBuilder {
+	return &muxedTransactionParticipantsBatchInsertBuilder{
+		tableName: "history_muxed_transaction_participants",
+		builder:   db.FastBatchInsertBuilder{},
+	}
+}
+
+// Add adds a new muxed transaction participant to the batch
+func (i *muxedTransactionParticipantsBatchInsertBuilder) Add(transactionID int64, accountID FutureAccountID, muxedID uint64) error {
+	return i.builder.Row(map[string]interface{}{
+		"history_transaction_id": transactionID,
+		"history_account_id":     accountID,
+		"muxed_id":               int64(muxedID),
+	})
+}
+
+// Exec flushes all pending muxed transaction participants to the db
+func (i *muxedTransactionParticipantsBatchInsertBuilder) Exec(ctx context.Context, session db.SessionInterface) error {
+	return i.builder.Exec(ctx, session, i.tableName)
+}
+
+// parseMuxedAddress splits a muxed account (M...) address into the address of
+// the underlying account and the muxed id. Other addresses are returned as is
+// and muxed is false.
+func parseMuxedAddress(address string) (accountAddress string, muxedID uint64, muxed bool) {
+	account, err := xdr.AddressToMuxedAccount(address)
+	if err != nil || account.Type != xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		return address, 0, false
+	}
+	aid := account.ToAccountId()
+	return aid.Address(), uint64(account.Med25519.Id), true
+}
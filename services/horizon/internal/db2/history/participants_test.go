@@ -1,9 +1,11 @@
 package history
 
 import (
+	"math"
 	"testing"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/services/horizon/internal/test"
@@ -69,3 +71,67 @@ func TestTransactionParticipantsBatch(t *testing.T) {
 	}
 	tt.Assert.ElementsMatch(expected, participants)
 }
+
+func TestParseMuxedAddress(t *testing.T) {
+	address := "GAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWQPZW"
+	accountAddress, muxedID, muxed := parseMuxedAddress(address)
+	assert.False(t, muxed)
+	assert.Equal(t, address, accountAddress)
+	assert.Zero(t, muxedID)
+
+	accountAddress, muxedID, muxed = parseMuxedAddress("MAN4WOTCFSASG3J6SGLLQZURDDUVNBQANAHEQJ3PBNDZ74X63UZWRAAAAAAAAAAAAWIHU")
+	assert.True(t, muxed)
+	assert.Equal(t, address, accountAddress)
+	assert.Equal(t, uint64(1<<63+5), muxedID)
+}
+
+type muxedParticipant struct {
+	ID        int64 `db:"id"`
+	AccountID int64 `db:"history_account_id"`
+	MuxedID   int64 `db:"muxed_id"`
+}
+
+func TestMuxedParticipantsBatch(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	accountLoader := NewAccountLoader(ConcurrentInserts)
+	address := keypair.MustRandom().Address()
+	txBatch := q.NewMuxedTransactionParticipantsBatchInsertBuilder()
+	opBatch := q.NewMuxedOperationParticipantBatchInsertBuilder()
+	tt.Assert.NoError(txBatch.Add(1, accountLoader.GetFuture(address), 1))
+	tt.Assert.NoError(txBatch.Add(2, accountLoader.GetFuture(address), math.MaxUint64))
+	tt.Assert.NoError(opBatch.Add(3, accountLoader.GetFuture(address), 1))
+
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+	tt.Assert.NoError(accountLoader.Exec(tt.Ctx, q))
+	tt.Assert.NoError(txBatch.Exec(tt.Ctx, q))
+	tt.Assert.NoError(opBatch.Exec(tt.Ctx, q))
+	tt.Assert.NoError(q.Commit())
+
+	accountID, err := accountLoader.GetNow(address)
+	tt.Assert.NoError(err)
+
+	var participants []muxedParticipant
+	tt.Assert.NoError(q.Select(tt.Ctx, &participants, sq.Select(
+		"history_transaction_id as id", "history_account_id", "muxed_id",
+	).From("history_muxed_transaction_participants").OrderBy("history_transaction_id asc")))
+	tt.Assert.Equal([]muxedParticipant{
+		{ID: 1, AccountID: accountID, MuxedID: 1},
+		{ID: 2, AccountID: accountID, MuxedID: -1},
+	}, participants)
+
+	participants = nil
+	tt.Assert.NoError(q.Select(tt.Ctx, &participants, sq.Select(
+		"history_operation_id as id", "history_account_id", "muxed_id",
+	).From("history_muxed_operation_participants")))
+	tt.Assert.Equal([]muxedParticipant{{ID: 3, AccountID: accountID, MuxedID: 1}}, participants)
+
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+	deleted, err := q.DeleteRangeAll(tt.Ctx, 0, 3)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(2), deleted)
+	tt.Assert.NoError(q.Commit())
+}
@@ -97,14 +97,25 @@ func (q *Q) Transactions() *TransactionsQ {
 	}
 }
 
-// ForAccount filters the transactions collection to a specific account
+// ForAccount filters the transactions collection to a specific account. If
+// aid is a muxed account (M...) address, only the transactions in which the
+// muxed account participates are included.
 func (q *TransactionsQ) ForAccount(ctx context.Context, aid string) *TransactionsQ {
+	address, muxedID, muxed := parseMuxedAddress(aid)
 	var account Account
-	q.Err = q.parent.AccountByAddress(ctx, &account, aid)
+	q.Err = q.parent.AccountByAddress(ctx, &account, address)
 	if q.Err != nil {
 		return q
 	}
 
+	if muxed {
+		q.sql = q.sql.
+			Join("history_muxed_transaction_participants hmtp ON hmtp.history_transaction_id = ht.id").
+			Where("hmtp.history_account_id = ? AND hmtp.muxed_id = ?", account.ID, int64(muxedID))
+		q.txIdCol = "hmtp.history_transaction_id"
+		return q
+	}
+
 	q.sql = q.sql.
 		Join("history_transaction_participants htp ON htp.history_transaction_id = ht.id").
 		Where("htp.history_account_id = ?", account.ID)
@@ -20,6 +20,10 @@ const (
 	// reaping of lookup tables. The value is arbitrary. The only requirement is that
 	// all ingesting nodes use the same value which is why it's hard coded here.
 	lookupTableReaperLockId = 329518896
+	// webhookDeliveryLockId is the objid for the advisory lock acquired during
+	// delivery of webhook payloads. The value is arbitrary. The only requirement is that
+	// all nodes use the same value which is why it's hard coded here.
+	webhookDeliveryLockId = 581730236
 )
 
 // TryStateVerificationLock attempts to acquire the state verification lock
@@ -42,6 +46,14 @@ func (q *Q) TryLookupTableReaperLock(ctx context.Context) (bool, error) {
 	return q.tryAdvisoryLock(ctx, lookupTableReaperLockId)
 }
 
+// TryWebhookDeliveryLock attempts to acquire the webhook delivery lock
+// which gives the node exclusive access to deliver webhook payloads.
+// TryWebhookDeliveryLock returns true if the lock was acquired or false if the
+// lock could not be acquired because it is held by another node.
+func (q *Q) TryWebhookDeliveryLock(ctx context.Context) (bool, error) {
+	return q.tryAdvisoryLock(ctx, webhookDeliveryLockId)
+}
+
 func (q *Q) tryAdvisoryLock(ctx context.Context, lockId int) (bool, error) {
 	if tx := q.GetTx(); tx == nil {
 		return false, errors.New("cannot be called outside of a transaction")
@@ -0,0 +1,181 @@
+package history
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/lib/pq"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/errors"
+)
+
+const (
+	webhookSubscriptionsTableName = "webhook_subscriptions"
+	webhookDeadLettersTableName   = "webhook_dead_letters"
+)
+
+// WebhookSubscription is a row of data from the `webhook_subscriptions` table.
+// LastLedger is the cursor of the subscription: the last ledger whose events
+// were delivered (or dead lettered).
+type WebhookSubscription struct {
+	ID             int64          `db:"id"`
+	URL            string         `db:"url"`
+	Secret         string         `db:"secret"`
+	Accounts       pq.StringArray `db:"accounts"`
+	Assets         pq.StringArray `db:"assets"`
+	OperationTypes pq.Int32Array  `db:"operation_types"`
+	EffectTypes    pq.Int32Array  `db:"effect_types"`
+	LastLedger     uint32         `db:"last_ledger"`
+	FailedAttempts int32          `db:"failed_attempts"`
+	NextAttemptAt  null.Time      `db:"next_attempt_at"`
+	LastError      string         `db:"last_error"`
+	CreatedAt      time.Time      `db:"created_at"`
+}
+
+// PagingToken returns a cursor for this subscription
+func (s WebhookSubscription) PagingToken() string {
+	return strconv.FormatInt(s.ID, 10)
+}
+
+// WebhookDeadLetter is a row of data from the `webhook_dead_letters` table.
+// A dead letter is recorded when the payload of a ledger could not be
+// delivered to a subscription after the maximum number of attempts.
+type WebhookDeadLetter struct {
+	ID             int64     `db:"id"`
+	SubscriptionID int64     `db:"subscription_id"`
+	Ledger         uint32    `db:"ledger"`
+	Payload        string    `db:"payload"`
+	Attempts       int32     `db:"attempts"`
+	LastError      string    `db:"last_error"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// PagingToken returns a cursor for this dead letter
+func (d WebhookDeadLetter) PagingToken() string {
+	return strconv.FormatInt(d.ID, 10)
+}
+
+// QWebhooks defines webhook subscription related queries.
+type QWebhooks interface {
+	InsertWebhookSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error)
+	GetWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	GetWebhookSubscriptionByID(ctx context.Context, id int64) (WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id int64) (int64, error)
+	UpdateWebhookSubscriptionDelivery(ctx context.Context, subscription WebhookSubscription) error
+	InsertWebhookDeadLetter(ctx context.Context, deadLetter WebhookDeadLetter) error
+	GetWebhookDeadLetters(ctx context.Context, subscriptionID int64, page db2.PageQuery) ([]WebhookDeadLetter, error)
+}
+
+// InsertWebhookSubscription creates a new subscription and returns it with
+// its id and creation time populated.
+func (q *Q) InsertWebhookSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error) {
+	sql := sq.Insert(webhookSubscriptionsTableName).SetMap(map[string]interface{}{
+		"url":             subscription.URL,
+		"secret":          subscription.Secret,
+		"accounts":        nonNilStrings(subscription.Accounts),
+		"assets":          nonNilStrings(subscription.Assets),
+		"operation_types": nonNilInt32s(subscription.OperationTypes),
+		"effect_types":    nonNilInt32s(subscription.EffectTypes),
+		"last_ledger":     subscription.LastLedger,
+	}).Suffix("RETURNING *")
+
+	var inserted WebhookSubscription
+	if err := q.Get(ctx, &inserted, sql); err != nil {
+		return WebhookSubscription{}, errors.Wrap(err, "could not insert webhook subscription")
+	}
+	return inserted, nil
+}
+
+// GetWebhookSubscriptions returns all the subscriptions ordered by id.
+func (q *Q) GetWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	var subscriptions []WebhookSubscription
+	sql := sq.Select("*").From(webhookSubscriptionsTableName).OrderBy("id asc")
+	err := q.Select(ctx, &subscriptions, sql)
+	return subscriptions, err
+}
+
+// GetWebhookSubscriptionByID returns the subscription with the given id, the
+// error satisfies q.NoRows() if it does not exist.
+func (q *Q) GetWebhookSubscriptionByID(ctx context.Context, id int64) (WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	sql := sq.Select("*").From(webhookSubscriptionsTableName).Where("id = ?", id)
+	err := q.Get(ctx, &subscription, sql)
+	return subscription, err
+}
+
+// DeleteWebhookSubscription removes the subscription and its dead letters. It
+// returns the number of deleted subscriptions.
+func (q *Q) DeleteWebhookSubscription(ctx context.Context, id int64) (int64, error) {
+	sql := sq.Delete(webhookSubscriptionsTableName).Where("id = ?", id)
+	return q.checkForError(sql, ctx)
+}
+
+// UpdateWebhookSubscriptionDelivery persists the delivery state (cursor,
+// failed attempts, next attempt time and last error) of the subscription.
+func (q *Q) UpdateWebhookSubscriptionDelivery(ctx context.Context, subscription WebhookSubscription) error {
+	nextAttemptAt := subscription.NextAttemptAt
+	if nextAttemptAt.Valid {
+		nextAttemptAt = null.TimeFrom(nextAttemptAt.Time.UTC())
+	}
+	sql := sq.Update(webhookSubscriptionsTableName).SetMap(map[string]interface{}{
+		"last_ledger":     subscription.LastLedger,
+		"failed_attempts": subscription.FailedAttempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      subscription.LastError,
+	}).Where("id = ?", subscription.ID)
+
+	rowCnt, err := q.checkForError(sql, ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not update webhook subscription")
+	}
+	if rowCnt != 1 {
+		return errors.Errorf("webhook subscription %d does not exist", subscription.ID)
+	}
+	return nil
+}
+
+// InsertWebhookDeadLetter records a payload which could not be delivered.
+func (q *Q) InsertWebhookDeadLetter(ctx context.Context, deadLetter WebhookDeadLetter) error {
+	sql := sq.Insert(webhookDeadLettersTableName).SetMap(map[string]interface{}{
+		"subscription_id": deadLetter.SubscriptionID,
+		"ledger":          deadLetter.Ledger,
+		"payload":         deadLetter.Payload,
+		"attempts":        deadLetter.Attempts,
+		"last_error":      deadLetter.LastError,
+	})
+	_, err := q.Exec(ctx, sql)
+	return errors.Wrap(err, "could not insert webhook dead letter")
+}
+
+// GetWebhookDeadLetters returns a page of the dead letters of a subscription.
+func (q *Q) GetWebhookDeadLetters(ctx context.Context, subscriptionID int64, page db2.PageQuery) ([]WebhookDeadLetter, error) {
+	sql := sq.Select("*").
+		From(webhookDeadLettersTableName).
+		Where("subscription_id = ?", subscriptionID)
+	sql, err := page.ApplyTo(sql, "id")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not apply query to page")
+	}
+
+	var deadLetters []WebhookDeadLetter
+	err = q.Select(ctx, &deadLetters, sql)
+	return deadLetters, err
+}
+
+func nonNilStrings(values pq.StringArray) pq.StringArray {
+	if values == nil {
+		return pq.StringArray{}
+	}
+	return values
+}
+
+func nonNilInt32s(values pq.Int32Array) pq.Int32Array {
+	if values == nil {
+		return pq.Int32Array{}
+	}
+	return values
+}
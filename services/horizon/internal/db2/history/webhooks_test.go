@@ -0,0 +1,107 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/lib/pq"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestWebhookSubscriptions(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	first, err := q.InsertWebhookSubscription(tt.Ctx, WebhookSubscription{
+		URL:            "http://localhost:8080/hook",
+		Secret:         "secret",
+		Accounts:       pq.StringArray{"GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"},
+		OperationTypes: pq.Int32Array{1},
+		LastLedger:     10,
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.NotZero(first.ID)
+	tt.Assert.Empty(first.Assets)
+	tt.Assert.Empty(first.EffectTypes)
+	tt.Assert.False(first.NextAttemptAt.Valid)
+
+	second, err := q.InsertWebhookSubscription(tt.Ctx, WebhookSubscription{
+		URL:        "http://localhost:8080/other",
+		Secret:     "other",
+		Assets:     pq.StringArray{"native"},
+		LastLedger: 20,
+	})
+	tt.Assert.NoError(err)
+
+	subscriptions, err := q.GetWebhookSubscriptions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(subscriptions, 2)
+	tt.Assert.Equal(first.ID, subscriptions[0].ID)
+	tt.Assert.Equal(second.ID, subscriptions[1].ID)
+
+	nextAttempt := time.Unix(1700000000, 0).UTC()
+	first.LastLedger = 11
+	first.FailedAttempts = 2
+	first.NextAttemptAt = null.TimeFrom(nextAttempt)
+	first.LastError = "unexpected status code 500"
+	tt.Assert.NoError(q.UpdateWebhookSubscriptionDelivery(tt.Ctx, first))
+
+	updated, err := q.GetWebhookSubscriptionByID(tt.Ctx, first.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(11), updated.LastLedger)
+	tt.Assert.Equal(int32(2), updated.FailedAttempts)
+	tt.Assert.True(updated.NextAttemptAt.Valid)
+	tt.Assert.True(nextAttempt.Equal(updated.NextAttemptAt.Time))
+	tt.Assert.Equal("unexpected status code 500", updated.LastError)
+
+	for _, ledger := range []uint32{11, 12, 13} {
+		tt.Assert.NoError(q.InsertWebhookDeadLetter(tt.Ctx, WebhookDeadLetter{
+			SubscriptionID: first.ID,
+			Ledger:         ledger,
+			Payload:        `{"ledger":1}`,
+			Attempts:       5,
+			LastError:      "timeout",
+		}))
+	}
+
+	deadLetters, err := q.GetWebhookDeadLetters(tt.Ctx, first.ID, db2.PageQuery{Order: "asc", Limit: 2})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deadLetters, 2)
+	tt.Assert.Equal(uint32(11), deadLetters[0].Ledger)
+	tt.Assert.JSONEq(`{"ledger":1}`, deadLetters[0].Payload)
+
+	deadLetters, err = q.GetWebhookDeadLetters(tt.Ctx, first.ID, db2.PageQuery{
+		Cursor: deadLetters[1].PagingToken(),
+		Order:  "asc",
+		Limit:  2,
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deadLetters, 1)
+	tt.Assert.Equal(uint32(13), deadLetters[0].Ledger)
+
+	deadLetters, err = q.GetWebhookDeadLetters(tt.Ctx, second.ID, db2.PageQuery{Order: "asc", Limit: 10})
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(deadLetters)
+
+	deleted, err := q.DeleteWebhookSubscription(tt.Ctx, first.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), deleted)
+
+	_, err = q.GetWebhookSubscriptionByID(tt.Ctx, first.ID)
+	tt.Assert.True(q.NoRows(err))
+
+	deadLetters, err = q.GetWebhookDeadLetters(tt.Ctx, first.ID, db2.PageQuery{Order: "asc", Limit: 10})
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(deadLetters)
+
+	deleted, err = q.DeleteWebhookSubscription(tt.Ctx, first.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), deleted)
+
+	tt.Assert.Error(q.UpdateWebhookSubscriptionDelivery(tt.Ctx, first))
+}
@@ -65,7 +65,14 @@
 // migrations/66_contract_asset_stats.sql (583B)
 // migrations/67_remove_unused_indexes.sql (2.897kB)
 // migrations/68_remove_deprecated_fields_from_exp_asset_stats.sql (471B)
+// migrations/69_contract_events.sql (744B)
 // migrations/6_create_assets_table.sql (366B)
+// migrations/70_contract_asset_balance_holders.sql (326B)
+// migrations/71_account_balance_history.sql (723B)
+// migrations/72_order_book_history.sql (1.227kB)
+// migrations/73_webhooks.sql (1.156kB)
+// migrations/74_api_keys.sql (504B)
+// migrations/75_muxed_participants.sql (1.320kB)
 // migrations/7_modify_trades_table.sql (2.303kB)
 // migrations/8_add_aggregators.sql (907B)
 // migrations/8_create_asset_stats_table.sql (441B)
@@ -1438,6 +1445,26 @@ func migrations68_remove_deprecated_fields_from_exp_asset_statsSql() (*asset, er
 	return a, nil
 }
 
+var _migrations69_contract_eventsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x92\x4f\x4f\xf3\x30\x0c\xc6\xef\xf9\x14\x56\x4f\xab\xde\xed\xf0\xc2\xd8\x65\xa7\xc1\x2a\x34\x69\xea\x60\xac\x12\xb7\x28\x4b\xac\x36\x52\x9b\x54\x8e\xd9\x1f\x3e\x3d\xa2\xb0\xaa\x2a\x9b\x06\xd7\x9f\xe3\x3c\x8f\xfd\x78\x34\x82\x7f\x95\xcd\x49\x31\x42\x56\x0b\xf1\xb0\x4e\x66\x9b\x04\x36\xb3\xfb\x65\x02\x85\x0d\xec\xe9\x28\xb5\x77\x4c\x4a\xb3\xc4\x1d\x3a\x0e\x30\x10\x00\xd0\x56\x7d\x8d\xa4\xd8\x7a\x27\xad\x81\xad\xcd\xad\x63\x48\x57\x1b\x48\xb3\xe5\x72\xd8\xbc\x8c\x3c\x19\xa4\x08\xac\x63\xcc\x91\x7a\x55\x26\xe5\x82\xd2\xcd\x0f\x85\x0a\x05\xe8\x42\x7d\xaa\x21\xc1\x4e\xd1\xd1\xba\x7c\x30\x19\xc7\xbd\xa6\x12\x4d\x8e\x24\x75\xe9\x03\x1a\xa9\x18\xd8\x56\x18\x58\x55\x35\xec\x2d\x17\xfe\xed\x8b\xc0\xbb\x77\xd8\x6b\x6d\xa7\xb1\xe6\x8c\xd4\xdd\x24\xfe\xb6\x75\xac\x11\x42\xa5\xca\xf2\xe7\x40\xec\x6b\xab\xff\x03\xe3\x81\x3b\xe0\xa6\x0f\x6e\xfb\x60\xdc\x01\xcd\x2e\xe5\xc1\x50\xc3\x5a\x01\x11\x4f\xdb\x14\xb2\x74\xf1\x9c\x25\xb0\x48\xe7\xc9\x2b\x44\xd6\x19\x3c\xc8\x0b\x99\xc8\x66\xfd\x11\xac\xd2\x8b\xa9\x65\x2f\x8b\xf4\x11\xb6\x4c\x88\x30\x38\x17\xde\xf0\x14\x54\x3c\x3d\x39\xf8\xad\x74\x8b\xfe\xe2\xa1\xd3\x34\x84\x6b\x86\x44\xf7\x52\xe7\x7e\xef\x84\x98\xaf\x57\x4f\x57\x2e\x55\xab\xa0\x95\xc1\xa9\xf8\x18\x00\x48\x4b\x4f\xac\xe8\x02\x00\x00")
+
+func migrations69_contract_eventsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations69_contract_eventsSql,
+		"migrations/69_contract_events.sql",
+	)
+}
+
+func migrations69_contract_eventsSql() (*asset, error) {
+	bytes, err := migrations69_contract_eventsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/69_contract_events.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xea, 0xf, 0x29, 0x73, 0x58, 0x45, 0xbe, 0xd9, 0xba, 0x3f, 0x97, 0x34, 0x80, 0xd6, 0xac, 0x92, 0x3a, 0xd9, 0x29, 0xbd, 0x44, 0xb0, 0xa7, 0x19, 0xd8, 0xa0, 0x3, 0xfe, 0x91, 0x78, 0xd8, 0x91}}
+	return a, nil
+}
+
 var _migrations6_create_assets_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x3d\x4f\xc3\x30\x18\x84\x77\xff\x8a\x1b\x1d\x91\x0e\x20\xe8\x92\xc9\x34\x16\x58\x18\xa7\xb8\x31\xa2\x53\xe5\x26\x16\x78\x80\x54\xb6\x11\xca\xbf\x47\xaa\x28\xf9\x50\xe6\x7b\xf4\xbc\xef\xdd\x6a\x85\xab\x4f\xff\x1e\x6c\x72\x30\x27\xb2\xd1\x9c\xd5\x1c\x35\xbb\x97\x1c\x1f\x3e\xa6\x2e\xf4\x07\x1b\xa3\x4b\x11\x94\x00\x80\x6f\xb1\xe3\x5a\x30\x89\xad\x16\xcf\x4c\xef\xf1\xc4\xf7\xc8\xcf\xd9\x19\x3c\xa4\xfe\xe4\xf0\xca\xf4\xe6\x91\x69\xba\xbe\xcd\xa0\xaa\x1a\xca\x48\x39\x86\x9a\xae\x1d\xa0\xeb\x9b\x65\xc8\xc7\xf8\xed\xc2\x3f\x76\xb7\x9e\x63\x46\x89\x17\xc3\xe9\xa0\xcc\x47\x3f\xe4\x13\x4b\x46\xb2\x82\x5c\xfa\x09\x55\xf2\xb7\xbf\xf8\xd8\x5f\xee\x54\x6a\x5e\xd9\xec\x84\x7a\xc0\x31\x05\xe7\x40\x27\xb6\x82\x90\xf1\x74\x65\xf7\xf3\x45\x4a\x5d\x6d\x97\xa7\x6b\x6c\x6c\x6c\xeb\x8a\xdf\x00\x00\x00\xff\xff\xfb\x53\x3e\x81\x6e\x01\x00\x00")
 
 func migrations6_create_assets_tableSqlBytes() ([]byte, error) {
@@ -1458,6 +1485,126 @@ func migrations6_create_assets_tableSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations70_contract_asset_balance_holdersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x8f\xc1\x8a\x83\x30\x14\x45\xf7\xef\x2b\x2e\xae\x66\x98\xf1\x0b\xb2\x8a\x26\x14\xc1\xc6\x62\x23\xb4\xab\x10\x35\xb4\x82\xd5\x12\x03\xa5\x7f\x5f\xa8\x20\xa5\x20\xb8\x7f\xf7\x9c\x77\xe2\x18\x7f\xb7\xee\xe2\x6d\x70\xa8\xee\x44\x3c\xd7\xb2\x84\xe6\x49\x2e\xd1\x8c\x43\xf0\xb6\x09\xc6\x4e\x93\x0b\xa6\xb6\xbd\x1d\x1a\x37\x81\x0b\x81\xb4\xc8\xab\xbd\xc2\x75\xec\x5b\xe7\x4d\xd7\x22\x39\x6b\xc9\x19\xa5\xa5\xe4\x5a\x22\x53\x42\x9e\x10\xad\x10\x4c\xfd\x34\xf3\x32\x42\xa1\x56\x3d\xd5\x31\x53\x3b\xd4\xc1\x3b\x87\x9f\xc5\xf4\x8f\xf9\x6c\x59\x75\xed\x2f\x23\xfa\x0c\x11\xe3\x63\x20\x12\x65\x71\xd8\xfe\x08\xdb\x94\xfe\x66\x7e\xb7\x33\x7a\x0d\x00\xd1\xeb\x51\x67\x46\x01\x00\x00")
+
+func migrations70_contract_asset_balance_holdersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations70_contract_asset_balance_holdersSql,
+		"migrations/70_contract_asset_balance_holders.sql",
+	)
+}
+
+func migrations70_contract_asset_balance_holdersSql() (*asset, error) {
+	bytes, err := migrations70_contract_asset_balance_holdersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/70_contract_asset_balance_holders.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdf, 0x10, 0x64, 0xc9, 0x6c, 0xde, 0xfe, 0x87, 0x8e, 0x44, 0x3e, 0x2a, 0xbe, 0x36, 0x8a, 0xd1, 0xe4, 0x27, 0xfa, 0x1b, 0xe8, 0x3, 0x4c, 0x83, 0xd5, 0x96, 0xcc, 0xbf, 0x57, 0xda, 0xca, 0xe4}}
+	return a, nil
+}
+
+var _migrations71_account_balance_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x52\x41\x6a\xc3\x30\x10\xbc\xeb\x15\x43\x4e\x09\x4d\x5e\x90\x53\x5a\x9b\x62\x30\x72\x9b\x5a\xd0\x9b\x90\xa5\xc5\x11\xd8\x52\xb0\x14\xd2\xf6\xf5\x25\x4d\xec\x16\x17\xd3\xe2\xeb\xcc\x2c\x33\xb3\xbb\x9b\x0d\xee\x5a\x5b\x77\x2a\x12\xc4\x91\xb1\x87\x7d\xba\x2b\x53\x94\xbb\xfb\x3c\xc5\xc1\x86\xe8\xbb\x77\xa9\xb4\xf6\x27\x17\x65\xa5\x1a\xe5\x34\x05\x2c\x19\x80\x81\x6e\xc8\xd4\xd4\x49\x6b\x50\xd9\xda\xba\x08\x5e\x94\xe0\x22\xcf\xd7\x5f\xb2\x1b\xad\x1b\x1f\xc8\x48\x15\x11\x6d\x4b\x21\xaa\xf6\x88\xb3\x8d\x07\x7f\xba\x22\xf8\xf0\x8e\x46\xa3\xe3\x00\x53\x16\x83\x2e\x04\x9a\x56\xdd\xe2\x8f\x49\xb6\xda\x0e\xbd\x05\xcf\x9e\x45\x8a\x8c\x27\xe9\x2b\x16\xd6\x19\x7a\x93\x53\x5b\x90\xde\xf5\xd8\x02\x05\xc7\x94\x0e\xe2\x25\xe3\x8f\xa8\x62\x47\x84\xe5\x58\x65\xcd\xfa\xf7\x22\xbf\xa1\xbe\xd1\x6a\xdb\x47\xfc\x7f\xb6\xcb\xe8\x9c\x64\xf3\x2d\xaf\xa7\x9e\xe1\x39\x34\xbf\xdc\xe2\xe7\x4f\x26\xfe\xec\x18\x4b\xf6\xc5\xd3\x5f\x3f\xa9\x55\xd0\xca\xd0\x96\x7d\x0e\x00\x42\xd9\x74\xe1\xd3\x02\x00\x00")
+
+func migrations71_account_balance_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations71_account_balance_historySql,
+		"migrations/71_account_balance_history.sql",
+	)
+}
+
+func migrations71_account_balance_historySql() (*asset, error) {
+	bytes, err := migrations71_account_balance_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/71_account_balance_history.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2, 0xec, 0x7d, 0x13, 0x36, 0xae, 0x56, 0xf6, 0xb4, 0x6e, 0x38, 0x9a, 0xa2, 0xe, 0x3a, 0xf, 0xbd, 0x7a, 0x82, 0xec, 0x66, 0xb9, 0x5c, 0xf5, 0x5e, 0xb7, 0x9, 0xb5, 0x27, 0xe0, 0x13, 0x9f}}
+	return a, nil
+}
+
+var _migrations72_order_book_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x94\x41\x6f\xe2\x30\x10\x85\xef\xfe\x15\x23\x4e\xa0\x0d\xbf\x20\x27\x76\x89\x56\x48\x28\xac\x58\x90\x7a\xb3\xec\x78\x48\x47\x32\x76\x6a\x3b\x2d\xfc\xfb\xaa\x11\x69\x9b\x60\x53\xc4\x31\xfa\x5e\x3c\xe3\xf7\x9e\x3c\x9f\xc3\xaf\x23\xd5\x4e\x04\x84\x7d\xc3\xd8\x9f\x6d\xb1\xd8\x15\xb0\x5b\xfc\x5e\x17\xf0\x4c\x3e\x58\x77\xe6\xf6\x70\x40\xe7\x61\xca\x00\x00\xba\x0f\x4e\x0a\x24\xd5\x64\x02\x94\x9b\x1d\x94\xfb\xf5\x3a\xeb\x68\xff\x8b\x46\x55\xdf\x90\x79\xd4\x9a\x4c\xcd\x85\xf7\x18\x20\xe0\x69\x2c\x90\xed\xf9\x26\x17\x47\xdb\x9a\x10\x3f\xbc\x71\x54\xa1\x01\x32\x01\x6b\x74\x31\xa8\x6e\x41\x50\xb6\x95\x1a\xa1\x71\x58\x91\x27\x6b\x46\x22\x85\x1a\x03\x2a\x90\xd6\x6a\x14\x5f\x94\xcd\xf2\x4f\xff\x56\xe5\xb2\x78\x82\x09\x19\x85\x27\x3e\xb4\x91\x5b\xc3\x49\x4d\x60\x53\x8e\xfd\xdd\xff\x5f\x95\x7f\x41\x06\x87\x08\xd3\xde\xe6\xec\xda\xd2\x59\x7e\xe7\x98\xce\x3d\xff\xe3\xa8\x41\x18\xd9\xc0\xfa\x59\x9e\xa8\x84\xa6\x97\x96\x14\x85\x33\x6f\xac\xd5\xdc\xa1\x47\xf7\x8a\x7d\x47\x46\x94\x54\x2c\xc2\xab\x7b\xc5\xd3\xec\xf6\xe0\x22\x5a\x82\x0e\xc9\x18\xba\x2c\xc4\x45\xfc\xd0\x1e\xcb\x38\x3e\x20\x26\x1a\xf2\x70\xf8\x09\xc3\x22\x6d\x48\x28\x87\x99\x8d\x44\x8f\xf4\x24\x31\x27\x51\x9c\xbb\xb6\xba\x64\x95\xf5\xc9\x7c\xd4\xe7\xfb\x0b\xb3\xb4\x6f\x86\xb1\xe5\x76\xf3\x2f\xfe\xc2\x54\xc2\x57\x42\x61\x1e\x93\xa4\x16\xa8\x84\xaf\x84\xc2\x9c\xbd\x0f\x00\x6a\xc0\x65\x8c\xcb\x04\x00\x00")
+
+func migrations72_order_book_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations72_order_book_historySql,
+		"migrations/72_order_book_history.sql",
+	)
+}
+
+func migrations72_order_book_historySql() (*asset, error) {
+	bytes, err := migrations72_order_book_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/72_order_book_history.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa5, 0xc0, 0x18, 0x35, 0x97, 0x5f, 0x89, 0x6, 0x6f, 0x34, 0x3a, 0xe7, 0x9, 0x42, 0x1f, 0x27, 0x68, 0xdf, 0x5, 0x7, 0x9b, 0x17, 0x9e, 0x9b, 0x80, 0x3d, 0xfb, 0xfb, 0x4f, 0x22, 0xcb, 0x3}}
+	return a, nil
+}
+
+var _migrations73_webhooksSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x94\x41\x6f\x9b\x40\x10\x85\xef\xfc\x8a\x51\x2e\xb6\xd5\x44\xea\xdd\x27\x0a\x9b\xca\x2a\xc5\x11\x06\xa9\x51\x55\xad\x16\x76\x4c\xb6\x85\x5d\xb4\x3b\x08\xa7\x55\xff\x7b\xe5\x40\x5c\x92\x60\x9a\x5e\x72\xdd\xf7\xbd\xd1\xf0\xe6\x89\xab\x2b\x78\x57\xab\xd2\x0a\x42\xc8\x1a\xcf\x0b\x12\xe6\xa7\x0c\x52\xff\x43\xc4\xa0\xc3\xfc\xce\x98\x1f\xdc\xb5\xb9\x2b\xac\x6a\x48\x19\xed\x60\xe9\x01\x00\x28\x09\xb9\x2a\x1d\x5a\x25\x2a\xb8\x49\x36\x9f\xfd\xe4\x16\x3e\xb1\xdb\xcb\x07\xb5\xb5\x15\x10\x1e\x08\xe2\x6d\x0a\x71\x16\x45\xfd\xb3\xc3\xc2\x22\x4d\x29\xa2\x28\x4c\xab\xc9\x3d\x68\x5f\xbf\x9d\x54\x08\xd9\xb5\x9f\x45\x29\x2c\x7e\xfd\x5e\x0c\xa8\x73\xf8\x2a\xd0\x34\x68\xc5\x71\x67\x4e\xf7\x0d\x3a\x50\x9a\xb0\x44\x3b\x6f\xc2\xfd\x1e\x0b\xfa\x1f\x47\x25\x1c\xf1\x0a\x65\x89\xf6\xd1\x70\xc2\x7b\x62\x2f\x54\x85\x92\x0b\x22\xac\x1b\x72\x2f\xa8\xd3\xd0\xf7\x3d\xaf\xf1\x40\x8f\x34\x17\x04\xa4\x6a\x74\x24\xea\x06\x3a\x45\x77\xa6\xed\x5f\xe0\xa7\xd1\x38\x5a\x01\xad\x35\xf6\x69\xb6\x7f\xb7\x1d\x76\x2d\x2c\x0a\x42\xf9\x8f\xa1\x2f\xfd\x4b\x6d\xba\xe5\x0a\x06\x5b\x0f\x2d\x5a\x2a\x16\x2b\x6f\xb5\x3e\xd3\x1a\x89\x42\xf2\x0a\x89\xd0\xbe\xae\x34\xe3\x9a\xf1\x1e\x55\x7a\xf4\x31\x09\xbb\x66\x09\x8b\x03\xb6\x3b\xd7\x4c\x25\x57\xb0\x8d\x21\x64\x11\x4b\x19\x04\xfe\x2e\xf0\x43\x36\x44\x34\x77\xa0\x46\xdc\x57\x46\x48\xf8\xee\x8c\xce\x9f\x69\x67\xaf\x36\x1f\xfd\x5b\xe4\xbd\x89\x43\xf6\x05\x2e\x94\x96\x78\xe0\x53\xb1\x73\xa3\x9f\x44\xc4\x95\xbc\x38\x06\x34\xc5\x42\xb6\xdb\xc4\x1f\x21\x27\x8b\x08\xcb\x67\xae\x4b\x50\xf2\x78\xe8\xf1\xef\x22\x34\x9d\xf6\xbc\x30\xd9\xde\xcc\x1c\x7e\x3d\x05\x8c\x87\xbb\xb5\xf7\x67\x00\xa1\xed\x26\x8d\x84\x04\x00\x00")
+
+func migrations73_webhooksSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations73_webhooksSql,
+		"migrations/73_webhooks.sql",
+	)
+}
+
+func migrations73_webhooksSql() (*asset, error) {
+	bytes, err := migrations73_webhooksSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/73_webhooks.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x74, 0xab, 0xee, 0x12, 0xf5, 0xc8, 0x1f, 0x7, 0xf9, 0xff, 0xe4, 0x9d, 0x92, 0x18, 0x1f, 0xb9, 0x1e, 0x6a, 0x5c, 0x54, 0x74, 0x2d, 0xe7, 0x9d, 0x3c, 0x82, 0x6f, 0x45, 0x3b, 0x93, 0x51, 0x9d}}
+	return a, nil
+}
+
+var _migrations74_api_keysSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x91\xcd\x4a\xc3\x40\x14\x85\xf7\xf3\x14\x67\xd7\x16\x2d\xb8\xef\x2a\xda\x11\x8a\x31\xad\x21\x59\x74\x35\x8c\xc9\x35\x19\xea\xfc\x38\x73\x43\x5a\x9f\x5e\x68\x04\x45\xc1\x6e\xdc\xce\xf9\xbe\x33\x17\xce\x72\x89\x2b\x6b\xba\xa8\x99\x50\x07\x21\xee\x4a\x99\x55\x12\x55\x76\x9b\x4b\xe8\x60\xd4\x81\x4e\x09\x73\x01\x00\xa6\xc5\xb3\xe9\x12\x45\xa3\x5f\xb1\x2b\x37\x8f\x59\xb9\xc7\x83\xdc\x5f\x9f\x53\xa7\x2d\x81\xe9\xc8\x28\xb6\x15\x8a\x3a\xcf\x51\x17\x9b\xa7\x5a\x4e\xf1\x81\x4e\xaa\xd7\xa9\xff\x03\x89\xf4\x36\x50\xe2\xa4\x02\x45\xd5\xfb\x21\xc2\x38\xa6\x8e\xe2\x17\xbe\x96\xf7\x59\x9d\x57\xb8\x99\x4a\xad\x3e\xaa\xc4\x91\xb4\x4d\x17\xd9\xa0\xb9\x57\x2f\xc6\xb5\xc6\x75\xe7\x1f\x12\x35\xde\xb5\x17\xbd\x26\x92\x66\x6a\x95\x66\xb0\xb1\x94\x58\xdb\x80\xd1\x70\xef\x87\xe9\x05\xef\xde\xd1\x6f\x7d\xee\xfc\x38\x5f\xe0\x53\x9b\xa0\xd9\xc0\xcd\x6c\x31\xdd\x3e\x84\xf6\x9f\x7b\xc5\x62\x25\xc4\xf7\x41\xd7\x7e\x74\x42\xac\xcb\xed\xee\xc7\xa0\x2b\xf1\x31\x00\x9b\x4c\xcf\xc9\xf8\x01\x00\x00")
+
+func migrations74_api_keysSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations74_api_keysSql,
+		"migrations/74_api_keys.sql",
+	)
+}
+
+func migrations74_api_keysSql() (*asset, error) {
+	bytes, err := migrations74_api_keysSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/74_api_keys.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x47, 0xc1, 0xc, 0x32, 0x84, 0x68, 0xd2, 0xf, 0xd7, 0x4d, 0x86, 0x9a, 0x54, 0x1e, 0x61, 0x6a, 0x91, 0x8, 0xbc, 0xa1, 0x65, 0x3e, 0xc8, 0x43, 0xf5, 0x8e, 0x22, 0xd4, 0xab, 0x8b, 0xa7, 0x26}}
+	return a, nil
+}
+
+var _migrations75_muxed_participantsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x94\xc1\x6e\xf2\x30\x10\x84\xef\x7e\x8a\x15\x27\xd0\x9f\xfc\xa7\xaa\x97\x9c\x68\x89\xda\x48\x28\xb4\x40\xd4\xde\x2c\x13\x9b\xc4\x52\xb1\x2d\x7b\x51\xe1\xed\xab\x86\x24\x24\xa4\xa1\xb4\xf4\x9a\x9d\xf5\x7e\xb3\x13\xdb\xf7\xe1\xdf\x46\x66\x96\xa1\x80\xc4\x10\xe2\xfb\xb0\xd9\xee\x04\xa7\x92\x43\xae\xdf\xb8\x03\xcc\x05\x6c\xa5\xc2\xdb\x1b\x90\x1c\xf4\xba\xf8\x50\x68\x80\xa5\xa9\xde\x2a\x84\x94\x39\x04\xd4\xc0\xc0\xc9\x4c\x09\x0e\x52\xa1\xc8\x84\xfd\x4f\xee\xe7\xe1\x78\x19\xc2\x72\x7c\x37\x0d\x21\x97\x0e\xb5\xdd\xd3\xa2\x99\xa2\x65\xca\xb1\x14\xa5\x56\xd4\x30\x8b\x32\x95\x86\x29\x74\x30\x24\x00\x50\x8b\x9b\x32\xc9\x61\x25\x33\xa9\x10\xe2\xd9\x12\xe2\x64\x3a\xf5\x5a\xda\x92\xa7\x57\x57\x3b\x3b\xa9\x92\x51\x40\x2a\xd4\x24\x8e\x9e\x93\x10\xa2\x78\x12\xbe\x16\x07\x57\xb8\x3b\x6a\x3e\x0f\x9e\xc5\x97\xfa\x48\x16\x51\xfc\x00\x2b\xb4\x42\xc0\xb0\x8b\xe8\xd5\x8b\xf6\x7a\xcc\x8e\x82\x0a\xaa\xa4\xd9\xa0\xa1\xab\x3d\xcd\xf1\x7a\x8e\xce\xa4\x73\x51\x69\x23\x2c\xfb\x2e\xa8\xa3\xa8\x6f\xfd\xdd\x1d\xfc\x7d\x4c\xda\xf4\xc4\xd4\xe3\xe1\x17\x21\x35\x8d\x76\x23\xd2\x87\x88\xf4\xb5\x0c\x27\x53\xda\x63\xa4\xe2\x62\x47\x2b\xa9\x58\xaf\x45\x8a\x8e\x6a\x45\x19\xe7\x56\x38\x77\xf0\xdc\x04\x28\x35\xed\x59\x2d\xf5\xd7\x06\x3d\x18\x68\xcb\x85\x1d\x8c\xe0\xe5\x31\x9c\x87\xd0\x9e\x10\x2d\xea\x74\x02\x42\x9a\x8f\xc9\x44\xbf\x2b\x42\x26\xf3\xd9\xd3\x8f\x98\x83\x66\x4b\x73\x9b\x27\x85\xe3\x4d\x28\x0b\x97\xff\xb7\x67\x1a\xfa\xee\x50\x40\x3e\x06\x00\x45\xe2\x24\xa3\x28\x05\x00\x00")
+
+func migrations75_muxed_participantsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations75_muxed_participantsSql,
+		"migrations/75_muxed_participants.sql",
+	)
+}
+
+func migrations75_muxed_participantsSql() (*asset, error) {
+	bytes, err := migrations75_muxed_participantsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/75_muxed_participants.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd9, 0x75, 0x4e, 0x1f, 0xab, 0xd4, 0x32, 0x13, 0x6f, 0xa0, 0x24, 0x66, 0xfc, 0xbe, 0x49, 0x6f, 0xd4, 0xe, 0x61, 0xfb, 0xb8, 0xd4, 0x6a, 0x7, 0xf2, 0x97, 0x97, 0xea, 0x78, 0xa7, 0xb9, 0x91}}
+	return a, nil
+}
+
 var _migrations7_modify_trades_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x54\x4d\x8f\xda\x30\x14\xbc\xe7\x57\x3c\xed\x29\x51\xc3\xaa\xad\xda\xbd\x6c\x55\x09\x58\x97\x46\x65\xc3\x36\x04\xa9\xb7\xc8\x89\xdf\x06\xab\xc1\x8e\x6c\xa7\x88\x7f\x5f\x05\x08\xcd\x27\xb0\xbb\x87\x5e\x93\x99\x79\x6f\xec\xf1\x8c\x46\xf0\x6e\xc3\x53\x45\x0d\xc2\x2a\xb7\x46\x23\x60\x4a\xe6\x60\xd6\x08\x32\x63\x60\x14\x65\xa8\xc1\xd0\x38\xc3\x5b\xc8\x0b\x03\x14\x04\x6e\x41\x0a\x04\x2e\x20\xcf\x68\x82\xd6\x43\xb0\x78\x82\x70\x3c\x99\x13\x58\x73\x6d\xa4\xda\x45\x07\xde\xbd\x35\x0d\xc8\x38\x24\xbd\x3f\xc1\xb6\x00\xe0\xf4\x51\xe6\xa8\xa8\xe1\x52\x44\x9c\xc1\xc4\x9b\x79\x7e\x08\xfe\x22\x04\x7f\x35\x9f\xbb\x7b\xe4\x8d\x54\x0c\xd5\x0d\x78\x7e\x48\x66\x24\x68\xfd\xcd\x90\xa5\xa8\xa2\x24\x93\x1a\x59\x44\x0d\x84\xde\x23\x59\x86\xe3\xc7\xa7\x16\x50\x3e\x3f\xa3\x1a\x1c\x12\x53\x8d\x11\x4d\x12\x59\x08\xd3\x03\x82\x80\x7c\x23\x01\xf1\xa7\x64\x79\xda\xfc\x88\xd6\x36\x67\x4e\x5d\x44\x6b\xbc\x5a\xa2\xc4\x76\x04\x36\xa5\x6c\x87\x3e\xfd\x4e\xa6\x3f\xc0\xae\x43\xbe\xc2\xfb\x23\x71\xbf\x09\xaa\x37\x3b\x38\xe9\xbc\xc1\xc4\x49\xe3\xac\x8f\x16\xea\x9f\x95\xbd\x41\xae\x23\x8d\x59\x86\x0a\x26\x8b\xc5\x9c\x8c\xfd\xc3\xbf\x3d\xd7\x6e\x1e\xf3\x97\xce\xd2\x8e\xe5\xdc\x5b\x55\x04\x57\xbe\xf7\x73\x45\xc0\xf3\x1f\xc8\x2f\x58\x1b\xc5\xa2\x9c\x33\x58\xf8\xed\x54\xae\x96\x9e\x3f\x83\xd8\x28\x44\xb0\xfb\xc2\xe9\x56\x41\x74\x4e\xf1\xae\x8b\x52\xae\x22\xc3\x37\x18\x65\x52\xfe\x2e\xf2\xc1\x09\x93\x30\x20\xa4\x69\xc1\xed\x38\x70\x3b\xb1\xee\x1d\x5a\xd1\xae\x1a\xd9\x39\xa5\x3e\xc5\xeb\x1d\x5c\xb5\x60\xbc\x8b\xf6\xcf\xee\xd2\x79\x57\x6f\xb3\xbc\x37\xab\x5e\x4d\x0f\x72\x2b\x1a\xe5\x24\x70\x8b\xaa\xea\x25\x85\x5c\x68\x53\xe2\xaa\xde\x92\x02\x6f\x87\x7b\x09\x12\xaa\x13\xca\xf0\xd5\xfd\x14\xf3\x94\x0b\x33\xd0\x4f\x5c\x18\x4c\x51\x0d\xd5\x4e\x2f\xf7\x10\xf2\xc1\xdf\x71\xb1\x3b\x47\x96\x19\x3b\x5e\xa7\xd9\xe5\x08\xc9\x9a\x2a\x9a\x18\x54\xf0\x87\xaa\x1d\x17\xa9\x7d\xf7\xc9\x19\xe6\x70\xad\x0b\x54\x3d\xac\xcf\x77\x67\x58\x89\x64\x7d\x93\x3e\x7c\xec\xe7\x1c\x5e\x77\x6b\xfd\xaa\x03\xea\x90\x5a\x01\xc8\x22\x5d\x9b\x97\x1a\x6b\xb0\x5e\x60\xad\xc1\xbb\xda\x5c\xc5\x3a\x6b\xaf\x09\x2a\x0d\xfe\x87\x62\x7a\xc5\x13\x6c\x8b\x94\x1a\xe5\x55\x5d\x92\x68\xe5\xd1\x6d\xc7\xc6\xed\xa6\x6f\x60\xda\xe1\xe4\x2e\xcd\xeb\x04\xc5\xed\xde\xa6\xdb\x17\x0c\xe7\xfe\x6f\x00\x00\x00\xff\xff\x2a\xff\xe8\x4a\xff\x08\x00\x00")
 
 func migrations7_modify_trades_tableSqlBytes() ([]byte, error) {
@@ -1694,7 +1841,14 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/66_contract_asset_stats.sql":                             migrations66_contract_asset_statsSql,
 	"migrations/67_remove_unused_indexes.sql":                            migrations67_remove_unused_indexesSql,
 	"migrations/68_remove_deprecated_fields_from_exp_asset_stats.sql":    migrations68_remove_deprecated_fields_from_exp_asset_statsSql,
+	"migrations/69_contract_events.sql":                                  migrations69_contract_eventsSql,
 	"migrations/6_create_assets_table.sql":                               migrations6_create_assets_tableSql,
+	"migrations/70_contract_asset_balance_holders.sql":                   migrations70_contract_asset_balance_holdersSql,
+	"migrations/71_account_balance_history.sql":                          migrations71_account_balance_historySql,
+	"migrations/72_order_book_history.sql":                               migrations72_order_book_historySql,
+	"migrations/73_webhooks.sql":                                         migrations73_webhooksSql,
+	"migrations/74_api_keys.sql":                                         migrations74_api_keysSql,
+	"migrations/75_muxed_participants.sql":                               migrations75_muxed_participantsSql,
 	"migrations/7_modify_trades_table.sql":                               migrations7_modify_trades_tableSql,
 	"migrations/8_add_aggregators.sql":                                   migrations8_add_aggregatorsSql,
 	"migrations/8_create_asset_stats_table.sql":                          migrations8_create_asset_stats_tableSql,
@@ -1810,7 +1964,14 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"66_contract_asset_stats.sql":                             {migrations66_contract_asset_statsSql, map[string]*bintree{}},
 		"67_remove_unused_indexes.sql":                            {migrations67_remove_unused_indexesSql, map[string]*bintree{}},
 		"68_remove_deprecated_fields_from_exp_asset_stats.sql":    {migrations68_remove_deprecated_fields_from_exp_asset_statsSql, map[string]*bintree{}},
+		"69_contract_events.sql":                                  {migrations69_contract_eventsSql, map[string]*bintree{}},
 		"6_create_assets_table.sql":                               {migrations6_create_assets_tableSql, map[string]*bintree{}},
+		"70_contract_asset_balance_holders.sql":                   {migrations70_contract_asset_balance_holdersSql, map[string]*bintree{}},
+		"71_account_balance_history.sql":                          {migrations71_account_balance_historySql, map[string]*bintree{}},
+		"72_order_book_history.sql":                               {migrations72_order_book_historySql, map[string]*bintree{}},
+		"73_webhooks.sql":                                         {migrations73_webhooksSql, map[string]*bintree{}},
+		"74_api_keys.sql":                                         {migrations74_api_keysSql, map[string]*bintree{}},
+		"75_muxed_participants.sql":                               {migrations75_muxed_participantsSql, map[string]*bintree{}},
 		"7_modify_trades_table.sql":                               {migrations7_modify_trades_tableSql, map[string]*bintree{}},
 		"8_add_aggregators.sql":                                   {migrations8_add_aggregatorsSql, map[string]*bintree{}},
 		"8_create_asset_stats_table.sql":                          {migrations8_create_asset_stats_tableSql, map[string]*bintree{}},
@@ -0,0 +1,22 @@
+-- +migrate Up
+
+CREATE TABLE history_contract_events (
+    history_operation_id bigint NOT NULL,
+    "order" integer NOT NULL,
+    transaction_hash character varying(64) NOT NULL,
+    ledger_closed_at timestamp without time zone NOT NULL,
+    contract_id character varying(56),
+    type smallint NOT NULL,
+    topic1 text,
+    topic2 text,
+    topic3 text,
+    topic4 text,
+    event_xdr text NOT NULL
+);
+
+CREATE UNIQUE INDEX "index_history_contract_events_on_id" ON history_contract_events USING btree (history_operation_id, "order");
+CREATE INDEX "index_history_contract_events_on_contract_id" ON history_contract_events USING btree (contract_id, history_operation_id, "order");
+
+-- +migrate Down
+
+DROP TABLE history_contract_events cascade;
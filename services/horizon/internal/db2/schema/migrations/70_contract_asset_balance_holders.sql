@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE contract_asset_balances ADD COLUMN holder_id BYTEA;
+CREATE INDEX "contract_asset_balances_by_holder" ON contract_asset_balances USING btree (holder_id, asset_contract_id);
+
+-- +migrate Down
+
+DROP INDEX "contract_asset_balances_by_holder";
+ALTER TABLE contract_asset_balances DROP COLUMN holder_id;
@@ -0,0 +1,17 @@
+-- +migrate Up
+
+CREATE TABLE history_account_balances (
+    history_ledger_id bigint NOT NULL,
+    ledger_closed_at timestamp without time zone NOT NULL,
+    history_account_id bigint NOT NULL,
+    history_asset_id bigint NOT NULL,
+    balance bigint NOT NULL
+);
+
+CREATE UNIQUE INDEX "index_history_account_balances_on_account" ON history_account_balances USING btree (history_account_id, history_ledger_id, history_asset_id);
+CREATE INDEX "index_history_account_balances_on_asset" ON history_account_balances USING btree (history_asset_id);
+CREATE INDEX "index_history_account_balances_on_ledger" ON history_account_balances USING btree (history_ledger_id);
+
+-- +migrate Down
+
+DROP TABLE history_account_balances cascade;
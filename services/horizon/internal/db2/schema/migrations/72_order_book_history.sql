@@ -0,0 +1,35 @@
+-- +migrate Up
+
+CREATE TABLE history_offers (
+    offer_id bigint NOT NULL,
+    history_ledger_id bigint NOT NULL,
+    selling_asset text NOT NULL,
+    buying_asset text NOT NULL,
+    amount bigint NOT NULL,
+    pricen integer NOT NULL,
+    priced integer NOT NULL,
+    price double precision NOT NULL,
+    deleted boolean NOT NULL
+);
+
+CREATE INDEX "index_history_offers_on_id" ON history_offers USING btree (offer_id, history_ledger_id);
+CREATE INDEX "index_history_offers_on_assets" ON history_offers USING btree (selling_asset, buying_asset);
+
+CREATE TABLE history_liquidity_pool_reserves (
+    liquidity_pool_id text NOT NULL,
+    history_ledger_id bigint NOT NULL,
+    asset_a text NOT NULL,
+    asset_b text NOT NULL,
+    reserve_a bigint NOT NULL,
+    reserve_b bigint NOT NULL,
+    fee integer NOT NULL,
+    deleted boolean NOT NULL
+);
+
+CREATE INDEX "index_history_liquidity_pool_reserves_on_id" ON history_liquidity_pool_reserves USING btree (liquidity_pool_id, history_ledger_id);
+CREATE INDEX "index_history_liquidity_pool_reserves_on_assets" ON history_liquidity_pool_reserves USING btree (asset_a, asset_b);
+
+-- +migrate Down
+
+DROP TABLE history_offers cascade;
+DROP TABLE history_liquidity_pool_reserves cascade;
@@ -0,0 +1,33 @@
+-- +migrate Up
+
+CREATE TABLE webhook_subscriptions (
+    id bigserial PRIMARY KEY,
+    url text NOT NULL,
+    secret text NOT NULL,
+    accounts text[] NOT NULL DEFAULT '{}',
+    assets text[] NOT NULL DEFAULT '{}',
+    operation_types integer[] NOT NULL DEFAULT '{}',
+    effect_types integer[] NOT NULL DEFAULT '{}',
+    last_ledger integer NOT NULL,
+    failed_attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp without time zone,
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc')
+);
+
+CREATE TABLE webhook_dead_letters (
+    id bigserial PRIMARY KEY,
+    subscription_id bigint NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    ledger integer NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer NOT NULL,
+    last_error text NOT NULL,
+    created_at timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc')
+);
+
+CREATE INDEX "index_webhook_dead_letters_on_subscription_id" ON webhook_dead_letters USING btree (subscription_id, id);
+
+-- +migrate Down
+
+DROP TABLE webhook_dead_letters;
+DROP TABLE webhook_subscriptions;
@@ -0,0 +1,16 @@
+-- +migrate Up
+
+CREATE TABLE api_keys (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL UNIQUE,
+    key_hash text NOT NULL UNIQUE,
+    requests_per_hour integer NOT NULL DEFAULT 0,
+    max_streams integer NOT NULL DEFAULT 0,
+    path_finding_per_second integer NOT NULL DEFAULT 0,
+    created_at timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc'),
+    updated_at timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc')
+);
+
+-- +migrate Down
+
+DROP TABLE api_keys;
@@ -0,0 +1,30 @@
+-- +migrate Up
+
+-- muxed_id holds the uint64 id of the muxed account cast to a signed integer.
+CREATE TABLE history_muxed_transaction_participants (
+    history_transaction_id bigint NOT NULL,
+    history_account_id bigint NOT NULL,
+    muxed_id bigint NOT NULL
+);
+
+CREATE UNIQUE INDEX hist_muxed_tx_p_id ON history_muxed_transaction_participants USING btree (history_account_id, muxed_id, history_transaction_id);
+CREATE INDEX hmtp_by_htid ON history_muxed_transaction_participants USING btree (history_transaction_id);
+
+CREATE TABLE history_muxed_operation_participants (
+    history_operation_id bigint NOT NULL,
+    history_account_id bigint NOT NULL,
+    muxed_id bigint NOT NULL
+);
+
+CREATE UNIQUE INDEX hist_muxed_op_p_id ON history_muxed_operation_participants USING btree (history_account_id, muxed_id, history_operation_id);
+CREATE INDEX hmop_by_hoid ON history_muxed_operation_participants USING btree (history_operation_id);
+
+CREATE INDEX index_history_effects_on_address_muxed ON history_effects USING btree (address_muxed, history_operation_id, "order") WHERE address_muxed IS NOT NULL;
+
+-- +migrate Down
+
+DROP INDEX index_history_effects_on_address_muxed;
+DROP INDEX hmop_by_hoid;
+DROP INDEX hmtp_by_htid;
+DROP TABLE history_muxed_operation_participants;
+DROP TABLE history_muxed_transaction_participants;
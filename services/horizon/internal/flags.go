@@ -63,6 +63,18 @@ const (
 	DisableTxSubFlagName = "disable-tx-sub"
 	// SkipTxmeta is the command line flag for disabling persistence of tx meta in history transaction table
 	SkipTxmeta = "skip-txmeta"
+	// IngestContractEventsFlagName is the command line flag for enabling ingestion of contract events into the history contract events table
+	IngestContractEventsFlagName = "ingest-contract-events"
+	// IngestBalanceHistoryFlagName is the command line flag for enabling ingestion of account balance changes into the history account balances table
+	IngestBalanceHistoryFlagName = "ingest-balance-history"
+	// IngestOrderBookHistoryFlagName is the command line flag for enabling ingestion of offer and liquidity pool changes into the order book history tables
+	IngestOrderBookHistoryFlagName = "ingest-order-book-history"
+	// EnableWebhooksFlagName is the command line flag for enabling delivery of ingested operations and effects to webhook subscriptions
+	EnableWebhooksFlagName = "enable-webhooks"
+	// EnableGraphQLFlagName is the command line flag for enabling the GraphQL API served at /graphql
+	EnableGraphQLFlagName = "enable-graphql"
+	// EnableAPIKeysFlagName is the command line flag for enabling the authentication of requests with API keys
+	EnableAPIKeysFlagName = "enable-api-keys"
 
 	// StellarPubnet is a constant representing the Stellar public network
 	StellarPubnet = "pubnet"
@@ -823,6 +835,88 @@ func Flags() (*Config, support.ConfigOptions) {
 			Usage:          "excludes tx meta from persistence on transaction history",
 			UsedInCommands: IngestionCommands,
 		},
+		&support.ConfigOption{
+			Name:           IngestContractEventsFlagName,
+			ConfigKey:      &config.IngestContractEvents,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "persists the events emitted by Soroban transactions in the contract events history table and enables the /contract_events endpoint",
+			UsedInCommands: IngestionCommands,
+		},
+		&support.ConfigOption{
+			Name:           IngestBalanceHistoryFlagName,
+			ConfigKey:      &config.IngestBalanceHistory,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "persists the native and trust line balances of accounts at the end of every ledger in which they changed and enables the /accounts/{account_id}/balances/history endpoint",
+			UsedInCommands: IngestionCommands,
+		},
+		&support.ConfigOption{
+			Name:           IngestOrderBookHistoryFlagName,
+			ConfigKey:      &config.IngestOrderBookHistory,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "persists the offers and liquidity pool reserves at the end of every ledger in which they changed and enables the ledger parameter of the /order_book endpoint",
+			UsedInCommands: IngestionCommands,
+		},
+		&support.ConfigOption{
+			Name:           EnableWebhooksFlagName,
+			ConfigKey:      &config.EnableWebhooks,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "delivers the operations and effects of every ingested ledger to the webhook subscriptions managed on the admin port (requires --admin-port). When several instances share a database only one of them delivers payloads at a time",
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:           EnableGraphQLFlagName,
+			ConfigKey:      &config.EnableGraphQL,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "serves a GraphQL API of the history and state at /graphql. The cost of every query (1 per object and the number of requested records per list) is limited to --max-concurrent-requests",
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:           EnableAPIKeysFlagName,
+			ConfigKey:      &config.EnableAPIKeys,
+			OptType:        types.Bool,
+			FlagDefault:    false,
+			Required:       false,
+			Usage:          "authenticates the requests sending an X-Api-Key header with the API keys managed on the admin port (requires --admin-port). Every key has its own request rate, concurrent stream and path finding limits, which replace the per ip address limits for its requests",
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:           "export-max-rows",
+			ConfigKey:      &config.ExportMaxRows,
+			OptType:        types.Uint,
+			FlagDefault:    uint(100000),
+			Usage:          "max count of transactions, operations, effects and trades of an export of the history of an account (/accounts/{account_id}/export), 0 disables the endpoint",
+			UsedInCommands: ApiServerCommands,
+		},
+		&support.ConfigOption{
+			Name:        "export-per-hour-rate-limit",
+			ConfigKey:   &config.ExportRateQuota,
+			OptType:     types.Int,
+			FlagDefault: 10,
+			CustomSetValue: func(co *support.ConfigOption) error {
+				var rateLimit *throttled.RateQuota = nil
+				perHourRateLimit := viper.GetInt(co.Name)
+				if perHourRateLimit > 0 {
+					rateLimit = &throttled.RateQuota{
+						MaxRate:  throttled.PerHour(perHourRateLimit),
+						MaxBurst: perHourRateLimit - 1,
+					}
+					*(co.ConfigKey.(**throttled.RateQuota)) = rateLimit
+				}
+				return nil
+			},
+			Usage:          "max count of account history exports allowed in a one hour period, by remote ip address, on top of --per-hour-rate-limit. 0 disables the limit",
+			UsedInCommands: ApiServerCommands,
+		},
 	}
 
 	return config, flags
@@ -997,6 +1091,14 @@ func ApplyFlags(config *Config, flags support.ConfigOptions, options ApplyOption
 			" If Horizon is behind both, use --behind-cloudflare only")
 	}
 
+	if config.EnableWebhooks && config.AdminPort == 0 {
+		return fmt.Errorf("invalid config: --%s requires --admin-port to be set", EnableWebhooksFlagName)
+	}
+
+	if config.EnableAPIKeys && config.AdminPort == 0 {
+		return fmt.Errorf("invalid config: --%s requires --admin-port to be set", EnableAPIKeysFlagName)
+	}
+
 	if config.ClientQueryTimeout == clientQueryTimeoutNotSet {
 		// the default value for cancel-db-query-timeout is twice the connection-timeout
 		config.ClientQueryTimeout = config.ConnectionTimeout * 2
@@ -0,0 +1,173 @@
+// Package gql implements the GraphQL API of Horizon. Queries are resolved by
+// the same actions serving the REST API, so both APIs share their filters,
+// validations and cursors.
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/httpjson"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// DefaultMaxQueryCost is the cost limit of a query when the number of
+// concurrent requests is not limited.
+const DefaultMaxQueryCost = 1000
+
+const (
+	maxQueryDepth     = 10
+	maxQueryLength    = 10 * 1024
+	maxVariablesBytes = 10 * 1024
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Config holds the dependencies of the GraphQL API.
+type Config struct {
+	LedgerState     *ledger.State
+	CoreStateGetter actions.CoreStateGetter
+	SkipTxMeta      bool
+	// MaxQueryCost is the cost limit of a single query: every object looked up
+	// costs 1 and every list costs the number of records it requests.
+	MaxQueryCost int
+	// Timeout is the maximum duration of a query.
+	Timeout time.Duration
+}
+
+// Handler serves GraphQL queries sent with POST requests (with a JSON body
+// holding the query, operationName and variables) or GET requests (with the
+// same fields as query parameters).
+//
+// Queries must be served with a database session in their context. Fields
+// are resolved sequentially because the session is not safe for concurrent
+// use, which also lets the whole query read from a single repeatable read
+// transaction.
+type Handler struct {
+	schema       *graphql.Schema
+	maxQueryCost int
+	timeout      time.Duration
+}
+
+// NewHandler parses the GraphQL schema and returns a handler resolving it.
+func NewHandler(config Config) (*Handler, error) {
+	if config.MaxQueryCost <= 0 {
+		return nil, errors.New("MaxQueryCost must be positive")
+	}
+	r := &resolver{
+		ledgerState:     config.LedgerState,
+		coreStateGetter: config.CoreStateGetter,
+		skipTxMeta:      config.SkipTxMeta,
+	}
+	parsed, err := graphql.ParseSchema(
+		schema,
+		r,
+		graphql.MaxDepth(maxQueryDepth),
+		graphql.MaxParallelism(1),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse graphql schema")
+	}
+	return &Handler{
+		schema:       parsed,
+		maxQueryCost: config.MaxQueryCost,
+		timeout:      config.Timeout,
+	}, nil
+}
+
+type queryRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	request, err := parseQueryRequest(r)
+	if err != nil {
+		problem.Render(ctx, w, err)
+		return
+	}
+
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	ctx = withCostBudget(ctx, h.maxQueryCost)
+
+	response := h.schema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+	httpjson.Render(w, response, httpjson.JSON)
+}
+
+func parseQueryRequest(r *http.Request) (queryRequest, error) {
+	var request queryRequest
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if len(variables) > maxVariablesBytes {
+				return request, problem.MakeInvalidFieldProblem("variables", errors.New("variables are too long"))
+			}
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return request, problem.MakeInvalidFieldProblem("variables", errors.New("variables must be a JSON object"))
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return request, problem.BadRequest
+		}
+	default:
+		return request, problem.BadRequest
+	}
+
+	if request.Query == "" {
+		return request, problem.MakeInvalidFieldProblem("query", errors.New("query is required"))
+	}
+	if len(request.Query) > maxQueryLength {
+		return request, problem.MakeInvalidFieldProblem("query", errors.New("query is too long"))
+	}
+	return request, nil
+}
+
+type costBudgetKey struct{}
+
+// costBudget tracks the cost of the fields resolved for a query.
+type costBudget struct {
+	lock      sync.Mutex
+	limit     int
+	remaining int
+}
+
+func withCostBudget(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, costBudgetKey{}, &costBudget{limit: limit, remaining: limit})
+}
+
+// charge consumes cost from the budget of the query, it fails once the cost
+// limit of the query is exceeded.
+func charge(ctx context.Context, cost int) error {
+	budget, ok := ctx.Value(costBudgetKey{}).(*costBudget)
+	if !ok {
+		return nil
+	}
+	budget.lock.Lock()
+	defer budget.lock.Unlock()
+	if cost > budget.remaining {
+		budget.remaining = 0
+		return errors.Errorf("query exceeds the cost limit of %d", budget.limit)
+	}
+	budget.remaining -= cost
+	return nil
+}
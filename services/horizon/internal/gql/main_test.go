@@ -0,0 +1,255 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+)
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func execute(t *testing.T, handler *Handler, r *http.Request) graphQLResponse {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response graphQLResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func TestNewHandler(t *testing.T) {
+	_, err := NewHandler(Config{})
+	assert.EqualError(t, err, "MaxQueryCost must be positive")
+
+	handler, err := NewHandler(Config{MaxQueryCost: DefaultMaxQueryCost})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxQueryCost, handler.maxQueryCost)
+}
+
+func TestParseQueryRequest(t *testing.T) {
+	query := "{ transactions { edges { cursor } } }"
+	longQuery := "{" + strings.Repeat(" ", maxQueryLength) + "}"
+
+	for _, testCase := range []struct {
+		name     string
+		request  *http.Request
+		expected queryRequest
+		field    string
+	}{
+		{
+			name: "get",
+			request: httptest.NewRequest(http.MethodGet, "/graphql?"+url.Values{
+				"query":         {query},
+				"operationName": {"Txs"},
+				"variables":     {`{"first":2}`},
+			}.Encode(), nil),
+			expected: queryRequest{
+				Query:         query,
+				OperationName: "Txs",
+				Variables:     map[string]interface{}{"first": float64(2)},
+			},
+		},
+		{
+			name: "post",
+			request: httptest.NewRequest(
+				http.MethodPost,
+				"/graphql",
+				strings.NewReader(`{"query":"`+query+`","variables":{"first":2}}`),
+			),
+			expected: queryRequest{
+				Query:     query,
+				Variables: map[string]interface{}{"first": float64(2)},
+			},
+		},
+		{
+			name:    "missing query",
+			request: httptest.NewRequest(http.MethodGet, "/graphql", nil),
+			field:   "query",
+		},
+		{
+			name:    "long query",
+			request: httptest.NewRequest(http.MethodGet, "/graphql?"+url.Values{"query": {longQuery}}.Encode(), nil),
+			field:   "query",
+		},
+		{
+			name:    "invalid variables",
+			request: httptest.NewRequest(http.MethodGet, "/graphql?"+url.Values{"query": {query}, "variables": {"[1]"}}.Encode(), nil),
+			field:   "variables",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := parseQueryRequest(testCase.request)
+			if testCase.field == "" {
+				require.NoError(t, err)
+				assert.Equal(t, testCase.expected, request)
+				return
+			}
+			p, ok := err.(*problem.P)
+			require.True(t, ok, "unexpected error %v", err)
+			assert.Equal(t, testCase.field, p.Extras["invalid_field"])
+		})
+	}
+
+	_, err := parseQueryRequest(httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{")))
+	assert.Equal(t, problem.BadRequest, err)
+}
+
+func TestCharge(t *testing.T) {
+	assert.NoError(t, charge(context.Background(), 1000))
+
+	ctx := withCostBudget(context.Background(), 10)
+	assert.NoError(t, charge(ctx, 4))
+	assert.NoError(t, charge(ctx, 6))
+	assert.EqualError(t, charge(ctx, 1), "query exceeds the cost limit of 10")
+}
+
+func TestHandlerLimits(t *testing.T) {
+	handler, err := NewHandler(Config{MaxQueryCost: 15})
+	require.NoError(t, err)
+
+	response := execute(t, handler, httptest.NewRequest(
+		http.MethodPost,
+		"/graphql",
+		strings.NewReader(`{"query":"{ transactions(first: 20) { edges { cursor } } }"}`),
+	))
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "query exceeds the cost limit of 15", response.Errors[0].Message)
+
+	response = execute(t, handler, httptest.NewRequest(
+		http.MethodPost,
+		"/graphql",
+		strings.NewReader(`{"query":"{ operations(first: 201) { edges { cursor } } }"}`),
+	))
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "invalid first: first must be between 1 and 200", response.Errors[0].Message)
+	assert.Equal(t, "first", response.Errors[0].Extensions["invalid_field"])
+	assert.Equal(t, float64(http.StatusBadRequest), response.Errors[0].Extensions["status"])
+
+	response = execute(t, handler, httptest.NewRequest(
+		http.MethodPost,
+		"/graphql",
+		strings.NewReader(`{"query":"{ missing }"}`),
+	))
+	require.Len(t, response.Errors, 1)
+	assert.Contains(t, response.Errors[0].Message, `Cannot query field "missing"`)
+}
+
+func TestPageArgsParams(t *testing.T) {
+	after := "123"
+	params, err := pageArgs{First: 10, After: &after, Order: "DESC"}.params()
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"limit":  {"11"},
+		"order":  {"desc"},
+		"cursor": {"123"},
+	}, params)
+
+	params, err = pageArgs{First: 200, Order: "ASC"}.params()
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{"limit": {"200"}, "order": {"asc"}}, params)
+
+	_, err = pageArgs{First: 0, Order: "ASC"}.params()
+	assert.Error(t, err)
+}
+
+type testPageAction struct {
+	records []hal.Pageable
+	request *http.Request
+}
+
+func (a *testPageAction) GetResourcePage(w actions.HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	a.request = r
+	limit, err := strconv.Atoi(r.URL.Query().Get(actions.ParamLimit))
+	if err != nil {
+		return nil, err
+	}
+	if limit < len(a.records) {
+		return a.records[:limit], nil
+	}
+	return a.records, nil
+}
+
+type testObjectAction struct {
+	err error
+}
+
+func (a testObjectAction) GetResource(w actions.HeaderWriter, r *http.Request) (interface{}, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return protocol.Offer{ID: 1, PT: "1"}, nil
+}
+
+func TestPage(t *testing.T) {
+	r := &resolver{}
+	action := &testPageAction{records: []hal.Pageable{
+		protocol.Offer{ID: 1, PT: "1"},
+		protocol.Offer{ID: 2, PT: "2"},
+		protocol.Offer{ID: 3, PT: "3"},
+	}}
+	ctx := withCostBudget(context.Background(), 5)
+
+	result, err := page(ctx, action, map[string]string{"account_id": "GA"}, url.Values{"seller": {"GB"}}, pageArgs{First: 2, Order: "ASC"}, r.newOffer)
+	require.NoError(t, err)
+	require.Len(t, result.Edges(), 2)
+	assert.Equal(t, "1", result.Edges()[0].Cursor())
+	assert.Equal(t, "2", string(result.Edges()[1].Node().ID()))
+	assert.True(t, result.PageInfo().HasNextPage())
+	assert.Equal(t, "2", *result.PageInfo().EndCursor())
+	assert.Equal(t, "GA", chi.URLParam(action.request, "account_id"))
+	assert.Equal(t, "GB", action.request.URL.Query().Get("seller"))
+
+	result, err = page(ctx, action, nil, nil, pageArgs{First: 3, Order: "ASC"}, r.newOffer)
+	require.NoError(t, err)
+	assert.Len(t, result.Edges(), 3)
+	assert.False(t, result.PageInfo().HasNextPage())
+
+	// the budget is exhausted
+	_, err = page(ctx, action, nil, nil, pageArgs{First: 1, Order: "ASC"}, r.newOffer)
+	assert.EqualError(t, err, "query exceeds the cost limit of 5")
+}
+
+func TestObject(t *testing.T) {
+	r := &resolver{}
+	ctx := context.Background()
+
+	result, err := object(ctx, testObjectAction{}, nil, r.newOffer)
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(result.ID()))
+
+	result, err = object(ctx, testObjectAction{err: problem.NotFound}, nil, r.newOffer)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	_, err = object(ctx, testObjectAction{err: problem.MakeInvalidFieldProblem("limit", errors.New("too big"))}, nil, r.newOffer)
+	require.Error(t, err)
+	assert.Equal(t, "invalid first: too big", err.Error())
+
+	_, err = object(ctx, testObjectAction{err: errors.New("connection refused")}, nil, r.newOffer)
+	assert.Equal(t, errUnexpected, err)
+
+	_, err = object(ctx, testObjectAction{}, nil, r.newTransaction)
+	assert.Equal(t, errUnexpected, err)
+}
@@ -0,0 +1,466 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// errUnexpected obfuscates the errors which are not caused by the query to
+// avoid exposing the underlying implementation.
+var errUnexpected = errors.New("could not retrieve the requested data")
+
+// restParams maps the names of the REST parameters to the GraphQL arguments
+// in the errors returned by the actions.
+var restParams = map[string]string{
+	actions.ParamLimit:  "first",
+	actions.ParamCursor: "after",
+}
+
+type resolver struct {
+	ledgerState     *ledger.State
+	coreStateGetter actions.CoreStateGetter
+	skipTxMeta      bool
+}
+
+type pageAction interface {
+	GetResourcePage(w actions.HeaderWriter, r *http.Request) ([]hal.Pageable, error)
+}
+
+type objectAction interface {
+	GetResource(w actions.HeaderWriter, r *http.Request) (interface{}, error)
+}
+
+// pageArgs are the cursor pagination arguments of the list fields.
+type pageArgs struct {
+	First int32
+	After *string
+	Order string
+}
+
+// params returns the REST parameters requesting the page. One more record
+// than requested is fetched to find out if there is a next page.
+func (args pageArgs) params() (url.Values, error) {
+	if args.First <= 0 || args.First > db2.MaxPageSize {
+		return nil, problem.MakeInvalidFieldProblem(
+			"first",
+			errors.Errorf("first must be between 1 and %d", db2.MaxPageSize),
+		)
+	}
+	limit := args.First
+	if limit < db2.MaxPageSize {
+		limit++
+	}
+
+	params := url.Values{}
+	params.Set(actions.ParamLimit, strconv.Itoa(int(limit)))
+	params.Set(actions.ParamOrder, strings.ToLower(args.Order))
+	if args.After != nil {
+		params.Set(actions.ParamCursor, *args.After)
+	}
+	return params, nil
+}
+
+type headerWriter struct {
+	header http.Header
+}
+
+func (w headerWriter) Header() http.Header {
+	return w.header
+}
+
+// actionRequest builds the request served to an action to resolve a field,
+// urlParams are the parameters of the REST endpoint path.
+func actionRequest(ctx context.Context, urlParams map[string]string, params url.Values) (*http.Request, error) {
+	r, err := http.NewRequest(http.MethodGet, "/?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	routeContext := chi.NewRouteContext()
+	for key, value := range urlParams {
+		routeContext.URLParams.Add(key, value)
+	}
+	return r.WithContext(context.WithValue(ctx, chi.RouteCtxKey, routeContext)), nil
+}
+
+// page resolves a list field with a page action.
+func page[T any](
+	ctx context.Context,
+	action pageAction,
+	urlParams map[string]string,
+	params url.Values,
+	args pageArgs,
+	newNode func(interface{}) (T, error),
+) (*connection[T], error) {
+	pageParams, err := args.params()
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+	if err = charge(ctx, int(args.First)); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = url.Values{}
+	}
+	for key, values := range pageParams {
+		params[key] = values
+	}
+
+	r, err := actionRequest(ctx, urlParams, params)
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+	records, err := action.GetResourcePage(headerWriter{http.Header{}}, r)
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+
+	result := &connection[T]{edges: []*edge[T]{}}
+	// Full pages of the maximum size cannot be fetched with an extra record
+	// so they are assumed to have a next page.
+	if len(records) > int(args.First) || len(records) == db2.MaxPageSize {
+		result.hasNextPage = true
+		records = records[:args.First]
+	}
+	for _, record := range records {
+		node, err := newNode(record)
+		if err != nil {
+			return nil, resolverError(ctx, err)
+		}
+		result.edges = append(result.edges, &edge[T]{cursor: record.PagingToken(), node: node})
+	}
+	return result, nil
+}
+
+// object resolves a field with an object action, it returns nil if the
+// object does not exist.
+func object[T any](
+	ctx context.Context,
+	action objectAction,
+	urlParams map[string]string,
+	newObject func(interface{}) (*T, error),
+) (*T, error) {
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	r, err := actionRequest(ctx, urlParams, url.Values{})
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+	resource, err := action.GetResource(headerWriter{http.Header{}}, r)
+	if isNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+	result, err := newObject(resource)
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+	return result, nil
+}
+
+// account adapts the account action which also serves contracts.
+type accountAction struct{}
+
+func (accountAction) GetResource(w actions.HeaderWriter, r *http.Request) (interface{}, error) {
+	resource, err := actions.GetAccountByIDHandler{}.GetResource(w, r)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := resource.(actions.Account); !ok {
+		return nil, problem.NotFound
+	}
+	return resource, nil
+}
+
+func (r *resolver) account(ctx context.Context, id string) (*account, error) {
+	return object(ctx, accountAction{}, map[string]string{"account_id": id}, r.newAccount)
+}
+
+func (r *resolver) transaction(ctx context.Context, hash string) (*transaction, error) {
+	return object(
+		ctx,
+		actions.GetTransactionByHashHandler{SkipTxMeta: r.skipTxMeta},
+		map[string]string{"tx_id": hash},
+		r.newTransaction,
+	)
+}
+
+func (r *resolver) operation(ctx context.Context, id string) (*operation, error) {
+	return object(
+		ctx,
+		actions.GetOperationByIDHandler{LedgerState: r.ledgerState, SkipTxMeta: r.skipTxMeta},
+		map[string]string{"id": id},
+		r.newOperation,
+	)
+}
+
+func (r *resolver) transactions(ctx context.Context, urlParams map[string]string, includeFailed bool, args pageArgs) (*connection[*transaction], error) {
+	params := url.Values{}
+	if includeFailed {
+		params.Set("include_failed", "true")
+	}
+	return page(
+		ctx,
+		actions.GetTransactionsHandler{LedgerState: r.ledgerState, SkipTxMeta: r.skipTxMeta},
+		urlParams,
+		params,
+		args,
+		r.newTransaction,
+	)
+}
+
+func (r *resolver) operations(ctx context.Context, urlParams map[string]string, includeFailed, onlyPayments bool, args pageArgs) (*connection[*operation], error) {
+	params := url.Values{}
+	if includeFailed {
+		params.Set("include_failed", "true")
+	}
+	return page(
+		ctx,
+		actions.GetOperationsHandler{LedgerState: r.ledgerState, OnlyPayments: onlyPayments, SkipTxMeta: r.skipTxMeta},
+		urlParams,
+		params,
+		args,
+		r.newOperation,
+	)
+}
+
+func (r *resolver) effects(ctx context.Context, urlParams map[string]string, args pageArgs) (*connection[*effect], error) {
+	return page(ctx, actions.GetEffectsHandler{LedgerState: r.ledgerState}, urlParams, nil, args, r.newEffect)
+}
+
+func (r *resolver) trades(ctx context.Context, urlParams map[string]string, params url.Values, args pageArgs) (*connection[*trade], error) {
+	return page(
+		ctx,
+		actions.GetTradesHandler{LedgerState: r.ledgerState, CoreStateGetter: r.coreStateGetter},
+		urlParams,
+		params,
+		args,
+		r.newTrade,
+	)
+}
+
+func (r *resolver) offers(ctx context.Context, params url.Values, args pageArgs) (*connection[*offer], error) {
+	return page(ctx, actions.GetOffersHandler{LedgerState: r.ledgerState}, nil, params, args, r.newOffer)
+}
+
+func (r *resolver) liquidityPools(ctx context.Context, params url.Values, args pageArgs) (*connection[*liquidityPool], error) {
+	return page(ctx, actions.GetLiquidityPoolsHandler{LedgerState: r.ledgerState}, nil, params, args, r.newLiquidityPool)
+}
+
+func (r *resolver) claimableBalances(ctx context.Context, params url.Values, args pageArgs) (*connection[*claimableBalance], error) {
+	return page(ctx, actions.GetClaimableBalancesHandler{LedgerState: r.ledgerState}, nil, params, args, r.newClaimableBalance)
+}
+
+// Account resolves the account() query.
+func (r *resolver) Account(ctx context.Context, args struct{ ID graphql.ID }) (*account, error) {
+	return r.account(ctx, string(args.ID))
+}
+
+// Accounts resolves the accounts() query.
+func (r *resolver) Accounts(ctx context.Context, args struct {
+	Signer        *string
+	Asset         *string
+	Sponsor       *string
+	LiquidityPool *string
+	pageArgs
+}) (*connection[*account], error) {
+	params := url.Values{}
+	setParam(params, "signer", args.Signer)
+	setParam(params, "asset", args.Asset)
+	setParam(params, "sponsor", args.Sponsor)
+	setParam(params, "liquidity_pool", args.LiquidityPool)
+	return page(ctx, actions.GetAccountsHandler{LedgerState: r.ledgerState}, nil, params, args.pageArgs, r.newAccount)
+}
+
+// Transaction resolves the transaction() query.
+func (r *resolver) Transaction(ctx context.Context, args struct{ Hash graphql.ID }) (*transaction, error) {
+	return r.transaction(ctx, string(args.Hash))
+}
+
+// Transactions resolves the transactions() query.
+func (r *resolver) Transactions(ctx context.Context, args struct {
+	IncludeFailed bool
+	pageArgs
+}) (*connection[*transaction], error) {
+	return r.transactions(ctx, nil, args.IncludeFailed, args.pageArgs)
+}
+
+// Operation resolves the operation() query.
+func (r *resolver) Operation(ctx context.Context, args struct{ ID graphql.ID }) (*operation, error) {
+	return r.operation(ctx, string(args.ID))
+}
+
+// Operations resolves the operations() query.
+func (r *resolver) Operations(ctx context.Context, args struct {
+	IncludeFailed bool
+	OnlyPayments  bool
+	pageArgs
+}) (*connection[*operation], error) {
+	return r.operations(ctx, nil, args.IncludeFailed, args.OnlyPayments, args.pageArgs)
+}
+
+// Effects resolves the effects() query.
+func (r *resolver) Effects(ctx context.Context, args pageArgs) (*connection[*effect], error) {
+	return r.effects(ctx, nil, args)
+}
+
+// Trades resolves the trades() query.
+func (r *resolver) Trades(ctx context.Context, args struct {
+	TradeType *string
+	pageArgs
+}) (*connection[*trade], error) {
+	params := url.Values{}
+	setParam(params, "trade_type", args.TradeType)
+	return r.trades(ctx, nil, params, args.pageArgs)
+}
+
+// Offer resolves the offer() query.
+func (r *resolver) Offer(ctx context.Context, args struct{ ID graphql.ID }) (*offer, error) {
+	return object(ctx, actions.GetOfferByID{}, map[string]string{"offer_id": string(args.ID)}, r.newOffer)
+}
+
+// Offers resolves the offers() query.
+func (r *resolver) Offers(ctx context.Context, args struct {
+	Seller  *string
+	Sponsor *string
+	Selling *string
+	Buying  *string
+	pageArgs
+}) (*connection[*offer], error) {
+	params := url.Values{}
+	setParam(params, "seller", args.Seller)
+	setParam(params, "sponsor", args.Sponsor)
+	setParam(params, "selling", args.Selling)
+	setParam(params, "buying", args.Buying)
+	return r.offers(ctx, params, args.pageArgs)
+}
+
+// LiquidityPool resolves the liquidityPool() query.
+func (r *resolver) LiquidityPool(ctx context.Context, args struct{ ID graphql.ID }) (*liquidityPool, error) {
+	return object(
+		ctx,
+		actions.GetLiquidityPoolByIDHandler{},
+		map[string]string{"liquidity_pool_id": string(args.ID)},
+		r.newLiquidityPool,
+	)
+}
+
+// LiquidityPools resolves the liquidityPools() query.
+func (r *resolver) LiquidityPools(ctx context.Context, args struct {
+	Reserves *[]string
+	Account  *string
+	pageArgs
+}) (*connection[*liquidityPool], error) {
+	params := url.Values{}
+	if args.Reserves != nil {
+		params.Set("reserves", strings.Join(*args.Reserves, ","))
+	}
+	setParam(params, "account", args.Account)
+	return r.liquidityPools(ctx, params, args.pageArgs)
+}
+
+// ClaimableBalance resolves the claimableBalance() query.
+func (r *resolver) ClaimableBalance(ctx context.Context, args struct{ ID graphql.ID }) (*claimableBalance, error) {
+	return object(
+		ctx,
+		actions.GetClaimableBalanceByIDHandler{},
+		map[string]string{"id": string(args.ID)},
+		r.newClaimableBalance,
+	)
+}
+
+// ClaimableBalances resolves the claimableBalances() query.
+func (r *resolver) ClaimableBalances(ctx context.Context, args struct {
+	Claimant *string
+	Sponsor  *string
+	Asset    *string
+	pageArgs
+}) (*connection[*claimableBalance], error) {
+	params := url.Values{}
+	setParam(params, "claimant", args.Claimant)
+	setParam(params, "sponsor", args.Sponsor)
+	setParam(params, "asset", args.Asset)
+	return r.claimableBalances(ctx, params, args.pageArgs)
+}
+
+func setParam(params url.Values, name string, value *string) {
+	if value != nil {
+		params.Set(name, *value)
+	}
+}
+
+// queryError is a problem returned by an action, its extensions hold the
+// fields of the problem.
+type queryError struct {
+	p problem.P
+}
+
+func (e queryError) Error() string {
+	if field, ok := e.p.Extras["invalid_field"].(string); ok {
+		if arg, ok := restParams[field]; ok {
+			field = arg
+		}
+		return fmt.Sprintf("invalid %s: %v", field, e.p.Extras["reason"])
+	}
+	if e.p.Detail != "" {
+		return e.p.Detail
+	}
+	return e.p.Title
+}
+
+// Extensions implements the ResolverError interface of graphql-go
+func (e queryError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"type":   e.p.Type,
+		"status": e.p.Status,
+	}
+	for key, value := range e.p.Extras {
+		extensions[key] = value
+	}
+	return extensions
+}
+
+func toProblem(err error) (problem.P, bool) {
+	switch p := errors.Cause(err).(type) {
+	case problem.P:
+		return p, true
+	case *problem.P:
+		return *p, true
+	}
+	if known, ok := problem.IsKnownError(err).(problem.P); ok {
+		return known, true
+	}
+	return problem.P{}, false
+}
+
+func resolverError(ctx context.Context, err error) error {
+	if p, ok := toProblem(err); ok {
+		return queryError{p}
+	}
+	log.Ctx(ctx).WithStack(err).Error(err)
+	return errUnexpected
+}
+
+func isNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	p, ok := toProblem(err)
+	return ok && p.Status == http.StatusNotFound
+}
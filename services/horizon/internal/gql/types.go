@@ -0,0 +1,911 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/support/errors"
+)
+
+// jsonValue implements the JSON scalar.
+type jsonValue json.RawMessage
+
+func newJSONValue(value interface{}) (jsonValue, error) {
+	raw, err := json.Marshal(value)
+	return jsonValue(raw), err
+}
+
+func (jsonValue) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (v *jsonValue) UnmarshalGraphQL(input interface{}) error {
+	raw, err := json.Marshal(input)
+	*v = raw
+	return err
+}
+
+func (v jsonValue) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+type connection[T any] struct {
+	edges       []*edge[T]
+	hasNextPage bool
+}
+
+func (c *connection[T]) Edges() []*edge[T] {
+	return c.edges
+}
+
+func (c *connection[T]) PageInfo() pageInfo {
+	info := pageInfo{hasNextPage: c.hasNextPage}
+	if len(c.edges) > 0 {
+		info.endCursor = &c.edges[len(c.edges)-1].cursor
+	}
+	return info
+}
+
+type edge[T any] struct {
+	cursor string
+	node   T
+}
+
+func (e *edge[T]) Cursor() string {
+	return e.cursor
+}
+
+func (e *edge[T]) Node() T {
+	return e.node
+}
+
+type pageInfo struct {
+	endCursor   *string
+	hasNextPage bool
+}
+
+func (p pageInfo) EndCursor() *string {
+	return p.endCursor
+}
+
+func (p pageInfo) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+// optional returns nil for the empty values omitted by the REST API.
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func optionalTime(value *time.Time) *graphql.Time {
+	if value == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *value}
+}
+
+func canonicalAsset(assetType, code, issuer string) string {
+	if assetType == "native" {
+		return "native"
+	}
+	return code + ":" + issuer
+}
+
+func unexpectedType(resource interface{}) error {
+	return errors.Errorf("unexpected resource type %T", resource)
+}
+
+type price struct {
+	n, d int64
+}
+
+func (p price) N() string {
+	return strconv.FormatInt(p.n, 10)
+}
+
+func (p price) D() string {
+	return strconv.FormatInt(p.d, 10)
+}
+
+type account struct {
+	r *resolver
+	protocol.Account
+}
+
+func (r *resolver) newAccount(resource interface{}) (*account, error) {
+	switch resource := resource.(type) {
+	case protocol.Account:
+		return &account{r, resource}, nil
+	case actions.Account:
+		return &account{r, protocol.Account(resource)}, nil
+	default:
+		return nil, unexpectedType(resource)
+	}
+}
+
+func (a *account) ID() graphql.ID {
+	return graphql.ID(a.Account.ID)
+}
+
+func (a *account) PagingToken() string {
+	return a.Account.PagingToken()
+}
+
+func (a *account) Sequence() string {
+	return strconv.FormatInt(a.Account.Sequence, 10)
+}
+
+func (a *account) SequenceLedger() *int32 {
+	if a.Account.SequenceLedger == 0 {
+		return nil
+	}
+	sequenceLedger := int32(a.Account.SequenceLedger)
+	return &sequenceLedger
+}
+
+func (a *account) SubentryCount() int32 {
+	return a.Account.SubentryCount
+}
+
+func (a *account) InflationDestination() *string {
+	return optional(a.Account.InflationDestination)
+}
+
+func (a *account) HomeDomain() *string {
+	return optional(a.Account.HomeDomain)
+}
+
+func (a *account) LastModifiedLedger() int32 {
+	return int32(a.Account.LastModifiedLedger)
+}
+
+func (a *account) LastModifiedTime() *graphql.Time {
+	return optionalTime(a.Account.LastModifiedTime)
+}
+
+func (a *account) Thresholds() thresholds {
+	return thresholds(a.Account.Thresholds)
+}
+
+func (a *account) Flags() accountFlags {
+	return accountFlags{a.Account.Flags}
+}
+
+func (a *account) Balances() []balance {
+	balances := make([]balance, len(a.Account.Balances))
+	for i, b := range a.Account.Balances {
+		balances[i] = balance{b}
+	}
+	return balances
+}
+
+func (a *account) Signers() []signer {
+	signers := make([]signer, len(a.Account.Signers))
+	for i, s := range a.Account.Signers {
+		signers[i] = signer{s}
+	}
+	return signers
+}
+
+func (a *account) Data() []dataEntry {
+	entries := make([]dataEntry, 0, len(a.Account.Data))
+	for name, value := range a.Account.Data {
+		entries = append(entries, dataEntry{name: name, value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	return entries
+}
+
+func (a *account) NumSponsoring() int32 {
+	return int32(a.Account.NumSponsoring)
+}
+
+func (a *account) NumSponsored() int32 {
+	return int32(a.Account.NumSponsored)
+}
+
+func (a *account) Sponsor() *string {
+	return optional(a.Account.Sponsor)
+}
+
+func (a *account) urlParams() map[string]string {
+	return map[string]string{"account_id": a.Account.ID}
+}
+
+func (a *account) Transactions(ctx context.Context, args struct {
+	IncludeFailed bool
+	pageArgs
+}) (*connection[*transaction], error) {
+	return a.r.transactions(ctx, a.urlParams(), args.IncludeFailed, args.pageArgs)
+}
+
+func (a *account) Operations(ctx context.Context, args struct {
+	IncludeFailed bool
+	OnlyPayments  bool
+	pageArgs
+}) (*connection[*operation], error) {
+	return a.r.operations(ctx, a.urlParams(), args.IncludeFailed, args.OnlyPayments, args.pageArgs)
+}
+
+func (a *account) Effects(ctx context.Context, args pageArgs) (*connection[*effect], error) {
+	return a.r.effects(ctx, a.urlParams(), args)
+}
+
+func (a *account) Trades(ctx context.Context, args pageArgs) (*connection[*trade], error) {
+	return a.r.trades(ctx, a.urlParams(), nil, args)
+}
+
+func (a *account) Offers(ctx context.Context, args pageArgs) (*connection[*offer], error) {
+	return a.r.offers(ctx, map[string][]string{"seller": {a.Account.ID}}, args)
+}
+
+func (a *account) ClaimableBalances(ctx context.Context, args pageArgs) (*connection[*claimableBalance], error) {
+	return a.r.claimableBalances(ctx, map[string][]string{"claimant": {a.Account.ID}}, args)
+}
+
+func (a *account) LiquidityPools(ctx context.Context, args pageArgs) (*connection[*liquidityPool], error) {
+	return a.r.liquidityPools(ctx, map[string][]string{"account": {a.Account.ID}}, args)
+}
+
+type thresholds protocol.AccountThresholds
+
+func (t thresholds) Low() int32 {
+	return int32(t.LowThreshold)
+}
+
+func (t thresholds) Medium() int32 {
+	return int32(t.MedThreshold)
+}
+
+func (t thresholds) High() int32 {
+	return int32(t.HighThreshold)
+}
+
+type accountFlags struct {
+	flags protocol.AccountFlags
+}
+
+func (f accountFlags) AuthRequired() bool {
+	return f.flags.AuthRequired
+}
+
+func (f accountFlags) AuthRevocable() bool {
+	return f.flags.AuthRevocable
+}
+
+func (f accountFlags) AuthImmutable() bool {
+	return f.flags.AuthImmutable
+}
+
+func (f accountFlags) AuthClawbackEnabled() bool {
+	return f.flags.AuthClawbackEnabled
+}
+
+type balance struct {
+	balance protocol.Balance
+}
+
+func (b balance) AssetType() string {
+	return b.balance.Type
+}
+
+func (b balance) AssetCode() *string {
+	return optional(b.balance.Code)
+}
+
+func (b balance) AssetIssuer() *string {
+	return optional(b.balance.Issuer)
+}
+
+func (b balance) LiquidityPoolID() *string {
+	return optional(b.balance.LiquidityPoolId)
+}
+
+func (b balance) Balance() string {
+	return b.balance.Balance
+}
+
+func (b balance) Limit() *string {
+	return optional(b.balance.Limit)
+}
+
+func (b balance) BuyingLiabilities() *string {
+	return optional(b.balance.BuyingLiabilities)
+}
+
+func (b balance) SellingLiabilities() *string {
+	return optional(b.balance.SellingLiabilities)
+}
+
+func (b balance) Sponsor() *string {
+	return optional(b.balance.Sponsor)
+}
+
+func (b balance) LastModifiedLedger() *int32 {
+	if b.balance.LastModifiedLedger == 0 {
+		return nil
+	}
+	lastModifiedLedger := int32(b.balance.LastModifiedLedger)
+	return &lastModifiedLedger
+}
+
+func (b balance) IsAuthorized() *bool {
+	return b.balance.IsAuthorized
+}
+
+func (b balance) IsAuthorizedToMaintainLiabilities() *bool {
+	return b.balance.IsAuthorizedToMaintainLiabilities
+}
+
+func (b balance) IsClawbackEnabled() *bool {
+	return b.balance.IsClawbackEnabled
+}
+
+type signer struct {
+	signer protocol.Signer
+}
+
+func (s signer) Key() string {
+	return s.signer.Key
+}
+
+func (s signer) Type() string {
+	return s.signer.Type
+}
+
+func (s signer) Weight() int32 {
+	return s.signer.Weight
+}
+
+func (s signer) Sponsor() *string {
+	return optional(s.signer.Sponsor)
+}
+
+type dataEntry struct {
+	name, value string
+}
+
+func (d dataEntry) Name() string {
+	return d.name
+}
+
+func (d dataEntry) Value() string {
+	return d.value
+}
+
+type transaction struct {
+	r *resolver
+	protocol.Transaction
+}
+
+func (r *resolver) newTransaction(resource interface{}) (*transaction, error) {
+	tx, ok := resource.(protocol.Transaction)
+	if !ok {
+		return nil, unexpectedType(resource)
+	}
+	return &transaction{r, tx}, nil
+}
+
+func (t *transaction) ID() graphql.ID {
+	return graphql.ID(t.Transaction.ID)
+}
+
+func (t *transaction) PagingToken() string {
+	return t.Transaction.PagingToken()
+}
+
+func (t *transaction) Hash() string {
+	return t.Transaction.Hash
+}
+
+func (t *transaction) Ledger() int32 {
+	return t.Transaction.Ledger
+}
+
+func (t *transaction) CreatedAt() graphql.Time {
+	return graphql.Time{Time: t.LedgerCloseTime}
+}
+
+func (t *transaction) Successful() bool {
+	return t.Transaction.Successful
+}
+
+func (t *transaction) SourceAccount() string {
+	return t.Transaction.Account
+}
+
+func (t *transaction) SourceAccountSequence() string {
+	return strconv.FormatInt(t.AccountSequence, 10)
+}
+
+func (t *transaction) FeeAccount() string {
+	return t.Transaction.FeeAccount
+}
+
+func (t *transaction) FeeCharged() string {
+	return strconv.FormatInt(t.Transaction.FeeCharged, 10)
+}
+
+func (t *transaction) MaxFee() string {
+	return strconv.FormatInt(t.Transaction.MaxFee, 10)
+}
+
+func (t *transaction) OperationCount() int32 {
+	return t.Transaction.OperationCount
+}
+
+func (t *transaction) MemoType() string {
+	return t.Transaction.MemoType
+}
+
+func (t *transaction) Memo() *string {
+	return optional(t.Transaction.Memo)
+}
+
+func (t *transaction) Signatures() []string {
+	return t.Transaction.Signatures
+}
+
+func (t *transaction) EnvelopeXdr() string {
+	return t.Transaction.EnvelopeXdr
+}
+
+func (t *transaction) ResultXdr() string {
+	return t.Transaction.ResultXdr
+}
+
+func (t *transaction) Account(ctx context.Context) (*account, error) {
+	return t.r.account(ctx, t.Transaction.Account)
+}
+
+func (t *transaction) urlParams() map[string]string {
+	return map[string]string{"tx_id": t.Transaction.Hash}
+}
+
+func (t *transaction) Operations(ctx context.Context, args pageArgs) (*connection[*operation], error) {
+	return t.r.operations(ctx, t.urlParams(), false, false, args)
+}
+
+func (t *transaction) Effects(ctx context.Context, args pageArgs) (*connection[*effect], error) {
+	return t.r.effects(ctx, t.urlParams(), args)
+}
+
+type operation struct {
+	r    *resolver
+	op   operations.Operation
+	base operations.Base
+}
+
+func (r *resolver) newOperation(resource interface{}) (*operation, error) {
+	op, ok := resource.(operations.Operation)
+	if !ok {
+		return nil, unexpectedType(resource)
+	}
+	return &operation{r: r, op: op, base: op.GetBase()}, nil
+}
+
+func (o *operation) ID() graphql.ID {
+	return graphql.ID(o.base.ID)
+}
+
+func (o *operation) PagingToken() string {
+	return o.base.PT
+}
+
+func (o *operation) Type() string {
+	return o.base.Type
+}
+
+func (o *operation) TypeI() int32 {
+	return o.base.TypeI
+}
+
+func (o *operation) SourceAccount() string {
+	return o.base.SourceAccount
+}
+
+func (o *operation) TransactionHash() string {
+	return o.base.TransactionHash
+}
+
+func (o *operation) TransactionSuccessful() bool {
+	return o.base.TransactionSuccessful
+}
+
+func (o *operation) CreatedAt() graphql.Time {
+	return graphql.Time{Time: o.base.LedgerCloseTime}
+}
+
+func (o *operation) Details() (jsonValue, error) {
+	return newJSONValue(o.op)
+}
+
+func (o *operation) Transaction(ctx context.Context) (*transaction, error) {
+	return o.r.transaction(ctx, o.base.TransactionHash)
+}
+
+func (o *operation) Effects(ctx context.Context, args pageArgs) (*connection[*effect], error) {
+	return o.r.effects(ctx, map[string]string{"op_id": o.base.ID}, args)
+}
+
+type effect struct {
+	r       *resolver
+	details jsonValue
+	base    effects.Base
+}
+
+func (r *resolver) newEffect(resource interface{}) (*effect, error) {
+	details, err := newJSONValue(resource)
+	if err != nil {
+		return nil, err
+	}
+	e := &effect{r: r, details: details}
+	if err := json.Unmarshal(details, &e.base); err != nil {
+		return nil, errors.Wrap(err, "could not decode effect")
+	}
+	return e, nil
+}
+
+func (e *effect) ID() graphql.ID {
+	return graphql.ID(e.base.ID)
+}
+
+func (e *effect) PagingToken() string {
+	return e.base.PT
+}
+
+func (e *effect) Type() string {
+	return e.base.Type
+}
+
+func (e *effect) TypeI() int32 {
+	return e.base.TypeI
+}
+
+func (e *effect) Account() string {
+	return e.base.Account
+}
+
+func (e *effect) CreatedAt() graphql.Time {
+	return graphql.Time{Time: e.base.LedgerCloseTime}
+}
+
+func (e *effect) Details() jsonValue {
+	return e.details
+}
+
+// Operation returns the operation of the effect, the paging token of an
+// effect starts with the id of its operation.
+func (e *effect) Operation(ctx context.Context) (*operation, error) {
+	opID, _, _ := strings.Cut(e.base.PT, "-")
+	return e.r.operation(ctx, opID)
+}
+
+type trade struct {
+	protocol.Trade
+}
+
+func (r *resolver) newTrade(resource interface{}) (*trade, error) {
+	t, ok := resource.(protocol.Trade)
+	if !ok {
+		return nil, unexpectedType(resource)
+	}
+	return &trade{t}, nil
+}
+
+func (t *trade) ID() graphql.ID {
+	return graphql.ID(t.Trade.ID)
+}
+
+func (t *trade) PagingToken() string {
+	return t.Trade.PagingToken()
+}
+
+func (t *trade) LedgerCloseTime() graphql.Time {
+	return graphql.Time{Time: t.Trade.LedgerCloseTime}
+}
+
+func (t *trade) TradeType() string {
+	return t.Trade.TradeType
+}
+
+func (t *trade) LiquidityPoolFeeBp() *int32 {
+	if t.LiquidityPoolFeeBP == 0 {
+		return nil
+	}
+	fee := int32(t.LiquidityPoolFeeBP)
+	return &fee
+}
+
+func (t *trade) BaseOfferID() *string {
+	return optional(t.Trade.BaseOfferID)
+}
+
+func (t *trade) BaseAccount() *string {
+	return optional(t.Trade.BaseAccount)
+}
+
+func (t *trade) BaseLiquidityPoolID() *string {
+	return optional(t.Trade.BaseLiquidityPoolID)
+}
+
+func (t *trade) BaseAsset() string {
+	return canonicalAsset(t.BaseAssetType, t.BaseAssetCode, t.BaseAssetIssuer)
+}
+
+func (t *trade) BaseAmount() string {
+	return t.Trade.BaseAmount
+}
+
+func (t *trade) CounterOfferID() *string {
+	return optional(t.Trade.CounterOfferID)
+}
+
+func (t *trade) CounterAccount() *string {
+	return optional(t.Trade.CounterAccount)
+}
+
+func (t *trade) CounterLiquidityPoolID() *string {
+	return optional(t.Trade.CounterLiquidityPoolID)
+}
+
+func (t *trade) CounterAsset() string {
+	return canonicalAsset(t.CounterAssetType, t.CounterAssetCode, t.CounterAssetIssuer)
+}
+
+func (t *trade) CounterAmount() string {
+	return t.Trade.CounterAmount
+}
+
+func (t *trade) BaseIsSeller() bool {
+	return t.Trade.BaseIsSeller
+}
+
+func (t *trade) Price() price {
+	return price{n: t.Trade.Price.N, d: t.Trade.Price.D}
+}
+
+type offer struct {
+	r *resolver
+	protocol.Offer
+}
+
+func (r *resolver) newOffer(resource interface{}) (*offer, error) {
+	o, ok := resource.(protocol.Offer)
+	if !ok {
+		return nil, unexpectedType(resource)
+	}
+	return &offer{r, o}, nil
+}
+
+func (o *offer) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(o.Offer.ID, 10))
+}
+
+func (o *offer) PagingToken() string {
+	return o.Offer.PagingToken()
+}
+
+func (o *offer) Seller() string {
+	return o.Offer.Seller
+}
+
+func (o *offer) Selling() string {
+	return canonicalAsset(o.Offer.Selling.Type, o.Offer.Selling.Code, o.Offer.Selling.Issuer)
+}
+
+func (o *offer) Buying() string {
+	return canonicalAsset(o.Offer.Buying.Type, o.Offer.Buying.Code, o.Offer.Buying.Issuer)
+}
+
+func (o *offer) Amount() string {
+	return o.Offer.Amount
+}
+
+func (o *offer) Price() string {
+	return o.Offer.Price
+}
+
+func (o *offer) PriceR() price {
+	return price{n: int64(o.Offer.PriceR.N), d: int64(o.Offer.PriceR.D)}
+}
+
+func (o *offer) LastModifiedLedger() int32 {
+	return o.Offer.LastModifiedLedger
+}
+
+func (o *offer) LastModifiedTime() *graphql.Time {
+	return optionalTime(o.Offer.LastModifiedTime)
+}
+
+func (o *offer) Sponsor() *string {
+	return optional(o.Offer.Sponsor)
+}
+
+func (o *offer) SellerAccount(ctx context.Context) (*account, error) {
+	return o.r.account(ctx, o.Offer.Seller)
+}
+
+func (o *offer) Trades(ctx context.Context, args pageArgs) (*connection[*trade], error) {
+	return o.r.trades(ctx, map[string]string{"offer_id": string(o.ID())}, nil, args)
+}
+
+type liquidityPool struct {
+	r *resolver
+	protocol.LiquidityPool
+}
+
+func (r *resolver) newLiquidityPool(resource interface{}) (*liquidityPool, error) {
+	pool, ok := resource.(protocol.LiquidityPool)
+	if !ok {
+		return nil, unexpectedType(resource)
+	}
+	return &liquidityPool{r, pool}, nil
+}
+
+func (p *liquidityPool) ID() graphql.ID {
+	return graphql.ID(p.LiquidityPool.ID)
+}
+
+func (p *liquidityPool) PagingToken() string {
+	return p.LiquidityPool.PagingToken()
+}
+
+func (p *liquidityPool) FeeBp() int32 {
+	return int32(p.FeeBP)
+}
+
+func (p *liquidityPool) Type() string {
+	return p.LiquidityPool.Type
+}
+
+func (p *liquidityPool) TotalTrustlines() string {
+	return strconv.FormatUint(p.LiquidityPool.TotalTrustlines, 10)
+}
+
+func (p *liquidityPool) TotalShares() string {
+	return p.LiquidityPool.TotalShares
+}
+
+func (p *liquidityPool) Reserves() []liquidityPoolReserve {
+	reserves := make([]liquidityPoolReserve, len(p.LiquidityPool.Reserves))
+	for i, reserve := range p.LiquidityPool.Reserves {
+		reserves[i] = liquidityPoolReserve{reserve}
+	}
+	return reserves
+}
+
+func (p *liquidityPool) LastModifiedLedger() int32 {
+	return int32(p.LiquidityPool.LastModifiedLedger)
+}
+
+func (p *liquidityPool) LastModifiedTime() *graphql.Time {
+	return optionalTime(p.LiquidityPool.LastModifiedTime)
+}
+
+func (p *liquidityPool) urlParams() map[string]string {
+	return map[string]string{"liquidity_pool_id": p.LiquidityPool.ID}
+}
+
+func (p *liquidityPool) Transactions(ctx context.Context, args pageArgs) (*connection[*transaction], error) {
+	return p.r.transactions(ctx, p.urlParams(), false, args)
+}
+
+func (p *liquidityPool) Operations(ctx context.Context, args pageArgs) (*connection[*operation], error) {
+	return p.r.operations(ctx, p.urlParams(), false, false, args)
+}
+
+func (p *liquidityPool) Effects(ctx context.Context, args pageArgs) (*connection[*effect], error) {
+	return p.r.effects(ctx, p.urlParams(), args)
+}
+
+func (p *liquidityPool) Trades(ctx context.Context, args pageArgs) (*connection[*trade], error) {
+	return p.r.trades(ctx, p.urlParams(), nil, args)
+}
+
+type liquidityPoolReserve struct {
+	reserve protocol.LiquidityPoolReserve
+}
+
+func (r liquidityPoolReserve) Asset() string {
+	return r.reserve.Asset
+}
+
+func (r liquidityPoolReserve) Amount() string {
+	return r.reserve.Amount
+}
+
+type claimableBalance struct {
+	r *resolver
+	protocol.ClaimableBalance
+}
+
+func (r *resolver) newClaimableBalance(resource interface{}) (*claimableBalance, error) {
+	cb, ok := resource.(protocol.ClaimableBalance)
+	if !ok {
+		return nil, unexpectedType(resource)
+	}
+	return &claimableBalance{r, cb}, nil
+}
+
+func (b *claimableBalance) ID() graphql.ID {
+	return graphql.ID(b.BalanceID)
+}
+
+func (b *claimableBalance) PagingToken() string {
+	return b.ClaimableBalance.PagingToken()
+}
+
+func (b *claimableBalance) Asset() string {
+	return b.ClaimableBalance.Asset
+}
+
+func (b *claimableBalance) Amount() string {
+	return b.ClaimableBalance.Amount
+}
+
+func (b *claimableBalance) Sponsor() *string {
+	return optional(b.ClaimableBalance.Sponsor)
+}
+
+func (b *claimableBalance) LastModifiedLedger() int32 {
+	return int32(b.ClaimableBalance.LastModifiedLedger)
+}
+
+func (b *claimableBalance) LastModifiedTime() *graphql.Time {
+	return optionalTime(b.ClaimableBalance.LastModifiedTime)
+}
+
+func (b *claimableBalance) Claimants() []claimant {
+	claimants := make([]claimant, len(b.ClaimableBalance.Claimants))
+	for i, c := range b.ClaimableBalance.Claimants {
+		claimants[i] = claimant{c}
+	}
+	return claimants
+}
+
+func (b *claimableBalance) ClawbackEnabled() bool {
+	return b.Flags.ClawbackEnabled
+}
+
+func (b *claimableBalance) urlParams() map[string]string {
+	return map[string]string{"claimable_balance_id": b.BalanceID}
+}
+
+func (b *claimableBalance) Transactions(ctx context.Context, args pageArgs) (*connection[*transaction], error) {
+	return b.r.transactions(ctx, b.urlParams(), false, args)
+}
+
+func (b *claimableBalance) Operations(ctx context.Context, args pageArgs) (*connection[*operation], error) {
+	return b.r.operations(ctx, b.urlParams(), false, false, args)
+}
+
+type claimant struct {
+	claimant protocol.Claimant
+}
+
+func (c claimant) Destination() string {
+	return c.claimant.Destination
+}
+
+func (c claimant) Predicate() (jsonValue, error) {
+	return newJSONValue(c.claimant.Predicate)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/services/horizon/internal/apikeys"
 	horizonContext "github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/errors"
@@ -101,6 +102,14 @@ func loggerMiddleware(serverMetrics *ServerMetrics) func(next http.Handler) http
 func timeoutMiddleware(timeout time.Duration) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
+			// websocket connections are long lived, the streams served over
+			// them are subject to the timeout instead. Account exports stream
+			// their records for as long as the client reads them.
+			if isWebSocketUpgrade(r) || isAccountExport(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			mw := newWrapResponseWriter(w, r)
 			ctx, cancel := context.WithTimeout(r.Context(), timeout)
 			defer func() {
@@ -194,9 +203,46 @@ func recoverMiddleware(h http.Handler) http.Handler {
 	})
 }
 
+// apiKeyMiddleware authenticates the requests sending an API key and enforces
+// the stream limit of the key. Requests without API key are anonymous, the
+// request rate of both is enforced by the rate limiter.
+func apiKeyMiddleware(registry *apikeys.Registry) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(apikeys.Header)
+			if value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := registry.Authenticate(value)
+			if key == nil {
+				problem.Render(r.Context(), w, hProblem.InvalidAPIKey)
+				return
+			}
+			key.CountRequest()
+
+			if render.Negotiate(r) == render.MimeEventStream {
+				if !key.AcquireStream() {
+					problem.Render(r.Context(), w, hProblem.StreamLimitExceeded)
+					return
+				}
+				defer key.ReleaseStream()
+			}
+			next.ServeHTTP(w, r.WithContext(apikeys.WithKey(r.Context(), key)))
+		})
+	}
+}
+
 // NewHistoryMiddleware adds session to the request context and ensures Horizon
 // is not in a stale state, which is when the difference between latest core
 // ledger and latest history ledger is higher than the given threshold
+// isAccountExport returns true if r requests an export of the history of an
+// account (/accounts/{account_id}/export).
+func isAccountExport(r *http.Request) bool {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	return len(parts) == 3 && parts[0] == "accounts" && parts[2] == "export"
+}
+
 func NewHistoryMiddleware(ledgerState *ledger.State, staleThreshold int32, session db.SessionInterface, contextDBTimeout time.Duration) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 
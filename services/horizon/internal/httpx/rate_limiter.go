@@ -7,6 +7,7 @@ import (
 
 	"github.com/stellar/throttled"
 
+	"github.com/stellar/go/services/horizon/internal/apikeys"
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
 	"github.com/stellar/go/support/render/problem"
@@ -39,10 +40,18 @@ func (v VaryByRemoteIP) Key(r *http.Request) string {
 	return remoteAddrIP(r)
 }
 
-func newRateLimiter(rateQuota *throttled.RateQuota) (*throttled.HTTPRateLimiter, error) {
-	rateLimiter, err := throttled.NewGCRARateLimiter(lruCacheSize, *rateQuota)
-	if err != nil {
-		return nil, err
+// newRateLimiter returns a rate limiter limiting requests by remote ip address
+// with rateQuota. When apiKeys is not nil, the requests authenticated with an
+// API key are limited by the request rate of the key instead, and anonymous
+// requests are not limited if rateQuota is nil.
+func newRateLimiter(rateQuota *throttled.RateQuota, apiKeys *apikeys.Registry) (*throttled.HTTPRateLimiter, error) {
+	var rateLimiter throttled.RateLimiter
+	if rateQuota != nil {
+		var err error
+		rateLimiter, err = throttled.NewGCRARateLimiter(lruCacheSize, *rateQuota)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	result := &throttled.HTTPRateLimiter{
@@ -52,5 +61,16 @@ func newRateLimiter(rateQuota *throttled.RateQuota) (*throttled.HTTPRateLimiter,
 		}),
 		VaryBy: VaryByRemoteIP{},
 	}
+	if apiKeys != nil {
+		result.RateLimiter = apiKeys.RateLimiter(rateLimiter)
+		result.VaryBy = apikeys.VaryBy(VaryByRemoteIP{})
+		result.DeniedHandler = http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+			if apikeys.FromContext(request.Context()) != nil {
+				problem.Render(request.Context(), w, hProblem.APIKeyRateLimitExceeded)
+				return
+			}
+			problem.Render(request.Context(), w, hProblem.RateLimitExceeded)
+		})
+	}
 	return result, nil
 }
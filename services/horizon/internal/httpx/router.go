@@ -17,8 +17,11 @@ import (
 	"github.com/rs/cors"
 	"github.com/stellar/throttled"
 
+	"github.com/stellar/go/exp/orderbook"
 	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/services/horizon/internal/apikeys"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/gql"
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/services/horizon/internal/paths"
 	"github.com/stellar/go/services/horizon/internal/render"
@@ -47,6 +50,8 @@ type RouterConfig struct {
 	MaxPathLength           uint
 	MaxAssetsPerPathRequest int
 	PathFinder              paths.Finder
+	OrderBookGraph          *orderbook.OrderBookGraph
+	DisablePoolPathFinding  bool
 	PrometheusRegistry      *prometheus.Registry
 	CoreGetter              actions.CoreStateGetter
 	HorizonVersion          string
@@ -54,6 +59,14 @@ type RouterConfig struct {
 	HealthCheck             http.Handler
 	DisableTxSub            bool
 	SkipTxMeta              bool
+	IngestContractEvents    bool
+	IngestBalanceHistory    bool
+	IngestOrderBookHistory  bool
+	EnableWebhooks          bool
+	EnableGraphQL           bool
+	ExportMaxRows           uint
+	ExportRateQuota         *throttled.RateQuota
+	APIKeys                 *apikeys.Registry
 	StellarCoreURL          string
 }
 
@@ -68,15 +81,41 @@ func NewRouter(config *RouterConfig, serverMetrics *ServerMetrics, ledgerState *
 		Internal: chi.NewMux(),
 	}
 	var rateLimiter *throttled.HTTPRateLimiter
-	if config.RateQuota != nil {
+	if config.RateQuota != nil || config.APIKeys != nil {
 		var err error
-		rateLimiter, err = newRateLimiter(config.RateQuota)
+		rateLimiter, err = newRateLimiter(config.RateQuota, config.APIKeys)
 		if err != nil {
 			return nil, fmt.Errorf("unable to create RateLimiter: %v", err)
 		}
 	}
+	var graphQLHandler http.Handler
+	if config.EnableGraphQL {
+		maxQueryCost := int(config.MaxConcurrentRequests)
+		if maxQueryCost == 0 {
+			maxQueryCost = gql.DefaultMaxQueryCost
+		}
+		handler, err := gql.NewHandler(gql.Config{
+			LedgerState:     ledgerState,
+			CoreStateGetter: config.CoreGetter,
+			SkipTxMeta:      config.SkipTxMeta,
+			MaxQueryCost:    maxQueryCost,
+			Timeout:         config.ConnectionTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to create GraphQL handler: %v", err)
+		}
+		graphQLHandler = handler
+	}
+	var exportRateLimiter *throttled.HTTPRateLimiter
+	if config.ExportRateQuota != nil {
+		var err error
+		exportRateLimiter, err = newRateLimiter(config.ExportRateQuota, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create export RateLimiter: %v", err)
+		}
+	}
 	result.addMiddleware(config, rateLimiter, serverMetrics)
-	result.addRoutes(config, rateLimiter, ledgerState)
+	result.addRoutes(config, rateLimiter, exportRateLimiter, ledgerState, graphQLHandler)
 	return &result, nil
 }
 
@@ -114,6 +153,10 @@ func (r *Router) addMiddleware(config *RouterConfig,
 	})
 	r.Use(c.Handler)
 
+	if config.APIKeys != nil {
+		r.Use(apiKeyMiddleware(config.APIKeys))
+	}
+
 	if rateLimitter != nil {
 		r.Use(func(handler http.Handler) http.Handler {
 			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -144,7 +187,7 @@ func (r *Router) addMiddleware(config *RouterConfig,
 	r.Internal.Use(loggerMiddleware(serverMetrics))
 }
 
-func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRateLimiter, ledgerState *ledger.State) {
+func (r *Router) addRoutes(config *RouterConfig, rateLimiter, exportRateLimiter *throttled.HTTPRateLimiter, ledgerState *ledger.State, graphQLHandler http.Handler) {
 	stateMiddleware := StateMiddleware{
 		HorizonSession:     config.DBSession,
 		ClientQueryTimeout: config.ClientQueryTimeout,
@@ -165,6 +208,16 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		LedgerSourceFactory: historyLedgerSourceFactory{ledgerState: ledgerState, updateFrequency: config.SSEUpdateFrequency},
 	}
 
+	// WebSocket transport multiplexing the streaming endpoints below
+	r.Method(http.MethodGet, webSocketPath, websocketHandler{router: r})
+
+	// GraphQL API resolved by the actions of the endpoints below within a
+	// single repeatable read transaction
+	if graphQLHandler != nil {
+		r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/graphql", graphQLHandler)
+		r.With(stateMiddleware.Wrap).Method(http.MethodPost, "/graphql", graphQLHandler)
+	}
+
 	historyMiddleware := NewHistoryMiddleware(ledgerState, int32(config.StaleThreshold), config.DBSession, config.ClientQueryTimeout)
 	// State endpoints behind stateMiddleware
 	r.Group(func(r chi.Router) {
@@ -188,6 +241,18 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 			})
 		})
 
+		r.Route("/contracts/{contract_id}", func(r chi.Router) {
+			r.With(stateMiddleware.Wrap).Method(
+				http.MethodGet,
+				"/",
+				streamableObjectActionHandler{
+					streamHandler: streamHandler,
+					action:        actions.GetContractByIDHandler{},
+				},
+			)
+			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/balances", restPageHandler(ledgerState, actions.GetContractBalancesHandler{LedgerState: ledgerState}))
+		})
+
 		r.Route("/claimable_balances", func(r chi.Router) {
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/", restPageHandler(ledgerState, actions.GetClaimableBalancesHandler{LedgerState: ledgerState}))
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/{id}", ObjectActionHandler{actions.GetClaimableBalanceByIDHandler{}})
@@ -238,7 +303,10 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 			"/order_book",
 			streamableObjectActionHandler{
 				streamHandler: streamHandler,
-				action:        actions.GetOrderbookHandler{},
+				action: actions.GetOrderbookHandler{
+					LedgerState:      ledgerState,
+					OrderBookHistory: config.IngestOrderBookHistory,
+				},
 			},
 		)
 	})
@@ -259,6 +327,24 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		}, streamHandler))
 		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
 		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState, SkipTxMeta: config.SkipTxMeta}, streamHandler))
+
+		// balance history is only available when balance changes are ingested
+		if config.IngestBalanceHistory {
+			r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/balances/history", streamableHistoryPageHandler(ledgerState, actions.GetAccountBalanceHistoryHandler{LedgerState: ledgerState}, streamHandler))
+		}
+
+		// exports stream the history of an account in a period as a file
+		if config.ExportMaxRows > 0 {
+			var exportMiddlewares chi.Middlewares
+			if exportRateLimiter != nil {
+				exportMiddlewares = append(exportMiddlewares, exportRateLimiter.RateLimit)
+			}
+			exportMiddlewares = append(exportMiddlewares, historyMiddleware)
+			r.With(exportMiddlewares...).Method(http.MethodGet, "/accounts/{account_id:\\w+}/export", actions.GetAccountExportHandler{
+				MaxRows:    config.ExportMaxRows,
+				SkipTxMeta: config.SkipTxMeta,
+			})
+		}
 	})
 	// ledger actions
 	r.Route("/ledgers", func(r chi.Router) {
@@ -330,6 +416,11 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		// effect actions
 		r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
 
+		// contract event actions, only available when contract events are ingested
+		if config.IngestContractEvents {
+			r.With(historyMiddleware).Method(http.MethodGet, "/contract_events", streamableHistoryPageHandler(ledgerState, actions.GetContractEventsHandler{LedgerState: ledgerState}, streamHandler))
+		}
+
 		// trading related endpoints
 		r.With(historyMiddleware).Method(http.MethodGet, "/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
 		r.With(historyMiddleware).Method(http.MethodGet, "/trade_aggregations", ObjectActionHandler{actions.GetTradeAggregationsHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}})
@@ -358,6 +449,13 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		}, config.PrometheusRegistry, "async_txsub"),
 	}})
 
+	// Transaction simulation API
+	r.With(stateMiddleware.Wrap).Method(http.MethodPost, "/transactions/simulate", ObjectActionHandler{actions.SimulateTransactionHandler{
+		NetworkPassphrase: config.NetworkPassphrase,
+		OrderBookGraph:    config.OrderBookGraph,
+		IncludePools:      !config.DisablePoolPathFinding,
+	}})
+
 	// Network state related endpoints
 	r.Method(http.MethodGet, "/fee_stats", ObjectActionHandler{actions.FeeStatsHandler{}})
 
@@ -404,4 +502,34 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		r.With(historyMiddleware).Get("/asset", handler.GetAssetConfig)
 		r.With(historyMiddleware).Get("/account", handler.GetAccountConfig)
 	})
+	if config.EnableWebhooks {
+		// subscriptions are written to, so they must not be served from a read replica
+		webhooksMiddleware := historyMiddleware
+		if config.PrimaryDBSession != nil {
+			webhooksMiddleware = NewHistoryMiddleware(ledgerState, int32(config.StaleThreshold), config.PrimaryDBSession, config.ClientQueryTimeout)
+		}
+		r.Internal.Route("/webhooks", func(r chi.Router) {
+			handler := actions.WebhookHandler{LedgerState: ledgerState}
+			r.With(webhooksMiddleware).Post("/", handler.CreateSubscription)
+			r.With(webhooksMiddleware).Get("/", handler.GetSubscriptions)
+			r.With(webhooksMiddleware).Get("/{id}", handler.GetSubscription)
+			r.With(webhooksMiddleware).Delete("/{id}", handler.DeleteSubscription)
+			r.With(webhooksMiddleware).Get("/{id}/dead_letters", handler.GetDeadLetters)
+		})
+	}
+	if config.APIKeys != nil {
+		// keys are written to, so they must not be served from a read replica
+		apiKeysMiddleware := historyMiddleware
+		if config.PrimaryDBSession != nil {
+			apiKeysMiddleware = NewHistoryMiddleware(ledgerState, int32(config.StaleThreshold), config.PrimaryDBSession, config.ClientQueryTimeout)
+		}
+		r.Internal.Route("/api_keys", func(r chi.Router) {
+			handler := actions.APIKeyHandler{Registry: config.APIKeys}
+			r.With(apiKeysMiddleware).Post("/", handler.CreateKey)
+			r.With(apiKeysMiddleware).Get("/", handler.GetKeys)
+			r.With(apiKeysMiddleware).Get("/{id}", handler.GetKey)
+			r.With(apiKeysMiddleware).Put("/{id}", handler.UpdateKey)
+			r.With(apiKeysMiddleware).Delete("/{id}", handler.DeleteKey)
+		})
+	}
 }
@@ -0,0 +1,436 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpguts"
+	"golang.org/x/net/websocket"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/render"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+)
+
+const (
+	webSocketPath = "/ws"
+
+	maxWebSocketSubscriptions = 50
+	maxWebSocketMessageSize   = 4096
+	webSocketWriteTimeout     = 10 * time.Second
+	// minStreamRestartInterval is the minimum time between two runs of a
+	// subscription's stream which did not send any event.
+	minStreamRestartInterval = time.Second
+
+	streamRequestSubscribe   = "subscribe"
+	streamRequestUnsubscribe = "unsubscribe"
+
+	streamMessageSubscribed   = "subscribed"
+	streamMessageUnsubscribed = "unsubscribed"
+	streamMessageEvent        = "event"
+	streamMessageError        = "error"
+)
+
+// websocketHandler serves the WebSocket transport of the streaming endpoints.
+// A single connection can subscribe to several streams, every subscription is
+// served by dispatching a Server Sent Events request to router whose events
+// are forwarded to the connection instead of being written to the response.
+// This way subscriptions share the ledger triggered wakeups, rate limiting,
+// concurrent request limit and cursor semantics of the SSE streams.
+type websocketHandler struct {
+	router http.Handler
+}
+
+func (handler websocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The origin is not checked, like CORS all origins are allowed.
+	server := websocket.Server{Handler: handler.serveConnection}
+	server.ServeHTTP(w, r)
+}
+
+func (handler websocketHandler) serveConnection(ws *websocket.Conn) {
+	ws.MaxPayloadBytes = maxWebSocketMessageSize
+
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &webSocketConnection{
+		ws:            ws,
+		router:        handler.router,
+		upgrade:       ws.Request(),
+		ctx:           ctx,
+		cancel:        cancel,
+		subscriptions: map[string]*webSocketSubscription{},
+		logger:        log.Ctx(ws.Request().Context()),
+	}
+	defer func() {
+		cancel()
+		conn.wg.Wait()
+		ws.Close()
+	}()
+
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		var request protocol.StreamRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			conn.sendError("", problem.BadRequest)
+			continue
+		}
+		conn.handle(request)
+	}
+}
+
+type webSocketConnection struct {
+	ws      *websocket.Conn
+	router  http.Handler
+	upgrade *http.Request
+	logger  *log.Entry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	writeLock sync.Mutex
+
+	lock          sync.Mutex
+	subscriptions map[string]*webSocketSubscription
+}
+
+func (c *webSocketConnection) handle(request protocol.StreamRequest) {
+	switch request.Type {
+	case streamRequestSubscribe:
+		c.subscribe(request)
+	case streamRequestUnsubscribe:
+		c.unsubscribe(request)
+	default:
+		c.sendError(request.ID, problem.MakeInvalidFieldProblem(
+			"type",
+			errors.New("type must be either subscribe or unsubscribe"),
+		))
+	}
+}
+
+func (c *webSocketConnection) subscribe(request protocol.StreamRequest) {
+	if request.ID == "" {
+		c.sendError("", problem.MakeInvalidFieldProblem("id", errors.New("id is required")))
+		return
+	}
+	path, err := parseStreamPath(request.Path)
+	if err != nil {
+		c.sendError(request.ID, problem.MakeInvalidFieldProblem("path", err))
+		return
+	}
+
+	c.lock.Lock()
+	if _, ok := c.subscriptions[request.ID]; ok {
+		c.lock.Unlock()
+		c.sendError(request.ID, problem.MakeInvalidFieldProblem("id", errors.New("id is already subscribed")))
+		return
+	}
+	if len(c.subscriptions) >= maxWebSocketSubscriptions {
+		c.lock.Unlock()
+		c.sendError(request.ID, problem.MakeInvalidFieldProblem(
+			"id",
+			errors.Errorf("a connection cannot have more than %d subscriptions", maxWebSocketSubscriptions),
+		))
+		return
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	subscription := &webSocketSubscription{
+		id:     request.ID,
+		conn:   c,
+		path:   path,
+		header: streamRequestHeader(c.upgrade.Header),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	c.subscriptions[request.ID] = subscription
+	c.lock.Unlock()
+
+	c.send(protocol.StreamMessage{Type: streamMessageSubscribed, ID: request.ID})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		subscription.run()
+	}()
+}
+
+func (c *webSocketConnection) unsubscribe(request protocol.StreamRequest) {
+	c.lock.Lock()
+	subscription, ok := c.subscriptions[request.ID]
+	delete(c.subscriptions, request.ID)
+	c.lock.Unlock()
+
+	if !ok {
+		c.sendError(request.ID, problem.MakeInvalidFieldProblem("id", errors.New("id is not subscribed")))
+		return
+	}
+	subscription.cancel()
+	c.send(protocol.StreamMessage{Type: streamMessageUnsubscribed, ID: request.ID})
+}
+
+// remove drops the subscription unless it was already unsubscribed. It returns
+// true if the subscription was removed.
+func (c *webSocketConnection) remove(subscription *webSocketSubscription) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.subscriptions[subscription.id] != subscription {
+		return false
+	}
+	delete(c.subscriptions, subscription.id)
+	return true
+}
+
+func (c *webSocketConnection) send(message protocol.StreamMessage) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if c.ctx.Err() != nil {
+		return
+	}
+
+	c.ws.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+	if err := websocket.JSON.Send(c.ws, message); err != nil {
+		c.logger.WithError(err).Debug("could not write to websocket, closing connection")
+		c.cancel()
+		// Unblock the read loop
+		c.ws.Close()
+	}
+}
+
+func (c *webSocketConnection) sendError(id string, err error) {
+	c.send(protocol.StreamMessage{
+		Type:  streamMessageError,
+		ID:    id,
+		Error: renderProblem(c.ctx, err),
+	})
+}
+
+// webSocketSubscription is a stream served over a websocket connection.
+type webSocketSubscription struct {
+	id     string
+	conn   *webSocketConnection
+	path   *url.URL
+	header http.Header
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	eventsSent int
+	// lastEventID is the id of the last event which was sent, the stream is
+	// restarted from it.
+	lastEventID string
+	// lastObject is the last event without id which was sent, streams of
+	// objects send their current state again every time they are restarted.
+	lastObject []byte
+	err        error
+}
+
+// run serves the stream until it fails or the subscription is cancelled.
+// Every request ends after sending its limit of events (or when it reaches the
+// connection timeout), it is then restarted from the last event like the SSE
+// clients do.
+func (s *webSocketSubscription) run() {
+	defer s.cancel()
+	for {
+		started := time.Now()
+		s.eventsSent = 0
+
+		w := &streamResponseWriter{header: http.Header{}}
+		s.conn.router.ServeHTTP(w, s.request())
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		var message json.RawMessage
+		switch {
+		case w.status >= http.StatusBadRequest:
+			message = w.problem()
+		case s.err != nil:
+			message = renderProblem(s.ctx, s.err)
+		case w.body.Len() > 0:
+			message = renderProblem(s.ctx, problem.MakeInvalidFieldProblem(
+				"path",
+				errors.New("the endpoint does not support streaming"),
+			))
+		}
+		if message != nil {
+			if s.conn.remove(s) {
+				s.conn.send(protocol.StreamMessage{Type: streamMessageError, ID: s.id, Error: message})
+			}
+			return
+		}
+
+		if s.eventsSent == 0 {
+			select {
+			case <-time.After(minStreamRestartInterval - time.Since(started)):
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// request builds the SSE request of the subscription. Like SSE clients do
+// when reconnecting, it resumes the stream from the last event which was sent.
+func (s *webSocketSubscription) request() *http.Request {
+	if s.lastEventID != "" {
+		s.header.Set("Last-Event-ID", s.lastEventID)
+	}
+	u := *s.path
+	r := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &u,
+		RequestURI: u.RequestURI(),
+		Proto:      s.conn.upgrade.Proto,
+		ProtoMajor: s.conn.upgrade.ProtoMajor,
+		ProtoMinor: s.conn.upgrade.ProtoMinor,
+		Header:     s.header,
+		Body:       http.NoBody,
+		Host:       s.conn.upgrade.Host,
+		RemoteAddr: s.conn.upgrade.RemoteAddr,
+	}
+	return r.WithContext(sse.WithEventSink(s.ctx, s))
+}
+
+// Send implements sse.EventSink
+func (s *webSocketSubscription) Send(e sse.Event) {
+	if e.Error != nil {
+		s.err = e.Error
+		return
+	}
+
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		s.err = errors.Wrap(err, "could not marshal event")
+		return
+	}
+	s.eventsSent++
+	if e.ID != "" {
+		s.lastEventID = e.ID
+	} else {
+		if bytes.Equal(data, s.lastObject) {
+			return
+		}
+		s.lastObject = data
+	}
+
+	s.conn.send(protocol.StreamMessage{
+		Type:    streamMessageEvent,
+		ID:      s.id,
+		EventID: e.ID,
+		Data:    data,
+	})
+}
+
+func parseStreamPath(path string) (*url.URL, error) {
+	if path == "" {
+		return nil, errors.New("path is required")
+	}
+	u, err := url.ParseRequestURI(path)
+	if err != nil {
+		return nil, errors.New("path must be a valid path and query")
+	}
+	if u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return nil, errors.New("path must be relative to the server root")
+	}
+	if strings.TrimSuffix(u.Path, "/") == webSocketPath {
+		return nil, errors.New("path cannot be the websocket endpoint")
+	}
+	return u, nil
+}
+
+// streamRequestHeader returns the header of the SSE requests of a subscription
+// based on the websocket upgrade request: client and proxy headers are kept so
+// that subscriptions are logged and rate limited like the client's SSE
+// requests.
+func streamRequestHeader(upgrade http.Header) http.Header {
+	header := upgrade.Clone()
+	for key := range header {
+		if strings.HasPrefix(key, "Sec-Websocket-") {
+			header.Del(key)
+		}
+	}
+	header.Del("Upgrade")
+	header.Del("Connection")
+	header.Del("Last-Event-Id")
+	header.Set("Accept", render.MimeEventStream)
+	return header
+}
+
+// isWebSocketUpgrade returns true if r is a request to upgrade a connection
+// to the websocket endpoint. The Upgrade header alone doesn't make a request
+// long lived, other endpoints ignore it.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		r.URL.Path == webSocketPath &&
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		httpguts.HeaderValuesContainsToken(r.Header["Connection"], "upgrade")
+}
+
+// streamResponseWriter records the response of the SSE requests served for a
+// subscription. Events are not written to it, only the response of requests
+// which fail before streaming.
+type streamResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *streamResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *streamResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(b)
+}
+
+func (w *streamResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Flush implements http.Flusher
+func (w *streamResponseWriter) Flush() {}
+
+// problem returns the problem document of a failed request.
+func (w *streamResponseWriter) problem() json.RawMessage {
+	body := bytes.TrimSpace(w.body.Bytes())
+	if len(body) > 0 && json.Valid(body) {
+		return body
+	}
+	return renderProblem(context.Background(), &problem.P{
+		Type:   "about:blank",
+		Title:  http.StatusText(w.status),
+		Status: w.status,
+	})
+}
+
+// renderProblem renders err as a problem document. Unknown errors have already
+// been logged by the stream, they are rendered as a server error.
+func renderProblem(ctx context.Context, err error) json.RawMessage {
+	switch err.(type) {
+	case problem.P, *problem.P:
+	default:
+		err = problem.ServerError
+	}
+
+	w := &streamResponseWriter{header: http.Header{}}
+	problem.Render(ctx, w, err)
+	return bytes.TrimSpace(w.body.Bytes())
+}
@@ -0,0 +1,349 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stellar/throttled"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+)
+
+type webSocketTest struct {
+	t            *testing.T
+	server       *httptest.Server
+	ws           *websocket.Conn
+	pageSource   *ledger.TestingSource
+	objectSource *ledger.TestingSource
+}
+
+func newWebSocketTest(t *testing.T) *webSocketTest {
+	router := chi.NewMux()
+	router.Use(timeoutMiddleware(time.Minute))
+	router.Method(http.MethodGet, webSocketPath, websocketHandler{router: router})
+	return newWebSocketTestWithRouter(t, router)
+}
+
+// newWebSocketTestWithRouter adds the test streams to router, which must
+// serve the websocket endpoint, and connects to it.
+func newWebSocketTestWithRouter(t *testing.T, router chi.Router) *webSocketTest {
+	pageSource := ledger.NewTestingSource(3)
+	pageAction := &testPageAction{
+		objects: map[uint32][]string{
+			3: {"a", "b", "c"},
+			4: {"a", "b", "c", "d", "e"},
+		},
+		ledgerSource: pageSource,
+	}
+	objectSource := ledger.NewTestingSource(3)
+	objectAction := &testObjectAction{
+		objects: map[uint32]stringObject{
+			3: "a",
+			4: "a",
+			5: "b",
+			6: "c",
+		},
+		ledgerSource: objectSource,
+	}
+
+	router.Method(http.MethodGet, "/pages", streamableStatePageHandler(
+		&ledger.State{},
+		pageAction,
+		sse.StreamHandler{LedgerSourceFactory: &testingFactory{pageSource}},
+	))
+	router.Method(http.MethodGet, "/object", streamableObjectActionHandler{
+		action:        objectAction,
+		limit:         2,
+		streamHandler: sse.StreamHandler{LedgerSourceFactory: &testingFactory{objectSource}},
+	})
+	router.Get("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":"plain"}`))
+	})
+
+	server := httptest.NewServer(router)
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+webSocketPath, "", server.URL)
+	require.NoError(t, err)
+
+	return &webSocketTest{
+		t:            t,
+		server:       server,
+		ws:           ws,
+		pageSource:   pageSource,
+		objectSource: objectSource,
+	}
+}
+
+func (wt *webSocketTest) Close() {
+	wt.ws.Close()
+	wt.server.Close()
+}
+
+func (wt *webSocketTest) send(request protocol.StreamRequest) {
+	require.NoError(wt.t, websocket.JSON.Send(wt.ws, request))
+}
+
+func (wt *webSocketTest) receive() protocol.StreamMessage {
+	var message protocol.StreamMessage
+	require.NoError(wt.t, wt.ws.SetReadDeadline(time.Now().Add(10*time.Second)))
+	require.NoError(wt.t, websocket.JSON.Receive(wt.ws, &message))
+	return message
+}
+
+// receiveEvents reads messages until count events of every subscription in
+// counts have been received and returns the data of the events by
+// subscription.
+func (wt *webSocketTest) receiveEvents(counts map[string]int) map[string][]string {
+	events := map[string][]string{}
+	remaining := 0
+	for _, count := range counts {
+		remaining += count
+	}
+	for remaining > 0 {
+		message := wt.receive()
+		require.Equal(wt.t, "event", message.Type, string(message.Error))
+		var value string
+		if message.EventID != "" {
+			var page testPage
+			require.NoError(wt.t, json.Unmarshal(message.Data, &page))
+			value = message.EventID + ":" + page.Value
+		} else {
+			require.NoError(wt.t, json.Unmarshal(message.Data, &value))
+		}
+		events[message.ID] = append(events[message.ID], value)
+		remaining--
+	}
+	return events
+}
+
+func (wt *webSocketTest) expectError(id, field string, status int) {
+	message := wt.receive()
+	assert.Equal(wt.t, "error", message.Type)
+	assert.Equal(wt.t, id, message.ID)
+
+	var p struct {
+		Status int               `json:"status"`
+		Extras map[string]string `json:"extras"`
+	}
+	require.NoError(wt.t, json.Unmarshal(message.Error, &p))
+	assert.Equal(wt.t, status, p.Status)
+	if field != "" {
+		assert.Equal(wt.t, field, p.Extras["invalid_field"])
+	}
+}
+
+func TestWebSocketMultiplexesStreams(t *testing.T) {
+	wt := newWebSocketTest(t)
+	defer wt.Close()
+
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "pages", Path: "/pages?limit=2"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "pages"}, wt.receive())
+	// the stream is restarted from the last event once its limit is reached
+	assert.Equal(t, map[string][]string{
+		"pages": {"1:a", "2:b", "3:c"},
+	}, wt.receiveEvents(map[string]int{"pages": 3}))
+
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "object", Path: "/object"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "object"}, wt.receive())
+	assert.Equal(t, map[string][]string{
+		"object": {"a"},
+	}, wt.receiveEvents(map[string]int{"object": 1}))
+
+	go func() {
+		wt.pageSource.AddLedger(4)
+		for _, sequence := range []uint32{4, 5, 6} {
+			wt.objectSource.AddLedger(sequence)
+		}
+	}()
+	// the object is not sent again when its stream is restarted
+	assert.Equal(t, map[string][]string{
+		"pages":  {"4:d", "5:e"},
+		"object": {"b", "c"},
+	}, wt.receiveEvents(map[string]int{"pages": 2, "object": 2}))
+
+	wt.send(protocol.StreamRequest{Type: "unsubscribe", ID: "object"})
+	assert.Equal(t, protocol.StreamMessage{Type: "unsubscribed", ID: "object"}, wt.receive())
+
+	// ids can be reused once unsubscribed
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "object", Path: "/object"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "object"}, wt.receive())
+	assert.Equal(t, map[string][]string{
+		"object": {"c"},
+	}, wt.receiveEvents(map[string]int{"object": 1}))
+}
+
+func TestWebSocketErrors(t *testing.T) {
+	wt := newWebSocketTest(t)
+	defer wt.Close()
+
+	_, err := wt.ws.Write([]byte("{"))
+	require.NoError(t, err)
+	wt.expectError("", "", http.StatusBadRequest)
+
+	wt.send(protocol.StreamRequest{Type: "listen", ID: "pages"})
+	wt.expectError("pages", "type", http.StatusBadRequest)
+
+	wt.send(protocol.StreamRequest{Type: "subscribe", Path: "/pages"})
+	wt.expectError("", "id", http.StatusBadRequest)
+
+	for _, path := range []string{"", "pages", "http://example.com/pages", "/ws", "/ws/"} {
+		wt.send(protocol.StreamRequest{Type: "subscribe", ID: "pages", Path: path})
+		wt.expectError("pages", "path", http.StatusBadRequest)
+	}
+
+	wt.send(protocol.StreamRequest{Type: "unsubscribe", ID: "pages"})
+	wt.expectError("pages", "id", http.StatusBadRequest)
+
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "pages", Path: "/pages"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "pages"}, wt.receive())
+	wt.receiveEvents(map[string]int{"pages": 3})
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "pages", Path: "/pages"})
+	wt.expectError("pages", "id", http.StatusBadRequest)
+
+	// requests which fail before streaming end the subscription with their
+	// problem
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "cursor", Path: "/pages?cursor=-1"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "cursor"}, wt.receive())
+	wt.expectError("cursor", "cursor", http.StatusBadRequest)
+
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "missing", Path: "/missing"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "missing"}, wt.receive())
+	wt.expectError("missing", "", http.StatusNotFound)
+
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "plain", Path: "/plain"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "plain"}, wt.receive())
+	wt.expectError("plain", "path", http.StatusBadRequest)
+
+	// failed subscriptions are removed
+	wt.send(protocol.StreamRequest{Type: "unsubscribe", ID: "plain"})
+	wt.expectError("plain", "id", http.StatusBadRequest)
+}
+
+func TestWebSocketThroughRouter(t *testing.T) {
+	metrics := &ServerMetrics{
+		RequestDurationSummary: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{Name: "requests_duration_seconds"},
+			[]string{"status", "route", "streaming", "method"},
+		),
+		RequestsInFlightGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: "requests_in_flight"},
+			[]string{"route", "streaming", "method"},
+		),
+		RequestsReceivedCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Name: "requests_received"},
+			[]string{"route", "streaming", "method"},
+		),
+	}
+	router, err := NewRouter(&RouterConfig{
+		RateQuota: &throttled.RateQuota{
+			MaxRate:  throttled.PerHour(3600),
+			MaxBurst: 100,
+		},
+		// the connection and one running subscription
+		MaxConcurrentRequests: 2,
+		ConnectionTimeout:     time.Minute,
+		HealthCheck:           http.NotFoundHandler(),
+		PrometheusRegistry:    prometheus.NewRegistry(),
+	}, metrics, &ledger.State{})
+	require.NoError(t, err)
+
+	wt := newWebSocketTestWithRouter(t, router.Mux)
+	defer wt.Close()
+
+	get := func() int {
+		response, err := http.Get(wt.server.URL + "/plain")
+		require.NoError(t, err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "object", Path: "/object"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "object"}, wt.receive())
+	assert.Equal(t, map[string][]string{
+		"object": {"a"},
+	}, wt.receiveEvents(map[string]int{"object": 1}))
+
+	// the connection and the stream of the subscription, which waits for the
+	// next ledger, are both counted as concurrent requests
+	assert.Equal(t, http.StatusServiceUnavailable, get())
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "pages", Path: "/pages"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "pages"}, wt.receive())
+	wt.expectError("pages", "", http.StatusServiceUnavailable)
+
+	wt.send(protocol.StreamRequest{Type: "unsubscribe", ID: "object"})
+	assert.Equal(t, protocol.StreamMessage{Type: "unsubscribed", ID: "object"}, wt.receive())
+	assert.Eventually(t, func() bool {
+		return get() == http.StatusOK
+	}, 10*time.Second, 10*time.Millisecond)
+
+	wt.send(protocol.StreamRequest{Type: "subscribe", ID: "pages", Path: "/pages?limit=2"})
+	assert.Equal(t, protocol.StreamMessage{Type: "subscribed", ID: "pages"}, wt.receive())
+	assert.Equal(t, map[string][]string{
+		"pages": {"1:a", "2:b", "3:c"},
+	}, wt.receiveEvents(map[string]int{"pages": 3}))
+}
+
+func TestTimeoutMiddlewareWebSocketUpgrade(t *testing.T) {
+	router := chi.NewMux()
+	router.Use(timeoutMiddleware(10 * time.Millisecond))
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusSwitchingProtocols)
+	}
+	router.Get(webSocketPath, handler)
+	router.Get("/paths", handler)
+
+	for _, testCase := range []struct {
+		path       string
+		connection string
+		status     int
+	}{
+		{path: webSocketPath, connection: "Upgrade", status: http.StatusSwitchingProtocols},
+		{path: webSocketPath, connection: "keep-alive, Upgrade", status: http.StatusSwitchingProtocols},
+		{path: webSocketPath, connection: "keep-alive", status: http.StatusGatewayTimeout},
+		// other endpoints are subject to the timeout even with the headers
+		// of a websocket upgrade
+		{path: "/paths", connection: "Upgrade", status: http.StatusGatewayTimeout},
+	} {
+		t.Run(testCase.path+" "+testCase.connection, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+			r.Header.Set("Upgrade", "websocket")
+			r.Header.Set("Connection", testCase.connection)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			assert.Equal(t, testCase.status, w.Code)
+		})
+	}
+}
+
+func TestStreamRequestHeader(t *testing.T) {
+	upgrade := http.Header{}
+	upgrade.Set("Upgrade", "websocket")
+	upgrade.Set("Connection", "Upgrade")
+	upgrade.Set("Sec-WebSocket-Key", "key")
+	upgrade.Set("Sec-WebSocket-Version", "13")
+	upgrade.Set("Last-Event-ID", "10")
+	upgrade.Set("X-Forwarded-For", "10.0.0.1")
+	upgrade.Set("X-Client-Name", "js-stellar-sdk")
+
+	header := streamRequestHeader(upgrade)
+	assert.Equal(t, http.Header{
+		"Accept":          {"text/event-stream"},
+		"X-Forwarded-For": {"10.0.0.1"},
+		"X-Client-Name":   {"js-stellar-sdk"},
+	}, header)
+	assert.Equal(t, "websocket", upgrade.Get("Upgrade"))
+}
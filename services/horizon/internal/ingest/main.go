@@ -64,7 +64,8 @@ const (
 	//       contract data ledger entries.
 	// - 18: Ingest contract asset balances so we can keep track of expired / restore asset
 	//       balances for asset stats.
-	CurrentVersion = 18
+	// - 19: Trigger state rebuild to populate the holder of contract asset balances.
+	CurrentVersion = 19
 
 	// MaxDBConnections is the size of the postgres connection pool dedicated to Horizon ingestion:
 	//  * Ledger ingestion,
@@ -144,6 +145,10 @@ type Config struct {
 	MaxLedgerPerFlush uint32
 	SkipTxmeta        bool
 
+	IngestContractEvents   bool
+	IngestBalanceHistory   bool
+	IngestOrderBookHistory bool
+
 	CoreProtocolVersionFn ledgerbackend.CoreProtocolVersionFunc
 	CoreBuildVersionFn    ledgerbackend.CoreBuildVersionFunc
 
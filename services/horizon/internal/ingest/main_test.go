@@ -406,8 +406,10 @@ type mockDBQ struct {
 	mock.Mock
 
 	history.MockQAccounts
+	history.MockQAccountBalanceHistory
 	history.MockQFilter
 	history.MockQClaimableBalances
+	history.MockQContractEvents
 	history.MockQHistoryClaimableBalances
 	history.MockQLiquidityPools
 	history.MockQHistoryLiquidityPools
@@ -416,6 +418,7 @@ type mockDBQ struct {
 	history.MockQEffects
 	history.MockQLedgers
 	history.MockQOffers
+	history.MockQOrderBookHistory
 	history.MockQOperations
 	history.MockQSigners
 	history.MockQTransactions
@@ -550,6 +553,11 @@ func (m *mockDBQ) DeleteRangeAll(ctx context.Context, start, end int64) (int64,
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *mockDBQ) DeleteOrderBookHistoryRange(ctx context.Context, start, end int64) (int64, error) {
+	args := m.Called(ctx, start, end)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // Methods from interfaces duplicating methods:
 
 func (m *mockDBQ) NewTransactionParticipantsBatchInsertBuilder() history.TransactionParticipantsBatchInsertBuilder {
@@ -562,6 +570,16 @@ func (m *mockDBQ) NewOperationParticipantBatchInsertBuilder() history.OperationP
 	return args.Get(0).(history.OperationParticipantBatchInsertBuilder)
 }
 
+func (m *mockDBQ) NewMuxedTransactionParticipantsBatchInsertBuilder() history.MuxedTransactionParticipantsBatchInsertBuilder {
+	args := m.Called()
+	return args.Get(0).(history.MuxedTransactionParticipantsBatchInsertBuilder)
+}
+
+func (m *mockDBQ) NewMuxedOperationParticipantBatchInsertBuilder() history.MuxedOperationParticipantBatchInsertBuilder {
+	args := m.Called()
+	return args.Get(0).(history.MuxedOperationParticipantBatchInsertBuilder)
+}
+
 func (m *mockDBQ) NewTradeBatchInsertBuilder() history.TradeBatchInsertBuilder {
 	args := m.Called()
 	return args.Get(0).(history.TradeBatchInsertBuilder)
@@ -114,12 +114,13 @@ func buildChangeProcessor(
 	source ingestionSource,
 	ledgerSequence uint32,
 	networkPassphrase string,
+	ingestOrderBookHistory bool,
 ) *groupChangeProcessors {
 	statsChangeProcessor := &statsChangeProcessor{
 		StatsChangeProcessor: changeStats,
 	}
 
-	return newGroupChangeProcessors([]horizonChangeProcessor{
+	changeProcessors := []horizonChangeProcessor{
 		statsChangeProcessor,
 		processors.NewAccountDataProcessor(historyQ),
 		processors.NewAccountsProcessor(historyQ),
@@ -129,7 +130,15 @@ func buildChangeProcessor(
 		processors.NewTrustLinesProcessor(historyQ),
 		processors.NewClaimableBalancesChangeProcessor(historyQ),
 		processors.NewLiquidityPoolsChangeProcessor(historyQ, ledgerSequence),
-	})
+	}
+	if ingestOrderBookHistory {
+		// the order book history processor must run after the offers and
+		// liquidity pools processors because it reads the state they commit
+		changeProcessors = append(changeProcessors,
+			processors.NewOrderBookHistoryProcessor(historyQ, ledgerSequence, source == historyArchiveSource))
+	}
+
+	return newGroupChangeProcessors(changeProcessors)
 }
 
 func (s *ProcessorRunner) buildTransactionProcessor(ledgersProcessor *processors.LedgersProcessor, concurrencyMode history.ConcurrencyMode) (groupLoaders, *groupTransactionProcessors) {
@@ -144,6 +153,16 @@ func (s *ProcessorRunner) buildTransactionProcessor(ledgersProcessor *processors
 	tradeProcessor := processors.NewTradeProcessor(accountLoader,
 		lpLoader, assetLoader, s.historyQ.NewTradeBatchInsertBuilder())
 
+	var contractEventsProcessor *processors.ContractEventsProcessor
+	if s.config.IngestContractEvents {
+		contractEventsProcessor = processors.NewContractEventsProcessor(s.historyQ.NewContractEventBatchInsertBuilder())
+	}
+
+	var accountBalancesProcessor *processors.AccountBalancesProcessor
+	if s.config.IngestBalanceHistory {
+		accountBalancesProcessor = processors.NewAccountBalancesProcessor(accountLoader, assetLoader, s.historyQ.NewAccountBalanceBatchInsertBuilder())
+	}
+
 	processors := []horizonTransactionProcessor{
 		statsLedgerTransactionProcessor,
 		processors.NewEffectProcessor(accountLoader, s.historyQ.NewEffectBatchInsertBuilder(), s.config.NetworkPassphrase),
@@ -151,13 +170,22 @@ func (s *ProcessorRunner) buildTransactionProcessor(ledgersProcessor *processors
 		processors.NewOperationProcessor(s.historyQ.NewOperationBatchInsertBuilder(), s.config.NetworkPassphrase),
 		tradeProcessor,
 		processors.NewParticipantsProcessor(accountLoader,
-			s.historyQ.NewTransactionParticipantsBatchInsertBuilder(), s.historyQ.NewOperationParticipantBatchInsertBuilder(), s.config.NetworkPassphrase),
+			s.historyQ.NewTransactionParticipantsBatchInsertBuilder(), s.historyQ.NewOperationParticipantBatchInsertBuilder(),
+			s.historyQ.NewMuxedTransactionParticipantsBatchInsertBuilder(), s.historyQ.NewMuxedOperationParticipantBatchInsertBuilder(),
+			s.config.NetworkPassphrase),
 		processors.NewTransactionProcessor(s.historyQ.NewTransactionBatchInsertBuilder(), s.config.SkipTxmeta),
 		processors.NewClaimableBalancesTransactionProcessor(cbLoader,
 			s.historyQ.NewTransactionClaimableBalanceBatchInsertBuilder(), s.historyQ.NewOperationClaimableBalanceBatchInsertBuilder()),
 		processors.NewLiquidityPoolsTransactionProcessor(lpLoader,
 			s.historyQ.NewTransactionLiquidityPoolBatchInsertBuilder(), s.historyQ.NewOperationLiquidityPoolBatchInsertBuilder())}
 
+	if contractEventsProcessor != nil {
+		processors = append(processors, contractEventsProcessor)
+	}
+	if accountBalancesProcessor != nil {
+		processors = append(processors, accountBalancesProcessor)
+	}
+
 	return loaders, newGroupTransactionProcessors(processors, statsLedgerTransactionProcessor, tradeProcessor)
 }
 
@@ -204,6 +232,7 @@ func (s *ProcessorRunner) RunHistoryArchiveIngestion(
 		historyArchiveSource,
 		checkpointLedger,
 		s.config.NetworkPassphrase,
+		s.config.IngestOrderBookHistory,
 	)
 
 	if err := registerChangeProcessors(
@@ -585,6 +614,7 @@ func (s *ProcessorRunner) RunAllProcessorsOnLedger(ledger xdr.LedgerCloseMeta) (
 		ledgerSource,
 		ledger.LedgerSequence(),
 		s.config.NetworkPassphrase,
+		s.config.IngestOrderBookHistory,
 	)
 
 	registry := nameRegistry{}
@@ -151,7 +151,7 @@ func TestProcessorRunnerBuildChangeProcessor(t *testing.T) {
 	}
 
 	stats := &ingest.StatsChangeProcessor{}
-	processor := buildChangeProcessor(runner.historyQ, stats, ledgerSource, 123, "")
+	processor := buildChangeProcessor(runner.historyQ, stats, ledgerSource, 123, "", false)
 	assert.IsType(t, &groupChangeProcessors{}, processor)
 
 	assert.IsType(t, &statsChangeProcessor{}, processor.processors[0])
@@ -170,7 +170,7 @@ func TestProcessorRunnerBuildChangeProcessor(t *testing.T) {
 		filters:  &MockFilters{},
 	}
 
-	processor = buildChangeProcessor(runner.historyQ, stats, historyArchiveSource, 456, "")
+	processor = buildChangeProcessor(runner.historyQ, stats, historyArchiveSource, 456, "", false)
 	assert.IsType(t, &groupChangeProcessors{}, processor)
 
 	assert.IsType(t, &statsChangeProcessor{}, processor.processors[0])
@@ -182,6 +182,34 @@ func TestProcessorRunnerBuildChangeProcessor(t *testing.T) {
 		Elem().FieldByName("ingestFromHistoryArchive").Bool())
 	assert.IsType(t, &processors.SignersProcessor{}, processor.processors[5])
 	assert.IsType(t, &processors.TrustLinesProcessor{}, processor.processors[6])
+	assert.Len(t, processor.processors, 9)
+}
+
+func TestProcessorRunnerBuildChangeProcessorWithOrderBookHistory(t *testing.T) {
+	ctx := context.Background()
+
+	q := &mockDBQ{}
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	defer mock.AssertExpectationsForObjects(t, mockChangeProcessorBatchBuilders(q, ctx, false)...)
+	q.MockQOrderBookHistory.On("NewOfferHistoryBatchInsertBuilder").
+		Return(&history.MockOfferHistoryBatchInsertBuilder{}).Twice()
+	q.MockQOrderBookHistory.On("NewLiquidityPoolReservesHistoryBatchInsertBuilder").
+		Return(&history.MockLiquidityPoolReservesHistoryBatchInsertBuilder{}).Twice()
+	defer q.MockQOrderBookHistory.AssertExpectations(t)
+
+	stats := &ingest.StatsChangeProcessor{}
+	processor := buildChangeProcessor(q, stats, ledgerSource, 123, "", true)
+	assert.Len(t, processor.processors, 10)
+	assert.IsType(t, &processors.OrderBookHistoryProcessor{}, processor.processors[9])
+	assert.False(t, reflect.ValueOf(processor.processors[9]).
+		Elem().FieldByName("fromCheckpoint").Bool())
+
+	processor = buildChangeProcessor(q, stats, historyArchiveSource, 456, "", true)
+	assert.Len(t, processor.processors, 10)
+	assert.IsType(t, &processors.OrderBookHistoryProcessor{}, processor.processors[9])
+	assert.True(t, reflect.ValueOf(processor.processors[9]).
+		Elem().FieldByName("fromCheckpoint").Bool())
 }
 
 func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
@@ -203,6 +231,10 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 		Return(&history.MockTransactionParticipantsBatchInsertBuilder{})
 	q.On("NewOperationParticipantBatchInsertBuilder").
 		Return(&history.MockOperationParticipantBatchInsertBuilder{})
+	q.On("NewMuxedTransactionParticipantsBatchInsertBuilder").
+		Return(&history.MockMuxedTransactionParticipantsBatchInsertBuilder{})
+	q.On("NewMuxedOperationParticipantBatchInsertBuilder").
+		Return(&history.MockMuxedOperationParticipantBatchInsertBuilder{})
 	q.MockQHistoryClaimableBalances.On("NewTransactionClaimableBalanceBatchInsertBuilder").
 		Return(&history.MockTransactionClaimableBalanceBatchInsertBuilder{})
 	q.MockQHistoryClaimableBalances.On("NewOperationClaimableBalanceBatchInsertBuilder").
@@ -230,6 +262,26 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 	assert.IsType(t, &processors.ParticipantsProcessor{}, processor.processors[5])
 	assert.IsType(t, &processors.ClaimableBalancesTransactionProcessor{}, processor.processors[7])
 	assert.IsType(t, &processors.LiquidityPoolsTransactionProcessor{}, processor.processors[8])
+	assert.Len(t, processor.processors, 9)
+
+	// contract events are only ingested when enabled
+	q.MockQContractEvents.On("NewContractEventBatchInsertBuilder").
+		Return(&history.MockContractEventBatchInsertBuilder{}).Once()
+	runner.config.IngestContractEvents = true
+	_, processor = runner.buildTransactionProcessor(ledgersProcessor, history.ConcurrentInserts)
+	assert.Len(t, processor.processors, 10)
+	assert.IsType(t, &processors.ContractEventsProcessor{}, processor.processors[9])
+	q.MockQContractEvents.AssertExpectations(t)
+
+	// account balances are only ingested when enabled
+	q.MockQAccountBalanceHistory.On("NewAccountBalanceBatchInsertBuilder").
+		Return(&history.MockAccountBalanceBatchInsertBuilder{}).Once()
+	runner.config.IngestContractEvents = false
+	runner.config.IngestBalanceHistory = true
+	_, processor = runner.buildTransactionProcessor(ledgersProcessor, history.ConcurrentInserts)
+	assert.Len(t, processor.processors, 10)
+	assert.IsType(t, &processors.AccountBalancesProcessor{}, processor.processors[9])
+	q.MockQAccountBalanceHistory.AssertExpectations(t)
 }
 
 func TestProcessorRunnerRunAllProcessorsOnLedger(t *testing.T) {
@@ -474,6 +526,16 @@ func mockTxProcessorBatchBuilders(q *mockDBQ, mockSession *db.MockSession, ctx c
 	q.On("NewOperationParticipantBatchInsertBuilder").
 		Return(mockOperationParticipantBatchInsertBuilder).Once()
 
+	mockMuxedTransactionParticipantsBatchInsertBuilder := &history.MockMuxedTransactionParticipantsBatchInsertBuilder{}
+	mockMuxedTransactionParticipantsBatchInsertBuilder.On("Exec", ctx, mockSession).Return(nil).Once()
+	q.On("NewMuxedTransactionParticipantsBatchInsertBuilder").
+		Return(mockMuxedTransactionParticipantsBatchInsertBuilder).Once()
+
+	mockMuxedOperationParticipantBatchInsertBuilder := &history.MockMuxedOperationParticipantBatchInsertBuilder{}
+	mockMuxedOperationParticipantBatchInsertBuilder.On("Exec", ctx, mockSession).Return(nil).Once()
+	q.On("NewMuxedOperationParticipantBatchInsertBuilder").
+		Return(mockMuxedOperationParticipantBatchInsertBuilder).Once()
+
 	mockTransactionClaimableBalanceBatchInsertBuilder := &history.MockTransactionClaimableBalanceBatchInsertBuilder{}
 	mockTransactionClaimableBalanceBatchInsertBuilder.On("Exec", ctx, mockSession).Return(nil).Once()
 	q.MockQHistoryClaimableBalances.On("NewTransactionClaimableBalanceBatchInsertBuilder").
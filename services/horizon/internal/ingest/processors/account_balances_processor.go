@@ -0,0 +1,146 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountBalancesProcessor inserts the native and trust line balances of
+// accounts at the end of every ledger in which they changed into the
+// history_account_balances table.
+type AccountBalancesProcessor struct {
+	accountLoader *history.AccountLoader
+	assetLoader   *history.AssetLoader
+	batch         history.AccountBalanceBatchInsertBuilder
+	balances      map[accountBalanceKey]accountBalance
+}
+
+type accountBalanceKey struct {
+	ledgerSequence uint32
+	account        string
+	asset          string
+}
+
+type accountBalance struct {
+	ledgerCloseTime time.Time
+	accountID       history.FutureAccountID
+	assetID         history.FutureAssetID
+	balance         int64
+	// fromFee is true when the balance was set by a fee change. Fees are
+	// charged before any transaction in the ledger is applied so a balance
+	// set by any other change is always more recent.
+	fromFee bool
+}
+
+func NewAccountBalancesProcessor(
+	accountLoader *history.AccountLoader,
+	assetLoader *history.AssetLoader,
+	batch history.AccountBalanceBatchInsertBuilder,
+) *AccountBalancesProcessor {
+	return &AccountBalancesProcessor{
+		accountLoader: accountLoader,
+		assetLoader:   assetLoader,
+		batch:         batch,
+		balances:      map[accountBalanceKey]accountBalance{},
+	}
+}
+
+func (p *AccountBalancesProcessor) Name() string {
+	return "processors.AccountBalancesProcessor"
+}
+
+func (p *AccountBalancesProcessor) ProcessTransaction(lcm xdr.LedgerCloseMeta, transaction ingest.LedgerTransaction) error {
+	ledgerSequence := lcm.LedgerSequence()
+	closeTime := time.Unix(lcm.LedgerCloseTime(), 0).UTC()
+
+	for _, change := range transaction.GetFeeChanges() {
+		p.processChange(ledgerSequence, closeTime, change, true)
+	}
+
+	changes, err := transaction.GetChanges()
+	if err != nil {
+		return errors.Wrap(err, "could not determine changes in transaction")
+	}
+	for _, change := range changes {
+		p.processChange(ledgerSequence, closeTime, change, false)
+	}
+	return nil
+}
+
+func (p *AccountBalancesProcessor) processChange(ledgerSequence uint32, closeTime time.Time, change ingest.Change, fromFee bool) {
+	var (
+		account string
+		asset   xdr.Asset
+		balance int64
+	)
+
+	switch change.Type {
+	case xdr.LedgerEntryTypeAccount:
+		entry := change.Post
+		if entry == nil {
+			entry = change.Pre
+		}
+		account = entry.Data.MustAccount().AccountId.Address()
+		asset = xdr.MustNewNativeAsset()
+		if change.Post != nil {
+			balance = int64(change.Post.Data.MustAccount().Balance)
+		}
+	case xdr.LedgerEntryTypeTrustline:
+		entry := change.Post
+		if entry == nil {
+			entry = change.Pre
+		}
+		trustLine := entry.Data.MustTrustLine()
+		// liquidity pool shares are not tracked
+		if trustLine.Asset.Type == xdr.AssetTypeAssetTypePoolShare {
+			return
+		}
+		account = trustLine.AccountId.Address()
+		asset = trustLine.Asset.ToAsset()
+		if change.Post != nil {
+			balance = int64(change.Post.Data.MustTrustLine().Balance)
+		}
+	default:
+		return
+	}
+
+	key := accountBalanceKey{
+		ledgerSequence: ledgerSequence,
+		account:        account,
+		asset:          asset.StringCanonical(),
+	}
+	if existing, ok := p.balances[key]; ok && fromFee && !existing.fromFee {
+		return
+	}
+	p.balances[key] = accountBalance{
+		ledgerCloseTime: closeTime,
+		accountID:       p.accountLoader.GetFuture(account),
+		assetID:         p.assetLoader.GetFuture(history.AssetKeyFromXDR(asset)),
+		balance:         balance,
+		fromFee:         fromFee,
+	}
+}
+
+func (p *AccountBalancesProcessor) Flush(ctx context.Context, session db.SessionInterface) error {
+	for key, balance := range p.balances {
+		err := p.batch.Add(
+			toid.New(int32(key.ledgerSequence), 0, 0).ToInt64(),
+			balance.ledgerCloseTime,
+			balance.accountID,
+			balance.assetID,
+			balance.balance,
+		)
+		if err != nil {
+			return errors.Wrap(err, "could not insert account balance")
+		}
+	}
+	p.balances = map[accountBalanceKey]accountBalance{}
+	return p.batch.Exec(ctx, session)
+}
@@ -0,0 +1,139 @@
+package processors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func accountBalanceEntry(account string, balance int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId: xdr.MustAddress(account),
+				Balance:   xdr.Int64(balance),
+			},
+		},
+	}
+}
+
+func trustLineBalanceEntry(account string, asset xdr.Asset, balance int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{
+				AccountId: xdr.MustAddress(account),
+				Asset:     asset.ToTrustLineAsset(),
+				Balance:   xdr.Int64(balance),
+			},
+		},
+	}
+}
+
+func updatedEntryChanges(pre, post xdr.LedgerEntry) xdr.LedgerEntryChanges {
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &post},
+	}
+}
+
+func TestAccountBalancesProcessor(t *testing.T) {
+	ctx := context.Background()
+	source := "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	destination := "GANFZDRBCNTUXIODCJEYMACPMCSZEVE4WZGZ3CZDZ3P2SXK4KH75IK6Y"
+	usd := xdr.MustNewCreditAsset("USD", source)
+
+	ledger := contractEventsTestLedger()
+	firstTx := ingest.LedgerTransaction{
+		Index:      1,
+		FeeChanges: updatedEntryChanges(accountBalanceEntry(source, 1000), accountBalanceEntry(source, 900)),
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 3,
+			V3: &xdr.TransactionMetaV3{
+				Operations: []xdr.OperationMeta{
+					{
+						Changes: append(
+							updatedEntryChanges(accountBalanceEntry(source, 800), accountBalanceEntry(source, 700)),
+							xdr.LedgerEntryChange{
+								Type:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+								Created: &[]xdr.LedgerEntry{trustLineBalanceEntry(destination, usd, 50)}[0],
+							},
+						),
+					},
+				},
+			},
+		},
+	}
+	// the fee of the second transaction is charged before the first
+	// transaction is applied so it must not override its balance
+	secondTx := ingest.LedgerTransaction{
+		Index:      2,
+		FeeChanges: updatedEntryChanges(accountBalanceEntry(source, 900), accountBalanceEntry(source, 800)),
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 3,
+			V3: &xdr.TransactionMetaV3{
+				Operations: []xdr.OperationMeta{
+					{
+						Changes: xdr.LedgerEntryChanges{
+							{
+								Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+								State: &[]xdr.LedgerEntry{trustLineBalanceEntry(destination, usd, 50)}[0],
+							},
+							{
+								Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+								Removed: &xdr.LedgerKey{
+									Type: xdr.LedgerEntryTypeTrustline,
+									TrustLine: &xdr.LedgerKeyTrustLine{
+										AccountId: xdr.MustAddress(destination),
+										Asset:     usd.ToTrustLineAsset(),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	accountLoader := history.NewAccountLoader(history.ConcurrentInserts)
+	assetLoader := history.NewAssetLoader(history.ConcurrentInserts)
+	batch := &history.MockAccountBalanceBatchInsertBuilder{}
+	processor := NewAccountBalancesProcessor(accountLoader, assetLoader, batch)
+
+	require.NoError(t, processor.ProcessTransaction(ledger, firstTx))
+	require.NoError(t, processor.ProcessTransaction(ledger, secondTx))
+
+	ledgerID := toid.New(20, 0, 0).ToInt64()
+	closeTime := time.Unix(1000, 0).UTC()
+	batch.On(
+		"Add",
+		ledgerID,
+		closeTime,
+		accountLoader.GetFuture(source),
+		assetLoader.GetFuture(history.AssetKeyFromXDR(xdr.MustNewNativeAsset())),
+		int64(700),
+	).Return(nil).Once()
+	batch.On(
+		"Add",
+		ledgerID,
+		closeTime,
+		accountLoader.GetFuture(destination),
+		assetLoader.GetFuture(history.AssetKeyFromXDR(usd)),
+		int64(0),
+	).Return(nil).Once()
+	batch.On("Exec", ctx, &db.MockSession{}).Return(nil).Once()
+
+	assert.NoError(t, processor.Flush(ctx, &db.MockSession{}))
+	batch.AssertExpectations(t)
+}
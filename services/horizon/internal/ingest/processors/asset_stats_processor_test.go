@@ -682,6 +682,7 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestInsertContractBalance() {
 		{
 			KeyHash:          keyHash[:],
 			ContractID:       usdID[:],
+			HolderID:         []byte{1, 31: 0},
 			Amount:           "200",
 			ExpirationLedger: 2234,
 		},
@@ -968,6 +969,7 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestInsertContractIDWithBalance() {
 		{
 			KeyHash:          keyHash[:],
 			ContractID:       btcID[:],
+			HolderID:         []byte{1, 31: 0},
 			Amount:           "20",
 			ExpirationLedger: 2234,
 		},
@@ -1330,6 +1332,7 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestUpdateContractIDWithBalance() {
 		{
 			KeyHash:          keyHash[:],
 			ContractID:       eurID[:],
+			HolderID:         []byte{1, 31: 0},
 			Amount:           "150",
 			ExpirationLedger: 2234,
 		},
@@ -161,7 +161,7 @@ func (s *ContractAssetStatSet) ingestContractAssetBalance(ctx context.Context, c
 			return nil
 		}
 
-		_, postAmt, postOk := ContractBalanceFromContractData(*change.Post, s.networkPassphrase)
+		holder, postAmt, postOk := ContractBalanceFromContractData(*change.Post, s.networkPassphrase)
 		// we only ingest created ledger entries if we determine that they resemble the shape of
 		// a Stellar Asset Contract balance ledger entry
 		if !postOk {
@@ -179,6 +179,7 @@ func (s *ContractAssetStatSet) ingestContractAssetBalance(ctx context.Context, c
 		s.createdBalances = append(s.createdBalances, history.ContractAssetBalance{
 			KeyHash:          keyHash[:],
 			ContractID:       (*pContractID)[:],
+			HolderID:         holder[:],
 			Amount:           postAmt.String(),
 			ExpirationLedger: expirationLedger,
 		})
@@ -147,18 +147,21 @@ func TestAddContractData(t *testing.T) {
 		{
 			KeyHash:          uniBalanceKeyHash[:],
 			ContractID:       uniID[:],
+			HolderID:         make([]byte, 32),
 			Amount:           "0",
 			ExpirationLedger: 150,
 		},
 		{
 			KeyHash:          etherBalanceKeyHash[:],
 			ContractID:       etherID[:],
+			HolderID:         make([]byte, 32),
 			Amount:           "50",
 			ExpirationLedger: 100,
 		},
 		{
 			KeyHash:          otherEtherBalanceKeyHash[:],
 			ContractID:       etherID[:],
+			HolderID:         []byte{1, 31: 0},
 			Amount:           "150",
 			ExpirationLedger: 150,
 		},
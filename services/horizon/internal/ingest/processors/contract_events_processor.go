@@ -0,0 +1,104 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// ContractEventsProcessor inserts the contract and system events emitted by
+// successful Soroban transactions into the history_contract_events table.
+type ContractEventsProcessor struct {
+	batch history.ContractEventBatchInsertBuilder
+}
+
+func NewContractEventsProcessor(batch history.ContractEventBatchInsertBuilder) *ContractEventsProcessor {
+	return &ContractEventsProcessor{
+		batch: batch,
+	}
+}
+
+func (p *ContractEventsProcessor) Name() string {
+	return "processors.ContractEventsProcessor"
+}
+
+func (p *ContractEventsProcessor) ProcessTransaction(lcm xdr.LedgerCloseMeta, transaction ingest.LedgerTransaction) error {
+	// Failed transactions don't emit events
+	if !transaction.Result.Successful() {
+		return nil
+	}
+	if transaction.UnsafeMeta.V != 3 || transaction.UnsafeMeta.MustV3().SorobanMeta == nil {
+		return nil
+	}
+
+	// Soroban transactions contain a single operation, so all the events
+	// belong to the first one.
+	operationID := toid.New(int32(lcm.LedgerSequence()), int32(transaction.Index), 1).ToInt64()
+	closeTime := time.Unix(lcm.LedgerCloseTime(), 0).UTC()
+	transactionHash := transaction.Result.TransactionHash.HexString()
+
+	for i, event := range transaction.UnsafeMeta.MustV3().SorobanMeta.Events {
+		row, err := contractEventRow(event)
+		if err != nil {
+			return errors.Wrapf(err, "could not process event %d of transaction %s", i, transactionHash)
+		}
+		row.HistoryOperationID = operationID
+		row.Order = int32(i + 1)
+		row.TransactionHash = transactionHash
+		row.LedgerCloseTime = closeTime
+		if err = p.batch.Add(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ContractEventsProcessor) Flush(ctx context.Context, session db.SessionInterface) error {
+	return p.batch.Exec(ctx, session)
+}
+
+func contractEventRow(event xdr.ContractEvent) (history.ContractEvent, error) {
+	row := history.ContractEvent{Type: event.Type}
+
+	if event.ContractId != nil {
+		contractID, err := strkey.Encode(strkey.VersionByteContract, event.ContractId[:])
+		if err != nil {
+			return history.ContractEvent{}, errors.Wrap(err, "invalid contract id")
+		}
+		row.ContractID = null.StringFrom(contractID)
+	}
+
+	body, ok := event.Body.GetV0()
+	if !ok {
+		return history.ContractEvent{}, errors.Errorf("unsupported event body version %d", event.Body.V)
+	}
+	topics := []*null.String{&row.Topic1, &row.Topic2, &row.Topic3, &row.Topic4}
+	for i, topic := range body.Topics {
+		// Only the first topics can be filtered on, the full event is kept
+		// in event_xdr.
+		if i >= len(topics) {
+			break
+		}
+		encoded, err := xdr.MarshalBase64(topic)
+		if err != nil {
+			return history.ContractEvent{}, errors.Wrapf(err, "could not encode topic %d", i)
+		}
+		*topics[i] = null.StringFrom(encoded)
+	}
+
+	eventXDR, err := xdr.MarshalBase64(event)
+	if err != nil {
+		return history.ContractEvent{}, errors.Wrap(err, "could not encode event")
+	}
+	row.EventXDR = eventXDR
+	return row, nil
+}
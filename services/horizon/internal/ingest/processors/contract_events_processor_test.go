@@ -0,0 +1,146 @@
+package processors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func contractEventsTestLedger() xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					LedgerSeq: 20,
+					ScpValue:  xdr.StellarValue{CloseTime: 1000},
+				},
+			},
+		},
+	}
+}
+
+func contractEventsTestTransaction(successful bool, events ...xdr.ContractEvent) ingest.LedgerTransaction {
+	code := xdr.TransactionResultCodeTxSuccess
+	if !successful {
+		code = xdr.TransactionResultCodeTxFailed
+	}
+	return ingest.LedgerTransaction{
+		Index: 2,
+		Result: xdr.TransactionResultPair{
+			TransactionHash: xdr.Hash{1, 2, 3},
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    code,
+					Results: &[]xdr.OperationResult{},
+				},
+			},
+		},
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 3,
+			V3: &xdr.TransactionMetaV3{
+				SorobanMeta: &xdr.SorobanTransactionMeta{
+					Events: events,
+				},
+			},
+		},
+	}
+}
+
+func TestContractEventsProcessor(t *testing.T) {
+	contractID := xdr.Hash{0xaa}
+	symbol := xdr.ScSymbol("transfer")
+	amount := xdr.Uint32(10)
+	contractEvent := xdr.ContractEvent{
+		ContractId: &contractID,
+		Type:       xdr.ContractEventTypeContract,
+		Body: xdr.ContractEventBody{
+			V: 0,
+			V0: &xdr.ContractEventV0{
+				Topics: []xdr.ScVal{
+					{Type: xdr.ScValTypeScvSymbol, Sym: &symbol},
+					{Type: xdr.ScValTypeScvU32, U32: &amount},
+				},
+				Data: xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &amount},
+			},
+		},
+	}
+	systemEvent := xdr.ContractEvent{
+		Type: xdr.ContractEventTypeSystem,
+		Body: xdr.ContractEventBody{
+			V: 0,
+			V0: &xdr.ContractEventV0{
+				Data: xdr.ScVal{Type: xdr.ScValTypeScvVoid},
+			},
+		},
+	}
+
+	symbolXDR, err := xdr.MarshalBase64(contractEvent.Body.V0.Topics[0])
+	require.NoError(t, err)
+	amountXDR, err := xdr.MarshalBase64(contractEvent.Body.V0.Topics[1])
+	require.NoError(t, err)
+	contractEventXDR, err := xdr.MarshalBase64(contractEvent)
+	require.NoError(t, err)
+	systemEventXDR, err := xdr.MarshalBase64(systemEvent)
+	require.NoError(t, err)
+
+	contractAddress, err := strkey.Encode(strkey.VersionByteContract, contractID[:])
+	require.NoError(t, err)
+	operationID := toid.New(20, 2, 1).ToInt64()
+	transactionHash := xdr.Hash{1, 2, 3}.HexString()
+	closeTime := time.Unix(1000, 0).UTC()
+
+	batch := &history.MockContractEventBatchInsertBuilder{}
+	batch.On("Add", history.ContractEvent{
+		HistoryOperationID: operationID,
+		Order:              1,
+		TransactionHash:    transactionHash,
+		LedgerCloseTime:    closeTime,
+		ContractID:         null.StringFrom(contractAddress),
+		Type:               xdr.ContractEventTypeContract,
+		Topic1:             null.StringFrom(symbolXDR),
+		Topic2:             null.StringFrom(amountXDR),
+		EventXDR:           contractEventXDR,
+	}).Return(nil).Once()
+	batch.On("Add", history.ContractEvent{
+		HistoryOperationID: operationID,
+		Order:              2,
+		TransactionHash:    transactionHash,
+		LedgerCloseTime:    closeTime,
+		Type:               xdr.ContractEventTypeSystem,
+		EventXDR:           systemEventXDR,
+	}).Return(nil).Once()
+	session := &db.MockSession{}
+	batch.On("Exec", context.Background(), session).Return(nil).Once()
+	defer batch.AssertExpectations(t)
+
+	processor := NewContractEventsProcessor(batch)
+	ledger := contractEventsTestLedger()
+	require.NoError(t, processor.ProcessTransaction(ledger, contractEventsTestTransaction(true, contractEvent, systemEvent)))
+	// events of failed transactions are not ingested
+	require.NoError(t, processor.ProcessTransaction(ledger, contractEventsTestTransaction(false, contractEvent)))
+	require.NoError(t, processor.Flush(context.Background(), session))
+}
+
+func TestContractEventsProcessorUnsupportedBody(t *testing.T) {
+	batch := &history.MockContractEventBatchInsertBuilder{}
+	defer batch.AssertExpectations(t)
+
+	processor := NewContractEventsProcessor(batch)
+	err := processor.ProcessTransaction(
+		contractEventsTestLedger(),
+		contractEventsTestTransaction(true, xdr.ContractEvent{Type: xdr.ContractEventTypeContract, Body: xdr.ContractEventBody{V: 1}}),
+	)
+	assert.EqualError(t, err, "could not process event 0 of transaction "+xdr.Hash{1, 2, 3}.HexString()+
+		": unsupported event body version 1")
+}
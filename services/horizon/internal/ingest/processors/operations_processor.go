@@ -1133,6 +1133,33 @@ func getParticipantsFromSACEvents(contractEvents []xdr.ContractEvent, network st
 	return participants
 }
 
+// MuxedParticipants returns the muxed accounts (M...) taking part in the
+// operation, accounts which are not muxed are omitted.
+func (operation *transactionOperationWrapper) MuxedParticipants() []xdr.MuxedAccount {
+	participants := []xdr.MuxedAccount{*operation.SourceAccount()}
+	op := operation.operation
+
+	switch operation.OperationType() {
+	case xdr.OperationTypePayment:
+		participants = append(participants, op.Body.MustPaymentOp().Destination)
+	case xdr.OperationTypePathPaymentStrictReceive:
+		participants = append(participants, op.Body.MustPathPaymentStrictReceiveOp().Destination)
+	case xdr.OperationTypePathPaymentStrictSend:
+		participants = append(participants, op.Body.MustPathPaymentStrictSendOp().Destination)
+	case xdr.OperationTypeAccountMerge:
+		participants = append(participants, op.Body.MustDestination())
+	case xdr.OperationTypeEndSponsoringFutureReserves:
+		beginSponsorshipOp := operation.findInitatingBeginSponsoringOp()
+		if beginSponsorshipOp != nil {
+			participants = append(participants, *beginSponsorshipOp.SourceAccount())
+		}
+	case xdr.OperationTypeClawback:
+		participants = append(participants, op.Body.MustClawbackOp().From)
+	}
+
+	return dedupeMuxedParticipants(participants)
+}
+
 // dedupeParticipants remove any duplicate ids from `in`
 func dedupeParticipants(in []xdr.AccountId) []xdr.AccountId {
 	if len(in) <= 1 {
@@ -1155,6 +1182,25 @@ func dedupeParticipants(in []xdr.AccountId) []xdr.AccountId {
 
 }
 
+// dedupeMuxedParticipants removes the accounts which are not muxed and any
+// duplicate muxed accounts from `in`
+func dedupeMuxedParticipants(in []xdr.MuxedAccount) []xdr.MuxedAccount {
+	var out []xdr.MuxedAccount
+	seen := map[string]bool{}
+	for _, account := range in {
+		if account.Type != xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+			continue
+		}
+		address := account.Address()
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		out = append(out, account)
+	}
+	return out
+}
+
 // OperationsParticipants returns a map with all participants per operation
 func operationsParticipants(transaction ingest.LedgerTransaction, sequence uint32, network string) (map[int64][]xdr.AccountId, error) {
 	participants := map[int64][]xdr.AccountId{}
@@ -1177,3 +1223,24 @@ func operationsParticipants(transaction ingest.LedgerTransaction, sequence uint3
 
 	return participants, nil
 }
+
+// operationsMuxedParticipants returns a map with the muxed accounts taking
+// part in each operation, operations without muxed participants are omitted
+func operationsMuxedParticipants(transaction ingest.LedgerTransaction, sequence uint32) map[int64][]xdr.MuxedAccount {
+	participants := map[int64][]xdr.MuxedAccount{}
+
+	for opi, op := range transaction.Envelope.Operations() {
+		operation := transactionOperationWrapper{
+			index:          uint32(opi),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: sequence,
+		}
+
+		if p := operation.MuxedParticipants(); len(p) > 0 {
+			participants[operation.ID()] = p
+		}
+	}
+
+	return participants
+}
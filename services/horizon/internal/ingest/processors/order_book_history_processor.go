@@ -0,0 +1,153 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// OrderBookHistoryProcessor records the state of every offer and liquidity
+// pool at the end of each ledger in which it changed, so the order book of a
+// trading pair can be reconstructed at past ledgers.
+//
+// The order book history is seeded from the offers and liquidity_pools
+// tables so OrderBookHistoryProcessor must be committed after the
+// OffersProcessor and LiquidityPoolsChangeProcessor.
+type OrderBookHistoryProcessor struct {
+	historyQ       history.QOrderBookHistory
+	sequence       uint32
+	fromCheckpoint bool
+
+	offersBatch history.OfferHistoryBatchInsertBuilder
+	poolsBatch  history.LiquidityPoolReservesHistoryBatchInsertBuilder
+}
+
+func NewOrderBookHistoryProcessor(historyQ history.QOrderBookHistory, sequence uint32, fromCheckpoint bool) *OrderBookHistoryProcessor {
+	p := &OrderBookHistoryProcessor{
+		historyQ:       historyQ,
+		sequence:       sequence,
+		fromCheckpoint: fromCheckpoint,
+	}
+	p.reset()
+	return p
+}
+
+func (p *OrderBookHistoryProcessor) Name() string {
+	return "processors.OrderBookHistoryProcessor"
+}
+
+func (p *OrderBookHistoryProcessor) reset() {
+	p.offersBatch = p.historyQ.NewOfferHistoryBatchInsertBuilder()
+	p.poolsBatch = p.historyQ.NewLiquidityPoolReservesHistoryBatchInsertBuilder()
+}
+
+func (p *OrderBookHistoryProcessor) ProcessChange(ctx context.Context, change ingest.Change) error {
+	// When ingesting a checkpoint the order book history is seeded from the
+	// state tables in Commit.
+	if p.fromCheckpoint {
+		return nil
+	}
+
+	ledgerID := toid.New(int32(p.sequence), 0, 0).ToInt64()
+	switch change.Type {
+	case xdr.LedgerEntryTypeOffer:
+		entry, deleted := change.Post, false
+		if entry == nil {
+			entry, deleted = change.Pre, true
+		}
+		offer := entry.Data.MustOffer()
+		err := p.offersBatch.Add(history.OfferHistory{
+			OfferID:         int64(offer.OfferId),
+			HistoryLedgerID: ledgerID,
+			SellingAsset:    offer.Selling,
+			BuyingAsset:     offer.Buying,
+			Amount:          int64(offer.Amount),
+			Pricen:          int32(offer.Price.N),
+			Priced:          int32(offer.Price.D),
+			Price:           float64(offer.Price.N) / float64(offer.Price.D),
+			Deleted:         deleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error adding to OfferHistoryBatchInsertBuilder")
+		}
+	case xdr.LedgerEntryTypeLiquidityPool:
+		entry, deleted := change.Post, false
+		if entry == nil {
+			entry, deleted = change.Pre, true
+		}
+		pool := entry.Data.MustLiquidityPool()
+		cp := pool.Body.MustConstantProduct()
+		err := p.poolsBatch.Add(history.LiquidityPoolReservesHistory{
+			PoolID:          PoolIDToString(pool.LiquidityPoolId),
+			HistoryLedgerID: ledgerID,
+			AssetA:          cp.Params.AssetA,
+			AssetB:          cp.Params.AssetB,
+			ReserveA:        int64(cp.ReserveA),
+			ReserveB:        int64(cp.ReserveB),
+			Fee:             int32(cp.Params.Fee),
+			Deleted:         deleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error adding to LiquidityPoolReservesHistoryBatchInsertBuilder")
+		}
+	default:
+		return nil
+	}
+
+	if p.offersBatch.Len()+p.poolsBatch.Len() > maxBatchSize {
+		if err := p.flushCache(ctx); err != nil {
+			return errors.Wrap(err, "error in Commit")
+		}
+	}
+
+	return nil
+}
+
+func (p *OrderBookHistoryProcessor) flushCache(ctx context.Context) error {
+	defer p.reset()
+
+	if err := p.offersBatch.Exec(ctx); err != nil {
+		return errors.Wrap(err, "error executing OfferHistoryBatchInsertBuilder")
+	}
+	if err := p.poolsBatch.Exec(ctx); err != nil {
+		return errors.Wrap(err, "error executing LiquidityPoolReservesHistoryBatchInsertBuilder")
+	}
+	return nil
+}
+
+func (p *OrderBookHistoryProcessor) Commit(ctx context.Context) error {
+	startLedger, err := p.historyQ.GetOrderBookHistoryStartLedger(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get order book history start ledger")
+	}
+	lastLedger, err := p.historyQ.GetOrderBookHistoryLastLedger(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get order book history last ledger")
+	}
+
+	if startLedger > 0 && startLedger <= p.sequence && p.sequence <= lastLedger {
+		// The history already holds the state at the end of the ledger, for
+		// instance when the state is rebuilt from a checkpoint which was
+		// ingested before.
+		return nil
+	}
+	if startLedger > 0 && startLedger <= p.sequence && p.sequence == lastLedger+1 && !p.fromCheckpoint {
+		if err := p.flushCache(ctx); err != nil {
+			return err
+		}
+		return p.historyQ.UpdateOrderBookHistoryLastLedger(ctx, p.sequence)
+	}
+
+	// The order book history is seeded when it has not been ingested yet
+	// (for instance, when the feature was just enabled) or when it has a
+	// gap, for instance when the flag was disabled for a while or the state
+	// is rebuilt from a checkpoint after the history.
+	if err := p.historyQ.ResetOrderBookHistory(ctx, p.sequence); err != nil {
+		return errors.Wrap(err, "could not reset order book history")
+	}
+	return nil
+}
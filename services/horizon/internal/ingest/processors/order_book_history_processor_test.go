@@ -0,0 +1,195 @@
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestOrderBookHistoryProcessorTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderBookHistoryProcessorTestSuite))
+}
+
+type OrderBookHistoryProcessorTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	mockQ       *history.MockQOrderBookHistory
+	offersBatch *history.MockOfferHistoryBatchInsertBuilder
+	poolsBatch  *history.MockLiquidityPoolReservesHistoryBatchInsertBuilder
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.mockQ = &history.MockQOrderBookHistory{}
+	s.offersBatch = &history.MockOfferHistoryBatchInsertBuilder{}
+	s.poolsBatch = &history.MockLiquidityPoolReservesHistoryBatchInsertBuilder{}
+	s.mockQ.On("NewOfferHistoryBatchInsertBuilder").Return(s.offersBatch)
+	s.mockQ.On("NewLiquidityPoolReservesHistoryBatchInsertBuilder").Return(s.poolsBatch)
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+	s.offersBatch.AssertExpectations(s.T())
+	s.poolsBatch.AssertExpectations(s.T())
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) offerEntry(amount xdr.Int64) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.OfferEntry{
+				SellerId: xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"),
+				OfferId:  xdr.Int64(200),
+				Selling:  xdr.MustNewNativeAsset(),
+				Buying:   xdr.MustNewCreditAsset("USD", "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"),
+				Amount:   amount,
+				Price:    xdr.Price{N: 1, D: 2},
+			},
+		},
+	}
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) TestCheckpointResetsHistory() {
+	processor := NewOrderBookHistoryProcessor(s.mockQ, 63, true)
+
+	s.Assert().NoError(processor.ProcessChange(s.ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Post: s.offerEntry(100),
+	}))
+
+	s.mockQ.On("GetOrderBookHistoryStartLedger", s.ctx).Return(uint32(0), nil).Once()
+	s.mockQ.On("GetOrderBookHistoryLastLedger", s.ctx).Return(uint32(0), nil).Once()
+	s.mockQ.On("ResetOrderBookHistory", s.ctx, uint32(63)).Return(nil).Once()
+	s.Assert().NoError(processor.Commit(s.ctx))
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) TestCheckpointKeepsIngestedHistory() {
+	processor := NewOrderBookHistoryProcessor(s.mockQ, 63, true)
+
+	s.Assert().NoError(processor.ProcessChange(s.ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Post: s.offerEntry(100),
+	}))
+
+	// the history holds the state at the checkpoint so it is not seeded again
+	s.mockQ.On("GetOrderBookHistoryStartLedger", s.ctx).Return(uint32(10), nil).Once()
+	s.mockQ.On("GetOrderBookHistoryLastLedger", s.ctx).Return(uint32(70), nil).Once()
+	s.Assert().NoError(processor.Commit(s.ctx))
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) TestCheckpointResetsHistoryAfterGap() {
+	processor := NewOrderBookHistoryProcessor(s.mockQ, 127, true)
+
+	s.mockQ.On("GetOrderBookHistoryStartLedger", s.ctx).Return(uint32(10), nil).Once()
+	s.mockQ.On("GetOrderBookHistoryLastLedger", s.ctx).Return(uint32(70), nil).Once()
+	s.mockQ.On("ResetOrderBookHistory", s.ctx, uint32(127)).Return(nil).Once()
+	s.Assert().NoError(processor.Commit(s.ctx))
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) TestLedgerResetsHistoryWhenNotIngested() {
+	processor := NewOrderBookHistoryProcessor(s.mockQ, 64, false)
+
+	s.offersBatch.On("Add", history.OfferHistory{
+		OfferID:         200,
+		HistoryLedgerID: toid.New(64, 0, 0).ToInt64(),
+		SellingAsset:    xdr.MustNewNativeAsset(),
+		BuyingAsset:     xdr.MustNewCreditAsset("USD", "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"),
+		Amount:          100,
+		Pricen:          1,
+		Priced:          2,
+		Price:           0.5,
+	}).Return(nil).Once()
+	s.offersBatch.On("Len").Return(1)
+	s.poolsBatch.On("Len").Return(0)
+	s.Assert().NoError(processor.ProcessChange(s.ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Post: s.offerEntry(100),
+	}))
+
+	s.mockQ.On("GetOrderBookHistoryStartLedger", s.ctx).Return(uint32(0), nil).Once()
+	s.mockQ.On("GetOrderBookHistoryLastLedger", s.ctx).Return(uint32(0), nil).Once()
+	s.mockQ.On("ResetOrderBookHistory", s.ctx, uint32(64)).Return(nil).Once()
+	s.Assert().NoError(processor.Commit(s.ctx))
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) TestLedgerInsertsChanges() {
+	processor := NewOrderBookHistoryProcessor(s.mockQ, 65, false)
+	ledgerID := toid.New(65, 0, 0).ToInt64()
+
+	s.offersBatch.On("Add", history.OfferHistory{
+		OfferID:         200,
+		HistoryLedgerID: ledgerID,
+		SellingAsset:    xdr.MustNewNativeAsset(),
+		BuyingAsset:     xdr.MustNewCreditAsset("USD", "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"),
+		Amount:          100,
+		Pricen:          1,
+		Priced:          2,
+		Price:           0.5,
+		Deleted:         true,
+	}).Return(nil).Once()
+	s.offersBatch.On("Len").Return(1)
+
+	poolID := xdr.PoolId{1, 2, 3}
+	assetA := xdr.MustNewNativeAsset()
+	assetB := xdr.MustNewCreditAsset("USD", "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML")
+	s.poolsBatch.On("Add", history.LiquidityPoolReservesHistory{
+		PoolID:          PoolIDToString(poolID),
+		HistoryLedgerID: ledgerID,
+		AssetA:          assetA,
+		AssetB:          assetB,
+		ReserveA:        1000,
+		ReserveB:        2000,
+		Fee:             30,
+	}).Return(nil).Once()
+	s.poolsBatch.On("Len").Return(1)
+
+	s.Assert().NoError(processor.ProcessChange(s.ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeOffer,
+		Pre:  s.offerEntry(100),
+	}))
+	s.Assert().NoError(processor.ProcessChange(s.ctx, ingest.Change{
+		Type: xdr.LedgerEntryTypeLiquidityPool,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeLiquidityPool,
+				LiquidityPool: &xdr.LiquidityPoolEntry{
+					LiquidityPoolId: poolID,
+					Body: xdr.LiquidityPoolEntryBody{
+						Type: xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+						ConstantProduct: &xdr.LiquidityPoolEntryConstantProduct{
+							Params: xdr.LiquidityPoolConstantProductParameters{
+								AssetA: assetA,
+								AssetB: assetB,
+								Fee:    30,
+							},
+							ReserveA: 1000,
+							ReserveB: 2000,
+						},
+					},
+				},
+			},
+		},
+	}))
+
+	s.mockQ.On("GetOrderBookHistoryStartLedger", s.ctx).Return(uint32(10), nil).Once()
+	s.mockQ.On("GetOrderBookHistoryLastLedger", s.ctx).Return(uint32(64), nil).Once()
+	s.offersBatch.On("Exec", s.ctx).Return(nil).Once()
+	s.poolsBatch.On("Exec", s.ctx).Return(nil).Once()
+	s.mockQ.On("UpdateOrderBookHistoryLastLedger", s.ctx, uint32(65)).Return(nil).Once()
+	s.Assert().NoError(processor.Commit(s.ctx))
+}
+
+func (s *OrderBookHistoryProcessorTestSuite) TestLedgerResetsHistoryAfterGap() {
+	processor := NewOrderBookHistoryProcessor(s.mockQ, 65, false)
+
+	s.mockQ.On("GetOrderBookHistoryStartLedger", s.ctx).Return(uint32(10), nil).Once()
+	s.mockQ.On("GetOrderBookHistoryLastLedger", s.ctx).Return(uint32(60), nil).Once()
+	s.mockQ.On("ResetOrderBookHistory", s.ctx, uint32(65)).Return(nil).Once()
+	s.Assert().NoError(processor.Commit(s.ctx))
+}
@@ -19,6 +19,8 @@ type ParticipantsProcessor struct {
 	accountLoader *history.AccountLoader
 	txBatch       history.TransactionParticipantsBatchInsertBuilder
 	opBatch       history.OperationParticipantBatchInsertBuilder
+	muxedTxBatch  history.MuxedTransactionParticipantsBatchInsertBuilder
+	muxedOpBatch  history.MuxedOperationParticipantBatchInsertBuilder
 	network       string
 }
 
@@ -26,6 +28,8 @@ func NewParticipantsProcessor(
 	accountLoader *history.AccountLoader,
 	txBatch history.TransactionParticipantsBatchInsertBuilder,
 	opBatch history.OperationParticipantBatchInsertBuilder,
+	muxedTxBatch history.MuxedTransactionParticipantsBatchInsertBuilder,
+	muxedOpBatch history.MuxedOperationParticipantBatchInsertBuilder,
 	network string,
 
 ) *ParticipantsProcessor {
@@ -33,6 +37,8 @@ func NewParticipantsProcessor(
 		accountLoader: accountLoader,
 		txBatch:       txBatch,
 		opBatch:       opBatch,
+		muxedTxBatch:  muxedTxBatch,
+		muxedOpBatch:  muxedOpBatch,
 		network:       network,
 	}
 }
@@ -126,6 +132,15 @@ func (p *ParticipantsProcessor) addTransactionParticipants(
 		}
 	}
 
+	for _, participant := range muxedParticipantsForTransaction(sequence, transaction) {
+		aid := participant.ToAccountId()
+		if err := p.muxedTxBatch.Add(
+			transactionID, p.accountLoader.GetFuture(aid.Address()), uint64(participant.Med25519.Id),
+		); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -147,6 +162,17 @@ func (p *ParticipantsProcessor) addOperationsParticipants(
 		}
 	}
 
+	for operationID, accounts := range operationsMuxedParticipants(transaction, sequence) {
+		for _, participant := range accounts {
+			aid := participant.ToAccountId()
+			if err := p.muxedOpBatch.Add(
+				operationID, p.accountLoader.GetFuture(aid.Address()), uint64(participant.Med25519.Id),
+			); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -170,6 +196,12 @@ func (p *ParticipantsProcessor) Flush(ctx context.Context, session db.SessionInt
 	if err := p.opBatch.Exec(ctx, session); err != nil {
 		return errors.Wrap(err, "Could not flush operation participants to db")
 	}
+	if err := p.muxedTxBatch.Exec(ctx, session); err != nil {
+		return errors.Wrap(err, "Could not flush muxed transaction participants to db")
+	}
+	if err := p.muxedOpBatch.Exec(ctx, session); err != nil {
+		return errors.Wrap(err, "Could not flush muxed operation participants to db")
+	}
 	return nil
 }
 
@@ -215,3 +247,30 @@ func ParticipantsForTransaction(
 
 	return dedupeParticipants(participants), nil
 }
+
+// muxedParticipantsForTransaction returns the muxed accounts (M...) taking
+// part in the transaction: its source account, its fee account and the muxed
+// participants of its operations.
+func muxedParticipantsForTransaction(
+	sequence uint32,
+	transaction ingest.LedgerTransaction,
+) []xdr.MuxedAccount {
+	participants := []xdr.MuxedAccount{
+		transaction.Envelope.SourceAccount(),
+	}
+	if transaction.Envelope.IsFeeBump() {
+		participants = append(participants, transaction.Envelope.FeeBumpAccount())
+	}
+
+	for opi, op := range transaction.Envelope.Operations() {
+		operation := transactionOperationWrapper{
+			index:          uint32(opi),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: sequence,
+		}
+		participants = append(participants, operation.MuxedParticipants()...)
+	}
+
+	return dedupeMuxedParticipants(participants)
+}
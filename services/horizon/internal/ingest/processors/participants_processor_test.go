@@ -4,6 +4,7 @@ package processors
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -18,12 +19,14 @@ import (
 
 type ParticipantsProcessorTestSuiteLedger struct {
 	suite.Suite
-	ctx                              context.Context
-	processor                        *ParticipantsProcessor
-	mockSession                      *db.MockSession
-	mockBatchInsertBuilder           *history.MockTransactionParticipantsBatchInsertBuilder
-	mockOperationsBatchInsertBuilder *history.MockOperationParticipantBatchInsertBuilder
-	accountLoader                    *history.AccountLoader
+	ctx                                   context.Context
+	processor                             *ParticipantsProcessor
+	mockSession                           *db.MockSession
+	mockBatchInsertBuilder                *history.MockTransactionParticipantsBatchInsertBuilder
+	mockOperationsBatchInsertBuilder      *history.MockOperationParticipantBatchInsertBuilder
+	mockMuxedBatchInsertBuilder           *history.MockMuxedTransactionParticipantsBatchInsertBuilder
+	mockMuxedOperationsBatchInsertBuilder *history.MockMuxedOperationParticipantBatchInsertBuilder
+	accountLoader                         *history.AccountLoader
 
 	lcm             xdr.LedgerCloseMeta
 	firstTx         ingest.LedgerTransaction
@@ -45,6 +48,8 @@ func (s *ParticipantsProcessorTestSuiteLedger) SetupTest() {
 	s.ctx = context.Background()
 	s.mockBatchInsertBuilder = &history.MockTransactionParticipantsBatchInsertBuilder{}
 	s.mockOperationsBatchInsertBuilder = &history.MockOperationParticipantBatchInsertBuilder{}
+	s.mockMuxedBatchInsertBuilder = &history.MockMuxedTransactionParticipantsBatchInsertBuilder{}
+	s.mockMuxedOperationsBatchInsertBuilder = &history.MockMuxedOperationParticipantBatchInsertBuilder{}
 	sequence := uint32(20)
 	s.lcm = xdr.LedgerCloseMeta{
 		V0: &xdr.LedgerCloseMetaV0{
@@ -96,6 +101,8 @@ func (s *ParticipantsProcessorTestSuiteLedger) SetupTest() {
 		s.accountLoader,
 		s.mockBatchInsertBuilder,
 		s.mockOperationsBatchInsertBuilder,
+		s.mockMuxedBatchInsertBuilder,
+		s.mockMuxedOperationsBatchInsertBuilder,
 		networkPassphrase,
 	)
 
@@ -109,6 +116,13 @@ func (s *ParticipantsProcessorTestSuiteLedger) SetupTest() {
 func (s *ParticipantsProcessorTestSuiteLedger) TearDownTest() {
 	s.mockBatchInsertBuilder.AssertExpectations(s.T())
 	s.mockOperationsBatchInsertBuilder.AssertExpectations(s.T())
+	s.mockMuxedBatchInsertBuilder.AssertExpectations(s.T())
+	s.mockMuxedOperationsBatchInsertBuilder.AssertExpectations(s.T())
+}
+
+func (s *ParticipantsProcessorTestSuiteLedger) mockSuccessfulMuxedExecs() {
+	s.mockMuxedBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
+	s.mockMuxedOperationsBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
 }
 
 func (s *ParticipantsProcessorTestSuiteLedger) mockSuccessfulTransactionBatchAdds() {
@@ -145,6 +159,7 @@ func (s *ParticipantsProcessorTestSuiteLedger) mockSuccessfulOperationBatchAdds(
 func (s *ParticipantsProcessorTestSuiteLedger) TestEmptyParticipants() {
 	s.mockBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
 	s.mockOperationsBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
+	s.mockSuccessfulMuxedExecs()
 
 	err := s.processor.Flush(s.ctx, s.mockSession)
 	s.Assert().NoError(err)
@@ -189,6 +204,7 @@ func (s *ParticipantsProcessorTestSuiteLedger) TestFeeBumptransaction() {
 
 	s.mockBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
 	s.mockOperationsBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
+	s.mockSuccessfulMuxedExecs()
 
 	s.Assert().NoError(s.processor.ProcessTransaction(s.lcm, feeBumpTx))
 	s.Assert().NoError(s.processor.Flush(s.ctx, s.mockSession))
@@ -200,6 +216,7 @@ func (s *ParticipantsProcessorTestSuiteLedger) TestIngestParticipantsSucceeds()
 
 	s.mockBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
 	s.mockOperationsBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
+	s.mockSuccessfulMuxedExecs()
 
 	for _, tx := range s.txs {
 		err := s.processor.ProcessTransaction(s.lcm, tx)
@@ -259,3 +276,59 @@ func (s *ParticipantsProcessorTestSuiteLedger) TestOperationBatchAddExecFails()
 	err := s.processor.Flush(s.ctx, s.mockSession)
 	s.Assert().EqualError(err, "Could not flush operation participants to db: transient error")
 }
+
+func (s *ParticipantsProcessorTestSuiteLedger) TestMuxedParticipants() {
+	source := xdr.MuxedAccount{
+		Type: xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+		Med25519: &xdr.MuxedAccountMed25519{
+			Id:      1,
+			Ed25519: xdr.MustAddress(s.addresses[0]).MustEd25519(),
+		},
+	}
+	destination := xdr.MuxedAccount{
+		Type: xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+		Med25519: &xdr.MuxedAccountMed25519{
+			Id:      math.MaxUint64,
+			Ed25519: xdr.MustAddress(s.addresses[1]).MustEd25519(),
+		},
+	}
+	tx := createTransaction(true, 1, 2)
+	tx.Index = 1
+	tx.Envelope.V1.Tx.SourceAccount = source
+	tx.Envelope.Operations()[0].Body = xdr.OperationBody{
+		Type: xdr.OperationTypePayment,
+		PaymentOp: &xdr.PaymentOp{
+			Destination: destination,
+			Asset:       xdr.MustNewNativeAsset(),
+			Amount:      100,
+		},
+	}
+
+	for _, address := range s.addresses[:2] {
+		s.mockBatchInsertBuilder.On(
+			"Add", s.firstTxID, s.addressToFuture[address],
+		).Return(nil).Once()
+		s.mockOperationsBatchInsertBuilder.On(
+			"Add", s.firstTxID+1, s.addressToFuture[address],
+		).Return(nil).Once()
+	}
+	s.mockMuxedBatchInsertBuilder.On(
+		"Add", s.firstTxID, s.addressToFuture[s.addresses[0]], uint64(1),
+	).Return(nil).Once()
+	s.mockMuxedBatchInsertBuilder.On(
+		"Add", s.firstTxID, s.addressToFuture[s.addresses[1]], uint64(math.MaxUint64),
+	).Return(nil).Once()
+	s.mockMuxedOperationsBatchInsertBuilder.On(
+		"Add", s.firstTxID+1, s.addressToFuture[s.addresses[0]], uint64(1),
+	).Return(nil).Once()
+	s.mockMuxedOperationsBatchInsertBuilder.On(
+		"Add", s.firstTxID+1, s.addressToFuture[s.addresses[1]], uint64(math.MaxUint64),
+	).Return(nil).Once()
+
+	s.mockBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
+	s.mockOperationsBatchInsertBuilder.On("Exec", s.ctx, s.mockSession).Return(nil).Once()
+	s.mockSuccessfulMuxedExecs()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(s.lcm, tx))
+	s.Assert().NoError(s.processor.Flush(s.ctx, s.mockSession))
+}
@@ -228,6 +228,11 @@ func (r *Reaper) deleteBatch(ctx context.Context, batchStartSeq, batchEndSeq uin
 	if err != nil {
 		return 0, errors.Wrap(err, "Error in DeleteRangeAll")
 	}
+	orderBookCount, err := r.historyQ.DeleteOrderBookHistoryRange(ctx, batchStart, batchEnd)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error in DeleteOrderBookHistoryRange")
+	}
+	count += orderBookCount
 
 	err = r.historyQ.Commit()
 	if err != nil {
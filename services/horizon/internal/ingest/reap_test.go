@@ -197,6 +197,9 @@ func (t *ReaperTestSuite) TestSucceeds() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(55, 0, 0).ToInt64(), toid.New(61, 0, 0).ToInt64(),
 		).Return(int64(400), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(55, 0, 0).ToInt64(), toid.New(61, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 		t.reapLockQ.On("Rollback").Return(nil).Once(),
@@ -225,6 +228,30 @@ func (t *ReaperTestSuite) TestFails() {
 	t.Assert().EqualError(t.reaper.DeleteUnretainedHistory(t.ctx), "Error in DeleteRangeAll: transient error")
 }
 
+func (t *ReaperTestSuite) TestFailsDeletingOrderBookHistory() {
+	assertMocksInOrder(
+		t.reapLockQ.On("Begin", t.ctx).Return(nil).Once(),
+		t.reapLockQ.On("TryReaperLock", t.ctx).Return(true, nil).Once(),
+		t.historyQ.On("GetLatestHistoryLedger", t.ctx).Return(uint32(90), nil).Once(),
+		t.historyQ.On("ElderLedger", t.ctx, mock.AnythingOfType("*uint32")).
+			Return(nil).Once().Run(
+			func(args mock.Arguments) {
+				ledger := args.Get(1).(*uint32)
+				*ledger = 55
+			}),
+		t.historyQ.On("Begin", t.ctx).Return(nil).Once(),
+		t.historyQ.On("DeleteRangeAll", t.ctx,
+			toid.New(55, 0, 0).ToInt64(), toid.New(61, 0, 0).ToInt64(),
+		).Return(int64(400), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(55, 0, 0).ToInt64(), toid.New(61, 0, 0).ToInt64(),
+		).Return(int64(0), fmt.Errorf("transient error")).Once(),
+		t.historyQ.On("Rollback").Return(nil).Once(),
+		t.reapLockQ.On("Rollback").Return(nil).Once(),
+	)
+	t.Assert().EqualError(t.reaper.DeleteUnretainedHistory(t.ctx), "Error in DeleteOrderBookHistoryRange: transient error")
+}
+
 func (t *ReaperTestSuite) TestPartiallySucceeds() {
 	assertMocksInOrder(
 		t.reapLockQ.On("Begin", t.ctx).Return(nil).Once(),
@@ -241,6 +268,9 @@ func (t *ReaperTestSuite) TestPartiallySucceeds() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(30, 0, 0).ToInt64(), toid.New(41, 0, 0).ToInt64(),
 		).Return(int64(200), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(30, 0, 0).ToInt64(), toid.New(41, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 
@@ -271,6 +301,9 @@ func (t *ReaperTestSuite) TestSucceedsOnMultipleBatches() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(35, 0, 0).ToInt64(), toid.New(46, 0, 0).ToInt64(),
 		).Return(int64(200), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(35, 0, 0).ToInt64(), toid.New(46, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 
@@ -278,6 +311,9 @@ func (t *ReaperTestSuite) TestSucceedsOnMultipleBatches() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(46, 0, 0).ToInt64(), toid.New(57, 0, 0).ToInt64(),
 		).Return(int64(150), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(46, 0, 0).ToInt64(), toid.New(57, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 
@@ -285,6 +321,9 @@ func (t *ReaperTestSuite) TestSucceedsOnMultipleBatches() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(57, 0, 0).ToInt64(), toid.New(61, 0, 0).ToInt64(),
 		).Return(int64(80), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(57, 0, 0).ToInt64(), toid.New(61, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 
@@ -309,6 +348,9 @@ func (t *ReaperTestSuite) TestSkipGap() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(2, 0, 0).ToInt64(), toid.New(13, 0, 0).ToInt64(),
 		).Return(int64(200), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(2, 0, 0).ToInt64(), toid.New(13, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 
@@ -316,6 +358,9 @@ func (t *ReaperTestSuite) TestSkipGap() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(13, 0, 0).ToInt64(), toid.New(24, 0, 0).ToInt64(),
 		).Return(int64(0), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(13, 0, 0).ToInt64(), toid.New(24, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 		t.historyQ.On("GetNextLedgerSequence", t.ctx, uint32(13)).Return(uint32(55), true, nil).Once(),
@@ -324,6 +369,9 @@ func (t *ReaperTestSuite) TestSkipGap() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(55, 0, 0).ToInt64(), toid.New(61, 0, 0).ToInt64(),
 		).Return(int64(20), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(55, 0, 0).ToInt64(), toid.New(61, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 
@@ -348,6 +396,9 @@ func (t *ReaperTestSuite) TestSkipGapTerminatesEarly() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(2, 0, 0).ToInt64(), toid.New(13, 0, 0).ToInt64(),
 		).Return(int64(200), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(2, 0, 0).ToInt64(), toid.New(13, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 
@@ -355,6 +406,9 @@ func (t *ReaperTestSuite) TestSkipGapTerminatesEarly() {
 		t.historyQ.On("DeleteRangeAll", t.ctx,
 			toid.New(13, 0, 0).ToInt64(), toid.New(24, 0, 0).ToInt64(),
 		).Return(int64(0), nil).Once(),
+		t.historyQ.On("DeleteOrderBookHistoryRange", t.ctx,
+			toid.New(13, 0, 0).ToInt64(), toid.New(24, 0, 0).ToInt64(),
+		).Return(int64(0), nil).Once(),
 		t.historyQ.On("Commit").Return(nil).Once(),
 		t.historyQ.On("Rollback").Return(nil).Once(),
 		t.historyQ.On("GetNextLedgerSequence", t.ctx, uint32(13)).Return(uint32(65), true, nil).Once(),
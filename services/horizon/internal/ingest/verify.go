@@ -30,7 +30,7 @@ const assetStatsBatchSize = 500
 // check them.
 // There is a test that checks it, to fix it: update the actual `verifyState`
 // method instead of just updating this value!
-const stateVerifierExpectedIngestionVersion = 18
+const stateVerifierExpectedIngestionVersion = 19
 
 // verifyState is called as a go routine from pipeline post hook every 64
 // ledgers. It checks if the state is correct. If another go routine is already
@@ -564,6 +564,17 @@ func checkContractBalances(
 				)
 			}
 
+			if !bytes.Equal(row.HolderID, expected.HolderID) {
+				return ingest.NewStateError(
+					fmt.Errorf(
+						"contract balance %v has holder %v in HAS but is %v in db",
+						key,
+						expected.HolderID,
+						row.HolderID,
+					),
+				)
+			}
+
 			if row.ExpirationLedger != expected.ExpirationLedger {
 				return ingest.NewStateError(
 					fmt.Errorf(
@@ -9,11 +9,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/stellar/go/exp/orderbook"
+	"github.com/stellar/go/services/horizon/internal/apikeys"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/ingest"
 	"github.com/stellar/go/services/horizon/internal/paths"
 	"github.com/stellar/go/services/horizon/internal/simplepath"
 	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/services/horizon/internal/webhooks"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/log"
 )
@@ -103,6 +105,9 @@ func initIngester(app *App) {
 		EnableExtendedLogLedgerStats:         app.config.IngestEnableExtendedLogLedgerStats,
 		RoundingSlippageFilter:               app.config.RoundingSlippageFilter,
 		SkipTxmeta:                           app.config.SkipTxmeta,
+		IngestContractEvents:                 app.config.IngestContractEvents,
+		IngestBalanceHistory:                 app.config.IngestBalanceHistory,
+		IngestOrderBookHistory:               app.config.IngestOrderBookHistory,
 		ReapConfig: ingest.ReapConfig{
 			Frequency:      app.config.ReapFrequency,
 			RetentionCount: uint32(app.config.HistoryRetentionCount),
@@ -125,13 +130,46 @@ func initPathFinder(app *App) {
 		orderBookGraph,
 	)
 
+	app.orderBookGraph = orderBookGraph
+
 	var finder paths.Finder = simplepath.NewInMemoryFinder(orderBookGraph, !app.config.DisablePoolPathFinding)
 	if app.config.MaxPathFindingRequests != 0 {
 		finder = paths.NewRateLimitedFinder(finder, app.config.MaxPathFindingRequests)
 	}
+	if app.apiKeys != nil {
+		finder = apikeys.NewPathFinder(finder)
+	}
 	app.paths = finder
 }
 
+func initWebhooks(app *App) {
+	if !app.config.EnableWebhooks {
+		return
+	}
+	// the dispatcher updates the subscription cursors so it must use the
+	// primary database when a read replica is configured
+	session := app.historyQ.SessionInterface
+	if app.primaryHistoryQ != nil {
+		session = app.primaryHistoryQ.SessionInterface
+	}
+	app.webhooks = webhooks.NewDispatcher(webhooks.Config{}, session)
+	app.webhooks.RegisterMetrics(app.prometheusRegistry)
+}
+
+func initAPIKeys(app *App) {
+	if !app.config.EnableAPIKeys {
+		return
+	}
+	// keys are created on the primary database when a read replica is
+	// configured, reading them from it makes them usable right away
+	session := app.historyQ.SessionInterface
+	if app.primaryHistoryQ != nil {
+		session = app.primaryHistoryQ.SessionInterface
+	}
+	app.apiKeys = apikeys.NewRegistry(apikeys.Config{}, session)
+	app.apiKeys.RegisterMetrics(app.prometheusRegistry)
+}
+
 // initSentry initialized the default sentry client with the configured DSN
 func initSentry(app *App) {
 	if app.config.SentryDSN == "" {
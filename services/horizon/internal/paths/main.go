@@ -25,6 +25,10 @@ type Path struct {
 	SourceAmount      xdr.Int64
 	Destination       string
 	DestinationAmount xdr.Int64
+	// Routes is only populated by split path finding. It contains the payment
+	// paths across which the payment is divided, the amounts of the routes add
+	// up to the amounts of the Path.
+	Routes []Path
 }
 
 // Finder finds paths.
@@ -44,4 +48,18 @@ type Finder interface {
 		destinationAssets []xdr.Asset,
 		maxLength uint,
 	) ([]Path, uint32, error)
+	// FindSplitPaths works like Find but each of the returned payments is
+	// split across several payment paths (listed in Routes) to reduce the
+	// slippage of delivering the destination amount.
+	FindSplitPaths(ctx context.Context, q Query, maxLength uint) ([]Path, uint32, error)
+	// FindFixedSplitPaths works like FindFixedPaths but each of the returned
+	// payments is split across several payment paths (listed in Routes) to
+	// reduce the slippage of spending `amountToSpend`.
+	FindFixedSplitPaths(
+		ctx context.Context,
+		sourceAsset xdr.Asset,
+		amountToSpend xdr.Int64,
+		destinationAssets []xdr.Asset,
+		maxLength uint,
+	) ([]Path, uint32, error)
 }
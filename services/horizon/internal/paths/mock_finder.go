@@ -31,3 +31,21 @@ func (m *MockFinder) FindFixedPaths(
 
 	return args.Get(0).([]Path), args.Get(1).(uint32), args.Error(2)
 }
+
+func (m *MockFinder) FindSplitPaths(ctx context.Context, q Query, maxLength uint) ([]Path, uint32, error) {
+	args := m.Called(ctx, q, maxLength)
+
+	return args.Get(0).([]Path), args.Get(1).(uint32), args.Error(2)
+}
+
+func (m *MockFinder) FindFixedSplitPaths(
+	ctx context.Context,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAssets []xdr.Asset,
+	maxLength uint,
+) ([]Path, uint32, error) {
+	args := m.Called(ctx, sourceAsset, amountToSpend, destinationAssets, maxLength)
+
+	return args.Get(0).([]Path), args.Get(1).(uint32), args.Error(2)
+}
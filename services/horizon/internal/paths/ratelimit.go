@@ -57,3 +57,27 @@ func (f *RateLimitedFinder) FindFixedPaths(
 	}
 	return f.finder.FindFixedPaths(ctx, sourceAsset, amountToSpend, destinationAssets, maxLength)
 }
+
+// FindSplitPaths implements the Finder interface and returns ErrRateLimitExceeded if the
+// RateLimitedFinder is unable to complete the request due to rate limits.
+func (f *RateLimitedFinder) FindSplitPaths(ctx context.Context, q Query, maxLength uint) ([]Path, uint32, error) {
+	if !f.limiter.Allow() {
+		return nil, 0, ErrRateLimitExceeded
+	}
+	return f.finder.FindSplitPaths(ctx, q, maxLength)
+}
+
+// FindFixedSplitPaths implements the Finder interface and returns ErrRateLimitExceeded if the
+// RateLimitedFinder is unable to complete the request due to rate limits.
+func (f *RateLimitedFinder) FindFixedSplitPaths(
+	ctx context.Context,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAssets []xdr.Asset,
+	maxLength uint,
+) ([]Path, uint32, error) {
+	if !f.limiter.Allow() {
+		return nil, 0, ErrRateLimitExceeded
+	}
+	return f.finder.FindFixedSplitPaths(ctx, sourceAsset, amountToSpend, destinationAssets, maxLength)
+}
@@ -30,6 +30,20 @@ func TestRateLimitedFinder(t *testing.T) {
 				)
 				errorChan <- err
 			}
+			findSplitPaths := func(finder Finder) {
+				_, _, err := finder.FindSplitPaths(context.Background(), Query{}, 1)
+				errorChan <- err
+			}
+			findFixedSplitPaths := func(finder Finder) {
+				_, _, err := finder.FindFixedSplitPaths(
+					context.Background(),
+					xdr.MustNewNativeAsset(),
+					10,
+					nil,
+					0,
+				)
+				errorChan <- err
+			}
 
 			wg := &sync.WaitGroup{}
 			mockFinder := &MockFinder{}
@@ -45,8 +59,20 @@ func TestRateLimitedFinder(t *testing.T) {
 					wg.Done()
 					wg.Wait()
 				})
+			mockFinder.On("FindSplitPaths", mock.Anything, mock.Anything, mock.Anything).
+				Return([]Path{}, uint32(0), nil).Maybe().Times(limit).
+				Run(func(args mock.Arguments) {
+					wg.Done()
+					wg.Wait()
+				})
+			mockFinder.On("FindFixedSplitPaths", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return([]Path{}, uint32(0), nil).Maybe().Times(limit).
+				Run(func(args mock.Arguments) {
+					wg.Done()
+					wg.Wait()
+				})
 
-			for _, f := range []func(Finder){find, findFixedPaths} {
+			for _, f := range []func(Finder){find, findFixedPaths, findSplitPaths, findFixedSplitPaths} {
 				wg.Add(totalCalls)
 				rateLimitedFinder := NewRateLimitedFinder(mockFinder, uint(limit))
 				assert.Equal(t, limit, rateLimitedFinder.Limit())
@@ -40,6 +40,48 @@ var (
 			"headers.",
 	}
 
+	// APIKeyRateLimitExceeded is a well-known problem type.  Use it as a
+	// shortcut in your actions.
+	APIKeyRateLimitExceeded = problem.P{
+		Type:   "rate_limit_exceeded",
+		Title:  "Rate Limit Exceeded",
+		Status: http.StatusTooManyRequests,
+		Detail: "The rate limit of the API key of the request is over its alloted " +
+			"limit.  The allowed limit and requests left per time period are " +
+			"communicated to clients via the http response headers 'X-RateLimit-*' " +
+			"headers.",
+	}
+
+	// StreamLimitExceeded is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	StreamLimitExceeded = problem.P{
+		Type:   "stream_limit_exceeded",
+		Title:  "Stream Limit Exceeded",
+		Status: http.StatusTooManyRequests,
+		Detail: "All the concurrent streams allowed for the API key of the " +
+			"request are open. Close one of them before opening a new stream.",
+	}
+
+	// PathFindingRateLimitExceeded is a well-known problem type.  Use it as a
+	// shortcut in your actions.
+	PathFindingRateLimitExceeded = problem.P{
+		Type:   "path_finding_rate_limit_exceeded",
+		Title:  "Path Finding Rate Limit Exceeded",
+		Status: http.StatusTooManyRequests,
+		Detail: "The API key of the request has exceeded its allowed number of " +
+			"path finding requests per second. Please try again later.",
+	}
+
+	// InvalidAPIKey is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	InvalidAPIKey = problem.P{
+		Type:   "invalid_api_key",
+		Title:  "Invalid API Key",
+		Status: http.StatusUnauthorized,
+		Detail: "The API key sent in the 'X-Api-Key' header does not exist. " +
+			"Omit the header to make anonymous requests.",
+	}
+
 	// NotImplemented is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	NotImplemented = problem.P{
@@ -25,6 +25,10 @@ func TestCommonProblems(t *testing.T) {
 	}{
 		{"NotFound", problem.NotFound, 404},
 		{"RateLimitExceeded", RateLimitExceeded, 429},
+		{"APIKeyRateLimitExceeded", APIKeyRateLimitExceeded, 429},
+		{"StreamLimitExceeded", StreamLimitExceeded, 429},
+		{"PathFindingRateLimitExceeded", PathFindingRateLimitExceeded, 429},
+		{"InvalidAPIKey", InvalidAPIKey, 401},
 		{"ClientDisconneted", ClientDisconnected, 499},
 	}
 
@@ -17,9 +17,27 @@ var (
 	ErrRateLimited = errors.New("Rate limit exceeded")
 )
 
+// EventSink receives the events of a stream in place of the response writer.
+// It allows transports other than Server Sent Events (e.g. WebSockets) to
+// reuse the streaming handlers.
+type EventSink interface {
+	Send(e Event)
+}
+
+type eventSinkKey struct{}
+
+// WithEventSink returns a context which makes the streams served with it
+// deliver their events to sink instead of writing them to the response.
+// Errors which occur before the first event are still rendered as a regular
+// HTTP problem response.
+func WithEventSink(ctx context.Context, sink EventSink) context.Context {
+	return context.WithValue(ctx, eventSinkKey{}, sink)
+}
+
 type Stream struct {
 	ctx         context.Context
 	w           http.ResponseWriter
+	sink        EventSink
 	done        bool
 	eventsSent  int
 	limit       int
@@ -28,9 +46,11 @@ type Stream struct {
 
 // NewStream creates a new stream against the provided response writer.
 func NewStream(ctx context.Context, w http.ResponseWriter) *Stream {
+	sink, _ := ctx.Value(eventSinkKey{}).(EventSink)
 	return &Stream{
-		ctx: ctx,
-		w:   w,
+		ctx:  ctx,
+		w:    w,
+		sink: sink,
 	}
 }
 
@@ -40,6 +60,12 @@ func NewStream(ctx context.Context, w http.ResponseWriter) *Stream {
 func (s *Stream) Init() {
 	if !s.initialized {
 		s.initialized = true
+		if s.sink != nil {
+			// Nothing is written to the response but its status is set so
+			// that the request is not considered unanswered.
+			s.w.WriteHeader(http.StatusOK)
+			return
+		}
 		ok := WritePreamble(s.ctx, s.w)
 		if !ok {
 			s.done = true
@@ -49,7 +75,7 @@ func (s *Stream) Init() {
 
 func (s *Stream) Send(e Event) {
 	s.Init()
-	WriteEvent(s.ctx, s.w, e)
+	s.write(e)
 	s.eventsSent++
 }
 
@@ -59,7 +85,9 @@ func (s *Stream) SetLimit(limit int) {
 
 func (s *Stream) Done() {
 	s.Init()
-	WriteEvent(s.ctx, s.w, goodbyeEvent)
+	if s.sink == nil {
+		WriteEvent(s.ctx, s.w, goodbyeEvent)
+	}
 	s.done = true
 }
 
@@ -79,6 +107,14 @@ func (s *Stream) Err(err error) {
 	}
 
 	s.Init()
-	WriteEvent(s.ctx, s.w, Event{Error: err})
+	s.write(Event{Error: err})
 	s.done = true
 }
+
+func (s *Stream) write(e Event) {
+	if s.sink != nil {
+		s.sink.Send(e)
+		return
+	}
+	WriteEvent(s.ctx, s.w, e)
+}
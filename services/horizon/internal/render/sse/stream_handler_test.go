@@ -42,3 +42,28 @@ func TestSendByeByeOnContextDone(t *testing.T) {
 		t.Fatalf("expected '%v' but got '%v'", expected, got)
 	}
 }
+
+func TestEventSinkDoesNotWriteToResponse(t *testing.T) {
+	ledgerSource := ledger.NewTestingSource(1)
+	handler := StreamHandler{LedgerSourceFactory: &testingFactory{ledgerSource}}
+
+	r, err := http.NewRequest("GET", "http://localhost", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	sink := &recordingSink{}
+	r = r.WithContext(WithEventSink(context.Background(), sink))
+
+	w := httptest.NewRecorder()
+
+	handler.ServeStream(w, r, 2, func() ([]Event, error) {
+		return []Event{{ID: "1", Data: "a"}, {ID: "2", Data: "b"}, {ID: "3", Data: "c"}}, nil
+	})
+
+	if got := w.Body.String(); got != "" {
+		t.Fatalf("expected empty body but got '%v'", got)
+	}
+	if len(sink.events) != 2 || sink.events[0].ID != "1" || sink.events[1].ID != "2" {
+		t.Fatalf("unexpected events %v", sink.events)
+	}
+}
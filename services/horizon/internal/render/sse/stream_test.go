@@ -143,6 +143,44 @@ func (suite *StreamTestSuite) TestStream_SentCount() {
 	assert.Equal(suite.T(), 5, suite.stream.SentCount())
 }
 
+type recordingSink struct {
+	events []Event
+}
+
+func (r *recordingSink) Send(e Event) {
+	r.events = append(r.events, e)
+}
+
+// Tests that a stream with an event sink delivers its events to the sink
+// without writing them to the response.
+func (suite *StreamTestSuite) TestStream_EventSink() {
+	problem.RegisterError(sql.ErrNoRows, problem.NotFound)
+	defer problem.UnRegisterErrors()
+
+	sink := &recordingSink{}
+	suite.stream = NewStream(WithEventSink(suite.ctx, sink), suite.w)
+	suite.stream.Init()
+	suite.stream.Send(Event{ID: "1", Data: "test message"})
+	suite.stream.Err(sql.ErrNoRows)
+
+	assert.Empty(suite.T(), suite.w.Body.String())
+	assert.Empty(suite.T(), suite.w.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), 200, suite.w.Code)
+	assert.Equal(suite.T(), []Event{
+		{ID: "1", Data: "test message"},
+		{Error: problem.NotFound},
+	}, sink.events)
+	assert.True(suite.T(), suite.stream.IsDone())
+
+	// Errors before the first event are rendered as regular problems.
+	suite.w = httptest.NewRecorder()
+	sink = &recordingSink{}
+	suite.stream = NewStream(WithEventSink(suite.ctx, sink), suite.w)
+	suite.stream.Err(sql.ErrNoRows)
+	assert.Equal(suite.T(), 404, suite.w.Code)
+	assert.Empty(suite.T(), sink.events)
+}
+
 // Runs the test suite.
 func TestStreamTestSuite(t *testing.T) {
 	suite.Run(t, new(StreamTestSuite))
@@ -0,0 +1,33 @@
+package resourceadapter
+
+import (
+	"context"
+
+	"github.com/stellar/go/amount"
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/base"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/render/hal"
+)
+
+// PopulateAccountBalanceHistory fills out the details of an account balance
+// using a row from the history_account_balances table.
+func PopulateAccountBalanceHistory(
+	ctx context.Context,
+	dest *protocol.AccountBalanceHistory,
+	row history.AccountBalanceHistory,
+) {
+	dest.PT = row.PagingToken()
+	dest.Ledger = row.LedgerSequence()
+	dest.LedgerCloseTime = row.LedgerCloseTime
+	dest.Balance = amount.StringFromInt64(row.Balance)
+	dest.Asset = base.Asset{
+		Type:   row.AssetType,
+		Code:   row.AssetCode,
+		Issuer: row.AssetIssuer,
+	}
+
+	lb := hal.LinkBuilder{Base: horizonContext.BaseURL(ctx)}
+	dest.Links.Ledger = lb.Linkf("/ledgers/%d", dest.Ledger)
+}
@@ -0,0 +1,40 @@
+package resourceadapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/test"
+	"github.com/stellar/go/toid"
+)
+
+func TestPopulateAccountBalanceHistory(t *testing.T) {
+	tt := assert.New(t)
+	ctx, _ := test.ContextWithLogBuffer()
+
+	row := history.AccountBalanceHistory{
+		HistoryLedgerID: toid.New(10, 0, 0).ToInt64(),
+		LedgerCloseTime: time.Unix(1000, 0).UTC(),
+		HistoryAssetID:  3,
+		Balance:         1234567,
+		AssetType:       "credit_alphanum4",
+		AssetCode:       "USD",
+		AssetIssuer:     "GANFZDRBCNTUXIODCJEYMACPMCSZEVE4WZGZ3CZDZ3P2SXK4KH75IK6Y",
+	}
+
+	var dest AccountBalanceHistory
+	PopulateAccountBalanceHistory(ctx, &dest, row)
+
+	tt.Equal(row.PagingToken(), dest.PagingToken())
+	tt.Equal(int32(10), dest.Ledger)
+	tt.Equal(row.LedgerCloseTime, dest.LedgerCloseTime)
+	tt.Equal("0.1234567", dest.Balance)
+	tt.Equal("USD", dest.Code)
+	tt.Equal(row.AssetIssuer, dest.Issuer)
+	tt.Equal("credit_alphanum4", dest.Type)
+	tt.Equal("/ledgers/10", dest.Links.Ledger.Href)
+}
@@ -0,0 +1,66 @@
+package resourceadapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stellar/go/amount"
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/base"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/xdr"
+)
+
+// PopulateContract fills out the resource's fields. assetStat is the asset
+// stat of the asset issued by the contract or nil if the contract is not a
+// stellar asset contract.
+func PopulateContract(
+	ctx context.Context,
+	dest *protocol.Contract,
+	contractID string,
+	assetStat *history.ExpAssetStat,
+) error {
+	dest.ID = contractID
+	if assetStat != nil {
+		dest.Asset = &base.Asset{
+			Type:   xdr.AssetTypeToString[assetStat.AssetType],
+			Code:   assetStat.AssetCode,
+			Issuer: assetStat.AssetIssuer,
+		}
+	}
+
+	lb := hal.LinkBuilder{Base: horizonContext.BaseURL(ctx)}
+	self := fmt.Sprintf("/contracts/%s", dest.ID)
+	dest.Links.Self = lb.Link(self)
+	dest.Links.Balances = lb.PagedLink(self, "balances")
+	return nil
+}
+
+// PopulateContractBalance fills out the resource's fields
+func PopulateContractBalance(
+	ctx context.Context,
+	dest *protocol.ContractBalance,
+	holding history.ContractAssetHolding,
+) error {
+	var err error
+	dest.PT = holding.PagingToken()
+	dest.AssetContractID, err = strkey.Encode(strkey.VersionByteContract, holding.ContractID)
+	if err != nil {
+		return errors.Wrap(err, "encoding asset contract id")
+	}
+	dest.Balance, err = amount.IntStringToAmount(holding.Amount)
+	if err != nil {
+		return errors.Wrap(err, "converting balance")
+	}
+	dest.LiveUntilLedger = holding.ExpirationLedger
+	dest.Asset = base.Asset{
+		Type:   xdr.AssetTypeToString[holding.AssetType],
+		Code:   holding.AssetCode,
+		Issuer: holding.AssetIssuer,
+	}
+	return nil
+}
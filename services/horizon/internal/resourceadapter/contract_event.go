@@ -0,0 +1,66 @@
+package resourceadapter
+
+import (
+	"context"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/xdr"
+)
+
+// ContractEventTypeNames maps contract event types to the names used in the
+// API.
+var ContractEventTypeNames = map[xdr.ContractEventType]string{
+	xdr.ContractEventTypeSystem:     "system",
+	xdr.ContractEventTypeContract:   "contract",
+	xdr.ContractEventTypeDiagnostic: "diagnostic",
+}
+
+// PopulateContractEvent fills out the details of a contract event using a row
+// from the history_contract_events table.
+func PopulateContractEvent(
+	ctx context.Context,
+	dest *protocol.ContractEvent,
+	row history.ContractEvent,
+) error {
+	var event xdr.ContractEvent
+	if err := xdr.SafeUnmarshalBase64(row.EventXDR, &event); err != nil {
+		return errors.Wrap(err, "could not decode contract event")
+	}
+	body, ok := event.Body.GetV0()
+	if !ok {
+		return errors.Errorf("unsupported event body version %d", event.Body.V)
+	}
+
+	dest.ID = row.ID()
+	dest.PT = row.PagingToken()
+	dest.Type = ContractEventTypeNames[row.Type]
+	dest.Ledger = row.LedgerSequence()
+	dest.LedgerCloseTime = row.LedgerCloseTime
+	dest.TransactionHash = row.TransactionHash
+	if row.ContractID.Valid {
+		dest.ContractID = row.ContractID.String
+	}
+
+	dest.Topics = make([]string, 0, len(body.Topics))
+	for _, topic := range body.Topics {
+		encoded, err := xdr.MarshalBase64(topic)
+		if err != nil {
+			return errors.Wrap(err, "could not encode topic")
+		}
+		dest.Topics = append(dest.Topics, encoded)
+	}
+	value, err := xdr.MarshalBase64(body.Data)
+	if err != nil {
+		return errors.Wrap(err, "could not encode value")
+	}
+	dest.Value = value
+
+	lb := hal.LinkBuilder{Base: horizonContext.BaseURL(ctx)}
+	dest.Links.Transaction = lb.Link("/transactions", row.TransactionHash)
+	dest.Links.Operation = lb.Linkf("/operations/%d", row.HistoryOperationID)
+	return nil
+}
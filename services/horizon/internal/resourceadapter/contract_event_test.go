@@ -0,0 +1,69 @@
+package resourceadapter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/test"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestPopulateContractEvent(t *testing.T) {
+	tt := assert.New(t)
+	ctx, _ := test.ContextWithLogBuffer()
+
+	symbol := xdr.ScSymbol("transfer")
+	amount := xdr.Uint32(10)
+	event := xdr.ContractEvent{
+		ContractId: &xdr.Hash{1},
+		Type:       xdr.ContractEventTypeContract,
+		Body: xdr.ContractEventBody{
+			V: 0,
+			V0: &xdr.ContractEventV0{
+				Topics: []xdr.ScVal{
+					{Type: xdr.ScValTypeScvSymbol, Sym: &symbol},
+					{Type: xdr.ScValTypeScvU32, U32: &amount},
+				},
+				Data: xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &amount},
+			},
+		},
+	}
+	eventXDR, err := xdr.MarshalBase64(event)
+	tt.NoError(err)
+
+	operationID := toid.New(20, 2, 1).ToInt64()
+	row := history.ContractEvent{
+		HistoryOperationID: operationID,
+		Order:              3,
+		TransactionHash:    "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d",
+		LedgerCloseTime:    time.Unix(1000, 0).UTC(),
+		ContractID:         null.StringFrom("CA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQGAXE"),
+		Type:               xdr.ContractEventTypeContract,
+		EventXDR:           eventXDR,
+	}
+
+	var resource ContractEvent
+	tt.NoError(PopulateContractEvent(ctx, &resource, row))
+
+	tt.Equal(row.ID(), resource.ID)
+	tt.Equal(fmt.Sprintf("%d-3", operationID), resource.PagingToken())
+	tt.Equal("contract", resource.Type)
+	tt.Equal(int32(20), resource.Ledger)
+	tt.Equal(row.LedgerCloseTime, resource.LedgerCloseTime)
+	tt.Equal(row.TransactionHash, resource.TransactionHash)
+	tt.Equal(row.ContractID.String, resource.ContractID)
+	tt.Equal([]string{"AAAADwAAAAh0cmFuc2Zlcg==", "AAAAAwAAAAo="}, resource.Topics)
+	tt.Equal("AAAAAwAAAAo=", resource.Value)
+	tt.Equal("/transactions/"+row.TransactionHash, resource.Links.Transaction.Href)
+	tt.Equal(fmt.Sprintf("/operations/%d", operationID), resource.Links.Operation.Href)
+
+	row.EventXDR = "foobar"
+	tt.Error(PopulateContractEvent(ctx, &resource, row))
+}
@@ -0,0 +1,61 @@
+package resourceadapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/test"
+	"github.com/stellar/go/xdr"
+)
+
+func TestPopulateContract(t *testing.T) {
+	tt := assert.New(t)
+	ctx, _ := test.ContextWithLogBuffer()
+	contractID := strkey.MustEncode(strkey.VersionByteContract, make([]byte, 32))
+
+	var resource Contract
+	tt.NoError(PopulateContract(ctx, &resource, contractID, nil))
+	tt.Equal(contractID, resource.ID)
+	tt.Nil(resource.Asset)
+	tt.Equal("/contracts/"+contractID, resource.Links.Self.Href)
+	tt.Equal("/contracts/"+contractID+"/balances{?cursor,limit,order}", resource.Links.Balances.Href)
+
+	resource = Contract{}
+	tt.NoError(PopulateContract(ctx, &resource, contractID, &history.ExpAssetStat{
+		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum12,
+		AssetCode:   "EURT",
+		AssetIssuer: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+	}))
+	tt.Equal("credit_alphanum12", resource.Asset.Type)
+	tt.Equal("EURT", resource.Asset.Code)
+	tt.Equal("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", resource.Asset.Issuer)
+}
+
+func TestPopulateContractBalance(t *testing.T) {
+	tt := assert.New(t)
+	ctx, _ := test.ContextWithLogBuffer()
+	assetContractID := xdr.Hash{0xab}
+
+	var resource ContractBalance
+	tt.NoError(PopulateContractBalance(ctx, &resource, history.ContractAssetHolding{
+		ContractAssetBalance: history.ContractAssetBalance{
+			ContractID:       assetContractID[:],
+			Amount:           "170141183460469231731687303715884105727",
+			ExpirationLedger: 1234,
+		},
+		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+		AssetCode:   "USD",
+		AssetIssuer: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+	}))
+
+	tt.Equal("ab00000000000000000000000000000000000000000000000000000000000000", resource.PagingToken())
+	tt.Equal(strkey.MustEncode(strkey.VersionByteContract, assetContractID[:]), resource.AssetContractID)
+	tt.Equal("17014118346046923173168730371588.4105727", resource.Balance)
+	tt.Equal(uint32(1234), resource.LiveUntilLedger)
+	tt.Equal("credit_alphanum4", resource.Type)
+	tt.Equal("USD", resource.Code)
+}
@@ -3,6 +3,7 @@ package resourceadapter
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/stellar/go/amount"
@@ -59,5 +60,18 @@ func PopulatePath(ctx context.Context, dest *horizon.Path, p paths.Path) (err er
 			return
 		}
 	}
+
+	if len(p.Routes) == 0 {
+		return
+	}
+	if p.SourceAmount > 0 {
+		dest.Price = big.NewRat(int64(p.DestinationAmount), int64(p.SourceAmount)).FloatString(7)
+	}
+	dest.Routes = make([]horizon.Path, len(p.Routes))
+	for i, route := range p.Routes {
+		if err = PopulatePath(ctx, &dest.Routes[i], route); err != nil {
+			return
+		}
+	}
 	return
 }
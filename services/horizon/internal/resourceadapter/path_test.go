@@ -48,3 +48,55 @@ func TestPopulatePath(t *testing.T) {
 		},
 	}, dest)
 }
+
+func TestPopulateSplitPath(t *testing.T) {
+	native := xdr.MustNewNativeAsset()
+	usdc := xdr.MustNewCreditAsset("USDC", "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML")
+	bingo := xdr.MustNewCreditAsset("BINGO", "GBZ35ZJRIKJGYH5PBKLKOZ5L6EXCNTO7BKIL7DAVVDFQ2ODJEEHHJXIM")
+	routes := []paths.Path{
+		{
+			Path:              []string{bingo.String()},
+			Source:            native.String(),
+			SourceAmount:      300,
+			Destination:       usdc.String(),
+			DestinationAmount: 150,
+		},
+		{
+			Path:              []string{},
+			Source:            native.String(),
+			SourceAmount:      100,
+			Destination:       usdc.String(),
+			DestinationAmount: 50,
+		},
+	}
+	p := paths.Path{
+		Path:              routes[0].Path,
+		Source:            native.String(),
+		SourceAmount:      400,
+		Destination:       usdc.String(),
+		DestinationAmount: 200,
+		Routes:            routes,
+	}
+
+	var dest horizon.Path
+	assert.NoError(t, PopulatePath(context.Background(), &dest, p))
+
+	assert.Equal(t, "0.0000400", dest.SourceAmount)
+	assert.Equal(t, "0.0000200", dest.DestinationAmount)
+	assert.Equal(t, "0.5000000", dest.Price)
+	assert.Equal(t, []horizon.Asset{
+		{
+			Type:   "credit_alphanum12",
+			Code:   "BINGO",
+			Issuer: "GBZ35ZJRIKJGYH5PBKLKOZ5L6EXCNTO7BKIL7DAVVDFQ2ODJEEHHJXIM",
+		},
+	}, dest.Path)
+	assert.Len(t, dest.Routes, 2)
+	assert.Equal(t, "0.0000300", dest.Routes[0].SourceAmount)
+	assert.Equal(t, "0.0000150", dest.Routes[0].DestinationAmount)
+	assert.Equal(t, dest.Path, dest.Routes[0].Path)
+	assert.Empty(t, dest.Routes[0].Price)
+	assert.Equal(t, "0.0000100", dest.Routes[1].SourceAmount)
+	assert.Equal(t, "0.0000050", dest.Routes[1].DestinationAmount)
+	assert.Empty(t, dest.Routes[1].Path)
+}
@@ -11,6 +11,9 @@ import (
 
 const (
 	maxAssetsPerPath = 5
+	// maxRoutesPerSplitPath is the maximum number of payment paths a split
+	// payment is divided into
+	maxRoutesPerSplitPath = 4
 	// MaxInMemoryPathLength is the maximum path length which can be queried by the InMemoryFinder
 	MaxInMemoryPathLength = 5
 )
@@ -117,3 +120,97 @@ func (finder InMemoryFinder) FindFixedPaths(
 	}
 	return results, lastLedger, err
 }
+
+// FindSplitPaths implements the path payments finder interface. Each of the
+// returned payments delivers the destination amount of the query by
+// splitting it across up to maxRoutesPerSplitPath payment paths.
+func (finder InMemoryFinder) FindSplitPaths(ctx context.Context, q paths.Query, maxLength uint) ([]paths.Path, uint32, error) {
+	if finder.graph.IsEmpty() {
+		return nil, 0, ErrEmptyInMemoryOrderBook
+	}
+
+	if maxLength == 0 {
+		maxLength = MaxInMemoryPathLength
+	}
+	if maxLength > MaxInMemoryPathLength {
+		return nil, 0, errors.New("invalid value of maxLength")
+	}
+
+	splitPaths, lastLedger, err := finder.graph.FindSplitPaths(
+		ctx,
+		int(maxLength),
+		q.DestinationAsset,
+		q.DestinationAmount,
+		q.SourceAccount,
+		q.SourceAssets,
+		q.SourceAssetBalances,
+		q.ValidateSourceBalance,
+		maxRoutesPerSplitPath,
+		finder.includePools,
+	)
+	return splitPathsToPaths(splitPaths), lastLedger, err
+}
+
+// FindFixedSplitPaths returns a list of payments where the source and
+// destination assets are fixed. Each of the returned payments spends
+// `amountToSpend` of `sourceAsset` by splitting it across up to
+// maxRoutesPerSplitPath payment paths.
+func (finder InMemoryFinder) FindFixedSplitPaths(
+	ctx context.Context,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAssets []xdr.Asset,
+	maxLength uint,
+) ([]paths.Path, uint32, error) {
+	if finder.graph.IsEmpty() {
+		return nil, 0, ErrEmptyInMemoryOrderBook
+	}
+
+	if maxLength == 0 {
+		maxLength = MaxInMemoryPathLength
+	}
+	if maxLength > MaxInMemoryPathLength {
+		return nil, 0, errors.New("invalid value of maxLength")
+	}
+
+	splitPaths, lastLedger, err := finder.graph.FindFixedSplitPaths(
+		ctx,
+		int(maxLength),
+		sourceAsset,
+		amountToSpend,
+		destinationAssets,
+		maxRoutesPerSplitPath,
+		finder.includePools,
+	)
+	return splitPathsToPaths(splitPaths), lastLedger, err
+}
+
+// splitPathsToPaths converts split payments to paths.Path instances. The
+// path of each result is the path of the route carrying the largest share of
+// the payment.
+func splitPathsToPaths(splitPaths []orderbook.SplitPath) []paths.Path {
+	results := make([]paths.Path, len(splitPaths))
+	for i, split := range splitPaths {
+		routes := make([]paths.Path, len(split.Routes))
+		for j, route := range split.Routes {
+			routes[j] = paths.Path{
+				Path:              route.InteriorNodes,
+				Source:            route.SourceAsset,
+				SourceAmount:      route.SourceAmount,
+				Destination:       route.DestinationAsset,
+				DestinationAmount: route.DestinationAmount,
+			}
+		}
+		results[i] = paths.Path{
+			Source:            split.SourceAsset,
+			SourceAmount:      split.SourceAmount,
+			Destination:       split.DestinationAsset,
+			DestinationAmount: split.DestinationAmount,
+			Routes:            routes,
+		}
+		if len(routes) > 0 {
+			results[i].Path = routes[0].Path
+		}
+	}
+	return results
+}
@@ -0,0 +1,386 @@
+// Package txsim predicts the result of submitting a transaction to the
+// network, without submitting it, by checking it against the ledger state
+// ingested by Horizon.
+//
+// The simulation covers the checks which most commonly make transactions
+// fail: time bounds, fees, sequence numbers, signatures and thresholds,
+// balances and reserves, trust line authorization, sponsorships and, when an
+// order book graph is available, the outcome of crossing offers and path
+// payments. Operations which are not listed in Simulator.operation are
+// assumed to succeed once their source account and signatures are checked.
+package txsim
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/stellar/go/exp/orderbook"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/services/horizon/internal/codes"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrSorobanNotSupported is returned when simulating a transaction which
+// invokes a Soroban host function. Those transactions need to be simulated
+// with the simulateTransaction endpoint of Stellar RPC.
+var ErrSorobanNotSupported = errors.New("soroban transactions cannot be simulated")
+
+// State gives access to the ledger entries a transaction simulation depends
+// on. The returned bools are false if the ledger entry does not exist.
+type State interface {
+	GetAccount(ctx context.Context, accountID string) (history.AccountEntry, bool, error)
+	// GetSigners returns the signers of the account, including its master key
+	// if its weight is positive.
+	GetSigners(ctx context.Context, accountID string) ([]history.AccountSigner, error)
+	GetTrustLine(ctx context.Context, accountID string, asset xdr.Asset) (history.TrustLine, bool, error)
+	GetOffer(ctx context.Context, offerID int64) (history.Offer, bool, error)
+}
+
+// Ledger describes the last closed ledger the transaction is simulated after.
+type Ledger struct {
+	Sequence uint32
+	// CloseTime is the close time of the ledger as a unix timestamp.
+	CloseTime   int64
+	BaseFee     uint32
+	BaseReserve uint32
+}
+
+// Simulator simulates transactions.
+type Simulator struct {
+	NetworkPassphrase string
+	// OrderBook is used to simulate offers and path payments. If it is nil
+	// only the balances and trust lines of the accounts involved in offers
+	// and path payments are checked.
+	OrderBook    *orderbook.OrderBookGraph
+	IncludePools bool
+}
+
+// Result is the predicted result of a transaction. The result codes are the
+// ones used by Horizon in the `result_codes` of failed submissions.
+type Result struct {
+	Hash       string
+	Successful bool
+	// FeeCharged is zero when the transaction would be rejected without being
+	// included in a ledger.
+	FeeCharged      int64
+	TransactionCode string
+	// InnerHash and InnerTransactionCode are only set for fee bump
+	// transactions.
+	InnerHash            string
+	InnerTransactionCode string
+	OperationCodes       []string
+}
+
+// Simulate predicts the result of submitting the transaction in `envelope`
+// right after `ledger`, whose ledger entries are provided by `state`.
+func (s Simulator) Simulate(
+	ctx context.Context,
+	state State,
+	ledger Ledger,
+	envelope xdr.TransactionEnvelope,
+) (Result, error) {
+	for _, op := range envelope.Operations() {
+		switch op.Body.Type {
+		case xdr.OperationTypeInvokeHostFunction,
+			xdr.OperationTypeExtendFootprintTtl,
+			xdr.OperationTypeRestoreFootprint:
+			return Result{}, ErrSorobanNotSupported
+		}
+	}
+
+	hash, err := network.HashTransactionInEnvelope(envelope, s.NetworkPassphrase)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "could not hash transaction")
+	}
+
+	sim := &simulation{
+		Simulator: s,
+		ledger:    ledger,
+		state:     newLedgerState(state),
+		sponsors:  map[string]string{},
+	}
+	result := Result{Hash: hex.EncodeToString(hash[:])}
+
+	if !envelope.IsFeeBump() {
+		code, opCodes, err := sim.transaction(ctx, envelope, hash, true)
+		if err != nil {
+			return Result{}, err
+		}
+		if result.TransactionCode, err = codes.String(code); err != nil {
+			return Result{}, err
+		}
+		result.OperationCodes = opCodes
+		result.Successful = code == xdr.TransactionResultCodeTxSuccess
+		if sim.applied {
+			result.FeeCharged = sim.feeCharged(int64(envelope.Fee()), len(envelope.Operations()))
+		}
+		return result, nil
+	}
+
+	code, err := sim.feeBump(ctx, envelope, hash)
+	if err != nil {
+		return Result{}, err
+	}
+	if code != xdr.TransactionResultCodeTxSuccess {
+		result.TransactionCode, err = codes.String(code)
+		return result, err
+	}
+
+	inner := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1:   envelope.FeeBump.Tx.InnerTx.V1,
+	}
+	innerHash, err := network.HashTransactionInEnvelope(inner, s.NetworkPassphrase)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "could not hash inner transaction")
+	}
+	result.InnerHash = hex.EncodeToString(innerHash[:])
+
+	innerCode, opCodes, err := sim.transaction(ctx, inner, innerHash, false)
+	if err != nil {
+		return Result{}, err
+	}
+	if result.InnerTransactionCode, err = codes.String(innerCode); err != nil {
+		return Result{}, err
+	}
+	result.OperationCodes = opCodes
+
+	outerCode := xdr.TransactionResultCodeTxFeeBumpInnerFailed
+	if innerCode == xdr.TransactionResultCodeTxSuccess {
+		outerCode = xdr.TransactionResultCodeTxFeeBumpInnerSuccess
+		result.Successful = true
+	}
+	if result.TransactionCode, err = codes.String(outerCode); err != nil {
+		return Result{}, err
+	}
+	// a fee bump transaction whose inner transaction is invalid is rejected
+	// without being charged, the fee is only charged if the inner
+	// transaction is applied, even if it fails
+	if sim.applied {
+		result.FeeCharged = sim.feeCharged(envelope.FeeBumpFee(), len(envelope.Operations())+1)
+	}
+	return result, nil
+}
+
+// simulation holds the state of a single transaction simulation.
+type simulation struct {
+	Simulator
+	ledger Ledger
+	state  *ledgerState
+	// sponsors maps the accounts whose future reserves are sponsored to
+	// their sponsor.
+	sponsors map[string]string
+	// applied is set once the transaction passed validation and is applied,
+	// it is then included in a ledger and charged its fee whatever its
+	// result.
+	applied bool
+}
+
+// feeCharged returns the fee charged for a transaction with the given fee
+// bid and number of operations, assuming there is no surge pricing.
+func (sim *simulation) feeCharged(fee int64, operations int) int64 {
+	minFee := int64(sim.ledger.BaseFee) * int64(operations)
+	if fee < minFee {
+		return fee
+	}
+	return minFee
+}
+
+// minBalance returns the minimum native balance of the account.
+func (sim *simulation) minBalance(account *account) int64 {
+	entries := 2 + int64(account.NumSubEntries) + int64(account.NumSponsoring) - int64(account.NumSponsored)
+	return entries * int64(sim.ledger.BaseReserve)
+}
+
+// availableBalance returns the native balance the account can spend.
+func (sim *simulation) availableBalance(account *account) int64 {
+	return account.Balance - sim.minBalance(account) - account.SellingLiabilities
+}
+
+// feeBump checks the outer transaction of a fee bump transaction and charges
+// its fee.
+func (sim *simulation) feeBump(ctx context.Context, envelope xdr.TransactionEnvelope, hash [32]byte) (xdr.TransactionResultCode, error) {
+	operations := int64(len(envelope.Operations())) + 1
+	if envelope.FeeBumpFee() < int64(sim.ledger.BaseFee)*operations {
+		return xdr.TransactionResultCodeTxInsufficientFee, nil
+	}
+
+	feeSource := envelope.FeeBumpAccount().ToAccountId()
+	account, err := sim.state.account(ctx, feeSource.Address())
+	if err != nil {
+		return 0, err
+	}
+	if account == nil {
+		return xdr.TransactionResultCodeTxNoAccount, nil
+	}
+
+	checker := newSignatureChecker(hash, envelope.FeeBumpSignatures())
+	ok, err := sim.checkSignatures(ctx, checker, account, account.ThresholdLow)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return xdr.TransactionResultCodeTxBadAuth, nil
+	}
+	if sim.availableBalance(account) < envelope.FeeBumpFee() {
+		return xdr.TransactionResultCodeTxInsufficientBalance, nil
+	}
+	if !checker.allUsed() {
+		return xdr.TransactionResultCodeTxBadAuthExtra, nil
+	}
+
+	account.Balance -= sim.feeCharged(envelope.FeeBumpFee(), int(operations))
+	return xdr.TransactionResultCodeTxSuccess, nil
+}
+
+// transaction simulates a transaction which is not a fee bump transaction.
+// The fee is only checked and charged if `payFee` is true, which is not the
+// case for the inner transaction of fee bump transactions.
+func (sim *simulation) transaction(
+	ctx context.Context,
+	envelope xdr.TransactionEnvelope,
+	hash [32]byte,
+	payFee bool,
+) (xdr.TransactionResultCode, []string, error) {
+	ops := envelope.Operations()
+	if len(ops) == 0 {
+		return xdr.TransactionResultCodeTxMissingOperation, nil, nil
+	}
+	if code := sim.checkBounds(envelope); code != xdr.TransactionResultCodeTxSuccess {
+		return code, nil, nil
+	}
+	fee := int64(envelope.Fee())
+	if payFee && fee < int64(sim.ledger.BaseFee)*int64(len(ops)) {
+		return xdr.TransactionResultCodeTxInsufficientFee, nil, nil
+	}
+
+	sourceID := envelope.SourceAccount().ToAccountId()
+	source, err := sim.state.account(ctx, sourceID.Address())
+	if err != nil {
+		return 0, nil, err
+	}
+	if source == nil {
+		return xdr.TransactionResultCodeTxNoAccount, nil, nil
+	}
+	if code := sim.checkSequence(envelope, source); code != xdr.TransactionResultCodeTxSuccess {
+		return code, nil, nil
+	}
+
+	checker := newSignatureChecker(hash, envelope.Signatures())
+	ok, err := sim.checkSignatures(ctx, checker, source, source.ThresholdLow)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !ok {
+		return xdr.TransactionResultCodeTxBadAuth, nil, nil
+	}
+	for _, signer := range envelope.ExtraSigners() {
+		if !checker.signedBy(signer.Address()) {
+			return xdr.TransactionResultCodeTxBadAuth, nil, nil
+		}
+	}
+	if payFee && sim.availableBalance(source) < fee {
+		return xdr.TransactionResultCodeTxInsufficientBalance, nil, nil
+	}
+
+	// the signatures of all the operations are checked before applying them
+	opCodes := make([]string, len(ops))
+	failed := false
+	for i, op := range ops {
+		opCodes[i] = codes.OpSuccess
+		ok, err := sim.checkOperationSignatures(ctx, checker, op, sourceID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !ok {
+			if opCodes[i], err = codes.String(xdr.OperationResultCodeOpBadAuth); err != nil {
+				return 0, nil, err
+			}
+			failed = true
+		}
+	}
+	if failed {
+		return xdr.TransactionResultCodeTxFailed, opCodes, nil
+	}
+	if !checker.allUsed() {
+		return xdr.TransactionResultCodeTxBadAuthExtra, nil, nil
+	}
+
+	if payFee {
+		source.Balance -= sim.feeCharged(fee, len(ops))
+	}
+	source.SequenceNumber = envelope.SeqNum()
+	sim.applied = true
+
+	for i, op := range ops {
+		opSourceID := sourceID
+		if op.SourceAccount != nil {
+			opSourceID = op.SourceAccount.ToAccountId()
+		}
+		opCodes[i], err = sim.operation(ctx, op, opSourceID)
+		if err != nil {
+			return 0, nil, errors.Wrapf(err, "could not simulate operation %d", i)
+		}
+		if opCodes[i] != codes.OpSuccess {
+			failed = true
+		}
+	}
+	if failed {
+		return xdr.TransactionResultCodeTxFailed, opCodes, nil
+	}
+	if len(sim.sponsors) > 0 {
+		return xdr.TransactionResultCodeTxBadSponsorship, nil, nil
+	}
+	return xdr.TransactionResultCodeTxSuccess, opCodes, nil
+}
+
+// checkBounds checks the time and ledger bounds of the transaction against
+// the ledger following the last closed ledger.
+func (sim *simulation) checkBounds(envelope xdr.TransactionEnvelope) xdr.TransactionResultCode {
+	closeTime := uint64(sim.ledger.CloseTime)
+	if timeBounds := envelope.TimeBounds(); timeBounds != nil {
+		if uint64(timeBounds.MinTime) > closeTime {
+			return xdr.TransactionResultCodeTxTooEarly
+		}
+		if timeBounds.MaxTime != 0 && uint64(timeBounds.MaxTime) < closeTime {
+			return xdr.TransactionResultCodeTxTooLate
+		}
+	}
+
+	next := sim.ledger.Sequence + 1
+	if ledgerBounds := envelope.LedgerBounds(); ledgerBounds != nil {
+		if uint32(ledgerBounds.MinLedger) > next {
+			return xdr.TransactionResultCodeTxTooEarly
+		}
+		if ledgerBounds.MaxLedger != 0 && uint32(ledgerBounds.MaxLedger) <= next {
+			return xdr.TransactionResultCodeTxTooLate
+		}
+	}
+	return xdr.TransactionResultCodeTxSuccess
+}
+
+// checkSequence checks the sequence number preconditions of the transaction.
+func (sim *simulation) checkSequence(envelope xdr.TransactionEnvelope, source *account) xdr.TransactionResultCode {
+	seq := envelope.SeqNum()
+	if minSeq := envelope.MinSeqNum(); minSeq != nil {
+		if source.SequenceNumber < *minSeq || seq <= source.SequenceNumber {
+			return xdr.TransactionResultCodeTxBadSeq
+		}
+	} else if seq != source.SequenceNumber+1 {
+		return xdr.TransactionResultCodeTxBadSeq
+	}
+
+	if minAge := envelope.MinSeqAge(); minAge != nil && *minAge > 0 {
+		if sim.ledger.CloseTime-source.SequenceTime.Int64 < int64(*minAge) {
+			return xdr.TransactionResultCodeTxBadMinSeqAgeOrGap
+		}
+	}
+	if minGap := envelope.MinSeqLedgerGap(); minGap != nil && *minGap > 0 {
+		if int64(sim.ledger.Sequence+1)-source.SequenceLedger.Int64 < int64(*minGap) {
+			return xdr.TransactionResultCodeTxBadMinSeqAgeOrGap
+		}
+	}
+	return xdr.TransactionResultCodeTxSuccess
+}
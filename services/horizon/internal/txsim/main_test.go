@@ -0,0 +1,582 @@
+package txsim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/exp/orderbook"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+type mapState struct {
+	accounts   map[string]history.AccountEntry
+	signers    map[string][]history.AccountSigner
+	trustLines map[trustLineKey]history.TrustLine
+	offers     map[int64]history.Offer
+}
+
+func (s *mapState) GetAccount(ctx context.Context, accountID string) (history.AccountEntry, bool, error) {
+	account, ok := s.accounts[accountID]
+	return account, ok, nil
+}
+
+func (s *mapState) GetSigners(ctx context.Context, accountID string) ([]history.AccountSigner, error) {
+	return s.signers[accountID], nil
+}
+
+func (s *mapState) GetTrustLine(ctx context.Context, accountID string, asset xdr.Asset) (history.TrustLine, bool, error) {
+	line, ok := s.trustLines[trustLineKey{accountID: accountID, asset: asset.String()}]
+	return line, ok, nil
+}
+
+func (s *mapState) GetOffer(ctx context.Context, offerID int64) (history.Offer, bool, error) {
+	offer, ok := s.offers[offerID]
+	return offer, ok, nil
+}
+
+func (s *mapState) addAccount(kp *keypair.Full, balance int64) {
+	s.accounts[kp.Address()] = history.AccountEntry{
+		AccountID:      kp.Address(),
+		Balance:        balance,
+		SequenceNumber: 10,
+		MasterWeight:   1,
+	}
+	s.signers[kp.Address()] = []history.AccountSigner{
+		{Account: kp.Address(), Signer: kp.Address(), Weight: 1},
+	}
+}
+
+func (s *mapState) addTrustLine(kp *keypair.Full, asset xdr.Asset, balance, limit int64, authorized bool) {
+	line := history.TrustLine{
+		AccountID: kp.Address(),
+		AssetType: asset.Type,
+		Balance:   balance,
+		Limit:     limit,
+	}
+	if authorized {
+		line.Flags = uint32(xdr.TrustLineFlagsAuthorizedFlag)
+	}
+	s.trustLines[trustLineKey{accountID: kp.Address(), asset: asset.String()}] = line
+}
+
+type simulationTest struct {
+	source, destination, issuer *keypair.Full
+	usd                         xdr.Asset
+	state                       *mapState
+	ledger                      Ledger
+	simulator                   Simulator
+}
+
+func newSimulationTest() *simulationTest {
+	test := &simulationTest{
+		source:      keypair.MustRandom(),
+		destination: keypair.MustRandom(),
+		issuer:      keypair.MustRandom(),
+		state: &mapState{
+			accounts:   map[string]history.AccountEntry{},
+			signers:    map[string][]history.AccountSigner{},
+			trustLines: map[trustLineKey]history.TrustLine{},
+			offers:     map[int64]history.Offer{},
+		},
+		ledger: Ledger{
+			Sequence:    100,
+			CloseTime:   1000,
+			BaseFee:     100,
+			BaseReserve: 5000000,
+		},
+		simulator: Simulator{NetworkPassphrase: network.TestNetworkPassphrase},
+	}
+	test.usd = xdr.MustNewCreditAsset("USD", test.issuer.Address())
+	test.state.addAccount(test.source, 1000000000)
+	test.state.addAccount(test.destination, 1000000000)
+	test.state.addAccount(test.issuer, 1000000000)
+	return test
+}
+
+func (test *simulationTest) envelope(
+	t *testing.T,
+	params txnbuild.TransactionParams,
+	signers ...*keypair.Full,
+) (*txnbuild.Transaction, xdr.TransactionEnvelope) {
+	if params.SourceAccount == nil {
+		params.SourceAccount = &txnbuild.SimpleAccount{AccountID: test.source.Address(), Sequence: 10}
+	}
+	params.IncrementSequenceNum = true
+	if params.BaseFee == 0 {
+		params.BaseFee = txnbuild.MinBaseFee
+	}
+	if params.Preconditions.TimeBounds == (txnbuild.TimeBounds{}) {
+		params.Preconditions.TimeBounds = txnbuild.NewInfiniteTimeout()
+	}
+	if len(signers) == 0 {
+		signers = []*keypair.Full{test.source}
+	}
+
+	tx, err := txnbuild.NewTransaction(params)
+	require.NoError(t, err)
+	tx, err = tx.Sign(network.TestNetworkPassphrase, signers...)
+	require.NoError(t, err)
+	return tx, tx.ToXDR()
+}
+
+func (test *simulationTest) simulate(t *testing.T, ops []txnbuild.Operation, signers ...*keypair.Full) Result {
+	_, envelope := test.envelope(t, txnbuild.TransactionParams{Operations: ops}, signers...)
+	result, err := test.simulator.Simulate(context.Background(), test.state, test.ledger, envelope)
+	require.NoError(t, err)
+	return result
+}
+
+func (test *simulationTest) payment(destination *keypair.Full, asset txnbuild.Asset, amount string) *txnbuild.Payment {
+	return &txnbuild.Payment{Destination: destination.Address(), Asset: asset, Amount: amount}
+}
+
+func (test *simulationTest) usdAsset() txnbuild.CreditAsset {
+	return txnbuild.CreditAsset{Code: "USD", Issuer: test.issuer.Address()}
+}
+
+func TestSimulateSuccessfulPayment(t *testing.T) {
+	test := newSimulationTest()
+	tx, envelope := test.envelope(t, txnbuild.TransactionParams{
+		Operations: []txnbuild.Operation{test.payment(test.destination, txnbuild.NativeAsset{}, "10")},
+		BaseFee:    500,
+	}, test.source)
+
+	result, err := test.simulator.Simulate(context.Background(), test.state, test.ledger, envelope)
+	require.NoError(t, err)
+	hash, err := tx.HashHex(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, Result{
+		Hash:            hash,
+		Successful:      true,
+		FeeCharged:      100,
+		TransactionCode: "tx_success",
+		OperationCodes:  []string{"op_success"},
+	}, result)
+}
+
+func TestSimulateTransactionChecks(t *testing.T) {
+	ops := func(test *simulationTest) []txnbuild.Operation {
+		return []txnbuild.Operation{test.payment(test.destination, txnbuild.NativeAsset{}, "10")}
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		params   func(test *simulationTest) txnbuild.TransactionParams
+		signers  func(test *simulationTest) []*keypair.Full
+		setup    func(test *simulationTest)
+		expected string
+	}{
+		{
+			name: "bad sequence number",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{
+					SourceAccount: &txnbuild.SimpleAccount{AccountID: test.source.Address(), Sequence: 11},
+					Operations:    ops(test),
+				}
+			},
+			expected: "tx_bad_seq",
+		},
+		{
+			name: "missing source account",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{Operations: ops(test)}
+			},
+			setup: func(test *simulationTest) {
+				delete(test.state.accounts, test.source.Address())
+			},
+			expected: "tx_no_source_account",
+		},
+		{
+			name: "bad signature",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{Operations: ops(test)}
+			},
+			signers: func(test *simulationTest) []*keypair.Full {
+				return []*keypair.Full{test.destination}
+			},
+			expected: "tx_bad_auth",
+		},
+		{
+			name: "extra signature",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{Operations: ops(test)}
+			},
+			signers: func(test *simulationTest) []*keypair.Full {
+				return []*keypair.Full{test.source, test.destination}
+			},
+			expected: "tx_bad_auth_extra",
+		},
+		{
+			name: "threshold not reached",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{Operations: ops(test)}
+			},
+			setup: func(test *simulationTest) {
+				account := test.state.accounts[test.source.Address()]
+				account.ThresholdLow = 2
+				test.state.accounts[test.source.Address()] = account
+			},
+			expected: "tx_bad_auth",
+		},
+		{
+			name: "insufficient fee",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{Operations: ops(test)}
+			},
+			setup: func(test *simulationTest) {
+				test.ledger.BaseFee = 200
+			},
+			expected: "tx_insufficient_fee",
+		},
+		{
+			name: "insufficient balance",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{Operations: ops(test)}
+			},
+			setup: func(test *simulationTest) {
+				account := test.state.accounts[test.source.Address()]
+				account.Balance = 10000050
+				test.state.accounts[test.source.Address()] = account
+			},
+			expected: "tx_insufficient_balance",
+		},
+		{
+			name: "too late",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{
+					Operations:    ops(test),
+					Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewTimebounds(0, 500)},
+				}
+			},
+			expected: "tx_too_late",
+		},
+		{
+			name: "too early",
+			params: func(test *simulationTest) txnbuild.TransactionParams {
+				return txnbuild.TransactionParams{
+					Operations: ops(test),
+					Preconditions: txnbuild.Preconditions{
+						TimeBounds:   txnbuild.NewInfiniteTimeout(),
+						LedgerBounds: &txnbuild.LedgerBounds{MinLedger: 200},
+					},
+				}
+			},
+			expected: "tx_too_early",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			test := newSimulationTest()
+			if testCase.setup != nil {
+				testCase.setup(test)
+			}
+			var signers []*keypair.Full
+			if testCase.signers != nil {
+				signers = testCase.signers(test)
+			}
+			_, envelope := test.envelope(t, testCase.params(test), signers...)
+
+			result, err := test.simulator.Simulate(context.Background(), test.state, test.ledger, envelope)
+			require.NoError(t, err)
+			assert.False(t, result.Successful)
+			assert.Equal(t, testCase.expected, result.TransactionCode)
+			assert.Empty(t, result.OperationCodes)
+			assert.Zero(t, result.FeeCharged)
+		})
+	}
+}
+
+func TestSimulatePaymentFailures(t *testing.T) {
+	test := newSimulationTest()
+	recipient := keypair.MustRandom()
+	test.state.addAccount(recipient, 1000000000)
+	test.state.addTrustLine(test.source, test.usd, 500000000, 10000000000, true)
+	test.state.addTrustLine(test.destination, test.usd, 0, 100000000, false)
+	test.state.addTrustLine(recipient, test.usd, 0, 1000000000, true)
+
+	result := test.simulate(t, []txnbuild.Operation{
+		// 100 XLM minus the minimum balance and the fee is available
+		test.payment(test.destination, txnbuild.NativeAsset{}, "99"),
+		test.payment(keypair.MustRandom(), txnbuild.NativeAsset{}, "1"),
+		test.payment(test.issuer, test.usdAsset(), "10"),
+		test.payment(test.destination, test.usdAsset(), "10"),
+		test.payment(recipient, test.usdAsset(), "200"),
+		test.payment(recipient, test.usdAsset(), "10"),
+		test.payment(recipient, test.usdAsset(), "45"),
+	})
+	assert.False(t, result.Successful)
+	assert.Equal(t, "tx_failed", result.TransactionCode)
+	assert.Equal(t, int64(700), result.FeeCharged)
+	assert.Equal(t, []string{
+		"op_underfunded",
+		"op_no_destination",
+		"op_success",
+		"op_not_authorized",
+		"op_line_full",
+		"op_success",
+		"op_underfunded",
+	}, result.OperationCodes)
+}
+
+func TestSimulateOperationSourceAuth(t *testing.T) {
+	test := newSimulationTest()
+	payment := test.payment(test.source, txnbuild.NativeAsset{}, "10")
+	payment.SourceAccount = test.destination.Address()
+
+	result := test.simulate(t, []txnbuild.Operation{
+		test.payment(test.destination, txnbuild.NativeAsset{}, "10"),
+		payment,
+	})
+	assert.Equal(t, "tx_failed", result.TransactionCode)
+	assert.Equal(t, []string{"op_success", "op_bad_auth"}, result.OperationCodes)
+	// the signatures of the operations are checked before the transaction is
+	// included in a ledger
+	assert.Zero(t, result.FeeCharged)
+
+	result = test.simulate(t, []txnbuild.Operation{
+		test.payment(test.destination, txnbuild.NativeAsset{}, "10"),
+		payment,
+	}, test.source, test.destination)
+	assert.True(t, result.Successful)
+	assert.Equal(t, []string{"op_success", "op_success"}, result.OperationCodes)
+}
+
+func TestSimulateOperationsSeeEarlierChanges(t *testing.T) {
+	test := newSimulationTest()
+	sendUSD := test.payment(test.source, test.usdAsset(), "10")
+	sendUSD.SourceAccount = test.issuer.Address()
+
+	result := test.simulate(t, []txnbuild.Operation{
+		&txnbuild.ChangeTrust{Line: test.usdAsset().MustToChangeTrustAsset()},
+		sendUSD,
+		test.payment(test.destination, test.usdAsset(), "10"),
+	}, test.source, test.issuer)
+	assert.Equal(t, []string{"op_success", "op_success", "op_no_trust"}, result.OperationCodes)
+
+	// the changes made by failed operations are reverted
+	result = test.simulate(t, []txnbuild.Operation{
+		test.payment(keypair.MustRandom(), txnbuild.NativeAsset{}, "98"),
+		test.payment(test.destination, txnbuild.NativeAsset{}, "98"),
+	})
+	assert.Equal(t, []string{"op_no_destination", "op_success"}, result.OperationCodes)
+}
+
+func TestSimulateCreateAccount(t *testing.T) {
+	test := newSimulationTest()
+	newAccount := keypair.MustRandom()
+
+	result := test.simulate(t, []txnbuild.Operation{
+		&txnbuild.CreateAccount{Destination: newAccount.Address(), Amount: "0.5"},
+		&txnbuild.CreateAccount{Destination: test.destination.Address(), Amount: "1"},
+	})
+	assert.Equal(t, []string{"op_low_reserve", "op_already_exists"}, result.OperationCodes)
+
+	endSponsoring := &txnbuild.EndSponsoringFutureReserves{SourceAccount: newAccount.Address()}
+	result = test.simulate(t, []txnbuild.Operation{
+		&txnbuild.BeginSponsoringFutureReserves{SponsoredID: newAccount.Address()},
+		&txnbuild.CreateAccount{Destination: newAccount.Address(), Amount: "0"},
+		endSponsoring,
+	}, test.source, newAccount)
+	assert.True(t, result.Successful)
+	assert.Equal(t, []string{"op_success", "op_success", "op_success"}, result.OperationCodes)
+
+	result = test.simulate(t, []txnbuild.Operation{
+		&txnbuild.BeginSponsoringFutureReserves{SponsoredID: newAccount.Address()},
+		&txnbuild.CreateAccount{Destination: newAccount.Address(), Amount: "0"},
+	})
+	assert.Equal(t, "tx_bad_sponsorship", result.TransactionCode)
+	assert.Empty(t, result.OperationCodes)
+}
+
+func TestSimulateExchanges(t *testing.T) {
+	test := newSimulationTest()
+	market := keypair.MustRandom()
+	test.state.addTrustLine(test.source, test.usd, 1000000000, 10000000000, true)
+
+	graph := orderbook.NewOrderBookGraph()
+	graph.AddOffers(
+		// the market sells 1000 XLM for 0.5 USD each
+		xdr.OfferEntry{
+			SellerId: xdr.MustAddress(market.Address()),
+			OfferId:  1,
+			Selling:  xdr.MustNewNativeAsset(),
+			Buying:   test.usd,
+			Amount:   10000000000,
+			Price:    xdr.Price{N: 1, D: 2},
+		},
+		// the source account sells 10 USD for 3 XLM each
+		xdr.OfferEntry{
+			SellerId: xdr.MustAddress(test.source.Address()),
+			OfferId:  2,
+			Selling:  test.usd,
+			Buying:   xdr.MustNewNativeAsset(),
+			Amount:   100000000,
+			Price:    xdr.Price{N: 3, D: 1},
+		},
+	)
+	require.NoError(t, graph.Apply(1))
+	test.simulator.OrderBook = graph
+
+	pathPayment := func(amount, sendMax string) *txnbuild.PathPaymentStrictReceive {
+		return &txnbuild.PathPaymentStrictReceive{
+			SendAsset:   test.usdAsset(),
+			SendMax:     sendMax,
+			Destination: test.destination.Address(),
+			DestAsset:   txnbuild.NativeAsset{},
+			DestAmount:  amount,
+		}
+	}
+	result := test.simulate(t, []txnbuild.Operation{
+		pathPayment("100", "60"),
+		pathPayment("100", "40"),
+		pathPayment("3000", "10000"),
+		&txnbuild.PathPaymentStrictSend{
+			SendAsset:   test.usdAsset(),
+			SendAmount:  "10",
+			Destination: test.destination.Address(),
+			DestAsset:   txnbuild.NativeAsset{},
+			DestMin:     "21",
+		},
+	})
+	assert.Equal(t, []string{
+		"op_success",
+		"op_over_source_max",
+		"op_too_few_offers",
+		"op_under_dest_min",
+	}, result.OperationCodes)
+
+	result = test.simulate(t, []txnbuild.Operation{
+		// crosses the offer of the market
+		&txnbuild.ManageSellOffer{
+			Selling: test.usdAsset(),
+			Buying:  txnbuild.NativeAsset{},
+			Amount:  "10",
+			Price:   xdr.Price{N: 1, D: 1},
+		},
+		// crosses the offer of the source account
+		&txnbuild.ManageSellOffer{
+			Selling: txnbuild.NativeAsset{},
+			Buying:  test.usdAsset(),
+			Amount:  "10",
+			Price:   xdr.Price{N: 1, D: 10},
+		},
+		&txnbuild.ManageSellOffer{
+			Selling: test.usdAsset(),
+			Buying:  txnbuild.NativeAsset{},
+			Amount:  "10",
+			Price:   xdr.Price{N: 1, D: 1},
+			OfferID: 3,
+		},
+	})
+	assert.Equal(t, []string{"op_success", "op_cross_self", "op_offer_not_found"}, result.OperationCodes)
+}
+
+func TestSimulateFeeBump(t *testing.T) {
+	test := newSimulationTest()
+	inner, _ := test.envelope(t, txnbuild.TransactionParams{
+		Operations: []txnbuild.Operation{test.payment(test.destination, txnbuild.NativeAsset{}, "1000")},
+	})
+	feeBump, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: test.destination.Address(),
+		BaseFee:    txnbuild.MinBaseFee,
+	})
+	require.NoError(t, err)
+	feeBump, err = feeBump.Sign(network.TestNetworkPassphrase, test.destination)
+	require.NoError(t, err)
+	envelope := feeBump.ToXDR()
+
+	result, err := test.simulator.Simulate(context.Background(), test.state, test.ledger, envelope)
+	require.NoError(t, err)
+	hash, err := feeBump.HashHex(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	innerHash, err := inner.HashHex(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, Result{
+		Hash:                 hash,
+		FeeCharged:           200,
+		TransactionCode:      "tx_fee_bump_inner_failed",
+		InnerHash:            innerHash,
+		InnerTransactionCode: "tx_failed",
+		OperationCodes:       []string{"op_underfunded"},
+	}, result)
+
+	// the fee of the inner transaction is paid by the fee account
+	account := test.state.accounts[test.source.Address()]
+	account.Balance = 10000000
+	test.state.accounts[test.source.Address()] = account
+	inner, _ = test.envelope(t, txnbuild.TransactionParams{
+		Operations: []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 20}},
+	})
+	feeBump, err = txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: test.destination.Address(),
+		BaseFee:    txnbuild.MinBaseFee,
+	})
+	require.NoError(t, err)
+	feeBump, err = feeBump.Sign(network.TestNetworkPassphrase, test.destination)
+	require.NoError(t, err)
+	envelope = feeBump.ToXDR()
+
+	result, err = test.simulator.Simulate(context.Background(), test.state, test.ledger, envelope)
+	require.NoError(t, err)
+	assert.True(t, result.Successful)
+	assert.Equal(t, "tx_fee_bump_inner_success", result.TransactionCode)
+	assert.Equal(t, "tx_success", result.InnerTransactionCode)
+}
+
+func TestSimulateFeeBumpInvalidInnerTransaction(t *testing.T) {
+	test := newSimulationTest()
+	inner, _ := test.envelope(t, txnbuild.TransactionParams{
+		SourceAccount: &txnbuild.SimpleAccount{AccountID: test.source.Address(), Sequence: 11},
+		Operations:    []txnbuild.Operation{test.payment(test.destination, txnbuild.NativeAsset{}, "10")},
+	})
+	feeBump, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: test.destination.Address(),
+		BaseFee:    txnbuild.MinBaseFee,
+	})
+	require.NoError(t, err)
+	feeBump, err = feeBump.Sign(network.TestNetworkPassphrase, test.destination)
+	require.NoError(t, err)
+
+	result, err := test.simulator.Simulate(context.Background(), test.state, test.ledger, feeBump.ToXDR())
+	require.NoError(t, err)
+	hash, err := feeBump.HashHex(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	innerHash, err := inner.HashHex(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	// the fee bump transaction is rejected without being charged
+	assert.Equal(t, Result{
+		Hash:                 hash,
+		TransactionCode:      "tx_fee_bump_inner_failed",
+		InnerHash:            innerHash,
+		InnerTransactionCode: "tx_bad_seq",
+	}, result)
+}
+
+func TestSimulateSorobanTransaction(t *testing.T) {
+	test := newSimulationTest()
+	envelope := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: xdr.MustMuxedAddress(test.source.Address()),
+				Operations: []xdr.Operation{{
+					Body: xdr.OperationBody{
+						Type:                 xdr.OperationTypeInvokeHostFunction,
+						InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{},
+					},
+				}},
+			},
+		},
+	}
+	_, err := test.simulator.Simulate(context.Background(), test.state, test.ledger, envelope)
+	assert.Equal(t, ErrSorobanNotSupported, err)
+}
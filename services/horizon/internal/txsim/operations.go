@@ -0,0 +1,751 @@
+package txsim
+
+import (
+	"context"
+	"math"
+
+	"github.com/stellar/go/price"
+	"github.com/stellar/go/services/horizon/internal/codes"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// operation applies the operation and returns its result code. The changes
+// made by failed operations are reverted.
+func (sim *simulation) operation(ctx context.Context, op xdr.Operation, sourceID xdr.AccountId) (string, error) {
+	snapshot := sim.state.snapshot()
+	sponsors := make(map[string]string, len(sim.sponsors))
+	for sponsored, sponsor := range sim.sponsors {
+		sponsors[sponsored] = sponsor
+	}
+
+	code, err := sim.applyOperation(ctx, op, sourceID)
+	if err != nil {
+		return "", err
+	}
+	// operations which are not simulated are assumed to succeed
+	if code == nil {
+		return codes.OpSuccess, nil
+	}
+	result, err := codes.String(code)
+	if err != nil {
+		return "", err
+	}
+	if result != codes.OpSuccess {
+		sim.state.restore(snapshot)
+		sim.sponsors = sponsors
+	}
+	return result, nil
+}
+
+// applyOperation returns the result code of the operation, which is nil if
+// the operation is not simulated.
+func (sim *simulation) applyOperation(ctx context.Context, op xdr.Operation, sourceID xdr.AccountId) (interface{}, error) {
+	source, err := sim.state.account(ctx, sourceID.Address())
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return xdr.OperationResultCodeOpNoAccount, nil
+	}
+
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		return sim.createAccount(ctx, source, op.Body.MustCreateAccountOp())
+	case xdr.OperationTypePayment:
+		return sim.payment(ctx, source, op.Body.MustPaymentOp())
+	case xdr.OperationTypePathPaymentStrictReceive:
+		return sim.pathPaymentStrictReceive(ctx, source, op.Body.MustPathPaymentStrictReceiveOp())
+	case xdr.OperationTypePathPaymentStrictSend:
+		return sim.pathPaymentStrictSend(ctx, source, op.Body.MustPathPaymentStrictSendOp())
+	case xdr.OperationTypeManageSellOffer:
+		offer := op.Body.MustManageSellOfferOp()
+		result, err := sim.manageOffer(ctx, source, offerRequest{
+			selling: offer.Selling,
+			buying:  offer.Buying,
+			amount:  int64(offer.Amount),
+			price:   offer.Price,
+			offerID: int64(offer.OfferId),
+		})
+		return manageSellOfferCodes[result], err
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		offer := op.Body.MustCreatePassiveSellOfferOp()
+		result, err := sim.manageOffer(ctx, source, offerRequest{
+			selling: offer.Selling,
+			buying:  offer.Buying,
+			amount:  int64(offer.Amount),
+			price:   offer.Price,
+			passive: true,
+		})
+		return manageSellOfferCodes[result], err
+	case xdr.OperationTypeManageBuyOffer:
+		offer := op.Body.MustManageBuyOfferOp()
+		// the price of buy offers is the price of the buying asset in terms
+		// of the selling asset
+		amount, err := price.MulFractionRoundDown(int64(offer.BuyAmount), int64(offer.Price.N), int64(offer.Price.D))
+		if err != nil {
+			return xdr.ManageBuyOfferResultCodeManageBuyOfferMalformed, nil
+		}
+		result, err := sim.manageOffer(ctx, source, offerRequest{
+			selling: offer.Selling,
+			buying:  offer.Buying,
+			amount:  amount,
+			price:   xdr.Price{N: offer.Price.D, D: offer.Price.N},
+			offerID: int64(offer.OfferId),
+		})
+		return manageBuyOfferCodes[result], err
+	case xdr.OperationTypeChangeTrust:
+		return sim.changeTrust(ctx, source, op.Body.MustChangeTrustOp())
+	case xdr.OperationTypeAccountMerge:
+		return sim.accountMerge(ctx, source, op.Body.MustDestination().ToAccountId())
+	case xdr.OperationTypeBumpSequence:
+		return sim.bumpSequence(source, op.Body.MustBumpSequenceOp())
+	case xdr.OperationTypeBeginSponsoringFutureReserves:
+		return sim.beginSponsoring(source, op.Body.MustBeginSponsoringFutureReservesOp())
+	case xdr.OperationTypeEndSponsoringFutureReserves:
+		return sim.endSponsoring(source)
+	default:
+		return nil, nil
+	}
+}
+
+func isIssuer(asset xdr.Asset, accountID string) bool {
+	return asset.Type != xdr.AssetTypeAssetTypeNative && asset.GetIssuer() == accountID
+}
+
+func isAuthorized(line *history.TrustLine) bool {
+	return xdr.TrustLineFlags(line.Flags)&xdr.TrustLineFlagsAuthorizedFlag != 0
+}
+
+// reserveSubEntry adds a subentry to the account. The reserve of the subentry
+// is paid by the sponsor of the account, if there is one, and false is
+// returned if the account paying the reserve cannot afford it.
+func (sim *simulation) reserveSubEntry(ctx context.Context, entry *account) (bool, error) {
+	payer := entry
+	sponsorID, sponsored := sim.sponsors[entry.AccountID]
+	if sponsored {
+		var err error
+		if payer, err = sim.state.account(ctx, sponsorID); err != nil {
+			return false, err
+		}
+		if payer == nil {
+			return false, nil
+		}
+	}
+	if sim.availableBalance(payer) < int64(sim.ledger.BaseReserve) {
+		return false, nil
+	}
+
+	entry.NumSubEntries++
+	if sponsored {
+		entry.NumSponsored++
+		payer.NumSponsoring++
+	}
+	return true, nil
+}
+
+// releaseSubEntry removes a subentry of the account, sponsored by `sponsor`.
+func (sim *simulation) releaseSubEntry(ctx context.Context, entry *account, sponsor string) error {
+	entry.NumSubEntries--
+	if sponsor == "" {
+		return nil
+	}
+	sponsorAccount, err := sim.state.account(ctx, sponsor)
+	if err != nil {
+		return err
+	}
+	entry.NumSponsored--
+	if sponsorAccount != nil {
+		sponsorAccount.NumSponsoring--
+	}
+	return nil
+}
+
+// paymentResult is the outcome of the checks shared by payments and path
+// payments.
+type paymentResult int
+
+const (
+	paymentSuccess paymentResult = iota
+	paymentUnderfunded
+	paymentSrcNoTrust
+	paymentSrcNotAuthorized
+	paymentNoDestination
+	paymentNoTrust
+	paymentNotAuthorized
+	paymentLineFull
+	paymentTooFewOffers
+	paymentOverSendMax
+	paymentUnderDestMin
+)
+
+var paymentCodes = map[paymentResult]xdr.PaymentResultCode{
+	paymentSuccess:          xdr.PaymentResultCodePaymentSuccess,
+	paymentUnderfunded:      xdr.PaymentResultCodePaymentUnderfunded,
+	paymentSrcNoTrust:       xdr.PaymentResultCodePaymentSrcNoTrust,
+	paymentSrcNotAuthorized: xdr.PaymentResultCodePaymentSrcNotAuthorized,
+	paymentNoDestination:    xdr.PaymentResultCodePaymentNoDestination,
+	paymentNoTrust:          xdr.PaymentResultCodePaymentNoTrust,
+	paymentNotAuthorized:    xdr.PaymentResultCodePaymentNotAuthorized,
+	paymentLineFull:         xdr.PaymentResultCodePaymentLineFull,
+}
+
+var pathPaymentStrictReceiveCodes = map[paymentResult]xdr.PathPaymentStrictReceiveResultCode{
+	paymentSuccess:          xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSuccess,
+	paymentUnderfunded:      xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveUnderfunded,
+	paymentSrcNoTrust:       xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSrcNoTrust,
+	paymentSrcNotAuthorized: xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSrcNotAuthorized,
+	paymentNoDestination:    xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNoDestination,
+	paymentNoTrust:          xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNoTrust,
+	paymentNotAuthorized:    xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNotAuthorized,
+	paymentLineFull:         xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveLineFull,
+	paymentTooFewOffers:     xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveTooFewOffers,
+	paymentOverSendMax:      xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveOverSendmax,
+}
+
+var pathPaymentStrictSendCodes = map[paymentResult]xdr.PathPaymentStrictSendResultCode{
+	paymentSuccess:          xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess,
+	paymentUnderfunded:      xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendUnderfunded,
+	paymentSrcNoTrust:       xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSrcNoTrust,
+	paymentSrcNotAuthorized: xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSrcNotAuthorized,
+	paymentNoDestination:    xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNoDestination,
+	paymentNoTrust:          xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNoTrust,
+	paymentNotAuthorized:    xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNotAuthorized,
+	paymentLineFull:         xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendLineFull,
+	paymentTooFewOffers:     xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendTooFewOffers,
+	paymentUnderDestMin:     xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendUnderDestmin,
+}
+
+// credit adds `amount` of `asset` to the balance of the destination account.
+func (sim *simulation) credit(ctx context.Context, destinationID string, asset xdr.Asset, amount int64) (paymentResult, error) {
+	destination, err := sim.state.account(ctx, destinationID)
+	if err != nil {
+		return 0, err
+	}
+	if destination == nil {
+		return paymentNoDestination, nil
+	}
+
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		if destination.Balance > math.MaxInt64-destination.BuyingLiabilities-amount {
+			return paymentLineFull, nil
+		}
+		destination.Balance += amount
+		return paymentSuccess, nil
+	}
+	// issuers can receive any amount of their assets
+	if isIssuer(asset, destinationID) {
+		return paymentSuccess, nil
+	}
+
+	line, err := sim.state.trustLine(ctx, destinationID, asset)
+	if err != nil {
+		return 0, err
+	}
+	if line == nil {
+		return paymentNoTrust, nil
+	}
+	if !isAuthorized(line) {
+		return paymentNotAuthorized, nil
+	}
+	if line.Limit-line.Balance-line.BuyingLiabilities < amount {
+		return paymentLineFull, nil
+	}
+	line.Balance += amount
+	return paymentSuccess, nil
+}
+
+// debit removes `amount` of `asset` from the balance of the source account.
+func (sim *simulation) debit(ctx context.Context, source *account, asset xdr.Asset, amount int64) (paymentResult, error) {
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		if sim.availableBalance(source) < amount {
+			return paymentUnderfunded, nil
+		}
+		source.Balance -= amount
+		return paymentSuccess, nil
+	}
+	// issuers can send any amount of their assets
+	if isIssuer(asset, source.AccountID) {
+		return paymentSuccess, nil
+	}
+
+	line, err := sim.state.trustLine(ctx, source.AccountID, asset)
+	if err != nil {
+		return 0, err
+	}
+	if line == nil {
+		return paymentSrcNoTrust, nil
+	}
+	if !isAuthorized(line) {
+		return paymentSrcNotAuthorized, nil
+	}
+	if line.Balance-line.SellingLiabilities < amount {
+		return paymentUnderfunded, nil
+	}
+	line.Balance -= amount
+	return paymentSuccess, nil
+}
+
+func (sim *simulation) payment(ctx context.Context, source *account, op xdr.PaymentOp) (interface{}, error) {
+	if op.Amount <= 0 {
+		return xdr.PaymentResultCodePaymentMalformed, nil
+	}
+	result, err := sim.credit(ctx, op.Destination.ToAccountId().Address(), op.Asset, int64(op.Amount))
+	if err != nil || result != paymentSuccess {
+		return paymentCodes[result], err
+	}
+	result, err = sim.debit(ctx, source, op.Asset, int64(op.Amount))
+	return paymentCodes[result], err
+}
+
+func (sim *simulation) pathPaymentStrictReceive(ctx context.Context, source *account, op xdr.PathPaymentStrictReceiveOp) (interface{}, error) {
+	if op.DestAmount <= 0 || op.SendMax <= 0 {
+		return xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveMalformed, nil
+	}
+	result, err := sim.credit(ctx, op.Destination.ToAccountId().Address(), op.DestAsset, int64(op.DestAmount))
+	if err != nil || result != paymentSuccess {
+		return pathPaymentStrictReceiveCodes[result], err
+	}
+
+	// without an order book only the trust lines of the source account are
+	// checked
+	var sendAmount int64
+	if sim.OrderBook != nil {
+		sourceID := xdr.MustAddress(source.AccountID)
+		assets := append(append([]xdr.Asset{op.SendAsset}, op.Path...), op.DestAsset)
+		needed, ok, err := sim.OrderBook.SimulatePathPayment(assets, op.DestAmount, true, &sourceID, sim.IncludePools)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not simulate path payment")
+		}
+		if !ok {
+			return pathPaymentStrictReceiveCodes[paymentTooFewOffers], nil
+		}
+		if needed > op.SendMax {
+			return pathPaymentStrictReceiveCodes[paymentOverSendMax], nil
+		}
+		sendAmount = int64(needed)
+	}
+
+	result, err = sim.debit(ctx, source, op.SendAsset, sendAmount)
+	return pathPaymentStrictReceiveCodes[result], err
+}
+
+func (sim *simulation) pathPaymentStrictSend(ctx context.Context, source *account, op xdr.PathPaymentStrictSendOp) (interface{}, error) {
+	if op.SendAmount <= 0 || op.DestMin <= 0 {
+		return xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendMalformed, nil
+	}
+	result, err := sim.debit(ctx, source, op.SendAsset, int64(op.SendAmount))
+	if err != nil || result != paymentSuccess {
+		return pathPaymentStrictSendCodes[result], err
+	}
+
+	// without an order book only the trust lines of the destination account
+	// are checked
+	var destAmount int64
+	if sim.OrderBook != nil {
+		assets := append(append([]xdr.Asset{op.SendAsset}, op.Path...), op.DestAsset)
+		received, ok, err := sim.OrderBook.SimulatePathPayment(assets, op.SendAmount, false, nil, sim.IncludePools)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not simulate path payment")
+		}
+		if !ok {
+			return pathPaymentStrictSendCodes[paymentTooFewOffers], nil
+		}
+		if received < op.DestMin {
+			return pathPaymentStrictSendCodes[paymentUnderDestMin], nil
+		}
+		destAmount = int64(received)
+	}
+
+	result, err = sim.credit(ctx, op.Destination.ToAccountId().Address(), op.DestAsset, destAmount)
+	return pathPaymentStrictSendCodes[result], err
+}
+
+// offerRequest is a new offer, or an update of an existing offer, in terms of
+// a sell offer.
+type offerRequest struct {
+	selling xdr.Asset
+	buying  xdr.Asset
+	// amount is the amount of the selling asset
+	amount int64
+	// price is the price of the selling asset in terms of the buying asset
+	price   xdr.Price
+	offerID int64
+	passive bool
+}
+
+// offerResult is the outcome of managing an offer.
+type offerResult int
+
+const (
+	offerSuccess offerResult = iota
+	offerMalformed
+	offerSellNoTrust
+	offerBuyNoTrust
+	offerSellNotAuthorized
+	offerBuyNotAuthorized
+	offerLineFull
+	offerUnderfunded
+	offerCrossSelf
+	offerNotFound
+	offerLowReserve
+)
+
+var manageSellOfferCodes = map[offerResult]xdr.ManageSellOfferResultCode{
+	offerSuccess:           xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+	offerMalformed:         xdr.ManageSellOfferResultCodeManageSellOfferMalformed,
+	offerSellNoTrust:       xdr.ManageSellOfferResultCodeManageSellOfferSellNoTrust,
+	offerBuyNoTrust:        xdr.ManageSellOfferResultCodeManageSellOfferBuyNoTrust,
+	offerSellNotAuthorized: xdr.ManageSellOfferResultCodeManageSellOfferSellNotAuthorized,
+	offerBuyNotAuthorized:  xdr.ManageSellOfferResultCodeManageSellOfferBuyNotAuthorized,
+	offerLineFull:          xdr.ManageSellOfferResultCodeManageSellOfferLineFull,
+	offerUnderfunded:       xdr.ManageSellOfferResultCodeManageSellOfferUnderfunded,
+	offerCrossSelf:         xdr.ManageSellOfferResultCodeManageSellOfferCrossSelf,
+	offerNotFound:          xdr.ManageSellOfferResultCodeManageSellOfferNotFound,
+	offerLowReserve:        xdr.ManageSellOfferResultCodeManageSellOfferLowReserve,
+}
+
+var manageBuyOfferCodes = map[offerResult]xdr.ManageBuyOfferResultCode{
+	offerSuccess:           xdr.ManageBuyOfferResultCodeManageBuyOfferSuccess,
+	offerMalformed:         xdr.ManageBuyOfferResultCodeManageBuyOfferMalformed,
+	offerSellNoTrust:       xdr.ManageBuyOfferResultCodeManageBuyOfferSellNoTrust,
+	offerBuyNoTrust:        xdr.ManageBuyOfferResultCodeManageBuyOfferBuyNoTrust,
+	offerSellNotAuthorized: xdr.ManageBuyOfferResultCodeManageBuyOfferSellNotAuthorized,
+	offerBuyNotAuthorized:  xdr.ManageBuyOfferResultCodeManageBuyOfferBuyNotAuthorized,
+	offerLineFull:          xdr.ManageBuyOfferResultCodeManageBuyOfferLineFull,
+	offerUnderfunded:       xdr.ManageBuyOfferResultCodeManageBuyOfferUnderfunded,
+	offerCrossSelf:         xdr.ManageBuyOfferResultCodeManageBuyOfferCrossSelf,
+	offerNotFound:          xdr.ManageBuyOfferResultCodeManageBuyOfferNotFound,
+	offerLowReserve:        xdr.ManageBuyOfferResultCodeManageBuyOfferLowReserve,
+}
+
+// offerBalances returns the amount of the selling asset the account can sell
+// and the amount of the buying asset it can receive, or the failure preventing
+// the account from trading the assets.
+func (sim *simulation) offerBalances(ctx context.Context, source *account, req offerRequest) (int64, int64, offerResult, error) {
+	available, room := int64(math.MaxInt64), int64(math.MaxInt64)
+
+	if req.selling.Type == xdr.AssetTypeAssetTypeNative {
+		available = sim.availableBalance(source)
+	} else if !isIssuer(req.selling, source.AccountID) {
+		line, err := sim.state.trustLine(ctx, source.AccountID, req.selling)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if line == nil {
+			return 0, 0, offerSellNoTrust, nil
+		}
+		if !isAuthorized(line) {
+			return 0, 0, offerSellNotAuthorized, nil
+		}
+		available = line.Balance - line.SellingLiabilities
+	}
+
+	if req.buying.Type == xdr.AssetTypeAssetTypeNative {
+		room = math.MaxInt64 - source.Balance - source.BuyingLiabilities
+	} else if !isIssuer(req.buying, source.AccountID) {
+		line, err := sim.state.trustLine(ctx, source.AccountID, req.buying)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if line == nil {
+			return 0, 0, offerBuyNoTrust, nil
+		}
+		if !isAuthorized(line) {
+			return 0, 0, offerBuyNotAuthorized, nil
+		}
+		room = line.Limit - line.Balance - line.BuyingLiabilities
+	}
+	return available, room, offerSuccess, nil
+}
+
+// adjustBalance changes the balance of `asset` held by the account, whose
+// trust line has already been checked.
+func (sim *simulation) adjustBalance(ctx context.Context, entry *account, asset xdr.Asset, delta int64) error {
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		entry.Balance += delta
+		return nil
+	}
+	if isIssuer(asset, entry.AccountID) {
+		return nil
+	}
+	line, err := sim.state.trustLine(ctx, entry.AccountID, asset)
+	if err != nil || line == nil {
+		return err
+	}
+	line.Balance += delta
+	return nil
+}
+
+func (sim *simulation) manageOffer(ctx context.Context, source *account, req offerRequest) (offerResult, error) {
+	if req.amount < 0 || req.price.N <= 0 || req.price.D <= 0 || req.selling.Equals(req.buying) {
+		return offerMalformed, nil
+	}
+
+	var existing *history.Offer
+	if req.offerID != 0 {
+		var err error
+		if existing, err = sim.state.offer(ctx, req.offerID); err != nil {
+			return 0, err
+		}
+		if existing == nil || existing.SellerID != source.AccountID {
+			return offerNotFound, nil
+		}
+		if req.amount == 0 {
+			sim.state.removeOffer(req.offerID)
+			return offerSuccess, sim.releaseSubEntry(ctx, source, existing.Sponsor.String)
+		}
+	} else if req.amount == 0 {
+		return offerMalformed, nil
+	}
+
+	available, room, result, err := sim.offerBalances(ctx, source, req)
+	if err != nil || result != offerSuccess {
+		return result, err
+	}
+	// the liabilities of the updated offer are released
+	if existing != nil && existing.SellingAsset.Equals(req.selling) {
+		available += existing.Amount
+	}
+	if available <= 0 {
+		return offerUnderfunded, nil
+	}
+	if room <= 0 {
+		return offerLineFull, nil
+	}
+
+	var crossing struct{ sold, bought int64 }
+	if sim.OrderBook != nil {
+		sellerID := xdr.MustAddress(source.AccountID)
+		result, err := sim.OrderBook.SimulateOffer(
+			sellerID, req.selling, req.buying, xdr.Int64(req.amount), req.price, req.passive,
+		)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not simulate offer")
+		}
+		if result.CrossesSelf {
+			return offerCrossSelf, nil
+		}
+		crossing.sold, crossing.bought = int64(result.AmountSold), int64(result.AmountBought)
+	}
+	if crossing.bought > room {
+		return offerLineFull, nil
+	}
+
+	// the part of the offer which is not crossed is added to the order book
+	// and needs to be covered by liabilities
+	remaining := req.amount - crossing.sold
+	if remaining > 0 {
+		if remaining > available-crossing.sold {
+			return offerUnderfunded, nil
+		}
+		buying, err := price.MulFractionRoundDown(remaining, int64(req.price.N), int64(req.price.D))
+		if err != nil || buying > room-crossing.bought {
+			return offerLineFull, nil
+		}
+		if existing == nil {
+			ok, err := sim.reserveSubEntry(ctx, source)
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				return offerLowReserve, nil
+			}
+		}
+	}
+
+	if err := sim.adjustBalance(ctx, source, req.selling, -crossing.sold); err != nil {
+		return 0, err
+	}
+	return offerSuccess, sim.adjustBalance(ctx, source, req.buying, crossing.bought)
+}
+
+func (sim *simulation) changeTrust(ctx context.Context, source *account, op xdr.ChangeTrustOp) (interface{}, error) {
+	// liquidity pool shares are not simulated
+	if op.Line.Type == xdr.AssetTypeAssetTypePoolShare {
+		return nil, nil
+	}
+	asset := op.Line.ToAsset()
+	if op.Limit < 0 || asset.Type == xdr.AssetTypeAssetTypeNative || isIssuer(asset, source.AccountID) {
+		return xdr.ChangeTrustResultCodeChangeTrustMalformed, nil
+	}
+
+	line, err := sim.state.trustLine(ctx, source.AccountID, asset)
+	if err != nil {
+		return nil, err
+	}
+	if op.Limit == 0 {
+		if line == nil || line.Balance > 0 || line.BuyingLiabilities > 0 {
+			return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit, nil
+		}
+		sim.state.setTrustLine(source.AccountID, asset, nil)
+		return xdr.ChangeTrustResultCodeChangeTrustSuccess, sim.releaseSubEntry(ctx, source, line.Sponsor.String)
+	}
+	if line != nil {
+		if int64(op.Limit) < line.Balance+line.BuyingLiabilities {
+			return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit, nil
+		}
+		line.Limit = int64(op.Limit)
+		return xdr.ChangeTrustResultCodeChangeTrustSuccess, nil
+	}
+
+	issuer, err := sim.state.account(ctx, asset.GetIssuer())
+	if err != nil {
+		return nil, err
+	}
+	if issuer == nil {
+		return xdr.ChangeTrustResultCodeChangeTrustNoIssuer, nil
+	}
+	ok, err := sim.reserveSubEntry(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return xdr.ChangeTrustResultCodeChangeTrustLowReserve, nil
+	}
+
+	newLine := &history.TrustLine{
+		AccountID: source.AccountID,
+		AssetType: asset.Type,
+		Limit:     int64(op.Limit),
+	}
+	if xdr.AccountFlags(issuer.Flags)&xdr.AccountFlagsAuthRequiredFlag == 0 {
+		newLine.Flags = uint32(xdr.TrustLineFlagsAuthorizedFlag)
+	}
+	if sponsor, ok := sim.sponsors[source.AccountID]; ok {
+		newLine.Sponsor.SetValid(sponsor)
+	}
+	sim.state.setTrustLine(source.AccountID, asset, newLine)
+	return xdr.ChangeTrustResultCodeChangeTrustSuccess, nil
+}
+
+func (sim *simulation) accountMerge(ctx context.Context, source *account, destinationID xdr.AccountId) (interface{}, error) {
+	if destinationID.Address() == source.AccountID {
+		return xdr.AccountMergeResultCodeAccountMergeMalformed, nil
+	}
+	destination, err := sim.state.account(ctx, destinationID.Address())
+	if err != nil {
+		return nil, err
+	}
+	if destination == nil {
+		return xdr.AccountMergeResultCodeAccountMergeNoAccount, nil
+	}
+	if xdr.AccountFlags(source.Flags)&xdr.AccountFlagsAuthImmutableFlag != 0 {
+		return xdr.AccountMergeResultCodeAccountMergeImmutableSet, nil
+	}
+
+	// signers are the only subentries allowed in merged accounts
+	signers, err := sim.state.accountSigners(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	extraSigners := 0
+	for _, signer := range signers {
+		if signer.Signer != source.AccountID {
+			extraSigners++
+		}
+	}
+	if int(source.NumSubEntries) > extraSigners {
+		return xdr.AccountMergeResultCodeAccountMergeHasSubEntries, nil
+	}
+	if source.NumSponsoring > 0 {
+		return xdr.AccountMergeResultCodeAccountMergeIsSponsor, nil
+	}
+	if source.SequenceNumber >= int64(sim.ledger.Sequence+1)<<32 {
+		return xdr.AccountMergeResultCodeAccountMergeSeqnumTooFar, nil
+	}
+	if destination.Balance > math.MaxInt64-destination.BuyingLiabilities-source.Balance {
+		return xdr.AccountMergeResultCodeAccountMergeDestFull, nil
+	}
+
+	destination.Balance += source.Balance
+	sim.state.removeAccount(source.AccountID)
+	return xdr.AccountMergeResultCodeAccountMergeSuccess, nil
+}
+
+func (sim *simulation) bumpSequence(source *account, op xdr.BumpSequenceOp) (interface{}, error) {
+	if op.BumpTo < 0 {
+		return xdr.BumpSequenceResultCodeBumpSequenceBadSeq, nil
+	}
+	if int64(op.BumpTo) > source.SequenceNumber {
+		source.SequenceNumber = int64(op.BumpTo)
+	}
+	return xdr.BumpSequenceResultCodeBumpSequenceSuccess, nil
+}
+
+func (sim *simulation) createAccount(ctx context.Context, source *account, op xdr.CreateAccountOp) (interface{}, error) {
+	destinationID := op.Destination.Address()
+	if op.StartingBalance < 0 || destinationID == source.AccountID {
+		return xdr.CreateAccountResultCodeCreateAccountMalformed, nil
+	}
+	destination, err := sim.state.account(ctx, destinationID)
+	if err != nil {
+		return nil, err
+	}
+	if destination != nil {
+		return xdr.CreateAccountResultCodeCreateAccountAlreadyExist, nil
+	}
+
+	// the base reserve of the new account is paid by its sponsor, if there is
+	// one
+	accountReserve := 2 * int64(sim.ledger.BaseReserve)
+	sponsorID, sponsored := sim.sponsors[destinationID]
+	var sponsor *account
+	if sponsored {
+		if sponsor, err = sim.state.account(ctx, sponsorID); err != nil {
+			return nil, err
+		}
+		if sponsor == nil || sim.availableBalance(sponsor) < accountReserve {
+			return xdr.CreateAccountResultCodeCreateAccountLowReserve, nil
+		}
+	} else if int64(op.StartingBalance) < accountReserve {
+		return xdr.CreateAccountResultCodeCreateAccountLowReserve, nil
+	}
+	if sim.availableBalance(source) < int64(op.StartingBalance) {
+		return xdr.CreateAccountResultCodeCreateAccountUnderfunded, nil
+	}
+
+	source.Balance -= int64(op.StartingBalance)
+	created := sim.state.createAccount(history.AccountEntry{
+		AccountID:      destinationID,
+		Balance:        int64(op.StartingBalance),
+		SequenceNumber: int64(sim.ledger.Sequence+1) << 32,
+		MasterWeight:   1,
+	})
+	if sponsored {
+		created.NumSponsored = 2
+		created.Sponsor.SetValid(sponsorID)
+		sponsor.NumSponsoring += 2
+	}
+	return xdr.CreateAccountResultCodeCreateAccountSuccess, nil
+}
+
+func (sim *simulation) beginSponsoring(source *account, op xdr.BeginSponsoringFutureReservesOp) (interface{}, error) {
+	sponsoredID := op.SponsoredId.Address()
+	if sponsoredID == source.AccountID {
+		return xdr.BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesMalformed, nil
+	}
+	if _, ok := sim.sponsors[sponsoredID]; ok {
+		return xdr.BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesAlreadySponsored, nil
+	}
+	// sponsors cannot be sponsored and sponsored accounts cannot sponsor
+	if _, ok := sim.sponsors[source.AccountID]; ok {
+		return xdr.BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesRecursive, nil
+	}
+	for _, sponsor := range sim.sponsors {
+		if sponsor == sponsoredID {
+			return xdr.BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesRecursive, nil
+		}
+	}
+	sim.sponsors[sponsoredID] = source.AccountID
+	return xdr.BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesSuccess, nil
+}
+
+func (sim *simulation) endSponsoring(source *account) (interface{}, error) {
+	if _, ok := sim.sponsors[source.AccountID]; !ok {
+		return xdr.EndSponsoringFutureReservesResultCodeEndSponsoringFutureReservesNotSponsored, nil
+	}
+	delete(sim.sponsors, source.AccountID)
+	return xdr.EndSponsoringFutureReservesResultCodeEndSponsoringFutureReservesSuccess, nil
+}
@@ -0,0 +1,205 @@
+package txsim
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// signatureChecker matches the signatures of a transaction with signers,
+// keeping track of the signatures used, like stellar-core does.
+type signatureChecker struct {
+	hash       [32]byte
+	signatures []xdr.DecoratedSignature
+	used       []bool
+}
+
+func newSignatureChecker(hash [32]byte, signatures []xdr.DecoratedSignature) *signatureChecker {
+	return &signatureChecker{
+		hash:       hash,
+		signatures: signatures,
+		used:       make([]bool, len(signatures)),
+	}
+}
+
+// signedBy returns true if the transaction is authorized by the signer, given
+// as a strkey encoded signer key.
+func (c *signatureChecker) signedBy(signer string) bool {
+	version, payload, err := strkey.DecodeAny(signer)
+	if err != nil {
+		return false
+	}
+
+	switch version {
+	case strkey.VersionByteHashTx:
+		// pre-authorized transactions do not need a signature
+		return bytes.Equal(payload, c.hash[:])
+	case strkey.VersionByteHashX:
+		var hint xdr.SignatureHint
+		copy(hint[:], payload[len(payload)-len(hint):])
+		return c.use(hint, func(signature []byte) bool {
+			preimageHash := sha256.Sum256(signature)
+			return bytes.Equal(preimageHash[:], payload)
+		})
+	case strkey.VersionByteAccountID:
+		kp, err := keypair.ParseAddress(signer)
+		if err != nil {
+			return false
+		}
+		return c.use(kp.Hint(), func(signature []byte) bool {
+			return kp.Verify(c.hash[:], signature) == nil
+		})
+	case strkey.VersionByteSignedPayload:
+		signedPayload, err := strkey.DecodeSignedPayload(signer)
+		if err != nil {
+			return false
+		}
+		kp, err := keypair.ParseAddress(signedPayload.Signer())
+		if err != nil {
+			return false
+		}
+		hint := xdr.NewDecoratedSignatureForPayload(nil, kp.Hint(), signedPayload.Payload()).Hint
+		return c.use(hint, func(signature []byte) bool {
+			return kp.Verify(signedPayload.Payload(), signature) == nil
+		})
+	default:
+		return false
+	}
+}
+
+// use marks the first signature with the given hint accepted by `verify` as
+// used.
+func (c *signatureChecker) use(hint xdr.SignatureHint, verify func(signature []byte) bool) bool {
+	for i, signature := range c.signatures {
+		if signature.Hint != hint || !verify(signature.Signature) {
+			continue
+		}
+		c.used[i] = true
+		return true
+	}
+	return false
+}
+
+// allUsed returns true if all the signatures of the transaction were used to
+// authorize it.
+func (c *signatureChecker) allUsed() bool {
+	for _, used := range c.used {
+		if !used {
+			return false
+		}
+	}
+	return true
+}
+
+// signerTypeOrder is the order in which stellar-core checks signers.
+var signerTypeOrder = map[strkey.VersionByte]int{
+	strkey.VersionByteHashTx:        0,
+	strkey.VersionByteHashX:         1,
+	strkey.VersionByteAccountID:     2,
+	strkey.VersionByteSignedPayload: 3,
+}
+
+// checkSignatures returns true if the signatures of the transaction reach the
+// threshold of the account. Signatures are only marked as used until the
+// threshold is reached.
+func (sim *simulation) checkSignatures(
+	ctx context.Context,
+	checker *signatureChecker,
+	account *account,
+	threshold byte,
+) (bool, error) {
+	signers, err := sim.state.accountSigners(ctx, account)
+	if err != nil {
+		return false, err
+	}
+	sorted := make([]string, 0, len(signers))
+	weights := map[string]int32{}
+	for _, signer := range signers {
+		if signer.Weight <= 0 {
+			continue
+		}
+		sorted = append(sorted, signer.Signer)
+		weights[signer.Signer] = signer.Weight
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return signerTypeOrder[signerVersion(sorted[i])] < signerTypeOrder[signerVersion(sorted[j])]
+	})
+
+	// at least one signature is needed even if the threshold is zero
+	needed := int32(threshold)
+	if needed == 0 {
+		needed = 1
+	}
+	var weight int32
+	for _, signer := range sorted {
+		if !checker.signedBy(signer) {
+			continue
+		}
+		weight += weights[signer]
+		if weight >= needed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func signerVersion(signer string) strkey.VersionByte {
+	version, err := strkey.Version(signer)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// checkOperationSignatures returns true if the operation is authorized by the
+// signatures of the transaction. Operations whose source account does not
+// exist must be signed by the master key of the account.
+func (sim *simulation) checkOperationSignatures(
+	ctx context.Context,
+	checker *signatureChecker,
+	op xdr.Operation,
+	txSourceID xdr.AccountId,
+) (bool, error) {
+	sourceID := txSourceID
+	if op.SourceAccount != nil {
+		sourceID = op.SourceAccount.ToAccountId()
+	}
+	source, err := sim.state.account(ctx, sourceID.Address())
+	if err != nil {
+		return false, err
+	}
+	if source == nil {
+		return checker.signedBy(sourceID.Address()), nil
+	}
+	return sim.checkSignatures(ctx, checker, source, operationThreshold(op, source))
+}
+
+// operationThreshold returns the threshold of the source account the
+// operation needs to reach.
+func operationThreshold(op xdr.Operation, source *account) byte {
+	switch op.Body.Type {
+	case xdr.OperationTypeAccountMerge:
+		return source.ThresholdHigh
+	case xdr.OperationTypeSetOptions:
+		options := op.Body.MustSetOptionsOp()
+		if options.MasterWeight != nil || options.LowThreshold != nil ||
+			options.MedThreshold != nil || options.HighThreshold != nil ||
+			options.Signer != nil {
+			return source.ThresholdHigh
+		}
+		return source.ThresholdMedium
+	case xdr.OperationTypeAllowTrust,
+		xdr.OperationTypeSetTrustLineFlags,
+		xdr.OperationTypeBumpSequence,
+		xdr.OperationTypeClaimClaimableBalance,
+		xdr.OperationTypeInflation:
+		return source.ThresholdLow
+	default:
+		return source.ThresholdMedium
+	}
+}
@@ -0,0 +1,238 @@
+package txsim
+
+import (
+	"context"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// NewHistoryState returns a State reading the ledger entries ingested in the
+// Horizon database. Queries should run in a repeatable read transaction so
+// that all the ledger entries belong to the same ledger.
+func NewHistoryState(q *history.Q) State {
+	return historyState{q: q}
+}
+
+type historyState struct {
+	q *history.Q
+}
+
+func (s historyState) GetAccount(ctx context.Context, accountID string) (history.AccountEntry, bool, error) {
+	account, err := s.q.GetAccountByID(ctx, accountID)
+	if s.q.NoRows(err) {
+		return history.AccountEntry{}, false, nil
+	}
+	if err != nil {
+		return history.AccountEntry{}, false, errors.Wrap(err, "could not load account")
+	}
+	return account, true, nil
+}
+
+func (s historyState) GetSigners(ctx context.Context, accountID string) ([]history.AccountSigner, error) {
+	signers, err := s.q.GetAccountSignersByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load signers")
+	}
+	return signers, nil
+}
+
+func (s historyState) GetTrustLine(ctx context.Context, accountID string, asset xdr.Asset) (history.TrustLine, bool, error) {
+	var key xdr.LedgerKey
+	if err := key.SetTrustline(xdr.MustAddress(accountID), asset.ToTrustLineAsset()); err != nil {
+		return history.TrustLine{}, false, errors.Wrap(err, "could not create trust line key")
+	}
+	encodedKey, err := key.MarshalBinaryBase64()
+	if err != nil {
+		return history.TrustLine{}, false, errors.Wrap(err, "could not encode trust line key")
+	}
+	lines, err := s.q.GetTrustLinesByKeys(ctx, []string{encodedKey})
+	if err != nil {
+		return history.TrustLine{}, false, errors.Wrap(err, "could not load trust line")
+	}
+	if len(lines) == 0 {
+		return history.TrustLine{}, false, nil
+	}
+	return lines[0], true, nil
+}
+
+func (s historyState) GetOffer(ctx context.Context, offerID int64) (history.Offer, bool, error) {
+	offers, err := s.q.GetOffersByIDs(ctx, []int64{offerID})
+	if err != nil {
+		return history.Offer{}, false, errors.Wrap(err, "could not load offer")
+	}
+	if len(offers) == 0 {
+		return history.Offer{}, false, nil
+	}
+	return offers[0], true, nil
+}
+
+// account is an account loaded or created during a simulation.
+type account struct {
+	history.AccountEntry
+	// created is true if the account was created by the simulated
+	// transaction, in which case its only signer is its master key.
+	created bool
+}
+
+type trustLineKey struct {
+	accountID string
+	asset     string
+}
+
+// ledgerState is the ledger state modified by a simulated transaction. Ledger
+// entries are loaded from the underlying State the first time they are used
+// and nil entries are entries which do not exist (or were removed).
+type ledgerState struct {
+	state      State
+	accounts   map[string]*account
+	signers    map[string][]history.AccountSigner
+	trustLines map[trustLineKey]*history.TrustLine
+	offers     map[int64]*history.Offer
+}
+
+func newLedgerState(state State) *ledgerState {
+	return &ledgerState{
+		state:      state,
+		accounts:   map[string]*account{},
+		signers:    map[string][]history.AccountSigner{},
+		trustLines: map[trustLineKey]*history.TrustLine{},
+		offers:     map[int64]*history.Offer{},
+	}
+}
+
+func (l *ledgerState) account(ctx context.Context, accountID string) (*account, error) {
+	if entry, ok := l.accounts[accountID]; ok {
+		return entry, nil
+	}
+	entry, ok, err := l.state.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		l.accounts[accountID] = nil
+		return nil, nil
+	}
+	l.accounts[accountID] = &account{AccountEntry: entry}
+	return l.accounts[accountID], nil
+}
+
+func (l *ledgerState) createAccount(entry history.AccountEntry) *account {
+	created := &account{AccountEntry: entry, created: true}
+	l.accounts[entry.AccountID] = created
+	return created
+}
+
+func (l *ledgerState) removeAccount(accountID string) {
+	l.accounts[accountID] = nil
+}
+
+// accountSigners returns the signers of an existing account.
+func (l *ledgerState) accountSigners(ctx context.Context, entry *account) ([]history.AccountSigner, error) {
+	if entry.created {
+		return []history.AccountSigner{{
+			Account: entry.AccountID,
+			Signer:  entry.AccountID,
+			Weight:  int32(entry.MasterWeight),
+		}}, nil
+	}
+	if signers, ok := l.signers[entry.AccountID]; ok {
+		return signers, nil
+	}
+	signers, err := l.state.GetSigners(ctx, entry.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	l.signers[entry.AccountID] = signers
+	return signers, nil
+}
+
+func (l *ledgerState) trustLine(ctx context.Context, accountID string, asset xdr.Asset) (*history.TrustLine, error) {
+	key := trustLineKey{accountID: accountID, asset: asset.String()}
+	if line, ok := l.trustLines[key]; ok {
+		return line, nil
+	}
+	line, ok, err := l.state.GetTrustLine(ctx, accountID, asset)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		l.trustLines[key] = nil
+		return nil, nil
+	}
+	l.trustLines[key] = &line
+	return &line, nil
+}
+
+func (l *ledgerState) setTrustLine(accountID string, asset xdr.Asset, line *history.TrustLine) {
+	l.trustLines[trustLineKey{accountID: accountID, asset: asset.String()}] = line
+}
+
+func (l *ledgerState) offer(ctx context.Context, offerID int64) (*history.Offer, error) {
+	if offer, ok := l.offers[offerID]; ok {
+		return offer, nil
+	}
+	offer, ok, err := l.state.GetOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		l.offers[offerID] = nil
+		return nil, nil
+	}
+	l.offers[offerID] = &offer
+	return &offer, nil
+}
+
+func (l *ledgerState) removeOffer(offerID int64) {
+	l.offers[offerID] = nil
+}
+
+// ledgerSnapshot is a copy of the ledger entries modified by a simulation,
+// used to revert the changes made by failed operations.
+type ledgerSnapshot struct {
+	accounts   map[string]*account
+	trustLines map[trustLineKey]*history.TrustLine
+	offers     map[int64]*history.Offer
+}
+
+func (l *ledgerState) snapshot() ledgerSnapshot {
+	s := ledgerSnapshot{
+		accounts:   make(map[string]*account, len(l.accounts)),
+		trustLines: make(map[trustLineKey]*history.TrustLine, len(l.trustLines)),
+		offers:     make(map[int64]*history.Offer, len(l.offers)),
+	}
+	for key, entry := range l.accounts {
+		if entry != nil {
+			entryCopy := *entry
+			entry = &entryCopy
+		}
+		s.accounts[key] = entry
+	}
+	for key, line := range l.trustLines {
+		if line != nil {
+			lineCopy := *line
+			line = &lineCopy
+		}
+		s.trustLines[key] = line
+	}
+	for key, offer := range l.offers {
+		if offer != nil {
+			offerCopy := *offer
+			offer = &offerCopy
+		}
+		s.offers[key] = offer
+	}
+	return s
+}
+
+// restore reverts the entries to the snapshot, which cannot be reused
+// afterwards. Entries loaded after the snapshot was taken are dropped and
+// will be loaded again if needed. Pointers to entries obtained before
+// restoring are stale.
+func (l *ledgerState) restore(s ledgerSnapshot) {
+	l.accounts = s.accounts
+	l.trustLines = s.trustLines
+	l.offers = s.offers
+}
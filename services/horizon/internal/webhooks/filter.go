@@ -0,0 +1,183 @@
+package webhooks
+
+import (
+	"encoding/json"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+)
+
+// assetPrefixes are the prefixes of the asset_type, asset_code and
+// asset_issuer fields used by operations and effects to describe assets.
+var assetPrefixes = []string{"", "selling_", "buying_", "source_", "sold_", "bought_"}
+
+// event is an operation or effect together with the fields subscriptions
+// filter on.
+type event struct {
+	raw      json.RawMessage
+	typ      int32
+	accounts map[string]struct{}
+	assets   map[string]struct{}
+}
+
+type ledgerEvents struct {
+	LedgerEvents
+	operations []event
+	effects    []event
+}
+
+func parseLedgerEvents(events LedgerEvents) (ledgerEvents, error) {
+	parsed := ledgerEvents{LedgerEvents: events}
+	for _, raw := range events.Operations {
+		e, err := parseEvent(raw)
+		if err != nil {
+			return parsed, err
+		}
+		parsed.operations = append(parsed.operations, e)
+	}
+	for _, raw := range events.Effects {
+		e, err := parseEvent(raw)
+		if err != nil {
+			return parsed, err
+		}
+		parsed.effects = append(parsed.effects, e)
+	}
+	return parsed, nil
+}
+
+func parseEvent(raw json.RawMessage) (event, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return event{}, err
+	}
+	e := event{
+		raw:      raw,
+		accounts: map[string]struct{}{},
+		assets:   map[string]struct{}{},
+	}
+	if typ, ok := fields["type_i"].(float64); ok {
+		e.typ = int32(typ)
+	}
+	e.collect(fields)
+	return e, nil
+}
+
+// collect walks the fields of an operation or effect and records all the
+// accounts and assets it references.
+func (e *event) collect(value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if strkey.IsValidEd25519PublicKey(v) {
+			e.accounts[v] = struct{}{}
+		}
+	case []interface{}:
+		for _, item := range v {
+			e.collect(item)
+		}
+	case map[string]interface{}:
+		for _, prefix := range assetPrefixes {
+			assetType, ok := v[prefix+"asset_type"].(string)
+			if !ok {
+				continue
+			}
+			if assetType == "native" {
+				e.assets["native"] = struct{}{}
+				continue
+			}
+			code, _ := v[prefix+"asset_code"].(string)
+			issuer, _ := v[prefix+"asset_issuer"].(string)
+			if code != "" && issuer != "" {
+				e.assets[code+":"+issuer] = struct{}{}
+			}
+		}
+		if asset, ok := v["asset"].(string); ok {
+			e.assets[asset] = struct{}{}
+		}
+		for key, item := range v {
+			if key == "_links" {
+				continue
+			}
+			e.collect(item)
+		}
+	}
+}
+
+// filter selects the events delivered to a subscription. Empty filters match
+// everything, except that when either type filter is set only the operations
+// or effects of the listed types are delivered.
+type filter struct {
+	accounts       map[string]struct{}
+	assets         map[string]struct{}
+	operationTypes map[int32]struct{}
+	effectTypes    map[int32]struct{}
+	filterTypes    bool
+}
+
+func newFilter(subscription history.WebhookSubscription) filter {
+	f := filter{
+		accounts:       map[string]struct{}{},
+		assets:         map[string]struct{}{},
+		operationTypes: map[int32]struct{}{},
+		effectTypes:    map[int32]struct{}{},
+		filterTypes:    len(subscription.OperationTypes) > 0 || len(subscription.EffectTypes) > 0,
+	}
+	for _, account := range subscription.Accounts {
+		f.accounts[account] = struct{}{}
+	}
+	for _, asset := range subscription.Assets {
+		f.assets[asset] = struct{}{}
+	}
+	for _, typ := range subscription.OperationTypes {
+		f.operationTypes[typ] = struct{}{}
+	}
+	for _, typ := range subscription.EffectTypes {
+		f.effectTypes[typ] = struct{}{}
+	}
+	return f
+}
+
+func (f filter) matches(e event, types map[int32]struct{}) bool {
+	if f.filterTypes {
+		if _, ok := types[e.typ]; !ok {
+			return false
+		}
+	}
+	if len(f.accounts) > 0 && !intersects(f.accounts, e.accounts) {
+		return false
+	}
+	if len(f.assets) > 0 && !intersects(f.assets, e.assets) {
+		return false
+	}
+	return true
+}
+
+func (f filter) payload(subscriptionID int64, events ledgerEvents) protocol.WebhookPayload {
+	payload := protocol.WebhookPayload{
+		SubscriptionID: subscriptionID,
+		Ledger:         events.Sequence,
+		ClosedAt:       events.ClosedAt,
+		Operations:     []json.RawMessage{},
+		Effects:        []json.RawMessage{},
+	}
+	for _, e := range events.operations {
+		if f.matches(e, f.operationTypes) {
+			payload.Operations = append(payload.Operations, e.raw)
+		}
+	}
+	for _, e := range events.effects {
+		if f.matches(e, f.effectTypes) {
+			payload.Effects = append(payload.Effects, e.raw)
+		}
+	}
+	return payload
+}
+
+func intersects(a, b map[string]struct{}) bool {
+	for key := range a {
+		if _, ok := b[key]; ok {
+			return true
+		}
+	}
+	return false
+}
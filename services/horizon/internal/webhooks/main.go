@@ -0,0 +1,391 @@
+// Package webhooks delivers the operations and effects of ingested ledgers to
+// HTTP endpoints registered on the admin port. Every subscription has a durable
+// cursor so payloads are delivered in ledger order, at least once, across
+// restarts. Failed deliveries are retried with exponential backoff and moved to
+// a dead letter list once the maximum number of attempts is reached.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	logpkg "github.com/stellar/go/support/log"
+)
+
+const (
+	// SignatureHeader holds the signature of the payload in the form
+	// `t=<unix timestamp>,v1=<hex encoded signature>`, see Sign.
+	SignatureHeader = "X-Horizon-Signature"
+	// SubscriptionHeader holds the id of the subscription the payload is
+	// delivered to.
+	SubscriptionHeader = "X-Horizon-Webhook-Subscription"
+	// LedgerHeader holds the sequence of the ledger the payload belongs to.
+	LedgerHeader = "X-Horizon-Ledger"
+
+	defaultFrequency         = time.Second
+	defaultMaxAttempts       = 10
+	defaultInitialBackoff    = 5 * time.Second
+	defaultMaxBackoff        = time.Hour
+	defaultMaxLedgersPerTick = 100
+	defaultMaxTickDuration   = 30 * time.Second
+	defaultRequestTimeout    = 10 * time.Second
+)
+
+var log = logpkg.DefaultLogger.WithField("service", "webhooks")
+
+// Config configures the Dispatcher, zero values are replaced by defaults.
+type Config struct {
+	// HTTPClient is used to deliver payloads.
+	HTTPClient *http.Client
+	// Frequency is how often new ledgers are checked for.
+	Frequency time.Duration
+	// MaxAttempts is the number of failed deliveries of a payload after which
+	// the payload is dead lettered.
+	MaxAttempts int32
+	// InitialBackoff is the delay after the first failed delivery, it doubles
+	// after every subsequent failure up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxLedgersPerTick bounds the number of ledgers processed for a
+	// subscription on every tick, so that a subscription catching up does not
+	// starve the others.
+	MaxLedgersPerTick uint32
+	// MaxTickDuration bounds the time spent delivering payloads on every
+	// tick, during which the delivery lock is held in a database
+	// transaction: no delivery is started once it has elapsed and the
+	// remaining subscriptions are dispatched first on the next tick.
+	MaxTickDuration time.Duration
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of `<timestamp>.<body>` keyed by
+// the subscription secret. Receivers should recompute it to authenticate
+// payloads and reject stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type lockQ interface {
+	Begin(ctx context.Context) error
+	Rollback() error
+	TryWebhookDeliveryLock(ctx context.Context) (bool, error)
+}
+
+// Dispatcher delivers webhook payloads for all subscriptions. Only one
+// Dispatcher across all Horizon instances sharing a database delivers payloads
+// at a time.
+type Dispatcher struct {
+	config Config
+	store  Store
+	lockQ  lockQ
+	now    func() time.Time
+	logger *logpkg.Entry
+	// resumeFrom is the id of the first subscription to dispatch on the next
+	// tick, set when a tick runs out of time.
+	resumeFrom int64
+
+	deliveries       *prometheus.CounterVec
+	deliveryDuration prometheus.Summary
+}
+
+// NewDispatcher creates a new Dispatcher instance
+func NewDispatcher(config Config, dbSession db.SessionInterface) *Dispatcher {
+	return newDispatcher(
+		config,
+		NewHistoryStore(&history.Q{dbSession.Clone()}),
+		&history.Q{dbSession.Clone()},
+	)
+}
+
+func newDispatcher(config Config, store Store, lockQ lockQ) *Dispatcher {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: defaultRequestTimeout}
+	}
+	if config.Frequency == 0 {
+		config.Frequency = defaultFrequency
+	}
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.InitialBackoff == 0 {
+		config.InitialBackoff = defaultInitialBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = defaultMaxBackoff
+	}
+	if config.MaxLedgersPerTick == 0 {
+		config.MaxLedgersPerTick = defaultMaxLedgersPerTick
+	}
+	if config.MaxTickDuration == 0 {
+		config.MaxTickDuration = defaultMaxTickDuration
+	}
+
+	return &Dispatcher{
+		config: config,
+		store:  store,
+		lockQ:  lockQ,
+		now:    time.Now,
+		logger: log.WithField("subservice", "dispatcher"),
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "horizon", Subsystem: "webhooks", Name: "deliveries_total",
+			Help: "number of webhook delivery attempts, by result",
+		}, []string{"result"}),
+		deliveryDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Namespace: "horizon", Subsystem: "webhooks", Name: "delivery_duration_seconds",
+			Help:       "webhook delivery duration in seconds, sliding window = 10m",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		}),
+	}
+}
+
+// RegisterMetrics registers the prometheus metrics
+func (d *Dispatcher) RegisterMetrics(registry *prometheus.Registry) {
+	registry.MustRegister(d.deliveries, d.deliveryDuration)
+}
+
+// Run delivers payloads until the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.Frequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+				d.logger.WithError(err).Error("could not dispatch webhooks")
+			}
+		case <-ctx.Done():
+			d.logger.Info("shutting down webhook dispatcher")
+			return
+		}
+	}
+}
+
+// Dispatch delivers the payloads of all the ingested ledgers which are past
+// the cursors of the subscriptions, for at most MaxTickDuration.
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	if err := d.lockQ.Begin(ctx); err != nil {
+		return errors.Wrap(err, "error while starting webhook delivery lock transaction")
+	}
+	defer func() {
+		if err := d.lockQ.Rollback(); err != nil {
+			d.logger.WithError(err).Error("failed to release webhook delivery lock")
+		}
+	}()
+	if acquired, err := d.lockQ.TryWebhookDeliveryLock(ctx); err != nil {
+		return errors.Wrap(err, "error while acquiring webhook delivery lock")
+	} else if !acquired {
+		return nil
+	}
+
+	latest, err := d.store.LatestLedger(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get latest ingested ledger")
+	}
+	subscriptions, err := d.store.Subscriptions(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not load webhook subscriptions")
+	}
+
+	// subscriptions are sorted by id, the ones a previous tick did not get
+	// to are dispatched first
+	for i, subscription := range subscriptions {
+		if subscription.ID >= d.resumeFrom {
+			subscriptions = append(subscriptions[i:], subscriptions[:i]...)
+			break
+		}
+	}
+	d.resumeFrom = 0
+
+	deadline := d.now().Add(d.config.MaxTickDuration)
+	ledgers := map[uint32]ledgerEvents{}
+	for _, subscription := range subscriptions {
+		if !d.now().Before(deadline) {
+			d.resumeFrom = subscription.ID
+			d.logger.WithField("subscription", subscription.ID).
+				Info("webhook dispatch ran out of time, resuming on the next tick")
+			break
+		}
+		if err := d.dispatchSubscription(ctx, subscription, latest, deadline, ledgers); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			d.logger.WithError(err).
+				WithField("subscription", subscription.ID).
+				Warn("could not dispatch webhook subscription")
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) dispatchSubscription(
+	ctx context.Context,
+	subscription history.WebhookSubscription,
+	latest uint32,
+	deadline time.Time,
+	ledgers map[uint32]ledgerEvents,
+) error {
+	if subscription.NextAttemptAt.Valid && d.now().Before(subscription.NextAttemptAt.Time) {
+		return nil
+	}
+
+	end := latest
+	if subscription.LastLedger+d.config.MaxLedgersPerTick < end {
+		end = subscription.LastLedger + d.config.MaxLedgersPerTick
+	}
+	filter := newFilter(subscription)
+	dirty := false
+	for sequence := subscription.LastLedger + 1; sequence <= end && d.now().Before(deadline); sequence++ {
+		events, ok := ledgers[sequence]
+		if !ok {
+			loaded, err := d.store.LedgerEvents(ctx, sequence)
+			if err == ErrLedgerNotFound {
+				return d.failMissingLedger(ctx, subscription, sequence)
+			} else if err != nil {
+				return errors.Wrapf(err, "could not load events of ledger %d", sequence)
+			}
+			events, err = parseLedgerEvents(loaded)
+			if err != nil {
+				return errors.Wrapf(err, "could not parse events of ledger %d", sequence)
+			}
+			ledgers[sequence] = events
+		}
+
+		payload := filter.payload(subscription.ID, events)
+		if len(payload.Operations) == 0 && len(payload.Effects) == 0 {
+			subscription.LastLedger = sequence
+			dirty = true
+			continue
+		}
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal webhook payload")
+		}
+		if err = d.post(ctx, subscription, sequence, body); err == nil {
+			d.deliveries.With(prometheus.Labels{"result": "success"}).Inc()
+			subscription.LastLedger = sequence
+			subscription.FailedAttempts = 0
+			subscription.NextAttemptAt = null.Time{}
+			subscription.LastError = ""
+			if err = d.store.UpdateDelivery(ctx, subscription); err != nil {
+				return err
+			}
+			dirty = false
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		subscription.FailedAttempts++
+		subscription.LastError = err.Error()
+		if subscription.FailedAttempts < d.config.MaxAttempts {
+			d.deliveries.With(prometheus.Labels{"result": "failure"}).Inc()
+			subscription.NextAttemptAt = null.TimeFrom(d.now().Add(d.backoff(subscription.FailedAttempts)))
+			return d.store.UpdateDelivery(ctx, subscription)
+		}
+
+		d.deliveries.With(prometheus.Labels{"result": "dead_letter"}).Inc()
+		d.logger.WithField("subscription", subscription.ID).
+			WithField("ledger", sequence).
+			WithField("attempts", subscription.FailedAttempts).
+			WithField("last_error", subscription.LastError).
+			Warn("webhook payload moved to dead letters")
+		err = d.store.InsertDeadLetter(ctx, history.WebhookDeadLetter{
+			SubscriptionID: subscription.ID,
+			Ledger:         sequence,
+			Payload:        string(body),
+			Attempts:       subscription.FailedAttempts,
+			LastError:      subscription.LastError,
+		})
+		if err != nil {
+			return err
+		}
+		subscription.LastLedger = sequence
+		subscription.FailedAttempts = 0
+		subscription.NextAttemptAt = null.Time{}
+		if err = d.store.UpdateDelivery(ctx, subscription); err != nil {
+			return err
+		}
+		dirty = false
+	}
+
+	if dirty {
+		return d.store.UpdateDelivery(ctx, subscription)
+	}
+	return nil
+}
+
+// failMissingLedger stops the delivery of a subscription whose cursor is
+// behind the ledgers in the history database: the events of the ledger are
+// not available anymore so the cursor cannot move past it without losing
+// them. The subscription is retried after MaxBackoff, in case the ledger is
+// reingested, and has to be recreated otherwise.
+func (d *Dispatcher) failMissingLedger(ctx context.Context, subscription history.WebhookSubscription, sequence uint32) error {
+	d.deliveries.With(prometheus.Labels{"result": "missing_ledger"}).Inc()
+	d.logger.WithField("subscription", subscription.ID).
+		WithField("ledger", sequence).
+		Error("webhook subscription is behind the history, its payloads cannot be delivered")
+
+	subscription.LastError = fmt.Sprintf("ledger %d is not in the history database, it may have been removed by the history retention", sequence)
+	subscription.NextAttemptAt = null.TimeFrom(d.now().Add(d.config.MaxBackoff))
+	return d.store.UpdateDelivery(ctx, subscription)
+}
+
+// backoff returns the delay before the next delivery after the given number
+// of consecutive failures.
+func (d *Dispatcher) backoff(failedAttempts int32) time.Duration {
+	backoff := d.config.InitialBackoff
+	for i := int32(1); i < failedAttempts && backoff < d.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.config.MaxBackoff {
+		backoff = d.config.MaxBackoff
+	}
+	return backoff
+}
+
+func (d *Dispatcher) post(ctx context.Context, subscription history.WebhookSubscription, sequence uint32, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create request")
+	}
+	timestamp := d.now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(SignatureHeader, fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(subscription.Secret, timestamp, body)))
+	request.Header.Set(SubscriptionHeader, strconv.FormatInt(subscription.ID, 10))
+	request.Header.Set(LedgerHeader, strconv.FormatUint(uint64(sequence), 10))
+
+	startTime := time.Now()
+	response, err := d.config.HTTPClient.Do(request)
+	d.deliveryDuration.Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	// drain (part of) the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 4096))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d", response.StatusCode)
+	}
+	return nil
+}
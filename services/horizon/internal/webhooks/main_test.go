@@ -0,0 +1,475 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	alice  = "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	bob    = "GANFZDRBCNTUXIODCJEYMACPMCSZEVE4WZGZ3CZDZ3P2SXK4KH75IK6Y"
+	issuer = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+)
+
+type fakeLock struct {
+	held bool
+}
+
+func (l *fakeLock) Begin(ctx context.Context) error { return nil }
+func (l *fakeLock) Rollback() error                 { return nil }
+func (l *fakeLock) TryWebhookDeliveryLock(ctx context.Context) (bool, error) {
+	return !l.held, nil
+}
+
+type fakeStore struct {
+	latest uint32
+	// elder is the oldest ledger in the history database
+	elder         uint32
+	ledgers       map[uint32]LedgerEvents
+	subscriptions []history.WebhookSubscription
+	deadLetters   []history.WebhookDeadLetter
+	// loaded is called when the events of a ledger are loaded
+	loaded func(sequence uint32)
+}
+
+func (s *fakeStore) LatestLedger(ctx context.Context) (uint32, error) {
+	return s.latest, nil
+}
+
+func (s *fakeStore) Subscriptions(ctx context.Context) ([]history.WebhookSubscription, error) {
+	return append([]history.WebhookSubscription{}, s.subscriptions...), nil
+}
+
+func (s *fakeStore) LedgerEvents(ctx context.Context, sequence uint32) (LedgerEvents, error) {
+	if s.loaded != nil {
+		s.loaded(sequence)
+	}
+	if sequence < s.elder {
+		return LedgerEvents{}, ErrLedgerNotFound
+	}
+	if events, ok := s.ledgers[sequence]; ok {
+		return events, nil
+	}
+	return LedgerEvents{Sequence: sequence}, nil
+}
+
+func (s *fakeStore) UpdateDelivery(ctx context.Context, subscription history.WebhookSubscription) error {
+	for i := range s.subscriptions {
+		if s.subscriptions[i].ID == subscription.ID {
+			s.subscriptions[i] = subscription
+			return nil
+		}
+	}
+	return fmt.Errorf("subscription %d not found", subscription.ID)
+}
+
+func (s *fakeStore) InsertDeadLetter(ctx context.Context, deadLetter history.WebhookDeadLetter) error {
+	s.deadLetters = append(s.deadLetters, deadLetter)
+	return nil
+}
+
+type delivery struct {
+	header  http.Header
+	body    []byte
+	payload protocol.WebhookPayload
+}
+
+type receiver struct {
+	*httptest.Server
+	lock       sync.Mutex
+	status     int
+	deliveries []delivery
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		var payload protocol.WebhookPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.deliveries = append(r.deliveries, delivery{header: req.Header, body: body, payload: payload})
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func paymentOperation(id int64, from, to string, asset xdr.Asset) json.RawMessage {
+	fields := map[string]interface{}{
+		"id":             fmt.Sprintf("%d", id),
+		"paging_token":   fmt.Sprintf("%d", id),
+		"type_i":         int32(xdr.OperationTypePayment),
+		"type":           "payment",
+		"source_account": from,
+		"from":           from,
+		"to":             to,
+		"amount":         "10.0000000",
+	}
+	var assetType, code, assetIssuer string
+	if err := asset.Extract(&assetType, &code, &assetIssuer); err != nil {
+		panic(err)
+	}
+	fields["asset_type"] = assetType
+	if assetType != "native" {
+		fields["asset_code"] = code
+		fields["asset_issuer"] = assetIssuer
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func creditEffect(id string, account string, typ history.EffectType) json.RawMessage {
+	raw, err := json.Marshal(map[string]interface{}{
+		"id":         id,
+		"type_i":     typ,
+		"account":    account,
+		"asset_type": "native",
+		"amount":     "10.0000000",
+	})
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func newTestDispatcher(store *fakeStore, lock *fakeLock, now *time.Time) *Dispatcher {
+	d := newDispatcher(Config{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     4 * time.Second,
+	}, store, lock)
+	d.now = func() time.Time { return *now }
+	return d
+}
+
+func TestDispatchDeliversSignedPayloads(t *testing.T) {
+	r := newReceiver(t)
+	usd := xdr.MustNewCreditAsset("USD", issuer)
+	closedAt := time.Unix(1700000000, 0).UTC()
+	store := &fakeStore{
+		latest: 12,
+		ledgers: map[uint32]LedgerEvents{
+			11: {
+				Sequence: 11,
+				ClosedAt: closedAt,
+				Operations: []json.RawMessage{
+					paymentOperation(1, alice, bob, usd),
+					paymentOperation(2, bob, issuer, xdr.MustNewNativeAsset()),
+				},
+				Effects: []json.RawMessage{
+					creditEffect("1-1", bob, history.EffectAccountCredited),
+					creditEffect("2-1", issuer, history.EffectAccountCredited),
+				},
+			},
+		},
+		subscriptions: []history.WebhookSubscription{
+			{ID: 1, URL: r.URL, Secret: "secret", Accounts: pq.StringArray{alice}, LastLedger: 10},
+		},
+	}
+	now := time.Unix(1700000100, 0)
+	d := newTestDispatcher(store, &fakeLock{}, &now)
+
+	require.NoError(t, d.Dispatch(context.Background()))
+
+	require.Len(t, r.deliveries, 1)
+	received := r.deliveries[0]
+	assert.Equal(t, int64(1), received.payload.SubscriptionID)
+	assert.Equal(t, uint32(11), received.payload.Ledger)
+	assert.True(t, closedAt.Equal(received.payload.ClosedAt))
+	require.Len(t, received.payload.Operations, 1)
+	assert.JSONEq(t, string(paymentOperation(1, alice, bob, usd)), string(received.payload.Operations[0]))
+	assert.Empty(t, received.payload.Effects)
+
+	assert.Equal(t, "application/json", received.header.Get("Content-Type"))
+	assert.Equal(t, "1", received.header.Get(SubscriptionHeader))
+	assert.Equal(t, "11", received.header.Get(LedgerHeader))
+	assert.Equal(
+		t,
+		fmt.Sprintf("t=%d,v1=%s", now.Unix(), Sign("secret", now.Unix(), received.body)),
+		received.header.Get(SignatureHeader),
+	)
+	assert.NotEqual(t, Sign("other", now.Unix(), received.body), Sign("secret", now.Unix(), received.body))
+
+	// ledger 12 has no events so the cursor moves past it without a delivery
+	assert.Equal(t, uint32(12), store.subscriptions[0].LastLedger)
+	assert.Equal(t, int32(0), store.subscriptions[0].FailedAttempts)
+
+	require.NoError(t, d.Dispatch(context.Background()))
+	assert.Len(t, r.deliveries, 1)
+}
+
+func TestDispatchFilters(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", issuer)
+	events := LedgerEvents{
+		Sequence: 2,
+		Operations: []json.RawMessage{
+			paymentOperation(1, alice, bob, usd),
+			paymentOperation(2, bob, issuer, xdr.MustNewNativeAsset()),
+		},
+		Effects: []json.RawMessage{
+			creditEffect("1-1", bob, history.EffectAccountCredited),
+			creditEffect("2-1", issuer, history.EffectAccountDebited),
+		},
+	}
+	parsed, err := parseLedgerEvents(events)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		name         string
+		subscription history.WebhookSubscription
+		operations   int
+		effects      int
+	}{
+		{"no filters", history.WebhookSubscription{}, 2, 2},
+		{"account", history.WebhookSubscription{Accounts: pq.StringArray{issuer}}, 2, 1},
+		{"asset", history.WebhookSubscription{Assets: pq.StringArray{"USD:" + issuer}}, 1, 0},
+		{"native asset", history.WebhookSubscription{Assets: pq.StringArray{"native"}}, 1, 2},
+		{
+			"operation type",
+			history.WebhookSubscription{OperationTypes: pq.Int32Array{int32(xdr.OperationTypePayment)}},
+			2, 0,
+		},
+		{
+			"effect type",
+			history.WebhookSubscription{EffectTypes: pq.Int32Array{int32(history.EffectAccountDebited)}},
+			0, 1,
+		},
+		{
+			"account and operation type",
+			history.WebhookSubscription{
+				Accounts:       pq.StringArray{alice},
+				OperationTypes: pq.Int32Array{int32(xdr.OperationTypeCreateAccount)},
+			},
+			0, 0,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			payload := newFilter(testCase.subscription).payload(1, parsed)
+			assert.Len(t, payload.Operations, testCase.operations)
+			assert.Len(t, payload.Effects, testCase.effects)
+		})
+	}
+}
+
+func TestDispatchRetriesWithBackoff(t *testing.T) {
+	r := newReceiver(t)
+	r.status = http.StatusInternalServerError
+	store := &fakeStore{
+		latest: 11,
+		ledgers: map[uint32]LedgerEvents{
+			11: {Sequence: 11, Operations: []json.RawMessage{paymentOperation(1, alice, bob, xdr.MustNewNativeAsset())}},
+		},
+		subscriptions: []history.WebhookSubscription{
+			{ID: 1, URL: r.URL, Secret: "secret", LastLedger: 10},
+		},
+	}
+	now := time.Unix(1700000000, 0)
+	d := newTestDispatcher(store, &fakeLock{}, &now)
+
+	require.NoError(t, d.Dispatch(context.Background()))
+	assert.Len(t, r.deliveries, 1)
+	subscription := store.subscriptions[0]
+	assert.Equal(t, uint32(10), subscription.LastLedger)
+	assert.Equal(t, int32(1), subscription.FailedAttempts)
+	assert.Equal(t, "unexpected status code 500", subscription.LastError)
+	assert.True(t, now.Add(time.Second).Equal(subscription.NextAttemptAt.Time))
+
+	// the next attempt is not due yet
+	require.NoError(t, d.Dispatch(context.Background()))
+	assert.Len(t, r.deliveries, 1)
+
+	now = now.Add(time.Second)
+	require.NoError(t, d.Dispatch(context.Background()))
+	assert.Len(t, r.deliveries, 2)
+	subscription = store.subscriptions[0]
+	assert.Equal(t, int32(2), subscription.FailedAttempts)
+	assert.True(t, now.Add(2*time.Second).Equal(subscription.NextAttemptAt.Time))
+
+	r.status = http.StatusNoContent
+	now = now.Add(2 * time.Second)
+	require.NoError(t, d.Dispatch(context.Background()))
+	assert.Len(t, r.deliveries, 3)
+	subscription = store.subscriptions[0]
+	assert.Equal(t, uint32(11), subscription.LastLedger)
+	assert.Equal(t, int32(0), subscription.FailedAttempts)
+	assert.False(t, subscription.NextAttemptAt.Valid)
+	assert.Empty(t, subscription.LastError)
+	assert.Empty(t, store.deadLetters)
+	assert.Equal(t, r.deliveries[0].body, r.deliveries[2].body)
+}
+
+func TestDispatchDeadLetters(t *testing.T) {
+	r := newReceiver(t)
+	r.status = http.StatusBadGateway
+	store := &fakeStore{
+		latest: 12,
+		ledgers: map[uint32]LedgerEvents{
+			11: {Sequence: 11, Operations: []json.RawMessage{paymentOperation(1, alice, bob, xdr.MustNewNativeAsset())}},
+			12: {Sequence: 12, Operations: []json.RawMessage{paymentOperation(2, bob, alice, xdr.MustNewNativeAsset())}},
+		},
+		subscriptions: []history.WebhookSubscription{
+			{ID: 7, URL: r.URL, Secret: "secret", LastLedger: 10},
+		},
+	}
+	now := time.Unix(1700000000, 0)
+	d := newTestDispatcher(store, &fakeLock{}, &now)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, d.Dispatch(context.Background()))
+		now = now.Add(time.Hour)
+	}
+
+	require.Len(t, store.deadLetters, 1)
+	deadLetter := store.deadLetters[0]
+	assert.Equal(t, int64(7), deadLetter.SubscriptionID)
+	assert.Equal(t, uint32(11), deadLetter.Ledger)
+	assert.Equal(t, int32(3), deadLetter.Attempts)
+	assert.Equal(t, "unexpected status code 502", deadLetter.LastError)
+	assert.Equal(t, string(r.deliveries[0].body), deadLetter.Payload)
+
+	// after dead lettering ledger 11 the dispatcher moves on to ledger 12
+	require.Len(t, r.deliveries, 4)
+	assert.Equal(t, uint32(12), r.deliveries[3].payload.Ledger)
+	subscription := store.subscriptions[0]
+	assert.Equal(t, uint32(11), subscription.LastLedger)
+	assert.Equal(t, int32(1), subscription.FailedAttempts)
+}
+
+func TestDispatchSkipsWithoutLock(t *testing.T) {
+	r := newReceiver(t)
+	store := &fakeStore{
+		latest: 11,
+		ledgers: map[uint32]LedgerEvents{
+			11: {Sequence: 11, Operations: []json.RawMessage{paymentOperation(1, alice, bob, xdr.MustNewNativeAsset())}},
+		},
+		subscriptions: []history.WebhookSubscription{
+			{ID: 1, URL: r.URL, Secret: "secret", LastLedger: 10},
+		},
+	}
+	now := time.Now()
+	d := newTestDispatcher(store, &fakeLock{held: true}, &now)
+
+	require.NoError(t, d.Dispatch(context.Background()))
+	assert.Empty(t, r.deliveries)
+	assert.Equal(t, uint32(10), store.subscriptions[0].LastLedger)
+}
+
+func TestDispatchLimitsLedgersPerTick(t *testing.T) {
+	store := &fakeStore{
+		latest: 1000,
+		subscriptions: []history.WebhookSubscription{
+			{ID: 1, URL: "http://127.0.0.1:0", Secret: "secret", LastLedger: 10},
+		},
+	}
+	now := time.Now()
+	d := newTestDispatcher(store, &fakeLock{}, &now)
+
+	require.NoError(t, d.Dispatch(context.Background()))
+	assert.Equal(t, uint32(10+defaultMaxLedgersPerTick), store.subscriptions[0].LastLedger)
+}
+
+func TestDispatchLimitsTickDuration(t *testing.T) {
+	r := newReceiver(t)
+	store := &fakeStore{
+		latest: 12,
+		ledgers: map[uint32]LedgerEvents{
+			11: {Sequence: 11, Operations: []json.RawMessage{paymentOperation(1, alice, bob, xdr.MustNewNativeAsset())}},
+			12: {Sequence: 12, Operations: []json.RawMessage{paymentOperation(2, alice, bob, xdr.MustNewNativeAsset())}},
+		},
+		subscriptions: []history.WebhookSubscription{
+			{ID: 1, URL: r.URL, Secret: "secret", LastLedger: 10},
+			{ID: 2, URL: r.URL, Secret: "secret", LastLedger: 10},
+		},
+	}
+	now := time.Unix(1700000000, 0)
+	// loading a ledger takes longer than a tick
+	store.loaded = func(uint32) {
+		now = now.Add(defaultMaxTickDuration)
+	}
+	d := newTestDispatcher(store, &fakeLock{}, &now)
+
+	require.NoError(t, d.Dispatch(context.Background()))
+	require.Len(t, r.deliveries, 1)
+	assert.Equal(t, "1", r.deliveries[0].header.Get(SubscriptionHeader))
+	assert.Equal(t, uint32(11), store.subscriptions[0].LastLedger)
+	assert.Equal(t, uint32(10), store.subscriptions[1].LastLedger)
+
+	// the next tick starts with the subscription the previous one did not
+	// get to
+	require.NoError(t, d.Dispatch(context.Background()))
+	require.Len(t, r.deliveries, 2)
+	assert.Equal(t, "2", r.deliveries[1].header.Get(SubscriptionHeader))
+	assert.Equal(t, uint32(11), store.subscriptions[0].LastLedger)
+	assert.Equal(t, uint32(11), store.subscriptions[1].LastLedger)
+
+	require.NoError(t, d.Dispatch(context.Background()))
+	require.Len(t, r.deliveries, 3)
+	assert.Equal(t, "1", r.deliveries[2].header.Get(SubscriptionHeader))
+	assert.Equal(t, uint32(12), store.subscriptions[0].LastLedger)
+}
+
+func TestDispatchFailsOnMissingLedgers(t *testing.T) {
+	r := newReceiver(t)
+	store := &fakeStore{
+		latest: 30,
+		elder:  20,
+		ledgers: map[uint32]LedgerEvents{
+			20: {Sequence: 20, Operations: []json.RawMessage{paymentOperation(1, alice, bob, xdr.MustNewNativeAsset())}},
+		},
+		subscriptions: []history.WebhookSubscription{
+			{ID: 1, URL: r.URL, Secret: "secret", LastLedger: 10},
+		},
+	}
+	now := time.Unix(1700000000, 0)
+	d := newTestDispatcher(store, &fakeLock{}, &now)
+
+	// the cursor does not move past the ledgers removed from the history
+	require.NoError(t, d.Dispatch(context.Background()))
+	assert.Empty(t, r.deliveries)
+	subscription := store.subscriptions[0]
+	assert.Equal(t, uint32(10), subscription.LastLedger)
+	assert.Equal(t, int32(0), subscription.FailedAttempts)
+	assert.Equal(t, "ledger 11 is not in the history database, it may have been removed by the history retention", subscription.LastError)
+	assert.True(t, now.Add(4*time.Second).Equal(subscription.NextAttemptAt.Time))
+	assert.Empty(t, store.deadLetters)
+
+	// once the ledgers are reingested the payloads are delivered
+	store.elder = 1
+	now = now.Add(4 * time.Second)
+	require.NoError(t, d.Dispatch(context.Background()))
+	require.Len(t, r.deliveries, 1)
+	assert.Equal(t, "20", r.deliveries[0].header.Get(LedgerHeader))
+	assert.Equal(t, uint32(30), store.subscriptions[0].LastLedger)
+	assert.Empty(t, store.subscriptions[0].LastError)
+}
+
+func TestBackoff(t *testing.T) {
+	d := newDispatcher(Config{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}, &fakeStore{}, &fakeLock{})
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 8*time.Second, d.backoff(4))
+	assert.Equal(t, 10*time.Second, d.backoff(5))
+	assert.Equal(t, 10*time.Second, d.backoff(60))
+}
@@ -0,0 +1,126 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/support/errors"
+)
+
+const ledgerEventsPageSize = 200
+
+// ErrLedgerNotFound is returned by Store.LedgerEvents when the ledger is not
+// in the history database, for instance because it was removed by the
+// history retention.
+var ErrLedgerNotFound = errors.New("ledger not found in the history database")
+
+// LedgerEvents holds the operations and effects of a ledger rendered in the
+// same format as the /operations and /effects endpoints.
+type LedgerEvents struct {
+	Sequence   uint32
+	ClosedAt   time.Time
+	Operations []json.RawMessage
+	Effects    []json.RawMessage
+}
+
+// Store provides the data used by the Dispatcher and persists the delivery
+// state of the subscriptions.
+type Store interface {
+	LatestLedger(ctx context.Context) (uint32, error)
+	Subscriptions(ctx context.Context) ([]history.WebhookSubscription, error)
+	// LedgerEvents returns the events of a ledger or ErrLedgerNotFound if
+	// the ledger is not in the history database.
+	LedgerEvents(ctx context.Context, sequence uint32) (LedgerEvents, error)
+	UpdateDelivery(ctx context.Context, subscription history.WebhookSubscription) error
+	InsertDeadLetter(ctx context.Context, deadLetter history.WebhookDeadLetter) error
+}
+
+type historyStore struct {
+	q *history.Q
+}
+
+// NewHistoryStore returns a Store backed by the Horizon history database.
+func NewHistoryStore(q *history.Q) Store {
+	return historyStore{q: q}
+}
+
+func (s historyStore) LatestLedger(ctx context.Context) (uint32, error) {
+	return s.q.GetLastLedgerIngestNonBlocking(ctx)
+}
+
+func (s historyStore) Subscriptions(ctx context.Context) ([]history.WebhookSubscription, error) {
+	return s.q.GetWebhookSubscriptions(ctx)
+}
+
+func (s historyStore) UpdateDelivery(ctx context.Context, subscription history.WebhookSubscription) error {
+	return s.q.UpdateWebhookSubscriptionDelivery(ctx, subscription)
+}
+
+func (s historyStore) InsertDeadLetter(ctx context.Context, deadLetter history.WebhookDeadLetter) error {
+	return s.q.InsertWebhookDeadLetter(ctx, deadLetter)
+}
+
+func (s historyStore) LedgerEvents(ctx context.Context, sequence uint32) (LedgerEvents, error) {
+	events := LedgerEvents{Sequence: sequence}
+
+	var ledger history.Ledger
+	err := s.q.LedgerBySequence(ctx, &ledger, int32(sequence))
+	if s.q.NoRows(err) {
+		return events, ErrLedgerNotFound
+	} else if err != nil {
+		return events, errors.Wrap(err, "could not load ledger")
+	}
+	events.ClosedAt = ledger.ClosedAt
+
+	page := db2.PageQuery{Order: db2.OrderAscending, Limit: ledgerEventsPageSize}
+	for {
+		operations, _, err := s.q.Operations().ForLedger(ctx, int32(sequence)).Page(page, 0).Fetch(ctx)
+		if err != nil {
+			return events, errors.Wrap(err, "could not load operations")
+		}
+		for _, operation := range operations {
+			resource, err := resourceadapter.NewOperation(ctx, operation, operation.TransactionHash, nil, ledger, true)
+			if err != nil {
+				return events, errors.Wrap(err, "could not render operation")
+			}
+			raw, err := json.Marshal(resource)
+			if err != nil {
+				return events, errors.Wrap(err, "could not marshal operation")
+			}
+			events.Operations = append(events.Operations, raw)
+		}
+		if uint64(len(operations)) < page.Limit {
+			break
+		}
+		page.Cursor = operations[len(operations)-1].PagingToken()
+	}
+
+	page = db2.PageQuery{Order: db2.OrderAscending, Limit: ledgerEventsPageSize}
+	for {
+		effects, err := s.q.EffectsForLedger(ctx, int32(sequence), page)
+		if err != nil {
+			return events, errors.Wrap(err, "could not load effects")
+		}
+		for _, effect := range effects {
+			resource, err := resourceadapter.NewEffect(ctx, effect, ledger)
+			if err != nil {
+				return events, errors.Wrap(err, "could not render effect")
+			}
+			raw, err := json.Marshal(resource)
+			if err != nil {
+				return events, errors.Wrap(err, "could not marshal effect")
+			}
+			events.Effects = append(events.Effects, raw)
+		}
+		if uint64(len(effects)) < page.Limit {
+			break
+		}
+		page.Cursor = effects[len(effects)-1].PagingToken()
+	}
+
+	return events, nil
+}
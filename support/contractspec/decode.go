@@ -0,0 +1,547 @@
+package contractspec
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// DecodeArgs decodes the arguments of an invocation of function into an
+// object keyed by argument name.
+func (s *Spec) DecodeArgs(function string, args []xdr.ScVal) (map[string]interface{}, error) {
+	spec, err := s.mustFunction(function)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != len(spec.Inputs) {
+		return nil, errors.Errorf("function %s expects %d arguments, got %d", function, len(spec.Inputs), len(args))
+	}
+	decoded := make(map[string]interface{}, len(args))
+	for i, input := range spec.Inputs {
+		if decoded[input.Name], err = s.DecodeValue(args[i], input.Type); err != nil {
+			return nil, errors.Wrapf(err, "could not decode argument %s of function %s", input.Name, function)
+		}
+	}
+	return decoded, nil
+}
+
+// DecodeResult decodes the return value of an invocation of function.
+func (s *Spec) DecodeResult(function string, result xdr.ScVal) (interface{}, error) {
+	spec, err := s.mustFunction(function)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Outputs) == 0 {
+		if result.Type != xdr.ScValTypeScvVoid {
+			return nil, errors.Errorf("function %s returns void, got %s", function, result.Type)
+		}
+		return nil, nil
+	}
+	decoded, err := s.DecodeValue(result, spec.Outputs[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode result of function %s", function)
+	}
+	return decoded, nil
+}
+
+// Event is the decoded form of the topics and data of a contract event.
+type Event struct {
+	Topics []interface{} `json:"topics"`
+	Data   interface{}   `json:"data"`
+}
+
+// DecodeEvent decodes the topics and data of a contract event. Version 0
+// contract specs do not describe events, so the values are decoded without
+// type information, except for contract errors which are named after the
+// cases of the error enums of the spec.
+func (s *Spec) DecodeEvent(event xdr.ContractEvent) (Event, error) {
+	return decodeEvent(s, event)
+}
+
+// DecodeEvent decodes the topics and data of a contract event without a
+// contract spec.
+func DecodeEvent(event xdr.ContractEvent) (Event, error) {
+	return decodeEvent(nil, event)
+}
+
+func decodeEvent(s *Spec, event xdr.ContractEvent) (Event, error) {
+	body, ok := event.Body.GetV0()
+	if !ok {
+		return Event{}, errors.Errorf("unsupported event body version %d", event.Body.V)
+	}
+	decoded := Event{Topics: make([]interface{}, 0, len(body.Topics))}
+	for i, topic := range body.Topics {
+		value, err := s.decodeVal(topic)
+		if err != nil {
+			return Event{}, errors.Wrapf(err, "could not decode topic %d", i)
+		}
+		decoded.Topics = append(decoded.Topics, value)
+	}
+	var err error
+	if decoded.Data, err = s.decodeVal(body.Data); err != nil {
+		return Event{}, errors.Wrap(err, "could not decode data")
+	}
+	return decoded, nil
+}
+
+// DecodeVal decodes a value without type information, the JSON
+// representation is chosen from the type of the ScVal:
+//   - vec: array
+//   - map: object when all the keys are strings, symbols or addresses, an
+//     array of {"key": key, "value": value} objects otherwise
+//   - contract instance: {"executable": executable, "storage": map}, where the
+//     executable is "stellar_asset" or {"wasm": hash}
+//   - ledger key contract instance: "ledger_key_contract_instance"
+//   - ledger key nonce: {"nonce": decimal string}
+//
+// The other types are represented as their spec type.
+func DecodeVal(val xdr.ScVal) (interface{}, error) {
+	var s *Spec
+	return s.decodeVal(val)
+}
+
+// DecodeValue decodes a value of the given type.
+func (s *Spec) DecodeValue(val xdr.ScVal, typ xdr.ScSpecTypeDef) (interface{}, error) {
+	switch typ.Type {
+	case xdr.ScSpecTypeScSpecTypeVal:
+		return s.decodeVal(val)
+	case xdr.ScSpecTypeScSpecTypeOption:
+		if val.Type == xdr.ScValTypeScvVoid {
+			return nil, nil
+		}
+		return s.DecodeValue(val, typ.MustOption().ValueType)
+	case xdr.ScSpecTypeScSpecTypeResult:
+		result := typ.MustResult()
+		if val.Type == xdr.ScValTypeScvError {
+			decoded, err := s.DecodeValue(val, result.ErrorType)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"error": decoded}, nil
+		}
+		decoded, err := s.DecodeValue(val, result.OkType)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"ok": decoded}, nil
+	case xdr.ScSpecTypeScSpecTypeVec:
+		elements, err := vecElements(val)
+		if err != nil {
+			return nil, err
+		}
+		elementType := typ.MustVec().ElementType
+		decoded := make([]interface{}, 0, len(elements))
+		for i, element := range elements {
+			value, err := s.DecodeValue(element, elementType)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid element %d", i)
+			}
+			decoded = append(decoded, value)
+		}
+		return decoded, nil
+	case xdr.ScSpecTypeScSpecTypeMap:
+		return s.decodeMap(val, typ.MustMap())
+	case xdr.ScSpecTypeScSpecTypeTuple:
+		return s.decodeTuple(val, typ.MustTuple().ValueTypes)
+	case xdr.ScSpecTypeScSpecTypeBytesN:
+		if err := expect(val, xdr.ScValTypeScvBytes); err != nil {
+			return nil, err
+		}
+		if n := typ.MustBytesN().N; len(*val.Bytes) != int(n) {
+			return nil, errors.Errorf("expected %d bytes, got %d", n, len(*val.Bytes))
+		}
+		return hex.EncodeToString(*val.Bytes), nil
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		return s.decodeUdt(val, typ)
+	}
+
+	valType, ok := scValTypes[typ.Type]
+	if !ok {
+		return nil, errors.Errorf("unsupported type %s", typ.Type)
+	}
+	if err := expect(val, valType); err != nil {
+		return nil, err
+	}
+	return s.decodeVal(val)
+}
+
+// scValTypes maps the spec types of primitive values to their ScVal type.
+var scValTypes = map[xdr.ScSpecType]xdr.ScValType{
+	xdr.ScSpecTypeScSpecTypeBool:      xdr.ScValTypeScvBool,
+	xdr.ScSpecTypeScSpecTypeVoid:      xdr.ScValTypeScvVoid,
+	xdr.ScSpecTypeScSpecTypeError:     xdr.ScValTypeScvError,
+	xdr.ScSpecTypeScSpecTypeU32:       xdr.ScValTypeScvU32,
+	xdr.ScSpecTypeScSpecTypeI32:       xdr.ScValTypeScvI32,
+	xdr.ScSpecTypeScSpecTypeU64:       xdr.ScValTypeScvU64,
+	xdr.ScSpecTypeScSpecTypeI64:       xdr.ScValTypeScvI64,
+	xdr.ScSpecTypeScSpecTypeTimepoint: xdr.ScValTypeScvTimepoint,
+	xdr.ScSpecTypeScSpecTypeDuration:  xdr.ScValTypeScvDuration,
+	xdr.ScSpecTypeScSpecTypeU128:      xdr.ScValTypeScvU128,
+	xdr.ScSpecTypeScSpecTypeI128:      xdr.ScValTypeScvI128,
+	xdr.ScSpecTypeScSpecTypeU256:      xdr.ScValTypeScvU256,
+	xdr.ScSpecTypeScSpecTypeI256:      xdr.ScValTypeScvI256,
+	xdr.ScSpecTypeScSpecTypeBytes:     xdr.ScValTypeScvBytes,
+	xdr.ScSpecTypeScSpecTypeString:    xdr.ScValTypeScvString,
+	xdr.ScSpecTypeScSpecTypeSymbol:    xdr.ScValTypeScvSymbol,
+	xdr.ScSpecTypeScSpecTypeAddress:   xdr.ScValTypeScvAddress,
+}
+
+func expect(val xdr.ScVal, valType xdr.ScValType) error {
+	if val.Type != valType {
+		return errors.Errorf("expected %s, got %s", valType, val.Type)
+	}
+	return nil
+}
+
+func vecElements(val xdr.ScVal) (xdr.ScVec, error) {
+	if err := expect(val, xdr.ScValTypeScvVec); err != nil {
+		return nil, err
+	}
+	if vec := *val.Vec; vec != nil {
+		return *vec, nil
+	}
+	return nil, nil
+}
+
+func mapEntries(val xdr.ScVal) (xdr.ScMap, error) {
+	if err := expect(val, xdr.ScValTypeScvMap); err != nil {
+		return nil, err
+	}
+	if m := *val.Map; m != nil {
+		return *m, nil
+	}
+	return nil, nil
+}
+
+// isObjectKey returns true if map keys of the given type are represented as
+// the keys of a JSON object.
+func isObjectKey(valType xdr.ScValType) bool {
+	return valType == xdr.ScValTypeScvString || valType == xdr.ScValTypeScvSymbol || valType == xdr.ScValTypeScvAddress
+}
+
+func (s *Spec) decodeMap(val xdr.ScVal, typ xdr.ScSpecTypeMap) (interface{}, error) {
+	entries, err := mapEntries(val)
+	if err != nil {
+		return nil, err
+	}
+	keyType, isObject := scValTypes[typ.KeyType.Type]
+	isObject = isObject && isObjectKey(keyType)
+
+	keys := make([]interface{}, 0, len(entries))
+	values := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		key, err := s.DecodeValue(entry.Key, typ.KeyType)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid map key")
+		}
+		value, err := s.DecodeValue(entry.Val, typ.ValueType)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of map key %v", key)
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	return mapJSON(keys, values, isObject), nil
+}
+
+func (s *Spec) decodeTuple(val xdr.ScVal, types []xdr.ScSpecTypeDef) ([]interface{}, error) {
+	elements, err := vecElements(val)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) != len(types) {
+		return nil, errors.Errorf("expected %d elements, got %d", len(types), len(elements))
+	}
+	decoded := make([]interface{}, 0, len(elements))
+	for i, element := range elements {
+		value, err := s.DecodeValue(element, types[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid element %d", i)
+		}
+		decoded = append(decoded, value)
+	}
+	return decoded, nil
+}
+
+func (s *Spec) decodeUdt(val xdr.ScVal, typ xdr.ScSpecTypeDef) (interface{}, error) {
+	entry, err := s.udt(typ)
+	if err != nil {
+		return nil, err
+	}
+	switch entry.Kind {
+	case xdr.ScSpecEntryKindScSpecEntryUdtStructV0:
+		return s.decodeStruct(val, entry.MustUdtStructV0())
+	case xdr.ScSpecEntryKindScSpecEntryUdtUnionV0:
+		return s.decodeUnion(val, entry.MustUdtUnionV0())
+	case xdr.ScSpecEntryKindScSpecEntryUdtEnumV0:
+		udt := entry.MustUdtEnumV0()
+		if err = expect(val, xdr.ScValTypeScvU32); err != nil {
+			return nil, err
+		}
+		for _, c := range udt.Cases {
+			if c.Value == *val.U32 {
+				return c.Name, nil
+			}
+		}
+		return nil, errors.Errorf("unknown value %d of enum %s", *val.U32, udt.Name)
+	case xdr.ScSpecEntryKindScSpecEntryUdtErrorEnumV0:
+		udt := entry.MustUdtErrorEnumV0()
+		if err = expect(val, xdr.ScValTypeScvError); err != nil {
+			return nil, err
+		}
+		if val.Error.Type != xdr.ScErrorTypeSceContract {
+			return nil, errors.Errorf("expected contract error, got %s", val.Error.Type)
+		}
+		for _, c := range udt.Cases {
+			if c.Value == *val.Error.ContractCode {
+				return c.Name, nil
+			}
+		}
+		return nil, errors.Errorf("unknown value %d of error enum %s", *val.Error.ContractCode, udt.Name)
+	}
+	return nil, errors.Errorf("%s is not a type", typ.MustUdt().Name)
+}
+
+func (s *Spec) decodeStruct(val xdr.ScVal, udt xdr.ScSpecUdtStructV0) (interface{}, error) {
+	if isTupleStruct(udt) {
+		types := make([]xdr.ScSpecTypeDef, 0, len(udt.Fields))
+		for _, field := range udt.Fields {
+			types = append(types, field.Type)
+		}
+		decoded, err := s.decodeTuple(val, types)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid struct %s", udt.Name)
+		}
+		return decoded, nil
+	}
+
+	entries, err := mapEntries(val)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid struct %s", udt.Name)
+	}
+	if len(entries) != len(udt.Fields) {
+		return nil, errors.Errorf("struct %s has %d fields, got %d", udt.Name, len(udt.Fields), len(entries))
+	}
+	fields := make(map[string]xdr.ScVal, len(entries))
+	for _, entry := range entries {
+		name, ok := entry.Key.GetSym()
+		if !ok {
+			return nil, errors.Errorf("invalid struct %s, expected symbol field names, got %s", udt.Name, entry.Key.Type)
+		}
+		fields[string(name)] = entry.Val
+	}
+	decoded := make(map[string]interface{}, len(udt.Fields))
+	for _, field := range udt.Fields {
+		value, ok := fields[field.Name]
+		if !ok {
+			return nil, errors.Errorf("missing field %s of struct %s", field.Name, udt.Name)
+		}
+		if decoded[field.Name], err = s.DecodeValue(value, field.Type); err != nil {
+			return nil, errors.Wrapf(err, "invalid field %s of struct %s", field.Name, udt.Name)
+		}
+	}
+	return decoded, nil
+}
+
+func (s *Spec) decodeUnion(val xdr.ScVal, udt xdr.ScSpecUdtUnionV0) (interface{}, error) {
+	elements, err := vecElements(val)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid union %s", udt.Name)
+	}
+	if len(elements) == 0 {
+		return nil, errors.Errorf("invalid union %s, missing case name", udt.Name)
+	}
+	name, ok := elements[0].GetSym()
+	if !ok {
+		return nil, errors.Errorf("invalid union %s, expected symbol case name, got %s", udt.Name, elements[0].Type)
+	}
+	for _, c := range udt.Cases {
+		switch c.Kind {
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0:
+			if c.MustVoidCase().Name != string(name) {
+				continue
+			}
+			if len(elements) != 1 {
+				return nil, errors.Errorf("case %s of union %s has no values, got %d", name, udt.Name, len(elements)-1)
+			}
+			return string(name), nil
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0:
+			tupleCase := c.MustTupleCase()
+			if tupleCase.Name != string(name) {
+				continue
+			}
+			values := elements[1:]
+			decoded, err := s.decodeTuple(xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: newScVec(values)}, tupleCase.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid case %s of union %s", name, udt.Name)
+			}
+			return map[string]interface{}{string(name): decoded}, nil
+		}
+	}
+	return nil, errors.Errorf("unknown case %s of union %s", name, udt.Name)
+}
+
+// decodeVal decodes a value without type information, s may be nil.
+func (s *Spec) decodeVal(val xdr.ScVal) (interface{}, error) {
+	switch val.Type {
+	case xdr.ScValTypeScvBool:
+		return *val.B, nil
+	case xdr.ScValTypeScvVoid:
+		return nil, nil
+	case xdr.ScValTypeScvError:
+		return s.decodeError(*val.Error)
+	case xdr.ScValTypeScvU32:
+		return uint32(*val.U32), nil
+	case xdr.ScValTypeScvI32:
+		return int32(*val.I32), nil
+	case xdr.ScValTypeScvU64:
+		return strconv.FormatUint(uint64(*val.U64), 10), nil
+	case xdr.ScValTypeScvI64:
+		return strconv.FormatInt(int64(*val.I64), 10), nil
+	case xdr.ScValTypeScvTimepoint:
+		return strconv.FormatUint(uint64(*val.Timepoint), 10), nil
+	case xdr.ScValTypeScvDuration:
+		return strconv.FormatUint(uint64(*val.Duration), 10), nil
+	case xdr.ScValTypeScvU128, xdr.ScValTypeScvI128, xdr.ScValTypeScvU256, xdr.ScValTypeScvI256:
+		return bigIntFromScVal(val).String(), nil
+	case xdr.ScValTypeScvBytes:
+		return hex.EncodeToString(*val.Bytes), nil
+	case xdr.ScValTypeScvString:
+		return string(*val.Str), nil
+	case xdr.ScValTypeScvSymbol:
+		return string(*val.Sym), nil
+	case xdr.ScValTypeScvAddress:
+		return val.Address.String()
+	case xdr.ScValTypeScvVec:
+		elements, err := vecElements(val)
+		if err != nil {
+			return nil, err
+		}
+		decoded := make([]interface{}, 0, len(elements))
+		for i, element := range elements {
+			value, err := s.decodeVal(element)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid element %d", i)
+			}
+			decoded = append(decoded, value)
+		}
+		return decoded, nil
+	case xdr.ScValTypeScvMap:
+		entries, err := mapEntries(val)
+		if err != nil {
+			return nil, err
+		}
+		return s.decodeValMap(entries)
+	case xdr.ScValTypeScvContractInstance:
+		var executable interface{} = "stellar_asset"
+		if val.Instance.Executable.Type == xdr.ContractExecutableTypeContractExecutableWasm {
+			executable = map[string]interface{}{"wasm": val.Instance.Executable.MustWasmHash().HexString()}
+		}
+		var storage xdr.ScMap
+		if val.Instance.Storage != nil {
+			storage = *val.Instance.Storage
+		}
+		decodedStorage, err := s.decodeValMap(storage)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid contract instance storage")
+		}
+		return map[string]interface{}{"executable": executable, "storage": decodedStorage}, nil
+	case xdr.ScValTypeScvLedgerKeyContractInstance:
+		return "ledger_key_contract_instance", nil
+	case xdr.ScValTypeScvLedgerKeyNonce:
+		return map[string]interface{}{"nonce": strconv.FormatInt(int64(val.NonceKey.Nonce), 10)}, nil
+	}
+	return nil, errors.Errorf("unsupported value type %s", val.Type)
+}
+
+func (s *Spec) decodeValMap(entries xdr.ScMap) (interface{}, error) {
+	keys := make([]interface{}, 0, len(entries))
+	values := make([]interface{}, 0, len(entries))
+	isObject := true
+	// keys of different types may have the same string form
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		key, err := s.decodeVal(entry.Key)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid map key")
+		}
+		value, err := s.decodeVal(entry.Val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of map key %v", key)
+		}
+		if str, ok := key.(string); ok && isObjectKey(entry.Key.Type) && !seen[str] {
+			seen[str] = true
+		} else {
+			isObject = false
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	return mapJSON(keys, values, isObject), nil
+}
+
+// mapJSON returns the JSON representation of a map, an object if isObject is
+// true and the keys are strings, or an array of key value pairs.
+func mapJSON(keys, values []interface{}, isObject bool) interface{} {
+	if isObject {
+		object := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			object[key.(string)] = values[i]
+		}
+		return object
+	}
+	pairs := make([]interface{}, 0, len(keys))
+	for i, key := range keys {
+		pairs = append(pairs, map[string]interface{}{"key": key, "value": values[i]})
+	}
+	return pairs
+}
+
+func (s *Spec) decodeError(scError xdr.ScError) (interface{}, error) {
+	if scError.Type == xdr.ScErrorTypeSceContract {
+		decoded := map[string]interface{}{"contract": uint32(*scError.ContractCode)}
+		if name, ok := s.errorName(*scError.ContractCode); ok {
+			decoded["name"] = name
+		}
+		return decoded, nil
+	}
+	if scError.Code == nil {
+		return nil, errors.Errorf("invalid error of type %s, missing code", scError.Type)
+	}
+	return map[string]interface{}{
+		"type": strings.TrimPrefix(scError.Type.String(), "ScErrorType"),
+		"code": strings.TrimPrefix(scError.Code.String(), "ScErrorCode"),
+	}, nil
+}
+
+// errorName returns the name of a contract error code if exactly one error
+// enum case of the spec has that code.
+func (s *Spec) errorName(code xdr.Uint32) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	var names []string
+	for _, entry := range s.entries {
+		udt, ok := entry.GetUdtErrorEnumV0()
+		if !ok {
+			continue
+		}
+		for _, c := range udt.Cases {
+			if c.Value == code {
+				names = append(names, c.Name)
+			}
+		}
+	}
+	if len(names) != 1 {
+		return "", false
+	}
+	return names[0], true
+}
+
+func newScVec(values xdr.ScVec) **xdr.ScVec {
+	vec := &values
+	return &vec
+}
@@ -0,0 +1,601 @@
+package contractspec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+const maxSymbolLength = 32
+
+// EncodeArgs encodes the arguments of an invocation of function from an
+// object keyed by argument name. Missing optional arguments are encoded as
+// void.
+func (s *Spec) EncodeArgs(function string, args map[string]interface{}) ([]xdr.ScVal, error) {
+	spec, err := s.mustFunction(function)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make(map[string]bool, len(spec.Inputs))
+	for _, input := range spec.Inputs {
+		inputs[input.Name] = true
+	}
+	for name := range args {
+		if !inputs[name] {
+			return nil, errors.Errorf("function %s has no argument %s", function, name)
+		}
+	}
+
+	encoded := make([]xdr.ScVal, 0, len(spec.Inputs))
+	for _, input := range spec.Inputs {
+		value, ok := args[input.Name]
+		if !ok && input.Type.Type != xdr.ScSpecTypeScSpecTypeOption {
+			return nil, errors.Errorf("missing argument %s of function %s", input.Name, function)
+		}
+		val, err := s.EncodeValue(value, input.Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not encode argument %s of function %s", input.Name, function)
+		}
+		encoded = append(encoded, val)
+	}
+	return encoded, nil
+}
+
+// EncodeArgsJSON encodes the arguments of an invocation of function from a
+// JSON object keyed by argument name.
+func (s *Spec) EncodeArgsJSON(function string, args []byte) ([]xdr.ScVal, error) {
+	var decoded map[string]interface{}
+	if err := unmarshalJSON(args, &decoded); err != nil {
+		return nil, err
+	}
+	return s.EncodeArgs(function, decoded)
+}
+
+// EncodeValueJSON encodes a JSON value of the given type.
+func (s *Spec) EncodeValueJSON(value []byte, typ xdr.ScSpecTypeDef) (xdr.ScVal, error) {
+	var decoded interface{}
+	if err := unmarshalJSON(value, &decoded); err != nil {
+		return xdr.ScVal{}, err
+	}
+	return s.EncodeValue(decoded, typ)
+}
+
+// unmarshalJSON decodes numbers as json.Number so that integers do not lose
+// precision.
+func unmarshalJSON(data []byte, dest interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(dest); err != nil {
+		return errors.Wrap(err, "invalid json")
+	}
+	return nil
+}
+
+// EncodeValue encodes a value of the given type. value is either the JSON
+// representation of the type, as returned by DecodeValue or decoded by
+// encoding/json, or an xdr.ScVal which is returned unchanged. Values of type
+// val must be xdr.ScVal values as their type cannot be inferred.
+func (s *Spec) EncodeValue(value interface{}, typ xdr.ScSpecTypeDef) (xdr.ScVal, error) {
+	if val, ok := value.(xdr.ScVal); ok {
+		return val, nil
+	}
+
+	if _, ok := integerTypes[typ.Type]; ok {
+		v, err := toBigInt(value)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		return integerScVal(v, typ.Type)
+	}
+
+	switch typ.Type {
+	case xdr.ScSpecTypeScSpecTypeVal:
+		return xdr.ScVal{}, errors.New("values of type val must be xdr.ScVal values")
+	case xdr.ScSpecTypeScSpecTypeBool:
+		b, ok := value.(bool)
+		if !ok {
+			return xdr.ScVal{}, errors.Errorf("expected bool, got %T", value)
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &b}, nil
+	case xdr.ScSpecTypeScSpecTypeVoid:
+		if value != nil {
+			return xdr.ScVal{}, errors.Errorf("expected null, got %T", value)
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvVoid}, nil
+	case xdr.ScSpecTypeScSpecTypeError:
+		scError, err := encodeError(value)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvError, Error: &scError}, nil
+	case xdr.ScSpecTypeScSpecTypeBytes, xdr.ScSpecTypeScSpecTypeBytesN:
+		str, err := toString(value)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		raw, err := hex.DecodeString(str)
+		if err != nil {
+			return xdr.ScVal{}, errors.Wrap(err, "invalid hex string")
+		}
+		if bytesN, ok := typ.GetBytesN(); ok && len(raw) != int(bytesN.N) {
+			return xdr.ScVal{}, errors.Errorf("expected %d bytes, got %d", bytesN.N, len(raw))
+		}
+		b := xdr.ScBytes(raw)
+		return xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &b}, nil
+	case xdr.ScSpecTypeScSpecTypeString:
+		str, err := toString(value)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		scString := xdr.ScString(str)
+		return xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &scString}, nil
+	case xdr.ScSpecTypeScSpecTypeSymbol:
+		str, err := toString(value)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		return symbolScVal(str)
+	case xdr.ScSpecTypeScSpecTypeAddress:
+		str, err := toString(value)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		address, err := scAddress(str)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvAddress, Address: &address}, nil
+	case xdr.ScSpecTypeScSpecTypeOption:
+		if value == nil {
+			return xdr.ScVal{Type: xdr.ScValTypeScvVoid}, nil
+		}
+		return s.EncodeValue(value, typ.MustOption().ValueType)
+	case xdr.ScSpecTypeScSpecTypeResult:
+		object, ok := value.(map[string]interface{})
+		if !ok || len(object) != 1 {
+			return xdr.ScVal{}, errors.New(`expected {"ok": value} or {"error": error}`)
+		}
+		if okValue, isOk := object["ok"]; isOk {
+			return s.EncodeValue(okValue, typ.MustResult().OkType)
+		}
+		if errValue, isErr := object["error"]; isErr {
+			return s.EncodeValue(errValue, typ.MustResult().ErrorType)
+		}
+		return xdr.ScVal{}, errors.New(`expected {"ok": value} or {"error": error}`)
+	case xdr.ScSpecTypeScSpecTypeVec:
+		elements, ok := value.([]interface{})
+		if !ok {
+			return xdr.ScVal{}, errors.Errorf("expected array, got %T", value)
+		}
+		vec := make(xdr.ScVec, 0, len(elements))
+		for i, element := range elements {
+			val, err := s.EncodeValue(element, typ.MustVec().ElementType)
+			if err != nil {
+				return xdr.ScVal{}, errors.Wrapf(err, "invalid element %d", i)
+			}
+			vec = append(vec, val)
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: newScVec(vec)}, nil
+	case xdr.ScSpecTypeScSpecTypeMap:
+		return s.encodeMap(value, typ.MustMap())
+	case xdr.ScSpecTypeScSpecTypeTuple:
+		vec, err := s.encodeTuple(value, typ.MustTuple().ValueTypes)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: newScVec(vec)}, nil
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		return s.encodeUdt(value, typ)
+	}
+	return xdr.ScVal{}, errors.Errorf("unsupported type %s", typ.Type)
+}
+
+func toString(value interface{}) (string, error) {
+	str, ok := value.(string)
+	if !ok {
+		return "", errors.Errorf("expected string, got %T", value)
+	}
+	return str, nil
+}
+
+func symbolScVal(str string) (xdr.ScVal, error) {
+	if len(str) > maxSymbolLength {
+		return xdr.ScVal{}, errors.Errorf("symbol %q is longer than %d characters", str, maxSymbolLength)
+	}
+	for _, c := range str {
+		if !(c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')) {
+			return xdr.ScVal{}, errors.Errorf("symbol %q has invalid character %q", str, c)
+		}
+	}
+	sym := xdr.ScSymbol(str)
+	return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}, nil
+}
+
+func scAddress(str string) (xdr.ScAddress, error) {
+	version, err := strkey.Version(str)
+	if err != nil {
+		return xdr.ScAddress{}, errors.Wrapf(err, "invalid address %q", str)
+	}
+	switch version {
+	case strkey.VersionByteAccountID:
+		accountID, err := xdr.AddressToAccountId(str)
+		if err != nil {
+			return xdr.ScAddress{}, err
+		}
+		return xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &accountID}, nil
+	case strkey.VersionByteContract:
+		raw, err := strkey.Decode(strkey.VersionByteContract, str)
+		if err != nil {
+			return xdr.ScAddress{}, err
+		}
+		var contractID xdr.Hash
+		copy(contractID[:], raw)
+		return xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID}, nil
+	}
+	return xdr.ScAddress{}, errors.Errorf("%q is not an account or contract address", str)
+}
+
+var (
+	scErrorTypes = map[string]xdr.ScErrorType{}
+	scErrorCodes = map[string]xdr.ScErrorCode{}
+)
+
+func init() {
+	for i := int32(0); i < 32; i++ {
+		if errorType := xdr.ScErrorType(i); errorType.ValidEnum(i) {
+			scErrorTypes[strings.TrimPrefix(errorType.String(), "ScErrorType")] = errorType
+		}
+		if code := xdr.ScErrorCode(i); code.ValidEnum(i) {
+			scErrorCodes[strings.TrimPrefix(code.String(), "ScErrorCode")] = code
+		}
+	}
+}
+
+func encodeError(value interface{}) (xdr.ScError, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return xdr.ScError{}, errors.Errorf("expected error object, got %T", value)
+	}
+	if contract, ok := object["contract"]; ok {
+		v, err := toBigInt(contract)
+		if err != nil {
+			return xdr.ScError{}, errors.Wrap(err, "invalid contract error code")
+		}
+		code, err := integerScVal(v, xdr.ScSpecTypeScSpecTypeU32)
+		if err != nil {
+			return xdr.ScError{}, errors.Wrap(err, "invalid contract error code")
+		}
+		return xdr.ScError{Type: xdr.ScErrorTypeSceContract, ContractCode: code.U32}, nil
+	}
+
+	typeName, _ := object["type"].(string)
+	errorType, ok := scErrorTypes[typeName]
+	if !ok || errorType == xdr.ScErrorTypeSceContract {
+		return xdr.ScError{}, errors.Errorf("invalid error type %v", object["type"])
+	}
+	codeName, _ := object["code"].(string)
+	code, ok := scErrorCodes[codeName]
+	if !ok {
+		return xdr.ScError{}, errors.Errorf("invalid error code %v", object["code"])
+	}
+	return xdr.ScError{Type: errorType, Code: &code}, nil
+}
+
+func (s *Spec) encodeTuple(value interface{}, types []xdr.ScSpecTypeDef) (xdr.ScVec, error) {
+	elements, ok := value.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected array, got %T", value)
+	}
+	if len(elements) != len(types) {
+		return nil, errors.Errorf("expected %d elements, got %d", len(types), len(elements))
+	}
+	vec := make(xdr.ScVec, 0, len(elements))
+	for i, element := range elements {
+		val, err := s.EncodeValue(element, types[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid element %d", i)
+		}
+		vec = append(vec, val)
+	}
+	return vec, nil
+}
+
+func (s *Spec) encodeMap(value interface{}, typ xdr.ScSpecTypeMap) (xdr.ScVal, error) {
+	var entries xdr.ScMap
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			entry, err := s.encodeMapEntry(key, value, typ)
+			if err != nil {
+				return xdr.ScVal{}, err
+			}
+			entries = append(entries, entry)
+		}
+	case []interface{}:
+		for i, pair := range v {
+			object, ok := pair.(map[string]interface{})
+			key, hasKey := object["key"]
+			value, hasValue := object["value"]
+			if !ok || len(object) != 2 || !hasKey || !hasValue {
+				return xdr.ScVal{}, errors.Errorf(`invalid map entry %d, expected {"key": key, "value": value}`, i)
+			}
+			entry, err := s.encodeMapEntry(key, value, typ)
+			if err != nil {
+				return xdr.ScVal{}, err
+			}
+			entries = append(entries, entry)
+		}
+	default:
+		return xdr.ScVal{}, errors.Errorf("expected object or array, got %T", value)
+	}
+
+	if err := sortScMap(entries); err != nil {
+		return xdr.ScVal{}, err
+	}
+	return newScMapVal(entries), nil
+}
+
+func (s *Spec) encodeMapEntry(key, value interface{}, typ xdr.ScSpecTypeMap) (xdr.ScMapEntry, error) {
+	keyVal, err := s.EncodeValue(key, typ.KeyType)
+	if err != nil {
+		return xdr.ScMapEntry{}, errors.Wrap(err, "invalid map key")
+	}
+	val, err := s.EncodeValue(value, typ.ValueType)
+	if err != nil {
+		return xdr.ScMapEntry{}, errors.Wrapf(err, "invalid value of map key %v", key)
+	}
+	return xdr.ScMapEntry{Key: keyVal, Val: val}, nil
+}
+
+func newScMapVal(entries xdr.ScMap) xdr.ScVal {
+	m := &entries
+	return xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &m}
+}
+
+func (s *Spec) encodeUdt(value interface{}, typ xdr.ScSpecTypeDef) (xdr.ScVal, error) {
+	entry, err := s.udt(typ)
+	if err != nil {
+		return xdr.ScVal{}, err
+	}
+	switch entry.Kind {
+	case xdr.ScSpecEntryKindScSpecEntryUdtStructV0:
+		return s.encodeStruct(value, entry.MustUdtStructV0())
+	case xdr.ScSpecEntryKindScSpecEntryUdtUnionV0:
+		return s.encodeUnion(value, entry.MustUdtUnionV0())
+	case xdr.ScSpecEntryKindScSpecEntryUdtEnumV0:
+		udt := entry.MustUdtEnumV0()
+		name, err := toString(value)
+		if err != nil {
+			return xdr.ScVal{}, errors.Wrapf(err, "invalid enum %s", udt.Name)
+		}
+		for _, c := range udt.Cases {
+			if c.Name == name {
+				v := c.Value
+				return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &v}, nil
+			}
+		}
+		return xdr.ScVal{}, errors.Errorf("unknown case %s of enum %s", name, udt.Name)
+	case xdr.ScSpecEntryKindScSpecEntryUdtErrorEnumV0:
+		udt := entry.MustUdtErrorEnumV0()
+		name, err := toString(value)
+		if err != nil {
+			return xdr.ScVal{}, errors.Wrapf(err, "invalid error enum %s", udt.Name)
+		}
+		for _, c := range udt.Cases {
+			if c.Name == name {
+				code := c.Value
+				return xdr.ScVal{
+					Type:  xdr.ScValTypeScvError,
+					Error: &xdr.ScError{Type: xdr.ScErrorTypeSceContract, ContractCode: &code},
+				}, nil
+			}
+		}
+		return xdr.ScVal{}, errors.Errorf("unknown case %s of error enum %s", name, udt.Name)
+	}
+	return xdr.ScVal{}, errors.Errorf("%s is not a type", typ.MustUdt().Name)
+}
+
+func (s *Spec) encodeStruct(value interface{}, udt xdr.ScSpecUdtStructV0) (xdr.ScVal, error) {
+	if isTupleStruct(udt) {
+		types := make([]xdr.ScSpecTypeDef, 0, len(udt.Fields))
+		for _, field := range udt.Fields {
+			types = append(types, field.Type)
+		}
+		vec, err := s.encodeTuple(value, types)
+		if err != nil {
+			return xdr.ScVal{}, errors.Wrapf(err, "invalid struct %s", udt.Name)
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: newScVec(vec)}, nil
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return xdr.ScVal{}, errors.Errorf("invalid struct %s, expected object, got %T", udt.Name, value)
+	}
+	if len(object) != len(udt.Fields) {
+		return xdr.ScVal{}, errors.Errorf("struct %s has %d fields, got %d", udt.Name, len(udt.Fields), len(object))
+	}
+	entries := make(xdr.ScMap, 0, len(udt.Fields))
+	for _, field := range udt.Fields {
+		fieldValue, ok := object[field.Name]
+		if !ok {
+			return xdr.ScVal{}, errors.Errorf("missing field %s of struct %s", field.Name, udt.Name)
+		}
+		val, err := s.EncodeValue(fieldValue, field.Type)
+		if err != nil {
+			return xdr.ScVal{}, errors.Wrapf(err, "invalid field %s of struct %s", field.Name, udt.Name)
+		}
+		key, err := symbolScVal(field.Name)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		entries = append(entries, xdr.ScMapEntry{Key: key, Val: val})
+	}
+	if err := sortScMap(entries); err != nil {
+		return xdr.ScVal{}, err
+	}
+	return newScMapVal(entries), nil
+}
+
+func (s *Spec) encodeUnion(value interface{}, udt xdr.ScSpecUdtUnionV0) (xdr.ScVal, error) {
+	name, values, isVoid := "", interface{}(nil), true
+	switch v := value.(type) {
+	case string:
+		name = v
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return xdr.ScVal{}, errors.Errorf("invalid union %s, expected a single case", udt.Name)
+		}
+		for caseName, caseValues := range v {
+			name, values = caseName, caseValues
+		}
+		isVoid = false
+	default:
+		return xdr.ScVal{}, errors.Errorf("invalid union %s, expected string or object, got %T", udt.Name, value)
+	}
+
+	nameVal, err := symbolScVal(name)
+	if err != nil {
+		return xdr.ScVal{}, errors.Wrapf(err, "invalid union %s", udt.Name)
+	}
+	for _, c := range udt.Cases {
+		switch c.Kind {
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0:
+			if c.MustVoidCase().Name != name {
+				continue
+			}
+			if !isVoid {
+				return xdr.ScVal{}, errors.Errorf("case %s of union %s has no values", name, udt.Name)
+			}
+			return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: newScVec(xdr.ScVec{nameVal})}, nil
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0:
+			tupleCase := c.MustTupleCase()
+			if tupleCase.Name != name {
+				continue
+			}
+			if isVoid {
+				return xdr.ScVal{}, errors.Errorf("case %s of union %s has values", name, udt.Name)
+			}
+			vec, err := s.encodeTuple(values, tupleCase.Type)
+			if err != nil {
+				return xdr.ScVal{}, errors.Wrapf(err, "invalid case %s of union %s", name, udt.Name)
+			}
+			return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: newScVec(append(xdr.ScVec{nameVal}, vec...))}, nil
+		}
+	}
+	return xdr.ScVal{}, errors.Errorf("unknown case %s of union %s", name, udt.Name)
+}
+
+// sortScMap sorts the map entries by key, as required by the Soroban host,
+// and rejects duplicate keys.
+func sortScMap(entries xdr.ScMap) error {
+	var err error
+	sort.SliceStable(entries, func(i, j int) bool {
+		cmp, cmpErr := compareScVal(entries[i].Key, entries[j].Key)
+		if cmpErr != nil && err == nil {
+			err = cmpErr
+		}
+		return cmp < 0
+	})
+	if err != nil {
+		return err
+	}
+	for i := 1; i < len(entries); i++ {
+		if cmp, _ := compareScVal(entries[i-1].Key, entries[i].Key); cmp == 0 {
+			return errors.Errorf("duplicate map key %s", entries[i].Key)
+		}
+	}
+	return nil
+}
+
+// compareScVal orders map keys the same way as the Soroban host: first by
+// type and then by value. Only the types which can be map keys of contract
+// specs without a vec or map are supported.
+func compareScVal(a, b xdr.ScVal) (int, error) {
+	if a.Type != b.Type {
+		if a.Type < b.Type {
+			return -1, nil
+		}
+		return 1, nil
+	}
+	switch a.Type {
+	case xdr.ScValTypeScvVoid:
+		return 0, nil
+	case xdr.ScValTypeScvBool:
+		return compareInts(boolToInt(*a.B), boolToInt(*b.B)), nil
+	case xdr.ScValTypeScvU32:
+		return compareInts(int64(*a.U32), int64(*b.U32)), nil
+	case xdr.ScValTypeScvI32:
+		return compareInts(int64(*a.I32), int64(*b.I32)), nil
+	case xdr.ScValTypeScvI64:
+		return compareInts(int64(*a.I64), int64(*b.I64)), nil
+	case xdr.ScValTypeScvU64:
+		return compareUints(uint64(*a.U64), uint64(*b.U64)), nil
+	case xdr.ScValTypeScvTimepoint:
+		return compareUints(uint64(*a.Timepoint), uint64(*b.Timepoint)), nil
+	case xdr.ScValTypeScvDuration:
+		return compareUints(uint64(*a.Duration), uint64(*b.Duration)), nil
+	case xdr.ScValTypeScvU128, xdr.ScValTypeScvI128, xdr.ScValTypeScvU256, xdr.ScValTypeScvI256:
+		return bigIntFromScVal(a).Cmp(bigIntFromScVal(b)), nil
+	case xdr.ScValTypeScvBytes:
+		return bytes.Compare(*a.Bytes, *b.Bytes), nil
+	case xdr.ScValTypeScvString:
+		return strings.Compare(string(*a.Str), string(*b.Str)), nil
+	case xdr.ScValTypeScvSymbol:
+		return strings.Compare(string(*a.Sym), string(*b.Sym)), nil
+	case xdr.ScValTypeScvAddress:
+		rawA, err := a.Address.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		rawB, err := b.Address.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(rawA, rawB), nil
+	case xdr.ScValTypeScvError:
+		rawA, err := a.Error.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		rawB, err := b.Error.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(rawA, rawB), nil
+	}
+	return 0, errors.Errorf("unsupported map key type %s", a.Type)
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package contractspec
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/xdr"
+)
+
+var typeNames = map[xdr.ScSpecType]string{
+	xdr.ScSpecTypeScSpecTypeVal:       "Val",
+	xdr.ScSpecTypeScSpecTypeBool:      "bool",
+	xdr.ScSpecTypeScSpecTypeVoid:      "()",
+	xdr.ScSpecTypeScSpecTypeError:     "Error",
+	xdr.ScSpecTypeScSpecTypeU32:       "u32",
+	xdr.ScSpecTypeScSpecTypeI32:       "i32",
+	xdr.ScSpecTypeScSpecTypeU64:       "u64",
+	xdr.ScSpecTypeScSpecTypeI64:       "i64",
+	xdr.ScSpecTypeScSpecTypeTimepoint: "Timepoint",
+	xdr.ScSpecTypeScSpecTypeDuration:  "Duration",
+	xdr.ScSpecTypeScSpecTypeU128:      "u128",
+	xdr.ScSpecTypeScSpecTypeI128:      "i128",
+	xdr.ScSpecTypeScSpecTypeU256:      "U256",
+	xdr.ScSpecTypeScSpecTypeI256:      "I256",
+	xdr.ScSpecTypeScSpecTypeBytes:     "Bytes",
+	xdr.ScSpecTypeScSpecTypeString:    "String",
+	xdr.ScSpecTypeScSpecTypeSymbol:    "Symbol",
+	xdr.ScSpecTypeScSpecTypeAddress:   "Address",
+}
+
+// TypeName returns the name of a type as written in the Rust contract SDK,
+// e.g. "Vec<Address>" or "Result<u64, Error>".
+func TypeName(typ xdr.ScSpecTypeDef) string {
+	if name, ok := typeNames[typ.Type]; ok {
+		return name
+	}
+	switch typ.Type {
+	case xdr.ScSpecTypeScSpecTypeOption:
+		return fmt.Sprintf("Option<%s>", TypeName(typ.MustOption().ValueType))
+	case xdr.ScSpecTypeScSpecTypeResult:
+		result := typ.MustResult()
+		return fmt.Sprintf("Result<%s, %s>", TypeName(result.OkType), TypeName(result.ErrorType))
+	case xdr.ScSpecTypeScSpecTypeVec:
+		return fmt.Sprintf("Vec<%s>", TypeName(typ.MustVec().ElementType))
+	case xdr.ScSpecTypeScSpecTypeMap:
+		m := typ.MustMap()
+		return fmt.Sprintf("Map<%s, %s>", TypeName(m.KeyType), TypeName(m.ValueType))
+	case xdr.ScSpecTypeScSpecTypeTuple:
+		names := make([]string, 0, len(typ.MustTuple().ValueTypes))
+		for _, valueType := range typ.MustTuple().ValueTypes {
+			names = append(names, TypeName(valueType))
+		}
+		return "(" + strings.Join(names, ", ") + ")"
+	case xdr.ScSpecTypeScSpecTypeBytesN:
+		return fmt.Sprintf("BytesN<%d>", typ.MustBytesN().N)
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		return typ.MustUdt().Name
+	}
+	return typ.Type.String()
+}
+
+// FunctionSignature returns the signature of a function, e.g.
+// "transfer(from: Address, to: Address, amount: i128)".
+func FunctionSignature(function xdr.ScSpecFunctionV0) string {
+	inputs := make([]string, 0, len(function.Inputs))
+	for _, input := range function.Inputs {
+		inputs = append(inputs, input.Name+": "+TypeName(input.Type))
+	}
+	signature := fmt.Sprintf("%s(%s)", function.Name, strings.Join(inputs, ", "))
+	if len(function.Outputs) > 0 {
+		signature += " -> " + TypeName(function.Outputs[0])
+	}
+	return signature
+}
+
+// FunctionSignatures returns the signatures of the functions of the contract,
+// in the order of the spec.
+func (s *Spec) FunctionSignatures() []string {
+	signatures := []string{}
+	for _, entry := range s.entries {
+		if function, ok := entry.GetFunctionV0(); ok {
+			signatures = append(signatures, FunctionSignature(function))
+		}
+	}
+	return signatures
+}
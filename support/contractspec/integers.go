@@ -0,0 +1,176 @@
+package contractspec
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+type integerBounds struct {
+	bits   uint
+	signed bool
+	min    *big.Int
+	max    *big.Int
+}
+
+func newIntegerBounds(bits uint, signed bool) integerBounds {
+	one := big.NewInt(1)
+	if signed {
+		return integerBounds{
+			bits:   bits,
+			signed: true,
+			min:    new(big.Int).Neg(new(big.Int).Lsh(one, bits-1)),
+			max:    new(big.Int).Sub(new(big.Int).Lsh(one, bits-1), one),
+		}
+	}
+	return integerBounds{
+		bits: bits,
+		min:  new(big.Int),
+		max:  new(big.Int).Sub(new(big.Int).Lsh(one, bits), one),
+	}
+}
+
+// integerTypes are the bounds of the integer spec types.
+var integerTypes = map[xdr.ScSpecType]integerBounds{
+	xdr.ScSpecTypeScSpecTypeU32:       newIntegerBounds(32, false),
+	xdr.ScSpecTypeScSpecTypeI32:       newIntegerBounds(32, true),
+	xdr.ScSpecTypeScSpecTypeU64:       newIntegerBounds(64, false),
+	xdr.ScSpecTypeScSpecTypeI64:       newIntegerBounds(64, true),
+	xdr.ScSpecTypeScSpecTypeTimepoint: newIntegerBounds(64, false),
+	xdr.ScSpecTypeScSpecTypeDuration:  newIntegerBounds(64, false),
+	xdr.ScSpecTypeScSpecTypeU128:      newIntegerBounds(128, false),
+	xdr.ScSpecTypeScSpecTypeI128:      newIntegerBounds(128, true),
+	xdr.ScSpecTypeScSpecTypeU256:      newIntegerBounds(256, false),
+	xdr.ScSpecTypeScSpecTypeI256:      newIntegerBounds(256, true),
+}
+
+// toBigInt converts the JSON representations of an integer, numbers and
+// decimal strings, into a big.Int.
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return parseBigInt(string(v))
+	case string:
+		return parseBigInt(v)
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+			return nil, errors.Errorf("%v is not an exact integer, use a decimal string", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int32:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint32:
+		return new(big.Int).SetUint64(uint64(v)), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case *big.Int:
+		return v, nil
+	}
+	return nil, errors.Errorf("expected integer, got %T", value)
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid integer %q", s)
+	}
+	return v, nil
+}
+
+// integerScVal converts v into an ScVal of an integer spec type.
+func integerScVal(v *big.Int, typ xdr.ScSpecType) (xdr.ScVal, error) {
+	bounds := integerTypes[typ]
+	if v.Cmp(bounds.min) < 0 || v.Cmp(bounds.max) > 0 {
+		return xdr.ScVal{}, errors.Errorf("%s is out of the range of %s", v, typ)
+	}
+
+	// two's complement words, most significant first
+	unsigned := new(big.Int).Set(v)
+	if v.Sign() < 0 {
+		unsigned.Add(unsigned, new(big.Int).Lsh(big.NewInt(1), bounds.bits))
+	}
+	words := make([]uint64, (bounds.bits+63)/64)
+	mask := new(big.Int).SetUint64(math.MaxUint64)
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = new(big.Int).And(unsigned, mask).Uint64()
+		unsigned.Rsh(unsigned, 64)
+	}
+
+	switch typ {
+	case xdr.ScSpecTypeScSpecTypeU32:
+		u32 := xdr.Uint32(words[0])
+		return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u32}, nil
+	case xdr.ScSpecTypeScSpecTypeI32:
+		i32 := xdr.Int32(int32(uint32(words[0])))
+		return xdr.ScVal{Type: xdr.ScValTypeScvI32, I32: &i32}, nil
+	case xdr.ScSpecTypeScSpecTypeU64:
+		u64 := xdr.Uint64(words[0])
+		return xdr.ScVal{Type: xdr.ScValTypeScvU64, U64: &u64}, nil
+	case xdr.ScSpecTypeScSpecTypeI64:
+		i64 := xdr.Int64(int64(words[0]))
+		return xdr.ScVal{Type: xdr.ScValTypeScvI64, I64: &i64}, nil
+	case xdr.ScSpecTypeScSpecTypeTimepoint:
+		timepoint := xdr.TimePoint(words[0])
+		return xdr.ScVal{Type: xdr.ScValTypeScvTimepoint, Timepoint: &timepoint}, nil
+	case xdr.ScSpecTypeScSpecTypeDuration:
+		duration := xdr.Duration(words[0])
+		return xdr.ScVal{Type: xdr.ScValTypeScvDuration, Duration: &duration}, nil
+	case xdr.ScSpecTypeScSpecTypeU128:
+		u128 := xdr.UInt128Parts{Hi: xdr.Uint64(words[0]), Lo: xdr.Uint64(words[1])}
+		return xdr.ScVal{Type: xdr.ScValTypeScvU128, U128: &u128}, nil
+	case xdr.ScSpecTypeScSpecTypeI128:
+		i128 := xdr.Int128Parts{Hi: xdr.Int64(int64(words[0])), Lo: xdr.Uint64(words[1])}
+		return xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &i128}, nil
+	case xdr.ScSpecTypeScSpecTypeU256:
+		u256 := xdr.UInt256Parts{
+			HiHi: xdr.Uint64(words[0]), HiLo: xdr.Uint64(words[1]),
+			LoHi: xdr.Uint64(words[2]), LoLo: xdr.Uint64(words[3]),
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvU256, U256: &u256}, nil
+	case xdr.ScSpecTypeScSpecTypeI256:
+		i256 := xdr.Int256Parts{
+			HiHi: xdr.Int64(int64(words[0])), HiLo: xdr.Uint64(words[1]),
+			LoHi: xdr.Uint64(words[2]), LoLo: xdr.Uint64(words[3]),
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvI256, I256: &i256}, nil
+	}
+	return xdr.ScVal{}, errors.Errorf("%s is not an integer type", typ)
+}
+
+// bigIntFromScVal returns the value of a 128 or 256 bit integer ScVal.
+func bigIntFromScVal(val xdr.ScVal) *big.Int {
+	switch val.Type {
+	case xdr.ScValTypeScvU128:
+		return bigIntFromWords(false, uint64(val.U128.Hi), uint64(val.U128.Lo))
+	case xdr.ScValTypeScvI128:
+		return bigIntFromWords(true, uint64(val.I128.Hi), uint64(val.I128.Lo))
+	case xdr.ScValTypeScvU256:
+		u256 := val.U256
+		return bigIntFromWords(false, uint64(u256.HiHi), uint64(u256.HiLo), uint64(u256.LoHi), uint64(u256.LoLo))
+	case xdr.ScValTypeScvI256:
+		i256 := val.I256
+		return bigIntFromWords(true, uint64(i256.HiHi), uint64(i256.HiLo), uint64(i256.LoHi), uint64(i256.LoLo))
+	}
+	panic("not a 128 or 256 bit integer: " + val.Type.String())
+}
+
+// bigIntFromWords builds an integer from its 64 bit words, most significant
+// first. If signed is true the words are a two's complement representation.
+func bigIntFromWords(signed bool, words ...uint64) *big.Int {
+	result := new(big.Int)
+	for _, word := range words {
+		result.Lsh(result, 64)
+		result.Or(result, new(big.Int).SetUint64(word))
+	}
+	if signed && words[0]>>63 == 1 {
+		result.Sub(result, new(big.Int).Lsh(big.NewInt(1), uint(64*len(words))))
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+// Package contractspec parses the specs of Soroban contracts, stored in the
+// contractspecv0 custom section of their WASM code, and uses them to convert
+// the xdr.ScVal arguments, return values and events of a contract to and from
+// named, typed JSON values.
+//
+// The JSON representation of the spec types is:
+//   - bool: boolean
+//   - void: null
+//   - u32, i32: number
+//   - u64, i64, timepoint, duration, u128, i128, u256, i256: decimal string
+//   - bytes, bytesN: hex string
+//   - string, symbol: string
+//   - address: strkey (G... or C...)
+//   - error: {"contract": code} for contract errors, {"type": type, "code": code}
+//     otherwise
+//   - option: null or the value
+//   - result: {"ok": value} or {"error": error}
+//   - vec, tuple: array
+//   - map: object when the keys are strings, symbols or addresses, an array of
+//     {"key": key, "value": value} objects otherwise
+//   - struct: object keyed by field name, array for tuple structs
+//   - union: the case name for void cases, {"case name": [values]} otherwise
+//   - enum, error enum: the case name
+//   - val: the value decoded without type information, see DecodeVal
+package contractspec
+
+import (
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// Spec holds the functions and user defined types of a contract.
+type Spec struct {
+	entries   []xdr.ScSpecEntry
+	functions map[string]xdr.ScSpecFunctionV0
+	types     map[string]xdr.ScSpecEntry
+}
+
+// NewSpec creates a Spec from its entries.
+func NewSpec(entries []xdr.ScSpecEntry) (*Spec, error) {
+	spec := &Spec{
+		entries:   entries,
+		functions: map[string]xdr.ScSpecFunctionV0{},
+		types:     map[string]xdr.ScSpecEntry{},
+	}
+	for _, entry := range entries {
+		var name string
+		switch entry.Kind {
+		case xdr.ScSpecEntryKindScSpecEntryFunctionV0:
+			function := entry.MustFunctionV0()
+			if _, ok := spec.functions[string(function.Name)]; ok {
+				return nil, errors.Errorf("duplicate function %s", function.Name)
+			}
+			spec.functions[string(function.Name)] = function
+			continue
+		case xdr.ScSpecEntryKindScSpecEntryUdtStructV0:
+			name = entry.MustUdtStructV0().Name
+		case xdr.ScSpecEntryKindScSpecEntryUdtUnionV0:
+			name = entry.MustUdtUnionV0().Name
+		case xdr.ScSpecEntryKindScSpecEntryUdtEnumV0:
+			name = entry.MustUdtEnumV0().Name
+		case xdr.ScSpecEntryKindScSpecEntryUdtErrorEnumV0:
+			name = entry.MustUdtErrorEnumV0().Name
+		default:
+			return nil, errors.Errorf("unknown spec entry kind %d", entry.Kind)
+		}
+		if _, ok := spec.types[name]; ok {
+			return nil, errors.Errorf("duplicate type %s", name)
+		}
+		spec.types[name] = entry
+	}
+	return spec, nil
+}
+
+// NewSpecFromWasm creates a Spec from the contract spec section of a WASM
+// module. ErrNoSpec is returned if the module has no spec.
+func NewSpecFromWasm(wasm []byte) (*Spec, error) {
+	entries, err := ExtractSpecEntries(wasm)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpec(entries)
+}
+
+// Entries returns the entries of the spec.
+func (s *Spec) Entries() []xdr.ScSpecEntry {
+	return s.entries
+}
+
+// Function returns the spec of a function of the contract.
+func (s *Spec) Function(name string) (xdr.ScSpecFunctionV0, bool) {
+	function, ok := s.functions[name]
+	return function, ok
+}
+
+// Type returns the spec entry of a user defined type of the contract.
+func (s *Spec) Type(name string) (xdr.ScSpecEntry, bool) {
+	entry, ok := s.types[name]
+	return entry, ok
+}
+
+func (s *Spec) mustFunction(name string) (xdr.ScSpecFunctionV0, error) {
+	function, ok := s.functions[name]
+	if !ok {
+		return xdr.ScSpecFunctionV0{}, errors.Errorf("unknown function %s", name)
+	}
+	return function, nil
+}
+
+func (s *Spec) udt(typ xdr.ScSpecTypeDef) (xdr.ScSpecEntry, error) {
+	name := typ.MustUdt().Name
+	entry, ok := s.types[name]
+	if !ok {
+		return xdr.ScSpecEntry{}, errors.Errorf("unknown type %s", name)
+	}
+	return entry, nil
+}
+
+// isTupleStruct returns true if the fields of a struct are unnamed, tuple
+// structs are represented as vectors instead of maps.
+func isTupleStruct(udt xdr.ScSpecUdtStructV0) bool {
+	for _, field := range udt.Fields {
+		for _, c := range field.Name {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return len(udt.Fields) > 0
+}
@@ -0,0 +1,432 @@
+package contractspec
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	testAccount  = "GCEZWKCA5VLDNRLN3RPRJMRZOX3Z6G5CHCGSNFHEYVXM3XOJMDS674JZ"
+	testContract = "CA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQGAXE"
+)
+
+func primitive(typ xdr.ScSpecType) xdr.ScSpecTypeDef {
+	return xdr.ScSpecTypeDef{Type: typ}
+}
+
+func udt(name string) xdr.ScSpecTypeDef {
+	return xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeUdt, Udt: &xdr.ScSpecTypeUdt{Name: name}}
+}
+
+func option(typ xdr.ScSpecTypeDef) xdr.ScSpecTypeDef {
+	return xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeOption, Option: &xdr.ScSpecTypeOption{ValueType: typ}}
+}
+
+func vec(typ xdr.ScSpecTypeDef) xdr.ScSpecTypeDef {
+	return xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeVec, Vec: &xdr.ScSpecTypeVec{ElementType: typ}}
+}
+
+func mapOf(key, value xdr.ScSpecTypeDef) xdr.ScSpecTypeDef {
+	return xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeMap, Map: &xdr.ScSpecTypeMap{KeyType: key, ValueType: value}}
+}
+
+var (
+	boolType      = primitive(xdr.ScSpecTypeScSpecTypeBool)
+	u32Type       = primitive(xdr.ScSpecTypeScSpecTypeU32)
+	u64Type       = primitive(xdr.ScSpecTypeScSpecTypeU64)
+	i128Type      = primitive(xdr.ScSpecTypeScSpecTypeI128)
+	u256Type      = primitive(xdr.ScSpecTypeScSpecTypeU256)
+	i256Type      = primitive(xdr.ScSpecTypeScSpecTypeI256)
+	stringType    = primitive(xdr.ScSpecTypeScSpecTypeString)
+	symbolType    = primitive(xdr.ScSpecTypeScSpecTypeSymbol)
+	addressType   = primitive(xdr.ScSpecTypeScSpecTypeAddress)
+	timepointType = primitive(xdr.ScSpecTypeScSpecTypeTimepoint)
+	bytes4Type    = xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeBytesN, BytesN: &xdr.ScSpecTypeBytesN{N: 4}}
+	resultType    = xdr.ScSpecTypeDef{
+		Type: xdr.ScSpecTypeScSpecTypeResult,
+		Result: &xdr.ScSpecTypeResult{
+			OkType: xdr.ScSpecTypeDef{
+				Type:  xdr.ScSpecTypeScSpecTypeTuple,
+				Tuple: &xdr.ScSpecTypeTuple{ValueTypes: []xdr.ScSpecTypeDef{udt("Pair"), udt("Color")}},
+			},
+			ErrorType: udt("Error"),
+		},
+	}
+)
+
+func testSpec(t *testing.T) *Spec {
+	spec, err := NewSpec([]xdr.ScSpecEntry{
+		{
+			Kind: xdr.ScSpecEntryKindScSpecEntryUdtStructV0,
+			UdtStructV0: &xdr.ScSpecUdtStructV0{
+				Name: "Transfer",
+				Fields: []xdr.ScSpecUdtStructFieldV0{
+					{Name: "to", Type: addressType},
+					{Name: "amount", Type: i128Type},
+					{Name: "memo", Type: option(stringType)},
+				},
+			},
+		},
+		{
+			Kind: xdr.ScSpecEntryKindScSpecEntryUdtStructV0,
+			UdtStructV0: &xdr.ScSpecUdtStructV0{
+				Name: "Pair",
+				Fields: []xdr.ScSpecUdtStructFieldV0{
+					{Name: "0", Type: u32Type},
+					{Name: "1", Type: symbolType},
+				},
+			},
+		},
+		{
+			Kind: xdr.ScSpecEntryKindScSpecEntryUdtUnionV0,
+			UdtUnionV0: &xdr.ScSpecUdtUnionV0{
+				Name: "Action",
+				Cases: []xdr.ScSpecUdtUnionCaseV0{
+					{
+						Kind:     xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0,
+						VoidCase: &xdr.ScSpecUdtUnionCaseVoidV0{Name: "Stop"},
+					},
+					{
+						Kind:      xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0,
+						TupleCase: &xdr.ScSpecUdtUnionCaseTupleV0{Name: "Move", Type: []xdr.ScSpecTypeDef{udt("Pair"), u64Type}},
+					},
+				},
+			},
+		},
+		{
+			Kind: xdr.ScSpecEntryKindScSpecEntryUdtEnumV0,
+			UdtEnumV0: &xdr.ScSpecUdtEnumV0{
+				Name:  "Color",
+				Cases: []xdr.ScSpecUdtEnumCaseV0{{Name: "Red", Value: 1}, {Name: "Green", Value: 2}},
+			},
+		},
+		{
+			Kind: xdr.ScSpecEntryKindScSpecEntryUdtErrorEnumV0,
+			UdtErrorEnumV0: &xdr.ScSpecUdtErrorEnumV0{
+				Name:  "Error",
+				Cases: []xdr.ScSpecUdtErrorEnumCaseV0{{Name: "NotFound", Value: 1}, {Name: "Denied", Value: 2}},
+			},
+		},
+		{
+			Kind: xdr.ScSpecEntryKindScSpecEntryFunctionV0,
+			FunctionV0: &xdr.ScSpecFunctionV0{
+				Name: "swap",
+				Inputs: []xdr.ScSpecFunctionInputV0{
+					{Name: "from", Type: addressType},
+					{Name: "transfers", Type: vec(udt("Transfer"))},
+					{Name: "balances", Type: mapOf(addressType, i128Type)},
+					{Name: "flags", Type: mapOf(u32Type, boolType)},
+					{Name: "action", Type: udt("Action")},
+					{Name: "color", Type: udt("Color")},
+					{Name: "hash", Type: bytes4Type},
+					{Name: "supply", Type: u256Type},
+					{Name: "delta", Type: i256Type},
+					{Name: "deadline", Type: timepointType},
+					{Name: "limit", Type: option(u64Type)},
+				},
+				Outputs: []xdr.ScSpecTypeDef{resultType},
+			},
+		},
+		{
+			Kind:       xdr.ScSpecEntryKindScSpecEntryFunctionV0,
+			FunctionV0: &xdr.ScSpecFunctionV0{Name: "reset"},
+		},
+	})
+	require.NoError(t, err)
+	return spec
+}
+
+const swapArgs = `{
+	"from": "` + testAccount + `",
+	"transfers": [
+		{"to": "` + testContract + `", "amount": "-170141183460469231731687303715884105728", "memo": "rent"},
+		{"to": "` + testAccount + `", "amount": "42", "memo": null}
+	],
+	"balances": {"` + testContract + `": "1", "` + testAccount + `": "2"},
+	"flags": [{"key": 7, "value": true}, {"key": 3, "value": false}],
+	"action": {"Move": [[1, "north"], "18446744073709551615"]},
+	"color": "Green",
+	"hash": "deadbeef",
+	"supply": "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+	"delta": "-1",
+	"deadline": "1700000000",
+	"limit": null
+}`
+
+func TestArgsRoundTrip(t *testing.T) {
+	spec := testSpec(t)
+	args, err := spec.EncodeArgsJSON("swap", []byte(swapArgs))
+	require.NoError(t, err)
+	require.Len(t, args, 11)
+
+	// struct fields and map keys are sorted
+	transfers := **args[1].Vec
+	fields := **transfers[0].Map
+	assert.Equal(t, xdr.ScSymbol("amount"), *fields[0].Key.Sym)
+	assert.Equal(t, xdr.ScSymbol("memo"), *fields[1].Key.Sym)
+	assert.Equal(t, xdr.ScSymbol("to"), *fields[2].Key.Sym)
+	assert.Equal(t, xdr.Int128Parts{Hi: -1 << 63, Lo: 0}, *fields[0].Val.I128)
+	balances := **args[2].Map
+	assert.Equal(t, xdr.ScAddressTypeScAddressTypeAccount, balances[0].Key.Address.Type)
+	assert.Equal(t, xdr.ScAddressTypeScAddressTypeContract, balances[1].Key.Address.Type)
+	flags := **args[3].Map
+	assert.Equal(t, xdr.Uint32(3), *flags[0].Key.U32)
+	assert.Equal(t, xdr.Uint32(7), *flags[1].Key.U32)
+
+	// unions are vectors starting with the case name
+	action := **args[4].Vec
+	require.Len(t, action, 3)
+	assert.Equal(t, xdr.ScSymbol("Move"), *action[0].Sym)
+	assert.Len(t, **action[1].Vec, 2)
+	assert.Equal(t, xdr.Uint32(2), *args[5].U32)
+	assert.Equal(t, xdr.Int256Parts{HiHi: -1, HiLo: 1<<64 - 1, LoHi: 1<<64 - 1, LoLo: 1<<64 - 1}, *args[8].I256)
+	assert.Equal(t, xdr.ScValTypeScvVoid, args[10].Type)
+
+	decoded, err := spec.DecodeArgs("swap", args)
+	require.NoError(t, err)
+	decodedJSON, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	// maps with integer keys are sorted by key
+	expected := []byte(swapArgs)
+	var expectedArgs map[string]interface{}
+	require.NoError(t, json.Unmarshal(expected, &expectedArgs))
+	expectedArgs["flags"] = []interface{}{
+		map[string]interface{}{"key": 3, "value": false},
+		map[string]interface{}{"key": 7, "value": true},
+	}
+	expected, err = json.Marshal(expectedArgs)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(decodedJSON))
+}
+
+func TestDecodeResult(t *testing.T) {
+	spec := testSpec(t)
+
+	ok, err := spec.EncodeValueJSON([]byte(`{"ok": [[5, "x"], "Red"]}`), resultType)
+	require.NoError(t, err)
+	decoded, err := spec.DecodeResult("swap", ok)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"ok": []interface{}{[]interface{}{uint32(5), "x"}, "Red"},
+	}, decoded)
+
+	code := xdr.Uint32(2)
+	failure := xdr.ScVal{Type: xdr.ScValTypeScvError, Error: &xdr.ScError{Type: xdr.ScErrorTypeSceContract, ContractCode: &code}}
+	decoded, err = spec.DecodeResult("swap", failure)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"error": "Denied"}, decoded)
+	encoded, err := spec.EncodeValue(decoded, resultType)
+	require.NoError(t, err)
+	assert.Equal(t, failure, encoded)
+
+	decoded, err = spec.DecodeResult("reset", xdr.ScVal{Type: xdr.ScValTypeScvVoid})
+	require.NoError(t, err)
+	assert.Nil(t, decoded)
+
+	_, err = spec.DecodeResult("reset", ok)
+	assert.EqualError(t, err, "function reset returns void, got ScValTypeScvVec")
+	_, err = spec.DecodeResult("transfer", ok)
+	assert.EqualError(t, err, "unknown function transfer")
+}
+
+func TestDecodeEvent(t *testing.T) {
+	spec := testSpec(t)
+	topic := xdr.ScSymbol("transfer")
+	amount := xdr.Int128Parts{Hi: 0, Lo: 100}
+	from, err := scAddress(testAccount)
+	require.NoError(t, err)
+	code := xdr.Uint32(1)
+	event := xdr.ContractEvent{
+		Type: xdr.ContractEventTypeContract,
+		Body: xdr.ContractEventBody{
+			V: 0,
+			V0: &xdr.ContractEventV0{
+				Topics: xdr.ScVec{
+					{Type: xdr.ScValTypeScvSymbol, Sym: &topic},
+					{Type: xdr.ScValTypeScvAddress, Address: &from},
+					{Type: xdr.ScValTypeScvError, Error: &xdr.ScError{Type: xdr.ScErrorTypeSceContract, ContractCode: &code}},
+				},
+				Data: newScMapVal(xdr.ScMap{
+					{Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &topic}, Val: xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &amount}},
+				}),
+			},
+		},
+	}
+
+	decoded, err := DecodeEvent(event)
+	require.NoError(t, err)
+	assert.Equal(t, Event{
+		Topics: []interface{}{"transfer", testAccount, map[string]interface{}{"contract": uint32(1)}},
+		Data:   map[string]interface{}{"transfer": "100"},
+	}, decoded)
+
+	decoded, err = spec.DecodeEvent(event)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"contract": uint32(1), "name": "NotFound"}, decoded.Topics[2])
+}
+
+func TestDecodeVal(t *testing.T) {
+	sym := xdr.ScSymbol("a")
+	str := xdr.ScString("a")
+	u32 := xdr.Uint32(1)
+	u64 := xdr.Uint64(1 << 63)
+	code := xdr.ScErrorCodeScecArithDomain
+
+	for _, testCase := range []struct {
+		name     string
+		val      xdr.ScVal
+		expected interface{}
+	}{
+		{"u64", xdr.ScVal{Type: xdr.ScValTypeScvU64, U64: &u64}, "9223372036854775808"},
+		{
+			"host error",
+			xdr.ScVal{Type: xdr.ScValTypeScvError, Error: &xdr.ScError{Type: xdr.ScErrorTypeSceWasmVm, Code: &code}},
+			map[string]interface{}{"type": "SceWasmVm", "code": "ScecArithDomain"},
+		},
+		{
+			"map with integer keys",
+			newScMapVal(xdr.ScMap{{Key: xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u32}, Val: xdr.ScVal{Type: xdr.ScValTypeScvVoid}}}),
+			[]interface{}{map[string]interface{}{"key": uint32(1), "value": nil}},
+		},
+		{
+			"map with colliding keys",
+			newScMapVal(xdr.ScMap{
+				{Key: xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &str}, Val: xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u32}},
+				{Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}, Val: xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u32}},
+			}),
+			[]interface{}{
+				map[string]interface{}{"key": "a", "value": uint32(1)},
+				map[string]interface{}{"key": "a", "value": uint32(1)},
+			},
+		},
+		{"ledger key", xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance}, "ledger_key_contract_instance"},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			decoded, err := DecodeVal(testCase.val)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, decoded)
+		})
+	}
+
+	hostError, err := (&Spec{}).EncodeValue(map[string]interface{}{"type": "SceWasmVm", "code": "ScecArithDomain"}, primitive(xdr.ScSpecTypeScSpecTypeError))
+	require.NoError(t, err)
+	assert.Equal(t, xdr.ScErrorTypeSceWasmVm, hostError.Error.Type)
+	assert.Equal(t, code, *hostError.Error.Code)
+}
+
+func TestIntegers(t *testing.T) {
+	spec := testSpec(t)
+	for _, typ := range []xdr.ScSpecType{
+		xdr.ScSpecTypeScSpecTypeU32, xdr.ScSpecTypeScSpecTypeI32, xdr.ScSpecTypeScSpecTypeU64, xdr.ScSpecTypeScSpecTypeI64,
+		xdr.ScSpecTypeScSpecTypeU128, xdr.ScSpecTypeScSpecTypeI128, xdr.ScSpecTypeScSpecTypeU256, xdr.ScSpecTypeScSpecTypeI256,
+	} {
+		bounds := integerTypes[typ]
+		for _, v := range []*big.Int{bounds.min, bounds.max, big.NewInt(0), big.NewInt(1)} {
+			val, err := spec.EncodeValue(v.String(), primitive(typ))
+			require.NoError(t, err)
+			decoded, err := spec.DecodeValue(val, primitive(typ))
+			require.NoError(t, err)
+			assert.Equal(t, v.String(), decodedString(decoded), "%s %s", typ, v)
+		}
+		_, err := spec.EncodeValue(new(big.Int).Add(bounds.max, big.NewInt(1)).String(), primitive(typ))
+		assert.ErrorContains(t, err, "is out of the range of")
+		_, err = spec.EncodeValue(new(big.Int).Sub(bounds.min, big.NewInt(1)).String(), primitive(typ))
+		assert.ErrorContains(t, err, "is out of the range of")
+	}
+
+	_, err := spec.EncodeValue(1.5, u32Type)
+	assert.EqualError(t, err, "1.5 is not an exact integer, use a decimal string")
+	_, err = spec.EncodeValue("0x10", u64Type)
+	assert.EqualError(t, err, `invalid integer "0x10"`)
+}
+
+func decodedString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestEncodeErrors(t *testing.T) {
+	spec := testSpec(t)
+	for _, testCase := range []struct {
+		name string
+		args string
+		err  string
+	}{
+		{"unknown argument", `{"to": 1}`, "function swap has no argument to"},
+		{"missing argument", `{}`, "missing argument from of function swap"},
+		{
+			"invalid address",
+			`{"from": "GABC"}`,
+			`could not encode argument from of function swap: invalid address "GABC"`,
+		},
+		{
+			"missing struct field",
+			`{"from": "` + testAccount + `", "transfers": [{"to": "` + testAccount + `", "amount": "1"}]}`,
+			"could not encode argument transfers of function swap: invalid element 0: struct Transfer has 3 fields, got 2",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := spec.EncodeArgsJSON("swap", []byte(testCase.args))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+
+	_, err := spec.EncodeValue("Blue", udt("Color"))
+	assert.EqualError(t, err, "unknown case Blue of enum Color")
+	_, err = spec.EncodeValue("Move", udt("Action"))
+	assert.EqualError(t, err, "case Move of union Action has values")
+	_, err = spec.EncodeValue(map[string]interface{}{"Stop": []interface{}{}}, udt("Action"))
+	assert.EqualError(t, err, "case Stop of union Action has no values")
+	_, err = spec.EncodeValue("deadbeefff", bytes4Type)
+	assert.EqualError(t, err, "expected 4 bytes, got 5")
+	_, err = spec.EncodeValue("not a symbol", symbolType)
+	assert.EqualError(t, err, `symbol "not a symbol" has invalid character ' '`)
+	_, err = spec.EncodeValue(
+		[]interface{}{map[string]interface{}{"key": 1, "value": true}, map[string]interface{}{"key": 1, "value": false}},
+		mapOf(u32Type, boolType),
+	)
+	assert.EqualError(t, err, "duplicate map key 1")
+	_, err = spec.EncodeValue("x", primitive(xdr.ScSpecTypeScSpecTypeVal))
+	assert.EqualError(t, err, "values of type val must be xdr.ScVal values")
+	_, err = spec.EncodeValue("x", udt("Unknown"))
+	assert.EqualError(t, err, "unknown type Unknown")
+}
+
+func TestDecodeErrors(t *testing.T) {
+	spec := testSpec(t)
+	u32 := xdr.Uint32(9)
+	_, err := spec.DecodeValue(xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u32}, udt("Color"))
+	assert.EqualError(t, err, "unknown value 9 of enum Color")
+	_, err = spec.DecodeValue(xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u32}, u64Type)
+	assert.EqualError(t, err, "expected ScValTypeScvU64, got ScValTypeScvU32")
+
+	stop := xdr.ScSymbol("Stop")
+	extra := xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: newScVec(xdr.ScVec{
+		{Type: xdr.ScValTypeScvSymbol, Sym: &stop},
+		{Type: xdr.ScValTypeScvU32, U32: &u32},
+	})}
+	_, err = spec.DecodeValue(extra, udt("Action"))
+	assert.EqualError(t, err, "case Stop of union Action has no values, got 1")
+
+	_, err = spec.DecodeArgs("swap", nil)
+	assert.EqualError(t, err, "function swap expects 11 arguments, got 0")
+}
+
+func TestFunctionSignatures(t *testing.T) {
+	assert.Equal(t, []string{
+		"swap(from: Address, transfers: Vec<Transfer>, balances: Map<Address, i128>, flags: Map<u32, bool>, " +
+			"action: Action, color: Color, hash: BytesN<4>, supply: U256, delta: I256, deadline: Timepoint, " +
+			"limit: Option<u64>) -> Result<(Pair, Color), Error>",
+		"reset()",
+	}, testSpec(t).FunctionSignatures())
+}
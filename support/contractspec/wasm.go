@@ -0,0 +1,116 @@
+package contractspec
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// SpecSectionName is the name of the WASM custom section holding the XDR
+// encoded ScSpecEntry values of a contract.
+const SpecSectionName = "contractspecv0"
+
+const (
+	wasmVersion     = 1
+	customSectionID = 0
+	maxSpecEntries  = 10000
+)
+
+var wasmMagic = []byte{0x00, 'a', 's', 'm'}
+
+// ErrNoSpec is returned when a WASM module has no contract spec section.
+var ErrNoSpec = errors.New("wasm module has no " + SpecSectionName + " section")
+
+// ExtractSpecEntries parses the contract spec out of a WASM module, e.g. the
+// code of a ContractCodeEntry. The entries of all the spec sections are
+// returned in the order they appear in the module.
+func ExtractSpecEntries(wasm []byte) ([]xdr.ScSpecEntry, error) {
+	sections, err := customSections(wasm, SpecSectionName)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 {
+		return nil, ErrNoSpec
+	}
+
+	var entries []xdr.ScSpecEntry
+	for _, section := range sections {
+		decoder := xdr.NewBytesDecoder()
+		for offset := 0; offset < len(section); {
+			if len(entries) >= maxSpecEntries {
+				return nil, errors.Errorf("contract spec has more than %d entries", maxSpecEntries)
+			}
+			var entry xdr.ScSpecEntry
+			n, err := decoder.DecodeBytes(&entry, section[offset:])
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not decode contract spec entry at offset %d", offset)
+			}
+			offset += n
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// customSections returns the contents of the custom sections of a WASM module
+// with the given name.
+func customSections(wasm []byte, name string) ([][]byte, error) {
+	if len(wasm) < 8 || !bytes.Equal(wasm[:4], wasmMagic) {
+		return nil, errors.New("invalid wasm module, missing magic number")
+	}
+	if version := binary.LittleEndian.Uint32(wasm[4:8]); version != wasmVersion {
+		return nil, errors.Errorf("unsupported wasm version %d", version)
+	}
+
+	var sections [][]byte
+	for offset := 8; offset < len(wasm); {
+		id := wasm[offset]
+		offset++
+		size, n, err := readVarUint32(wasm[offset:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid size of wasm section at offset %d", offset)
+		}
+		offset += n
+		if uint64(offset)+uint64(size) > uint64(len(wasm)) {
+			return nil, errors.Errorf("wasm section at offset %d overflows the module", offset)
+		}
+		contents := wasm[offset : offset+int(size)]
+		offset += int(size)
+		if id != customSectionID {
+			continue
+		}
+
+		nameLength, n, err := readVarUint32(contents)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid name of wasm custom section")
+		}
+		if uint64(n)+uint64(nameLength) > uint64(len(contents)) {
+			return nil, errors.New("invalid name of wasm custom section")
+		}
+		if string(contents[n:n+int(nameLength)]) == name {
+			sections = append(sections, contents[n+int(nameLength):])
+		}
+	}
+	return sections, nil
+}
+
+// readVarUint32 reads an unsigned LEB128 integer, returning the value and the
+// number of bytes read.
+func readVarUint32(b []byte) (uint32, int, error) {
+	var value uint32
+	for i := 0; i < 5; i++ {
+		if i >= len(b) {
+			return 0, 0, errors.New("unexpected end of wasm module")
+		}
+		value |= uint32(b[i]&0x7f) << (7 * i)
+		if b[i]&0x80 == 0 {
+			if i == 4 && b[i] > 0x0f {
+				return 0, 0, errors.New("varuint32 overflow")
+			}
+			return value, i + 1, nil
+		}
+	}
+	return 0, 0, errors.New("varuint32 overflow")
+}
@@ -0,0 +1,142 @@
+package contractspec
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/xdr"
+)
+
+func appendVarUint32(b []byte, v uint32) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// wasmSection encodes a WASM section, custom sections are prefixed with name.
+func wasmSection(id byte, name string, contents []byte) []byte {
+	var body []byte
+	if id == customSectionID {
+		body = appendVarUint32(body, uint32(len(name)))
+		body = append(body, name...)
+	}
+	body = append(body, contents...)
+	section := appendVarUint32([]byte{id}, uint32(len(body)))
+	return append(section, body...)
+}
+
+func wasmModule(sections ...[]byte) []byte {
+	module := append(append([]byte{}, wasmMagic...), 1, 0, 0, 0)
+	for _, section := range sections {
+		module = append(module, section...)
+	}
+	return module
+}
+
+func marshalEntries(t *testing.T, entries ...xdr.ScSpecEntry) []byte {
+	var raw []byte
+	for _, entry := range entries {
+		b, err := entry.MarshalBinary()
+		require.NoError(t, err)
+		raw = append(raw, b...)
+	}
+	return raw
+}
+
+func TestExtractSpecEntriesFromContracts(t *testing.T) {
+	wasm, err := os.ReadFile("testdata/soroban_add_u64.wasm")
+	require.NoError(t, err)
+	entries, err := ExtractSpecEntries(wasm)
+	require.NoError(t, err)
+	u64 := xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeU64}
+	assert.Equal(t, []xdr.ScSpecEntry{{
+		Kind: xdr.ScSpecEntryKindScSpecEntryFunctionV0,
+		FunctionV0: &xdr.ScSpecFunctionV0{
+			Name:    "add",
+			Inputs:  []xdr.ScSpecFunctionInputV0{{Name: "a", Type: u64}, {Name: "b", Type: u64}},
+			Outputs: []xdr.ScSpecTypeDef{u64},
+		},
+	}}, entries)
+
+	wasm, err = os.ReadFile("testdata/soroban_sac_test.wasm")
+	require.NoError(t, err)
+	spec, err := NewSpecFromWasm(wasm)
+	require.NoError(t, err)
+	assert.Len(t, spec.Entries(), 5)
+	transfer, ok := spec.Function("transfer")
+	require.True(t, ok)
+	assert.Len(t, transfer.Inputs, 2)
+	dataKey, ok := spec.Type("DataKey")
+	require.True(t, ok)
+	assert.Equal(t, xdr.ScSpecEntryKindScSpecEntryUdtUnionV0, dataKey.Kind)
+	_, ok = spec.Function("mint")
+	assert.False(t, ok)
+}
+
+func TestExtractSpecEntries(t *testing.T) {
+	first := xdr.ScSpecEntry{
+		Kind:      xdr.ScSpecEntryKindScSpecEntryUdtEnumV0,
+		UdtEnumV0: &xdr.ScSpecUdtEnumV0{Name: "Color", Cases: []xdr.ScSpecUdtEnumCaseV0{{Name: "Red", Value: 1}}},
+	}
+	second := xdr.ScSpecEntry{
+		Kind:       xdr.ScSpecEntryKindScSpecEntryFunctionV0,
+		FunctionV0: &xdr.ScSpecFunctionV0{Name: "paint"},
+	}
+	third := xdr.ScSpecEntry{
+		Kind:       xdr.ScSpecEntryKindScSpecEntryFunctionV0,
+		FunctionV0: &xdr.ScSpecFunctionV0{Name: "erase"},
+	}
+
+	wasm := wasmModule(
+		wasmSection(1, "", []byte{0x01, 0x60, 0x00, 0x00}),
+		wasmSection(customSectionID, "name", []byte{0x00}),
+		wasmSection(customSectionID, SpecSectionName, marshalEntries(t, first, second)),
+		wasmSection(10, "", make([]byte, 200)),
+		wasmSection(customSectionID, SpecSectionName, marshalEntries(t, third)),
+	)
+	entries, err := ExtractSpecEntries(wasm)
+	require.NoError(t, err)
+	assert.Equal(t, []xdr.ScSpecEntry{first, second, third}, entries)
+
+	_, err = ExtractSpecEntries(wasmModule(wasmSection(customSectionID, "contractenvmetav0", []byte{1, 2, 3})))
+	assert.Equal(t, ErrNoSpec, err)
+
+	_, err = ExtractSpecEntries([]byte("not a wasm module"))
+	assert.EqualError(t, err, "invalid wasm module, missing magic number")
+
+	_, err = ExtractSpecEntries(append(append([]byte{}, wasmMagic...), 2, 0, 0, 0))
+	assert.EqualError(t, err, "unsupported wasm version 2")
+
+	truncated := wasmModule(wasmSection(customSectionID, SpecSectionName, marshalEntries(t, first)))
+	_, err = ExtractSpecEntries(truncated[:len(truncated)-1])
+	assert.EqualError(t, err, "wasm section at offset 10 overflows the module")
+
+	invalid := wasmModule(wasmSection(customSectionID, SpecSectionName, marshalEntries(t, first)[:10]))
+	_, err = ExtractSpecEntries(invalid)
+	assert.ErrorContains(t, err, "could not decode contract spec entry at offset 0")
+}
+
+func TestNewSpecDuplicates(t *testing.T) {
+	function := xdr.ScSpecEntry{
+		Kind:       xdr.ScSpecEntryKindScSpecEntryFunctionV0,
+		FunctionV0: &xdr.ScSpecFunctionV0{Name: "paint"},
+	}
+	_, err := NewSpec([]xdr.ScSpecEntry{function, function})
+	assert.EqualError(t, err, "duplicate function paint")
+
+	enum := xdr.ScSpecEntry{
+		Kind:      xdr.ScSpecEntryKindScSpecEntryUdtEnumV0,
+		UdtEnumV0: &xdr.ScSpecUdtEnumV0{Name: "Color"},
+	}
+	errorEnum := xdr.ScSpecEntry{
+		Kind:           xdr.ScSpecEntryKindScSpecEntryUdtErrorEnumV0,
+		UdtErrorEnumV0: &xdr.ScSpecUdtErrorEnumV0{Name: "Color"},
+	}
+	_, err = NewSpec([]xdr.ScSpecEntry{enum, errorEnum})
+	assert.EqualError(t, err, "duplicate type Color")
+}
@@ -34,6 +34,14 @@ func NewDataStore(ctx context.Context, datastoreConfig DataStoreConfig) (DataSto
 			return nil, errors.Errorf("Invalid GCS config, no destination_bucket_path")
 		}
 		return NewGCSDataStore(ctx, destinationBucketPath, datastoreConfig.Schema)
+	case "S3":
+		return NewS3DataStore(ctx, datastoreConfig.Params, datastoreConfig.Schema)
+	case "Filesystem":
+		destinationPath, ok := datastoreConfig.Params["destination_path"]
+		if !ok {
+			return nil, errors.Errorf("Invalid Filesystem config, no destination_path")
+		}
+		return NewFilesystemDataStore(destinationPath, datastoreConfig.Schema)
 	default:
 		return nil, errors.Errorf("Invalid datastore type %v, not supported", datastoreConfig.Type)
 	}
@@ -0,0 +1,245 @@
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stellar/go/support/log"
+)
+
+// metadataSuffix is appended to the path of a file to obtain the path of the
+// sidecar file holding its metadata.
+const metadataSuffix = ".metadata.json"
+
+const (
+	// metadataWriteTimeout is how long after a file was written its metadata
+	// sidecar is waited for.
+	metadataWriteTimeout = 5 * time.Second
+	metadataPollInterval = 10 * time.Millisecond
+)
+
+// FilesystemDataStore implements DataStore on top of a local directory. Files
+// keep the same layout they would have in a bucket and their metadata is
+// stored as JSON in a sidecar file next to them.
+type FilesystemDataStore struct {
+	root   string
+	schema DataStoreSchema
+}
+
+// NewFilesystemDataStore creates a DataStore rooted at the given directory,
+// creating it if needed.
+func NewFilesystemDataStore(root string, schema DataStoreSchema) (DataStore, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create datastore directory %s: %w", root, err)
+	}
+	log.Infof("creating filesystem datastore at: %s", root)
+	return &FilesystemDataStore{root: root, schema: schema}, nil
+}
+
+// GetFileMetadata retrieves the metadata for the specified file. The sidecar
+// of a file created by PutFileIfNotExists is written right after the file, so
+// a missing sidecar is waited for if the file was modified in the last
+// metadataWriteTimeout.
+func (f FilesystemDataStore) GetFileMetadata(ctx context.Context, filePath string) (map[string]string, error) {
+	info, err := os.Stat(f.fullPath(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for {
+		data, err = os.ReadFile(f.fullPath(filePath) + metadataSuffix)
+		if !errors.Is(err, os.ErrNotExist) || time.Since(info.ModTime()) > metadataWriteTimeout {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(metadataPollInterval):
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading metadata of file %s: %w", filePath, err)
+	}
+
+	var metaData map[string]string
+	if err := json.Unmarshal(data, &metaData); err != nil {
+		return nil, fmt.Errorf("error decoding metadata of file %s: %w", filePath, err)
+	}
+	if len(metaData) == 0 {
+		return nil, nil
+	}
+	return metaData, nil
+}
+
+// GetFile retrieves a file from the datastore directory.
+func (f FilesystemDataStore) GetFile(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	file, err := os.Open(f.fullPath(filePath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, os.ErrNotExist
+		}
+		return nil, fmt.Errorf("error retrieving file %s: %w", filePath, err)
+	}
+	return file, nil
+}
+
+// PutFileIfNotExists writes a file only if it doesn't already exist. The file
+// is written to a temporary location first and then hard linked into place,
+// which fails atomically if another writer created the file in the meantime.
+// The metadata sidecar is only written by the writer which created the file,
+// right after linking it, see GetFileMetadata.
+func (f FilesystemDataStore) PutFileIfNotExists(ctx context.Context, filePath string, in io.WriterTo, metaData map[string]string) (bool, error) {
+	exists, err := f.Exists(ctx, filePath)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		log.Infof("Precondition failed: %s already exists in the datastore", filePath)
+		return false, nil // Treat as success
+	}
+
+	tmpPath, err := f.writeTemp(filePath, in)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmpPath)
+
+	if err := os.Link(tmpPath, f.fullPath(filePath)); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			log.Infof("Precondition failed: %s already exists in the datastore", filePath)
+			return false, nil // Treat as success
+		}
+		return false, fmt.Errorf("error writing file %s: %w", filePath, err)
+	}
+
+	if err := f.putMetadata(filePath, metaData); err != nil {
+		// remove the file so that it can be written again with its metadata
+		if removeErr := os.Remove(f.fullPath(filePath)); removeErr != nil {
+			log.Warnf("could not remove file %s without metadata: %v", filePath, removeErr)
+		}
+		return false, err
+	}
+
+	log.Infof("File written successfully: %s", filePath)
+	return true, nil
+}
+
+// PutFile writes a file, replacing any existing one. The metadata sidecar is
+// written before the file is moved into place, so the file it replaces may
+// briefly be read with the new metadata.
+func (f FilesystemDataStore) PutFile(ctx context.Context, filePath string, in io.WriterTo, metaData map[string]string) error {
+	tmpPath, err := f.writeTemp(filePath, in)
+	if err != nil {
+		return err
+	}
+
+	if err := f.putMetadata(filePath, metaData); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, f.fullPath(filePath)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing file %s: %w", filePath, err)
+	}
+
+	log.Infof("File written successfully: %s", filePath)
+	return nil
+}
+
+// Size retrieves the size of a file in the datastore directory.
+func (f FilesystemDataStore) Size(ctx context.Context, filePath string) (int64, error) {
+	info, err := os.Stat(f.fullPath(filePath))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Exists checks if a file exists in the datastore directory.
+func (f FilesystemDataStore) Exists(ctx context.Context, filePath string) (bool, error) {
+	_, err := f.Size(ctx, filePath)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Close is a no-op for the filesystem datastore.
+func (f FilesystemDataStore) Close() error {
+	return nil
+}
+
+// GetSchema returns the schema information which defines the structure
+// and organization of data in the datastore.
+func (f FilesystemDataStore) GetSchema() DataStoreSchema {
+	return f.schema
+}
+
+func (f FilesystemDataStore) fullPath(filePath string) string {
+	return filepath.Join(f.root, filepath.FromSlash(filePath))
+}
+
+// writeTemp writes the content of in to a temporary file in the directory of
+// filePath, so that it can be moved into place atomically.
+func (f FilesystemDataStore) writeTemp(filePath string, in io.WriterTo) (string, error) {
+	dir := filepath.Dir(f.fullPath(filePath))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file for %s: %w", filePath, err)
+	}
+	if _, err := in.WriteTo(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to sync file %s: %w", filePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to close file %s: %w", filePath, err)
+	}
+	return tmp.Name(), nil
+}
+
+// putMetadata writes the metadata sidecar of filePath. A sidecar is written
+// even without metadata, so that readers can tell a file without metadata
+// from a file whose sidecar hasn't been written yet.
+func (f FilesystemDataStore) putMetadata(filePath string, metaData map[string]string) error {
+	if metaData == nil {
+		metaData = map[string]string{}
+	}
+	data, err := json.Marshal(metaData)
+	if err != nil {
+		return fmt.Errorf("error encoding metadata of file %s: %w", filePath, err)
+	}
+	tmpPath, err := f.writeTemp(filePath, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, f.fullPath(filePath)+metadataSuffix); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing metadata of file %s: %w", filePath, err)
+	}
+	return nil
+}
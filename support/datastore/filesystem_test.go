@@ -0,0 +1,244 @@
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemExistsAndSize(t *testing.T) {
+	root := t.TempDir()
+	content := []byte("inside the file")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "file.txt"), content, 0644))
+
+	store, err := NewFilesystemDataStore(root, DataStoreSchema{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, store.Close())
+	})
+
+	exists, err := store.Exists(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = store.Exists(context.Background(), "missing-file.txt")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	size, err := store.Size(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), size)
+
+	_, err = store.Size(context.Background(), "missing-file.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFilesystemPutFile(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFilesystemDataStore(filepath.Join(root, "objects", "testnet"), DataStoreSchema{})
+	require.NoError(t, err)
+
+	content := []byte("inside the file")
+	writerTo := &writerToRecorder{
+		WriterTo: bytes.NewReader(content),
+	}
+	err = store.PutFile(context.Background(), "FFFFFFFF--0-9/file.txt", writerTo, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), writerTo.total)
+	require.FileExists(t, filepath.Join(root, "objects", "testnet", "FFFFFFFF--0-9", "file.txt"))
+
+	reader, err := store.GetFile(context.Background(), "FFFFFFFF--0-9/file.txt")
+	require.NoError(t, err)
+	requireReaderContentEquals(t, reader, content)
+
+	metadata, err := store.GetFileMetadata(context.Background(), "FFFFFFFF--0-9/file.txt")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string(nil), metadata)
+
+	otherContent := []byte("other text")
+	err = store.PutFile(context.Background(), "FFFFFFFF--0-9/file.txt", bytes.NewReader(otherContent), nil)
+	require.NoError(t, err)
+
+	reader, err = store.GetFile(context.Background(), "FFFFFFFF--0-9/file.txt")
+	require.NoError(t, err)
+	requireReaderContentEquals(t, reader, otherContent)
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(root, "objects", "testnet", "FFFFFFFF--0-9"))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "file.txt", entries[0].Name())
+	require.Equal(t, "file.txt"+metadataSuffix, entries[1].Name())
+}
+
+func TestFilesystemPutFileIfNotExistsWithMetadata(t *testing.T) {
+	store, err := NewFilesystemDataStore(t.TempDir(), DataStoreSchema{})
+	require.NoError(t, err)
+
+	metadataObj := MetaData{
+		StartLedger:          1234,
+		EndLedger:            1234,
+		StartLedgerCloseTime: 1234,
+		EndLedgerCloseTime:   1234,
+		NetworkPassPhrase:    "testnet",
+		CompressionType:      "zstd",
+		ProtocolVersion:      21,
+		CoreVersion:          "v1.2.3",
+		Version:              "1.0.0",
+	}
+
+	content := []byte("inside the file")
+	ok, err := store.PutFileIfNotExists(context.Background(), "file.txt", bytes.NewReader(content), metadataObj.ToMap())
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	metadata, err := store.GetFileMetadata(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, metadataObj.ToMap(), metadata)
+
+	modifiedMetadataObj := metadataObj
+	modifiedMetadataObj.StartLedger = 5678
+
+	ok, err = store.PutFileIfNotExists(context.Background(), "file.txt", bytes.NewReader([]byte("overwrite the file")), modifiedMetadataObj.ToMap())
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	reader, err := store.GetFile(context.Background(), "file.txt")
+	require.NoError(t, err)
+	requireReaderContentEquals(t, reader, content)
+
+	metadata, err = store.GetFileMetadata(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, metadataObj.ToMap(), metadata)
+}
+
+// racingWriterTo writes the file with its own metadata while its content is
+// being written, as a concurrent writer would.
+type racingWriterTo struct {
+	store    DataStore
+	path     string
+	metadata map[string]string
+	content  []byte
+}
+
+func (r racingWriterTo) WriteTo(w io.Writer) (int64, error) {
+	ok, err := r.store.PutFileIfNotExists(context.Background(), r.path, bytes.NewReader([]byte("written concurrently")), r.metadata)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errors.New("concurrent writer did not write the file")
+	}
+	n, err := w.Write(r.content)
+	return int64(n), err
+}
+
+func TestFilesystemPutFileIfNotExistsLostRace(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFilesystemDataStore(root, DataStoreSchema{})
+	require.NoError(t, err)
+
+	winnerMetadata := MetaData{StartLedger: 1, EndLedger: 1, NetworkPassPhrase: "testnet"}.ToMap()
+	for _, metadata := range []map[string]string{
+		MetaData{StartLedger: 1234, EndLedger: 1234, NetworkPassPhrase: "testnet"}.ToMap(),
+		nil,
+	} {
+		path := fmt.Sprintf("file-%d.txt", len(metadata))
+		in := racingWriterTo{store: store, path: path, metadata: winnerMetadata, content: []byte("inside the file")}
+		ok, err := store.PutFileIfNotExists(context.Background(), path, in, metadata)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		reader, err := store.GetFile(context.Background(), path)
+		require.NoError(t, err)
+		requireReaderContentEquals(t, reader, []byte("written concurrently"))
+
+		// the metadata of the writer which created the file is kept
+		actual, err := store.GetFileMetadata(context.Background(), path)
+		require.NoError(t, err)
+		require.Equal(t, winnerMetadata, actual)
+	}
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+}
+
+func TestFilesystemPutFileIfNotExistsConcurrent(t *testing.T) {
+	store, err := NewFilesystemDataStore(t.TempDir(), DataStoreSchema{})
+	require.NoError(t, err)
+
+	var (
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		written []int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			content := bytes.NewReader([]byte(fmt.Sprintf("content %d", i)))
+			metadata := MetaData{StartLedger: uint32(i), EndLedger: uint32(i)}.ToMap()
+			ok, err := store.PutFileIfNotExists(context.Background(), "file.txt", content, metadata)
+			require.NoError(t, err)
+			if ok {
+				lock.Lock()
+				written = append(written, i)
+				lock.Unlock()
+			}
+		}(i)
+		// readers see the metadata of the file as soon as it exists
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			metadata, err := store.GetFileMetadata(context.Background(), "file.txt")
+			if errors.Is(err, os.ErrNotExist) {
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, metadata)
+		}()
+	}
+	wg.Wait()
+	require.Len(t, written, 1)
+
+	reader, err := store.GetFile(context.Background(), "file.txt")
+	require.NoError(t, err)
+	requireReaderContentEquals(t, reader, []byte(fmt.Sprintf("content %d", written[0])))
+	metadata, err := store.GetFileMetadata(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, MetaData{StartLedger: uint32(written[0]), EndLedger: uint32(written[0])}.ToMap(), metadata)
+}
+
+func TestFilesystemGetNonExistentFile(t *testing.T) {
+	store, err := NewFilesystemDataStore(t.TempDir(), DataStoreSchema{})
+	require.NoError(t, err)
+
+	_, err = store.GetFile(context.Background(), "other-file.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	metadata, err := store.GetFileMetadata(context.Background(), "other-file.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.Equal(t, map[string]string(nil), metadata)
+}
+
+func TestFilesystemInvalidConfig(t *testing.T) {
+	_, err := NewDataStore(context.Background(), DataStoreConfig{Type: "Filesystem"})
+	require.EqualError(t, err, "Invalid Filesystem config, no destination_path")
+
+	store, err := NewDataStore(context.Background(), DataStoreConfig{
+		Type:   "Filesystem",
+		Params: map[string]string{"destination_path": t.TempDir()},
+		Schema: DataStoreSchema{LedgersPerFile: 1, FilesPerPartition: 10},
+	})
+	require.NoError(t, err)
+	require.Equal(t, DataStoreSchema{LedgersPerFile: 1, FilesPerPartition: 10}, store.GetSchema())
+}
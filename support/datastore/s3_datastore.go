@@ -0,0 +1,239 @@
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/url"
+)
+
+// S3DataStore implements DataStore for AWS S3 and S3 compatible object
+// stores such as MinIO or Ceph.
+type S3DataStore struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+	schema DataStoreSchema
+}
+
+// NewS3DataStore creates a DataStore backed by an S3 bucket. The supported
+// params are:
+//   - destination_bucket_path: bucket name followed by an optional path prefix
+//   - region: the AWS region of the bucket
+//   - endpoint: optional custom endpoint for S3 compatible stores
+//   - force_path_style: optional, set to "true" to use path-style addressing
+func NewS3DataStore(ctx context.Context, params map[string]string, schema DataStoreSchema) (DataStore, error) {
+	bucketPath, ok := params["destination_bucket_path"]
+	if !ok {
+		return nil, fmt.Errorf("invalid S3 config, no destination_bucket_path")
+	}
+
+	cfg := aws.NewConfig()
+	if region, ok := params["region"]; ok {
+		cfg = cfg.WithRegion(region)
+	}
+	if endpoint, ok := params["endpoint"]; ok {
+		cfg = cfg.WithEndpoint(endpoint)
+	}
+	if value, ok := params["force_path_style"]; ok {
+		forcePathStyle, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 config, force_path_style: %w", err)
+		}
+		cfg = cfg.WithS3ForcePathStyle(forcePathStyle)
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromS3Client(ctx, s3.New(sess), bucketPath, schema)
+}
+
+// FromS3Client creates a DataStore using an existing S3 client.
+func FromS3Client(ctx context.Context, client s3iface.S3API, bucketPath string, schema DataStoreSchema) (DataStore, error) {
+	// append the s3:// scheme to enable usage of the url package reliably to
+	// get parse bucket name which is first path segment as URL.Host
+	parsed, err := url.Parse(fmt.Sprintf("s3://%s", bucketPath))
+	if err != nil {
+		return nil, err
+	}
+
+	// Inside s3, all keys start _without_ the leading /
+	prefix := strings.TrimPrefix(parsed.Path, "/")
+	bucketName := parsed.Host
+
+	log.Infof("creating S3 client for bucket: %s, prefix: %s", bucketName, prefix)
+	// Check the bucket exists
+	if _, err := client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucketName)}); err != nil {
+		return nil, fmt.Errorf("failed to retrieve bucket attributes: %w", err)
+	}
+
+	return &S3DataStore{client: client, bucket: bucketName, prefix: prefix, schema: schema}, nil
+}
+
+// GetFileMetadata retrieves the metadata for the specified file in the S3 bucket.
+func (b S3DataStore) GetFileMetadata(ctx context.Context, filePath string) (map[string]string, error) {
+	output, err := b.headObject(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.Metadata) == 0 {
+		return nil, nil
+	}
+	// S3 returns user metadata keys in canonical header form (e.g.
+	// Start-Ledger), so they need to be lower cased to match what was stored.
+	metaData := make(map[string]string, len(output.Metadata))
+	for key, value := range output.Metadata {
+		metaData[strings.ToLower(key)] = aws.StringValue(value)
+	}
+	return metaData, nil
+}
+
+// GetFile retrieves a file from the S3 bucket.
+func (b S3DataStore) GetFile(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	filePath = path.Join(b.prefix, filePath)
+	output, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(filePath),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, os.ErrNotExist
+		}
+		return nil, fmt.Errorf("error retrieving file %s: %w", filePath, err)
+	}
+	log.Infof("File retrieved successfully: %s", filePath)
+	return output.Body, nil
+}
+
+// PutFileIfNotExists uploads a file to S3 only if it doesn't already exist.
+// It relies on S3 conditional writes (If-None-Match: *), so the object store
+// must support them for the check to be atomic.
+func (b S3DataStore) PutFileIfNotExists(ctx context.Context, filePath string, in io.WriterTo, metaData map[string]string) (bool, error) {
+	err := b.putFile(ctx, filePath, in, metaData, true)
+	if err != nil {
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusPreconditionFailed {
+			log.Infof("Precondition failed: %s already exists in the bucket", filePath)
+			return false, nil // Treat as success
+		}
+		return false, fmt.Errorf("error uploading file %s: %w", filePath, err)
+	}
+	log.Infof("File uploaded successfully: %s", filePath)
+	return true, nil
+}
+
+// PutFile uploads a file to S3
+func (b S3DataStore) PutFile(ctx context.Context, filePath string, in io.WriterTo, metaData map[string]string) error {
+	if err := b.putFile(ctx, filePath, in, metaData, false); err != nil {
+		return fmt.Errorf("error uploading file %s: %w", filePath, err)
+	}
+	log.Infof("File uploaded successfully: %s", filePath)
+	return nil
+}
+
+// Size retrieves the size of a file in the S3 bucket.
+func (b S3DataStore) Size(ctx context.Context, pth string) (int64, error) {
+	output, err := b.headObject(ctx, pth)
+	if err != nil {
+		return 0, err
+	}
+	return aws.Int64Value(output.ContentLength), nil
+}
+
+// Exists checks if a file exists in the S3 bucket.
+func (b S3DataStore) Exists(ctx context.Context, pth string) (bool, error) {
+	_, err := b.Size(ctx, pth)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Close is a no-op, the S3 client doesn't hold any resources.
+func (b S3DataStore) Close() error {
+	return nil
+}
+
+// GetSchema returns the schema information which defines the structure
+// and organization of data in the datastore.
+func (b S3DataStore) GetSchema() DataStoreSchema {
+	return b.schema
+}
+
+func (b S3DataStore) headObject(ctx context.Context, filePath string) (*s3.HeadObjectOutput, error) {
+	filePath = path.Join(b.prefix, filePath)
+	output, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(filePath),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+func (b S3DataStore) putFile(ctx context.Context, filePath string, in io.WriterTo, metaData map[string]string, ifNotExists bool) error {
+	filePath = path.Join(b.prefix, filePath)
+	buf := &bytes.Buffer{}
+	if _, err := in.WriteTo(buf); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+
+	// S3 validates the body against Content-MD5 and rejects corrupted uploads
+	checksum := md5.Sum(buf.Bytes())
+	input := &s3.PutObjectInput{
+		Bucket:     aws.String(b.bucket),
+		Key:        aws.String(filePath),
+		Body:       bytes.NewReader(buf.Bytes()),
+		ContentMD5: aws.String(base64.StdEncoding.EncodeToString(checksum[:])),
+		Metadata:   aws.StringMap(metaData),
+	}
+
+	var opts []request.Option
+	if ifNotExists {
+		opts = append(opts, func(r *request.Request) {
+			r.Handlers.Build.PushBack(func(r *request.Request) {
+				r.HTTPRequest.Header.Set("If-None-Match", "*")
+			})
+		})
+	}
+
+	_, err := b.client.PutObjectWithContext(ctx, input, opts...)
+	return err
+}
+
+func isS3NotFound(err error) bool {
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+		return true
+	}
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && (aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == "NotFound")
+}
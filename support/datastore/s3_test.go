@@ -0,0 +1,254 @@
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeS3Object struct {
+	content  []byte
+	metadata http.Header
+}
+
+// fakeS3Server is a minimal path-style S3 server supporting the requests
+// issued by S3DataStore.
+type fakeS3Server struct {
+	*httptest.Server
+	bucket  string
+	lock    sync.Mutex
+	objects map[string]fakeS3Object
+}
+
+func newFakeS3Server(t *testing.T, bucket string) *fakeS3Server {
+	server := &fakeS3Server{bucket: bucket, objects: map[string]fakeS3Object{}}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *fakeS3Server) writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+	}
+}
+
+func (s *fakeS3Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if parts[0] != s.bucket {
+		s.writeError(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	if len(parts) == 1 || parts[1] == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	key := parts[1]
+	object, exists := s.objects[key]
+
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		if !exists {
+			s.writeError(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		for name, values := range object.metadata {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(object.content)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(object.content)
+		}
+	case http.MethodPut:
+		if exists && r.Header.Get("If-None-Match") == "*" {
+			s.writeError(w, r, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.writeError(w, r, http.StatusInternalServerError, "InternalError")
+			return
+		}
+		metadata := http.Header{}
+		for name, values := range r.Header {
+			if strings.HasPrefix(strings.ToLower(name), "x-amz-meta-") {
+				metadata[name] = values
+			}
+		}
+		s.objects[key] = fakeS3Object{content: content, metadata: metadata}
+		w.WriteHeader(http.StatusOK)
+	default:
+		s.writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func newTestS3DataStore(t *testing.T, server *fakeS3Server, bucketPath string) DataStore {
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(server.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("key", "secret", ""),
+	})
+	require.NoError(t, err)
+
+	store, err := FromS3Client(context.Background(), s3.New(sess), bucketPath, DataStoreSchema{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, store.Close())
+	})
+	return store
+}
+
+func TestS3MissingBucket(t *testing.T) {
+	server := newFakeS3Server(t, "test-bucket")
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(server.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("key", "secret", ""),
+	})
+	require.NoError(t, err)
+
+	_, err = FromS3Client(context.Background(), s3.New(sess), "other-bucket/objects", DataStoreSchema{})
+	require.Error(t, err)
+}
+
+func TestS3ExistsAndSize(t *testing.T) {
+	server := newFakeS3Server(t, "test-bucket")
+	content := []byte("inside the file")
+	server.objects["objects/testnet/file.txt"] = fakeS3Object{content: content}
+	store := newTestS3DataStore(t, server, "test-bucket/objects/testnet")
+
+	exists, err := store.Exists(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = store.Exists(context.Background(), "missing-file.txt")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	size, err := store.Size(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), size)
+
+	_, err = store.Size(context.Background(), "missing-file.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestS3PutFile(t *testing.T) {
+	server := newFakeS3Server(t, "test-bucket")
+	store := newTestS3DataStore(t, server, "test-bucket/objects/testnet")
+
+	content := []byte("inside the file")
+	writerTo := &writerToRecorder{
+		WriterTo: bytes.NewReader(content),
+	}
+	err := store.PutFile(context.Background(), "file.txt", writerTo, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), writerTo.total)
+	require.Contains(t, server.objects, "objects/testnet/file.txt")
+
+	reader, err := store.GetFile(context.Background(), "file.txt")
+	require.NoError(t, err)
+	requireReaderContentEquals(t, reader, content)
+
+	metadata, err := store.GetFileMetadata(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string(nil), metadata)
+
+	otherContent := []byte("other text")
+	writerTo = &writerToRecorder{
+		WriterTo: bytes.NewReader(otherContent),
+	}
+	err = store.PutFile(context.Background(), "file.txt", writerTo, nil)
+	require.NoError(t, err)
+
+	reader, err = store.GetFile(context.Background(), "file.txt")
+	require.NoError(t, err)
+	requireReaderContentEquals(t, reader, otherContent)
+}
+
+func TestS3PutFileIfNotExistsWithMetadata(t *testing.T) {
+	server := newFakeS3Server(t, "test-bucket")
+	store := newTestS3DataStore(t, server, "test-bucket/objects/testnet")
+
+	metadataObj := MetaData{
+		StartLedger:          1234,
+		EndLedger:            1234,
+		StartLedgerCloseTime: 1234,
+		EndLedgerCloseTime:   1234,
+		NetworkPassPhrase:    "testnet",
+		CompressionType:      "zstd",
+		ProtocolVersion:      21,
+		CoreVersion:          "v1.2.3",
+		Version:              "1.0.0",
+	}
+
+	content := []byte("inside the file")
+	ok, err := store.PutFileIfNotExists(context.Background(), "file.txt", bytes.NewReader(content), metadataObj.ToMap())
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	metadata, err := store.GetFileMetadata(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, metadataObj.ToMap(), metadata)
+
+	modifiedMetadataObj := metadataObj
+	modifiedMetadataObj.StartLedger = 5678
+
+	ok, err = store.PutFileIfNotExists(context.Background(), "file.txt", bytes.NewReader([]byte("overwrite the file")), modifiedMetadataObj.ToMap())
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	reader, err := store.GetFile(context.Background(), "file.txt")
+	require.NoError(t, err)
+	requireReaderContentEquals(t, reader, content)
+
+	metadata, err = store.GetFileMetadata(context.Background(), "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, metadataObj.ToMap(), metadata)
+}
+
+func TestS3GetNonExistentFile(t *testing.T) {
+	server := newFakeS3Server(t, "test-bucket")
+	store := newTestS3DataStore(t, server, "test-bucket/objects/testnet")
+
+	_, err := store.GetFile(context.Background(), "other-file.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	metadata, err := store.GetFileMetadata(context.Background(), "other-file.txt")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.Equal(t, map[string]string(nil), metadata)
+}
+
+func TestS3InvalidConfig(t *testing.T) {
+	_, err := NewDataStore(context.Background(), DataStoreConfig{Type: "S3"})
+	require.EqualError(t, err, "invalid S3 config, no destination_bucket_path")
+
+	_, err = NewDataStore(context.Background(), DataStoreConfig{
+		Type: "S3",
+		Params: map[string]string{
+			"destination_bucket_path": "test-bucket",
+			"force_path_style":        "maybe",
+		},
+	})
+	require.ErrorContains(t, err, "invalid S3 config, force_path_style")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// partition is a range of ledgers written to its own files.
+type partition struct {
+	From uint32 `json:"from"`
+	To   uint32 `json:"to"`
+}
+
+// jobSettings are the settings of a job which determine its partitions and
+// files, a job can only be resumed with the same settings.
+type jobSettings struct {
+	Start         uint32   `json:"start"`
+	End           uint32   `json:"end"`
+	PartitionSize uint32   `json:"partition_size"`
+	Exports       []string `json:"exports"`
+	Format        string   `json:"format"`
+}
+
+type checkpointState struct {
+	Settings  jobSettings `json:"settings"`
+	Completed []partition `json:"completed"`
+}
+
+// checkpoint records the partitions which were written so an interrupted job
+// can be resumed.
+type checkpoint struct {
+	path      string
+	lock      sync.Mutex
+	state     checkpointState
+	completed map[partition]bool
+}
+
+// openCheckpoint loads the checkpoint file at path, or creates it if it does
+// not exist.
+func openCheckpoint(path string, settings jobSettings) (*checkpoint, error) {
+	c := &checkpoint{
+		path:      path,
+		state:     checkpointState{Settings: settings},
+		completed: map[partition]bool{},
+	}
+
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, c.save()
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read checkpoint file %s", path)
+	}
+
+	var state checkpointState
+	if err = json.Unmarshal(contents, &state); err != nil {
+		return nil, errors.Wrapf(err, "could not parse checkpoint file %s", path)
+	}
+	if !reflect.DeepEqual(state.Settings, settings) {
+		return nil, errors.Errorf("checkpoint file %s belongs to a job with different settings (%+v), "+
+			"remove it or use another checkpoint file to start a new job", path, state.Settings)
+	}
+	c.state = state
+	for _, p := range state.Completed {
+		c.completed[p] = true
+	}
+	return c, nil
+}
+
+func (c *checkpoint) isCompleted(p partition) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.completed[p]
+}
+
+// complete records that p was written.
+func (c *checkpoint) complete(p partition) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.completed[p] = true
+	c.state.Completed = append(c.state.Completed, p)
+	return c.save()
+}
+
+// save writes the checkpoint file atomically, the caller must hold the lock
+// if the checkpoint is shared.
+func (c *checkpoint) save() error {
+	contents, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not encode checkpoint")
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return errors.Wrapf(err, "could not create directory of checkpoint file %s", c.path)
+		}
+	}
+	tmpPath := c.path + ".tmp"
+	if err = os.WriteFile(tmpPath, contents, 0644); err != nil {
+		return errors.Wrapf(err, "could not write checkpoint file %s", c.path)
+	}
+	if err = os.Rename(tmpPath, c.path); err != nil {
+		return errors.Wrapf(err, "could not write checkpoint file %s", c.path)
+	}
+	return nil
+}
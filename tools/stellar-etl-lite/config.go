@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/pelletier/go-toml"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/ingest/cdp"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	pubnet  = "pubnet"
+	testnet = "testnet"
+
+	datastoreBackend   = "datastore"
+	captiveCoreBackend = "captive_core"
+
+	userAgent = "stellar-etl-lite"
+)
+
+type stellarCoreConfig struct {
+	Network               string   `toml:"network"`
+	NetworkPassphrase     string   `toml:"network_passphrase"`
+	HistoryArchiveUrls    []string `toml:"history_archive_urls"`
+	StellarCoreBinaryPath string   `toml:"stellar_core_binary_path"`
+	CaptiveCoreTomlPath   string   `toml:"captive_core_toml_path"`
+	CheckpointFrequency   uint32   `toml:"checkpoint_frequency"`
+	StoragePath           string   `toml:"storage_path"`
+}
+
+// config is the TOML configuration of the ledger backend, see
+// config.example.toml.
+type config struct {
+	LedgerBackend     string                    `toml:"ledger_backend"`
+	DataStoreConfig   datastore.DataStoreConfig `toml:"datastore_config"`
+	StellarCoreConfig stellarCoreConfig         `toml:"stellar_core_config"`
+
+	captiveCoreToml []byte
+}
+
+func loadConfig(path string) (*config, error) {
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load config file %s", path)
+	}
+	cfg := &config{}
+	if err = tree.Unmarshal(cfg); err != nil {
+		return nil, errors.Wrapf(err, "could not parse config file %s", path)
+	}
+
+	coreConfig := &cfg.StellarCoreConfig
+	switch coreConfig.Network {
+	case "":
+	case pubnet:
+		cfg.captiveCoreToml = ledgerbackend.PubnetDefaultConfig
+		if coreConfig.NetworkPassphrase == "" {
+			coreConfig.NetworkPassphrase = network.PublicNetworkPassphrase
+		}
+		if len(coreConfig.HistoryArchiveUrls) == 0 {
+			coreConfig.HistoryArchiveUrls = network.PublicNetworkhistoryArchiveURLs
+		}
+	case testnet:
+		cfg.captiveCoreToml = ledgerbackend.TestnetDefaultConfig
+		if coreConfig.NetworkPassphrase == "" {
+			coreConfig.NetworkPassphrase = network.TestNetworkPassphrase
+		}
+		if len(coreConfig.HistoryArchiveUrls) == 0 {
+			coreConfig.HistoryArchiveUrls = network.TestNetworkhistoryArchiveURLs
+		}
+	default:
+		return nil, errors.Errorf("invalid network %s, it must be %s or %s", coreConfig.Network, pubnet, testnet)
+	}
+	if coreConfig.NetworkPassphrase == "" {
+		return nil, errors.New("stellar_core_config.network or stellar_core_config.network_passphrase must be set")
+	}
+
+	switch cfg.LedgerBackend {
+	case datastoreBackend, "":
+		cfg.LedgerBackend = datastoreBackend
+		if cfg.DataStoreConfig.Type == "" {
+			return nil, errors.New("datastore_config must be set to use the datastore ledger backend")
+		}
+	case captiveCoreBackend:
+		if coreConfig.CaptiveCoreTomlPath != "" {
+			if cfg.captiveCoreToml, err = os.ReadFile(coreConfig.CaptiveCoreTomlPath); err != nil {
+				return nil, errors.Wrap(err, "could not read captive core toml file")
+			}
+		}
+		if cfg.captiveCoreToml == nil || len(coreConfig.HistoryArchiveUrls) == 0 {
+			return nil, errors.New("stellar_core_config.network or stellar_core_config.captive_core_toml_path " +
+				"and stellar_core_config.history_archive_urls must be set to use the captive core ledger backend")
+		}
+		if coreConfig.StellarCoreBinaryPath == "" {
+			if coreConfig.StellarCoreBinaryPath, err = exec.LookPath("stellar-core"); err != nil {
+				return nil, errors.New("stellar_core_config.stellar_core_binary_path must be set, stellar-core was not found in PATH")
+			}
+		}
+	default:
+		return nil, errors.Errorf("invalid ledger_backend %s, it must be %s or %s", cfg.LedgerBackend, datastoreBackend, captiveCoreBackend)
+	}
+	return cfg, nil
+}
+
+// ledgerSource streams the ledgers of a bounded range to callback, in order.
+type ledgerSource func(ctx context.Context, ledgerRange ledgerbackend.Range, callback func(xdr.LedgerCloseMeta) error) error
+
+func (cfg *config) ledgerSource() ledgerSource {
+	if cfg.LedgerBackend == captiveCoreBackend {
+		return cfg.captiveCoreLedgers
+	}
+	return cfg.datastoreLedgers
+}
+
+func (cfg *config) datastoreLedgers(ctx context.Context, ledgerRange ledgerbackend.Range, callback func(xdr.LedgerCloseMeta) error) error {
+	return cdp.ApplyLedgerMetadata(ledgerRange, cdp.PublisherConfig{
+		DataStoreConfig:       cfg.DataStoreConfig,
+		BufferedStorageConfig: cdp.DefaultBufferedStorageBackendConfig(cfg.DataStoreConfig.Schema.LedgersPerFile),
+		Log:                   logger.WithField("subservice", "datastore"),
+	}, ctx, callback)
+}
+
+// captiveCoreLedgers runs a captive core instance for the range, every
+// instance has its own storage directory so ranges can be processed in
+// parallel.
+func (cfg *config) captiveCoreLedgers(ctx context.Context, ledgerRange ledgerbackend.Range, callback func(xdr.LedgerCloseMeta) error) error {
+	coreConfig := cfg.StellarCoreConfig
+	params := ledgerbackend.CaptiveCoreTomlParams{
+		NetworkPassphrase:  coreConfig.NetworkPassphrase,
+		HistoryArchiveURLs: coreConfig.HistoryArchiveUrls,
+		CoreBinaryPath:     coreConfig.StellarCoreBinaryPath,
+		UseDB:              true,
+	}
+	captiveCoreToml, err := ledgerbackend.NewCaptiveCoreTomlFromData(cfg.captiveCoreToml, params)
+	if err != nil {
+		return errors.Wrap(err, "could not create captive core toml")
+	}
+
+	storagePath, err := os.MkdirTemp(coreConfig.StoragePath, "captive-core-")
+	if err != nil {
+		return errors.Wrap(err, "could not create captive core storage directory")
+	}
+	defer os.RemoveAll(storagePath)
+
+	checkpointFrequency := uint32(historyarchive.DefaultCheckpointFrequency)
+	if coreConfig.CheckpointFrequency > 0 {
+		checkpointFrequency = coreConfig.CheckpointFrequency
+	}
+	backend, err := ledgerbackend.NewCaptive(ledgerbackend.CaptiveCoreConfig{
+		BinaryPath:          coreConfig.StellarCoreBinaryPath,
+		NetworkPassphrase:   coreConfig.NetworkPassphrase,
+		HistoryArchiveURLs:  coreConfig.HistoryArchiveUrls,
+		CheckpointFrequency: checkpointFrequency,
+		Log:                 logger.WithField("subservice", "stellar-core"),
+		Toml:                captiveCoreToml,
+		UserAgent:           userAgent,
+		UseDB:               true,
+		StoragePath:         storagePath,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not create captive core instance")
+	}
+	defer backend.Close()
+
+	if err = backend.PrepareRange(ctx, ledgerRange); err != nil {
+		return errors.Wrapf(err, "could not prepare range %v", ledgerRange)
+	}
+	for sequence := ledgerRange.From(); sequence <= ledgerRange.To(); sequence++ {
+		ledger, err := backend.GetLedger(ctx, sequence)
+		if err != nil {
+			return errors.Wrapf(err, "could not get ledger %d", sequence)
+		}
+		if err = callback(ledger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/ingest"
+	account "github.com/stellar/go/ingest/processors/account_processor"
+	claimablebalance "github.com/stellar/go/ingest/processors/claimable_balance_processor"
+	configsetting "github.com/stellar/go/ingest/processors/config_setting_processor"
+	contract "github.com/stellar/go/ingest/processors/contract_processor"
+	effect "github.com/stellar/go/ingest/processors/effects_processor"
+	ledger "github.com/stellar/go/ingest/processors/ledger_processor"
+	liquiditypool "github.com/stellar/go/ingest/processors/liquidity_pool_processor"
+	offer "github.com/stellar/go/ingest/processors/offer_processor"
+	operation "github.com/stellar/go/ingest/processors/operation_processor"
+	parquetwriter "github.com/stellar/go/ingest/processors/parquet_writer"
+	trade "github.com/stellar/go/ingest/processors/trade_processor"
+	transaction "github.com/stellar/go/ingest/processors/transaction_processor"
+	trustline "github.com/stellar/go/ingest/processors/trustline_processor"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	ndjsonFormat  = "ndjson"
+	parquetFormat = "parquet"
+)
+
+// ledgerData holds a ledger and the transactions and changes read from it,
+// which are shared by all the exports.
+type ledgerData struct {
+	meta              xdr.LedgerCloseMeta
+	header            xdr.LedgerHeaderHistoryEntry
+	sequence          uint32
+	closedAt          time.Time
+	networkPassphrase string
+	transactions      []ingest.LedgerTransaction
+	changes           []ingest.Change
+}
+
+func readLedger(networkPassphrase string, meta xdr.LedgerCloseMeta) (*ledgerData, error) {
+	data := &ledgerData{
+		meta:              meta,
+		header:            meta.LedgerHeaderHistoryEntry(),
+		sequence:          meta.LedgerSequence(),
+		closedAt:          time.Unix(meta.LedgerCloseTime(), 0).UTC(),
+		networkPassphrase: networkPassphrase,
+	}
+
+	txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(networkPassphrase, meta)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read transactions of ledger %d", data.sequence)
+	}
+	defer txReader.Close()
+	for {
+		tx, err := txReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read transactions of ledger %d", data.sequence)
+		}
+		data.transactions = append(data.transactions, tx)
+	}
+
+	changeReader, err := ingest.NewLedgerChangeReaderFromLedgerCloseMeta(networkPassphrase, meta)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read changes of ledger %d", data.sequence)
+	}
+	defer changeReader.Close()
+	for {
+		change, err := changeReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read changes of ledger %d", data.sequence)
+		}
+		data.changes = append(data.changes, change)
+	}
+	return data, nil
+}
+
+// historyLedger returns the ledger in the form expected by
+// ledger.TransformLedger.
+func (l *ledgerData) historyLedger() historyarchive.Ledger {
+	historyLedger := historyarchive.Ledger{
+		Header: l.header,
+		Transaction: xdr.TransactionHistoryEntry{
+			LedgerSeq: xdr.Uint32(l.sequence),
+		},
+		TransactionResult: xdr.TransactionHistoryResultEntry{
+			LedgerSeq: xdr.Uint32(l.sequence),
+		},
+	}
+	switch l.meta.V {
+	case 0:
+		historyLedger.Transaction.TxSet = l.meta.MustV0().TxSet
+	case 1:
+		txSet := l.meta.MustV1().TxSet
+		historyLedger.Transaction.Ext = xdr.TransactionHistoryEntryExt{V: 1, GeneralizedTxSet: &txSet}
+	}
+	for i := 0; i < l.meta.CountTransactions(); i++ {
+		historyLedger.TransactionResult.TxResultSet.Results = append(
+			historyLedger.TransactionResult.TxResultSet.Results,
+			l.meta.TransactionResultPair(i),
+		)
+	}
+	return historyLedger
+}
+
+// export transforms ledgers with one of the processors.
+type export struct {
+	name string
+	// open creates the writer of the outputs of a partition.
+	open func(out io.Writer, format string, parquetConfig parquetwriter.Config) (exportWriter, error)
+}
+
+type exportWriter interface {
+	// writeLedger transforms a ledger and writes the outputs.
+	writeLedger(l *ledgerData) error
+	close() error
+}
+
+type typedExportWriter[O any] struct {
+	transform func(l *ledgerData) ([]O, error)
+	write     func(outputs []O) error
+	closeFn   func() error
+}
+
+func (w typedExportWriter[O]) writeLedger(l *ledgerData) error {
+	outputs, err := w.transform(l)
+	if err != nil {
+		return err
+	}
+	return w.write(outputs)
+}
+
+func (w typedExportWriter[O]) close() error {
+	return w.closeFn()
+}
+
+func newExport[O, R any](schema parquetwriter.Schema[O, R], transform func(l *ledgerData) ([]O, error)) export {
+	return export{
+		name: schema.Name,
+		open: func(out io.Writer, format string, parquetConfig parquetwriter.Config) (exportWriter, error) {
+			writer := typedExportWriter[O]{transform: transform}
+			switch format {
+			case ndjsonFormat:
+				encoder := json.NewEncoder(out)
+				writer.write = func(outputs []O) error {
+					for _, output := range outputs {
+						if err := encoder.Encode(output); err != nil {
+							return errors.Wrapf(err, "could not encode %s output", schema.Name)
+						}
+					}
+					return nil
+				}
+				writer.closeFn = func() error { return nil }
+			case parquetFormat:
+				parquetWriter, err := parquetwriter.NewWriter(out, schema, parquetConfig)
+				if err != nil {
+					return nil, err
+				}
+				writer.write = func(outputs []O) error {
+					return parquetWriter.Write(outputs...)
+				}
+				writer.closeFn = parquetWriter.Close
+			default:
+				return nil, errors.Errorf("unknown format %s", format)
+			}
+			return writer, nil
+		},
+	}
+}
+
+// newStateExport creates an export of the changes of the ledger entries of
+// entryType.
+func newStateExport[O, R any](
+	schema parquetwriter.Schema[O, R],
+	entryType xdr.LedgerEntryType,
+	transform func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]O, error),
+) export {
+	return newExport(schema, func(l *ledgerData) ([]O, error) {
+		var outputs []O
+		for _, change := range l.changes {
+			if change.Type != entryType {
+				continue
+			}
+			transformed, err := transform(change, l.header)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not transform %s change in ledger %d", schema.Name, l.sequence)
+			}
+			outputs = append(outputs, transformed...)
+		}
+		return outputs, nil
+	})
+}
+
+// single adapts the transform functions returning a single output.
+func single[O any](transform func(ingest.Change, xdr.LedgerHeaderHistoryEntry) (O, error)) func(ingest.Change, xdr.LedgerHeaderHistoryEntry) ([]O, error) {
+	return func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]O, error) {
+		output, err := transform(change, header)
+		if err != nil {
+			return nil, err
+		}
+		return []O{output}, nil
+	}
+}
+
+var allExports = []export{
+	newExport(parquetwriter.Ledgers, func(l *ledgerData) ([]ledger.LedgerOutput, error) {
+		output, err := ledger.TransformLedger(l.historyLedger(), l.meta)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not transform ledger %d", l.sequence)
+		}
+		return []ledger.LedgerOutput{output}, nil
+	}),
+	newExport(parquetwriter.Transactions, func(l *ledgerData) ([]transaction.TransactionOutput, error) {
+		outputs := make([]transaction.TransactionOutput, 0, len(l.transactions))
+		for _, tx := range l.transactions {
+			output, err := transaction.TransformTransaction(tx, l.header)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not transform transaction %d in ledger %d", tx.Index, l.sequence)
+			}
+			outputs = append(outputs, output)
+		}
+		return outputs, nil
+	}),
+	newExport(parquetwriter.Operations, func(l *ledgerData) ([]operation.OperationOutput, error) {
+		var outputs []operation.OperationOutput
+		for _, tx := range l.transactions {
+			for i, op := range tx.Envelope.Operations() {
+				output, err := operation.TransformOperation(op, int32(i), tx, int32(l.sequence), l.meta, l.networkPassphrase)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not transform operation %d of transaction %d in ledger %d", i, tx.Index, l.sequence)
+				}
+				outputs = append(outputs, output)
+			}
+		}
+		return outputs, nil
+	}),
+	newExport(parquetwriter.Effects, func(l *ledgerData) ([]effect.EffectOutput, error) {
+		var outputs []effect.EffectOutput
+		for _, tx := range l.transactions {
+			effects, err := effect.TransformEffect(tx, l.sequence, l.meta, l.networkPassphrase)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not transform effects of transaction %d in ledger %d", tx.Index, l.sequence)
+			}
+			outputs = append(outputs, effects...)
+		}
+		return outputs, nil
+	}),
+	newExport(parquetwriter.Trades, func(l *ledgerData) ([]trade.TradeOutput, error) {
+		var outputs []trade.TradeOutput
+		for _, tx := range l.transactions {
+			if !tx.Result.Successful() {
+				continue
+			}
+			for i, op := range tx.Envelope.Operations() {
+				if !isTradeOperation(op.Body.Type) {
+					continue
+				}
+				operationID := toid.New(int32(l.sequence), int32(tx.Index), int32(i)).ToInt64()
+				trades, err := trade.TransformTrade(int32(i), operationID, tx, l.closedAt)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not transform trades of operation %d of transaction %d in ledger %d", i, tx.Index, l.sequence)
+				}
+				outputs = append(outputs, trades...)
+			}
+		}
+		return outputs, nil
+	}),
+	newExport(parquetwriter.ContractEvents, func(l *ledgerData) ([]contract.ContractEventOutput, error) {
+		var outputs []contract.ContractEventOutput
+		for _, tx := range l.transactions {
+			events, err := contract.TransformContractEvent(tx, l.header)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not transform contract events of transaction %d in ledger %d", tx.Index, l.sequence)
+			}
+			outputs = append(outputs, events...)
+		}
+		return outputs, nil
+	}),
+	newStateExport(parquetwriter.Accounts, xdr.LedgerEntryTypeAccount, single(account.TransformAccount)),
+	newStateExport(parquetwriter.AccountSigners, xdr.LedgerEntryTypeAccount, account.TransformAccountSigners),
+	newStateExport(parquetwriter.ClaimableBalances, xdr.LedgerEntryTypeClaimableBalance, single(claimablebalance.TransformClaimableBalance)),
+	newStateExport(parquetwriter.ConfigSettings, xdr.LedgerEntryTypeConfigSetting, single(configsetting.TransformConfigSetting)),
+	newExport(parquetwriter.ContractData, func(l *ledgerData) ([]contract.ContractDataOutput, error) {
+		transformer := contract.NewTransformContractDataStruct(contract.AssetFromContractData, contract.ContractBalanceFromContractData)
+		var outputs []contract.ContractDataOutput
+		for _, change := range l.changes {
+			if change.Type != xdr.LedgerEntryTypeContractData {
+				continue
+			}
+			output, err, ok := transformer.TransformContractData(change, l.networkPassphrase, l.header)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not transform contract_data change in ledger %d", l.sequence)
+			}
+			if ok {
+				outputs = append(outputs, output)
+			}
+		}
+		return outputs, nil
+	}),
+	newStateExport(parquetwriter.ContractCode, xdr.LedgerEntryTypeContractCode, single(contract.TransformContractCode)),
+	newStateExport(parquetwriter.Ttls, xdr.LedgerEntryTypeTtl, single(contract.TransformTtl)),
+	newStateExport(parquetwriter.LiquidityPools, xdr.LedgerEntryTypeLiquidityPool, single(liquiditypool.TransformPool)),
+	newStateExport(parquetwriter.Offers, xdr.LedgerEntryTypeOffer, single(offer.TransformOffer)),
+	newStateExport(parquetwriter.Trustlines, xdr.LedgerEntryTypeTrustline, single(trustline.TransformTrustline)),
+}
+
+func isTradeOperation(operationType xdr.OperationType) bool {
+	switch operationType {
+	case xdr.OperationTypeManageBuyOffer,
+		xdr.OperationTypeManageSellOffer,
+		xdr.OperationTypeCreatePassiveSellOffer,
+		xdr.OperationTypePathPaymentStrictReceive,
+		xdr.OperationTypePathPaymentStrictSend:
+		return true
+	}
+	return false
+}
+
+// selectExports returns the exports with the given names, or all of them if
+// names is empty.
+func selectExports(names []string) ([]export, error) {
+	if len(names) == 0 {
+		return allExports, nil
+	}
+	byName := map[string]export{}
+	for _, e := range allExports {
+		byName[e.name] = e
+	}
+	var selected []export
+	seen := map[string]bool{}
+	for _, name := range names {
+		e, ok := byName[name]
+		if !ok {
+			return nil, errors.Errorf("unknown export %s, valid exports are: %v", name, exportNames(allExports))
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, e)
+		}
+	}
+	return selected, nil
+}
+
+func exportNames(exports []export) []string {
+	names := make([]string, 0, len(exports))
+	for _, e := range exports {
+		names = append(names, e.name)
+	}
+	sort.Strings(names)
+	return names
+}
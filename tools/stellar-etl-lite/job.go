@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	parquetwriter "github.com/stellar/go/ingest/processors/parquet_writer"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+)
+
+// job transforms a range of ledgers, split into partitions which are written
+// to their own files and processed in parallel.
+type job struct {
+	settings          jobSettings
+	parallelism       int
+	outputDir         string
+	parquetConfig     parquetwriter.Config
+	exports           []export
+	networkPassphrase string
+	ledgers           ledgerSource
+	checkpoint        *checkpoint
+}
+
+// partitions splits the range of the job in partitions of PartitionSize
+// ledgers. The last partition is merged into the previous one when it would
+// hold a single ledger, as the datastore cannot stream single ledger ranges.
+func (j *job) partitions() []partition {
+	var partitions []partition
+	for from := j.settings.Start; from <= j.settings.End; from += j.settings.PartitionSize {
+		to := from + j.settings.PartitionSize - 1
+		if to > j.settings.End || to < from {
+			to = j.settings.End
+		}
+		if from == to && len(partitions) > 0 {
+			partitions[len(partitions)-1].To = to
+			break
+		}
+		partitions = append(partitions, partition{From: from, To: to})
+		if to == j.settings.End {
+			break
+		}
+	}
+	return partitions
+}
+
+func (j *job) run(ctx context.Context) error {
+	var pending []partition
+	for _, p := range j.partitions() {
+		if j.checkpoint.isCompleted(p) {
+			continue
+		}
+		pending = append(pending, p)
+	}
+	logger.Infof("Processing %d partitions of ledgers %d-%d with %d workers", len(pending), j.settings.Start, j.settings.End, j.parallelism)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan partition)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for i := 0; i < j.parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range queue {
+				if err := j.processPartition(ctx, p); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, p := range pending {
+		select {
+		case queue <- p:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+type partitionFile struct {
+	export  export
+	path    string
+	file    *os.File
+	buffer  *bufio.Writer
+	writer  exportWriter
+	written bool
+}
+
+func (j *job) partitionPath(e export, p partition) string {
+	return filepath.Join(j.outputDir, e.name, fmt.Sprintf("%d-%d.%s", p.From, p.To, j.settings.Format))
+}
+
+// processPartition writes the files of a partition. The files are written to
+// temporary paths and renamed once complete, so the output directory never
+// holds partial files.
+func (j *job) processPartition(ctx context.Context, p partition) error {
+	var err error
+	startTime := time.Now()
+	partitionLogger := logger.WithFields(log.F{"from": p.From, "to": p.To})
+	partitionLogger.Info("Processing partition")
+
+	files := make([]*partitionFile, 0, len(j.exports))
+	defer func() {
+		for _, f := range files {
+			if !f.written {
+				f.file.Close()
+				os.Remove(f.file.Name())
+			}
+		}
+	}()
+	for _, e := range j.exports {
+		path := j.partitionPath(e, p)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.Wrapf(err, "could not create directory of %s", path)
+		}
+		var file *os.File
+		if file, err = os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*"); err != nil {
+			return errors.Wrapf(err, "could not create %s", path)
+		}
+		f := &partitionFile{export: e, path: path, file: file, buffer: bufio.NewWriter(file)}
+		files = append(files, f)
+		if f.writer, err = e.open(f.buffer, j.settings.Format, j.parquetConfig); err != nil {
+			return errors.Wrapf(err, "could not create %s writer", e.name)
+		}
+	}
+
+	err = j.ledgers(ctx, ledgerbackend.BoundedRange(p.From, p.To), func(meta xdr.LedgerCloseMeta) error {
+		data, err := readLedger(j.networkPassphrase, meta)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if err = f.writer.writeLedger(data); err != nil {
+				return errors.Wrapf(err, "could not write %s of ledger %d", f.export.name, data.sequence)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not process ledgers %d-%d", p.From, p.To)
+	}
+
+	for _, f := range files {
+		if err = f.writer.close(); err != nil {
+			return errors.Wrapf(err, "could not close %s", f.path)
+		}
+		if err = f.buffer.Flush(); err != nil {
+			return errors.Wrapf(err, "could not write %s", f.path)
+		}
+		if err = f.file.Close(); err != nil {
+			return errors.Wrapf(err, "could not close %s", f.path)
+		}
+		if err = os.Rename(f.file.Name(), f.path); err != nil {
+			return errors.Wrapf(err, "could not rename %s", f.path)
+		}
+		f.written = true
+	}
+
+	if err = j.checkpoint.complete(p); err != nil {
+		return err
+	}
+	partitionLogger.WithField("duration", time.Since(startTime).Seconds()).Info("Processed partition")
+	return nil
+}
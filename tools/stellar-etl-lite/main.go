@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	parquetwriter "github.com/stellar/go/ingest/processors/parquet_writer"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+var logger = log.New().WithField("service", "stellar-etl-lite")
+
+type options struct {
+	configFile     string
+	start          uint32
+	end            uint32
+	exports        []string
+	format         string
+	outputDir      string
+	partitionSize  uint32
+	parallelism    int
+	checkpointFile string
+	compression    string
+	rowGroupSize   int64
+}
+
+func main() {
+	if err := newCommand().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newCommand() *cobra.Command {
+	opts := options{}
+	cmd := &cobra.Command{
+		Use:   "stellar-etl-lite",
+		Short: "Transform a range of ledgers with the ingest processors",
+		Long: "Reads the ledgers of a range from a datastore or captive core, transforms them with the " +
+			"ingest/processors packages and writes the outputs to NDJSON or Parquet files partitioned by ledger range.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			cfg, err := loadConfig(opts.configFile)
+			if err != nil {
+				return err
+			}
+			j, err := newJob(opts, cfg.StellarCoreConfig.NetworkPassphrase, cfg.ledgerSource())
+			if err != nil {
+				return err
+			}
+			return j.run(ctx)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.configFile, "config-file", "config.toml", "Path to the TOML config file of the ledger backend")
+	flags.Uint32VarP(&opts.start, "start", "s", 0, "First ledger of the range (inclusive), must be greater than 1")
+	flags.Uint32VarP(&opts.end, "end", "e", 0, "Last ledger of the range (inclusive), must be greater than start")
+	flags.StringSliceVar(&opts.exports, "exports", nil, "Comma separated list of the outputs to export, defaults to all of them: "+
+		strings.Join(exportNames(allExports), ", "))
+	flags.StringVar(&opts.format, "format", ndjsonFormat, "Format of the files, ndjson or parquet")
+	flags.StringVarP(&opts.outputDir, "output", "o", "output", "Directory of the files, written to <output>/<export>/<from>-<to>.<format>")
+	flags.Uint32Var(&opts.partitionSize, "partition-size", 1000, "Number of ledgers of every file")
+	flags.IntVar(&opts.parallelism, "parallelism", 1, "Number of partitions processed in parallel")
+	flags.StringVar(&opts.checkpointFile, "checkpoint-file", "", "File recording the partitions already written, "+
+		"used to resume an interrupted job, defaults to <output>/checkpoint.json")
+	flags.StringVar(&opts.compression, "parquet-compression", string(parquetwriter.Snappy), "Compression of the Parquet files: none, snappy, gzip, zstd, lz4 or brotli")
+	flags.Int64Var(&opts.rowGroupSize, "parquet-row-group-size", parquetwriter.DefaultRowGroupSize, "Maximum number of rows of the row groups of the Parquet files")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("end")
+	return cmd
+}
+
+func newJob(opts options, networkPassphrase string, ledgers ledgerSource) (*job, error) {
+	if opts.start < 2 {
+		return nil, errors.New("invalid start, it must be greater than 1")
+	}
+	if opts.end <= opts.start {
+		return nil, errors.New("invalid end, it must be greater than start")
+	}
+	if opts.partitionSize < 2 {
+		return nil, errors.New("invalid partition size, it must be greater than 1")
+	}
+	if opts.parallelism < 1 {
+		return nil, errors.New("invalid parallelism, it must be positive")
+	}
+	if opts.format != ndjsonFormat && opts.format != parquetFormat {
+		return nil, errors.Errorf("invalid format %s, it must be %s or %s", opts.format, ndjsonFormat, parquetFormat)
+	}
+	exports, err := selectExports(opts.exports)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := jobSettings{
+		Start:         opts.start,
+		End:           opts.end,
+		PartitionSize: opts.partitionSize,
+		Exports:       exportNames(exports),
+		Format:        opts.format,
+	}
+	checkpointFile := opts.checkpointFile
+	if checkpointFile == "" {
+		checkpointFile = filepath.Join(opts.outputDir, "checkpoint.json")
+	}
+	checkpoint, err := openCheckpoint(checkpointFile, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job{
+		settings:    settings,
+		parallelism: opts.parallelism,
+		outputDir:   opts.outputDir,
+		parquetConfig: parquetwriter.Config{
+			RowGroupSize: opts.rowGroupSize,
+			Compression:  parquetwriter.Compression(opts.compression),
+		},
+		exports:           exports,
+		networkPassphrase: networkPassphrase,
+		ledgers:           ledgers,
+		checkpoint:        checkpoint,
+	}, nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	parquetwriter "github.com/stellar/go/ingest/processors/parquet_writer"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// fakeLedgers emits empty ledgers and records the ranges it was asked for.
+type fakeLedgers struct {
+	lock   sync.Mutex
+	ranges []partition
+	failOn uint32
+}
+
+func (f *fakeLedgers) source(ctx context.Context, ledgerRange ledgerbackend.Range, callback func(xdr.LedgerCloseMeta) error) error {
+	f.lock.Lock()
+	f.ranges = append(f.ranges, partition{From: ledgerRange.From(), To: ledgerRange.To()})
+	f.lock.Unlock()
+
+	for sequence := ledgerRange.From(); sequence <= ledgerRange.To(); sequence++ {
+		if sequence == f.failOn {
+			return errors.New("ledger not found")
+		}
+		meta := xdr.LedgerCloseMeta{
+			V: 0,
+			V0: &xdr.LedgerCloseMetaV0{
+				LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+					Header: xdr.LedgerHeader{
+						LedgerSeq: xdr.Uint32(sequence),
+						ScpValue:  xdr.StellarValue{CloseTime: xdr.TimePoint(1700000000 + 5*int64(sequence))},
+					},
+				},
+			},
+		}
+		if err := callback(meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testOptions(t *testing.T) options {
+	return options{
+		start:         2,
+		end:           10,
+		exports:       []string{"ledgers", "transactions"},
+		format:        ndjsonFormat,
+		outputDir:     t.TempDir(),
+		partitionSize: 4,
+		parallelism:   2,
+		compression:   string(parquetwriter.Snappy),
+		rowGroupSize:  parquetwriter.DefaultRowGroupSize,
+	}
+}
+
+func TestPartitions(t *testing.T) {
+	for _, testCase := range []struct {
+		name       string
+		settings   jobSettings
+		partitions []partition
+	}{
+		{
+			name:       "exact",
+			settings:   jobSettings{Start: 2, End: 9, PartitionSize: 4},
+			partitions: []partition{{2, 5}, {6, 9}},
+		},
+		{
+			name:       "shorter last partition",
+			settings:   jobSettings{Start: 2, End: 8, PartitionSize: 4},
+			partitions: []partition{{2, 5}, {6, 8}},
+		},
+		{
+			name:       "single ledger last partition",
+			settings:   jobSettings{Start: 2, End: 10, PartitionSize: 4},
+			partitions: []partition{{2, 5}, {6, 10}},
+		},
+		{
+			name:       "range smaller than partition",
+			settings:   jobSettings{Start: 2, End: 3, PartitionSize: 4},
+			partitions: []partition{{2, 3}},
+		},
+		{
+			name:       "end of ledger sequences",
+			settings:   jobSettings{Start: 4294967290, End: 4294967295, PartitionSize: 4},
+			partitions: []partition{{4294967290, 4294967293}, {4294967294, 4294967295}},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			j := &job{settings: testCase.settings}
+			assert.Equal(t, testCase.partitions, j.partitions())
+		})
+	}
+}
+
+func TestNewJobValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		name   string
+		modify func(*options)
+		err    string
+	}{
+		{"start", func(o *options) { o.start = 1 }, "invalid start, it must be greater than 1"},
+		{"end", func(o *options) { o.end = o.start }, "invalid end, it must be greater than start"},
+		{"partition size", func(o *options) { o.partitionSize = 1 }, "invalid partition size, it must be greater than 1"},
+		{"parallelism", func(o *options) { o.parallelism = 0 }, "invalid parallelism, it must be positive"},
+		{"format", func(o *options) { o.format = "csv" }, "invalid format csv, it must be ndjson or parquet"},
+		{"exports", func(o *options) { o.exports = []string{"ledgers", "blocks"} }, "unknown export blocks"},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			opts := testOptions(t)
+			testCase.modify(&opts)
+			_, err := newJob(opts, network.TestNetworkPassphrase, (&fakeLedgers{}).source)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+}
+
+func TestSelectExports(t *testing.T) {
+	exports, err := selectExports(nil)
+	require.NoError(t, err)
+	assert.Equal(t, exportNames(allExports), exportNames(exports))
+
+	exports, err = selectExports([]string{"trades", "ledgers"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ledgers", "trades"}, exportNames(exports))
+}
+
+func readNDJSON(t *testing.T, path string) []map[string]interface{} {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var rows []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		row := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+		rows = append(rows, row)
+	}
+	require.NoError(t, scanner.Err())
+	return rows
+}
+
+func TestRunNDJSON(t *testing.T) {
+	opts := testOptions(t)
+	ledgers := &fakeLedgers{}
+	j, err := newJob(opts, network.TestNetworkPassphrase, ledgers.source)
+	require.NoError(t, err)
+	require.NoError(t, j.run(context.Background()))
+	assert.ElementsMatch(t, []partition{{2, 5}, {6, 10}}, ledgers.ranges)
+
+	rows := readNDJSON(t, filepath.Join(opts.outputDir, "ledgers", "2-5.ndjson"))
+	require.Len(t, rows, 4)
+	for i, row := range rows {
+		assert.EqualValues(t, 2+i, row["sequence"])
+	}
+	assert.Len(t, readNDJSON(t, filepath.Join(opts.outputDir, "ledgers", "6-10.ndjson")), 5)
+	assert.Empty(t, readNDJSON(t, filepath.Join(opts.outputDir, "transactions", "2-5.ndjson")))
+
+	contents, err := os.ReadFile(filepath.Join(opts.outputDir, "checkpoint.json"))
+	require.NoError(t, err)
+	var state checkpointState
+	require.NoError(t, json.Unmarshal(contents, &state))
+	assert.Equal(t, j.settings, state.Settings)
+	assert.ElementsMatch(t, []partition{{2, 5}, {6, 10}}, state.Completed)
+}
+
+func TestRunParquet(t *testing.T) {
+	opts := testOptions(t)
+	opts.format = parquetFormat
+	opts.exports = []string{"ledgers"}
+	j, err := newJob(opts, network.TestNetworkPassphrase, (&fakeLedgers{}).source)
+	require.NoError(t, err)
+	require.NoError(t, j.run(context.Background()))
+
+	rows, err := parquet.ReadFile[parquetwriter.LedgerRow](filepath.Join(opts.outputDir, "ledgers", "6-10.parquet"))
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	for i, row := range rows {
+		assert.EqualValues(t, 6+i, row.Sequence)
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	opts := testOptions(t)
+	ledgers := &fakeLedgers{failOn: 7}
+	j, err := newJob(opts, network.TestNetworkPassphrase, ledgers.source)
+	require.NoError(t, err)
+	err = j.run(context.Background())
+	require.EqualError(t, err, "could not process ledgers 6-10: ledger not found")
+
+	assert.FileExists(t, filepath.Join(opts.outputDir, "ledgers", "2-5.ndjson"))
+	entries, err := os.ReadDir(filepath.Join(opts.outputDir, "ledgers"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "partial files must be removed")
+
+	ledgers = &fakeLedgers{}
+	j, err = newJob(opts, network.TestNetworkPassphrase, ledgers.source)
+	require.NoError(t, err)
+	require.NoError(t, j.run(context.Background()))
+	assert.Equal(t, []partition{{6, 10}}, ledgers.ranges)
+	assert.FileExists(t, filepath.Join(opts.outputDir, "ledgers", "6-10.ndjson"))
+
+	ledgers = &fakeLedgers{}
+	j, err = newJob(opts, network.TestNetworkPassphrase, ledgers.source)
+	require.NoError(t, err)
+	require.NoError(t, j.run(context.Background()))
+	assert.Empty(t, ledgers.ranges)
+}
+
+func TestCheckpointSettingsMismatch(t *testing.T) {
+	opts := testOptions(t)
+	_, err := newJob(opts, network.TestNetworkPassphrase, (&fakeLedgers{}).source)
+	require.NoError(t, err)
+
+	opts.partitionSize = 5
+	_, err = newJob(opts, network.TestNetworkPassphrase, (&fakeLedgers{}).source)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "belongs to a job with different settings")
+}
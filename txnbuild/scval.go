@@ -0,0 +1,456 @@
+package txnbuild
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/holiman/uint256"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ScAddress is the strkey of an account (G...) or contract (C...). It is
+// converted to and from xdr.ScAddress values by ToScVal and FromScVal.
+type ScAddress string
+
+// ToXDR returns the xdr.ScAddress of the account or contract.
+func (a ScAddress) ToXDR() (xdr.ScAddress, error) {
+	version, err := strkey.Version(string(a))
+	if err != nil {
+		return xdr.ScAddress{}, errors.Wrapf(err, "invalid address %q", string(a))
+	}
+	switch version {
+	case strkey.VersionByteAccountID:
+		accountID, err := xdr.AddressToAccountId(string(a))
+		if err != nil {
+			return xdr.ScAddress{}, err
+		}
+		return xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &accountID}, nil
+	case strkey.VersionByteContract:
+		raw, err := strkey.Decode(strkey.VersionByteContract, string(a))
+		if err != nil {
+			return xdr.ScAddress{}, err
+		}
+		var contractID xdr.Hash
+		copy(contractID[:], raw)
+		return xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID}, nil
+	default:
+		return xdr.ScAddress{}, errors.Errorf("%q is not an account or contract address", string(a))
+	}
+}
+
+var (
+	minInt128 = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	maxInt128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	two128    = new(big.Int).Lsh(big.NewInt(1), 128)
+	mask64    = new(big.Int).SetUint64(^uint64(0))
+)
+
+// ToScVal converts a native Go value into an xdr.ScVal. The supported types
+// are:
+//   - nil: void
+//   - bool: bool
+//   - int8, int16, int32: i32
+//   - uint8, uint16, uint32: u32
+//   - int, int64: i64
+//   - uint, uint64: u64
+//   - *big.Int: i128
+//   - *uint256.Int: u256
+//   - string: string
+//   - xdr.ScSymbol: symbol
+//   - []byte: bytes
+//   - ScAddress and xdr.ScAddress: address
+//   - xdr.Int128Parts, xdr.UInt128Parts, xdr.Int256Parts and xdr.UInt256Parts:
+//     i128, u128, i256 and u256 respectively
+//   - xdr.ScVal: returned unchanged
+//   - slices of any supported type: vec
+//   - maps with keys and values of any supported type: map, sorted by key
+//
+// Pointers are dereferenced, nil pointers are converted to void.
+func ToScVal(value interface{}) (xdr.ScVal, error) {
+	switch v := value.(type) {
+	case nil:
+		return xdr.ScVal{Type: xdr.ScValTypeScvVoid}, nil
+	case xdr.ScVal:
+		return v, nil
+	case bool:
+		return xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &v}, nil
+	case int8:
+		return newI32(int32(v)), nil
+	case int16:
+		return newI32(int32(v)), nil
+	case int32:
+		return newI32(v), nil
+	case uint8:
+		return newU32(uint32(v)), nil
+	case uint16:
+		return newU32(uint32(v)), nil
+	case uint32:
+		return newU32(v), nil
+	case int:
+		i64 := xdr.Int64(v)
+		return xdr.ScVal{Type: xdr.ScValTypeScvI64, I64: &i64}, nil
+	case int64:
+		i64 := xdr.Int64(v)
+		return xdr.ScVal{Type: xdr.ScValTypeScvI64, I64: &i64}, nil
+	case uint:
+		u64 := xdr.Uint64(v)
+		return xdr.ScVal{Type: xdr.ScValTypeScvU64, U64: &u64}, nil
+	case uint64:
+		u64 := xdr.Uint64(v)
+		return xdr.ScVal{Type: xdr.ScValTypeScvU64, U64: &u64}, nil
+	case *big.Int:
+		if v == nil {
+			return xdr.ScVal{Type: xdr.ScValTypeScvVoid}, nil
+		}
+		parts, err := bigIntToInt128Parts(v)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &parts}, nil
+	case *uint256.Int:
+		if v == nil {
+			return xdr.ScVal{Type: xdr.ScValTypeScvVoid}, nil
+		}
+		parts := xdr.UInt256Parts{
+			HiHi: xdr.Uint64(v[3]),
+			HiLo: xdr.Uint64(v[2]),
+			LoHi: xdr.Uint64(v[1]),
+			LoLo: xdr.Uint64(v[0]),
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvU256, U256: &parts}, nil
+	case xdr.Int128Parts:
+		return xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &v}, nil
+	case xdr.UInt128Parts:
+		return xdr.ScVal{Type: xdr.ScValTypeScvU128, U128: &v}, nil
+	case xdr.Int256Parts:
+		return xdr.ScVal{Type: xdr.ScValTypeScvI256, I256: &v}, nil
+	case xdr.UInt256Parts:
+		return xdr.ScVal{Type: xdr.ScValTypeScvU256, U256: &v}, nil
+	case string:
+		str := xdr.ScString(v)
+		return xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &str}, nil
+	case xdr.ScSymbol:
+		return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &v}, nil
+	case []byte:
+		b := xdr.ScBytes(v)
+		return xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &b}, nil
+	case ScAddress:
+		address, err := v.ToXDR()
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		return xdr.ScVal{Type: xdr.ScValTypeScvAddress, Address: &address}, nil
+	case xdr.ScAddress:
+		return xdr.ScVal{Type: xdr.ScValTypeScvAddress, Address: &v}, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return xdr.ScVal{Type: xdr.ScValTypeScvVoid}, nil
+		}
+		return ToScVal(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		vec := make(xdr.ScVec, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			item, err := ToScVal(rv.Index(i).Interface())
+			if err != nil {
+				return xdr.ScVal{}, errors.Wrapf(err, "invalid vec element %d", i)
+			}
+			vec = append(vec, item)
+		}
+		vecPtr := &vec
+		return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &vecPtr}, nil
+	case reflect.Map:
+		scMap := make(xdr.ScMap, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := ToScVal(iter.Key().Interface())
+			if err != nil {
+				return xdr.ScVal{}, errors.Wrapf(err, "invalid map key %v", iter.Key().Interface())
+			}
+			val, err := ToScVal(iter.Value().Interface())
+			if err != nil {
+				return xdr.ScVal{}, errors.Wrapf(err, "invalid map value for key %v", iter.Key().Interface())
+			}
+			scMap = append(scMap, xdr.ScMapEntry{Key: key, Val: val})
+		}
+		if err := sortScMap(scMap); err != nil {
+			return xdr.ScVal{}, err
+		}
+		mapPtr := &scMap
+		return xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &mapPtr}, nil
+	}
+
+	return xdr.ScVal{}, errors.Errorf("unsupported type %T", value)
+}
+
+// FromScVal converts an xdr.ScVal into a native Go value. It is the inverse
+// of ToScVal:
+//   - void: nil
+//   - bool: bool
+//   - u32, i32, u64, i64: uint32, int32, uint64, int64
+//   - timepoint, duration: xdr.TimePoint, xdr.Duration
+//   - u128, i128, i256: *big.Int
+//   - u256: *uint256.Int
+//   - bytes: []byte
+//   - string: string
+//   - symbol: xdr.ScSymbol
+//   - address: ScAddress
+//   - error: xdr.ScError
+//   - vec: []interface{}
+//   - map: map[interface{}]interface{}, keys must convert to comparable
+//     values, i.e. bytes, vec, map and big integer keys are not supported
+func FromScVal(value xdr.ScVal) (interface{}, error) {
+	switch value.Type {
+	case xdr.ScValTypeScvVoid:
+		return nil, nil
+	case xdr.ScValTypeScvBool:
+		return value.MustB(), nil
+	case xdr.ScValTypeScvU32:
+		return uint32(value.MustU32()), nil
+	case xdr.ScValTypeScvI32:
+		return int32(value.MustI32()), nil
+	case xdr.ScValTypeScvU64:
+		return uint64(value.MustU64()), nil
+	case xdr.ScValTypeScvI64:
+		return int64(value.MustI64()), nil
+	case xdr.ScValTypeScvTimepoint:
+		return value.MustTimepoint(), nil
+	case xdr.ScValTypeScvDuration:
+		return value.MustDuration(), nil
+	case xdr.ScValTypeScvU128:
+		parts := value.MustU128()
+		return bigIntFromWords(false, uint64(parts.Hi), uint64(parts.Lo)), nil
+	case xdr.ScValTypeScvI128:
+		parts := value.MustI128()
+		return bigIntFromWords(true, uint64(parts.Hi), uint64(parts.Lo)), nil
+	case xdr.ScValTypeScvU256:
+		parts := value.MustU256()
+		return &uint256.Int{uint64(parts.LoLo), uint64(parts.LoHi), uint64(parts.HiLo), uint64(parts.HiHi)}, nil
+	case xdr.ScValTypeScvI256:
+		parts := value.MustI256()
+		return bigIntFromWords(true, uint64(parts.HiHi), uint64(parts.HiLo), uint64(parts.LoHi), uint64(parts.LoLo)), nil
+	case xdr.ScValTypeScvBytes:
+		return []byte(value.MustBytes()), nil
+	case xdr.ScValTypeScvString:
+		return string(value.MustStr()), nil
+	case xdr.ScValTypeScvSymbol:
+		return value.MustSym(), nil
+	case xdr.ScValTypeScvAddress:
+		address, err := value.MustAddress().String()
+		if err != nil {
+			return nil, err
+		}
+		return ScAddress(address), nil
+	case xdr.ScValTypeScvError:
+		return value.MustError(), nil
+	case xdr.ScValTypeScvVec:
+		vec := value.MustVec()
+		if vec == nil {
+			return []interface{}{}, nil
+		}
+		result := make([]interface{}, 0, len(*vec))
+		for i, item := range *vec {
+			converted, err := FromScVal(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid vec element %d", i)
+			}
+			result = append(result, converted)
+		}
+		return result, nil
+	case xdr.ScValTypeScvMap:
+		scMap := value.MustMap()
+		result := map[interface{}]interface{}{}
+		if scMap == nil {
+			return result, nil
+		}
+		for _, entry := range *scMap {
+			key, err := FromScVal(entry.Key)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid map key")
+			}
+			if !isMapKey(key) {
+				return nil, errors.Errorf("map key of type %s cannot be used as a Go map key", entry.Key.Type)
+			}
+			val, err := FromScVal(entry.Val)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid map value for key %v", key)
+			}
+			result[key] = val
+		}
+		return result, nil
+	default:
+		return nil, errors.Errorf("unsupported ScVal type %s", value.Type)
+	}
+}
+
+// isMapKey returns true if v can be used as a key of a Go map and compares
+// by value.
+func isMapKey(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	t := reflect.TypeOf(v)
+	return t.Comparable() && t.Kind() != reflect.Ptr
+}
+
+func newI32(v int32) xdr.ScVal {
+	i32 := xdr.Int32(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvI32, I32: &i32}
+}
+
+func newU32(v uint32) xdr.ScVal {
+	u32 := xdr.Uint32(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u32}
+}
+
+func bigIntToInt128Parts(v *big.Int) (xdr.Int128Parts, error) {
+	if v.Cmp(minInt128) < 0 || v.Cmp(maxInt128) > 0 {
+		return xdr.Int128Parts{}, errors.Errorf("%s does not fit in an i128", v.String())
+	}
+	// two's complement representation of negative values
+	unsigned := new(big.Int).Set(v)
+	if unsigned.Sign() < 0 {
+		unsigned.Add(unsigned, two128)
+	}
+	lo := new(big.Int).And(unsigned, mask64).Uint64()
+	hi := new(big.Int).Rsh(unsigned, 64).Uint64()
+	return xdr.Int128Parts{Hi: xdr.Int64(int64(hi)), Lo: xdr.Uint64(lo)}, nil
+}
+
+// bigIntFromWords builds an integer from its 64 bit words, most significant
+// first. If signed is true the words are a two's complement representation.
+func bigIntFromWords(signed bool, words ...uint64) *big.Int {
+	result := new(big.Int)
+	for _, word := range words {
+		result.Lsh(result, 64)
+		result.Or(result, new(big.Int).SetUint64(word))
+	}
+	if signed && words[0]>>63 == 1 {
+		result.Sub(result, new(big.Int).Lsh(big.NewInt(1), uint(64*len(words))))
+	}
+	return result
+}
+
+// sortScMap sorts the map entries by key, as required by the Soroban host.
+func sortScMap(scMap xdr.ScMap) error {
+	var sortErr error
+	sort.Slice(scMap, func(i, j int) bool {
+		cmp, err := compareScVal(scMap[i].Key, scMap[j].Key)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return cmp < 0
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+	for i := 1; i < len(scMap); i++ {
+		if cmp, _ := compareScVal(scMap[i-1].Key, scMap[i].Key); cmp == 0 {
+			return errors.Errorf("duplicate map key %s", scMap[i].Key.String())
+		}
+	}
+	return nil
+}
+
+// compareScVal orders values the same way as the Soroban host: first by type
+// and then by value.
+func compareScVal(a, b xdr.ScVal) (int, error) {
+	if a.Type != b.Type {
+		if a.Type < b.Type {
+			return -1, nil
+		}
+		return 1, nil
+	}
+
+	switch a.Type {
+	case xdr.ScValTypeScvVoid:
+		return 0, nil
+	case xdr.ScValTypeScvBool:
+		return compareBool(a.MustB(), b.MustB()), nil
+	case xdr.ScValTypeScvU32, xdr.ScValTypeScvI32, xdr.ScValTypeScvU64, xdr.ScValTypeScvI64,
+		xdr.ScValTypeScvTimepoint, xdr.ScValTypeScvDuration, xdr.ScValTypeScvU128,
+		xdr.ScValTypeScvI128, xdr.ScValTypeScvU256, xdr.ScValTypeScvI256:
+		x, err := scValToBigInt(a)
+		if err != nil {
+			return 0, err
+		}
+		y, err := scValToBigInt(b)
+		if err != nil {
+			return 0, err
+		}
+		return x.Cmp(y), nil
+	case xdr.ScValTypeScvBytes:
+		return bytes.Compare(a.MustBytes(), b.MustBytes()), nil
+	case xdr.ScValTypeScvString:
+		return bytes.Compare([]byte(a.MustStr()), []byte(b.MustStr())), nil
+	case xdr.ScValTypeScvSymbol:
+		return bytes.Compare([]byte(a.MustSym()), []byte(b.MustSym())), nil
+	case xdr.ScValTypeScvAddress:
+		// addresses are ordered by type and then by their key, which matches
+		// the order of their XDR encoding
+		x, err := a.MustAddress().MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		y, err := b.MustAddress().MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(x, y), nil
+	default:
+		return 0, fmt.Errorf("map keys of type %s are not supported", a.Type)
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func scValToBigInt(v xdr.ScVal) (*big.Int, error) {
+	switch v.Type {
+	case xdr.ScValTypeScvU32:
+		return new(big.Int).SetUint64(uint64(v.MustU32())), nil
+	case xdr.ScValTypeScvI32:
+		return big.NewInt(int64(v.MustI32())), nil
+	case xdr.ScValTypeScvU64:
+		return new(big.Int).SetUint64(uint64(v.MustU64())), nil
+	case xdr.ScValTypeScvI64:
+		return big.NewInt(int64(v.MustI64())), nil
+	case xdr.ScValTypeScvTimepoint:
+		return new(big.Int).SetUint64(uint64(v.MustTimepoint())), nil
+	case xdr.ScValTypeScvDuration:
+		return new(big.Int).SetUint64(uint64(v.MustDuration())), nil
+	case xdr.ScValTypeScvU256:
+		converted, err := FromScVal(v)
+		if err != nil {
+			return nil, err
+		}
+		return converted.(*uint256.Int).ToBig(), nil
+	default:
+		converted, err := FromScVal(v)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := converted.(*big.Int)
+		if !ok {
+			return nil, errors.Errorf("%s is not an integer", v.Type)
+		}
+		return i, nil
+	}
+}
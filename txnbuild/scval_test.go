@@ -0,0 +1,137 @@
+package txnbuild
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/xdr"
+)
+
+const testContractID = "CA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQGAXE"
+
+func TestToScValRoundTrip(t *testing.T) {
+	maxU256 := new(uint256.Int).SetAllOne()
+	minI128, ok := new(big.Int).SetString("-170141183460469231731687303715884105728", 10)
+	require.True(t, ok)
+
+	for _, testCase := range []struct {
+		name     string
+		value    interface{}
+		scType   xdr.ScValType
+		expected interface{}
+	}{
+		{"void", nil, xdr.ScValTypeScvVoid, nil},
+		{"bool", true, xdr.ScValTypeScvBool, true},
+		{"i32", int32(-5), xdr.ScValTypeScvI32, int32(-5)},
+		{"u32", uint32(5), xdr.ScValTypeScvU32, uint32(5)},
+		{"int", 42, xdr.ScValTypeScvI64, int64(42)},
+		{"i64", int64(-42), xdr.ScValTypeScvI64, int64(-42)},
+		{"u64", uint64(42), xdr.ScValTypeScvU64, uint64(42)},
+		{"i128", big.NewInt(-12345), xdr.ScValTypeScvI128, big.NewInt(-12345)},
+		{"min i128", minI128, xdr.ScValTypeScvI128, minI128},
+		{"u256", uint256.NewInt(7), xdr.ScValTypeScvU256, uint256.NewInt(7)},
+		{"max u256", maxU256, xdr.ScValTypeScvU256, maxU256},
+		{"string", "hello", xdr.ScValTypeScvString, "hello"},
+		{"symbol", xdr.ScSymbol("transfer"), xdr.ScValTypeScvSymbol, xdr.ScSymbol("transfer")},
+		{"bytes", []byte{1, 2, 3}, xdr.ScValTypeScvBytes, []byte{1, 2, 3}},
+		{"account", ScAddress(newKeypair0().Address()), xdr.ScValTypeScvAddress, ScAddress(newKeypair0().Address())},
+		{"contract", ScAddress(testContractID), xdr.ScValTypeScvAddress, ScAddress(testContractID)},
+		{"vec", []uint32{1, 2}, xdr.ScValTypeScvVec, []interface{}{uint32(1), uint32(2)}},
+		{
+			"map",
+			map[string]interface{}{"b": int64(2), "a": []interface{}{"x"}},
+			xdr.ScValTypeScvMap,
+			map[interface{}]interface{}{"b": int64(2), "a": []interface{}{"x"}},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			scVal, err := ToScVal(testCase.value)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.scType, scVal.Type)
+
+			// make sure the value can be encoded
+			_, err = xdr.MarshalBase64(scVal)
+			require.NoError(t, err)
+
+			value, err := FromScVal(scVal)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, value)
+		})
+	}
+}
+
+func TestToScValI128Parts(t *testing.T) {
+	scVal, err := ToScVal(big.NewInt(-1))
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int128Parts{Hi: -1, Lo: xdr.Uint64(^uint64(0))}, scVal.MustI128())
+
+	large := new(big.Int).Lsh(big.NewInt(3), 64)
+	large.Add(large, big.NewInt(9))
+	scVal, err = ToScVal(large)
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int128Parts{Hi: 3, Lo: 9}, scVal.MustI128())
+
+	_, err = ToScVal(new(big.Int).Lsh(big.NewInt(1), 127))
+	assert.EqualError(t, err, "170141183460469231731687303715884105728 does not fit in an i128")
+}
+
+func TestToScValMapOrder(t *testing.T) {
+	scVal, err := ToScVal(map[interface{}]interface{}{
+		uint32(10):                         "u32",
+		uint32(2):                          "u32",
+		int64(-1):                          "i64",
+		xdr.ScSymbol("b"):                  "symbol",
+		xdr.ScSymbol("a"):                  "symbol",
+		ScAddress(testContractID):          "contract",
+		ScAddress(newKeypair0().Address()): "account",
+	})
+	require.NoError(t, err)
+
+	var keys []interface{}
+	for _, entry := range *scVal.MustMap() {
+		key, err := FromScVal(entry.Key)
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []interface{}{
+		uint32(2),
+		uint32(10),
+		int64(-1),
+		xdr.ScSymbol("a"),
+		xdr.ScSymbol("b"),
+		ScAddress(newKeypair0().Address()),
+		ScAddress(testContractID),
+	}, keys)
+}
+
+func TestToScValErrors(t *testing.T) {
+	_, err := ToScVal(struct{}{})
+	assert.EqualError(t, err, "unsupported type struct {}")
+
+	_, err = ToScVal([]interface{}{1, struct{}{}})
+	assert.EqualError(t, err, "invalid vec element 1: unsupported type struct {}")
+
+	_, err = ToScVal(ScAddress("invalid"))
+	assert.Error(t, err)
+
+	// strings and symbols with the same content are different keys
+	_, err = ToScVal(map[interface{}]int{"a": 1, xdr.ScSymbol("a"): 2, "b": 3})
+	require.NoError(t, err)
+
+	_, err = ToScVal(map[interface{}]int{[2]int{1, 2}: 1, [2]int{3, 4}: 2})
+	assert.EqualError(t, err, "map keys of type ScValTypeScvVec are not supported")
+}
+
+func TestFromScValUnsupportedMapKey(t *testing.T) {
+	scVal, err := ToScVal(map[string]int{"a": 1})
+	require.NoError(t, err)
+	(*scVal.MustMap())[0].Key, err = ToScVal([]byte{1})
+	require.NoError(t, err)
+
+	_, err = FromScVal(scVal)
+	assert.EqualError(t, err, "map key of type ScValTypeScvBytes cannot be used as a Go map key")
+}
@@ -0,0 +1,227 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// InvokeContract returns an InvokeHostFunction operation calling function on
+// the contract with the given strkey (C...). The arguments are converted with
+// ToScVal.
+func InvokeContract(contractID string, function string, args ...interface{}) (*InvokeHostFunction, error) {
+	contractAddress, err := ScAddress(contractID).ToXDR()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid contract id")
+	}
+	if contractAddress.Type != xdr.ScAddressTypeScAddressTypeContract {
+		return nil, errors.Errorf("%s is not a contract address", contractID)
+	}
+
+	scArgs, err := toScVals(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InvokeHostFunction{
+		HostFunction: xdr.HostFunction{
+			Type: xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+			InvokeContract: &xdr.InvokeContractArgs{
+				ContractAddress: contractAddress,
+				FunctionName:    xdr.ScSymbol(function),
+				Args:            scArgs,
+			},
+		},
+	}, nil
+}
+
+// UploadWasm returns an InvokeHostFunction operation uploading the given
+// contract code. The hash of the code, which is needed to create contracts
+// from it, is returned by WasmHash.
+func UploadWasm(wasm []byte) *InvokeHostFunction {
+	return &InvokeHostFunction{
+		HostFunction: xdr.HostFunction{
+			Type: xdr.HostFunctionTypeHostFunctionTypeUploadContractWasm,
+			Wasm: &wasm,
+		},
+	}
+}
+
+// WasmHash returns the hash identifying the given contract code on the ledger.
+func WasmHash(wasm []byte) xdr.Hash {
+	return sha256.Sum256(wasm)
+}
+
+// CreateContractParams is a container for the parameters used to create a
+// contract from uploaded code.
+type CreateContractParams struct {
+	// Deployer is the account (G...) or contract (C...) deploying the
+	// contract. Together with Salt it determines the contract id.
+	Deployer string
+	// WasmHash is the hash of the uploaded contract code.
+	WasmHash xdr.Hash
+	Salt     xdr.Uint256
+	// ConstructorArgs are passed to the constructor of the contract. They are
+	// converted with ToScVal.
+	ConstructorArgs []interface{}
+}
+
+func (p CreateContractParams) preimage() (xdr.ContractIdPreimage, error) {
+	deployer, err := ScAddress(p.Deployer).ToXDR()
+	if err != nil {
+		return xdr.ContractIdPreimage{}, errors.Wrap(err, "invalid deployer")
+	}
+	return xdr.ContractIdPreimage{
+		Type: xdr.ContractIdPreimageTypeContractIdPreimageFromAddress,
+		FromAddress: &xdr.ContractIdPreimageFromAddress{
+			Address: deployer,
+			Salt:    p.Salt,
+		},
+	}, nil
+}
+
+// ContractID returns the strkey (C...) of the contract which will be created
+// on the given network.
+func (p CreateContractParams) ContractID(networkPassphrase string) (string, error) {
+	preimage, err := p.preimage()
+	if err != nil {
+		return "", err
+	}
+	hashIDPreimage := xdr.HashIdPreimage{
+		Type: xdr.EnvelopeTypeEnvelopeTypeContractId,
+		ContractId: &xdr.HashIdPreimageContractId{
+			NetworkId:          network.ID(networkPassphrase),
+			ContractIdPreimage: preimage,
+		},
+	}
+	preimageBytes, err := hashIDPreimage.MarshalBinary()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode contract id preimage")
+	}
+	contractID := sha256.Sum256(preimageBytes)
+	return strkey.Encode(strkey.VersionByteContract, contractID[:])
+}
+
+// CreateContract returns an InvokeHostFunction operation creating a contract
+// from uploaded code.
+func CreateContract(params CreateContractParams) (*InvokeHostFunction, error) {
+	preimage, err := params.preimage()
+	if err != nil {
+		return nil, err
+	}
+	executable := xdr.ContractExecutable{
+		Type:     xdr.ContractExecutableTypeContractExecutableWasm,
+		WasmHash: &params.WasmHash,
+	}
+
+	// Contracts without constructor arguments use the original host function
+	// so they can be created on networks which don't support constructors.
+	if len(params.ConstructorArgs) == 0 {
+		return &InvokeHostFunction{
+			HostFunction: xdr.HostFunction{
+				Type: xdr.HostFunctionTypeHostFunctionTypeCreateContract,
+				CreateContract: &xdr.CreateContractArgs{
+					ContractIdPreimage: preimage,
+					Executable:         executable,
+				},
+			},
+		}, nil
+	}
+
+	args, err := toScVals(params.ConstructorArgs)
+	if err != nil {
+		return nil, err
+	}
+	return &InvokeHostFunction{
+		HostFunction: xdr.HostFunction{
+			Type: xdr.HostFunctionTypeHostFunctionTypeCreateContractV2,
+			CreateContractV2: &xdr.CreateContractArgsV2{
+				ContractIdPreimage: preimage,
+				Executable:         executable,
+				ConstructorArgs:    args,
+			},
+		},
+	}, nil
+}
+
+// SorobanSimulation contains the parts of a transaction simulation, as
+// returned by the simulateTransaction method of Stellar RPC, which are needed
+// to submit a Soroban transaction.
+type SorobanSimulation struct {
+	// TransactionData holds the footprint and resources of the transaction.
+	TransactionData xdr.SorobanTransactionData
+	// MinResourceFee is added to the fee of the transaction.
+	MinResourceFee int64
+	// Auth are the authorization entries required by an InvokeHostFunction
+	// operation. They are only applied if the operation has no
+	// authorization entries.
+	Auth []xdr.SorobanAuthorizationEntry
+}
+
+// ApplySorobanSimulation returns a copy of the transaction with the footprint,
+// resources and authorization entries of the simulation applied to its
+// Soroban operation, and with the resource fee added to its fee. The
+// operations of the original transaction are not modified and the signatures
+// are not kept, since the transaction hash changes.
+func (t *Transaction) ApplySorobanSimulation(simulation SorobanSimulation) (*Transaction, error) {
+	if simulation.MinResourceFee < 0 {
+		return nil, errors.New("resource fee cannot be negative")
+	}
+
+	operations := make([]Operation, len(t.operations))
+	copy(operations, t.operations)
+
+	found := false
+	ext := xdr.TransactionExt{V: 1, SorobanData: &simulation.TransactionData}
+	for i, op := range operations {
+		switch sorobanOp := op.(type) {
+		case *InvokeHostFunction:
+			applied := *sorobanOp
+			applied.Ext = ext
+			if len(applied.Auth) == 0 {
+				applied.Auth = simulation.Auth
+			}
+			operations[i] = &applied
+		case *ExtendFootprintTtl:
+			applied := *sorobanOp
+			applied.Ext = ext
+			operations[i] = &applied
+		case *RestoreFootprint:
+			applied := *sorobanOp
+			applied.Ext = ext
+			operations[i] = &applied
+		default:
+			continue
+		}
+		found = true
+	}
+	if !found {
+		return nil, errors.New("transaction has no Soroban operation")
+	}
+
+	// Soroban transactions contain a single operation, so the resource fee
+	// can be folded into the base fee.
+	return NewTransaction(TransactionParams{
+		SourceAccount:        &t.sourceAccount,
+		IncrementSequenceNum: false,
+		Operations:           operations,
+		BaseFee:              t.baseFee + simulation.MinResourceFee,
+		Memo:                 t.memo,
+		Preconditions:        t.preconditions,
+	})
+}
+
+func toScVals(values []interface{}) (xdr.ScVec, error) {
+	result := make(xdr.ScVec, 0, len(values))
+	for i, value := range values {
+		scVal, err := ToScVal(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid argument %d", i)
+		}
+		result = append(result, scVal)
+	}
+	return result, nil
+}
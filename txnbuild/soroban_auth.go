@@ -0,0 +1,137 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AuthEntrySigner signs Soroban authorization entries on behalf of an
+// address. Implement it to sign entries with keys which are not available as a
+// keypair.Full (for example keys held in an HSM) or for contract accounts which
+// expect a custom signature format.
+type AuthEntrySigner interface {
+	// SignAuthEntry returns the signature of address over payload, the SHA-256
+	// hash of the encoded preimage, in the format expected by the address.
+	SignAuthEntry(address xdr.ScAddress, preimage xdr.HashIdPreimage, payload xdr.Hash) (xdr.ScVal, error)
+}
+
+// KeypairAuthEntrySigner is an AuthEntrySigner signing authorization entries
+// of a Stellar account with its keypair.
+type KeypairAuthEntrySigner struct {
+	Keypair *keypair.Full
+}
+
+// SignAuthEntry implements AuthEntrySigner. The signature is encoded as a vec
+// containing a single map with the public_key and signature of the account,
+// which is the format verified by the Soroban host for Stellar accounts.
+func (s KeypairAuthEntrySigner) SignAuthEntry(address xdr.ScAddress, preimage xdr.HashIdPreimage, payload xdr.Hash) (xdr.ScVal, error) {
+	if s.Keypair == nil {
+		return xdr.ScVal{}, errors.New("keypair is not set")
+	}
+	addressString, err := address.String()
+	if err != nil {
+		return xdr.ScVal{}, errors.Wrap(err, "invalid address")
+	}
+	if addressString != s.Keypair.Address() {
+		return xdr.ScVal{}, errors.Errorf("keypair %s cannot sign for address %s", s.Keypair.Address(), addressString)
+	}
+
+	publicKey, err := strkey.Decode(strkey.VersionByteAccountID, s.Keypair.Address())
+	if err != nil {
+		return xdr.ScVal{}, errors.Wrap(err, "failed to decode public key")
+	}
+	signature, err := s.Keypair.Sign(payload[:])
+	if err != nil {
+		return xdr.ScVal{}, errors.Wrap(err, "failed to sign payload")
+	}
+
+	publicKeyBytes := xdr.ScBytes(publicKey)
+	signatureBytes := xdr.ScBytes(signature)
+	publicKeySymbol := xdr.ScSymbol("public_key")
+	signatureSymbol := xdr.ScSymbol("signature")
+	signatureMap := &xdr.ScMap{
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &publicKeySymbol},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &publicKeyBytes},
+		},
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &signatureSymbol},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &signatureBytes},
+		},
+	}
+	signatures := &xdr.ScVec{
+		{Type: xdr.ScValTypeScvMap, Map: &signatureMap},
+	}
+	return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &signatures}, nil
+}
+
+// AuthEntryPreimage returns the preimage which has to be signed to authorize
+// entry until (and including) the validUntilLedger ledger on the given
+// network. The entry must have address credentials.
+func AuthEntryPreimage(entry xdr.SorobanAuthorizationEntry, validUntilLedger uint32, networkPassphrase string) (xdr.HashIdPreimage, error) {
+	credentials, ok := entry.Credentials.GetAddress()
+	if !ok {
+		return xdr.HashIdPreimage{}, errors.New("authorization entry does not have address credentials")
+	}
+	return xdr.HashIdPreimage{
+		Type: xdr.EnvelopeTypeEnvelopeTypeSorobanAuthorization,
+		SorobanAuthorization: &xdr.HashIdPreimageSorobanAuthorization{
+			NetworkId:                 network.ID(networkPassphrase),
+			Nonce:                     credentials.Nonce,
+			SignatureExpirationLedger: xdr.Uint32(validUntilLedger),
+			Invocation:                entry.RootInvocation,
+		},
+	}, nil
+}
+
+// SignAuthEntry returns a copy of entry signed by signer and valid until (and
+// including) the validUntilLedger ledger on the given network. Entries with
+// source account credentials are authorized by the transaction signatures, so
+// they are returned unchanged.
+func SignAuthEntry(
+	entry xdr.SorobanAuthorizationEntry,
+	signer AuthEntrySigner,
+	validUntilLedger uint32,
+	networkPassphrase string,
+) (xdr.SorobanAuthorizationEntry, error) {
+	if entry.Credentials.Type == xdr.SorobanCredentialsTypeSorobanCredentialsSourceAccount {
+		return entry, nil
+	}
+
+	preimage, err := AuthEntryPreimage(entry, validUntilLedger, networkPassphrase)
+	if err != nil {
+		return xdr.SorobanAuthorizationEntry{}, err
+	}
+	preimageBytes, err := preimage.MarshalBinary()
+	if err != nil {
+		return xdr.SorobanAuthorizationEntry{}, errors.Wrap(err, "failed to encode preimage")
+	}
+	payload := xdr.Hash(sha256.Sum256(preimageBytes))
+
+	// Copy the credentials so the entry passed by the caller is not modified.
+	credentials := *entry.Credentials.Address
+	signature, err := signer.SignAuthEntry(credentials.Address, preimage, payload)
+	if err != nil {
+		return xdr.SorobanAuthorizationEntry{}, errors.Wrap(err, "failed to sign authorization entry")
+	}
+	credentials.SignatureExpirationLedger = xdr.Uint32(validUntilLedger)
+	credentials.Signature = signature
+	entry.Credentials.Address = &credentials
+	return entry, nil
+}
+
+// SignAuthEntryWithKeypair signs entry with the keypair of the Stellar account
+// it authorizes. See SignAuthEntry.
+func SignAuthEntryWithKeypair(
+	entry xdr.SorobanAuthorizationEntry,
+	kp *keypair.Full,
+	validUntilLedger uint32,
+	networkPassphrase string,
+) (xdr.SorobanAuthorizationEntry, error) {
+	return SignAuthEntry(entry, KeypairAuthEntrySigner{Keypair: kp}, validUntilLedger, networkPassphrase)
+}
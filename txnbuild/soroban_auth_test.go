@@ -0,0 +1,142 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+func newTestAuthEntry(t *testing.T, address string) xdr.SorobanAuthorizationEntry {
+	op, err := InvokeContract(testContractID, "transfer", ScAddress(address), int64(10))
+	require.NoError(t, err)
+	scAddress, err := ScAddress(address).ToXDR()
+	require.NoError(t, err)
+
+	return xdr.SorobanAuthorizationEntry{
+		Credentials: xdr.SorobanCredentials{
+			Type: xdr.SorobanCredentialsTypeSorobanCredentialsAddress,
+			Address: &xdr.SorobanAddressCredentials{
+				Address:   scAddress,
+				Nonce:     123,
+				Signature: xdr.ScVal{Type: xdr.ScValTypeScvVoid},
+			},
+		},
+		RootInvocation: xdr.SorobanAuthorizedInvocation{
+			Function: xdr.SorobanAuthorizedFunction{
+				Type:       xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn,
+				ContractFn: op.HostFunction.InvokeContract,
+			},
+		},
+	}
+}
+
+func TestSignAuthEntryWithKeypair(t *testing.T) {
+	kp0 := newKeypair0()
+	entry := newTestAuthEntry(t, kp0.Address())
+
+	signed, err := SignAuthEntryWithKeypair(entry, kp0, 1000, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+
+	credentials := signed.Credentials.MustAddress()
+	assert.Equal(t, xdr.Uint32(1000), credentials.SignatureExpirationLedger)
+	assert.Equal(t, xdr.Int64(123), credentials.Nonce)
+	assert.Equal(t, entry.RootInvocation, signed.RootInvocation)
+
+	// the entry passed in is not modified
+	assert.Equal(t, xdr.Uint32(0), entry.Credentials.MustAddress().SignatureExpirationLedger)
+	assert.Equal(t, xdr.ScValTypeScvVoid, entry.Credentials.MustAddress().Signature.Type)
+
+	signatures := *credentials.Signature.MustVec()
+	require.Len(t, signatures, 1)
+	signatureMap := *signatures[0].MustMap()
+	require.Len(t, signatureMap, 2)
+	assert.Equal(t, xdr.ScSymbol("public_key"), signatureMap[0].Key.MustSym())
+	assert.Equal(t, xdr.ScSymbol("signature"), signatureMap[1].Key.MustSym())
+	publicKey, err := strkey.Decode(strkey.VersionByteAccountID, kp0.Address())
+	require.NoError(t, err)
+	assert.Equal(t, xdr.ScBytes(publicKey), signatureMap[0].Val.MustBytes())
+
+	preimage, err := AuthEntryPreimage(entry, 1000, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	preimageBytes, err := preimage.MarshalBinary()
+	require.NoError(t, err)
+	payload := sha256.Sum256(preimageBytes)
+	assert.NoError(t, kp0.Verify(payload[:], signatureMap[1].Val.MustBytes()))
+
+	// the signature depends on the expiration ledger
+	other, err := SignAuthEntryWithKeypair(entry, kp0, 1001, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.NotEqual(t, credentials.Signature, other.Credentials.MustAddress().Signature)
+}
+
+func TestSignAuthEntryWrongKeypair(t *testing.T) {
+	entry := newTestAuthEntry(t, newKeypair0().Address())
+
+	_, err := SignAuthEntryWithKeypair(entry, newKeypair1(), 1000, network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "failed to sign authorization entry: keypair "+newKeypair1().Address()+
+		" cannot sign for address "+newKeypair0().Address())
+}
+
+func TestSignAuthEntrySourceAccount(t *testing.T) {
+	entry := newTestAuthEntry(t, newKeypair0().Address())
+	entry.Credentials = xdr.SorobanCredentials{
+		Type: xdr.SorobanCredentialsTypeSorobanCredentialsSourceAccount,
+	}
+
+	signed, err := SignAuthEntryWithKeypair(entry, newKeypair0(), 1000, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, entry, signed)
+
+	_, err = AuthEntryPreimage(entry, 1000, network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "authorization entry does not have address credentials")
+}
+
+type testAuthEntrySigner struct {
+	address  xdr.ScAddress
+	preimage xdr.HashIdPreimage
+	payload  xdr.Hash
+	err      error
+}
+
+func (s *testAuthEntrySigner) SignAuthEntry(address xdr.ScAddress, preimage xdr.HashIdPreimage, payload xdr.Hash) (xdr.ScVal, error) {
+	s.address = address
+	s.preimage = preimage
+	s.payload = payload
+	if s.err != nil {
+		return xdr.ScVal{}, s.err
+	}
+	signature := xdr.ScBytes(payload[:])
+	return xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &signature}, nil
+}
+
+func TestSignAuthEntryCustomSigner(t *testing.T) {
+	entry := newTestAuthEntry(t, testContractID)
+	signer := &testAuthEntrySigner{}
+
+	signed, err := SignAuthEntry(entry, signer, 500, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+
+	assert.Equal(t, entry.Credentials.MustAddress().Address, signer.address)
+	expectedPreimage, err := AuthEntryPreimage(entry, 500, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, expectedPreimage, signer.preimage)
+	preimageAuth := signer.preimage.MustSorobanAuthorization()
+	assert.Equal(t, xdr.Hash(network.ID(network.PublicNetworkPassphrase)), preimageAuth.NetworkId)
+	assert.Equal(t, xdr.Int64(123), preimageAuth.Nonce)
+	assert.Equal(t, xdr.Uint32(500), preimageAuth.SignatureExpirationLedger)
+
+	credentials := signed.Credentials.MustAddress()
+	assert.Equal(t, xdr.Uint32(500), credentials.SignatureExpirationLedger)
+	assert.Equal(t, xdr.ScBytes(signer.payload[:]), credentials.Signature.MustBytes())
+
+	signer.err = errors.New("device disconnected")
+	_, err = SignAuthEntry(entry, signer, 500, network.PublicNetworkPassphrase)
+	assert.EqualError(t, err, "failed to sign authorization entry: device disconnected")
+}
@@ -0,0 +1,190 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+func TestInvokeContract(t *testing.T) {
+	op, err := InvokeContract(testContractID, "transfer",
+		ScAddress(newKeypair0().Address()), ScAddress(newKeypair1().Address()), big.NewInt(100))
+	require.NoError(t, err)
+	require.NoError(t, op.Validate())
+
+	args := op.HostFunction.MustInvokeContract()
+	assert.Equal(t, xdr.ScSymbol("transfer"), args.FunctionName)
+	contractID, err := args.ContractAddress.String()
+	require.NoError(t, err)
+	assert.Equal(t, testContractID, contractID)
+	require.Len(t, args.Args, 3)
+	assert.Equal(t, xdr.ScValTypeScvAddress, args.Args[0].Type)
+	assert.Equal(t, xdr.Int128Parts{Hi: 0, Lo: 100}, args.Args[2].MustI128())
+
+	_, err = InvokeContract(newKeypair0().Address(), "transfer")
+	assert.EqualError(t, err, newKeypair0().Address()+" is not a contract address")
+
+	_, err = InvokeContract(testContractID, "transfer", struct{}{})
+	assert.EqualError(t, err, "invalid argument 0: unsupported type struct {}")
+}
+
+func TestUploadWasm(t *testing.T) {
+	wasm := []byte{0x00, 0x61, 0x73, 0x6d}
+	op := UploadWasm(wasm)
+	assert.Equal(t, xdr.HostFunctionTypeHostFunctionTypeUploadContractWasm, op.HostFunction.Type)
+	assert.Equal(t, wasm, op.HostFunction.MustWasm())
+	assert.Equal(t, xdr.Hash(sha256.Sum256(wasm)), WasmHash(wasm))
+}
+
+func TestCreateContract(t *testing.T) {
+	params := CreateContractParams{
+		Deployer: newKeypair0().Address(),
+		WasmHash: WasmHash([]byte{0x00, 0x61, 0x73, 0x6d}),
+		Salt:     xdr.Uint256{1},
+	}
+
+	op, err := CreateContract(params)
+	require.NoError(t, err)
+	assert.Equal(t, xdr.HostFunctionTypeHostFunctionTypeCreateContract, op.HostFunction.Type)
+	createArgs := op.HostFunction.MustCreateContract()
+	assert.Equal(t, params.WasmHash, createArgs.Executable.MustWasmHash())
+	assert.Equal(t, params.Salt, createArgs.ContractIdPreimage.MustFromAddress().Salt)
+
+	params.ConstructorArgs = []interface{}{xdr.ScSymbol("admin"), uint32(7)}
+	op, err = CreateContract(params)
+	require.NoError(t, err)
+	assert.Equal(t, xdr.HostFunctionTypeHostFunctionTypeCreateContractV2, op.HostFunction.Type)
+	assert.Len(t, op.HostFunction.MustCreateContractV2().ConstructorArgs, 2)
+
+	contractID, err := params.ContractID(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	version, err := strkey.Version(contractID)
+	require.NoError(t, err)
+	assert.Equal(t, strkey.VersionByte(strkey.VersionByteContract), version)
+
+	otherNetworkID, err := params.ContractID(network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+	assert.NotEqual(t, contractID, otherNetworkID)
+
+	params.Salt = xdr.Uint256{2}
+	otherSaltID, err := params.ContractID(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.NotEqual(t, contractID, otherSaltID)
+
+	_, err = CreateContract(CreateContractParams{Deployer: "invalid"})
+	assert.Error(t, err)
+}
+
+func TestApplySorobanSimulation(t *testing.T) {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+
+	op, err := InvokeContract(testContractID, "hello", xdr.ScSymbol("world"))
+	require.NoError(t, err)
+	tx, err := NewTransaction(TransactionParams{
+		SourceAccount: &sourceAccount,
+		Operations:    []Operation{op},
+		BaseFee:       MinBaseFee,
+		Memo:          MemoText("simulated"),
+		Preconditions: Preconditions{TimeBounds: NewInfiniteTimeout()},
+	})
+	require.NoError(t, err)
+	tx, err = tx.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+
+	transactionData := xdr.SorobanTransactionData{
+		Resources: xdr.SorobanResources{
+			Footprint: xdr.LedgerFootprint{
+				ReadOnly: []xdr.LedgerKey{
+					{
+						Type: xdr.LedgerEntryTypeContractCode,
+						ContractCode: &xdr.LedgerKeyContractCode{
+							Hash: xdr.Hash{1},
+						},
+					},
+				},
+			},
+			Instructions: 1000,
+		},
+		ResourceFee: 5000,
+	}
+	auth := []xdr.SorobanAuthorizationEntry{
+		{
+			Credentials: xdr.SorobanCredentials{
+				Type: xdr.SorobanCredentialsTypeSorobanCredentialsSourceAccount,
+			},
+			RootInvocation: xdr.SorobanAuthorizedInvocation{
+				Function: xdr.SorobanAuthorizedFunction{
+					Type:       xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn,
+					ContractFn: op.HostFunction.InvokeContract,
+				},
+			},
+		},
+	}
+
+	applied, err := tx.ApplySorobanSimulation(SorobanSimulation{
+		TransactionData: transactionData,
+		MinResourceFee:  5000,
+		Auth:            auth,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(MinBaseFee+5000), applied.MaxFee())
+	assert.Equal(t, tx.SequenceNumber(), applied.SequenceNumber())
+	assert.Equal(t, tx.Memo(), applied.Memo())
+	assert.Empty(t, applied.Signatures())
+
+	envelope := applied.ToXDR()
+	assert.Equal(t, xdr.TransactionExt{V: 1, SorobanData: &transactionData}, envelope.V1.Tx.Ext)
+	assert.Equal(t, auth, envelope.V1.Tx.Operations[0].Body.MustInvokeHostFunctionOp().Auth)
+
+	// the original transaction is not modified
+	assert.Empty(t, op.Auth)
+	assert.Equal(t, xdr.TransactionExt{}, op.Ext)
+	assert.Equal(t, int64(MinBaseFee), tx.MaxFee())
+	assert.Len(t, tx.Signatures(), 1)
+
+	// existing authorization entries are kept
+	signedAuth := auth[0]
+	signedAuth.Credentials = xdr.SorobanCredentials{
+		Type: xdr.SorobanCredentialsTypeSorobanCredentialsAddress,
+		Address: &xdr.SorobanAddressCredentials{
+			Address: xdr.ScAddress{
+				Type:      xdr.ScAddressTypeScAddressTypeAccount,
+				AccountId: xdr.MustAddressPtr(kp0.Address()),
+			},
+			Signature: xdr.ScVal{Type: xdr.ScValTypeScvVoid},
+		},
+	}
+	op.Auth = []xdr.SorobanAuthorizationEntry{signedAuth}
+	applied, err = tx.ApplySorobanSimulation(SorobanSimulation{
+		TransactionData: transactionData,
+		MinResourceFee:  5000,
+		Auth:            auth,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []xdr.SorobanAuthorizationEntry{signedAuth},
+		applied.ToXDR().V1.Tx.Operations[0].Body.MustInvokeHostFunctionOp().Auth)
+}
+
+func TestApplySorobanSimulationWithoutSorobanOperation(t *testing.T) {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+	tx, err := NewTransaction(TransactionParams{
+		SourceAccount: &sourceAccount,
+		Operations:    []Operation{&BumpSequence{BumpTo: 1}},
+		BaseFee:       MinBaseFee,
+		Preconditions: Preconditions{TimeBounds: NewInfiniteTimeout()},
+	})
+	require.NoError(t, err)
+
+	_, err = tx.ApplySorobanSimulation(SorobanSimulation{})
+	assert.EqualError(t, err, "transaction has no Soroban operation")
+}